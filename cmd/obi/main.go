@@ -5,15 +5,15 @@ import (
 	"os"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/app"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/fakecodex"
 )
 
 func main() {
 	args := os.Args[1:]
-	if len(args) == 1 && args[0] == "--version" {
-		fmt.Println(app.Version())
-		return
+	if scenario := os.Getenv(app.SimulateEnv); scenario != "" {
+		// obi go --simulate launches this binary in place of Codex.
+		os.Exit(fakecodex.Exec(scenario, args, os.Stdin, os.Stdout, os.Stderr))
 	}
-
 	if err := app.Run(args); err != nil {
 		fmt.Fprintf(os.Stderr, "obi: %v\n", err)
 		os.Exit(1)
@@ -4,5 +4,5 @@ go 1.25.4
 
 require (
 	github.com/creack/pty v1.1.23
-	github.com/pelletier/go-toml/v2 v2.2.4 // indirect
+	github.com/pelletier/go-toml/v2 v2.2.4
 )
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// Ledger values of abort_cleanup.
+const (
+	abortCleanupRan      = "ran"
+	abortCleanupFailed   = "failed"
+	abortCleanupDeclined = "declined"
+	abortCleanupSkipped  = "skipped"
+)
+
+// abortCleanupFields are the values a [guardrails] abort_cleanup command
+// can reference.
+type abortCleanupFields struct {
+	Paths string
+}
+
+// renderAbortCleanup expands tmpl with {{.Paths}} as the shell-quoted
+// paths, so the command only touches what Codex changed.
+func renderAbortCleanup(tmpl string, paths []string) (string, error) {
+	parsed, err := template.New("abort_cleanup").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("guardrails.abort_cleanup: %w", err)
+	}
+	var quoted []string
+	for _, p := range paths {
+		if p = strings.TrimSpace(p); p != "" {
+			quoted = append(quoted, shellQuote(p))
+		}
+	}
+	var b strings.Builder
+	if err := parsed.Execute(&b, abortCleanupFields{Paths: strings.Join(quoted, " ")}); err != nil {
+		return "", fmt.Errorf("guardrails.abort_cleanup: %w", err)
+	}
+	return b.String(), nil
+}
+
+// offerAbortCleanup asks whether to run [guardrails] abort_cleanup after
+// an aborted session and runs it from the git root when the operator
+// agrees. scope names the paths Codex changed. It returns the ledger
+// value, or "" when no cleanup is set. --ci, read-only and simulated
+// sessions never run it: nobody is there to confirm, or Codex could not
+// have changed the tree. Nor does a session that changed nothing.
+func offerAbortCleanup(guard config.GuardrailsConfig, plan sessionPlan, scope *scopeGuard, ci bool, in io.Reader, out io.Writer) string {
+	tmpl := strings.TrimSpace(guard.AbortCleanup)
+	if tmpl == "" {
+		return ""
+	}
+	if ci || plan.ReadOnly || plan.Simulated != "" {
+		return abortCleanupSkipped
+	}
+	paths, err := scope.cleanupPaths()
+	if err != nil {
+		fmt.Fprintf(out, "obi: abort cleanup not run: %v\n", err)
+		return abortCleanupFailed
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(out, "\nSession aborted. Codex left no changes to clean up.")
+		return abortCleanupSkipped
+	}
+	command, err := renderAbortCleanup(tmpl, paths)
+	if err != nil {
+		fmt.Fprintf(out, "obi: abort cleanup not run: %v\n", err)
+		return abortCleanupFailed
+	}
+
+	fmt.Fprintf(out, "\nSession aborted. Cleanup command (in %s):\n  %s\n", scope.top, command)
+	if !askAbortCleanup(in, out) {
+		fmt.Fprintln(out, "Cleanup skipped; the working tree is as Codex left it.")
+		return abortCleanupDeclined
+	}
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = scope.top
+	cmd.Env = append(os.Environ(), plan.Env...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(out, "obi: abort cleanup failed: %v\n", err)
+		return abortCleanupFailed
+	}
+	fmt.Fprintln(out, "Cleanup finished.")
+	return abortCleanupRan
+}
+
+// askAbortCleanup is a y/N prompt: discarding changes needs an explicit
+// yes, so an empty answer or closed input declines.
+func askAbortCleanup(in io.Reader, out io.Writer) bool {
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprint(out, "Run it? [y/N] ")
+		input, err := reader.ReadString('\n')
+		switch strings.TrimSpace(strings.ToLower(input)) {
+		case "y", "yes":
+			return true
+		case "", "n", "no":
+			return false
+		}
+		if err != nil {
+			return false
+		}
+		fmt.Fprintln(out, "Please respond with y or N.")
+	}
+}
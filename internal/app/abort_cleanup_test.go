@@ -0,0 +1,106 @@
+package app
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestRenderAbortCleanupQuotesPaths(t *testing.T) {
+	got, err := renderAbortCleanup("git checkout -- {{.Paths}}", []string{"services/api/main.go", "it's here", " "})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if want := `git checkout -- 'services/api/main.go' 'it'\''s here'`; got != want {
+		t.Fatalf("rendered %q, want %q", got, want)
+	}
+	if _, err := renderAbortCleanup("git clean {{.Dir}}", nil); err == nil || !strings.Contains(err.Error(), "guardrails.abort_cleanup") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestOfferAbortCleanupRunsOnlyWhenConfirmed(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=obi", "-c", "user.email=obi@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	write("README.md", "root\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "root")
+	write("notes.txt", "the operator's own edit\n")
+
+	guard := config.GuardrailsConfig{AbortCleanup: "echo {{.Paths}} > cleaned"}
+	cfg := &config.Config{ResultsLog: filepath.Join(dir, "obi-results.log"), Guardrails: guard}
+	scope := newScopeGuard(cfg, dir, filepath.Join(dir, "obi.toml"))
+	plan := sessionPlan{RepoRoot: dir}
+	marker := filepath.Join(dir, "cleaned")
+
+	var out strings.Builder
+	if got := offerAbortCleanup(guard, plan, scope, false, strings.NewReader("y\n"), &out); got != abortCleanupSkipped || !strings.Contains(out.String(), "no changes") {
+		t.Fatalf("without Codex changes got %q; output:\n%s", got, out.String())
+	}
+
+	write("codex.go", "package main\n")
+	write("obi-results.log", "{}\n")
+	for _, answer := range []string{"\n", "n\n", ""} {
+		if got := offerAbortCleanup(guard, plan, scope, false, strings.NewReader(answer), io.Discard); got != abortCleanupDeclined {
+			t.Fatalf("answer %q: got %q, want %q", answer, got, abortCleanupDeclined)
+		}
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("cleanup ran without confirmation: %v", err)
+	}
+
+	out.Reset()
+	if got := offerAbortCleanup(guard, plan, scope, false, strings.NewReader("maybe\ny\n"), &out); got != abortCleanupRan {
+		t.Fatalf("got %q, want %q; output:\n%s", got, abortCleanupRan, out.String())
+	}
+	if data, err := os.ReadFile(marker); err != nil || string(data) != "codex.go\n" {
+		t.Fatalf("cleanup should name only Codex's change, got %q, %v", data, err)
+	}
+	if !strings.Contains(out.String(), "Please respond with y or N.") {
+		t.Fatalf("expected a reprompt, got:\n%s", out.String())
+	}
+
+	guard.AbortCleanup = "exit 3"
+	if got := offerAbortCleanup(guard, plan, scope, false, strings.NewReader("yes\n"), io.Discard); got != abortCleanupFailed {
+		t.Fatalf("got %q, want %q", got, abortCleanupFailed)
+	}
+}
+
+func TestOfferAbortCleanupSkipsUnattendedSessions(t *testing.T) {
+	guard := config.GuardrailsConfig{AbortCleanup: "false"}
+	cases := map[string]struct {
+		plan sessionPlan
+		ci   bool
+	}{
+		"ci":        {plan: sessionPlan{}, ci: true},
+		"read-only": {plan: sessionPlan{ReadOnly: true}},
+		"simulated": {plan: sessionPlan{Simulated: "success"}},
+	}
+	for name, tc := range cases {
+		if got := offerAbortCleanup(guard, tc.plan, nil, tc.ci, strings.NewReader("y\n"), io.Discard); got != abortCleanupSkipped {
+			t.Errorf("%s: got %q, want %q", name, got, abortCleanupSkipped)
+		}
+	}
+	if got := offerAbortCleanup(config.GuardrailsConfig{}, sessionPlan{}, nil, false, strings.NewReader("y\n"), io.Discard); got != "" {
+		t.Fatalf("without abort_cleanup got %q, want empty", got)
+	}
+}
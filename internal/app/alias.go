@@ -0,0 +1,133 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func runAlias(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("obi alias requires a subcommand ('rename')")
+	}
+	switch args[0] {
+	case "rename":
+		return runAliasRename(args[1:])
+	default:
+		return fmt.Errorf("unknown alias subcommand %q", args[0])
+	}
+}
+
+func runAliasRename(args []string) error {
+	fs := newCommandFlags("alias rename", "obi alias rename <old> <new> [options]",
+		"Rename an epic alias in obi.toml. The old alias is kept under former_aliases so history\nand report queries that use it still find the epic's runs, and the rename is audited.", "old", "new")
+	var configPath, completionPath string
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.StringVar(&completionPath, "completion", "", "also rewrite the zsh completion script at this path")
+	positional, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	oldAlias, newAlias := positionalArg(positional, 0), positionalArg(positional, 1)
+	if oldAlias == "" || newAlias == "" {
+		return fmt.Errorf("usage: obi alias rename <old> <new>")
+	}
+
+	resolved, err := config.ResolvePath(configPath)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfigIfExists(resolved)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return fmt.Errorf("no obi config at %s; run obi init first", resolved)
+	}
+	key, previous, err := renameEpicAlias(cfg, oldAlias, newAlias)
+	if err != nil {
+		return err
+	}
+
+	written, err := saveEpicConfig(resolved, cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Renamed %s (%s) from %s to %s in %s.\n", key, cfg.Epics[key].ID, previous, newAlias, written)
+
+	if auditPath, err := cfg.AuditLogPath(); err == nil {
+		newAuditLog(auditPath, "", cfg.Epics[key].ID, nil).record(auditAliasRename, previous+" -> "+newAlias)
+	}
+
+	if completionPath != "" {
+		if err := os.WriteFile(completionPath, []byte(buildZshCompletionScript(cfg)+"\n"), 0o644); err != nil {
+			return fmt.Errorf("write completion script: %w", err)
+		}
+		fmt.Printf("Updated zsh completions in %s.\n", completionPath)
+	} else {
+		fmt.Println("Regenerate shell completions to pick up the new alias: obi completion zsh > ~/.zsh/completions/_obi")
+	}
+	return nil
+}
+
+// renameEpicAlias points the epic oldAlias names at newAlias and records the
+// alias it replaced under former_aliases. It returns the epic key and that
+// previous alias.
+func renameEpicAlias(cfg *config.Config, oldAlias, newAlias string) (string, string, error) {
+	key, epic, err := resolveEpic(cfg, strings.TrimSpace(oldAlias))
+	if err != nil {
+		return "", "", err
+	}
+	newAlias = strings.TrimSpace(newAlias)
+	if newAlias != strings.ToLower(newAlias) || enforceAliasCharset(newAlias) != newAlias || newAlias == "" {
+		return "", "", fmt.Errorf("alias %q must use lowercase letters, digits, and hyphens", newAlias)
+	}
+	previous := epicAliasHandle(key, epic)
+	if previous == newAlias {
+		return "", "", fmt.Errorf("epic %s is already called %s", key, newAlias)
+	}
+	if other := aliasOwner(cfg, newAlias, key); other != "" {
+		return "", "", fmt.Errorf("alias %q is already used by epic %s", newAlias, other)
+	}
+
+	var former []string
+	for _, alias := range append(epic.FormerAliases, previous) {
+		alias = strings.TrimSpace(alias)
+		if alias == "" || strings.EqualFold(alias, newAlias) || containsFold(former, alias) {
+			continue
+		}
+		former = append(former, alias)
+	}
+	epic.Alias = newAlias
+	epic.FormerAliases = former
+	cfg.Epics[key] = epic
+	return key, previous, nil
+}
+
+// aliasOwner names the epic, other than key, that already answers to alias
+// by key, ID, current alias, or former alias, including archived epics.
+func aliasOwner(cfg *config.Config, alias, key string) string {
+	for _, group := range []map[string]config.EpicConfig{cfg.Epics, cfg.Archive.Epics} {
+		for other, epic := range group {
+			if other == key {
+				continue
+			}
+			names := append([]string{other, epic.ID, epicAliasHandle(other, epic)}, epic.FormerAliases...)
+			if containsFold(names, alias) {
+				return other
+			}
+		}
+	}
+	return ""
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), target) {
+			return true
+		}
+	}
+	return false
+}
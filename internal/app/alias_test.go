@@ -0,0 +1,89 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestRenameEpicAliasKeepsFormerAlias(t *testing.T) {
+	cfg := &config.Config{
+		Epics: map[string]config.EpicConfig{
+			"obi_api": {Name: "API", ID: "obi-api", Alias: "api"},
+			"obi_web": {Name: "Web", ID: "obi-web", Alias: "web"},
+		},
+		Archive: config.ArchiveConfig{Epics: map[string]config.EpicConfig{
+			"obi_old": {Name: "Old", ID: "obi-old", Alias: "legacy"},
+		}},
+	}
+
+	key, previous, err := renameEpicAlias(cfg, "api", "backend")
+	if err != nil || key != "obi_api" || previous != "api" {
+		t.Fatalf("rename = %q, %q, %v", key, previous, err)
+	}
+	if got := cfg.Epics["obi_api"]; got.Alias != "backend" || strings.Join(got.FormerAliases, ",") != "api" {
+		t.Fatalf("renamed epic = %+v", got)
+	}
+	if got := reportEpicID(cfg, "api"); got != "obi-api" {
+		t.Fatalf("old alias resolves to %q", got)
+	}
+
+	// Renaming back drops the alias from former_aliases again.
+	if _, _, err := renameEpicAlias(cfg, "backend", "api"); err != nil {
+		t.Fatalf("rename back: %v", err)
+	}
+	if got := cfg.Epics["obi_api"]; got.Alias != "api" || strings.Join(got.FormerAliases, ",") != "backend" {
+		t.Fatalf("renamed back = %+v", got)
+	}
+
+	for newAlias, wantErr := range map[string]string{
+		"web":     "already used by epic obi_web",
+		"legacy":  "already used by epic obi_old",
+		"obi-web": "already used by epic obi_web",
+		"Api v2":  "lowercase letters",
+		"api":     "already called api",
+	} {
+		if _, _, err := renameEpicAlias(cfg, "api", newAlias); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("rename to %q: expected %q, got %v", newAlias, wantErr, err)
+		}
+	}
+}
+
+func TestRunAliasRenameRewritesConfigAndCompletion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "obi.toml")
+	cfg := &config.Config{
+		ResultsLog: filepath.Join(dir, "obi-results.log"),
+		Epics:      map[string]config.EpicConfig{"obi_api": {Name: "API", ID: "obi-api", Alias: "api", Prompt: "ship it"}},
+	}
+	if err := writeConfigFile(path, cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	completion := filepath.Join(dir, "_obi")
+
+	if err := runAliasRename([]string{"api", "backend", "--config", path, "--completion", completion}); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	loaded, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := loaded.Epics["obi_api"]; got.Alias != "backend" || len(got.FormerAliases) != 1 || got.Prompt != "ship it" {
+		t.Fatalf("epic after rename = %+v", got)
+	}
+	script, err := os.ReadFile(completion)
+	if err != nil || !strings.Contains(string(script), "'backend'") {
+		t.Fatalf("completion script = %q, %v", script, err)
+	}
+	auditPath, err := loaded.AuditLogPath()
+	if err != nil {
+		t.Fatalf("audit path: %v", err)
+	}
+	records, err := readAuditRecords(auditPath)
+	if err != nil || len(records) != 1 || records[0].Action != string(auditAliasRename) || records[0].Detail != "api -> backend" {
+		t.Fatalf("audit records = %+v, %v", records, err)
+	}
+}
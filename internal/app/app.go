@@ -3,6 +3,7 @@ package app
 import (
 	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -10,9 +11,11 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/codexexec"
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/fenced"
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/footer"
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
@@ -23,17 +26,65 @@ const usage = `obi – automate Codex bead execution
 
 Usage:
   obi init                      Scaffold obi.toml (or refresh if it already exists)
-  obi refresh [--config path]   Sync obi.toml with open epics
+  obi refresh [--config path] [--seed-prompts]
+                                Sync obi.toml with open epics
   obi list [--config path]      Show available epics and aliases
-  obi go <alias> [options]      Preview and run a Codex session`
+  obi env [alias] [--config path]
+                                Print the resolved execution context for an alias
+  obi go <alias> [options]      Preview and run a Codex session
+  obi go --workspace <alias> [--parallel]
+                                Run the epic loop in every repo of workspace.toml
+  obi prompt <alias> [options]  Print the composed prompt for review or diffing
+  obi ask "<question>" [--epic alias]
+                                Ask Codex a read-only question and log the answer
+  obi plan <alias> "<work>"     Have Codex propose beads for the epic, then bd create them
+  obi report beads [options]    Summarize attempts, time, and commits per bead
+  obi report digest [options]   Write a Markdown digest of recent runs by epic
+  obi tail <session|--latest>   Follow a running session's transcript read-only
+  obi audit [options]           Show who approved, hinted, paused, or stopped sessions
+  obi status [options]          Show running sessions and flag hung or vanished ones
+  obi history [alias] [options] List recorded runs by commit type (e.g. --type fix)
+  obi diff-runs <run-a> <run-b> Compare two runs' prompts, durations, tokens, summaries, and changed files
+  obi experiment --variants 'prompts/*.toml' --bead <id> --simulate|--live
+                                Run one bead with each prompt variant and report which did best
+  obi clean [--dry-run]         Prune old transcripts and leftovers from crashed runs
+  obi alias rename <old> <new>  Rename an epic alias, keeping history queries on the old one working
+  obi edit <alias>              Edit one epic's prompt in $EDITOR and preview the session prompt
+  obi run [template] [options]  Run a [template.*] chore outside bd, or list templates and when they are due
+  obi schedule [--once|--list]  Run the [[schedule.jobs]] cron entries unattended
+  obi ledger migrate [--dry-run]
+                                Upgrade the results log to the current schema, with a backup
+  obi ledger verify [--fix]     Check the results log for corrupt or inconsistent entries; --fix repairs them
+  obi --version [--verbose]     Print the version; --verbose adds build and tool details for bug reports
+
+Global options (any command):
+  --log-level debug|info|warn|error
+                                Level of obi's own diagnostics (default warn; $OBI_LOG_LEVEL)
+  --log-file path               Append diagnostics to this file instead of stderr ($OBI_LOG_FILE)
+
+Run "obi <command> --help" for command options.`
 
 // Run is the top-level entrypoint for the obi CLI.
 func Run(args []string) error {
+	defer tui.RecoverPanic()
+	args, closeLog, err := configureLogging(args)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
 	if len(args) == 0 {
 		fmt.Println(usage)
 		return nil
 	}
 
+	err = dispatch(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+func dispatch(args []string) error {
 	switch args[0] {
 	case "go":
 		return runGo(args[1:])
@@ -43,6 +94,42 @@ func Run(args []string) error {
 		return runList(args[1:])
 	case "init":
 		return runInit(args[1:])
+	case "env":
+		return runEnv(args[1:])
+	case "prompt":
+		return runPrompt(args[1:])
+	case "ask":
+		return runAsk(args[1:])
+	case "plan":
+		return runPlan(args[1:])
+	case "report":
+		return runReport(args[1:])
+	case "tail":
+		return runTail(args[1:])
+	case "audit":
+		return runAudit(args[1:])
+	case "status":
+		return runStatus(args[1:])
+	case "history":
+		return runHistory(args[1:])
+	case "diff-runs":
+		return runDiffRuns(args[1:])
+	case "experiment":
+		return runExperiment(args[1:])
+	case "clean":
+		return runClean(args[1:])
+	case "ledger":
+		return runLedger(args[1:])
+	case "alias":
+		return runAlias(args[1:])
+	case "edit":
+		return runEdit(args[1:])
+	case "run":
+		return runRun(args[1:])
+	case "schedule":
+		return runScheduler(args[1:])
+	case "--version":
+		return RunVersion(args[1:])
 	case "help", "-h", "--help":
 		fmt.Println(usage)
 		return nil
@@ -53,10 +140,39 @@ func Run(args []string) error {
 
 type goOptions struct {
 	configPath string
+	profile    string
 	aliasInput string
 	outPath    string
 	resume     bool
+	fresh      bool
 	noTUI      bool
+	dir        string
+	env        []string
+	context    []string
+	note       string
+	ci         bool
+	wait       bool
+	waitEvery  time.Duration
+	waitMax    time.Duration
+	skipDups   bool
+	readOnly   bool
+	yes        bool
+	// checkpoint is the countdown of the menu shown between loop
+	// sessions; 0 continues straight away. See checkpoint.go.
+	checkpoint time.Duration
+	// workspace is the epic alias to run in every repo of the workspace
+	// file; see workspace.go.
+	workspace     string
+	workspaceFile string
+	parallel      bool
+	// continueCodex is the run ID whose Codex session the first session
+	// resumes; see continue.go.
+	continueCodex string
+	// tmux reruns the command in a new tmux window; see tmux.go.
+	tmux bool
+	// simulate is the fakecodex scenario to play instead of running
+	// Codex; see simulate.go.
+	simulate simulateFlag
 }
 
 type sessionOutcome struct {
@@ -70,16 +186,32 @@ func runGo(args []string) error {
 	if err != nil {
 		return err
 	}
-
-	resolvedPath, err := config.ResolvePath(opts.configPath)
-	if err != nil {
-		return err
+	if opts.tmux && os.Getenv(tmuxWindowEnv) == "" {
+		return launchInTmux(args, opts)
+	}
+	if opts.workspace != "" {
+		if opts.simulate != "" {
+			return errors.New("--simulate runs a single session and is not supported with --workspace")
+		}
+		return runWorkspace(opts)
+	}
+	if opts.parallel {
+		return errors.New("--parallel only applies to --workspace runs")
 	}
+	return runGoTarget(opts)
+}
 
-	cfg, err := config.Load(resolvedPath)
+// runGoTarget runs obi go for one config: a single session, an epic loop,
+// or a group.
+func runGoTarget(opts goOptions) error {
+	resolvedPath, cfg, err := loadConfig(opts.configPath, opts.profile)
 	if err != nil {
 		return err
 	}
+	if opts.yes {
+		autoConfirm := false
+		cfg.ConfirmBeforeRun = &autoConfirm
+	}
 
 	repoRoot := repoRootForConfig(resolvedPath)
 	cfgDigest := configDigest(resolvedPath)
@@ -88,6 +220,28 @@ func runGo(args []string) error {
 	if err != nil {
 		return err
 	}
+	if legacy, err := countLegacyLedgerEntries(logPath); err == nil && legacy > 0 {
+		fmt.Fprintf(os.Stderr, "Note: %d results log entries predate schema %s; run `obi ledger migrate` to upgrade them.\n", legacy, ledgerSchemaVersion)
+	}
+
+	if group, ok := groupTarget(opts.aliasInput); ok {
+		if opts.readOnly {
+			return errors.New("--read-only runs a single session and is not supported with group targets")
+		}
+		if opts.simulate != "" {
+			return errors.New("--simulate runs a single session and is not supported with group targets")
+		}
+		if opts.continueCodex != "" {
+			return errors.New("--continue-codex resumes one run and is not supported with group targets")
+		}
+		if err := ensureCleanTree(cfg, repoRoot, resolvedPath); err != nil {
+			return err
+		}
+		if err := ensureScheduleOpen(cfg, time.Now()); err != nil {
+			return err
+		}
+		return runGroupLoop(group, opts, cfg, logPath, repoRoot, cfgDigest)
+	}
 
 	var plan sessionPlan
 
@@ -104,19 +258,78 @@ func runGo(args []string) error {
 		}
 	}
 
-	plan.RepoRoot = repoRoot
+	plan.RepoRoot, plan.Dir = workspaceRepoRoot(repoRoot, plan.Dir)
 	plan.ConfigDigest = cfgDigest
+	if err := applyRunContext(&plan, opts.dir, opts.env); err != nil {
+		return err
+	}
+	if err := loadContextFiles(&plan, opts.context); err != nil {
+		return err
+	}
+	plan.OperatorNote = strings.TrimSpace(opts.note)
+	if opts.simulate != "" {
+		if err := applySimulation(&plan, string(opts.simulate)); err != nil {
+			return err
+		}
+	}
+
+	if opts.continueCodex != "" {
+		if opts.readOnly {
+			return errors.New("--continue-codex and --read-only are mutually exclusive")
+		}
+		if plan.Continue, err = loadCodexContinuation(logPath, plan.EpicID, opts.continueCodex); err != nil {
+			return err
+		}
+	}
 
 	if opts.resume {
 		if err := enableResume(&plan, logPath); err != nil {
 			return err
 		}
+	} else {
+		offerResume(&plan, logPath, opts, !opts.ci && cfg.ConfirmBeforeRunValue(), os.Stdin, os.Stdout)
+	}
+
+	if !opts.readOnly {
+		if err := ensureCleanTree(cfg, gitRunDir(plan), resolvedPath); err != nil {
+			return err
+		}
+		if err := enforceBranchPolicy(cfg, plan); err != nil {
+			return err
+		}
+		if err := ensureScheduleOpen(cfg, time.Now()); err != nil {
+			return err
+		}
+	}
+	if plan.Simulated == "" {
+		maybeSyncBeads(cfg, plan.RepoRoot)
+	}
+
+	if plan.Simulated != "" {
+		// The fake Codex closes no beads, so an epic loop would never end.
+		if opts.readOnly {
+			applyReadOnly(&plan)
+		}
+		if logPath, err = simulateConfig(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("Simulating Codex with the %q fakecodex scenario; the run is logged to %s.\n", plan.Simulated, logPath)
+		_, err := executeSession(plan, opts, cfg, logPath, cfg.ConfirmBeforeRunValue(), !cfg.ConfirmBeforeRunValue())
+		return err
+	}
+	if opts.readOnly {
+		// Exploratory runs close no beads, so an epic loop would never end.
+		applyReadOnly(&plan)
+		plan.CheckDuplicates = true
+		_, err := executeSession(plan, opts, cfg, logPath, cfg.ConfirmBeforeRunValue(), !cfg.ConfirmBeforeRunValue())
+		return err
 	}
 
 	if plan.EpicID == "" || plan.EpicID == "issues" {
 		if err := ensureReadyWork(plan); err != nil {
 			return err
 		}
+		plan.CheckDuplicates = true
 		outcome, err := executeSession(plan, opts, cfg, logPath, cfg.ConfirmBeforeRunValue(), !cfg.ConfirmBeforeRunValue())
 		if err != nil {
 			return err
@@ -131,38 +344,71 @@ func runGo(args []string) error {
 }
 
 func runEpicLoop(plan sessionPlan, opts goOptions, cfg *config.Config, logPath string) error {
+	finished, err := runEpicSessions(plan, opts, cfg, logPath)
+	if err != nil || !finished {
+		return err
+	}
+	return maybeRunSummarizer(plan, opts, cfg, logPath)
+}
+
+// runEpicSessions launches sessions until no ready beads remain (finished)
+// or a session stops the loop early.
+func runEpicSessions(plan sessionPlan, opts goOptions, cfg *config.Config, logPath string) (bool, error) {
 	confirmFirst := cfg.ConfirmBeforeRunValue()
 	autoConfirmNotice := !confirmFirst
 	sessionCount := 0
+	plan.InLoop = true
 
 	for {
-		if sessionCount == 0 {
+		if sessionCount == 0 && plan.Continue != nil {
+			// The bead being continued is usually in progress, not ready.
+			plan.StateBanner = formatEpicState(plan, loadEpicState(plan, logPath), time.Now())
+			fmt.Println(plan.StateBanner)
+		} else if sessionCount == 0 {
+			if opts.wait {
+				if err := waitForReadyWork(plan, opts, cfg); err != nil {
+					return false, err
+				}
+			}
 			if err := ensureReadyWork(plan); err != nil {
-				return err
+				return false, err
 			}
+			plan.StateBanner = formatEpicState(plan, loadEpicState(plan, logPath), time.Now())
+			fmt.Println(plan.StateBanner)
 		} else {
+			maybeSyncBeads(cfg, plan.RepoRoot)
 			hasWork, err := readyWorkAvailable(plan)
 			if err != nil {
-				return err
+				return false, err
 			}
 			if !hasWork {
 				fmt.Printf("No ready beads remain for %s (%s). All done.\n", plan.EpicName, plan.EpicID)
-				if err := maybeRunSummarizer(plan, opts, cfg, logPath); err != nil {
-					return err
-				}
-				return nil
+				return true, nil
+			}
+			if stop, finished, err := loopCheckpoint(&plan, opts, cfg); stop || err != nil {
+				return finished, err
 			}
 			fmt.Printf("\nReady beads remain for %s (%s); launching next session.\n\n", plan.EpicName, plan.EpicID)
 		}
 
+		if sched, err := parseSchedule(cfg.Schedule, time.Local); err == nil {
+			if reason := sched.blocked(time.Now()); reason != "" {
+				fmt.Printf("Stopping the loop for %s: %s ([schedule]).\n", plan.EpicID, reason)
+				return false, nil
+			}
+		}
+
 		fmt.Printf("=== Codex session #%d ===\n\n", sessionCount+1)
+		plan.CheckDuplicates = sessionCount == 0
 
 		outcome, err := executeSession(plan, opts, cfg, logPath, confirmFirst && sessionCount == 0, autoConfirmNotice && sessionCount == 0)
 		if err != nil {
-			return err
+			return false, err
 		}
+		plan.StateBanner = ""
+		plan.Continue = nil
 		if outcome.Status == "" {
-			return nil
+			return false, nil
 		}
 		if bead := strings.TrimSpace(outcome.BeadID); bead != "" {
 			plan.ResumeCompletedBeads = append(plan.ResumeCompletedBeads, bead)
@@ -171,10 +417,55 @@ func runEpicLoop(plan sessionPlan, opts goOptions, cfg *config.Config, logPath s
 	}
 }
 
+// waitForReadyWork polls bd (syncing first when configured) until the epic
+// has ready beads or --wait-timeout passes.
+func waitForReadyWork(plan sessionPlan, opts goOptions, cfg *config.Config) error {
+	first := true
+	poller := readyPoller{
+		check: func() (bool, error) {
+			if !first {
+				maybeSyncBeads(cfg, plan.RepoRoot)
+			}
+			first = false
+			return readyWorkAvailable(plan)
+		},
+		sleep:    time.Sleep,
+		now:      time.Now,
+		interval: opts.waitEvery,
+		timeout:  opts.waitMax,
+		out:      os.Stdout,
+	}
+	return poller.wait(plan.EpicID)
+}
+
+// executeSession runs one session, relaunching it with the next entry of
+// codex.fallback_models while the provider reports the model unavailable.
+// Relaunches skip the confirmation prompt the first attempt already passed.
 func executeSession(plan sessionPlan, opts goOptions, cfg *config.Config, logPath string, requireConfirmation bool, autoConfirmNotice bool) (sessionOutcome, error) {
+	for {
+		outcome, err := runSessionAttempt(plan, opts, cfg, logPath, requireConfirmation, autoConfirmNotice)
+		var fallback *modelFallback
+		if !errors.As(err, &fallback) {
+			return outcome, err
+		}
+		fmt.Printf("\nobi: %v\n", fallback)
+		plan.FallbackFrom = append(plan.FallbackFrom, fallback.model)
+		plan.Codex.Model = fallback.next
+		plan.CheckDuplicates = false
+		requireConfirmation = false
+		autoConfirmNotice = false
+	}
+}
+
+func runSessionAttempt(plan sessionPlan, opts goOptions, cfg *config.Config, logPath string, requireConfirmation bool, autoConfirmNotice bool) (sessionOutcome, error) {
+	loadReadyList(&plan, cfg)
 	promptBody := buildPrompt(plan)
+	style, err := sessionStyle(cfg, plan)
+	if err != nil {
+		return sessionOutcome{}, err
+	}
 	sessionRunner := interactive.NewSessionRunner()
-	preparedPrompt, err := sessionRunner.PreparePrompt(promptBody)
+	preparedPrompt, err := sessionRunner.PreparePrompt(promptBody, style.guidance()...)
 	if err != nil {
 		return sessionOutcome{}, err
 	}
@@ -187,26 +478,94 @@ func executeSession(plan sessionPlan, opts goOptions, cfg *config.Config, logPat
 		fmt.Println()
 	}
 
+	if plan.CheckDuplicates {
+		if dup := findDuplicateRun(logPath, plan.EpicID, promptHash(prompt, preparedPrompt.SessionID), time.Now()); dup != nil {
+			fmt.Println(formatDuplicateWarning(*dup, time.Now()))
+			if opts.skipDups {
+				fmt.Println("Skipping launch (--skip-duplicates).")
+				return sessionOutcome{}, nil
+			}
+			fmt.Println()
+		}
+	}
+
+	secretEnv, secretValues, err := epicSecretEnv(plan.SecretEnv)
+	if err != nil {
+		return sessionOutcome{}, err
+	}
+	secrets := append(redactionSecrets(), secretValues...)
+	auditPath, err := cfg.AuditLogPath()
+	if err != nil {
+		return sessionOutcome{}, err
+	}
+	audit := newAuditLog(auditPath, preparedPrompt.SessionID, plan.EpicID, secrets)
+	webhookLog, err := cfg.WebhookLogPath()
+	if err != nil {
+		return sessionOutcome{}, err
+	}
+	webhooks := newWebhookSender(cfg.Webhooks, webhookLog)
+	attention, err := newAttentionNotifier(cfg.TUI, os.Stdout)
+	if err != nil {
+		return sessionOutcome{}, err
+	}
+	if opts.ci {
+		// Nobody is at the terminal to answer.
+		attention = nil
+	}
+	window := newTmuxStatus(opts, plan, os.Stdout)
+
+	savedPrompt := storedPrompt(prompt, preparedPrompt.SessionID)
 	if requireConfirmation {
+		if prev, prevPrompt, ok := previousPrompt(logPath, plan.EpicID); ok {
+			fmt.Println(formatPromptChanges(prev, prevPrompt, savedPrompt, time.Now()))
+		}
+		attention.alert(attentionConfirm, fmt.Sprintf("confirm the Codex launch for %s", plan.Alias))
+		window.setState("confirm?")
 		ok, err := promptForConfirmation()
 		if err != nil {
 			return sessionOutcome{}, err
 		}
 		if !ok {
+			audit.record(auditDecline, "")
 			fmt.Println("Run cancelled.")
 			return sessionOutcome{}, nil
 		}
+		audit.record(auditApprove, "")
 	} else if autoConfirmNotice {
+		audit.record(auditAutoApprove, "confirm_before_run=false")
 		fmt.Println("confirm_before_run=false; continuing without prompt.")
 	}
 
 	inv, err := codexexec.Build(plan.Codex, prompt)
+	if plan.Continue != nil {
+		fmt.Printf("Continuing Codex session %s from run %s.\n", plan.Continue.CodexSessionID, plan.Continue.RunID)
+		inv, err = codexexec.BuildResume(plan.Codex, plan.Continue.CodexSessionID, prompt)
+	}
 	if err != nil {
 		return sessionOutcome{}, err
 	}
+	codexEnv := mergeEnv(plan.Env, secretEnv)
+	if err := codexexec.CheckEnv(plan.Codex, codexEnv); err != nil {
+		return sessionOutcome{}, err
+	}
+	promptPath := promptCopyPath(logPath, preparedPrompt.SessionID)
+	if err := writePromptCopy(promptPath, savedPrompt); err != nil {
+		fmt.Printf("Warning: %v; the next confirmation can't diff against this prompt.\n", err)
+		promptPath = ""
+	}
 	fmt.Printf("\nLaunching Codex: %s %v\n", inv.Binary, inv.Args)
+	if plan.Dir != "" {
+		fmt.Printf("Working directory: %s\n", plan.Dir)
+	}
+	if len(plan.Env) > 0 {
+		fmt.Printf("Extra environment: %s\n", strings.Join(envKeys(plan.Env), ", "))
+	}
+	if len(secretEnv) > 0 {
+		fmt.Printf("Epic secrets: %s\n", strings.Join(envKeys(secretEnv), ", "))
+	}
 
-	transcript, transcriptPath, err := openTranscriptWriter(logPath, opts.outPath, preparedPrompt.SessionID)
+	transcriptNames := newTranscriptFields(plan, preparedPrompt.SessionID, time.Now())
+	transcript, transcriptPath, err := openTranscriptWriter(logPath, opts.outPath, cfg.TranscriptNameTemplate, transcriptNames)
 	if err != nil {
 		return sessionOutcome{}, err
 	}
@@ -215,15 +574,59 @@ func executeSession(plan sessionPlan, opts goOptions, cfg *config.Config, logPat
 	}
 
 	var teeWriter io.Writer
+	var collapser *interactive.LineCollapser
+	var capped *cappedTranscript
 	if transcript != nil {
-		if locked := newLockedWriter(transcript); locked != nil {
+		var sink io.Writer = transcript
+		if f, ok := transcript.(*os.File); ok && cfg.TranscriptMaxBytes() > 0 {
+			capped = newCappedTranscript(f, cfg.TranscriptMaxBytes())
+			sink = capped
+		}
+		if cfg.TUI.CollapseProgress {
+			collapser = interactive.NewLineCollapser(sink, interactive.DefaultCollapseWindow)
+			sink = collapser
+		}
+		if locked := newLockedWriter(sink); locked != nil {
 			teeWriter = locked
 		}
 	}
+	codexTranscript, operatorTranscript, transcriptFilter, err := transcriptWriters(cfg.Transcript, teeWriter)
+	if err != nil {
+		return sessionOutcome{}, err
+	}
 
-	secrets := redactionSecrets()
-	opLog := newOperatorLog(teeWriter)
+	opLog := newOperatorLog(operatorTranscript)
+	opLog.record(operatorEventNote, plan.OperatorNote)
 	useTUI := !opts.noTUI
+	var tuiSettings sessionTUISettings
+	if useTUI {
+		tuiSettings, err = loadSessionTUISettings(cfg.TUI, os.Getenv("NO_COLOR"))
+		if err != nil {
+			return sessionOutcome{}, err
+		}
+		tuiSettings.transcriptPath = transcriptPath
+		tuiSettings.onBeadClaim = window.setBead
+		tuiSettings.attention = attention
+	}
+	phaseRules, err := sessionPhaseRules(cfg.Phases)
+	if err != nil {
+		return sessionOutcome{}, err
+	}
+	policy, err := newEscalationPolicy(plan.Escalation)
+	if err != nil {
+		return sessionOutcome{}, err
+	}
+	var approvalAlert func(string)
+	if attention.wants(attentionApproval) {
+		approvalAlert = func(command string) {
+			attention.alert(attentionApproval, fmt.Sprintf("Codex asks to run %s (%s)", command, plan.Alias))
+		}
+	}
+	escalations := newEscalationWatcher(policy, opLog, audit).withAlert(policy, opLog, audit, approvalAlert)
+	tee := sessionTee(codexTranscript, opLog)
+	if escalations != nil {
+		tee = io.MultiWriter(tee, escalations)
+	}
 	var sessionStdout io.Writer
 	if useTUI {
 		sessionStdout = io.Discard
@@ -231,30 +634,73 @@ func executeSession(plan sessionPlan, opts goOptions, cfg *config.Config, logPat
 		sessionStdout = os.Stdout
 	}
 
+	gitBefore := captureGitBefore(gitRunDir(plan))
+	var scope *scopeGuard
+	if !plan.ReadOnly {
+		configPath, _ := config.ResolvePath(opts.configPath)
+		scope = newScopeGuard(cfg, gitRunDir(plan), configPath)
+	}
+	if gitBefore != nil {
+		tuiSettings.diffBase = gitBefore.HeadBefore
+	}
+	var environment *runEnvironment
+	if cfg.RecordEnvironment {
+		environment = captureRunEnvironment(inv.Binary, gitRunDir(plan))
+	}
 	handle, err := sessionRunner.Start(context.Background(), interactive.StartOptions{
-		SessionID:  preparedPrompt.SessionID,
-		Prompt:     prompt,
-		Invocation: inv,
-		Stdout:     sessionStdout,
-		Tee:        teeWriter,
-		Secrets:    secrets,
+		SessionID:       preparedPrompt.SessionID,
+		Prompt:          prompt,
+		Invocation:      inv,
+		Stdout:          sessionStdout,
+		Tee:             tee,
+		Secrets:         secrets,
+		RedactLive:      cfg.Redaction.Live,
+		Dir:             plan.Dir,
+		Env:             codexProcessEnv(plan, codexEnv),
+		Unset:           foreignSecrets(cfg, plan.SecretEnv),
+		EventBufferSize: cfg.TUI.EventBuffer,
+		PhaseRules:      phaseRules,
 	})
 	if err != nil {
 		return sessionOutcome{}, newExitError(err.Error())
 	}
+	if notice := degradedNotice(handle); notice != "" {
+		fmt.Fprintf(os.Stderr, "obi: warning: %s\n", notice)
+	}
+	statePath, err := cfg.StateFilePath()
+	if err != nil {
+		return sessionOutcome{}, err
+	}
+	beat := startHeartbeat(statePath, cfg.HeartbeatInterval(), plan, preparedPrompt.SessionID, handle.Progress)
+	defer beat.stop(nil)
+	window.setState("running")
 
 	var sessionView *sessionDisplay
+	// Without a TUI nobody reads the event channel, so drops are expected
+	// and not worth recording.
+	var eventsConsumed bool
 	if useTUI {
-		sessionView, err = startSessionTUI(handle, plan, opLog)
+		sessionView, err = startSessionTUI(handle, plan, opLog, audit, tuiSettings)
 		if err != nil {
 			return sessionOutcome{}, err
 		}
+		eventsConsumed = sessionView != nil
+		if cfg.Redaction.Live && len(secrets) > 0 {
+			sessionView.UpdateStatus(func(line *tui.StatusLine) {
+				line.LiveRedacted = true
+			})
+		}
 	}
 	defer func() {
 		if sessionView != nil {
 			sessionView.Stop()
 		}
 	}()
+	if sessionView != nil {
+		escalations.attach(handle, sessionView.router, sessionView.notifyEvent)
+	} else {
+		escalations.attach(handle, nil, nil)
+	}
 
 	sigCh := make(chan os.Signal, 2)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
@@ -262,43 +708,167 @@ func executeSession(plan sessionPlan, opts goOptions, cfg *config.Config, logPat
 	if useTUI {
 		signalWriter = io.Discard
 	}
-	stopRelay := startSignalRelay(handle, sigCh, signalWriter)
+	stopRelay := startSignalRelay(auditedSignals{signalSession: handle, audit: audit}, sigCh, signalWriter)
 	defer func() {
 		stopRelay()
 		signal.Stop(sigCh)
 		close(sigCh)
 	}()
+	if !plan.ReadOnly {
+		// Validated before launch, so the parse cannot fail here.
+		sched, _ := parseSchedule(cfg.Schedule, time.Local)
+		defer watchSchedule(sched, auditedSignals{signalSession: handle, audit: audit}, time.Now())()
+	}
+	stopScope := scope.watch(auditedSignals{signalSession: handle, audit: audit}, scopeCheckInterval)
 
 	runRes, err := handle.Wait()
+	stopScope()
 	if err != nil {
 		return sessionOutcome{}, newExitError(err.Error())
 	}
+	attention.alert(attentionExit, fmt.Sprintf("Codex exited with status %d (%s)", runRes.ExitCode, plan.Alias))
+	window.setState("exited")
+	beat.stop(&runRes.ExitCode)
+	if err := transcriptFilter.Flush(); err != nil {
+		diag.Logger().Warn("transcript flush failed", "path", transcriptPath, "err", err)
+	}
+	if collapser != nil {
+		if err := collapser.Flush(); err != nil {
+			diag.Logger().Warn("transcript flush failed", "path", transcriptPath, "err", err)
+		}
+	}
 
-	fencedRes, err := parseFencedReport(preparedPrompt.SessionID, runRes.Output)
-	if err != nil {
-		return sessionOutcome{}, newExitError(fmt.Sprintf("parse fenced report: %v", err))
+	opLog.finishAcks()
+	fencedRes, reportErr := parseFencedReport(preparedPrompt.SessionID, runRes.Output)
+	// Fields known once the process exits; the report-derived ones are
+	// filled in below. Parse failures still log this much as "unparsed".
+	entry := ledgerEntry{
+		RunID:          preparedPrompt.SessionID,
+		SessionID:      preparedPrompt.SessionID,
+		RepoRoot:       plan.RepoRoot,
+		EpicID:         plan.EpicID,
+		EpicKey:        plan.EpicKey,
+		EpicName:       plan.EpicName,
+		Alias:          plan.Alias,
+		StartedAt:      runRes.StartedAt,
+		CompletedAt:    runRes.CompletedAt,
+		ExitCode:       runRes.ExitCode,
+		TranscriptPath: transcriptPath,
+		CodexBinary:    inv.Binary,
+		CodexModel:     plan.Codex.Model,
+		CodexSandbox:   plan.Codex.Sandbox,
+		CodexApproval:  plan.Codex.Approval,
+		CodexExtraArgs: append([]string(nil), plan.Codex.ExtraArgs...),
+		FallbackFrom:   append([]string(nil), plan.FallbackFrom...),
+		ConfigDigest:   plan.ConfigDigest,
+		PromptHash:     promptHash(prompt, preparedPrompt.SessionID),
+		PromptPath:     promptPath,
+		Exploratory:    plan.ReadOnly,
+		OperatorEvents: opLog.ledgerEvents(secrets),
+		DroppedEvents:  droppedEventCounts(runRes.DroppedEvents, eventsConsumed),
+		PhaseDurations: phaseDurationMillis(runRes.PhaseDurations),
+		TokensUsed:     parseTokensUsed(runRes.Output),
+		CodexSessionID: parseCodexSessionID(runRes.Output, preparedPrompt.SessionID),
+		Profile:        cfg.ActiveProfile,
+		Git:            gitBefore.finish(gitRunDir(plan)),
+		Environment:    environment,
+	}
+	if rate := cfg.TUI.UsdPerMTok; rate > 0 && entry.TokensUsed > 0 {
+		entry.CostUSD = float64(entry.TokensUsed) / 1e6 * rate
+	}
+	entry.ScopeViolations = scope.recorded()
+	if runRes.ExitCode != 0 {
+		entry.FailureKind = classifyCodexFailure(runRes.ExitCode, runRes.Signal, runRes.Output)
+	}
+	if plan.Mode == sessionModeTemplate {
+		entry.Template = plan.Alias
+	}
+	entry.Simulated = plan.Simulated
+	if arm := plan.Experiment; arm != nil {
+		entry.Experiment, entry.Variant = arm.ID, arm.Variant
+	}
+	entry.Degraded = runRes.Degraded
+	if fallback := fallbackFor(plan, runRes.ExitCode, handle.Aborted(), runRes.Output, reportErr); fallback != nil {
+		// Log the failed attempt so the relaunch is not the only trace.
+		entry.Status = ledgerStatusUnparsed
+		entry.ParseError = fmt.Sprintf("parse fenced report: %v", reportErr)
+		entry.Escalation = fallback.Error()
+		if err := appendLedgerEntry(logPath, entry); err != nil {
+			return sessionOutcome{}, err
+		}
+		return sessionOutcome{}, fallback
+	}
+	if handle.Aborted() && strings.TrimSpace(cfg.Guardrails.AbortCleanup) != "" {
+		if sessionView != nil {
+			sessionView.Stop()
+			sessionView = nil
+		}
+		entry.AbortCleanup = offerAbortCleanup(cfg.Guardrails, plan, scope, opts.ci, os.Stdin, os.Stdout)
+	}
+	if plan.Continue != nil {
+		entry.ContinuedFrom = plan.Continue.RunID
+		entry.AttemptGroup = plan.Continue.AttemptGroup
+	}
+	if omitted := capped.Omitted(); omitted > 0 {
+		entry.TranscriptOmitted = omitted
+		fmt.Fprintf(os.Stderr, "obi: transcript exceeded transcript_max_mb; %d bytes were cut from the middle of %s\n", omitted, transcriptPath)
+	}
+
+	if reportErr != nil {
+		attention.alert(attentionNeedsHelp, fmt.Sprintf("no fenced report from Codex (%s)", plan.Alias))
+		if sessionView != nil {
+			sessionView.Stop()
+			sessionView = nil
+		}
+		collectArtifacts(cfg, plan, logPath, &entry)
+		return sessionOutcome{}, recordUnparsedRun(logPath, entry, plan, webhooks, runRes.Output, fmt.Sprintf("parse fenced report: %v", reportErr))
 	}
 
 	footerRes, err := footer.Parse(runRes.Output)
 	if err != nil {
-		return sessionOutcome{}, newExitError(fmt.Sprintf("parse footer: %v", err))
+		attention.alert(attentionNeedsHelp, fmt.Sprintf("no footer from Codex (%s)", plan.Alias))
+		if sessionView != nil {
+			sessionView.Stop()
+			sessionView = nil
+		}
+		collectArtifacts(cfg, plan, logPath, &entry)
+		return sessionOutcome{}, recordUnparsedRun(logPath, entry, plan, webhooks, runRes.Output, fmt.Sprintf("parse footer: %v", err))
 	}
 
-	if !strings.EqualFold(fencedRes.Status, footerRes.Status) {
-		return sessionOutcome{}, newExitError("fenced report status does not match legacy footer")
-	}
-	if normalizeMultiline(fencedRes.Details) != normalizeMultiline(footerRes.CommitMsg) {
-		return sessionOutcome{}, newExitError("fenced report details do not match legacy footer commit body")
-	}
-	if normalizeWhitespace(fencedRes.Escalation) != normalizeWhitespace(footerRes.Escalation) {
-		return sessionOutcome{}, newExitError("fenced report escalation does not match legacy footer")
+	var conflict *reportConflict
+	if fields := reportMismatches(fencedRes, footerRes); len(fields) > 0 {
+		if opts.ci {
+			return sessionOutcome{}, newExitError(reportMismatchMessage(fields[0]))
+		}
+		if sessionView != nil {
+			sessionView.Stop()
+			sessionView = nil
+		}
+		chosen, source, err := resolveReportConflict(fencedRes, footerRes, fields, os.Stdin, os.Stdout)
+		if err != nil {
+			return sessionOutcome{}, err
+		}
+		conflict = &reportConflict{Fields: fields, Chosen: source, FencedStatus: fencedRes.Status, FooterStatus: footerRes.Status}
+		audit.record(auditReportPick, fmt.Sprintf("kept %s version (%s differed)", source, strings.Join(fields, ", ")))
+		fencedRes = chosen
 	}
 
+	scannedSummary, summaryFindings := scrubDetectedSecrets(fencedRes.CommitMsg)
+	scannedDetails, detailsFindings := scrubDetectedSecrets(fencedRes.Details)
+	scannedEscalation, escalationFindings := scrubDetectedSecrets(strings.TrimSpace(fencedRes.Escalation))
+	findings := append(append(summaryFindings, detailsFindings...), escalationFindings...)
+
 	fmt.Printf("\nCodex status: %s\n", fencedRes.Status)
-	fmt.Printf("Commit summary: %s\n", fencedRes.CommitMsg)
-	fmt.Printf("Details:\n%s\n", fencedRes.Details)
-	if fencedRes.Escalation != "" {
-		fmt.Printf("Escalation: %s\n", fencedRes.Escalation)
+	if entry.CodexSessionID != "" {
+		fmt.Printf("Codex session: %s (codex resume %s)\n", entry.CodexSessionID, entry.CodexSessionID)
+	}
+	fmt.Printf("Commit summary: %s\n", scannedSummary)
+	fmt.Printf("Details:\n%s\n", scannedDetails)
+	if scannedEscalation != "" {
+		fmt.Printf("Escalation: %s\n", scannedEscalation)
+	}
+	if len(findings) > 0 {
+		fmt.Printf("Warning: possible secrets detected in the report (%s); redacted before logging. Review the commit message before pushing.\n", strings.Join(findingKinds(findings), ", "))
 	}
 
 	beadID := detectBeadID(plan, runRes.Output, fencedRes.Details, fencedRes.CommitMsg, footerRes.CommitMsg)
@@ -316,150 +886,196 @@ func executeSession(plan sessionPlan, opts goOptions, cfg *config.Config, logPat
 		sessionView = nil
 	}
 
-	redactedSummary, summaryRedacted := redactText(fencedRes.CommitMsg, secrets)
-	redactedDetails, detailsRedacted := redactText(fencedRes.Details, secrets)
-	redactedEscalation, escalationRedacted := redactText(strings.TrimSpace(fencedRes.Escalation), secrets)
-	redactionsApplied := summaryRedacted || detailsRedacted || escalationRedacted
+	redactedSummary, summaryRedacted := redactText(scannedSummary, secrets)
+	redactedDetails, detailsRedacted := redactText(scannedDetails, secrets)
+	redactedEscalation, escalationRedacted := redactText(scannedEscalation, secrets)
+	redactionsApplied := summaryRedacted || detailsRedacted || escalationRedacted || len(findings) > 0
+
+	status := fencedRes.Status
+	styleViolations := style.violations(redactedSummary)
+	styleFailed := false
+	if len(styleViolations) > 0 {
+		fmt.Printf("Warning: %s (see [style]).\n", strings.Join(styleViolations, "; "))
+		if style.fail && strings.EqualFold(status, footer.StatusSuccess) {
+			styleFailed = true
+			status = footer.StatusFailure
+			redactedEscalation = fmt.Sprintf("commit summary violates [style]: %s", strings.Join(styleViolations, "; "))
+			fmt.Printf("style.enforce = %q; treating the run as %s.\n", config.StyleFail, status)
+		}
+	}
+	var verification *verificationResult
+	if plan.VerifyCommand != "" && strings.EqualFold(status, footer.StatusSuccess) && runRes.ExitCode == 0 {
+		fmt.Printf("\nVerifying: %s\n", plan.VerifyCommand)
+		verifyRes, err := runVerification(plan.VerifyCommand, plan.RepoRoot, codexEnv, foreignSecrets(cfg, plan.SecretEnv), secrets, verifyOutputPath(logPath, preparedPrompt.SessionID))
+		if err != nil {
+			return sessionOutcome{}, err
+		}
+		verification = &verifyRes
+		if verifyRes.Passed {
+			fmt.Printf("Verification passed (output: %s)\n", verifyRes.OutputPath)
+		} else {
+			status = footer.StatusFailure
+			redactedEscalation = fmt.Sprintf("verify.command failed with exit code %d after Codex reported success; output: %s", verifyRes.ExitCode, verifyRes.OutputPath)
+			fmt.Printf("Verification failed (exit %d); treating the run as %s. Output: %s\n", verifyRes.ExitCode, status, verifyRes.OutputPath)
+		}
+	}
+	scopeViolations := entry.ScopeViolations
+	if len(scopeViolations) > 0 {
+		fmt.Printf("Warning: Codex changed files outside its allowed scope: %s.\n", strings.Join(scopeViolations, ", "))
+		if strings.EqualFold(status, footer.StatusSuccess) {
+			status = footer.StatusFailure
+			redactedEscalation = "changed files outside [guardrails] allowed_paths/forbidden_paths: " + strings.Join(scopeViolations, ", ")
+		}
+	}
+	branchErr := branchLanded(gitRunDir(plan), plan.Branch, entry.Git)
+	if branchErr != nil {
+		fmt.Printf("Warning: %v.\n", branchErr)
+		if strings.EqualFold(status, footer.StatusSuccess) {
+			status = footer.StatusFailure
+			redactedEscalation = branchErr.Error()
+		}
+	}
 
-	entryPromptHash := promptHash(prompt)
+	if plan.Mode == sessionModeSummary && cfg.Summary.Review && strings.EqualFold(status, footer.StatusSuccess) {
+		if opts.ci {
+			fmt.Println("Skipping [summary] review in --ci mode; recording Codex's summary as is.")
+		} else {
+			fmt.Printf("\nOpening the omnibus summary in %s for review...\n", operatorEditor())
+			raw := summaryDraft{CommitSummary: redactedSummary, CommitDetails: redactedDetails}
+			edited, changed, err := reviewSummary(raw, runOperatorEditor)
+			switch {
+			case err != nil:
+				fmt.Fprintf(os.Stderr, "obi: summary review failed (%v); recording Codex's summary as is.\n", err)
+			case changed:
+				redactedSummary, _ = redactText(edited.CommitSummary, secrets)
+				redactedDetails, _ = redactText(edited.CommitDetails, secrets)
+				entry.SummaryRaw = &raw
+				fmt.Println("Recording the edited summary; Codex's version is kept as summary_raw.")
+			default:
+				fmt.Println("Summary unchanged.")
+			}
+		}
+	}
 
-	entry := ledgerEntry{
-		RunID:          preparedPrompt.SessionID,
-		SessionID:      preparedPrompt.SessionID,
-		RepoRoot:       plan.RepoRoot,
-		EpicID:         plan.EpicID,
-		EpicKey:        plan.EpicKey,
-		EpicName:       plan.EpicName,
-		Alias:          plan.Alias,
-		Status:         fencedRes.Status,
-		CommitSummary:  redactedSummary,
-		CommitDetails:  redactedDetails,
-		Escalation:     redactedEscalation,
-		StartedAt:      runRes.StartedAt,
-		CompletedAt:    runRes.CompletedAt,
-		ExitCode:       runRes.ExitCode,
-		TranscriptPath: transcriptPath,
-		BeadID:         beadID,
-		CodexBinary:    inv.Binary,
-		CodexModel:     plan.Codex.Model,
-		CodexSandbox:   plan.Codex.Sandbox,
-		CodexApproval:  plan.Codex.Approval,
-		CodexExtraArgs: append([]string(nil), plan.Codex.ExtraArgs...),
-		ConfigDigest:   plan.ConfigDigest,
-		PromptHash:     entryPromptHash,
-		Redacted:       redactionsApplied,
-		OperatorEvents: opLog.ledgerEvents(secrets),
+	if !strings.EqualFold(status, footer.StatusSuccess) {
+		attention.alert(attentionNeedsHelp, fmt.Sprintf("%s needs help: %s", plan.Alias, firstLine(redactedEscalation)))
+	}
+	if beadID != "" {
+		window.setBead(beadID)
+	}
+	window.setState(status)
+	entry.Status = status
+	entry.CommitSummary = redactedSummary
+	applyCommitParts(&entry, redactedSummary)
+	entry.CommitDetails = redactedDetails
+	entry.Escalation = redactedEscalation
+	entry.BeadID = beadID
+	entry.Redacted = redactionsApplied
+	entry.Verification = verification
+	entry.StyleViolations = styleViolations
+	entry.ReportConflict = conflict
+	if opts.outPath == "" && beadID != transcriptNames.BeadID {
+		// The bead is only known now; move a templated transcript that
+		// names it.
+		transcriptNames.BeadID = beadID
+		if renamed, err := renameTranscript(logPath, transcriptPath, cfg.TranscriptNameTemplate, transcriptNames); err != nil {
+			fmt.Fprintf(os.Stderr, "obi: %v; transcript left at %s\n", err, transcriptPath)
+		} else {
+			entry.TranscriptPath = renamed
+		}
+	}
+	if plan.Mode == sessionModeSummary && strings.EqualFold(status, footer.StatusSuccess) && runRes.ExitCode == 0 {
+		entry.PullRequestURL = openPullRequest(cfg, plan, entry, logPath)
 	}
+	collectArtifacts(cfg, plan, logPath, &entry)
 	if err := appendLedgerEntry(logPath, entry); err != nil {
 		return sessionOutcome{}, err
 	}
+	webhooks.deliver(entry)
+	notifyPullRequest(cfg, entry)
+	printExitBanner(os.Stdout, plan, entry)
 
-	if footerRes.Status == footer.StatusFailure {
+	if strings.EqualFold(fencedRes.Status, footer.StatusFailure) {
 		return sessionOutcome{}, newExitError("Codex requested escalation; stopping.")
 	}
+	if styleFailed {
+		return sessionOutcome{}, newExitError("Commit summary violates [style] after Codex reported success; stopping.")
+	}
+	if verification != nil && !verification.Passed {
+		return sessionOutcome{}, newExitError("Verification failed after Codex reported success; stopping.")
+	}
+	if branchErr != nil {
+		return sessionOutcome{}, newExitError(fmt.Sprintf("Session left epic branch %s; stopping.", plan.Branch))
+	}
+	if len(scopeViolations) > 0 {
+		return sessionOutcome{}, newExitError("Session changed files outside [guardrails] allowed_paths/forbidden_paths; stopping.")
+	}
 
 	if runRes.ExitCode != 0 {
-		return sessionOutcome{}, newExitError(fmt.Sprintf("codex exited with status %d", runRes.ExitCode))
+		return sessionOutcome{}, newExitError(codexExitMessage(runRes.ExitCode, entry.FailureKind))
 	}
 
-	return sessionOutcome{Status: fencedRes.Status, BeadID: beadID}, nil
+	return sessionOutcome{Status: status, BeadID: beadID}, nil
 }
 
 func parseGoOptions(args []string) (goOptions, error) {
-	fs := flag.NewFlagSet("go", flag.ContinueOnError)
-	fs.SetOutput(io.Discard)
+	fs := newCommandFlags("go", "obi go [alias] [options]",
+		"Preview and run Codex sessions for an epic alias or ID, or every epic in a group\nwith group:<name>. Without an alias, works the \"issues outside epics\" block.", "alias")
 
 	var opts goOptions
 	fs.StringVar(&opts.configPath, "config", "", "path to obi config")
+	fs.StringVar(&opts.profile, "profile", "", "apply this [profiles.*] overlay (defaults to $OBI_PROFILE)")
 	fs.StringVar(&opts.outPath, "out", "", "tee codex stdout/stderr to this file")
+	fs.StringVar(&opts.outPath, "o", "", "shorthand for --out")
 	fs.BoolVar(&opts.resume, "resume", false, "skip beads already logged as success for this epic")
+	fs.BoolVar(&opts.fresh, "fresh", false, "ignore beads already logged as success instead of offering to resume")
 	fs.BoolVar(&opts.noTUI, "no-tui", false, "disable the interactive TUI (stream raw Codex output)")
-
-	normalized, alias, err := splitAliasAndArgs(args)
+	fs.StringVar(&opts.dir, "dir", "", "run Codex in this directory (overrides the epic's dir)")
+	fs.Var((*envFlag)(&opts.env), "env", "set KEY=VAL in the Codex environment (repeatable; overrides the epic's env)")
+	fs.Var((*contextFlag)(&opts.context), "context", "append this file to the prompt (repeatable; adds to the epic's context_files)")
+	fs.StringVar(&opts.note, "note", "", "add an operator note for this run to the prompt, e.g. \"Focus on the API layer first\"")
+	fs.BoolVar(&opts.ci, "ci", false, "non-interactive mode: fail when the fenced report and legacy footer disagree instead of prompting")
+	fs.BoolVar(&opts.wait, "wait", false, "poll bd until the epic has ready beads instead of exiting")
+	fs.DurationVar(&opts.waitEvery, "wait-interval", defaultReadyWaitInterval, "initial poll interval for --wait (backs off up to 5m)")
+	fs.DurationVar(&opts.waitMax, "wait-timeout", defaultReadyWaitTimeout, "give up --wait after this long (0 waits indefinitely)")
+	fs.DurationVar(&opts.checkpoint, "checkpoint", 0, "between loop sessions, offer continue/skip/break/stop and continue after this countdown (e.g. 30s)")
+	fs.BoolVar(&opts.skipDups, "skip-duplicates", false, "don't launch when a recent successful run used the identical prompt")
+	fs.BoolVar(&opts.readOnly, "read-only", false, "run one exploratory session in the read-only sandbox; the ledger entry never counts toward --resume")
+	fs.BoolVar(&opts.yes, "yes", false, "launch without the confirmation prompt, as with confirm_before_run = false")
+	fs.StringVar(&opts.workspace, "workspace", "", "run this epic alias in every repo listed in workspace.toml")
+	fs.StringVar(&opts.workspaceFile, "workspace-file", "", "path to workspace.toml (defaults to $OBI_WORKSPACE, then the nearest)")
+	fs.BoolVar(&opts.parallel, "parallel", false, "with --workspace, run the repos at once, each in a new git worktree")
+	fs.StringVar(&opts.continueCodex, "continue-codex", "", "resume the Codex session of this earlier run ID (e.g. after answering a needs_help)")
+	fs.Var(&opts.simulate, "simulate", "play a built-in fake Codex scenario (--simulate=needs_help; default success) instead of running Codex; logged under simulate/")
+	fs.BoolVar(&opts.tmux, "tmux", false, "run in a new tmux window named after the target whose name tracks the session state")
+
+	positional, err := fs.parse(args)
 	if err != nil {
 		return goOptions{}, err
 	}
-
-	if err := fs.Parse(normalized); err != nil {
-		return goOptions{}, fmt.Errorf("parse flags: %w", err)
+	opts.aliasInput = positionalArg(positional, 0)
+	if opts.resume && opts.fresh {
+		return goOptions{}, errors.New("--resume and --fresh are mutually exclusive")
 	}
 
-	opts.aliasInput = alias
-
 	return opts, nil
 }
 
-func splitAliasAndArgs(args []string) ([]string, string, error) {
-	var alias string
-	var normalized []string
-	iter := newArgIterator(args)
-	for iter.Next() {
-		arg := iter.Value()
-		if arg == "--" {
-			if !iter.Next() {
-				break
-			}
-			if alias != "" {
-				return nil, "", fmt.Errorf("unexpected extra arguments: %s", iter.Value())
-			}
-			alias = iter.Value()
-			if iter.Next() {
-				return nil, "", fmt.Errorf("unexpected extra arguments: %s", iter.Value())
-			}
-			break
-		}
-		if strings.HasPrefix(arg, "-") {
-			normalized = append(normalized, arg)
-			if consumesValue(arg) && !strings.Contains(arg, "=") {
-				if !iter.Next() {
-					return nil, "", fmt.Errorf("flag %s requires a value", flagName(arg))
-				}
-				normalized = append(normalized, iter.Value())
-			}
-			continue
-		}
-		if alias == "" {
-			alias = arg
-			continue
-		}
-		return nil, "", fmt.Errorf("unexpected extra arguments: %s", arg)
+// loadConfig resolves and loads obi.toml, then merges the selected profile
+// (flag first, then OBI_PROFILE) so every later step sees the overlay.
+func loadConfig(configPath, profile string) (string, *config.Config, error) {
+	resolved, err := config.ResolvePath(configPath)
+	if err != nil {
+		return "", nil, err
 	}
-	return normalized, alias, nil
-}
-
-func consumesValue(flag string) bool {
-	flag = flagName(flag)
-	switch flag {
-	case "-o", "--out", "--config":
-		return true
-	default:
-		return false
+	cfg, err := config.Load(resolved)
+	if err != nil {
+		return "", nil, err
 	}
-}
-
-func flagName(flag string) string {
-	if idx := strings.Index(flag, "="); idx != -1 {
-		return flag[:idx]
+	if err := cfg.ApplyProfile(config.ResolveProfile(profile)); err != nil {
+		return "", nil, err
 	}
-	return flag
-}
-
-type argIterator struct {
-	args []string
-	i    int
-}
-
-func newArgIterator(args []string) *argIterator {
-	return &argIterator{args: args, i: -1}
-}
-
-func (it *argIterator) Next() bool {
-	it.i++
-	return it.i < len(it.args)
-}
-
-func (it *argIterator) Value() string {
-	return it.args[it.i]
+	return resolved, cfg, nil
 }
 
 func planFromIssues(cfg *config.Config) sessionPlan {
@@ -472,6 +1088,7 @@ func planFromIssues(cfg *config.Config) sessionPlan {
 		EpicPrompt: cfg.Issues.Prompt,
 		BasePrompt: cfg.BasePrompt,
 		Codex:      cfg.Codex,
+		Escalation: cfg.Escalation,
 	}
 }
 
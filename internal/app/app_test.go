@@ -3,46 +3,36 @@ package app
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
 )
 
-func TestSplitAliasAndArgsHandlesFlagsWithValues(t *testing.T) {
-	args := []string{"scope-engine", "--out", "log.txt", "--config", "obi.toml"}
-	normalized, alias, err := splitAliasAndArgs(args)
+func TestParseGoOptionsHandlesFlagsWithValues(t *testing.T) {
+	opts, err := parseGoOptions([]string{"scope-engine", "--out", "log.txt", "--config", "obi.toml"})
 	if err != nil {
-		t.Fatalf("splitAliasAndArgs: %v", err)
-	}
-	if alias != "scope-engine" {
-		t.Fatalf("expected alias scope-engine, got %q", alias)
+		t.Fatalf("parseGoOptions: %v", err)
 	}
-	want := []string{"--out", "log.txt", "--config", "obi.toml"}
-	if len(normalized) != len(want) {
-		t.Fatalf("unexpected normalized args: %v", normalized)
+	if opts.aliasInput != "scope-engine" {
+		t.Fatalf("expected alias scope-engine, got %q", opts.aliasInput)
 	}
-	for i, val := range want {
-		if normalized[i] != val {
-			t.Fatalf("normalized[%d]=%q want %q", i, normalized[i], val)
-		}
+	if opts.outPath != "log.txt" || opts.configPath != "obi.toml" {
+		t.Fatalf("unexpected options: %+v", opts)
 	}
 }
 
-func TestSplitAliasAndArgsSupportsFlagEqualsSyntax(t *testing.T) {
-	args := []string{"--out=log.txt", "scope-engine"}
-	normalized, alias, err := splitAliasAndArgs(args)
+func TestParseGoOptionsSupportsFlagEqualsSyntax(t *testing.T) {
+	opts, err := parseGoOptions([]string{"--out=log.txt", "scope-engine"})
 	if err != nil {
-		t.Fatalf("splitAliasAndArgs: %v", err)
-	}
-	if alias != "scope-engine" {
-		t.Fatalf("expected alias scope-engine, got %q", alias)
+		t.Fatalf("parseGoOptions: %v", err)
 	}
-	if len(normalized) != 1 || normalized[0] != "--out=log.txt" {
-		t.Fatalf("unexpected normalized args: %v", normalized)
+	if opts.aliasInput != "scope-engine" || opts.outPath != "log.txt" {
+		t.Fatalf("unexpected options: %+v", opts)
 	}
 }
 
-func TestSplitAliasAndArgsRejectsMultipleTargets(t *testing.T) {
-	if _, _, err := splitAliasAndArgs([]string{"one", "two"}); err == nil {
+func TestParseGoOptionsRejectsMultipleTargets(t *testing.T) {
+	if _, err := parseGoOptions([]string{"one", "two"}); err == nil {
 		t.Fatalf("expected error for extra positional arguments")
 	}
 }
@@ -145,3 +135,20 @@ func TestParseGoOptionsRecognizesNoTUIFlag(t *testing.T) {
 		t.Fatalf("expected alias passthrough, got %s", opts.aliasInput)
 	}
 }
+
+func TestParseGoOptionsWaitFlags(t *testing.T) {
+	opts, err := parseGoOptions([]string{"alpha", "--wait", "--wait-interval", "1m", "--wait-timeout=0"})
+	if err != nil {
+		t.Fatalf("parseGoOptions: %v", err)
+	}
+	if !opts.wait || opts.waitEvery != time.Minute || opts.waitMax != 0 || opts.aliasInput != "alpha" {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+	defaults, err := parseGoOptions([]string{"alpha"})
+	if err != nil {
+		t.Fatalf("parseGoOptions: %v", err)
+	}
+	if defaults.wait || defaults.waitEvery != defaultReadyWaitInterval || defaults.waitMax != defaultReadyWaitTimeout {
+		t.Fatalf("unexpected defaults: %+v", defaults)
+	}
+}
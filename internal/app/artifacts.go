@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+)
+
+// artifactsDirFor returns where per-run artifact copies live next to the
+// results log, one directory per session.
+func artifactsDirFor(logPath string) string {
+	return filepath.Join(filepath.Dir(logPath), "artifacts")
+}
+
+// collectArtifacts copies the [artifacts] files a run left in its working
+// directory and records them on entry. Read-only and summary sessions
+// build nothing, so there is nothing to keep. Problems only warn: the run
+// itself is already over.
+func collectArtifacts(cfg *config.Config, plan sessionPlan, logPath string, entry *ledgerEntry) {
+	if len(cfg.Artifacts.Paths) == 0 || plan.ReadOnly || plan.Mode == sessionModeSummary {
+		return
+	}
+	dest := filepath.Join(artifactsDirFor(logPath), sanitizeFilename(entry.SessionID))
+	copied, warnings := copyArtifacts(gitRunDir(plan), dest, cfg.Artifacts.Paths, cfg.Artifacts.MaxBytes())
+	for _, warning := range warnings {
+		diag.Logger().Warn("artifact not copied", "detail", warning)
+	}
+	if len(copied) == 0 {
+		fmt.Println("Artifacts: nothing matched [artifacts] paths.")
+		return
+	}
+	entry.ArtifactsDir = dest
+	entry.Artifacts = copied
+	fmt.Printf("Artifacts: copied %d file(s) to %s\n", len(copied), dest)
+}
+
+// copyArtifacts copies the regular files matching patterns under src into
+// dest at the same relative paths, stopping at budget bytes. Symlinks and
+// .git directories are skipped, and patterns may not leave src, not even
+// through a symlinked directory. It returns the copied paths, relative to
+// both directories, and any warnings.
+func copyArtifacts(src, dest string, patterns []string, budget int64) ([]string, []string) {
+	var copied, warnings []string
+	realSrc, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("resolve the run directory: %v", err)}
+	}
+	seen := map[string]bool{}
+	var total int64
+	for _, pattern := range patterns {
+		clean := filepath.Clean(strings.TrimSpace(pattern))
+		if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			warnings = append(warnings, fmt.Sprintf("%q points outside the run directory; skipped", pattern))
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(src, clean))
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%q: %v", pattern, err))
+			continue
+		}
+		for _, match := range matches {
+			// Glob follows symlinked directories on the way to a match.
+			if real, err := filepath.EvalSymlinks(match); err != nil || !pathWithin(realSrc, real) {
+				rel, _ := filepath.Rel(src, match)
+				warnings = append(warnings, fmt.Sprintf("%s resolves outside the run directory; skipped", filepath.ToSlash(rel)))
+				continue
+			}
+			err := filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() && d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				if !d.Type().IsRegular() {
+					return nil
+				}
+				rel, err := filepath.Rel(src, path)
+				if err != nil || seen[rel] {
+					return err
+				}
+				seen[rel] = true
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				if total+info.Size() > budget {
+					warnings = append(warnings, fmt.Sprintf("%s skipped: the run reached max_mb (%s)", rel, formatBytes(budget)))
+					return nil
+				}
+				if err := copyArtifact(path, filepath.Join(dest, rel)); err != nil {
+					return err
+				}
+				total += info.Size()
+				copied = append(copied, filepath.ToSlash(rel))
+				return nil
+			})
+			if err != nil {
+				warnings = append(warnings, err.Error())
+			}
+		}
+	}
+	return copied, warnings
+}
+
+// pathWithin reports whether path is dir or lies beneath it.
+func pathWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func copyArtifact(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0o700); err != nil {
+		return fmt.Errorf("create artifacts dir: %w", err)
+	}
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(to, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("copy %s: %w", from, err)
+	}
+	return out.Close()
+}
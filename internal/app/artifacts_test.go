@@ -0,0 +1,91 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopyArtifactsGlobsDirectoriesAndBudget(t *testing.T) {
+	src := t.TempDir()
+	dest := filepath.Join(t.TempDir(), "artifacts", "run-1")
+	write := func(rel, body string) {
+		t.Helper()
+		path := filepath.Join(src, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+	write("coverage.out", "mode: set\n")
+	write("build/unit.log", "ok\n")
+	write("build/lint.log", "clean\n")
+	write("build/notes.txt", "not a log\n")
+	write("report/index.html", "<html></html>\n")
+	write("report/.git/HEAD", "ref\n")
+	write("big.bin", strings.Repeat("x", 64))
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.log"), []byte("secret\n"), 0o600); err != nil {
+		t.Fatalf("write outside: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(src, "linked")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	patterns := []string{"coverage.out", "build/*.log", "report", "../outside", "coverage.out", "big.bin", "linked/*.log"}
+	copied, warnings := copyArtifacts(src, dest, patterns, 40)
+	if got := strings.Join(copied, ","); got != "coverage.out,build/lint.log,build/unit.log,report/index.html" {
+		t.Fatalf("copied = %q", got)
+	}
+	if len(warnings) != 3 || !strings.Contains(warnings[0], "outside the run directory") || !strings.Contains(warnings[1], "big.bin skipped") ||
+		warnings[2] != "linked/secret.log resolves outside the run directory; skipped" {
+		t.Fatalf("warnings = %q", warnings)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "build", "unit.log"))
+	if err != nil || string(data) != "ok\n" {
+		t.Fatalf("copied log = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "report", ".git")); !os.IsNotExist(err) {
+		t.Fatalf(".git must not be copied: %v", err)
+	}
+}
+
+func TestCleanPrunesOldArtifactRuns(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "results.log")
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for name, modTime := range map[string]time.Time{"old-run": now.AddDate(0, 0, -40), "new-run": now} {
+		path := filepath.Join(artifactsDirFor(logPath), name, "coverage.out")
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("mode: set\n"), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		for _, p := range []string{path, filepath.Dir(path)} {
+			if err := os.Chtimes(p, modTime, modTime); err != nil {
+				t.Fatalf("chtimes: %v", err)
+			}
+		}
+	}
+	plan, err := buildCleanPlan(logPath, filepath.Join(dir, "state.log"), now.AddDate(0, 0, -30), now, "box", nil)
+	if err != nil {
+		t.Fatalf("buildCleanPlan: %v", err)
+	}
+	if len(plan.Removals) != 1 || filepath.Base(plan.Removals[0].Path) != "old-run" || !plan.Removals[0].Dir || plan.Removals[0].Bytes != 10 {
+		t.Fatalf("removals = %+v", plan.Removals)
+	}
+	if err := applyCleanPlan(plan); err != nil {
+		t.Fatalf("applyCleanPlan: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(artifactsDirFor(logPath), "old-run")); !os.IsNotExist(err) {
+		t.Fatalf("old artifacts survived: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(artifactsDirFor(logPath), "new-run", "coverage.out")); err != nil {
+		t.Fatalf("recent artifacts removed: %v", err)
+	}
+}
@@ -0,0 +1,303 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/codexexec"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
+)
+
+// askAnswerMarker introduces the answer at the end of Codex's reply so it
+// can be logged without the surrounding tool chatter.
+const askAnswerMarker = "ANSWER:"
+
+// askPlanID stands in for the epic ID of questions asked without --epic.
+const askPlanID = "ask"
+
+// askAnswerTail bounds the logged answer when Codex skips the marker.
+const askAnswerTail = 4000
+
+const askInstructions = `This is a read-only consultation, not a work session. Inspect the repository and beads (bd ready --json, bd show <id> --json) as needed, but do not edit files, commit, or change bead status.
+When you are done, print a line that reads exactly "` + askAnswerMarker + `" followed by your answer in plain text.`
+
+// qaEntry is one line of the Q&A ledger (qa.log), kept apart from the
+// results log so questions never count as work.
+type qaEntry struct {
+	SessionID      string    `json:"session_id"`
+	RepoRoot       string    `json:"repo_root,omitempty"`
+	EpicID         string    `json:"epic_id,omitempty"`
+	Alias          string    `json:"alias,omitempty"`
+	Question       string    `json:"question"`
+	Answer         string    `json:"answer"`
+	AnswerMarked   bool      `json:"answer_marked"`
+	StartedAt      time.Time `json:"started_at"`
+	CompletedAt    time.Time `json:"completed_at"`
+	DurationMs     int64     `json:"duration_ms"`
+	ExitCode       int       `json:"exit_code"`
+	TranscriptPath string    `json:"transcript_path,omitempty"`
+	CodexModel     string    `json:"codex_model,omitempty"`
+	Redacted       bool      `json:"redacted,omitempty"`
+}
+
+func runAsk(args []string) error {
+	fs := newCommandFlags("ask", "obi ask \"<question>\" [options]",
+		"Ask Codex a question about the repo (or an epic with --epic) in a read-only\nsandbox. The answer is streamed and logged to qa.log next to the results log.", "question")
+	var configPath, profile, epic, dirFlag, outPath string
+	var contextFlags []string
+	var noTUI bool
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.StringVar(&profile, "profile", "", "apply this [profiles.*] overlay (defaults to $OBI_PROFILE)")
+	fs.StringVar(&epic, "epic", "", "add this epic's prompt, context files, and codex settings")
+	fs.StringVar(&dirFlag, "dir", "", "run Codex in this directory")
+	fs.Var((*contextFlag)(&contextFlags), "context", "append this file to the prompt (repeatable)")
+	fs.StringVar(&outPath, "out", "", "tee codex output to this file")
+	fs.StringVar(&outPath, "o", "", "shorthand for --out")
+	fs.BoolVar(&noTUI, "no-tui", false, "stream raw Codex output instead of the TUI")
+
+	positional, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	question := strings.TrimSpace(positionalArg(positional, 0))
+	if question == "" {
+		return errors.New("obi ask needs a question, e.g. obi ask \"where is the ledger written?\"")
+	}
+
+	resolved, cfg, err := loadConfig(configPath, profile)
+	if err != nil {
+		return err
+	}
+	plan := sessionPlan{EpicName: "Question", EpicID: askPlanID, Codex: cfg.Codex}
+	if strings.TrimSpace(epic) != "" {
+		if _, ok := groupTarget(epic); ok {
+			return errors.New("obi ask --epic takes a single epic, not a group")
+		}
+		if plan, err = prepareSession(cfg, epic); err != nil {
+			return err
+		}
+	}
+	plan.RepoRoot = repoRootForConfig(resolved)
+	if err := applyRunContext(&plan, dirFlag, nil); err != nil {
+		return err
+	}
+	if err := loadContextFiles(&plan, contextFlags); err != nil {
+		return err
+	}
+	plan.Codex = readOnlyCodex(plan.Codex)
+
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+	qaPath, err := cfg.QALogPath()
+	if err != nil {
+		return err
+	}
+
+	runner := interactive.NewSessionRunner()
+	sessionID, err := runner.NewSessionID()
+	if err != nil {
+		return err
+	}
+	prompt := buildAskPrompt(plan, question)
+	inv, err := codexexec.Build(plan.Codex, prompt)
+	if err != nil {
+		return err
+	}
+	secretEnv, secretValues, err := epicSecretEnv(plan.SecretEnv)
+	if err != nil {
+		return err
+	}
+	codexEnv := mergeEnv(plan.Env, secretEnv)
+	if err := codexexec.CheckEnv(plan.Codex, codexEnv); err != nil {
+		return err
+	}
+	transcript, transcriptPath, err := openTranscriptWriter(logPath, outPath, cfg.TranscriptNameTemplate, newTranscriptFields(plan, sessionID, time.Now()))
+	if err != nil {
+		return err
+	}
+	defer transcript.Close()
+
+	auditPath, err := cfg.AuditLogPath()
+	if err != nil {
+		return err
+	}
+	secrets := append(redactionSecrets(), secretValues...)
+	audit := newAuditLog(auditPath, sessionID, plan.EpicID, secrets)
+	codexTranscript, operatorTranscript, transcriptFilter, err := transcriptWriters(cfg.Transcript, newLockedWriter(transcript))
+	if err != nil {
+		return err
+	}
+	opLog := newOperatorLog(operatorTranscript)
+
+	useTUI := !noTUI
+	var settings sessionTUISettings
+	if useTUI {
+		if settings, err = loadSessionTUISettings(cfg.TUI, os.Getenv("NO_COLOR")); err != nil {
+			return err
+		}
+		settings.transcriptPath = transcriptPath
+	}
+	var stdout io.Writer = os.Stdout
+	if useTUI {
+		stdout = io.Discard
+	}
+	fmt.Printf("Asking Codex (%s sandbox): %s\n", readOnlySandbox, firstLine(question))
+
+	handle, err := runner.Start(context.Background(), interactive.StartOptions{
+		SessionID:       sessionID,
+		Prompt:          prompt,
+		Invocation:      inv,
+		Stdout:          stdout,
+		Tee:             sessionTee(codexTranscript, opLog),
+		Secrets:         secrets,
+		RedactLive:      cfg.Redaction.Live,
+		Dir:             plan.Dir,
+		Env:             codexEnv,
+		Unset:           foreignSecrets(cfg, plan.SecretEnv),
+		EventBufferSize: cfg.TUI.EventBuffer,
+	})
+	if err != nil {
+		return newExitError(err.Error())
+	}
+	if notice := degradedNotice(handle); notice != "" {
+		fmt.Fprintf(os.Stderr, "obi: warning: %s\n", notice)
+	}
+	var view *sessionDisplay
+	if useTUI {
+		if view, err = startSessionTUI(handle, plan, opLog, audit, settings); err != nil {
+			return err
+		}
+	}
+
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	var signalWriter io.Writer = os.Stdout
+	if useTUI {
+		signalWriter = io.Discard
+	}
+	stopRelay := startSignalRelay(auditedSignals{signalSession: handle, audit: audit}, sigCh, signalWriter)
+	res, waitErr := handle.Wait()
+	stopRelay()
+	if err := transcriptFilter.Flush(); err != nil {
+		diag.Logger().Warn("transcript flush failed", "path", transcriptPath, "err", err)
+	}
+	signal.Stop(sigCh)
+	close(sigCh)
+	if view != nil {
+		view.Stop()
+	}
+	if waitErr != nil {
+		return newExitError(waitErr.Error())
+	}
+
+	answer, marked := extractAnswer(res.Output)
+	redactedQuestion, questionRedacted := redactText(question, secrets)
+	redactedAnswer, answerRedacted := redactText(answer, secrets)
+	entry := qaEntry{
+		SessionID:      sessionID,
+		RepoRoot:       plan.RepoRoot,
+		Question:       redactedQuestion,
+		Answer:         redactedAnswer,
+		AnswerMarked:   marked,
+		StartedAt:      res.StartedAt,
+		CompletedAt:    res.CompletedAt,
+		ExitCode:       res.ExitCode,
+		TranscriptPath: transcriptPath,
+		CodexModel:     plan.Codex.Model,
+		Redacted:       questionRedacted || answerRedacted,
+	}
+	if plan.EpicID != askPlanID {
+		entry.EpicID = plan.EpicID
+		entry.Alias = plan.Alias
+	}
+	if err := appendQAEntry(qaPath, entry); err != nil {
+		return err
+	}
+
+	if useTUI || !marked {
+		fmt.Printf("\nAnswer:\n%s\n", indentPrompt(redactedAnswer))
+	}
+	fmt.Printf("\nLogged to %s (transcript: %s)\n", qaPath, transcriptPath)
+	if res.ExitCode != 0 {
+		return newExitError(fmt.Sprintf("codex exited with status %d", res.ExitCode))
+	}
+	return nil
+}
+
+// buildAskPrompt frames question with the repo root, the epic block when
+// one was chosen, and any context files.
+func buildAskPrompt(plan sessionPlan, question string) string {
+	var sections []string
+	if plan.RepoRoot != "" {
+		sections = append(sections, fmt.Sprintf("Repository: %s", plan.RepoRoot))
+	}
+	if plan.EpicID != askPlanID {
+		lines := []string{fmt.Sprintf("Epic: %s (%s)", plan.EpicName, plan.EpicID)}
+		if trimmed := strings.TrimSpace(plan.EpicPrompt); trimmed != "" {
+			lines = append(lines, trimmed)
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	if text := formatContextFiles(plan.ContextFiles); text != "" {
+		sections = append(sections, text)
+	}
+	sections = append(sections, askInstructions, "Question:\n"+strings.TrimSpace(question))
+	return strings.Join(sections, "\n\n")
+}
+
+// extractAnswer returns the text after the last answer marker line, or the
+// tail of the output when Codex never printed one.
+func extractAnswer(output string) (string, bool) {
+	clean := ansiSequence.ReplaceAllString(strings.ReplaceAll(output, "\r\n", "\n"), "")
+	lines := strings.Split(clean, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, askAnswerMarker) {
+			continue
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(line, askAnswerMarker))
+		body := strings.TrimSpace(strings.Join(append([]string{rest}, lines[i+1:]...), "\n"))
+		if body != "" {
+			return body, true
+		}
+	}
+	clean = strings.TrimSpace(clean)
+	if len(clean) > askAnswerTail {
+		clean = "…" + clean[len(clean)-askAnswerTail:]
+	}
+	return clean, false
+}
+
+func appendQAEntry(path string, entry qaEntry) error {
+	if !entry.StartedAt.IsZero() && !entry.CompletedAt.IsZero() {
+		entry.DurationMs = entry.CompletedAt.Sub(entry.StartedAt).Milliseconds()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode Q&A entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create Q&A log dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open Q&A log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write Q&A log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractAnswerAfterLastMarker(t *testing.T) {
+	output := "prompt echo: print a line that reads exactly \"ANSWER:\"\n" +
+		"exec rg ledger\n" +
+		"\x1b[1mANSWER:\x1b[0m The ledger is written by appendLedgerEntry.\r\n" +
+		"It lives in internal/app/ledger.go.\n"
+	got, marked := extractAnswer(output)
+	if !marked {
+		t.Fatal("expected the marker to be found")
+	}
+	want := "The ledger is written by appendLedgerEntry.\nIt lives in internal/app/ledger.go."
+	if got != want {
+		t.Fatalf("answer = %q, want %q", got, want)
+	}
+}
+
+func TestExtractAnswerFallsBackToOutputTail(t *testing.T) {
+	output := strings.Repeat("x", askAnswerTail+10)
+	got, marked := extractAnswer(output)
+	if marked {
+		t.Fatal("expected no marker")
+	}
+	if !strings.HasPrefix(got, "…") || len(got) != askAnswerTail+len("…") {
+		t.Fatalf("expected the output tail, got %d bytes", len(got))
+	}
+}
+
+func TestBuildAskPromptIncludesEpicAndQuestion(t *testing.T) {
+	plan := sessionPlan{RepoRoot: "/repo", EpicName: "Ledger", EpicID: "obi-7", EpicPrompt: "Focus on the results log."}
+	got := buildAskPrompt(plan, "  Where are entries written?  ")
+	for _, part := range []string{"Repository: /repo", "Epic: Ledger (obi-7)", "Focus on the results log.", "read-only consultation", "Question:\nWhere are entries written?"} {
+		if !strings.Contains(got, part) {
+			t.Fatalf("expected %q in prompt:\n%s", part, got)
+		}
+	}
+	if strings.Contains(got, "```obi:") || strings.Contains(got, "completion contract") {
+		t.Fatalf("ask prompts must not carry the report contract:\n%s", got)
+	}
+
+	bare := buildAskPrompt(sessionPlan{EpicID: askPlanID}, "why?")
+	if strings.Contains(bare, "Epic:") {
+		t.Fatalf("expected no epic block without --epic:\n%s", bare)
+	}
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// [tui] attention modes.
+const (
+	attentionOff  = "off"
+	attentionBell = "bell"
+	attentionOSC9 = "osc9"
+	attentionBoth = "both"
+)
+
+// Moments that can alert the operator; see [tui] attention_events.
+const (
+	attentionConfirm   = "confirm"
+	attentionApproval  = "approval"
+	attentionNeedsHelp = "needs_help"
+	attentionExit      = "exit"
+	attentionStall     = "stall"
+)
+
+var attentionEventNames = []string{attentionConfirm, attentionApproval, attentionNeedsHelp, attentionExit, attentionStall}
+
+// attentionNotifier rings the terminal bell and/or sends an OSC 9
+// notification so multiplexers and terminal tabs flag the session. A nil
+// notifier stays quiet.
+type attentionNotifier struct {
+	bell, osc9 bool
+	events     map[string]bool
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// newAttentionNotifier validates the [tui] attention settings. It returns
+// nil when alerts are off.
+func newAttentionNotifier(cfg config.TUIConfig, out io.Writer) (*attentionNotifier, error) {
+	n := &attentionNotifier{out: out, events: map[string]bool{}}
+	switch mode := strings.ToLower(strings.TrimSpace(cfg.Attention)); mode {
+	case "", attentionOff:
+		return nil, nil
+	case attentionBell:
+		n.bell = true
+	case attentionOSC9:
+		n.osc9 = true
+	case attentionBoth:
+		n.bell, n.osc9 = true, true
+	default:
+		return nil, fmt.Errorf("tui.attention %q: want off, bell, osc9, or both", cfg.Attention)
+	}
+	events := cfg.AttentionEvents
+	if len(events) == 0 {
+		events = attentionEventNames
+	}
+	for _, raw := range events {
+		event := strings.ToLower(strings.TrimSpace(raw))
+		known := false
+		for _, name := range attentionEventNames {
+			known = known || event == name
+		}
+		if !known {
+			return nil, fmt.Errorf("tui.attention_events %q: want %s", raw, strings.Join(attentionEventNames, ", "))
+		}
+		n.events[event] = true
+	}
+	return n, nil
+}
+
+// wants reports whether event alerts at all.
+func (n *attentionNotifier) wants(event string) bool {
+	return n != nil && n.events[event]
+}
+
+// alert signals event with message, if that event is enabled.
+func (n *attentionNotifier) alert(event, message string) {
+	if !n.wants(event) {
+		return
+	}
+	var seq string
+	if n.osc9 {
+		seq += "\x1b]9;" + oscSafe("obi: "+message) + "\a"
+	}
+	if n.bell {
+		seq += "\a"
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	fmt.Fprint(n.out, seq)
+}
+
+// oscSafe drops control characters, which would end or corrupt the OSC
+// sequence, and caps the length terminals are willing to show.
+func oscSafe(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, text)
+	return strings.ToValidUTF8(truncate(strings.TrimSpace(text), 200), "")
+}
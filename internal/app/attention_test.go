@@ -0,0 +1,56 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestNewAttentionNotifierModes(t *testing.T) {
+	for _, mode := range []string{"", "off", " OFF "} {
+		n, err := newAttentionNotifier(config.TUIConfig{Attention: mode}, &bytes.Buffer{})
+		if err != nil || n != nil {
+			t.Fatalf("mode %q: notifier = %v, err = %v", mode, n, err)
+		}
+	}
+	if _, err := newAttentionNotifier(config.TUIConfig{Attention: "flash"}, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "tui.attention") {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := newAttentionNotifier(config.TUIConfig{Attention: "bell", AttentionEvents: []string{"idle"}}, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "tui.attention_events") {
+		t.Fatalf("err = %v", err)
+	}
+
+	// A nil notifier is quiet.
+	var off *attentionNotifier
+	off.alert(attentionExit, "done")
+}
+
+func TestAttentionNotifierWritesSequences(t *testing.T) {
+	var out bytes.Buffer
+	n, err := newAttentionNotifier(config.TUIConfig{Attention: "both", AttentionEvents: []string{"needs_help"}}, &out)
+	if err != nil {
+		t.Fatalf("newAttentionNotifier: %v", err)
+	}
+	n.alert(attentionExit, "Codex exited")
+	if out.Len() != 0 {
+		t.Fatalf("exit is not enabled, wrote %q", out.String())
+	}
+	n.alert(attentionNeedsHelp, "api needs help:\x1b]evil\a line two")
+	if got, want := out.String(), "\x1b]9;obi: api needs help: ]evil  line two\a\a"; got != want {
+		t.Fatalf("wrote %q, want %q", got, want)
+	}
+
+	out.Reset()
+	bell, err := newAttentionNotifier(config.TUIConfig{Attention: "bell"}, &out)
+	if err != nil {
+		t.Fatalf("newAttentionNotifier: %v", err)
+	}
+	for _, event := range attentionEventNames {
+		bell.alert(event, "x")
+	}
+	if got := out.String(); got != strings.Repeat("\a", len(attentionEventNames)) {
+		t.Fatalf("wrote %q", got)
+	}
+}
@@ -0,0 +1,283 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/tui"
+)
+
+type auditAction string
+
+const (
+	auditApprove     auditAction = "approve"
+	auditDecline     auditAction = "decline"
+	auditAutoApprove auditAction = "auto_approve"
+	auditHint        auditAction = "hint"
+	auditSoftStop    auditAction = "soft_stop"
+	auditAbort       auditAction = "abort"
+	auditPause       auditAction = "pause"
+	auditResume      auditAction = "resume"
+	auditReportPick  auditAction = "report_choice"
+	auditEscalation  auditAction = "escalation"
+	auditAliasRename auditAction = "alias_rename"
+)
+
+// auditRecord is one line of the append-only operator audit log. Unlike
+// ledger operator events it is written as the action happens, so it covers
+// runs that never reach the ledger.
+type auditRecord struct {
+	Time      time.Time `json:"time"`
+	Operator  string    `json:"operator"`
+	Host      string    `json:"host,omitempty"`
+	Action    string    `json:"action"`
+	SessionID string    `json:"session_id,omitempty"`
+	EpicID    string    `json:"epic_id,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// auditLog appends operator actions for one session. Write failures are
+// reported once on stderr and never interrupt the session.
+type auditLog struct {
+	path      string
+	operator  string
+	host      string
+	sessionID string
+	epicID    string
+	secrets   []string
+	now       func() time.Time
+
+	mu     sync.Mutex
+	warned bool
+}
+
+func newAuditLog(path, sessionID, epicID string, secrets []string) *auditLog {
+	host, _ := os.Hostname()
+	return &auditLog{
+		path:      path,
+		operator:  auditOperator(),
+		host:      host,
+		sessionID: sessionID,
+		epicID:    epicID,
+		secrets:   secrets,
+		now:       time.Now,
+	}
+}
+
+// auditOperator names the OS user driving obi.
+func auditOperator() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, key := range []string{"USER", "USERNAME", "LOGNAME"} {
+		if name := strings.TrimSpace(os.Getenv(key)); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+func (a *auditLog) record(action auditAction, detail string) {
+	if a == nil || a.path == "" {
+		return
+	}
+	detail, _ = redactText(strings.TrimSpace(detail), a.secrets)
+	rec := auditRecord{
+		Time:      a.now().UTC(),
+		Operator:  a.operator,
+		Host:      a.host,
+		Action:    string(action),
+		SessionID: a.sessionID,
+		EpicID:    a.epicID,
+		Detail:    detail,
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := appendAuditRecord(a.path, rec); err != nil && !a.warned {
+		a.warned = true
+		diag.Logger().Warn("audit log write failed; later failures are not reported", "path", a.path, "err", err)
+	}
+}
+
+func appendAuditRecord(path string, rec auditRecord) error {
+	return appendJSONLine(path, rec)
+}
+
+// appendJSONLine appends v as one JSON line to a private (0600) log file,
+// creating the file and its directory as needed.
+func appendJSONLine(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("ensure %s dir: %w", filepath.Base(path), err)
+	}
+	line, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode %s record: %w", filepath.Base(path), err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func readAuditRecords(path string) ([]auditRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []auditRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), ledgerScanMaxBytes)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec auditRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, fmt.Errorf("parse audit record: %w", err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan audit log: %w", err)
+	}
+	return records, nil
+}
+
+// auditedSignals records Ctrl+C soft stops and signal aborts.
+type auditedSignals struct {
+	signalSession
+	audit *auditLog
+}
+
+func (s auditedSignals) SoftStop(reason string) error {
+	err := s.signalSession.SoftStop(reason)
+	if err == nil {
+		s.audit.record(auditSoftStop, reason)
+	}
+	return err
+}
+
+func (s auditedSignals) Abort() error {
+	s.audit.record(auditAbort, "signal")
+	return s.signalSession.Abort()
+}
+
+// auditedShell records TUI pause toggles.
+type auditedShell struct {
+	*tui.Shell
+	audit *auditLog
+}
+
+func (s auditedShell) TogglePause() bool {
+	paused := s.Shell.TogglePause()
+	if paused {
+		s.audit.record(auditPause, "")
+	} else {
+		s.audit.record(auditResume, "")
+	}
+	return paused
+}
+
+func runAudit(args []string) error {
+	fs := newCommandFlags("audit", "obi audit [options]",
+		"Show the operator audit log: who approved, hinted, paused, stopped, or aborted each session.")
+	var configPath, session, since string
+	var asJSON bool
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.StringVar(&session, "session", "", "only show actions for this session ID (prefix match)")
+	fs.StringVar(&since, "since", "", "only show actions within this window (e.g. 24h, 7d) or since a date (YYYY-MM-DD)")
+	fs.BoolVar(&asJSON, "json", false, "print raw JSON lines")
+	if _, err := fs.parse(args); err != nil {
+		return err
+	}
+
+	var cutoff time.Time
+	if since != "" {
+		var err error
+		if cutoff, err = parseDigestSince(since, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	_, cfg, err := loadConfig(configPath, "")
+	if err != nil {
+		return err
+	}
+	path, err := cfg.AuditLogPath()
+	if err != nil {
+		return err
+	}
+	records, err := readAuditRecords(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("No operator actions recorded yet (%s).\n", path)
+			return nil
+		}
+		return err
+	}
+	records = filterAuditRecords(records, session, cutoff)
+
+	if asJSON {
+		for _, rec := range records {
+			line, err := json.Marshal(rec)
+			if err != nil {
+				return fmt.Errorf("encode audit record: %w", err)
+			}
+			fmt.Println(string(line))
+		}
+		return nil
+	}
+	fmt.Print(formatAuditRecords(records))
+	return nil
+}
+
+func filterAuditRecords(records []auditRecord, session string, cutoff time.Time) []auditRecord {
+	session = strings.ToLower(strings.TrimSpace(session))
+	var out []auditRecord
+	for _, rec := range records {
+		if session != "" && !strings.HasPrefix(strings.ToLower(rec.SessionID), session) {
+			continue
+		}
+		if !cutoff.IsZero() && rec.Time.Before(cutoff) {
+			continue
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func formatAuditRecords(records []auditRecord) string {
+	if len(records) == 0 {
+		return "No matching operator actions.\n"
+	}
+	var b strings.Builder
+	for _, rec := range records {
+		session := rec.SessionID
+		if len(session) > 8 {
+			session = session[:8]
+		}
+		line := fmt.Sprintf("%s  %-12s  %-13s  %-8s  %s", rec.Time.Local().Format("2006-01-02 15:04:05"), rec.Operator, rec.Action, session, rec.EpicID)
+		if rec.Detail != "" {
+			line += "  " + firstLine(rec.Detail)
+		}
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	return b.String()
+}
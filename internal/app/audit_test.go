@@ -0,0 +1,90 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLogAppendsRedactedRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "audit.log")
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	audit := newAuditLog(path, "8b654f71-f38a", "obi-a1", []string{"hunter2"})
+	audit.operator = "alice"
+	audit.now = func() time.Time { return now }
+
+	audit.record(auditApprove, "")
+	audit.record(auditHint, "use password hunter2 for the fixture")
+	other := newAuditLog(path, "c0ffee00-1234", "obi-b2", nil)
+	other.operator = "bob"
+	other.now = func() time.Time { return now.Add(48 * time.Hour) }
+	other.record(auditAbort, "hotkey 'q'")
+
+	records, err := readAuditRecords(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %+v", records)
+	}
+	if records[0].Operator != "alice" || records[0].Action != "approve" || records[0].SessionID != "8b654f71-f38a" || records[0].EpicID != "obi-a1" {
+		t.Fatalf("unexpected first record: %+v", records[0])
+	}
+	if strings.Contains(records[1].Detail, "hunter2") {
+		t.Fatalf("expected hint detail redacted, got %q", records[1].Detail)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 audit log, got %v %v", info, err)
+	}
+
+	filtered := filterAuditRecords(records, "8B654F71", time.Time{})
+	if len(filtered) != 2 {
+		t.Fatalf("expected session prefix filter to keep 2, got %+v", filtered)
+	}
+	if recent := filterAuditRecords(records, "", now.Add(time.Hour)); len(recent) != 1 || recent[0].Operator != "bob" {
+		t.Fatalf("expected cutoff filter to keep bob's abort, got %+v", recent)
+	}
+
+	out := formatAuditRecords(records)
+	if !strings.Contains(out, "alice") || !strings.Contains(out, "8b654f71  obi-a1") || !strings.Contains(out, "hotkey 'q'") {
+		t.Fatalf("unexpected audit table:\n%s", out)
+	}
+}
+
+func TestAuditedSignalsRecordActions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit := newAuditLog(path, "s1", "obi-a1", nil)
+	ctrl := &fakeSessionControl{}
+	relay := newSignalRelay(auditedSignals{signalSession: ctrl, audit: audit}, nil)
+	relay.handleSignal(os.Interrupt)
+	relay.handleSignal(os.Interrupt)
+
+	failing := auditedSignals{signalSession: &fakeSessionControl{softStopErr: errors.New("closed")}, audit: audit}
+	if err := failing.SoftStop("late"); err == nil {
+		t.Fatalf("expected soft stop error to pass through")
+	}
+
+	records, err := readAuditRecords(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var actions []string
+	for _, rec := range records {
+		actions = append(actions, rec.Action)
+	}
+	if got := strings.Join(actions, ","); got != "soft_stop,abort" {
+		t.Fatalf("unexpected audited actions %q", got)
+	}
+	if len(ctrl.softStopReasons) != 1 || ctrl.aborts != 1 {
+		t.Fatalf("expected calls forwarded, got %+v", ctrl)
+	}
+}
+
+func TestAuditLogNilIsNoop(t *testing.T) {
+	var audit *auditLog
+	audit.record(auditHint, "ignored")
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/footer"
+)
+
+// printExitBanner closes a session with a compact summary of the ledger
+// entry and what to do next, so the call to action is not lost in the
+// report details above it.
+func printExitBanner(out io.Writer, plan sessionPlan, entry ledgerEntry) {
+	fmt.Fprint(out, formatExitBanner(entry, sessionNextStep(plan, entry, fetchReadyIssues)))
+}
+
+// formatExitBanner renders the banner: status, bead, duration and usage on
+// one line, then the transcript and the next step when there is one.
+func formatExitBanner(entry ledgerEntry, next string) string {
+	parts := []string{entry.Status}
+	if entry.BeadID != "" {
+		parts = append(parts, "bead "+entry.BeadID)
+	}
+	if !entry.StartedAt.IsZero() && entry.CompletedAt.After(entry.StartedAt) {
+		parts = append(parts, entry.CompletedAt.Sub(entry.StartedAt).Round(time.Second).String())
+	}
+	if entry.TokensUsed > 0 {
+		parts = append(parts, strconv.FormatInt(entry.TokensUsed, 10)+" tokens")
+	}
+	if entry.CostUSD > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f", entry.CostUSD))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== %s ===\n", strings.Join(parts, " | "))
+	if entry.TranscriptPath != "" {
+		fmt.Fprintf(&b, "Transcript: %s\n", entry.TranscriptPath)
+	}
+	if next != "" {
+		fmt.Fprintf(&b, "Next: %s\n", next)
+	}
+	return b.String()
+}
+
+// sessionNextStep suggests what the operator should do after entry. Only a
+// successful work session outside the epic loop asks bd (through fetch) for
+// the next ready bead; the loop makes that check itself.
+func sessionNextStep(plan sessionPlan, entry ledgerEntry, fetch func() ([]readyIssue, error)) string {
+	rerun := "obi go"
+	if alias := strings.TrimSpace(plan.Alias); alias != "" && plan.EpicID != "issues" {
+		rerun += " " + alias
+	}
+	switch {
+	case plan.Simulated != "":
+		return "simulated run; nothing was changed"
+	case plan.ReadOnly:
+		return fmt.Sprintf("exploratory run; act on its findings with %s", rerun)
+	case entry.Status == ledgerStatusUnparsed || entry.ExitCode != 0:
+		return fmt.Sprintf("read the transcript, then rerun %s", rerun)
+	case !strings.EqualFold(entry.Status, footer.StatusSuccess):
+		if entry.CodexSessionID != "" {
+			return fmt.Sprintf("answer the escalation, then %s --continue-codex %s; obi list counts every bead that needs help", rerun, entry.RunID)
+		}
+		return fmt.Sprintf("answer the escalation in bd, then rerun %s; obi list counts every bead that needs help", rerun)
+	case plan.Mode == sessionModeSummary:
+		if entry.PullRequestURL != "" {
+			return "review the pull request at " + entry.PullRequestURL
+		}
+		return "epic summarized; obi list shows the other epics"
+	case plan.Mode == sessionModeTemplate:
+		return "obi run shows when each chore is due next"
+	case plan.InLoop:
+		return "the epic loop continues while ready beads remain"
+	}
+	issues, err := fetch()
+	if err != nil {
+		return "obi list shows what is ready"
+	}
+	done := plan
+	done.ResumeCompletedBeads = append(append([]string(nil), plan.ResumeCompletedBeads...), entry.BeadID)
+	beads := readyBeadsForPlan(done, issues)
+	if len(beads) == 0 {
+		return "no ready beads left here; obi list shows the other epics"
+	}
+	next := beads[0].ID
+	if title := strings.TrimSpace(beads[0].Title); title != "" {
+		next += " (" + title + ")"
+	}
+	return fmt.Sprintf("next ready bead: %s; %s picks it up", next, rerun)
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatExitBanner(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	entry := ledgerEntry{
+		Status:         "success",
+		BeadID:         "api-1.3",
+		StartedAt:      start,
+		CompletedAt:    start.Add(12*time.Minute + 4*time.Second + 300*time.Millisecond),
+		TokensUsed:     48213,
+		CostUSD:        0.2411,
+		TranscriptPath: "/tmp/obi/run.log",
+	}
+	got := formatExitBanner(entry, "next ready bead: api-1.4; obi go api picks it up")
+	want := "\n=== success | bead api-1.3 | 12m4s | 48213 tokens | $0.24 ===\n" +
+		"Transcript: /tmp/obi/run.log\n" +
+		"Next: next ready bead: api-1.4; obi go api picks it up\n"
+	if got != want {
+		t.Fatalf("banner:\n%s\nwant:\n%s", got, want)
+	}
+	if got := formatExitBanner(ledgerEntry{Status: ledgerStatusUnparsed}, ""); got != "\n=== unparsed ===\n" {
+		t.Fatalf("bare banner = %q", got)
+	}
+}
+
+func TestSessionNextStepSuggestsNextReadyBead(t *testing.T) {
+	plan := sessionPlan{Alias: "api", EpicID: "api-1"}
+	issues := []readyIssue{
+		{ID: "api-1", IssueType: "epic"},
+		{ID: "api-1.3", Title: "Done just now"},
+		{ID: "web-2.1", Title: "Other epic"},
+		{ID: "api-1.4", Title: "Add pagination"},
+	}
+	fetch := func() ([]readyIssue, error) { return issues, nil }
+	entry := ledgerEntry{Status: "success", BeadID: "api-1.3"}
+	if got := sessionNextStep(plan, entry, fetch); got != "next ready bead: api-1.4 (Add pagination); obi go api picks it up" {
+		t.Fatalf("next = %q", got)
+	}
+	issues = issues[:3]
+	if got := sessionNextStep(plan, entry, fetch); !strings.HasPrefix(got, "no ready beads left here") {
+		t.Fatalf("next without ready beads = %q", got)
+	}
+	failing := func() ([]readyIssue, error) { return nil, errors.New("bd missing") }
+	if got := sessionNextStep(plan, entry, failing); got != "obi list shows what is ready" {
+		t.Fatalf("next when bd fails = %q", got)
+	}
+}
+
+func TestSessionNextStepAfterProblems(t *testing.T) {
+	plan := sessionPlan{Alias: "api", EpicID: "api-1"}
+	fetch := func() ([]readyIssue, error) {
+		t.Fatal("only successful work sessions look up ready beads")
+		return nil, nil
+	}
+	cases := []struct {
+		name  string
+		plan  sessionPlan
+		entry ledgerEntry
+		want  string
+	}{
+		{"needs help", plan, ledgerEntry{Status: "needs_help", RunID: "run-1", CodexSessionID: "cx-1"}, "answer the escalation, then obi go api --continue-codex run-1; obi list counts every bead that needs help"},
+		{"needs help without codex session", plan, ledgerEntry{Status: "needs_help"}, "answer the escalation in bd, then rerun obi go api; obi list counts every bead that needs help"},
+		{"unparsed", plan, ledgerEntry{Status: ledgerStatusUnparsed}, "read the transcript, then rerun obi go api"},
+		{"codex crashed", plan, ledgerEntry{Status: "success", ExitCode: 1}, "read the transcript, then rerun obi go api"},
+		{"issues target", sessionPlan{Alias: "issues", EpicID: "issues"}, ledgerEntry{Status: ledgerStatusUnparsed}, "read the transcript, then rerun obi go"},
+		{"read-only", sessionPlan{Alias: "api", ReadOnly: true}, ledgerEntry{Status: "success"}, "exploratory run; act on its findings with obi go api"},
+		{"epic loop", sessionPlan{Alias: "api", EpicID: "api-1", InLoop: true}, ledgerEntry{Status: "success", BeadID: "api-1.3"}, "the epic loop continues while ready beads remain"},
+		{"summary", sessionPlan{Alias: "api", Mode: sessionModeSummary}, ledgerEntry{Status: "success", PullRequestURL: "https://example.com/pr/1"}, "review the pull request at https://example.com/pr/1"},
+	}
+	for _, tc := range cases {
+		got := sessionNextStep(tc.plan, tc.entry, fetch)
+		if got != tc.want {
+			t.Errorf("%s: next = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/codexexec"
+)
+
+// maxProposedBeads caps how many beads one obi plan call may create.
+const maxProposedBeads = 20
+
+// beadTypes are the issue types obi plan passes to bd create.
+var beadTypes = []string{"task", "bug", "feature", "chore"}
+
+// beadProposal is one bead Codex suggested for the described work.
+type beadProposal struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Priority    *int   `json:"priority"`
+}
+
+func runPlan(args []string) error {
+	fs := newCommandFlags("plan", "obi plan <alias> \"<description of work>\" [options]",
+		"Ask Codex to break a description of work into beads, show the proposal for\napproval, then create them under the epic with bd create.", "alias", "description")
+	var configPath, profile string
+	var yes bool
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.StringVar(&profile, "profile", "", "apply this [profiles.*] overlay (defaults to $OBI_PROFILE)")
+	fs.BoolVar(&yes, "yes", false, "create the proposed beads without asking")
+
+	positional, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	alias := positionalArg(positional, 0)
+	description := strings.TrimSpace(positionalArg(positional, 1))
+	if strings.TrimSpace(alias) == "" || description == "" {
+		return errors.New("usage: obi plan <alias> \"<description of work>\"")
+	}
+	if _, ok := groupTarget(alias); ok {
+		return errors.New("obi plan takes a single epic, not a group")
+	}
+
+	_, cfg, err := loadConfig(configPath, profile)
+	if err != nil {
+		return err
+	}
+	plan, err := prepareSession(cfg, alias)
+	if err != nil {
+		return err
+	}
+	codex := readOnlyCodex(plan.Codex)
+
+	fmt.Printf("Asking Codex to break the work into beads for %s (%s)...\n", plan.EpicName, plan.EpicID)
+	inv, err := codexexec.Build(codex, buildBeadPlanPrompt(plan, description))
+	if err != nil {
+		return err
+	}
+	output, err := runCodexCapture(context.Background(), inv)
+	if err != nil {
+		return err
+	}
+	proposals, err := parseBeadProposals(output)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Print(formatBeadProposals(plan, proposals))
+	fmt.Println()
+	if !yes {
+		ok, err := promptForConfirmation()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("No beads created.")
+			return nil
+		}
+	}
+
+	for i, proposal := range proposals {
+		id, err := createBead(plan.EpicID, proposal)
+		if err != nil {
+			if i > 0 {
+				return fmt.Errorf("%w (created %d of %d beads before the failure)", err, i, len(proposals))
+			}
+			return err
+		}
+		fmt.Printf("Created %s: %s\n", id, proposal.Title)
+	}
+	fmt.Printf("Created %d bead(s) under %s. Run `obi go %s` to work on them.\n", len(proposals), plan.EpicID, plan.Alias)
+	return nil
+}
+
+func buildBeadPlanPrompt(plan sessionPlan, description string) string {
+	var sb strings.Builder
+	sb.WriteString("Break the work described below into beads (small, independently shippable issues) for an epic. Do not edit files or run bd; only reply.\n")
+	sb.WriteString(fmt.Sprintf("Epic: %s (%s)\n", plan.EpicName, plan.EpicID))
+	if prompt := strings.TrimSpace(plan.EpicPrompt); prompt != "" {
+		sb.WriteString(fmt.Sprintf("Epic guidance: %s\n", truncate(prompt, 2000)))
+	}
+	sb.WriteString("\nWork:\n")
+	sb.WriteString(description)
+	sb.WriteString("\n\nRespond with a JSON object of the form ")
+	sb.WriteString(`{"beads":[{"title":"...","description":"...","type":"task","priority":2}]}`)
+	sb.WriteString(fmt.Sprintf(". type is one of %s; priority is 0 (highest) to 4. List beads in the order they should be done, at most %d.\n", strings.Join(beadTypes, ", "), maxProposedBeads))
+	sb.WriteString("Return only the JSON object.\n")
+	return sb.String()
+}
+
+// parseBeadProposals reads Codex's reply, dropping untitled entries and
+// normalizing unknown types and out-of-range priorities.
+func parseBeadProposals(output string) ([]beadProposal, error) {
+	jsonText, err := extractJSONObject(output)
+	if err != nil {
+		return nil, fmt.Errorf("bead plan output invalid: %w", err)
+	}
+	var parsed struct {
+		Beads []beadProposal `json:"beads"`
+	}
+	if err := json.Unmarshal([]byte(jsonText), &parsed); err != nil {
+		return nil, fmt.Errorf("bead plan parse: %w (json=%s)", err, truncate(jsonText, 400))
+	}
+	var proposals []beadProposal
+	for _, proposal := range parsed.Beads {
+		proposal.Title = strings.Join(strings.Fields(proposal.Title), " ")
+		if proposal.Title == "" {
+			continue
+		}
+		proposal.Description = strings.TrimSpace(proposal.Description)
+		proposal.Type = strings.ToLower(strings.TrimSpace(proposal.Type))
+		if !knownBeadType(proposal.Type) {
+			proposal.Type = beadTypes[0]
+		}
+		if proposal.Priority != nil && (*proposal.Priority < 0 || *proposal.Priority > 4) {
+			proposal.Priority = nil
+		}
+		proposals = append(proposals, proposal)
+	}
+	if len(proposals) == 0 {
+		return nil, errors.New("codex proposed no beads")
+	}
+	if len(proposals) > maxProposedBeads {
+		return nil, fmt.Errorf("codex proposed %d beads (limit %d); narrow the description", len(proposals), maxProposedBeads)
+	}
+	return proposals, nil
+}
+
+func knownBeadType(value string) bool {
+	for _, known := range beadTypes {
+		if value == known {
+			return true
+		}
+	}
+	return false
+}
+
+func formatBeadProposals(plan sessionPlan, proposals []beadProposal) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Proposed beads for %s (%s):\n", plan.EpicName, plan.EpicID)
+	for i, proposal := range proposals {
+		priority := "-"
+		if proposal.Priority != nil {
+			priority = "P" + strconv.Itoa(*proposal.Priority)
+		}
+		fmt.Fprintf(&b, "  %2d. [%s %s] %s\n", i+1, proposal.Type, priority, proposal.Title)
+		if proposal.Description != "" {
+			fmt.Fprintf(&b, "      %s\n", truncate(firstLine(proposal.Description), 100))
+		}
+	}
+	return b.String()
+}
+
+// beadCreateArgs builds the bd create command line for proposal under epicID.
+func beadCreateArgs(epicID string, proposal beadProposal) []string {
+	args := []string{"create", "--parent", epicID, "-t", proposal.Type}
+	if proposal.Description != "" {
+		args = append(args, "-d", proposal.Description)
+	}
+	if proposal.Priority != nil {
+		args = append(args, "-p", strconv.Itoa(*proposal.Priority))
+	}
+	// Codex wrote the title; after "--" one starting with "-" cannot be
+	// read as a flag.
+	return append(args, "--json", "--", proposal.Title)
+}
+
+func createBead(epicID string, proposal beadProposal) (string, error) {
+	cmd := exec.Command("bd", beadCreateArgs(epicID, proposal)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		detail := strings.TrimSpace(stderr.String())
+		if detail != "" {
+			return "", fmt.Errorf("bd create %q: %s: %s", proposal.Title, err, detail)
+		}
+		return "", fmt.Errorf("bd create %q: %w", proposal.Title, err)
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &created); err != nil || created.ID == "" {
+		return "(unknown id)", nil
+	}
+	return created.ID, nil
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBeadProposalsNormalizes(t *testing.T) {
+	output := "thinking...\n" + `{"beads":[` +
+		`{"title":"  Add   retry  ","description":" Retry failed posts. ","type":"Feature","priority":1},` +
+		`{"title":"","description":"dropped"},` +
+		`{"title":"Clean up","type":"epic","priority":9}]}`
+	proposals, err := parseBeadProposals(output)
+	if err != nil {
+		t.Fatalf("parseBeadProposals: %v", err)
+	}
+	if len(proposals) != 2 {
+		t.Fatalf("expected 2 proposals, got %d: %+v", len(proposals), proposals)
+	}
+	first := proposals[0]
+	if first.Title != "Add retry" || first.Description != "Retry failed posts." || first.Type != "feature" {
+		t.Fatalf("unexpected first proposal: %+v", first)
+	}
+	if first.Priority == nil || *first.Priority != 1 {
+		t.Fatalf("expected priority 1, got %v", first.Priority)
+	}
+	second := proposals[1]
+	if second.Type != "task" || second.Priority != nil {
+		t.Fatalf("expected unknown type and priority to be normalized, got %+v", second)
+	}
+}
+
+func TestParseBeadProposalsRejectsEmptyAndOversized(t *testing.T) {
+	if _, err := parseBeadProposals(`{"beads":[]}`); err == nil {
+		t.Fatal("expected error for empty proposal")
+	}
+	var beads []string
+	for i := 0; i <= maxProposedBeads; i++ {
+		beads = append(beads, `{"title":"bead"}`)
+	}
+	_, err := parseBeadProposals(`{"beads":[` + strings.Join(beads, ",") + `]}`)
+	if err == nil || !strings.Contains(err.Error(), "limit") {
+		t.Fatalf("expected limit error, got %v", err)
+	}
+}
+
+func TestBeadCreateArgs(t *testing.T) {
+	priority := 2
+	got := beadCreateArgs("obi-1", beadProposal{Title: "Add retry", Description: "Retry posts.", Type: "feature", Priority: &priority})
+	want := []string{"create", "--parent", "obi-1", "-t", "feature", "-d", "Retry posts.", "-p", "2", "--json", "--", "Add retry"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("args = %q, want %q", got, want)
+	}
+	got = beadCreateArgs("obi-1", beadProposal{Title: "--force everything", Type: "task"})
+	want = []string{"create", "--parent", "obi-1", "-t", "task", "--json", "--", "--force everything"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("args = %q, want %q", got, want)
+	}
+}
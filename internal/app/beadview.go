@@ -0,0 +1,107 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/tui"
+)
+
+// beadClaimPattern matches the claim command the completion contract asks
+// Codex to run, capturing the bead ID. The contract's own "<id>"
+// placeholder does not match.
+var beadClaimPattern = regexp.MustCompile(`\bbd\s+update\s+([A-Za-z0-9][\w.-]*)\b[^\n]*--status[= ]+in_progress`)
+
+// beadClaimWatcher spots bead claims in streamed Codex output so the TUI
+// knows the current bead before the session reports it.
+type beadClaimWatcher struct {
+	mu      sync.Mutex
+	partial string
+}
+
+// observe scans chunk for complete lines and returns the last bead
+// claimed in them, or "".
+func (w *beadClaimWatcher) observe(chunk string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	text := w.partial + chunk
+	cut := strings.LastIndexAny(text, "\r\n")
+	if cut < 0 {
+		w.partial = tailString(text, 512)
+		return ""
+	}
+	w.partial = tailString(text[cut+1:], 512)
+	var claimed string
+	for _, match := range beadClaimPattern.FindAllStringSubmatch(ansiSequence.ReplaceAllString(text[:cut], ""), -1) {
+		claimed = match[1]
+	}
+	return claimed
+}
+
+func tailString(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[len(s)-max:]
+}
+
+// bdShowIssue is the subset of bd show --json the bead overlay displays.
+type bdShowIssue struct {
+	ID                 string `json:"id"`
+	Title              string `json:"title"`
+	Status             string `json:"status"`
+	IssueType          string `json:"issue_type"`
+	Description        string `json:"description"`
+	AcceptanceCriteria string `json:"acceptance_criteria"`
+}
+
+// fetchBeadDetails runs bd show for the TUI's bead overlay.
+func fetchBeadDetails(ctx context.Context, beadID string) (tui.BeadDetails, error) {
+	cmd := exec.CommandContext(ctx, "bd", "show", beadID, "--json")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return tui.BeadDetails{}, errors.New("bd show timed out")
+		}
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return tui.BeadDetails{}, fmt.Errorf("bd show: %s: %s", err, firstLine(detail))
+		}
+		return tui.BeadDetails{}, fmt.Errorf("bd show: %w", err)
+	}
+	return parseBeadDetails(stdout.Bytes())
+}
+
+// parseBeadDetails accepts bd show's JSON as either one issue or a list.
+func parseBeadDetails(data []byte) (tui.BeadDetails, error) {
+	trimmed := bytes.TrimSpace(data)
+	var issue bdShowIssue
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var issues []bdShowIssue
+		if err := json.Unmarshal(trimmed, &issues); err != nil {
+			return tui.BeadDetails{}, fmt.Errorf("parse bd show output: %w", err)
+		}
+		if len(issues) == 0 {
+			return tui.BeadDetails{}, errors.New("bd show returned no issue")
+		}
+		issue = issues[0]
+	} else if err := json.Unmarshal(trimmed, &issue); err != nil {
+		return tui.BeadDetails{}, fmt.Errorf("parse bd show output: %w", err)
+	}
+	return tui.BeadDetails{
+		ID:                 issue.ID,
+		Title:              issue.Title,
+		Status:             issue.Status,
+		Type:               issue.IssueType,
+		Description:        issue.Description,
+		AcceptanceCriteria: issue.AcceptanceCriteria,
+	}, nil
+}
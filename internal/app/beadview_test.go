@@ -0,0 +1,39 @@
+package app
+
+import "testing"
+
+func TestBeadClaimWatcherAcrossChunks(t *testing.T) {
+	w := &beadClaimWatcher{}
+	if got := w.observe("- Claim it before coding: bd update <id> --status in_progress --json.\n"); got != "" {
+		t.Fatalf("contract placeholder should not count as a claim, got %q", got)
+	}
+	if got := w.observe("$ bd update obi-api.3 --sta"); got != "" {
+		t.Fatalf("partial line should wait for its end, got %q", got)
+	}
+	if got := w.observe("tus in_progress --json\r\n{\"id\":\"obi-api.3\"}\n"); got != "obi-api.3" {
+		t.Fatalf("expected claim split across chunks, got %q", got)
+	}
+	if got := w.observe("\x1b[1mbd update obi-api.4 --json --status=in_progress\x1b[0m\n"); got != "obi-api.4" {
+		t.Fatalf("expected styled claim with flags reordered, got %q", got)
+	}
+	if got := w.observe("bd update obi-api.4 --status closed\n"); got != "" {
+		t.Fatalf("closing is not a claim, got %q", got)
+	}
+}
+
+func TestParseBeadDetails(t *testing.T) {
+	list := `[{"id":"obi-api.3","title":"Add retry","status":"in_progress","issue_type":"task","description":"Retry posts.","acceptance_criteria":"- 5xx retried"}]`
+	got, err := parseBeadDetails([]byte(list))
+	if err != nil {
+		t.Fatalf("parse list: %v", err)
+	}
+	if got.ID != "obi-api.3" || got.Type != "task" || got.AcceptanceCriteria != "- 5xx retried" {
+		t.Fatalf("unexpected details: %+v", got)
+	}
+	if got, err := parseBeadDetails([]byte(` {"id":"obi-api.4","title":"Docs"}`)); err != nil || got.Title != "Docs" {
+		t.Fatalf("parse object: %+v, %v", got, err)
+	}
+	if _, err := parseBeadDetails([]byte(`[]`)); err == nil {
+		t.Fatal("expected error for empty result")
+	}
+}
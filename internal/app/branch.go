@@ -0,0 +1,160 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// epicBranchFields are the values an [epic.x] branch template can use.
+type epicBranchFields struct {
+	Alias   string
+	EpicKey string
+	EpicID  string
+}
+
+// renderEpicBranch expands an epic's branch template for plan; an empty
+// template means the epic runs on whatever branch is checked out.
+func renderEpicBranch(tmpl string, plan sessionPlan) (string, error) {
+	if strings.TrimSpace(tmpl) == "" {
+		return "", nil
+	}
+	parsed, err := template.New("branch").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("epic %s branch: %w", plan.EpicKey, err)
+	}
+	var b strings.Builder
+	if err := parsed.Execute(&b, epicBranchFields{Alias: plan.Alias, EpicKey: plan.EpicKey, EpicID: plan.EpicID}); err != nil {
+		return "", fmt.Errorf("epic %s branch: %w", plan.EpicKey, err)
+	}
+	name := strings.TrimSpace(b.String())
+	if name == "" {
+		return "", fmt.Errorf("epic %s branch: template %q renders an empty name", plan.EpicKey, tmpl)
+	}
+	return name, nil
+}
+
+// branchProtected reports whether branch matches an entry of
+// guardrails.protected_branches, either exactly or as a glob.
+func branchProtected(branch string, protected []string) bool {
+	for _, pattern := range protected {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if pattern == branch {
+			return true
+		}
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// enforceBranchPolicy puts the checkout on the plan's epic branch and
+// refuses to launch on a protected branch. Without an epic branch the
+// current branch is only checked against guardrails.protected_branches.
+func enforceBranchPolicy(cfg *config.Config, plan sessionPlan) error {
+	protected := cfg.Guardrails.ProtectedBranches
+	dir := gitRunDir(plan)
+	if plan.Branch != "" {
+		if branchProtected(plan.Branch, protected) {
+			return fmt.Errorf("epic %s branch %q is listed in guardrails.protected_branches", plan.EpicKey, plan.Branch)
+		}
+		return checkoutEpicBranch(dir, plan.Branch)
+	}
+	if len(protected) == 0 {
+		return nil
+	}
+	current, err := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		// Outside a git checkout there is no branch to protect.
+		return nil
+	}
+	if branchProtected(current, protected) {
+		return newExitError(fmt.Sprintf("Refusing to run Codex on protected branch %s (guardrails.protected_branches); switch branches or set branch = \"...\" on the epic.", current))
+	}
+	return nil
+}
+
+// checkoutEpicBranch switches dir to branch, creating it from the current
+// HEAD when it does not exist yet.
+func checkoutEpicBranch(dir, branch string) error {
+	current, err := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return fmt.Errorf("epic branch %s: %s is not inside a git checkout", branch, dir)
+	}
+	if current == branch {
+		return nil
+	}
+	if _, err := gitOutput(dir, "check-ref-format", "--branch", branch); err != nil {
+		return fmt.Errorf("epic branch %q is not a valid branch name", branch)
+	}
+	args := []string{"checkout", branch}
+	verb := "Switched to"
+	if _, err := gitOutput(dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err != nil {
+		args = []string{"checkout", "-b", branch}
+		verb = "Created"
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(out)))
+	}
+	fmt.Printf("%s epic branch %s (was %s).\n", verb, branch, current)
+	return nil
+}
+
+// branchLanded checks that a session's commits are on its epic branch, not
+// somewhere Codex switched to, by asking git whether the session's last
+// commit is an ancestor of the branch in dir. A session without commits
+// only has to end on the branch.
+func branchLanded(dir, branch string, git *gitMetadata) error {
+	if branch == "" || git == nil {
+		return nil
+	}
+	end := git.Branch
+	if git.BranchAfter != "" {
+		end = git.BranchAfter
+	}
+	if len(git.Commits) > 0 && git.HeadAfter != "" {
+		landed, err := gitIsAncestor(dir, git.HeadAfter, branch)
+		if err != nil {
+			return fmt.Errorf("check the session's commits against epic branch %s: %w", branch, err)
+		}
+		if landed {
+			return nil
+		}
+		if end == branch {
+			return fmt.Errorf("the session's commits are not on epic branch %s", branch)
+		}
+	} else if end == branch {
+		return nil
+	}
+	if end == "" || end == "HEAD" {
+		end = "a detached HEAD"
+	}
+	return fmt.Errorf("session ended on %s instead of epic branch %s; its commits did not land there", end, branch)
+}
+
+// gitIsAncestor reports whether commit is reachable from ref.
+func gitIsAncestor(dir, commit, ref string) (bool, error) {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", commit, ref)
+	cmd.Dir = dir
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return false, nil
+	default:
+		return false, fmt.Errorf("git merge-base: %w", err)
+	}
+}
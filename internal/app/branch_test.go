@@ -0,0 +1,106 @@
+package app
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestRenderEpicBranch(t *testing.T) {
+	plan := sessionPlan{EpicKey: "obi_api", Alias: "api", EpicID: "obi-api"}
+	if got, err := renderEpicBranch("", plan); err != nil || got != "" {
+		t.Fatalf("empty template = %q, %v", got, err)
+	}
+	if got, err := renderEpicBranch("epic/{{.Alias}}-{{.EpicID}}", plan); err != nil || got != "epic/api-obi-api" {
+		t.Fatalf("rendered %q, %v", got, err)
+	}
+	if _, err := renderEpicBranch("epic/{{.Bead}}", plan); err == nil || !strings.Contains(err.Error(), "epic obi_api branch") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestBranchLandedComparesEndingBranch(t *testing.T) {
+	if err := branchLanded("", "", &gitMetadata{Branch: "main"}); err != nil {
+		t.Fatalf("no epic branch should pass, got %v", err)
+	}
+	if err := branchLanded("", "epic/api", &gitMetadata{Branch: "epic/api"}); err != nil {
+		t.Fatalf("unchanged branch should pass, got %v", err)
+	}
+	err := branchLanded("", "epic/api", &gitMetadata{Branch: "epic/api", BranchAfter: "main"})
+	if err == nil || !strings.Contains(err.Error(), "ended on main instead of epic branch epic/api") {
+		t.Fatalf("expected branch switch error, got %v", err)
+	}
+	if err := branchLanded("", "epic/api", &gitMetadata{Branch: "epic/api", BranchAfter: "HEAD"}); err == nil || !strings.Contains(err.Error(), "detached HEAD") {
+		t.Fatalf("expected detached HEAD error, got %v", err)
+	}
+}
+
+func TestEnforceBranchPolicyChecksOutEpicBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=obi", "-c", "user.email=obi@example.com"}, args...)...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "main")
+	git("commit", "-q", "--allow-empty", "-m", "root")
+
+	cfg := &config.Config{Guardrails: config.GuardrailsConfig{ProtectedBranches: []string{"main", "release/*"}}}
+	plan := sessionPlan{EpicKey: "obi_api", RepoRoot: repo}
+	err := enforceBranchPolicy(cfg, plan)
+	if err == nil || !strings.Contains(err.Error(), "protected branch main") {
+		t.Fatalf("expected main to be refused, got %v", err)
+	}
+
+	plan.Branch = "release/1.0"
+	if err := enforceBranchPolicy(cfg, plan); err == nil || !strings.Contains(err.Error(), "protected_branches") {
+		t.Fatalf("expected protected epic branch to be refused, got %v", err)
+	}
+
+	plan.Branch = "epic/api"
+	if err := enforceBranchPolicy(cfg, plan); err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+	if got := git("rev-parse", "--abbrev-ref", "HEAD"); got != "epic/api" {
+		t.Fatalf("checked out %q, want epic/api", got)
+	}
+
+	meta := captureGitBefore(repo)
+	git("checkout", "-q", "main")
+	if err := branchLanded(repo, plan.Branch, meta.finish(repo)); err == nil || meta.BranchAfter != "main" {
+		t.Fatalf("expected the switch to main to be caught, got %v (%+v)", err, meta)
+	}
+	if err := enforceBranchPolicy(cfg, plan); err != nil {
+		t.Fatalf("switch back: %v", err)
+	}
+	if got := git("rev-parse", "--abbrev-ref", "HEAD"); got != "epic/api" {
+		t.Fatalf("checked out %q, want epic/api", got)
+	}
+
+	// Commits made on another branch have not landed until the epic
+	// branch contains them, whatever branch the session ends on.
+	meta = captureGitBefore(repo)
+	git("checkout", "-q", "-b", "side")
+	git("commit", "-q", "--allow-empty", "-m", "side work")
+	git("checkout", "-q", "epic/api")
+	meta.HeadAfter = git("rev-parse", "side")
+	meta.Commits = []string{meta.HeadAfter}
+	if err := branchLanded(repo, plan.Branch, meta); err == nil || !strings.Contains(err.Error(), "not on epic branch epic/api") {
+		t.Fatalf("expected commits off the epic branch to be caught, got %v", err)
+	}
+	git("merge", "-q", "--ff-only", "side")
+	meta.BranchAfter = "side"
+	if err := branchLanded(repo, plan.Branch, meta); err != nil {
+		t.Fatalf("commits merged into the epic branch should pass, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// Answers at a --checkpoint between loop sessions.
+const (
+	checkpointContinue = "continue"
+	checkpointSkip     = "skip"
+	checkpointBreak    = "break"
+	checkpointStop     = "stop"
+)
+
+// checkpointChoice is the operator's answer at a loop checkpoint; pause is
+// the length of a break.
+type checkpointChoice struct {
+	action string
+	pause  time.Duration
+}
+
+// parseCheckpointChoice reads c, s, b <minutes> or q (or the spelled-out
+// words). An empty answer continues.
+func parseCheckpointChoice(input string) (checkpointChoice, error) {
+	fields := strings.Fields(strings.ToLower(input))
+	if len(fields) == 0 {
+		return checkpointChoice{action: checkpointContinue}, nil
+	}
+	switch fields[0] {
+	case "c", "continue":
+		if len(fields) == 1 {
+			return checkpointChoice{action: checkpointContinue}, nil
+		}
+	case "s", "skip":
+		if len(fields) == 1 {
+			return checkpointChoice{action: checkpointSkip}, nil
+		}
+	case "q", "stop":
+		if len(fields) == 1 {
+			return checkpointChoice{action: checkpointStop}, nil
+		}
+	case "b", "break":
+		if len(fields) != 2 {
+			return checkpointChoice{}, fmt.Errorf("how long a break? e.g. b 15 for 15 minutes")
+		}
+		minutes, err := strconv.Atoi(fields[1])
+		if err != nil || minutes <= 0 {
+			return checkpointChoice{}, fmt.Errorf("break length %q: want a positive number of minutes", fields[1])
+		}
+		return checkpointChoice{action: checkpointBreak, pause: time.Duration(minutes) * time.Minute}, nil
+	}
+	return checkpointChoice{}, fmt.Errorf("unknown answer %q: want c, s, b <minutes> or q", strings.TrimSpace(input))
+}
+
+// askCheckpoint shows the checkpoint menu and waits up to countdown for an
+// answer, continuing when none arrives. read returns false when the time
+// ran out or the input closed. nextBead is the bead the next session
+// would most likely take; without one there is nothing to skip.
+func askCheckpoint(read func(time.Duration) (string, bool), out io.Writer, countdown time.Duration, nextBead string) checkpointChoice {
+	skip := "[s] skip the next bead"
+	if nextBead != "" {
+		skip = "[s] skip " + nextBead
+	}
+	fmt.Fprintf(out, "\nCheckpoint: [c] continue, %s, [b N] break N minutes, [q] stop and summarize.\n", skip)
+	deadline := time.Now().Add(countdown)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+		fmt.Fprintf(out, "Choice (continuing in %s): ", remaining.Round(time.Second))
+		line, ok := read(remaining)
+		if !ok {
+			fmt.Fprintln(out)
+			break
+		}
+		choice, err := parseCheckpointChoice(line)
+		if err == nil && choice.action == checkpointSkip && nextBead == "" {
+			err = fmt.Errorf("no ready bead is known to skip")
+		}
+		if err != nil {
+			fmt.Fprintf(out, "%v.\n", err)
+			continue
+		}
+		return choice
+	}
+	fmt.Fprintln(out, "No answer; continuing.")
+	return checkpointChoice{action: checkpointContinue}
+}
+
+// loopCheckpoint runs the --checkpoint menu between two sessions of an
+// epic loop, applying skips and breaks to plan. It reports whether the
+// loop should stop and, if so, whether the epic counts as finished so the
+// omnibus summary runs. Without a terminal on stdin nobody can answer, so
+// the loop simply continues.
+func loopCheckpoint(plan *sessionPlan, opts goOptions, cfg *config.Config) (stop, finished bool, err error) {
+	if opts.checkpoint <= 0 || opts.ci || !stdinIsTerminal() {
+		return false, false, nil
+	}
+	var nextBead string
+	if issues, err := fetchReadyIssues(); err == nil {
+		if beads := readyBeadsForPlan(*plan, issues); len(beads) > 0 {
+			nextBead = beads[0].ID
+		}
+	}
+	readStdin := func(d time.Duration) (string, bool) { return readLineWithin(os.Stdin, d) }
+	choice := askCheckpoint(readStdin, os.Stdout, opts.checkpoint, nextBead)
+	switch choice.action {
+	case checkpointContinue:
+		return false, false, nil
+	case checkpointStop:
+		fmt.Printf("Stopping the loop for %s (%s) at the checkpoint.\n", plan.EpicName, plan.EpicID)
+		return true, true, nil
+	case checkpointSkip:
+		plan.SkippedBeads = append(plan.SkippedBeads, nextBead)
+		fmt.Printf("Skipping %s for the rest of this run.\n", nextBead)
+	case checkpointBreak:
+		fmt.Printf("Taking a break until %s.\n", time.Now().Add(choice.pause).Format("15:04"))
+		time.Sleep(choice.pause)
+		maybeSyncBeads(cfg, plan.RepoRoot)
+	}
+	hasWork, err := readyWorkAvailable(*plan)
+	if err != nil {
+		return true, false, err
+	}
+	if !hasWork {
+		fmt.Printf("No ready beads remain for %s (%s).\n", plan.EpicName, plan.EpicID)
+		return true, len(plan.SkippedBeads) == 0, nil
+	}
+	return false, false, nil
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCheckpointChoice(t *testing.T) {
+	cases := map[string]checkpointChoice{
+		"":         {action: checkpointContinue},
+		"c":        {action: checkpointContinue},
+		" Skip ":   {action: checkpointSkip},
+		"b 15":     {action: checkpointBreak, pause: 15 * time.Minute},
+		"break 1":  {action: checkpointBreak, pause: time.Minute},
+		"q":        {action: checkpointStop},
+		"stop":     {action: checkpointStop},
+		"continue": {action: checkpointContinue},
+	}
+	for input, want := range cases {
+		got, err := parseCheckpointChoice(input)
+		if err != nil || got != want {
+			t.Errorf("parseCheckpointChoice(%q) = %+v, %v; want %+v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"b", "b 0", "b soon", "x", "s now"} {
+		if _, err := parseCheckpointChoice(input); err == nil {
+			t.Errorf("parseCheckpointChoice(%q) accepted", input)
+		}
+	}
+}
+
+func TestAskCheckpointRepromptsAndDefaultsToContinue(t *testing.T) {
+	answers := []string{"later", "s", "b 5"}
+	read := func(time.Duration) (string, bool) {
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, true
+	}
+	var out strings.Builder
+	got := askCheckpoint(read, &out, time.Minute, "")
+	if got.action != checkpointBreak || got.pause != 5*time.Minute {
+		t.Fatalf("choice = %+v, want a 5 minute break", got)
+	}
+	for _, want := range []string{"[s] skip the next bead", `unknown answer "later"`, "no ready bead is known to skip"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	timedOut := func(time.Duration) (string, bool) { return "", false }
+	if got := askCheckpoint(timedOut, &out, time.Minute, "api-1.4"); got.action != checkpointContinue {
+		t.Fatalf("choice after timeout = %+v, want continue", got)
+	}
+	if !strings.Contains(out.String(), "[s] skip api-1.4") || !strings.Contains(out.String(), "No answer; continuing.") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestSkippedBeadsStayOutOfTheLoop(t *testing.T) {
+	plan := sessionPlan{EpicID: "api-1", SkippedBeads: []string{"api-1.4"}}
+	if got := buildPrompt(plan); !strings.Contains(got, "do not pick them:\n- api-1.4") {
+		t.Fatalf("prompt does not name the skipped bead:\n%s", got)
+	}
+	issues := []readyIssue{{ID: "api-1.4"}, {ID: "api-1.5"}}
+	if beads := readyBeadsForPlan(plan, issues); len(beads) != 1 || beads[0].ID != "api-1.5" {
+		t.Fatalf("ready beads = %+v, want only api-1.5", beads)
+	}
+	if ok, err := hasReadyIssueForPlan(plan, issues[:1]); ok || err != nil {
+		t.Fatalf("hasReadyIssueForPlan with only the skipped bead = %v, %v", ok, err)
+	}
+	plan.ResumeEnabled = true
+	plan.ResumeCompletedBeads = []string{"api-1.2"}
+	if ok, err := hasReadyIssueForPlan(plan, issues[:1]); ok || err != nil {
+		t.Fatalf("with --resume, skipping the last ready bead should end the loop cleanly, got %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,80 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// readLineWithin reads one line from f, giving up after d. It reads a
+// non-blocking duplicate of the descriptor under a deadline, so a prompt
+// nobody answered leaves no reader behind to swallow the next session's
+// keystrokes.
+func readLineWithin(f *os.File, d time.Duration) (string, bool) {
+	fd, err := syscall.Dup(int(f.Fd()))
+	if err != nil {
+		return "", false
+	}
+	// O_NONBLOCK lives on the open file description the duplicate shares
+	// with f, and with the shell once obi exits, so it is cleared again
+	// even when a signal ends obi mid-prompt.
+	restore := func() { _ = syscall.SetNonblock(fd, false) }
+	stopWatch := restoreOnSignal(restore)
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		stopWatch()
+		_ = syscall.Close(fd)
+		return "", false
+	}
+	dup := os.NewFile(uintptr(fd), f.Name())
+	defer func() {
+		stopWatch()
+		restore()
+		_ = dup.Close()
+	}()
+	if err := dup.SetReadDeadline(time.Now().Add(d)); err != nil {
+		return "", false
+	}
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		// One byte at a time, so nothing past the newline is consumed.
+		n, err := dup.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return string(line), true
+			}
+			line = append(line, buf[0])
+		}
+		if err != nil {
+			return string(line), len(line) > 0
+		}
+	}
+}
+
+// restoreOnSignal runs restore before an interrupt, SIGTERM or SIGHUP
+// takes effect, then raises the signal again so it does what it would
+// have done anyway. The returned func stops watching.
+func restoreOnSignal(restore func()) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		select {
+		case sig := <-signals:
+			restore()
+			signal.Stop(signals)
+			_ = syscall.Kill(os.Getpid(), sig.(syscall.Signal))
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+		signal.Stop(signals)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReadLineWithinStopsAtNewlineAndDeadline(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	started := time.Now()
+	if line, ok := readLineWithin(r, 50*time.Millisecond); ok || line != "" {
+		t.Fatalf("read without input = %q, %v", line, ok)
+	}
+	if waited := time.Since(started); waited > 5*time.Second {
+		t.Fatalf("deadline ignored; waited %s", waited)
+	}
+
+	if _, err := w.WriteString("b 5\nrest"); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if line, ok := readLineWithin(r, time.Second); !ok || line != "b 5" {
+		t.Fatalf("line = %q, %v; want \"b 5\"", line, ok)
+	}
+	rest, err := io.ReadAll(r)
+	if err != nil || string(rest) != "rest" {
+		t.Fatalf("left after the line: %q, %v", rest, err)
+	}
+}
@@ -0,0 +1,305 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cleanRemoval is one file obi clean deletes, or a whole directory when
+// Dir is set.
+type cleanRemoval struct {
+	Path   string
+	Reason string
+	Bytes  int64
+	Dir    bool
+}
+
+// cleanPlan lists what obi clean will do; it is built first so --dry-run
+// can print it without touching anything.
+type cleanPlan struct {
+	Removals  []cleanRemoval
+	StatePath string
+	// StateKeep holds the state records that survive compaction; it is only
+	// applied when StateDropped > 0.
+	StateKeep     []stateRecord
+	StateDropped  int
+	StateSessions int
+	StateBytes    int64
+}
+
+func (p cleanPlan) reclaimed() int64 {
+	total := p.StateBytes
+	for _, rm := range p.Removals {
+		total += rm.Bytes
+	}
+	return total
+}
+
+func runClean(args []string) error {
+	fs := newCommandFlags("clean", "obi clean [options]",
+		"Delete transcripts and artifacts older than the retention window, remove files left behind by\ninterrupted runs, and drop finished or crashed sessions from the state file.")
+	var configPath, olderThan string
+	var dryRun bool
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.StringVar(&olderThan, "older-than", "30d", "delete transcripts last written before this window (e.g. 30d, 72h) or date (YYYY-MM-DD)")
+	fs.BoolVar(&dryRun, "dry-run", false, "list what would be removed without deleting anything")
+	if _, err := fs.parse(args); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	cutoff, err := parseDigestSince(olderThan, now)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than %q (want e.g. 30d, 72h, or 2026-03-01)", olderThan)
+	}
+	_, cfg, err := loadConfig(configPath, "")
+	if err != nil {
+		return err
+	}
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+	statePath, err := cfg.StateFilePath()
+	if err != nil {
+		return err
+	}
+	host, _ := os.Hostname()
+	plan, err := buildCleanPlan(logPath, statePath, cutoff, now, host, processAlive)
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		if err := applyCleanPlan(plan); err != nil {
+			return err
+		}
+	}
+	fmt.Print(formatCleanPlan(plan, dryRun))
+	return nil
+}
+
+// buildCleanPlan collects transcripts and artifact directories last written
+// before cutoff, verify logs whose transcript is gone, leftover ledger migration files, and the
+// state records of sessions that finished, crashed, or went stale before
+// cutoff. Transcripts of live sessions are never touched.
+func buildCleanPlan(logPath, statePath string, cutoff, now time.Time, host string, alive func(int) bool) (cleanPlan, error) {
+	plan := cleanPlan{StatePath: statePath}
+
+	records, err := readStateRecords(statePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return cleanPlan{}, err
+	}
+	statuses := summarizeSessions(records, now, host, alive)
+	running := map[string]bool{}
+	keep := map[string]bool{}
+	for _, st := range statuses {
+		switch {
+		case st.Health == "live":
+			running[st.Last.SessionID] = true
+			keep[st.Last.SessionID] = true
+		case st.Health == "stale" && !st.Last.Time.Before(cutoff):
+			// Possibly hung rather than dead; leave it for obi status.
+			running[st.Last.SessionID] = true
+			keep[st.Last.SessionID] = true
+		default:
+			plan.StateSessions++
+		}
+	}
+	for _, rec := range records {
+		if keep[rec.SessionID] {
+			plan.StateKeep = append(plan.StateKeep, rec)
+		} else {
+			plan.StateDropped++
+		}
+	}
+	if info, err := os.Stat(statePath); err == nil && plan.StateDropped > 0 {
+		plan.StateBytes = info.Size() - encodedStateSize(plan.StateKeep)
+		if plan.StateBytes < 0 {
+			plan.StateBytes = 0
+		}
+	} else {
+		plan.StateDropped, plan.StateSessions = 0, 0
+	}
+
+	files, err := transcriptFiles(transcriptDirFor(logPath))
+	if err != nil {
+		return cleanPlan{}, err
+	}
+	transcribed := map[string]bool{}
+	for _, path := range files {
+		if name := filepath.Base(path); isSessionTranscript(name) {
+			transcribed[transcriptSession(name)] = true
+		}
+	}
+	for _, path := range files {
+		name := filepath.Base(path)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		session := transcriptSession(name)
+		if running[session] {
+			continue
+		}
+		switch {
+		case info.ModTime().Before(cutoff):
+			plan.Removals = append(plan.Removals, cleanRemoval{Path: path, Reason: "older than retention window", Bytes: info.Size()})
+		case strings.HasSuffix(name, ".verify.log") && !transcribed[session]:
+			plan.Removals = append(plan.Removals, cleanRemoval{Path: path, Reason: "verify log without a transcript", Bytes: info.Size()})
+		}
+	}
+
+	artifactRuns, err := os.ReadDir(artifactsDirFor(logPath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return cleanPlan{}, err
+	}
+	for _, run := range artifactRuns {
+		if !run.IsDir() || running[run.Name()] {
+			continue
+		}
+		path := filepath.Join(artifactsDirFor(logPath), run.Name())
+		newest, size := artifactRunAge(path)
+		if newest.Before(cutoff) {
+			plan.Removals = append(plan.Removals, cleanRemoval{Path: path, Reason: "artifacts older than retention window", Bytes: size, Dir: true})
+		}
+	}
+
+	// obi ledger migrate, obi ledger verify --fix, and the in-place upgrade
+	// of older releases rename these into place; one left behind means the
+	// rewrite was interrupted and the results log itself is intact.
+	for _, suffix := range []string{".migrate", ".repair", ".upgrade"} {
+		leftover := logPath + suffix
+		if info, err := os.Stat(leftover); err == nil && !info.IsDir() {
+			plan.Removals = append(plan.Removals, cleanRemoval{Path: leftover, Reason: "interrupted ledger rewrite", Bytes: info.Size()})
+		}
+	}
+	sort.SliceStable(plan.Removals, func(i, j int) bool { return plan.Removals[i].Path < plan.Removals[j].Path })
+	return plan, nil
+}
+
+// artifactRunAge returns when a run's artifacts directory last changed and
+// the bytes it holds.
+func artifactRunAge(dir string) (time.Time, int64) {
+	var newest time.Time
+	var size int64
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return newest, size
+}
+
+// transcriptSession returns the session ID a transcript file belongs to;
+// transcript_name_template names carry it among other fields.
+func transcriptSession(name string) string {
+	if ids := sessionUUIDPattern.FindAllString(name, -1); len(ids) > 0 {
+		return ids[len(ids)-1]
+	}
+	name = strings.TrimSuffix(name, ".prompt.txt")
+	name = strings.TrimSuffix(name, ".log")
+	return strings.TrimSuffix(name, ".verify")
+}
+
+func encodedStateSize(records []stateRecord) int64 {
+	var size int64
+	for _, rec := range records {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			continue
+		}
+		size += int64(len(line)) + 1
+	}
+	return size
+}
+
+func applyCleanPlan(plan cleanPlan) error {
+	for _, rm := range plan.Removals {
+		remove := os.Remove
+		if rm.Dir {
+			remove = os.RemoveAll
+		}
+		if err := remove(rm.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove %s: %w", rm.Path, err)
+		}
+	}
+	if plan.StateDropped == 0 {
+		return nil
+	}
+	lock, err := lockStateFile(plan.StatePath)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	// Sessions may have appended records since the plan was built; the
+	// file only grows, so they follow the records the plan saw.
+	keep := plan.StateKeep
+	current, err := readStateRecords(plan.StatePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if seen := len(plan.StateKeep) + plan.StateDropped; len(current) > seen {
+		keep = append(append([]stateRecord(nil), keep...), current[seen:]...)
+	}
+	if len(keep) == 0 {
+		if err := os.Remove(plan.StatePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove state file: %w", err)
+		}
+		return nil
+	}
+	var b strings.Builder
+	for _, rec := range keep {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("encode state record: %w", err)
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	temp := plan.StatePath + ".clean"
+	if err := os.WriteFile(temp, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := os.Rename(temp, plan.StatePath); err != nil {
+		return fmt.Errorf("replace state file: %w", err)
+	}
+	return nil
+}
+
+func formatCleanPlan(plan cleanPlan, dryRun bool) string {
+	remove, compact, reclaim := "Removed", "Compacted", "Reclaimed"
+	if dryRun {
+		remove, compact, reclaim = "Would remove", "Would compact", "Would reclaim"
+	}
+	var b strings.Builder
+	for _, rm := range plan.Removals {
+		fmt.Fprintf(&b, "%s %s (%s, %s)\n", remove, rm.Path, formatBytes(rm.Bytes), rm.Reason)
+	}
+	if plan.StateDropped > 0 {
+		fmt.Fprintf(&b, "%s %s: %d records from %d finished or crashed sessions (%s)\n",
+			compact, plan.StatePath, plan.StateDropped, plan.StateSessions, formatBytes(plan.StateBytes))
+	}
+	if len(plan.Removals) == 0 && plan.StateDropped == 0 {
+		return "Nothing to clean.\n"
+	}
+	fmt.Fprintf(&b, "%s %s.\n", reclaim, formatBytes(plan.reclaimed()))
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCleanPrunesTranscriptsOrphansAndState(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "results.log")
+	statePath := filepath.Join(dir, "state.log")
+	transcripts := transcriptDirFor(logPath)
+	if err := os.MkdirAll(transcripts, 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -40)
+	write := func(path string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("output\n"), 0o600); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("chtimes %s: %v", path, err)
+		}
+	}
+	write(filepath.Join(transcripts, "done-old.log"), old)
+	write(filepath.Join(transcripts, "done-old.verify.log"), old)
+	write(filepath.Join(transcripts, "recent.log"), now)
+	write(filepath.Join(transcripts, "orphan.verify.log"), now)
+	write(filepath.Join(transcripts, "running.log"), old)
+	write(logPath+".upgrade", now)
+	write(logPath+".migrate", now)
+
+	exit := 0
+	var state strings.Builder
+	for _, rec := range []stateRecord{
+		{Time: old, Kind: stateStart, SessionID: "done-old", PID: 10, Host: "box"},
+		{Time: old.Add(time.Minute), Kind: stateEnd, SessionID: "done-old", PID: 10, Host: "box", ExitCode: &exit},
+		{Time: now.Add(-time.Hour), Kind: stateHeartbeat, SessionID: "crashed", PID: 11, Host: "box", IntervalMs: 60000},
+		{Time: now.Add(-10 * time.Second), Kind: stateHeartbeat, SessionID: "running", PID: 12, Host: "box", IntervalMs: 60000},
+	} {
+		line, _ := json.Marshal(rec)
+		state.Write(line)
+		state.WriteByte('\n')
+	}
+	if err := os.WriteFile(statePath, []byte(state.String()), 0o600); err != nil {
+		t.Fatalf("write state: %v", err)
+	}
+
+	alive := func(pid int) bool { return pid == 12 }
+	plan, err := buildCleanPlan(logPath, statePath, now.AddDate(0, 0, -30), now, "box", alive)
+	if err != nil {
+		t.Fatalf("buildCleanPlan: %v", err)
+	}
+	var removed []string
+	for _, rm := range plan.Removals {
+		removed = append(removed, filepath.Base(rm.Path))
+	}
+	if got := strings.Join(removed, ","); got != "results.log.migrate,results.log.upgrade,done-old.log,done-old.verify.log,orphan.verify.log" {
+		t.Fatalf("unexpected removals %q", got)
+	}
+	if plan.StateDropped != 3 || plan.StateSessions != 2 || len(plan.StateKeep) != 1 {
+		t.Fatalf("unexpected state compaction: dropped %d from %d sessions, kept %d", plan.StateDropped, plan.StateSessions, len(plan.StateKeep))
+	}
+
+	if out := formatCleanPlan(plan, true); !strings.Contains(out, "Would remove") || !strings.Contains(out, "Would reclaim") {
+		t.Fatalf("unexpected dry-run output:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(transcripts, "done-old.log")); err != nil {
+		t.Fatalf("dry run must not delete: %v", err)
+	}
+
+	// A session that starts while obi clean is deciding keeps its record.
+	if err := appendStateRecord(statePath, stateRecord{Time: now, Kind: stateStart, SessionID: "late", PID: 13, Host: "box"}); err != nil {
+		t.Fatalf("append state: %v", err)
+	}
+	if err := applyCleanPlan(plan); err != nil {
+		t.Fatalf("applyCleanPlan: %v", err)
+	}
+	for _, name := range []string{"done-old.log", "orphan.verify.log"} {
+		if _, err := os.Stat(filepath.Join(transcripts, name)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"recent.log", "running.log"} {
+		if _, err := os.Stat(filepath.Join(transcripts, name)); err != nil {
+			t.Fatalf("expected %s to survive: %v", name, err)
+		}
+	}
+	records, err := readStateRecords(statePath)
+	if err != nil {
+		t.Fatalf("read state: %v", err)
+	}
+	if len(records) != 2 || records[0].SessionID != "running" || records[1].SessionID != "late" {
+		t.Fatalf("expected the running and late sessions to remain, got %+v", records)
+	}
+}
+
+func TestCleanNothingToDo(t *testing.T) {
+	dir := t.TempDir()
+	plan, err := buildCleanPlan(filepath.Join(dir, "results.log"), filepath.Join(dir, "state.log"), time.Now().AddDate(0, 0, -30), time.Now(), "box", nil)
+	if err != nil {
+		t.Fatalf("buildCleanPlan: %v", err)
+	}
+	if out := formatCleanPlan(plan, false); out != "Nothing to clean.\n" {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// dirtyListLimit caps how many changed paths the clean-tree error lists.
+const dirtyListLimit = 10
+
+// dirtyEntry is one path git status reports as changed or untracked.
+type dirtyEntry struct {
+	// Status is the two-letter porcelain code, e.g. " M" or "??".
+	Status string
+	// Path is relative to the repository root, with forward slashes.
+	Path string
+}
+
+// ensureCleanTree enforces guardrails.require_clean_tree for a run in dir:
+// any change outside obi's own files and allow_dirty aborts the launch so
+// Codex's commits never absorb the operator's unfinished work.
+func ensureCleanTree(cfg *config.Config, dir, configPath string) error {
+	if !cfg.Guardrails.RequireCleanTree {
+		return nil
+	}
+	top, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("guardrails.require_clean_tree: %s is not inside a git checkout", dir)
+	}
+	cmd := exec.Command("git", "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	cmd.Dir = dir
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("guardrails.require_clean_tree: git status: %w", err)
+	}
+	allow := append(obiOwnedPaths(cfg, top, configPath), cfg.Guardrails.AllowDirty...)
+	dirty := unexpectedChanges(parsePorcelainZ(stdout.Bytes()), allow)
+	if len(dirty) == 0 {
+		return nil
+	}
+	return newExitError(formatDirtyTree(dirty))
+}
+
+// parsePorcelainZ reads `git status --porcelain=v1 -z` output. Renames and
+// copies carry their original path as an extra record, which is skipped.
+func parsePorcelainZ(out []byte) []dirtyEntry {
+	var entries []dirtyEntry
+	records := strings.Split(string(out), "\x00")
+	for i := 0; i < len(records); i++ {
+		rec := records[i]
+		if len(rec) < 4 {
+			continue
+		}
+		status := rec[:2]
+		entries = append(entries, dirtyEntry{Status: status, Path: rec[3:]})
+		if status[0] == 'R' || status[0] == 'C' {
+			i++
+		}
+	}
+	return entries
+}
+
+// unexpectedChanges drops entries covered by allow.
+func unexpectedChanges(entries []dirtyEntry, allow []string) []dirtyEntry {
+	var dirty []dirtyEntry
+	for _, entry := range entries {
+		if !pathAllowed(entry.Path, allow) {
+			dirty = append(dirty, entry)
+		}
+	}
+	return dirty
+}
+
+// pathAllowed reports whether p (slash-separated, relative to the git root)
+// is an allowed file, lies under an allowed directory, or matches a glob.
+func pathAllowed(p string, allow []string) bool {
+	for _, pattern := range allow {
+		pattern = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(pattern)), "./")
+		if pattern == "" {
+			continue
+		}
+		dir := strings.TrimSuffix(pattern, "/")
+		if p == dir || strings.HasPrefix(p, dir+"/") {
+			return true
+		}
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// obiOwnedPaths returns the config, logs, transcripts, artifact copies, and
+// experiment logs and worktrees obi itself writes, relative to top, so a
+// results log kept in the repo never trips the check.
+func obiOwnedPaths(cfg *config.Config, top, configPath string) []string {
+	var owned []string
+	if sources, err := config.Sources(configPath); err == nil {
+		owned = append(owned, sources...)
+	}
+	logPath, err := cfg.ResultsLogPath()
+	if err == nil {
+		// Backups and interrupted rewrites share the results log's name.
+		owned = append(owned, logPath+"*", transcriptDirFor(logPath), simulationDir(logPath), artifactsDirFor(logPath), experimentDir(logPath))
+	}
+	for _, resolve := range []func() (string, error){cfg.AuditLogPath, cfg.StateFilePath, cfg.WebhookLogPath, cfg.QALogPath, cfg.ScheduleLogPath, cfg.ScheduleLockPath} {
+		if p, err := resolve(); err == nil {
+			owned = append(owned, p)
+		}
+	}
+	if p, err := cfg.StateFilePath(); err == nil {
+		owned = append(owned, p+".lock")
+	}
+	var rel []string
+	for _, p := range owned {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			continue
+		}
+		// git reports top with symlinks resolved (e.g. /private/var on macOS).
+		if dir, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+			abs = filepath.Join(dir, filepath.Base(abs))
+		}
+		r, err := filepath.Rel(top, abs)
+		if err != nil || !filepath.IsLocal(r) {
+			continue
+		}
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	return rel
+}
+
+func formatDirtyTree(dirty []dirtyEntry) string {
+	lines := []string{"Working tree has uncommitted changes; commit or stash them, or list the paths under [guardrails] allow_dirty, before launching Codex (guardrails.require_clean_tree):"}
+	for i, entry := range dirty {
+		if i == dirtyListLimit {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(dirty)-dirtyListLimit))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s", entry.Status, entry.Path))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestParsePorcelainZSkipsRenameSources(t *testing.T) {
+	out := " M src/main.go\x00R  new name.go\x00old name.go\x00?? notes/todo.txt\x00"
+	got := parsePorcelainZ([]byte(out))
+	want := []dirtyEntry{
+		{Status: " M", Path: "src/main.go"},
+		{Status: "R ", Path: "new name.go"},
+		{Status: "??", Path: "notes/todo.txt"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parsePorcelainZ = %+v, want %+v", got, want)
+	}
+}
+
+func TestUnexpectedChangesHonorsAllowlist(t *testing.T) {
+	entries := []dirtyEntry{
+		{Status: " M", Path: "obi.toml"},
+		{Status: " M", Path: ".beads/issues.jsonl"},
+		{Status: "??", Path: "docs/notes.md"},
+		{Status: " M", Path: "src/main.go"},
+		{Status: "??", Path: "obi-results.log.bak-20261016"},
+	}
+	allow := []string{"./obi.toml", ".beads/", "docs/*.md", "obi-results.log*"}
+	got := unexpectedChanges(entries, allow)
+	if len(got) != 1 || got[0].Path != "src/main.go" {
+		t.Fatalf("unexpectedChanges = %+v", got)
+	}
+}
+
+func TestEnsureCleanTreeChecksGitStatus(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	configPath := filepath.Join(repo, "obi.toml")
+	write := func(name, body string) {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	write("obi.toml", "results_log = \"obi-results.log\"\n")
+	write("obi-results.log", "{}\n")
+	write(filepath.Join("transcripts", "abc.log"), "output\n")
+	write(filepath.Join("artifacts", "run-1", "coverage.out"), "mode: set\n")
+	write(filepath.Join("experiments", "experiments.log"), "{}\n")
+
+	cfg := &config.Config{ResultsLog: filepath.Join(repo, "obi-results.log")}
+	if err := ensureCleanTree(cfg, repo, configPath); err != nil {
+		t.Fatalf("guard off should pass, got %v", err)
+	}
+	cfg.Guardrails = config.GuardrailsConfig{RequireCleanTree: true, AllowDirty: []string{"scratch/"}}
+	if err := ensureCleanTree(cfg, repo, configPath); err != nil {
+		t.Fatalf("obi's own files should not count as dirty, got %v", err)
+	}
+
+	write(filepath.Join("scratch", "idea.txt"), "later\n")
+	write("main.go", "package main\n")
+	err := ensureCleanTree(cfg, repo, configPath)
+	if err == nil || !strings.Contains(err.Error(), "?? main.go") || strings.Contains(err.Error(), "scratch") {
+		t.Fatalf("expected main.go to block the launch, got %v", err)
+	}
+}
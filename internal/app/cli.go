@@ -0,0 +1,114 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// commandFlags wraps a subcommand's flag set with its positional arguments so
+// flags and positionals can be interleaved in any order (`obi go foo --resume`
+// and `obi go --resume foo` parse the same). Whether a flag takes a value is
+// read from the flag definition itself, so new options need no parser edits.
+type commandFlags struct {
+	*flag.FlagSet
+	usage       string
+	summary     string
+	positionals []string
+	help        io.Writer
+}
+
+func newCommandFlags(name, usage, summary string, positionals ...string) *commandFlags {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return &commandFlags{FlagSet: fs, usage: usage, summary: summary, positionals: positionals, help: os.Stdout}
+}
+
+// parse returns the positional arguments (at most one per declared name).
+// -h/--help prints the subcommand help and returns flag.ErrHelp, which Run
+// treats as success.
+func (c *commandFlags) parse(args []string) ([]string, error) {
+	var flagArgs, positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positional = append(positional, arg)
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if logName, value, hasValue := strings.Cut(name, "="); isLogFlag(logName) {
+			// The global logging flags are accepted after any command.
+			if !hasValue {
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("flag %s requires a value", arg)
+				}
+				i++
+				value = args[i]
+			}
+			if err := setLogFlag(logName, value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		flagArgs = append(flagArgs, arg)
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if name == "h" || name == "help" {
+			c.printHelp()
+			return nil, flag.ErrHelp
+		}
+		def := c.Lookup(name)
+		if def == nil {
+			return nil, fmt.Errorf("unknown flag %s for obi %s (see obi %s --help)", arg, c.Name(), c.Name())
+		}
+		if isBoolFlag(def) {
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, fmt.Errorf("flag %s requires a value", arg)
+		}
+		i++
+		flagArgs = append(flagArgs, args[i])
+	}
+
+	if err := c.Parse(flagArgs); err != nil {
+		return nil, fmt.Errorf("parse flags: %w", err)
+	}
+	if len(positional) > len(c.positionals) {
+		return nil, fmt.Errorf("unexpected extra arguments: %s", strings.Join(positional[len(c.positionals):], " "))
+	}
+	return positional, nil
+}
+
+func (c *commandFlags) printHelp() {
+	fmt.Fprintf(c.help, "Usage: %s\n\n%s\n", c.usage, c.summary)
+	var hasFlags bool
+	c.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if !hasFlags {
+		return
+	}
+	fmt.Fprintln(c.help, "\nOptions:")
+	c.SetOutput(c.help)
+	c.PrintDefaults()
+	c.SetOutput(io.Discard)
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// positionalArg returns the i-th positional argument or "".
+func positionalArg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestCommandFlagsInterleavesBoolAndValueFlags(t *testing.T) {
+	fs := newCommandFlags("demo", "obi demo [target]", "Demo command.", "target")
+	verbose := fs.Bool("verbose", false, "")
+	level := fs.String("level", "", "")
+
+	positional, err := fs.parse([]string{"--verbose", "target", "--level", "3"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !*verbose || *level != "3" {
+		t.Fatalf("flags not applied: verbose=%v level=%q", *verbose, *level)
+	}
+	if len(positional) != 1 || positional[0] != "target" {
+		t.Fatalf("expected bool flag not to swallow the positional, got %v", positional)
+	}
+}
+
+func TestCommandFlagsDoubleDashEndsFlags(t *testing.T) {
+	fs := newCommandFlags("demo", "obi demo [target]", "Demo command.", "target")
+	positional, err := fs.parse([]string{"--", "-odd-alias"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(positional) != 1 || positional[0] != "-odd-alias" {
+		t.Fatalf("unexpected positional: %v", positional)
+	}
+}
+
+func TestCommandFlagsRejectsUnknownFlags(t *testing.T) {
+	fs := newCommandFlags("demo", "obi demo", "Demo command.")
+	_, err := fs.parse([]string{"--bogus", "value"})
+	if err == nil || !strings.Contains(err.Error(), "unknown flag --bogus") {
+		t.Fatalf("expected unknown flag error, got %v", err)
+	}
+}
+
+func TestCommandFlagsHelpPrintsUsageAndDefaults(t *testing.T) {
+	fs := newCommandFlags("demo", "obi demo [target]", "Demo command.", "target")
+	fs.String("config", "", "path to obi.toml")
+	var out bytes.Buffer
+	fs.help = &out
+
+	_, err := fs.parse([]string{"target", "--help"})
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("expected flag.ErrHelp, got %v", err)
+	}
+	text := out.String()
+	if !strings.Contains(text, "Usage: obi demo [target]") || !strings.Contains(text, "-config") {
+		t.Fatalf("unexpected help output:\n%s", text)
+	}
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+)
+
+// commitParts is a commit summary split the Conventional Commits way.
+type commitParts struct {
+	Type     string
+	Scope    string
+	Subject  string
+	Breaking bool
+	// Inferred is set when Type was guessed from the leading verb of a
+	// summary that does not follow the convention.
+	Inferred bool
+}
+
+var conventionalHeader = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]+)\))?(!)?: +(\S.*)$`)
+
+// verbCommitTypes maps the leading verb of a plain summary to the type it
+// most likely stands for.
+var verbCommitTypes = map[string]string{
+	"add": "feat", "adds": "feat", "added": "feat",
+	"implement": "feat", "implements": "feat", "implemented": "feat",
+	"introduce": "feat", "introduces": "feat", "introduced": "feat",
+	"support": "feat", "supports": "feat", "allow": "feat", "allows": "feat",
+	"fix": "fix", "fixes": "fix", "fixed": "fix", "resolve": "fix", "resolves": "fix", "resolved": "fix",
+	"correct": "fix", "corrects": "fix", "corrected": "fix", "handle": "fix", "prevent": "fix",
+	"refactor": "refactor", "refactors": "refactor", "refactored": "refactor",
+	"rename": "refactor", "renamed": "refactor", "move": "refactor", "moved": "refactor",
+	"extract": "refactor", "extracted": "refactor", "simplify": "refactor", "simplified": "refactor",
+	"document": "docs", "documents": "docs", "documented": "docs",
+	"test": "test", "tests": "test", "tested": "test",
+	"speed": "perf", "optimize": "perf", "optimized": "perf",
+	"bump": "chore", "bumped": "chore", "upgrade": "chore", "upgraded": "chore",
+	"remove": "chore", "removed": "chore", "delete": "chore", "deleted": "chore",
+	"revert": "revert", "reverts": "revert", "reverted": "revert",
+}
+
+// parseCommitSummary splits the first line of summary. A Conventional
+// Commits header is taken as-is (type lowercased); otherwise the type is
+// inferred from the first word when it is a known verb.
+func parseCommitSummary(summary string) commitParts {
+	line := strings.TrimSpace(firstLine(summary))
+	if line == "" {
+		return commitParts{}
+	}
+	if m := conventionalHeader.FindStringSubmatch(line); m != nil {
+		return commitParts{
+			Type:     strings.ToLower(m[1]),
+			Scope:    strings.TrimSpace(m[2]),
+			Subject:  strings.TrimSpace(m[4]),
+			Breaking: m[3] == "!",
+		}
+	}
+	parts := commitParts{Subject: line}
+	word, _, _ := strings.Cut(line, " ")
+	if kind, ok := verbCommitTypes[strings.ToLower(strings.Trim(word, ".,:;"))]; ok {
+		parts.Type = kind
+		parts.Inferred = true
+	}
+	return parts
+}
+
+// applyCommitParts records the parsed summary on a ledger entry.
+func applyCommitParts(entry *ledgerEntry, summary string) {
+	parts := parseCommitSummary(summary)
+	entry.CommitType = parts.Type
+	entry.CommitScope = parts.Scope
+	entry.CommitSubject = parts.Subject
+	entry.CommitBreaking = parts.Breaking
+	entry.CommitTypeInferred = parts.Inferred
+}
+
+// entryCommitType returns the entry's commit type, parsing the summary for
+// entries logged before types were recorded.
+func entryCommitType(entry ledgerEntry) string {
+	if entry.CommitType != "" {
+		return entry.CommitType
+	}
+	return parseCommitSummary(entry.CommitSummary).Type
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCommitSummary(t *testing.T) {
+	cases := []struct {
+		summary string
+		want    commitParts
+	}{
+		{"feat(tui): add approval modal\n\nbody", commitParts{Type: "feat", Scope: "tui", Subject: "add approval modal"}},
+		{"Fix!: drop legacy footer", commitParts{Type: "fix", Subject: "drop legacy footer", Breaking: true}},
+		{"Fixed crash when the ledger is empty", commitParts{Type: "fix", Subject: "Fixed crash when the ledger is empty", Inferred: true}},
+		{"Add obi clean", commitParts{Type: "feat", Subject: "Add obi clean", Inferred: true}},
+		{"Completed fake run", commitParts{Subject: "Completed fake run"}},
+		{"  ", commitParts{}},
+	}
+	for _, tc := range cases {
+		if got := parseCommitSummary(tc.summary); got != tc.want {
+			t.Errorf("parseCommitSummary(%q) = %+v, want %+v", tc.summary, got, tc.want)
+		}
+	}
+}
+
+func TestFilterHistoryByType(t *testing.T) {
+	base := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	entries := []ledgerEntry{
+		{BeadID: "obi-1", Status: "success", CommitSummary: "fix(ledger): tolerate torn lines", CommitType: "fix", CompletedAt: base},
+		// Logged before commit types were recorded.
+		{BeadID: "obi-2", Status: "success", CommitSummary: "Fix spinner flicker", CompletedAt: base.Add(time.Hour)},
+		{BeadID: "obi-3", Status: "success", CommitSummary: "feat: add history", CommitType: "feat", CompletedAt: base.Add(2 * time.Hour)},
+		{BeadID: "obi-4", Status: "needs_help", CommitSummary: "Blocked on credentials", CompletedAt: base.Add(3 * time.Hour)},
+	}
+	fixes := filterHistory(entries, "FIX", time.Time{}, 0)
+	if len(fixes) != 2 || fixes[0].BeadID != "obi-2" || fixes[1].BeadID != "obi-1" {
+		t.Fatalf("expected obi-2 then obi-1, got %+v", fixes)
+	}
+	if untyped := filterHistory(entries, untypedCommit, time.Time{}, 0); len(untyped) != 1 || untyped[0].BeadID != "obi-4" {
+		t.Fatalf("expected obi-4 as the only untyped run, got %+v", untyped)
+	}
+	if recent := filterHistory(entries, "", base.Add(90*time.Minute), 1); len(recent) != 1 || recent[0].BeadID != "obi-4" {
+		t.Fatalf("expected the newest run only, got %+v", recent)
+	}
+	out := formatHistory(fixes)
+	if !strings.Contains(out, "fix(ledger)") || !strings.Contains(out, "tolerate torn lines") {
+		t.Fatalf("unexpected history output:\n%s", out)
+	}
+}
+
+func TestSummaryPromptGroupsCommitTypes(t *testing.T) {
+	plan := sessionPlan{
+		Mode:   sessionModeSummary,
+		EpicID: "obi-epic",
+		SummaryChunks: []summaryChunk{{Index: 1, Entries: []summaryEntry{
+			{BeadID: "obi-1", CommitSummary: "fix: a", CommitType: "fix"},
+			{BeadID: "obi-2", CommitSummary: "feat: b", CommitType: "feat"},
+			{BeadID: "obi-3", CommitSummary: "fix: c", CommitType: "fix"},
+		}}},
+	}
+	prompt := buildPrompt(plan)
+	want := "Commits by type (group the message along these lines):\n- fix (2): obi-1, obi-3\n- feat (1): obi-2"
+	if !strings.Contains(prompt, want) {
+		t.Fatalf("summary prompt missing type grouping:\n%s", prompt)
+	}
+}
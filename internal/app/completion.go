@@ -1,9 +1,7 @@
 package app
 
 import (
-	"flag"
 	"fmt"
-	"io"
 	"strings"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
@@ -26,12 +24,11 @@ func runCompletion(args []string) error {
 }
 
 func runCompletionZsh(args []string) error {
-	fs := flag.NewFlagSet("completion zsh", flag.ContinueOnError)
-	fs.SetOutput(io.Discard)
+	fs := newCommandFlags("completion zsh", "obi completion zsh [options]", "Print a zsh completion script that knows the configured aliases.")
 	var configPath string
 	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
-	if err := fs.Parse(args); err != nil {
-		return fmt.Errorf("parse flags: %w", err)
+	if _, err := fs.parse(args); err != nil {
+		return err
 	}
 
 	resolved, err := config.ResolvePath(configPath)
@@ -59,6 +56,23 @@ func buildZshCompletionScript(cfg *config.Config) string {
 	sb.WriteString("    'init:scaffold or refresh obi.toml'\n")
 	sb.WriteString("    'refresh:sync obi.toml with bead epics'\n")
 	sb.WriteString("    'list:show available epics'\n")
+	sb.WriteString("    'env:print the resolved execution context'\n")
+	sb.WriteString("    'prompt:print the composed session prompt'\n")
+	sb.WriteString("    'ask:ask Codex a read-only question'\n")
+	sb.WriteString("    'plan:have Codex propose beads for an epic'\n")
+	sb.WriteString("    'report:summarize the results log'\n")
+	sb.WriteString("    'tail:follow a session transcript'\n")
+	sb.WriteString("    'audit:show the operator audit log'\n")
+	sb.WriteString("    'status:show running sessions'\n")
+	sb.WriteString("    'history:list recorded runs by commit type'\n")
+	sb.WriteString("    'diff-runs:compare two recorded runs'\n")
+	sb.WriteString("    'experiment:A/B test prompt variants on one bead'\n")
+	sb.WriteString("    'clean:prune old transcripts and stale state'\n")
+	sb.WriteString("    'ledger:migrate or verify the results log'\n")
+	sb.WriteString("    'alias:rename an epic alias'\n")
+	sb.WriteString("    'edit:edit an epic prompt in $EDITOR'\n")
+	sb.WriteString("    'run:run a [template.*] chore'\n")
+	sb.WriteString("    'schedule:run the [[schedule.jobs]] cron entries'\n")
 	sb.WriteString("    'completion:generate shell completions'\n")
 	sb.WriteString("  )\n")
 	sb.WriteString("  local -a _obi_aliases\n")
@@ -67,6 +81,12 @@ func buildZshCompletionScript(cfg *config.Config) string {
 		sb.WriteString(fmt.Sprintf("    %s\n", zshQuote(handle)))
 	}
 	sb.WriteString("  )\n")
+	sb.WriteString("  local -a _obi_templates\n")
+	sb.WriteString("  _obi_templates=(\n")
+	for _, name := range sortedTemplateNames(cfg.Templates) {
+		sb.WriteString(fmt.Sprintf("    %s\n", zshQuote(name)))
+	}
+	sb.WriteString("  )\n")
 	sb.WriteString("  local state\n")
 	sb.WriteString("  _arguments -C \\\n")
 	sb.WriteString("    '1:command:->cmd' \\\n")
@@ -78,10 +98,14 @@ func buildZshCompletionScript(cfg *config.Config) string {
 	sb.WriteString("      return\n")
 	sb.WriteString("      ;;\n")
 	sb.WriteString("    alias)\n")
-	sb.WriteString("      if [[ $words[2] == go ]]; then\n")
+	sb.WriteString("      if [[ $words[2] == go || $words[2] == env || $words[2] == prompt || $words[2] == history || $words[2] == plan || $words[2] == edit ]]; then\n")
 	sb.WriteString("        _describe 'alias' _obi_aliases\n")
 	sb.WriteString("        return\n")
 	sb.WriteString("      fi\n")
+	sb.WriteString("      if [[ $words[2] == run ]]; then\n")
+	sb.WriteString("        _describe 'template' _obi_templates\n")
+	sb.WriteString("        return\n")
+	sb.WriteString("      fi\n")
 	sb.WriteString("      ;;\n")
 	sb.WriteString("  esac\n")
 	sb.WriteString("}\n\n")
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/fenced"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/footer"
+)
+
+const (
+	reportSourceFenced = "fenced"
+	reportSourceFooter = "footer"
+)
+
+// reportConflict records in the ledger that the fenced report and legacy
+// footer disagreed and which one the operator kept. Only statuses are
+// stored; the texts may contain unscanned secrets.
+type reportConflict struct {
+	Fields       []string `json:"fields"`
+	Chosen       string   `json:"chosen"`
+	FencedStatus string   `json:"fenced_status"`
+	FooterStatus string   `json:"footer_status"`
+}
+
+// reportMismatches lists the fields where the two reports disagree.
+func reportMismatches(f fenced.Result, l footer.Result) []string {
+	var fields []string
+	if !strings.EqualFold(f.Status, l.Status) {
+		fields = append(fields, "status")
+	}
+	if normalizeMultiline(f.Details) != normalizeMultiline(l.CommitMsg) {
+		fields = append(fields, "details")
+	}
+	if normalizeWhitespace(f.Escalation) != normalizeWhitespace(l.Escalation) {
+		fields = append(fields, "escalation")
+	}
+	return fields
+}
+
+func reportMismatchMessage(field string) string {
+	switch field {
+	case "status":
+		return "fenced report status does not match legacy footer"
+	case "details":
+		return "fenced report details do not match legacy footer commit body"
+	default:
+		return "fenced report escalation does not match legacy footer"
+	}
+}
+
+// resolveReportConflict shows both reports and asks the operator which to
+// record. The footer carries no separate summary, so choosing it keeps the
+// fenced summary line. EOF or "a" aborts the run.
+func resolveReportConflict(f fenced.Result, l footer.Result, fields []string, in io.Reader, out io.Writer) (fenced.Result, string, error) {
+	fmt.Fprintf(out, "\nThe fenced report and legacy footer disagree (%s).\n", strings.Join(fields, ", "))
+	fmt.Fprintln(out, "\n[f] Fenced report:")
+	writeReportVersion(out, f.Status, f.Details, f.Escalation)
+	fmt.Fprintln(out, "\n[l] Legacy footer:")
+	writeReportVersion(out, l.Status, l.CommitMsg, l.Escalation)
+
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprint(out, "\nRecord which version? [f/l/a(bort)]: ")
+		input, err := reader.ReadString('\n')
+		choice := strings.TrimSpace(strings.ToLower(input))
+		switch {
+		case choice == "f":
+			return f, reportSourceFenced, nil
+		case choice == "l":
+			chosen := fenced.Result{
+				SessionID:  f.SessionID,
+				Status:     l.Status,
+				CommitMsg:  f.CommitMsg,
+				Details:    l.CommitMsg,
+				Escalation: l.Escalation,
+			}
+			if strings.TrimSpace(chosen.CommitMsg) == "" {
+				chosen.CommitMsg = firstLine(l.CommitMsg)
+			}
+			return chosen, reportSourceFooter, nil
+		case choice == "a" || err != nil:
+			return fenced.Result{}, "", newExitError(fmt.Sprintf("run aborted: %s", reportMismatchMessage(fields[0])))
+		}
+		fmt.Fprintln(out, "Please respond with f, l, or a.")
+	}
+}
+
+func writeReportVersion(out io.Writer, status, body, escalation string) {
+	fmt.Fprintf(out, "    STATUS: %s\n", status)
+	if body = strings.TrimSpace(body); body != "" {
+		fmt.Fprintln(out, indentPrompt(body))
+	}
+	if escalation = strings.TrimSpace(escalation); escalation != "" {
+		fmt.Fprintf(out, "    ESCALATION: %s\n", escalation)
+	}
+}
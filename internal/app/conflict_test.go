@@ -0,0 +1,58 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/fenced"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/footer"
+)
+
+func conflictingReports() (fenced.Result, footer.Result) {
+	f := fenced.Result{SessionID: "s1", Status: "success", CommitMsg: "add parser", Details: "Parser added."}
+	l := footer.Result{Status: "needs_help", CommitMsg: "Parser half done.", Escalation: "tests fail"}
+	return f, l
+}
+
+func TestReportMismatchesListsDifferingFields(t *testing.T) {
+	f, l := conflictingReports()
+	got := strings.Join(reportMismatches(f, l), ",")
+	if got != "status,details,escalation" {
+		t.Fatalf("unexpected fields %q", got)
+	}
+	same := footer.Result{Status: "SUCCESS", CommitMsg: "Parser added.  "}
+	if fields := reportMismatches(f, same); len(fields) != 0 {
+		t.Fatalf("expected no mismatch, got %v", fields)
+	}
+}
+
+func TestResolveReportConflictChoices(t *testing.T) {
+	f, l := conflictingReports()
+	fields := reportMismatches(f, l)
+
+	var out strings.Builder
+	chosen, source, err := resolveReportConflict(f, l, fields, strings.NewReader("x\nl\n"), &out)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if source != reportSourceFooter || chosen.Status != "needs_help" || chosen.Details != "Parser half done." || chosen.Escalation != "tests fail" {
+		t.Fatalf("unexpected footer choice: %q %+v", source, chosen)
+	}
+	if chosen.CommitMsg != "add parser" || chosen.SessionID != "s1" {
+		t.Fatalf("expected fenced summary and session kept, got %+v", chosen)
+	}
+	if !strings.Contains(out.String(), "Please respond with f, l, or a.") || !strings.Contains(out.String(), "ESCALATION: tests fail") {
+		t.Fatalf("unexpected prompt output:\n%s", out.String())
+	}
+
+	chosen, source, err = resolveReportConflict(f, l, fields, strings.NewReader("F\n"), &out)
+	if err != nil || source != reportSourceFenced || chosen != f {
+		t.Fatalf("expected fenced choice, got %q %+v %v", source, chosen, err)
+	}
+
+	for _, input := range []string{"a\n", ""} {
+		if _, _, err := resolveReportConflict(f, l, fields, strings.NewReader(input), &out); err == nil || !strings.Contains(err.Error(), "status does not match") {
+			t.Fatalf("expected abort for %q, got %v", input, err)
+		}
+	}
+}
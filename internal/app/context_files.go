@@ -0,0 +1,128 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// contextFileMaxBytes caps how much of any one context file reaches the
+// prompt; longer files are cut with a note so a stray log or lockfile
+// cannot swamp the session.
+const contextFileMaxBytes = 32 * 1024
+
+// contextFile is one file appended to the prompt's context section.
+type contextFile struct {
+	Path    string
+	Text    string
+	Omitted int
+}
+
+// contextFlag collects repeatable --context PATH values.
+type contextFlag []string
+
+func (c *contextFlag) String() string {
+	if c == nil {
+		return ""
+	}
+	return strings.Join(*c, ",")
+}
+
+func (c *contextFlag) Set(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("--context expects a file path")
+	}
+	*c = append(*c, value)
+	return nil
+}
+
+// loadContextFiles reads the epic's context_files (relative to the repo
+// root) followed by any --context paths (relative to the working directory).
+// A path named twice is only included once.
+func loadContextFiles(plan *sessionPlan, flagPaths []string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	type source struct{ path, base string }
+	var sources []source
+	for _, path := range plan.ContextPaths {
+		sources = append(sources, source{path, plan.RepoRoot})
+	}
+	for _, path := range flagPaths {
+		sources = append(sources, source{path, wd})
+	}
+
+	plan.ContextFiles = nil
+	seen := map[string]struct{}{}
+	for _, src := range sources {
+		path := strings.TrimSpace(src.path)
+		if path == "" {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(src.base, path)
+		}
+		path = filepath.Clean(path)
+		if _, dup := seen[path]; dup {
+			continue
+		}
+		seen[path] = struct{}{}
+		file, err := readContextFile(path, plan.RepoRoot)
+		if err != nil {
+			return err
+		}
+		plan.ContextFiles = append(plan.ContextFiles, file)
+	}
+	return nil
+}
+
+func readContextFile(path, repoRoot string) (contextFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return contextFile{}, fmt.Errorf("context file: %w", err)
+	}
+	file := contextFile{Path: displayContextPath(path, repoRoot)}
+	if len(data) > contextFileMaxBytes {
+		file.Omitted = len(data) - contextFileMaxBytes
+		data = data[:contextFileMaxBytes]
+	}
+	file.Text = strings.TrimRight(string(data), "\n")
+	return file, nil
+}
+
+// displayContextPath shows paths inside the repo relative to its root.
+func displayContextPath(path, repoRoot string) string {
+	if repoRoot == "" {
+		return path
+	}
+	rel, err := filepath.Rel(repoRoot, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+func formatContextFiles(files []contextFile) string {
+	if len(files) == 0 {
+		return ""
+	}
+	blocks := []string{"Reference files (background only; do not edit unless a bead asks you to):"}
+	for _, file := range files {
+		block := fmt.Sprintf("===== %s =====\n%s", file.Path, file.Text)
+		if file.Omitted > 0 {
+			block += fmt.Sprintf("\n[obi: %d bytes truncated]", file.Omitted)
+		}
+		blocks = append(blocks, block)
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func contextFilePaths(files []contextFile) []string {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// codexContinuation is the earlier run whose Codex session obi go
+// --continue-codex picks up again.
+type codexContinuation struct {
+	RunID          string
+	CodexSessionID string
+	AttemptGroup   string
+	BeadID         string
+	Status         string
+	Escalation     string
+}
+
+// loadCodexContinuation finds the run runID names (a prefix is enough when
+// it is unambiguous) among epicID's ledger entries and checks that Codex
+// printed a session ID obi can resume.
+func loadCodexContinuation(logPath, epicID, runID string) (*codexContinuation, error) {
+	runID = strings.ToLower(strings.TrimSpace(runID))
+	if runID == "" {
+		return nil, errors.New("--continue-codex needs the run ID of an earlier session")
+	}
+	if epicID == "" || epicID == "issues" {
+		return nil, errors.New("--continue-codex requires targeting a specific epic")
+	}
+	entries, err := ledgerEntriesForEpic(logPath, epicID)
+	if err != nil {
+		return nil, err
+	}
+	match, err := findLedgerRun(entries, runID)
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s in %s", err, epicID, logPath)
+	}
+	if match.CodexSessionID == "" {
+		return nil, fmt.Errorf("run %s has no codex_session_id in the ledger, so there is no Codex session to continue", match.RunID)
+	}
+	group := match.AttemptGroup
+	if group == "" {
+		group = match.RunID
+	}
+	return &codexContinuation{
+		RunID:          match.RunID,
+		CodexSessionID: match.CodexSessionID,
+		AttemptGroup:   group,
+		BeadID:         match.BeadID,
+		Status:         match.Status,
+		Escalation:     match.Escalation,
+	}, nil
+}
+
+// continuationInstructions tells Codex which earlier turn it is resuming;
+// it already holds that conversation, so only the outcome is restated.
+func continuationInstructions(plan sessionPlan) string {
+	c := plan.Continue
+	if c == nil {
+		return ""
+	}
+	bead := c.BeadID
+	if bead == "" {
+		bead = "its bead"
+	}
+	lines := []string{fmt.Sprintf("You are continuing your earlier session (obi run %s), which ended with status=%s on %s.", c.RunID, c.Status, bead)}
+	if escalation := strings.TrimSpace(c.Escalation); escalation != "" {
+		lines = append(lines, "You asked for help with: "+firstLine(escalation))
+	}
+	lines = append(lines, "The operator has since unblocked it. Pick up where you left off, then report with a fresh fenced block for this session.")
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadCodexContinuationFindsRunByPrefix(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "obi-results.log")
+	for _, entry := range []ledgerEntry{
+		{RunID: "aa11-first", SessionID: "aa11-first", EpicID: "obi-foo", BeadID: "obi-foo.1", Status: "needs_help", Escalation: "Which API key?\nmore", CodexSessionID: "0199a213-81c0-7800-8aa1-bbab2a035a53"},
+		{RunID: "bb22-second", SessionID: "bb22-second", EpicID: "obi-foo", BeadID: "obi-foo.2", Status: "success"},
+		{RunID: "bb23-third", SessionID: "bb23-third", EpicID: "obi-foo", BeadID: "obi-foo.3", Status: "success"},
+	} {
+		if err := appendLedgerEntry(logPath, entry); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	c, err := loadCodexContinuation(logPath, "obi-foo", "AA11")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if c.RunID != "aa11-first" || c.CodexSessionID != "0199a213-81c0-7800-8aa1-bbab2a035a53" || c.AttemptGroup != "aa11-first" {
+		t.Fatalf("continuation = %+v", c)
+	}
+	text := continuationInstructions(sessionPlan{Continue: c})
+	if !strings.Contains(text, "obi run aa11-first") || !strings.Contains(text, "status=needs_help on obi-foo.1") || !strings.Contains(text, "Which API key?\n") {
+		t.Fatalf("instructions = %q", text)
+	}
+
+	for runID, wantErr := range map[string]string{
+		"bb2":  "matches both",
+		"bb22": "no codex_session_id",
+		"zz":   "no run",
+		"":     "needs the run ID",
+	} {
+		if _, err := loadCodexContinuation(logPath, "obi-foo", runID); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("run %q: expected %q, got %v", runID, wantErr, err)
+		}
+	}
+	if _, err := loadCodexContinuation(logPath, "obi-bar", "aa11"); err == nil {
+		t.Fatal("a run from another epic should not be continued")
+	}
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchLimit bounds how far next looks ahead; every valid expression
+// matches within four years (29 February on a given weekday).
+const cronSearchLimit = 4 * 366 * 24 * time.Hour
+
+// cronMacros are the @-shorthands accepted in place of five fields.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	cronMonthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	cronDayNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// cronSpec is a parsed five-field cron expression: minute, hour, day of
+// month, month, and day of week, each a bitset of allowed values.
+type cronSpec struct {
+	text                         string
+	minute, hour, dom, month     uint64
+	dow                          uint64
+	domRestricted, dowRestricted bool
+}
+
+// parseCron accepts standard five-field expressions with *, lists, ranges,
+// steps, and month or weekday names, plus the @daily-style macros.
+func parseCron(expr string) (cronSpec, error) {
+	text := strings.TrimSpace(expr)
+	fields := strings.Fields(text)
+	if len(fields) == 1 {
+		macro, ok := cronMacros[strings.ToLower(fields[0])]
+		if !ok {
+			return cronSpec{}, fmt.Errorf("cron %q: unknown macro", expr)
+		}
+		fields = strings.Fields(macro)
+	}
+	if len(fields) != 5 {
+		return cronSpec{}, fmt.Errorf("cron %q: want five fields (minute hour day-of-month month day-of-week)", expr)
+	}
+	spec := cronSpec{text: text}
+	var err error
+	if spec.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return cronSpec{}, fmt.Errorf("cron %q minute: %w", expr, err)
+	}
+	if spec.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return cronSpec{}, fmt.Errorf("cron %q hour: %w", expr, err)
+	}
+	if spec.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return cronSpec{}, fmt.Errorf("cron %q day of month: %w", expr, err)
+	}
+	if spec.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return cronSpec{}, fmt.Errorf("cron %q month: %w", expr, err)
+	}
+	// 7 is accepted as a second Sunday.
+	if spec.dow, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return cronSpec{}, fmt.Errorf("cron %q day of week: %w", expr, err)
+	}
+	if spec.dow&(1<<7) != 0 {
+		spec.dow |= 1
+	}
+	spec.domRestricted = !strings.HasPrefix(fields[2], "*")
+	spec.dowRestricted = !strings.HasPrefix(fields[4], "*")
+	return spec, nil
+}
+
+// parseCronField turns one comma-separated field into a bitset. names, when
+// set, are aliases for the values starting at min.
+func parseCronField(field string, min, max int, names []string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+		lo, hi := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = cronValue(from, min, max, names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = cronValue(to, min, max, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = max
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("range %q runs backwards", rangePart)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(text string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(text, name) {
+			return min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("value %q is not between %d and %d", text, min, max)
+	}
+	return n, nil
+}
+
+// matches reports whether the cron fires in the minute containing t. As in
+// classic cron, when both day fields are restricted (neither starts with
+// "*") either may match.
+func (c cronSpec) matches(t time.Time) bool {
+	if c.minute&(1<<uint(t.Minute())) == 0 || c.hour&(1<<uint(t.Hour())) == 0 || c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domOK := c.dom&(1<<uint(t.Day())) != 0
+	dowOK := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domRestricted && c.dowRestricted {
+		return domOK || dowOK
+	}
+	return domOK && dowOK
+}
+
+// next returns the first minute after t the cron fires, or the zero time
+// when it never does (e.g. 30 February).
+func (c cronSpec) next(t time.Time) time.Time {
+	limit := t.Add(cronSearchLimit)
+	for at := t.Truncate(time.Minute).Add(time.Minute); at.Before(limit); at = at.Add(time.Minute) {
+		if c.month&(1<<uint(at.Month())) == 0 {
+			// Skip to the first minute of the next month.
+			at = time.Date(at.Year(), at.Month()+1, 1, 0, 0, 0, 0, at.Location()).Add(-time.Minute)
+			continue
+		}
+		if c.matches(at) {
+			return at
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronMatches(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, time.UTC)
+		if err != nil {
+			t.Fatalf("parse %q: %v", value, err)
+		}
+		return parsed
+	}
+	cases := []struct {
+		expr string
+		time string
+		want bool
+	}{
+		{"0 3 * * 1", "2026-10-19 03:00", true}, // a Monday
+		{"0 3 * * 1", "2026-10-20 03:00", false},
+		{"*/15 9-17 * * mon-fri", "2026-10-16 09:45", true},
+		{"*/15 9-17 * * mon-fri", "2026-10-17 09:45", false}, // Saturday
+		{"*/15 9-17 * * mon-fri", "2026-10-16 09:50", false},
+		{"0 0 * * 7", "2026-10-18 00:00", true}, // 7 is Sunday too
+		{"30 2 1,15 jan,jul *", "2026-07-15 02:30", true},
+		{"30 2 1,15 jan,jul *", "2026-08-15 02:30", false},
+		// Both day fields restricted: either one matches.
+		{"0 12 13 * 5", "2026-10-16 12:00", true},
+		{"0 12 13 * 5", "2026-10-13 12:00", true},
+		{"0 12 13 * 5", "2026-10-14 12:00", false},
+		{"@daily", "2026-10-16 00:00", true},
+		{"@hourly", "2026-10-16 07:01", false},
+	}
+	for _, tc := range cases {
+		spec, err := parseCron(tc.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tc.expr, err)
+		}
+		if got := spec.matches(at(tc.time)); got != tc.want {
+			t.Errorf("%q at %s = %v, want %v", tc.expr, tc.time, got, tc.want)
+		}
+	}
+}
+
+func TestParseCronRejectsBadExpressions(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "@fortnightly", "0 0 * foo *"} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) should fail", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	spec, err := parseCron("0 3 29 2 *")
+	if err != nil {
+		t.Fatalf("parseCron: %v", err)
+	}
+	from := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	if got, want := spec.next(from), time.Date(2028, 2, 29, 3, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("next = %v, want %v", got, want)
+	}
+	never, err := parseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("parseCron: %v", err)
+	}
+	if got := never.next(from); !got.IsZero() {
+		t.Fatalf("30 February should never fire, got %v", got)
+	}
+}
@@ -0,0 +1,290 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// runComparison is one side of obi diff-runs: the ledger entry plus what
+// is looked up outside the ledger. Prompt is empty when the stored copy is
+// gone; FilesKnown is false when git could not list the changed files.
+type runComparison struct {
+	Entry      ledgerEntry
+	Prompt     string
+	PromptErr  string
+	Files      []string
+	FilesKnown bool
+}
+
+func runDiffRuns(args []string) error {
+	fs := newCommandFlags("diff-runs", "obi diff-runs <run-a> <run-b> [options]",
+		"Compare two recorded runs: status, duration, tokens, commit summaries, changed files,\nand a diff of their prompts. Run IDs may be shortened to any unambiguous prefix.", "run-a", "run-b")
+	var configPath string
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	positional, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return errors.New("obi diff-runs needs two run IDs, e.g. obi diff-runs 3f2a 9c1b")
+	}
+
+	_, cfg, err := loadConfig(configPath, "")
+	if err != nil {
+		return err
+	}
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+	entries, err := ledgerEntriesForEpic(logPath, "")
+	if err != nil {
+		return err
+	}
+	var sides [2]runComparison
+	for i, id := range positional {
+		entry, err := findLedgerRun(entries, id)
+		if err != nil {
+			return fmt.Errorf("%w in %s", err, logPath)
+		}
+		sides[i] = loadRunComparison(entry)
+	}
+	fmt.Print(formatRunDiff(sides[0], sides[1]))
+	return nil
+}
+
+// findLedgerRun returns the entry whose run ID starts with id. A prefix
+// shared by two different runs is an error.
+func findLedgerRun(entries []ledgerEntry, id string) (ledgerEntry, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "" {
+		return ledgerEntry{}, errors.New("empty run ID")
+	}
+	var match *ledgerEntry
+	for i := range entries {
+		entry := &entries[i]
+		if !strings.HasPrefix(strings.ToLower(entry.RunID), id) {
+			continue
+		}
+		if match != nil && match.RunID != entry.RunID {
+			return ledgerEntry{}, fmt.Errorf("run ID %q matches both %s and %s; give more of it", id, match.RunID, entry.RunID)
+		}
+		match = entry
+	}
+	if match == nil {
+		return ledgerEntry{}, fmt.Errorf("no run %q recorded", id)
+	}
+	return *match, nil
+}
+
+// loadRunComparison reads a run's stored prompt and asks git which files
+// its commits touched. Either may be unavailable: obi clean prunes prompt
+// copies, and history rewrites drop commits.
+func loadRunComparison(entry ledgerEntry) runComparison {
+	side := runComparison{Entry: entry}
+	switch path := strings.TrimSpace(entry.PromptPath); {
+	case path == "":
+		side.PromptErr = "not recorded"
+	default:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			side.PromptErr = "unreadable (" + path + ")"
+			if errors.Is(err, os.ErrNotExist) {
+				side.PromptErr = "pruned (" + path + ")"
+			}
+		} else {
+			side.Prompt = string(data)
+		}
+	}
+	if git := entry.Git; git != nil && git.HeadBefore != "" && git.HeadAfter != "" {
+		if git.HeadBefore == git.HeadAfter {
+			side.FilesKnown = true
+		} else if out, err := gitOutput(entry.RepoRoot, "diff", "--name-only", git.HeadBefore, git.HeadAfter); err == nil {
+			// One path per line; a path may contain spaces.
+			for _, path := range strings.Split(out, "\n") {
+				if path != "" {
+					side.Files = append(side.Files, path)
+				}
+			}
+			side.FilesKnown = true
+		}
+	}
+	return side
+}
+
+// formatRunDiff renders the comparison: a table of metrics with the
+// change from a to b, both commit summaries, the changed files split into
+// shared and one-sided, and a unified diff of the prompts.
+func formatRunDiff(a, b runComparison) string {
+	ea, eb := a.Entry, b.Entry
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "A: run %s  %s  %s\n", ea.RunID, runLabel(ea), ea.CompletedAt.Local().Format("2006-01-02 15:04"))
+	fmt.Fprintf(&sb, "B: run %s  %s  %s\n\n", eb.RunID, runLabel(eb), eb.CompletedAt.Local().Format("2006-01-02 15:04"))
+
+	rows := [][3]string{
+		{"status", ea.Status, eb.Status},
+		{"bead", dashIfEmpty(ea.BeadID), dashIfEmpty(eb.BeadID)},
+		{"duration", runDuration(ea).String(), runDuration(eb).String()},
+		{"tokens", fmt.Sprint(ea.TokensUsed), fmt.Sprint(eb.TokensUsed)},
+		{"cost", fmt.Sprintf("$%.2f", ea.CostUSD), fmt.Sprintf("$%.2f", eb.CostUSD)},
+		{"model", dashIfEmpty(ea.CodexModel), dashIfEmpty(eb.CodexModel)},
+		{"config", dashIfEmpty(shortHash(ea.ConfigDigest)), dashIfEmpty(shortHash(eb.ConfigDigest))},
+		{"prompt", dashIfEmpty(shortHash(ea.PromptHash)), dashIfEmpty(shortHash(eb.PromptHash))},
+	}
+	deltas := map[string]string{
+		"duration": formatDurationDelta(runDuration(eb) - runDuration(ea)),
+		"tokens":   formatCountDelta(ea.TokensUsed, eb.TokensUsed),
+	}
+	if ea.CostUSD != eb.CostUSD {
+		deltas["cost"] = fmt.Sprintf("%+.2f", eb.CostUSD-ea.CostUSD)
+	}
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row[1]), len(row[2]))
+	}
+	for _, row := range rows {
+		note := deltas[row[0]]
+		if note == "" && row[1] != row[2] {
+			note = "changed"
+		}
+		line := fmt.Sprintf("  %-9s %-*s  %-*s  %s", row[0], width, row[1], width, row[2], note)
+		sb.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+
+	sb.WriteString("\nCommit summary:\n")
+	fmt.Fprintf(&sb, "  A: %s\n", dashIfEmpty(firstLine(ea.CommitSummary)))
+	fmt.Fprintf(&sb, "  B: %s\n", dashIfEmpty(firstLine(eb.CommitSummary)))
+
+	sb.WriteString("\nChanged files:\n")
+	if !a.FilesKnown || !b.FilesKnown {
+		sb.WriteString("  unknown: the ledger has no git range for both runs, or their commits are gone\n")
+	} else {
+		both, onlyA, onlyB := splitFileSets(a.Files, b.Files)
+		if len(both)+len(onlyA)+len(onlyB) == 0 {
+			sb.WriteString("  neither run committed changes\n")
+		}
+		writeFileGroup(&sb, "both", both)
+		writeFileGroup(&sb, "only A", onlyA)
+		writeFileGroup(&sb, "only B", onlyB)
+	}
+
+	sb.WriteString("\nPrompt:\n")
+	switch {
+	case a.PromptErr != "" || b.PromptErr != "":
+		if a.PromptErr != "" {
+			fmt.Fprintf(&sb, "  A's prompt copy is %s\n", a.PromptErr)
+		}
+		if b.PromptErr != "" {
+			fmt.Fprintf(&sb, "  B's prompt copy is %s\n", b.PromptErr)
+		}
+	case strings.TrimSpace(a.Prompt) == strings.TrimSpace(b.Prompt):
+		sb.WriteString("  unchanged\n")
+	default:
+		diff, ok := unifiedDiff("A "+ea.RunID, "B "+eb.RunID, a.Prompt, b.Prompt, promptDiffContext)
+		if !ok {
+			sb.WriteString("  changed (too large to diff)\n")
+		} else {
+			sb.WriteString(diff)
+		}
+	}
+	return sb.String()
+}
+
+// runLabel names a run by alias and, for retries and continuations, the
+// attempt group it belongs to.
+func runLabel(entry ledgerEntry) string {
+	label := entry.Alias
+	if label == "" {
+		label = entry.EpicID
+	}
+	if entry.AttemptGroup != "" && entry.AttemptGroup != entry.RunID {
+		label += " (retry in " + entry.AttemptGroup + ")"
+	}
+	return label
+}
+
+func runDuration(entry ledgerEntry) time.Duration {
+	if entry.DurationMs > 0 {
+		return (time.Duration(entry.DurationMs) * time.Millisecond).Round(time.Second)
+	}
+	if entry.CompletedAt.After(entry.StartedAt) && !entry.StartedAt.IsZero() {
+		return entry.CompletedAt.Sub(entry.StartedAt).Round(time.Second)
+	}
+	return 0
+}
+
+func formatDurationDelta(d time.Duration) string {
+	switch {
+	case d > 0:
+		return "+" + d.String()
+	case d < 0:
+		return "-" + (-d).String()
+	}
+	return ""
+}
+
+func formatCountDelta(a, b int64) string {
+	if a == b {
+		return ""
+	}
+	if a == 0 {
+		return fmt.Sprintf("%+d", b-a)
+	}
+	return fmt.Sprintf("%+d (%+.0f%%)", b-a, float64(b-a)*100/float64(a))
+}
+
+func shortHash(hash string) string {
+	hash = strings.TrimPrefix(hash, "sha256:")
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+func dashIfEmpty(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
+
+// splitFileSets returns the files in both lists and those in only one,
+// each sorted.
+func splitFileSets(a, b []string) (both, onlyA, onlyB []string) {
+	inA := map[string]bool{}
+	for _, f := range a {
+		inA[f] = true
+	}
+	inB := map[string]bool{}
+	for _, f := range b {
+		inB[f] = true
+		if inA[f] {
+			both = append(both, f)
+		} else {
+			onlyB = append(onlyB, f)
+		}
+	}
+	for _, f := range a {
+		if !inB[f] {
+			onlyA = append(onlyA, f)
+		}
+	}
+	sort.Strings(both)
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	return both, onlyA, onlyB
+}
+
+func writeFileGroup(sb *strings.Builder, label string, files []string) {
+	if len(files) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "  %s (%d):\n", label, len(files))
+	for _, f := range files {
+		fmt.Fprintf(sb, "    %s\n", f)
+	}
+}
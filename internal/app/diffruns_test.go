@@ -0,0 +1,101 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindLedgerRunByPrefix(t *testing.T) {
+	entries := []ledgerEntry{{RunID: "aa11-first"}, {RunID: "bb22-second"}, {RunID: "bb23-third"}}
+	if entry, err := findLedgerRun(entries, "AA1"); err != nil || entry.RunID != "aa11-first" {
+		t.Fatalf("prefix lookup = %+v, %v", entry, err)
+	}
+	for id, wantErr := range map[string]string{"bb2": "matches both", "zz": "no run", " ": "empty run ID"} {
+		if _, err := findLedgerRun(entries, id); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("run %q: expected %q, got %v", id, wantErr, err)
+		}
+	}
+}
+
+func TestFormatRunDiff(t *testing.T) {
+	dir := t.TempDir()
+	promptA := filepath.Join(dir, "a.prompt.txt")
+	promptB := filepath.Join(dir, "b.prompt.txt")
+	if err := os.WriteFile(promptA, []byte("Work the epic.\nRun go test.\n"), 0o600); err != nil {
+		t.Fatalf("write prompt: %v", err)
+	}
+	if err := os.WriteFile(promptB, []byte("Work the epic.\nRun go test ./... first.\n"), 0o600); err != nil {
+		t.Fatalf("write prompt: %v", err)
+	}
+	done := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	a := loadRunComparison(ledgerEntry{
+		RunID: "run-a", Alias: "api", Status: "needs_help", BeadID: "api-1.3", DurationMs: 600_000,
+		TokensUsed: 50000, CommitSummary: "fix(api): retry uploads", PromptPath: promptA, CompletedAt: done,
+	})
+	b := loadRunComparison(ledgerEntry{
+		RunID: "run-b", Alias: "api", AttemptGroup: "run-a", Status: "success", BeadID: "api-1.3", DurationMs: 450_000,
+		TokensUsed: 40000, CommitSummary: "fix(api): retry uploads with backoff", PromptPath: promptB, CompletedAt: done.Add(time.Hour),
+	})
+	a.Files, a.FilesKnown = []string{"api/upload.go", "api/upload_test.go"}, true
+	b.Files, b.FilesKnown = []string{"api/upload.go", "api/backoff.go"}, true
+
+	out := formatRunDiff(a, b)
+	for _, want := range []string{
+		"B: run run-b  api (retry in run-a)",
+		"  status    needs_help  success     changed\n",
+		"  duration  10m0s       7m30s       -2m30s\n",
+		"  tokens    50000       40000       -10000 (-20%)\n",
+		"  bead      api-1.3     api-1.3\n",
+		"  A: fix(api): retry uploads\n  B: fix(api): retry uploads with backoff\n",
+		"  both (1):\n    api/upload.go\n  only A (1):\n    api/upload_test.go\n  only B (1):\n    api/backoff.go\n",
+		"-Run go test.\n+Run go test ./... first.\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("comparison missing %q:\n%s", want, out)
+		}
+	}
+
+	b.Entry.CodexModel = "gpt-5-codex-medium"
+	if out := formatRunDiff(a, b); !strings.Contains(out, "  model     -                   gpt-5-codex-medium  changed\n") {
+		t.Fatalf("columns should fit B's values too:\n%s", out)
+	}
+
+	b.Entry.PromptPath = filepath.Join(dir, "pruned.prompt.txt")
+	b = loadRunComparison(b.Entry)
+	out = formatRunDiff(a, b)
+	if !strings.Contains(out, "B's prompt copy is pruned") || !strings.Contains(out, "Changed files:\n  unknown") {
+		t.Fatalf("comparison without prompt or git range:\n%s", out)
+	}
+}
+
+func TestLoadRunComparisonKeepsPathsWithSpaces(t *testing.T) {
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=obi", "-c", "user.email=obi@example.com"}, args...)...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "main")
+	git("commit", "-q", "--allow-empty", "-m", "root")
+	before := git("rev-parse", "HEAD")
+	if err := os.WriteFile(filepath.Join(repo, "release notes.md"), []byte("notes\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "notes")
+	after := git("rev-parse", "HEAD")
+
+	side := loadRunComparison(ledgerEntry{RepoRoot: repo, Git: &gitMetadata{HeadBefore: before, HeadAfter: after}})
+	if !side.FilesKnown || len(side.Files) != 1 || side.Files[0] != "release notes.md" {
+		t.Fatalf("files = %q, known = %v", side.Files, side.FilesKnown)
+	}
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/tui"
+)
+
+// gitDiffFetcher runs git diff in dir for the TUI's diff overlay. Against
+// base, the HEAD when the session started, it covers Codex's commits as
+// well as uncommitted edits; untracked files do not show up. It returns nil
+// outside a git checkout so the overlay says the diff is unavailable.
+func gitDiffFetcher(dir, base string) tui.DiffFetcher {
+	if strings.TrimSpace(base) == "" {
+		return nil
+	}
+	return func(ctx context.Context, full bool) (string, error) {
+		args := []string{"diff", "--no-color", "--no-ext-diff"}
+		if !full {
+			args = append(args, "--stat")
+		}
+		args = append(args, base, "--")
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", errors.New("git diff timed out")
+			}
+			if detail := strings.TrimSpace(stderr.String()); detail != "" {
+				return "", fmt.Errorf("git diff: %s: %s", err, firstLine(detail))
+			}
+			return "", fmt.Errorf("git diff: %w", err)
+		}
+		return stdout.String(), nil
+	}
+}
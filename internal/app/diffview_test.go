@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitDiffFetcherCoversCommitsAndEdits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=obi", "-c", "user.email=obi@example.com"}, args...)...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, text string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	write("a.txt", "one\n")
+	write("b.txt", "one\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "root")
+	base := git("rev-parse", "HEAD")
+
+	write("a.txt", "two\n")
+	git("commit", "-q", "-am", "codex commit")
+	write("b.txt", "two\n")
+
+	fetch := gitDiffFetcher(repo, base)
+	stat, err := fetch(context.Background(), false)
+	if err != nil || !strings.Contains(stat, "a.txt") || !strings.Contains(stat, "b.txt") || !strings.Contains(stat, "2 files changed") {
+		t.Fatalf("stat = %q, %v", stat, err)
+	}
+	full, err := fetch(context.Background(), true)
+	if err != nil || !strings.Contains(full, "+two") {
+		t.Fatalf("full diff = %q, %v", full, err)
+	}
+	if gitDiffFetcher(repo, "") != nil {
+		t.Fatal("expected no fetcher without a base commit")
+	}
+}
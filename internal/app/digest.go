@@ -0,0 +1,238 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const digestWebhookTimeout = 10 * time.Second
+
+// epicDigest summarizes one epic's runs inside the digest window.
+type epicDigest struct {
+	Label      string
+	Runs       int
+	Successes  int
+	NeedsHelp  int
+	DurationMs int64
+	Highlights []string
+	Escalated  []string
+}
+
+func runReportDigest(args []string) error {
+	fs := newCommandFlags("report digest", "obi report digest [options]",
+		"Write a Markdown digest of recent runs grouped by epic, with highlights from commit summaries.")
+	var configPath, profile, since, outPath string
+	var post bool
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.StringVar(&profile, "profile", "", "apply this [profiles.*] overlay (defaults to $OBI_PROFILE)")
+	fs.StringVar(&since, "since", "7d", "include runs completed within this window (e.g. 24h, 7d) or since a date (YYYY-MM-DD)")
+	fs.StringVar(&outPath, "out", "", "write the digest to this file instead of stdout")
+	fs.BoolVar(&post, "post", false, "also post the digest to the [notify] webhook")
+	if _, err := fs.parse(args); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	cutoff, err := parseDigestSince(since, now)
+	if err != nil {
+		return err
+	}
+
+	resolved, cfg, err := loadConfig(configPath, profile)
+	if err != nil {
+		return err
+	}
+	webhook := strings.TrimSpace(cfg.Notify.Webhook)
+	if post && webhook == "" {
+		return fmt.Errorf("--post requires [notify] webhook in %s", resolved)
+	}
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+
+	entries, err := ledgerEntriesForEpic(logPath, "")
+	if err != nil {
+		if errors.Is(err, errLedgerNotFound) {
+			return fmt.Errorf("results log %s not found; run obi go at least once first", logPath)
+		}
+		return err
+	}
+
+	digest := formatDigestMarkdown(buildEpicDigests(entries, cutoff), cutoff, now)
+	if outPath == "" {
+		fmt.Print(digest)
+	} else {
+		if dir := filepath.Dir(outPath); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return fmt.Errorf("create digest dir: %w", err)
+			}
+		}
+		if err := os.WriteFile(outPath, []byte(digest), 0o644); err != nil {
+			return fmt.Errorf("write digest: %w", err)
+		}
+		fmt.Printf("Wrote digest to %s\n", outPath)
+	}
+
+	if post {
+		if err := postDigest(webhook, digest); err != nil {
+			return err
+		}
+		fmt.Println("Posted digest to the notify webhook.")
+	}
+	return nil
+}
+
+// parseDigestSince accepts a day count ("7d"), a Go duration ("36h"), or a
+// calendar date ("2026-03-01", local time).
+func parseDigestSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, errors.New("--since must not be empty")
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (want e.g. 7d, 24h, or 2026-03-01)", value)
+}
+
+// buildEpicDigests groups runs completed at or after cutoff by epic, in order
+// of each epic's first run in the window.
+func buildEpicDigests(entries []ledgerEntry, cutoff time.Time) []epicDigest {
+	var digests []epicDigest
+	index := map[string]int{}
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		if entry.CompletedAt.Before(cutoff) {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(entry.EpicID))
+		i, ok := index[key]
+		if !ok {
+			i = len(digests)
+			index[key] = i
+			digests = append(digests, epicDigest{Label: digestEpicLabel(entry)})
+		}
+		d := &digests[i]
+		d.Runs++
+		d.DurationMs += entry.DurationMs
+		switch entry.Status {
+		case "success":
+			d.Successes++
+			summary := strings.TrimSpace(firstLine(entry.CommitSummary))
+			if summary != "" && !seen[key+"\x00"+summary] {
+				seen[key+"\x00"+summary] = true
+				d.Highlights = append(d.Highlights, digestItem(entry.BeadID, summary))
+			}
+		case "needs_help":
+			d.NeedsHelp++
+			reason := strings.TrimSpace(firstLine(entry.Escalation))
+			if reason == "" {
+				reason = "needs help"
+			}
+			d.Escalated = append(d.Escalated, digestItem(entry.BeadID, reason))
+		}
+	}
+	return digests
+}
+
+func digestEpicLabel(entry ledgerEntry) string {
+	name := strings.TrimSpace(entry.EpicName)
+	alias := strings.TrimSpace(entry.Alias)
+	switch {
+	case name != "" && alias != "":
+		return fmt.Sprintf("%s (%s)", name, alias)
+	case name != "":
+		return name
+	case alias != "":
+		return alias
+	case strings.TrimSpace(entry.EpicID) != "":
+		return entry.EpicID
+	}
+	return "Issues outside epics"
+}
+
+func digestItem(beadID, text string) string {
+	if beadID = strings.TrimSpace(beadID); beadID != "" {
+		return fmt.Sprintf("`%s` %s", beadID, text)
+	}
+	return text
+}
+
+func formatDigestMarkdown(digests []epicDigest, cutoff, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Obi digest: %s – %s\n\n", cutoff.Format("2006-01-02"), now.Format("2006-01-02"))
+	if len(digests) == 0 {
+		b.WriteString("_No runs recorded in this window._\n")
+		return b.String()
+	}
+
+	var runs, successes, needsHelp int
+	var duration int64
+	for _, d := range digests {
+		runs += d.Runs
+		successes += d.Successes
+		needsHelp += d.NeedsHelp
+		duration += d.DurationMs
+	}
+	fmt.Fprintf(&b, "%d run(s) across %d epic(s): %d succeeded, %d needed help, %s of Codex time.\n",
+		runs, len(digests), successes, needsHelp, roundedMillis(duration))
+
+	for _, d := range digests {
+		fmt.Fprintf(&b, "\n## %s\n\n", d.Label)
+		fmt.Fprintf(&b, "%d run(s), %d succeeded, %d needed help, %s.\n", d.Runs, d.Successes, d.NeedsHelp, roundedMillis(d.DurationMs))
+		if len(d.Highlights) > 0 {
+			b.WriteString("\n**Highlights**\n\n")
+			for _, h := range d.Highlights {
+				fmt.Fprintf(&b, "- %s\n", h)
+			}
+		}
+		if len(d.Escalated) > 0 {
+			b.WriteString("\n**Needs help**\n\n")
+			for _, e := range d.Escalated {
+				fmt.Fprintf(&b, "- %s\n", e)
+			}
+		}
+	}
+	return b.String()
+}
+
+// roundedMillis formats a millisecond total to the nearest second.
+func roundedMillis(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).Round(time.Second).String()
+}
+
+// postDigest sends the digest as {"text": digest} to the webhook.
+func postDigest(webhook, digest string) error {
+	payload, err := json.Marshal(map[string]string{"text": digest})
+	if err != nil {
+		return fmt.Errorf("encode digest payload: %w", err)
+	}
+	client := &http.Client{Timeout: digestWebhookTimeout}
+	resp, err := client.Post(webhook, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("post digest: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("post digest: webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDigestSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"7d":         now.AddDate(0, 0, -7),
+		"36h":        now.Add(-36 * time.Hour),
+		"2026-03-01": time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for input, want := range cases {
+		got, err := parseDigestSince(input, now)
+		if err != nil || !got.Equal(want) {
+			t.Fatalf("parseDigestSince(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "soon", "-3d", "0h"} {
+		if _, err := parseDigestSince(bad, now); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestBuildEpicDigestsGroupsRunsInWindow(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	entries := []ledgerEntry{
+		{EpicID: "obi-a1", EpicName: "Alpha", Alias: "alpha", BeadID: "obi-a1.0", Status: "success", CommitSummary: "too old", CompletedAt: base.Add(-48 * time.Hour)},
+		{EpicID: "obi-a1", EpicName: "Alpha", Alias: "alpha", BeadID: "obi-a1.1", Status: "success", CommitSummary: "add parser\n\nbody", DurationMs: 60000, CompletedAt: base},
+		{EpicID: "obi-b2", EpicName: "Beta", BeadID: "obi-b2.1", Status: "needs_help", Escalation: "schema unclear", DurationMs: 30000, CompletedAt: base.Add(time.Hour)},
+		{EpicID: "OBI-A1", EpicName: "Alpha", Alias: "alpha", BeadID: "obi-a1.1", Status: "success", CommitSummary: "add parser", DurationMs: 1000, CompletedAt: base.Add(2 * time.Hour)},
+	}
+	digests := buildEpicDigests(entries, base.Add(-time.Hour))
+	if len(digests) != 2 {
+		t.Fatalf("expected 2 epics, got %+v", digests)
+	}
+	alpha := digests[0]
+	if alpha.Label != "Alpha (alpha)" || alpha.Runs != 2 || alpha.Successes != 2 || alpha.DurationMs != 61000 {
+		t.Fatalf("unexpected alpha digest: %+v", alpha)
+	}
+	if len(alpha.Highlights) != 1 || alpha.Highlights[0] != "`obi-a1.1` add parser" {
+		t.Fatalf("expected one deduplicated highlight, got %v", alpha.Highlights)
+	}
+	if beta := digests[1]; beta.NeedsHelp != 1 || beta.Escalated[0] != "`obi-b2.1` schema unclear" {
+		t.Fatalf("unexpected beta digest: %+v", beta)
+	}
+
+	md := formatDigestMarkdown(digests, base.Add(-time.Hour), base.Add(3*time.Hour))
+	for _, want := range []string{"# Obi digest: 2026-03-02", "3 run(s) across 2 epic(s)", "## Alpha (alpha)", "**Needs help**", "1m31s of Codex time"} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("digest missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestPostDigestSendsTextPayload(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	if err := postDigest(srv.URL, "# digest\n"); err != nil {
+		t.Fatalf("postDigest: %v", err)
+	}
+	if got["text"] != "# digest\n" {
+		t.Fatalf("unexpected payload: %v", got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer failing.Close()
+	if err := postDigest(failing.URL, "x"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// duplicateRunWindow is how far back obi go looks for a successful run with
+// the same prompt. An identical prompt usually means the bead list has not
+// changed, so Codex would redo the same work.
+const duplicateRunWindow = 24 * time.Hour
+
+// findDuplicateRun returns the latest successful run for epicID with the
+// given prompt hash that completed within duplicateRunWindow of now. A
+// missing or unreadable ledger yields no match rather than blocking the run.
+func findDuplicateRun(logPath, epicID, hash string, now time.Time) *ledgerEntry {
+	if hash == "" {
+		return nil
+	}
+	entries, err := ledgerEntriesForEpic(logPath, epicID)
+	if err != nil {
+		return nil
+	}
+	return latestDuplicate(entries, hash, now)
+}
+
+func latestDuplicate(entries []ledgerEntry, hash string, now time.Time) *ledgerEntry {
+	cutoff := now.Add(-duplicateRunWindow)
+	var match *ledgerEntry
+	for i := range entries {
+		entry := &entries[i]
+		if entry.PromptHash != hash || !strings.EqualFold(entry.Status, "success") {
+			continue
+		}
+		if entry.CompletedAt.Before(cutoff) {
+			continue
+		}
+		if match == nil || entry.CompletedAt.After(match.CompletedAt) {
+			match = entry
+		}
+	}
+	return match
+}
+
+func formatDuplicateWarning(entry ledgerEntry, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Warning: a successful run %s used this exact prompt", timeAgo(now.Sub(entry.CompletedAt)))
+	if bead := strings.TrimSpace(entry.BeadID); bead != "" {
+		fmt.Fprintf(&b, " (%s", bead)
+		if summary := strings.TrimSpace(entry.CommitSummary); summary != "" {
+			fmt.Fprintf(&b, ": %s", summary)
+		}
+		b.WriteString(")")
+	}
+	b.WriteString(".\nThe bead list may not have changed; Codex could redo the same work.")
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatestDuplicateMatchesRecentSuccess(t *testing.T) {
+	now := time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC)
+	entries := []ledgerEntry{
+		{BeadID: "obi-1", Status: "success", PromptHash: "abc", CompletedAt: now.Add(-3 * time.Hour)},
+		{BeadID: "obi-2", Status: "success", PromptHash: "abc", CompletedAt: now.Add(-time.Hour)},
+		{BeadID: "obi-3", Status: "failure", PromptHash: "abc", CompletedAt: now.Add(-10 * time.Minute)},
+		{BeadID: "obi-4", Status: "success", PromptHash: "other", CompletedAt: now.Add(-5 * time.Minute)},
+	}
+	got := latestDuplicate(entries, "abc", now)
+	if got == nil || got.BeadID != "obi-2" {
+		t.Fatalf("expected obi-2, got %+v", got)
+	}
+}
+
+func TestLatestDuplicateIgnoresOldRuns(t *testing.T) {
+	now := time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC)
+	entries := []ledgerEntry{
+		{BeadID: "obi-1", Status: "success", PromptHash: "abc", CompletedAt: now.Add(-duplicateRunWindow - time.Minute)},
+	}
+	if got := latestDuplicate(entries, "abc", now); got != nil {
+		t.Fatalf("expected no duplicate, got %+v", got)
+	}
+}
+
+func TestPromptHashIgnoresSessionID(t *testing.T) {
+	a := promptHash("work\n```obi:1111\nreport", "1111")
+	b := promptHash("work\n```obi:2222\nreport", "2222")
+	if a != b {
+		t.Fatalf("expected identical hashes, got %s and %s", a, b)
+	}
+	if c := promptHash("other\n```obi:3333\nreport", "3333"); c == a {
+		t.Fatal("expected a different prompt to hash differently")
+	}
+}
+
+func TestFormatDuplicateWarning(t *testing.T) {
+	now := time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC)
+	got := formatDuplicateWarning(ledgerEntry{BeadID: "obi-2", CommitSummary: "add parser", CompletedAt: now.Add(-2 * time.Hour)}, now)
+	if !strings.Contains(got, "2h0m ago") || !strings.Contains(got, "(obi-2: add parser)") {
+		t.Fatalf("unexpected warning: %q", got)
+	}
+}
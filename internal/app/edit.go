@@ -0,0 +1,188 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	toml "github.com/pelletier/go-toml/v2"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func runEdit(args []string) error {
+	fs := newCommandFlags("edit", "obi edit <alias> [options]",
+		"Open one epic's prompt in $EDITOR. The edit is checked as TOML before obi.toml is\nrewritten, then the recomposed session prompt is printed for review.", "alias")
+	var configPath string
+	var noPreview bool
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.BoolVar(&noPreview, "no-preview", false, "skip printing the recomposed session prompt")
+	positional, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	alias := positionalArg(positional, 0)
+	if strings.TrimSpace(alias) == "" {
+		return fmt.Errorf("usage: obi edit <alias>")
+	}
+
+	resolved, err := config.ResolvePath(configPath)
+	if err != nil {
+		return err
+	}
+	cfg, err := editEpicPrompt(resolved, alias, runOperatorEditor)
+	if err != nil || cfg == nil || noPreview {
+		return err
+	}
+
+	plan, err := prepareSession(cfg, alias)
+	if err != nil {
+		return err
+	}
+	plan.RepoRoot = repoRootForConfig(resolved)
+	plan.ConfigDigest = configDigest(resolved)
+	loadReadyList(&plan, cfg)
+	prompt, err := reviewablePrompt(cfg, plan)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nSession prompt for %s:\n\n%s", plan.EpicID, prompt)
+	return nil
+}
+
+// editEpicPrompt lets edit change the prompt of the epic alias names and
+// saves it to the config at path. It returns the reloaded config, or nil
+// when the prompt was left alone.
+func editEpicPrompt(path, alias string, edit func(path string) error) (*config.Config, error) {
+	cfg, err := loadConfigIfExists(path)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("no obi config at %s; run obi init first", path)
+	}
+	key, epic, err := resolveEpic(cfg, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt, ok, err := promptEditLoop(key, epic.Prompt, edit)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		fmt.Println("Edit cancelled; obi.toml is unchanged.")
+		return nil, nil
+	}
+	if strings.TrimSpace(prompt) == strings.TrimSpace(epic.Prompt) {
+		fmt.Println("Prompt unchanged.")
+		return nil, nil
+	}
+
+	epic.Prompt = prompt
+	cfg.Epics[key] = epic
+	// Back up whichever file the save rewrites, obi.toml or the epics
+	// fragment of a split config, so a config that no longer loads can be
+	// put back as it was.
+	target, _, err := epicConfigTarget(path)
+	if err != nil {
+		return nil, err
+	}
+	original, err := os.ReadFile(target)
+	existed := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read %s: %w", target, err)
+	}
+	written, err := saveEpicConfig(path, cfg)
+	if err != nil {
+		return nil, err
+	}
+	reloaded, err := config.Load(path)
+	if err != nil {
+		if existed {
+			_ = os.WriteFile(written, original, 0o644)
+		} else {
+			_ = os.Remove(written)
+		}
+		return nil, fmt.Errorf("edited config no longer loads, left %s unchanged: %w", written, err)
+	}
+	fmt.Printf("Updated the prompt for %s in %s.\n", key, written)
+	return reloaded, nil
+}
+
+// promptEditLoop writes prompt to a temporary TOML file and runs edit on it
+// until the file parses. ok is false when the operator emptied the file.
+func promptEditLoop(key, prompt string, edit func(path string) error) (string, bool, error) {
+	f, err := os.CreateTemp("", "obi-prompt-*.toml")
+	if err != nil {
+		return "", false, fmt.Errorf("create prompt draft: %w", err)
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	draft := promptDraft(key, prompt, "")
+	for {
+		if err := os.WriteFile(path, []byte(draft), 0o600); err != nil {
+			return "", false, fmt.Errorf("write prompt draft: %w", err)
+		}
+		if err := edit(path); err != nil {
+			return "", false, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("read prompt draft: %w", err)
+		}
+		edited, ok, err := parsePromptDraft(string(data))
+		if err == nil {
+			return edited, ok, nil
+		}
+		fmt.Fprintf(os.Stderr, "obi edit: %v; reopening the editor.\n", err)
+		draft = promptDraft(key, "", err.Error()) + stripDraftHeader(string(data))
+	}
+}
+
+// promptDraft is the file the operator edits: a comment header, then the
+// prompt as a TOML multi-line string.
+func promptDraft(key, prompt, problem string) string {
+	var sb strings.Builder
+	sb.WriteString("# Prompt for [epic." + key + "]. Save to update obi.toml; empty the file to cancel.\n")
+	if problem != "" {
+		sb.WriteString("# Not saved: " + strings.ReplaceAll(problem, "\n", " ") + "\n")
+	}
+	sb.WriteString("\n")
+	if prompt != "" {
+		sb.WriteString("prompt = " + tomlMultiline(strings.TrimSpace(prompt)) + "\n")
+	}
+	return sb.String()
+}
+
+// stripDraftHeader drops the leading comment lines promptDraft wrote so a
+// reopened draft does not pile up headers.
+func stripDraftHeader(text string) string {
+	lines := strings.Split(text, "\n")
+	i := 0
+	for i < len(lines) && (strings.HasPrefix(lines[i], "#") || strings.TrimSpace(lines[i]) == "") {
+		i++
+	}
+	return strings.Join(lines[i:], "\n")
+}
+
+// parsePromptDraft reads the prompt key back from an edited draft. ok is
+// false when nothing but comments is left; a draft without a prompt key is
+// an error.
+func parsePromptDraft(text string) (string, bool, error) {
+	if strings.TrimSpace(stripDraftHeader(text)) == "" {
+		return "", false, nil
+	}
+	var draft struct {
+		Prompt *string `toml:"prompt"`
+	}
+	if err := toml.Unmarshal([]byte(text), &draft); err != nil {
+		return "", false, fmt.Errorf("invalid TOML: %w", err)
+	}
+	if draft.Prompt == nil || strings.TrimSpace(*draft.Prompt) == "" {
+		return "", false, fmt.Errorf("the draft needs a non-empty prompt = ... value")
+	}
+	return strings.TrimSpace(*draft.Prompt), true, nil
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestParsePromptDraft(t *testing.T) {
+	prompt, ok, err := parsePromptDraft(promptDraft("obi_api", "line one\nuse C:\\tmp", ""))
+	if err != nil || !ok || prompt != "line one\nuse C:\\tmp" {
+		t.Fatalf("round trip = %q, %v, %v", prompt, ok, err)
+	}
+	if _, ok, err := parsePromptDraft("# only comments\n\n"); ok || err != nil {
+		t.Fatalf("empty draft = %v, %v", ok, err)
+	}
+	if _, _, err := parsePromptDraft("prompt = '''unterminated"); err == nil || !strings.Contains(err.Error(), "invalid TOML") {
+		t.Fatalf("bad TOML: %v", err)
+	}
+	if _, _, err := parsePromptDraft("other = 1\n"); err == nil {
+		t.Fatal("a draft without prompt should fail")
+	}
+	if got := tomlMultiline("has ''' quotes\\n"); !strings.HasPrefix(got, `"""`) {
+		t.Fatalf("tomlMultiline fallback = %q", got)
+	}
+}
+
+func TestEditEpicPromptReopensUntilValid(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "obi.toml")
+	cfg := &config.Config{
+		ResultsLog: filepath.Join(dir, "obi-results.log"),
+		Epics:      map[string]config.EpicConfig{"obi_api": {Name: "API", ID: "obi-api", Alias: "api", Prompt: "ship it"}},
+	}
+	if err := writeConfigFile(path, cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	var drafts []string
+	edit := func(draft string) error {
+		data, err := os.ReadFile(draft)
+		if err != nil {
+			return err
+		}
+		drafts = append(drafts, string(data))
+		next := "prompt = '''broken"
+		if len(drafts) > 1 {
+			next = "prompt = '''\nShip it.\nKeep commits small.'''\n"
+		}
+		return os.WriteFile(draft, []byte(next), 0o600)
+	}
+	loaded, err := editEpicPrompt(path, "api", edit)
+	if err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if len(drafts) != 2 || !strings.Contains(drafts[0], "ship it") || !strings.Contains(drafts[1], "# Not saved: invalid TOML") {
+		t.Fatalf("drafts = %q", drafts)
+	}
+	if got := loaded.Epics["obi_api"].Prompt; got != "Ship it.\nKeep commits small." {
+		t.Fatalf("reloaded prompt = %q", got)
+	}
+
+	cancel := func(draft string) error { return os.WriteFile(draft, nil, 0o600) }
+	if loaded, err := editEpicPrompt(path, "api", cancel); err != nil || loaded != nil {
+		t.Fatalf("cancelled edit = %v, %v", loaded, err)
+	}
+}
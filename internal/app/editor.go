@@ -0,0 +1,32 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// operatorEditor is $VISUAL, then $EDITOR, then vi.
+func operatorEditor() string {
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(key)); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// runOperatorEditor opens path in the operator's editor on the terminal. The
+// editor runs via sh so values such as "code --wait" work.
+func runOperatorEditor(path string) error {
+	editor := operatorEditor()
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "obi-editor", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s: %w", editor, err)
+	}
+	return nil
+}
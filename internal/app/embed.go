@@ -0,0 +1,280 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// The exported types and functions in this file back the public pkg/obi
+// API. They are deliberately narrower than the CLI's internal structures so
+// those can keep changing without breaking embedders.
+
+// PlanOptions mirrors the obi go flags that shape a session plan.
+type PlanOptions struct {
+	// RepoRoot anchors relative epic dirs and context files; empty uses the
+	// git toplevel of the config file's directory.
+	RepoRoot     string
+	ConfigPath   string
+	Dir          string
+	Env          []string
+	ContextFiles []string
+	// ResumeFromLog skips beads this results log already records as done.
+	ResumeFromLog string
+	// ReadOnly plans an exploratory session as obi go --read-only does.
+	ReadOnly bool
+}
+
+// Plan is a resolved session plan together with its composed prompt body
+// (without the per-session fenced-report instructions).
+type Plan struct {
+	EpicKey              string
+	EpicName             string
+	Alias                string
+	EpicID               string
+	Tool                 string
+	Prompt               string
+	Codex                config.CodexConfig
+	RepoRoot             string
+	ConfigDigest         string
+	Dir                  string
+	Env                  []string
+	VerifyCommand        string
+	ResumeCompletedBeads []string
+	// ReportGuidance holds the [style] rules to add to the fenced-report
+	// instructions.
+	ReportGuidance []string
+	// SecretEnv names the epic's secrets.from_env variables; Unset names
+	// every other epic's, which the session must not inherit.
+	SecretEnv []string
+	Unset     []string
+}
+
+// BuildPlan resolves alias the way obi go does; an empty alias selects the
+// "issues outside epics" block.
+func BuildPlan(cfg *config.Config, alias string, opts PlanOptions) (Plan, error) {
+	if cfg == nil {
+		return Plan{}, errors.New("nil config")
+	}
+	var plan sessionPlan
+	if strings.TrimSpace(alias) == "" {
+		if cfg.Issues == nil {
+			return Plan{}, errors.New("config has no \"issues outside epics\" section")
+		}
+		plan = planFromIssues(cfg)
+	} else {
+		var err error
+		if plan, err = prepareSession(cfg, alias); err != nil {
+			return Plan{}, err
+		}
+	}
+	plan.RepoRoot = opts.RepoRoot
+	if opts.ConfigPath != "" {
+		if plan.RepoRoot == "" {
+			plan.RepoRoot = repoRootForConfig(opts.ConfigPath)
+		}
+		plan.ConfigDigest = configDigest(opts.ConfigPath)
+	}
+	if err := applyRunContext(&plan, opts.Dir, opts.Env); err != nil {
+		return Plan{}, err
+	}
+	if err := loadContextFiles(&plan, opts.ContextFiles); err != nil {
+		return Plan{}, err
+	}
+	if opts.ResumeFromLog != "" {
+		if err := enableResume(&plan, opts.ResumeFromLog); err != nil {
+			return Plan{}, err
+		}
+	}
+	if opts.ReadOnly {
+		applyReadOnly(&plan)
+	}
+	style, err := sessionStyle(cfg, plan)
+	if err != nil {
+		return Plan{}, err
+	}
+	return Plan{
+		EpicKey:              plan.EpicKey,
+		EpicName:             plan.EpicName,
+		Alias:                plan.Alias,
+		EpicID:               plan.EpicID,
+		Tool:                 plan.Tool,
+		Prompt:               buildPrompt(plan),
+		Codex:                plan.Codex,
+		RepoRoot:             plan.RepoRoot,
+		ConfigDigest:         plan.ConfigDigest,
+		Dir:                  plan.Dir,
+		Env:                  plan.Env,
+		VerifyCommand:        plan.VerifyCommand,
+		ResumeCompletedBeads: plan.ResumeCompletedBeads,
+		ReportGuidance:       style.guidance(),
+		SecretEnv:            plan.SecretEnv,
+		Unset:                foreignSecrets(cfg, plan.SecretEnv),
+	}, nil
+}
+
+// SessionEnv resolves the environment obi go would launch Codex with: env
+// plus the secretEnv variables read from obi's own environment. It also
+// returns the values to redact from the transcript, $OBI_REDACT first.
+func SessionEnv(env, secretEnv []string) ([]string, []string, error) {
+	secretVars, secretValues, err := epicSecretEnv(secretEnv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mergeEnv(env, secretVars), append(redactionSecrets(), secretValues...), nil
+}
+
+// LedgerEntry is the stable subset of a results-log line.
+type LedgerEntry struct {
+	RunID          string    `json:"run_id"`
+	SessionID      string    `json:"session_id"`
+	RepoRoot       string    `json:"repo_root"`
+	EpicID         string    `json:"epic_id"`
+	EpicKey        string    `json:"epic_key"`
+	EpicName       string    `json:"epic_name"`
+	Alias          string    `json:"alias"`
+	BeadID         string    `json:"bead_id,omitempty"`
+	Status         string    `json:"status"`
+	CommitSummary  string    `json:"commit_summary"`
+	CommitDetails  string    `json:"commit_details"`
+	Escalation     string    `json:"escalation,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+	CompletedAt    time.Time `json:"completed_at"`
+	DurationMs     int64     `json:"duration_ms"`
+	ExitCode       int       `json:"exit_code"`
+	TranscriptPath string    `json:"transcript_path,omitempty"`
+	CodexModel     string    `json:"codex_model,omitempty"`
+	ConfigDigest   string    `json:"config_digest,omitempty"`
+	PromptHash     string    `json:"prompt_hash,omitempty"`
+	TokensUsed     int64     `json:"tokens_used,omitempty"`
+	CodexSessionID string    `json:"codex_session_id,omitempty"`
+	ContinuedFrom  string    `json:"continued_from,omitempty"`
+	Template       string    `json:"template,omitempty"`
+	FailureKind    string    `json:"failure_kind,omitempty"`
+	Simulated      string    `json:"simulated,omitempty"`
+	Degraded       string    `json:"degraded,omitempty"`
+	AbortCleanup   string    `json:"abort_cleanup,omitempty"`
+	ArtifactsDir   string    `json:"artifacts_dir,omitempty"`
+	Artifacts      []string  `json:"artifacts,omitempty"`
+	Experiment     string    `json:"experiment,omitempty"`
+	Variant        string    `json:"experiment_variant,omitempty"`
+	Profile        string    `json:"profile,omitempty"`
+	CommitType     string    `json:"commit_type,omitempty"`
+	CommitScope    string    `json:"commit_scope,omitempty"`
+	CommitSubject  string    `json:"commit_subject,omitempty"`
+	Exploratory    bool      `json:"exploratory,omitempty"`
+	AttemptGroup   string    `json:"attempt_group,omitempty"`
+	CostUSD        float64   `json:"cost_usd,omitempty"`
+}
+
+// ReadLedger returns the entries for epicID (all entries when empty). A
+// missing results log yields no entries.
+func ReadLedger(path, epicID string) ([]LedgerEntry, error) {
+	entries, err := ledgerEntriesForEpic(path, epicID)
+	if err != nil {
+		if errors.Is(err, errLedgerNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	out := make([]LedgerEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, publicLedgerEntry(entry))
+	}
+	return out, nil
+}
+
+// AppendLedger writes entry to the results log with the current schema
+// version, creating the file with 0600 permissions if needed. The commit
+// type, scope, and subject are derived from CommitSummary.
+func AppendLedger(path string, entry LedgerEntry) error {
+	if strings.TrimSpace(entry.Status) == "" {
+		return fmt.Errorf("ledger entry for session %s is missing a status", entry.SessionID)
+	}
+	record := ledgerEntry{
+		RunID:          entry.RunID,
+		SessionID:      entry.SessionID,
+		RepoRoot:       entry.RepoRoot,
+		EpicID:         entry.EpicID,
+		EpicKey:        entry.EpicKey,
+		EpicName:       entry.EpicName,
+		Alias:          entry.Alias,
+		BeadID:         entry.BeadID,
+		Status:         entry.Status,
+		CommitSummary:  entry.CommitSummary,
+		CommitDetails:  entry.CommitDetails,
+		Escalation:     entry.Escalation,
+		StartedAt:      entry.StartedAt,
+		CompletedAt:    entry.CompletedAt,
+		ExitCode:       entry.ExitCode,
+		TranscriptPath: entry.TranscriptPath,
+		CodexModel:     entry.CodexModel,
+		ConfigDigest:   entry.ConfigDigest,
+		PromptHash:     entry.PromptHash,
+		TokensUsed:     entry.TokensUsed,
+		CodexSessionID: entry.CodexSessionID,
+		ContinuedFrom:  entry.ContinuedFrom,
+		Template:       entry.Template,
+		FailureKind:    entry.FailureKind,
+		Simulated:      entry.Simulated,
+		Degraded:       entry.Degraded,
+		AbortCleanup:   entry.AbortCleanup,
+		ArtifactsDir:   entry.ArtifactsDir,
+		Artifacts:      entry.Artifacts,
+		Experiment:     entry.Experiment,
+		Variant:        entry.Variant,
+		Profile:        entry.Profile,
+		Exploratory:    entry.Exploratory,
+		AttemptGroup:   entry.AttemptGroup,
+		CostUSD:        entry.CostUSD,
+	}
+	applyCommitParts(&record, entry.CommitSummary)
+	return appendLedgerEntry(path, record)
+}
+
+func publicLedgerEntry(entry ledgerEntry) LedgerEntry {
+	return LedgerEntry{
+		RunID:          entry.RunID,
+		SessionID:      entry.SessionID,
+		RepoRoot:       entry.RepoRoot,
+		EpicID:         entry.EpicID,
+		EpicKey:        entry.EpicKey,
+		EpicName:       entry.EpicName,
+		Alias:          entry.Alias,
+		BeadID:         entry.BeadID,
+		Status:         entry.Status,
+		CommitSummary:  entry.CommitSummary,
+		CommitDetails:  entry.CommitDetails,
+		Escalation:     entry.Escalation,
+		StartedAt:      entry.StartedAt,
+		CompletedAt:    entry.CompletedAt,
+		DurationMs:     entry.DurationMs,
+		ExitCode:       entry.ExitCode,
+		TranscriptPath: entry.TranscriptPath,
+		CodexModel:     entry.CodexModel,
+		ConfigDigest:   entry.ConfigDigest,
+		PromptHash:     entry.PromptHash,
+		TokensUsed:     entry.TokensUsed,
+		CodexSessionID: entry.CodexSessionID,
+		ContinuedFrom:  entry.ContinuedFrom,
+		Template:       entry.Template,
+		FailureKind:    entry.FailureKind,
+		Simulated:      entry.Simulated,
+		Degraded:       entry.Degraded,
+		AbortCleanup:   entry.AbortCleanup,
+		ArtifactsDir:   entry.ArtifactsDir,
+		Artifacts:      entry.Artifacts,
+		Experiment:     entry.Experiment,
+		Variant:        entry.Variant,
+		Profile:        entry.Profile,
+		CommitType:     entry.CommitType,
+		CommitScope:    entry.CommitScope,
+		CommitSubject:  entry.CommitSubject,
+		Exploratory:    entry.Exploratory,
+		AttemptGroup:   entry.AttemptGroup,
+		CostUSD:        entry.CostUSD,
+	}
+}
@@ -0,0 +1,240 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/codexexec"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// envEntry is one key=value line of `obi env` output.
+type envEntry struct {
+	Key   string
+	Value string
+}
+
+// envContext gathers everything `obi env` reports for a resolved plan.
+type envContext struct {
+	ConfigPath    string
+	Config        *config.Config
+	Plan          sessionPlan
+	LogPath       string
+	SecretCount   int
+	Guardrail     string
+	CodexOverride *config.CodexConfig
+}
+
+func runEnv(args []string) error {
+	fs := newCommandFlags("env", "obi env [alias] [options]",
+		"Print the fully resolved execution context for an alias as key=value lines.", "alias")
+	var configPath, profile, dirFlag string
+	var envFlags, contextFlags []string
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.StringVar(&profile, "profile", "", "apply this [profiles.*] overlay (defaults to $OBI_PROFILE)")
+	fs.StringVar(&dirFlag, "dir", "", "resolve as if obi go --dir were given")
+	fs.Var((*envFlag)(&envFlags), "env", "resolve as if obi go --env KEY=VAL were given (repeatable)")
+	fs.Var((*contextFlag)(&contextFlags), "context", "resolve as if obi go --context PATH were given (repeatable)")
+
+	positional, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	alias := positionalArg(positional, 0)
+
+	resolved, cfg, err := loadConfig(configPath, profile)
+	if err != nil {
+		return err
+	}
+
+	ctx := envContext{ConfigPath: resolved, Config: cfg}
+	if strings.TrimSpace(alias) == "" {
+		if cfg.Issues == nil {
+			return fmt.Errorf("obi env needs an alias: obi.toml has no \"issues outside epics\" section")
+		}
+		ctx.Plan = planFromIssues(cfg)
+	} else {
+		ctx.Plan, err = prepareSession(cfg, alias)
+		if err != nil {
+			return err
+		}
+		ctx.CodexOverride = cfg.Epics[ctx.Plan.EpicKey].CodexOverride
+	}
+	ctx.Plan.RepoRoot = repoRootForConfig(resolved)
+	ctx.Plan.ConfigDigest = configDigest(resolved)
+	if err := applyRunContext(&ctx.Plan, dirFlag, envFlags); err != nil {
+		return err
+	}
+	if err := loadContextFiles(&ctx.Plan, contextFlags); err != nil {
+		return err
+	}
+
+	ctx.LogPath, err = cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+	ctx.SecretCount = len(redactionSecrets())
+
+	readyIssues, readyErr := fetchReadyIssues()
+	ctx.Guardrail = guardrailStatus(ctx.Plan, readyIssues, readyErr)
+
+	fmt.Print(formatEnvEntries(buildEnvEntries(ctx)))
+	return nil
+}
+
+// buildEnvEntries flattens the resolved execution context into git-config
+// style keys so layered settings can be inspected or grepped.
+func buildEnvEntries(ctx envContext) []envEntry {
+	plan := ctx.Plan
+	codex := plan.Codex
+	entries := []envEntry{
+		{"config.path", ctx.ConfigPath},
+		{"config.digest", plan.ConfigDigest},
+		{"config.profile", ctx.Config.ActiveProfile},
+		{"repo.root", plan.RepoRoot},
+		{"results.log", ctx.LogPath},
+		{"results.transcripts", transcriptDirFor(ctx.LogPath)},
+		{"results.transcript_name", ctx.Config.TranscriptNameTemplate},
+		{"transcript.include", strings.Join(ctx.Config.Transcript.Include, ",")},
+		{"transcript.drop_ansi", strconv.FormatBool(ctx.Config.Transcript.DropANSI)},
+		{"transcript.strip_progress", strconv.FormatBool(ctx.Config.Transcript.StripProgress)},
+		{"artifacts.dir", artifactsDirFor(ctx.LogPath)},
+		{"artifacts.paths", strings.Join(ctx.Config.Artifacts.Paths, ",")},
+		{"epic.key", plan.EpicKey},
+		{"epic.name", plan.EpicName},
+		{"epic.id", plan.EpicID},
+		{"epic.alias", plan.Alias},
+		{"epic.tool", plan.Tool},
+		{"epic.branch", plan.Branch},
+		{"run.dir", plan.Dir},
+		{"run.env", strings.Join(envKeys(plan.Env), ",")},
+		{"run.secrets", strings.Join(plan.SecretEnv, ",")},
+		{"verify.command", plan.VerifyCommand},
+		{"escalation.action", plan.Escalation.Action},
+		{"escalation.auto_approve", strings.Join(plan.Escalation.AutoApprove, ", ")},
+		{"prompt.context_files", strings.Join(contextFilePaths(plan.ContextFiles), ",")},
+		{"codex.binary", codex.Binary},
+		{"codex.model", codex.Model},
+		{"codex.sandbox", codex.Sandbox},
+		{"codex.approval", codex.Approval},
+		{"codex.extra_args", strings.Join(codex.ExtraArgs, " ")},
+		{"codex.fallback_models", strings.Join(codex.FallbackModels, ",")},
+		{"codex.forbidden_args", strings.Join(codex.ForbiddenArgs, "; ")},
+		{"codex.forbidden_env", strings.Join(codex.ForbiddenEnv, ",")},
+		{"codex.alias_timeout", codex.AliasTimeout().String()},
+		{"codex.overrides", strings.Join(codexOverrideFields(ctx.CodexOverride), ",")},
+	}
+	if inv, err := codexexec.Build(codex, "<prompt>"); err == nil {
+		entries = append(entries, envEntry{"codex.command", inv.Binary + " " + strings.Join(inv.Args, " ")})
+	} else {
+		entries = append(entries, envEntry{"codex.command", "refused: " + err.Error()})
+	}
+	if err := codexexec.CheckEnv(codex, plan.Env); err != nil {
+		entries = append(entries, envEntry{"codex.env_check", "refused: " + err.Error()})
+	}
+
+	sections := promptSections(plan)
+	names := make([]string, 0, len(sections))
+	for _, section := range sections {
+		names = append(names, section.Name)
+	}
+	entries = append(entries,
+		envEntry{"prompt.sections", strings.Join(names, ",")},
+		envEntry{"prompt.chars", strconv.Itoa(len(buildPrompt(plan)))},
+	)
+	for _, section := range sections {
+		entries = append(entries,
+			envEntry{"prompt." + section.Name + ".chars", strconv.Itoa(len(section.Text))},
+			envEntry{"prompt." + section.Name + ".head", truncatePrompt(firstLine(section.Text))},
+		)
+	}
+
+	entries = append(entries,
+		envEntry{"run.confirm_before_run", strconv.FormatBool(ctx.Config.ConfirmBeforeRunValue())},
+		envEntry{"redaction.secrets", strconv.Itoa(ctx.SecretCount)},
+		envEntry{"redaction.live", strconv.FormatBool(ctx.Config.Redaction.Live)},
+		envEntry{"webhooks.endpoints", strings.Join(webhookHosts(ctx.Config.Webhooks.Endpoints), ",")},
+		envEntry{"webhooks.secret_env", ctx.Config.Webhooks.SecretEnvValue()},
+		envEntry{"summary.review", strconv.FormatBool(ctx.Config.Summary.Review)},
+		envEntry{"summary.pull_request", ctx.Config.Summary.PullRequest.Command},
+		envEntry{"guardrail.status", ctx.Guardrail},
+		envEntry{"guardrails.require_clean_tree", strconv.FormatBool(ctx.Config.Guardrails.RequireCleanTree)},
+		envEntry{"guardrails.allow_dirty", strings.Join(ctx.Config.Guardrails.AllowDirty, ",")},
+		envEntry{"guardrails.protected_branches", strings.Join(ctx.Config.Guardrails.ProtectedBranches, ",")},
+		envEntry{"guardrails.allowed_paths", strings.Join(ctx.Config.Guardrails.AllowedPaths, ",")},
+		envEntry{"guardrails.forbidden_paths", strings.Join(ctx.Config.Guardrails.ForbiddenPaths, ",")},
+		envEntry{"guardrails.abort_cleanup", ctx.Config.Guardrails.AbortCleanup},
+		envEntry{"schedule.allowed_hours", ctx.Config.Schedule.AllowedHours},
+		envEntry{"schedule.blackout_dates", strings.Join(ctx.Config.Schedule.BlackoutDates, ",")},
+		envEntry{"schedule.jobs", strconv.Itoa(len(ctx.Config.Schedule.Jobs))},
+		envEntry{"update.release_url", ctx.Config.Update.ReleaseURL},
+	)
+	return entries
+}
+
+func formatEnvEntries(entries []envEntry) string {
+	var b strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "%s=%s\n", entry.Key, entry.Value)
+	}
+	return b.String()
+}
+
+// codexOverrideFields names the codex settings an epic overrides.
+func codexOverrideFields(override *config.CodexConfig) []string {
+	if override == nil {
+		return nil
+	}
+	var fields []string
+	if override.Binary != "" {
+		fields = append(fields, "binary")
+	}
+	if override.Model != "" {
+		fields = append(fields, "model")
+	}
+	if override.Sandbox != "" {
+		fields = append(fields, "sandbox")
+	}
+	if override.Approval != "" {
+		fields = append(fields, "approval")
+	}
+	if len(override.ExtraArgs) > 0 {
+		fields = append(fields, "extra_args")
+	}
+	if len(override.FallbackModels) > 0 {
+		fields = append(fields, "fallback_models")
+	}
+	if len(override.ForbiddenArgs) > 0 {
+		fields = append(fields, "forbidden_args")
+	}
+	if len(override.ForbiddenEnv) > 0 {
+		fields = append(fields, "forbidden_env")
+	}
+	return fields
+}
+
+// guardrailStatus describes whether the ready-bead preflight would let the plan run.
+func guardrailStatus(plan sessionPlan, readyIssues []readyIssue, readyErr error) string {
+	if plan.EpicID == "" || plan.EpicID == "issues" {
+		return "skipped (issues outside epics)"
+	}
+	if readyErr != nil {
+		return fmt.Sprintf("unavailable (%s)", readyErr)
+	}
+	ok, err := hasReadyIssueForPlan(plan, readyIssues)
+	if err != nil {
+		return fmt.Sprintf("blocked (%s)", err)
+	}
+	if !ok {
+		return fmt.Sprintf("blocked (%s)", missingReadyBeadsWarning(plan.EpicID))
+	}
+	return "ok"
+}
+
+func firstLine(text string) string {
+	if idx := strings.IndexByte(text, '\n'); idx != -1 {
+		return text[:idx]
+	}
+	return text
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestBuildEnvEntriesReportsResolvedContext(t *testing.T) {
+	override := &config.CodexConfig{Model: "o3", ExtraArgs: []string{"--search"}}
+	cfg := &config.Config{
+		BasePrompt: "Base instructions\nsecond line",
+		Codex:      config.CodexConfig{Binary: "codex", Model: "gpt-5", Sandbox: "workspace-write"},
+		Epics: map[string]config.EpicConfig{
+			"foo": {Name: "Foo Work", ID: "obi-foo", Alias: "foo", Prompt: "Epic text", CodexOverride: override},
+		},
+	}
+	plan, err := prepareSession(cfg, "foo")
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	plan.RepoRoot = "/repo"
+	plan.ConfigDigest = "abc123"
+
+	output := formatEnvEntries(buildEnvEntries(envContext{
+		ConfigPath:    "/repo/obi.toml",
+		Config:        cfg,
+		Plan:          plan,
+		LogPath:       "/state/obi/results.log",
+		SecretCount:   2,
+		Guardrail:     "ok",
+		CodexOverride: override,
+	}))
+
+	for _, want := range []string{
+		"config.path=/repo/obi.toml\n",
+		"config.digest=abc123\n",
+		"results.transcripts=/state/obi/transcripts\n",
+		"codex.model=o3\n",
+		"codex.sandbox=workspace-write\n",
+		"codex.overrides=model,extra_args\n",
+		"codex.command=codex exec --model o3 --sandbox workspace-write --search <prompt>\n",
+		"prompt.sections=base,epic,metadata,contract\n",
+		"prompt.base.head=Base instructions\n",
+		"redaction.secrets=2\n",
+		"guardrail.status=ok\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestGuardrailStatus(t *testing.T) {
+	plan := sessionPlan{EpicID: "obi-foo"}
+	if got := guardrailStatus(plan, []readyIssue{{ID: "obi-foo.1", IssueType: "task"}}, nil); got != "ok" {
+		t.Fatalf("expected ok, got %q", got)
+	}
+	if got := guardrailStatus(plan, nil, nil); !strings.HasPrefix(got, "blocked (") {
+		t.Fatalf("expected blocked status, got %q", got)
+	}
+	if got := guardrailStatus(plan, nil, errors.New("bd missing")); got != "unavailable (bd missing)" {
+		t.Fatalf("expected unavailable status, got %q", got)
+	}
+	if got := guardrailStatus(sessionPlan{EpicID: "issues"}, nil, nil); !strings.HasPrefix(got, "skipped") {
+		t.Fatalf("expected issues plan to skip guardrail, got %q", got)
+	}
+}
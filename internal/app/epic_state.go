@@ -0,0 +1,146 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// epicState is the snapshot shown before the first session of an epic loop
+// so the operator confirms with the epic's recent history in view.
+type epicState struct {
+	Ready      int
+	InProgress int
+	Closed     int
+	Total      int
+	// Warning explains missing data (bd unavailable, unreadable ledger).
+	Warning string
+	LastRun *ledgerEntry
+	// OpenEscalations are beads whose latest run ended in needs_help and
+	// that bd does not report as closed.
+	OpenEscalations []ledgerEntry
+}
+
+// loadEpicState gathers bead counts from bd and run history from the
+// ledger. Failures degrade the snapshot rather than blocking the run.
+func loadEpicState(plan sessionPlan, logPath string) epicState {
+	var problems []string
+	ready, err := fetchReadyIssues()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	all, err := fetchOpenIssues()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	entries, err := ledgerEntriesForEpic(logPath, plan.EpicID)
+	if err != nil && !errors.Is(err, errLedgerNotFound) {
+		problems = append(problems, err.Error())
+	}
+	state := buildEpicState(plan.EpicID, ready, all, entries)
+	state.Warning = strings.Join(problems, "; ")
+	return state
+}
+
+func buildEpicState(epicID string, ready []readyIssue, all []listIssue, entries []ledgerEntry) epicState {
+	var state epicState
+	for _, issue := range ready {
+		if !strings.EqualFold(issue.IssueType, "epic") && issueBelongsToEpic(issue.ID, epicID) {
+			state.Ready++
+		}
+	}
+	closed := map[string]bool{}
+	for _, issue := range all {
+		if strings.EqualFold(issue.IssueType, "epic") || !issueBelongsToEpic(issue.ID, epicID) {
+			continue
+		}
+		state.Total++
+		switch strings.ToLower(issue.Status) {
+		case "in_progress":
+			state.InProgress++
+		case "closed":
+			state.Closed++
+			closed[strings.ToLower(issue.ID)] = true
+		}
+	}
+
+	for i := range entries {
+		if state.LastRun == nil || !entries[i].CompletedAt.Before(state.LastRun.CompletedAt) {
+			state.LastRun = &entries[i]
+		}
+	}
+	state.OpenEscalations = openEscalations(entries, closed)
+	return state
+}
+
+// openEscalations returns the latest entry of every bead whose most recent
+// run ended in needs_help, skipping beads in closed (keyed by lowercase ID).
+func openEscalations(entries []ledgerEntry, closed map[string]bool) []ledgerEntry {
+	latest := map[string]ledgerEntry{}
+	var order []string
+	for _, entry := range entries {
+		bead := strings.ToLower(strings.TrimSpace(entry.BeadID))
+		if bead == "" {
+			continue
+		}
+		prev, seen := latest[bead]
+		if !seen {
+			order = append(order, bead)
+		}
+		if !seen || !entry.CompletedAt.Before(prev.CompletedAt) {
+			latest[bead] = entry
+		}
+	}
+	var open []ledgerEntry
+	for _, bead := range order {
+		if entry := latest[bead]; entry.Status == "needs_help" && !closed[bead] {
+			open = append(open, entry)
+		}
+	}
+	return open
+}
+
+func formatEpicState(plan sessionPlan, state epicState, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "State of %s (%s):\n", plan.EpicName, plan.EpicID)
+	if state.Total > 0 || state.Ready > 0 {
+		fmt.Fprintf(&b, "  Beads:       %d ready, %d in progress, %d closed (%d total)\n", state.Ready, state.InProgress, state.Closed, state.Total)
+	}
+	if state.Warning != "" {
+		fmt.Fprintf(&b, "  Warning:     %s\n", state.Warning)
+	}
+	if state.LastRun == nil {
+		b.WriteString("  Last run:    none recorded\n")
+	} else {
+		last := state.LastRun
+		fmt.Fprintf(&b, "  Last run:    %s", last.Status)
+		if bead := strings.TrimSpace(last.BeadID); bead != "" {
+			fmt.Fprintf(&b, " on %s", bead)
+		}
+		fmt.Fprintf(&b, ", %s (%s)\n", timeAgo(now.Sub(last.CompletedAt)), last.CompletedAt.Local().Format("2006-01-02 15:04"))
+	}
+	if len(state.OpenEscalations) > 0 {
+		fmt.Fprintf(&b, "  Needs help:  %d outstanding\n", len(state.OpenEscalations))
+		for _, entry := range state.OpenEscalations {
+			reason := strings.TrimSpace(firstLine(entry.Escalation))
+			if reason == "" {
+				reason = "no escalation recorded"
+			}
+			fmt.Fprintf(&b, "    - %s: %s\n", entry.BeadID, reason)
+		}
+	}
+	return b.String()
+}
+
+// timeAgo renders elapsed time at minute precision ("3h12m ago"), or in
+// days once it exceeds two days.
+func timeAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	}
+	return strings.TrimSuffix(d.Round(time.Minute).String(), "0s") + " ago"
+}
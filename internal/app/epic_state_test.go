@@ -0,0 +1,76 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildEpicStateCountsBeadsAndOutstandingEscalations(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ready := []readyIssue{
+		{ID: "obi-a1.3", IssueType: "task"},
+		{ID: "obi-b2.1", IssueType: "task"},
+		{ID: "obi-a1", IssueType: "epic"},
+	}
+	all := []listIssue{
+		{ID: "obi-a1", Status: "open", IssueType: "epic"},
+		{ID: "obi-a1.1", Status: "closed", IssueType: "task"},
+		{ID: "obi-a1.2", Status: "in_progress", IssueType: "task"},
+		{ID: "obi-a1.3", Status: "open", IssueType: "task"},
+		{ID: "obi-a1.4", Status: "closed", IssueType: "task"},
+		{ID: "obi-b2.1", Status: "open", IssueType: "task"},
+	}
+	entries := []ledgerEntry{
+		{BeadID: "obi-a1.1", Status: "needs_help", Escalation: "flaky", CompletedAt: base},
+		{BeadID: "obi-a1.2", Status: "needs_help", Escalation: "schema unclear\nmore", CompletedAt: base.Add(time.Hour)},
+		{BeadID: "obi-a1.3", Status: "needs_help", CompletedAt: base.Add(2 * time.Hour)},
+		{BeadID: "obi-a1.3", Status: "success", CompletedAt: base.Add(3 * time.Hour)},
+	}
+
+	state := buildEpicState("obi-a1", ready, all, entries)
+	if state.Ready != 1 || state.InProgress != 1 || state.Closed != 2 || state.Total != 4 {
+		t.Fatalf("unexpected counts: %+v", state)
+	}
+	if state.LastRun == nil || state.LastRun.BeadID != "obi-a1.3" || state.LastRun.Status != "success" {
+		t.Fatalf("unexpected last run: %+v", state.LastRun)
+	}
+	if len(state.OpenEscalations) != 1 || state.OpenEscalations[0].BeadID != "obi-a1.2" {
+		t.Fatalf("expected only the unresolved open bead, got %+v", state.OpenEscalations)
+	}
+
+	plan := sessionPlan{EpicName: "Alpha", EpicID: "obi-a1"}
+	out := formatEpicState(plan, state, base.Add(5*time.Hour+10*time.Minute))
+	for _, want := range []string{
+		"State of Alpha (obi-a1):",
+		"1 ready, 1 in progress, 2 closed (4 total)",
+		"Last run:    success on obi-a1.3, 2h10m ago",
+		"Needs help:  1 outstanding",
+		"- obi-a1.2: schema unclear\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("banner missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatEpicStateWithoutHistory(t *testing.T) {
+	out := formatEpicState(sessionPlan{EpicName: "Alpha", EpicID: "obi-a1"}, epicState{Warning: "bd list: not found"}, time.Now())
+	if !strings.Contains(out, "none recorded") || !strings.Contains(out, "Warning:     bd list: not found") || strings.Contains(out, "Beads:") {
+		t.Fatalf("unexpected banner:\n%s", out)
+	}
+}
+
+func TestTimeAgo(t *testing.T) {
+	cases := map[time.Duration]string{
+		20 * time.Second:            "just now",
+		45 * time.Minute:            "45m ago",
+		3*time.Hour + 2*time.Minute: "3h2m ago",
+		72 * time.Hour:              "3d ago",
+	}
+	for d, want := range cases {
+		if got := timeAgo(d); got != want {
+			t.Fatalf("timeAgo(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/tui"
+)
+
+const (
+	// epicDetailWorkers caps the bd show calls refresh --seed-prompts runs
+	// at once.
+	epicDetailWorkers = 4
+	// epicDetailTimeout bounds each bd show call.
+	epicDetailTimeout = 15 * time.Second
+)
+
+// describeEpics fills in the descriptions bd epic status left out, for
+// refresh --seed-prompts to start prompts from. Epics are fetched with
+// fetch in parallel; one that fails keeps an empty description and is
+// reported by ID in the returned map instead of failing the refresh.
+func describeEpics(requests map[string]aliasRequest, fetch tui.BeadFetcher, logger refreshLogger) map[string]error {
+	type job struct{ key, id string }
+	var todo []job
+	for key, req := range requests {
+		if strings.TrimSpace(req.Description) == "" && req.ID != "" {
+			todo = append(todo, job{key: key, id: req.ID})
+		}
+	}
+	if len(todo) == 0 {
+		return nil
+	}
+	sort.Slice(todo, func(i, j int) bool { return todo[i].key < todo[j].key })
+	logger.Printf("Fetching details for %d epic(s) via `bd show`...\n", len(todo))
+
+	type fetched struct {
+		job
+		description string
+		err         error
+	}
+	// Workers see only their job; requests is written once they are done.
+	jobs := make(chan job)
+	results := make(chan fetched)
+	var wg sync.WaitGroup
+	for i := 0; i < min(epicDetailWorkers, len(todo)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				ctx, cancel := context.WithTimeout(context.Background(), epicDetailTimeout)
+				details, err := fetch(ctx, j.id)
+				cancel()
+				results <- fetched{job: j, description: details.Description, err: err}
+			}
+		}()
+	}
+	go func() {
+		for _, j := range todo {
+			jobs <- j
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	var done []fetched
+	failures := map[string]error{}
+	progress := logger.progress("Fetched epic details", len(todo))
+	for res := range results {
+		if res.err != nil {
+			failures[res.id] = res.err
+			progress.note(fmt.Sprintf("  ! %s: %v; its prompt starts empty", res.id, res.err))
+		} else {
+			done = append(done, res)
+		}
+		progress.step()
+	}
+	progress.done()
+	for _, res := range done {
+		req := requests[res.key]
+		req.Description = res.description
+		requests[res.key] = req
+	}
+	return failures
+}
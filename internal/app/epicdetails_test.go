@@ -0,0 +1,40 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/tui"
+)
+
+func TestDescribeEpicsFetchesMissingDescriptionsAndReportsFailures(t *testing.T) {
+	requests := map[string]aliasRequest{
+		"obi_a": {Key: "obi_a", ID: "obi-a", Title: "A"},
+		"obi_b": {Key: "obi_b", ID: "obi-b", Title: "B", Description: "already known"},
+		"obi_c": {Key: "obi_c", ID: "obi-c", Title: "C"},
+	}
+	var calls atomic.Int32
+	fetch := func(_ context.Context, id string) (tui.BeadDetails, error) {
+		calls.Add(1)
+		if id == "obi-c" {
+			return tui.BeadDetails{}, errors.New("bd show: exit status 1")
+		}
+		return tui.BeadDetails{ID: id, Description: "about " + id}, nil
+	}
+
+	failures := describeEpics(requests, fetch, refreshLogger{})
+	if calls.Load() != 2 {
+		t.Fatalf("expected only epics without descriptions to be fetched, got %d calls", calls.Load())
+	}
+	if got := requests["obi_a"].Description; got != "about obi-a" {
+		t.Fatalf("description not filled in: %q", got)
+	}
+	if got := requests["obi_b"].Description; got != "already known" {
+		t.Fatalf("known description replaced: %q", got)
+	}
+	if len(failures) != 1 || failures["obi-c"] == nil || requests["obi_c"].Description != "" {
+		t.Fatalf("failures = %v, requests = %+v", failures, requests)
+	}
+}
@@ -0,0 +1,289 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// defaultEscalationPattern recognizes the approval prompts Codex prints when
+// a command needs approval or has to leave the sandbox. The capture group is
+// the command when it follows on the same line.
+const defaultEscalationPattern = `(?i)(?:allow command\?|would you like to run the following command\?|requires? (?:your )?approval(?: to run)?|sandbox escalation requested)[:\s]*(.*)$`
+
+const (
+	// Keys sent to Codex's approval prompt.
+	escalationApproveInput = "y"
+	escalationDenyInput    = "n"
+	// escalationRepeatWindow ignores the same request redrawn by Codex's TUI.
+	escalationRepeatWindow = 2 * time.Second
+)
+
+// shellControlPattern matches what lets one approved command run others:
+// separators, pipes, backgrounding, substitutions and redirections.
+var shellControlPattern = regexp.MustCompile("[;&|`<>\n]|\\$\\(")
+
+// escalationPolicy is a compiled [escalation] config.
+type escalationPolicy struct {
+	action string
+	// approve holds the auto_approve patterns anchored at both ends;
+	// approveSource keeps them as written, for messages.
+	approve       []*regexp.Regexp
+	approveSource []string
+	request       *regexp.Regexp
+}
+
+func newEscalationPolicy(cfg config.EscalationConfig) (escalationPolicy, error) {
+	policy := escalationPolicy{action: strings.ToLower(strings.TrimSpace(cfg.Action))}
+	switch policy.action {
+	case "":
+		policy.action = config.EscalationIgnore
+	case config.EscalationIgnore, config.EscalationPrompt, config.EscalationDeny:
+	default:
+		return escalationPolicy{}, fmt.Errorf("invalid escalation action %q (want %s, %s, or %s)", cfg.Action, config.EscalationPrompt, config.EscalationDeny, config.EscalationIgnore)
+	}
+	for _, pattern := range cfg.AutoApprove {
+		re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return escalationPolicy{}, fmt.Errorf("invalid escalation auto_approve pattern %q: %w", pattern, err)
+		}
+		policy.approve = append(policy.approve, re)
+		policy.approveSource = append(policy.approveSource, pattern)
+	}
+	requestPattern := defaultEscalationPattern
+	if strings.TrimSpace(cfg.RequestPattern) != "" {
+		requestPattern = cfg.RequestPattern
+	}
+	re, err := regexp.Compile(requestPattern)
+	if err != nil {
+		return escalationPolicy{}, fmt.Errorf("invalid escalation request_pattern: %w", err)
+	}
+	policy.request = re
+	return policy, nil
+}
+
+// active reports whether the policy ever answers on Codex's behalf.
+func (p escalationPolicy) active() bool {
+	return p.action != config.EscalationIgnore || len(p.approve) > 0
+}
+
+// autoApproves returns the auto_approve pattern that matches all of
+// command, if any. Commands that chain, pipe, substitute or redirect are
+// never auto-approved, whatever the patterns say.
+func (p escalationPolicy) autoApproves(command string) (string, bool) {
+	if shellControlPattern.MatchString(command) {
+		return "", false
+	}
+	for i, re := range p.approve {
+		if re.MatchString(command) {
+			return p.approveSource[i], true
+		}
+	}
+	return "", false
+}
+
+type escalationResponder interface {
+	WriteInput([]byte) (int, error)
+}
+
+type approvalPrompter interface {
+	RequestApproval(request string, decide func(approved bool))
+}
+
+// escalationWatcher reads Codex output for approval requests and answers
+// them according to the policy, recording every decision as an operator
+// event. It sits on the session tee, so it sees redacted output.
+type escalationWatcher struct {
+	policy escalationPolicy
+	log    *operatorLog
+	audit  *auditLog
+	now    func() time.Time
+
+	mu       sync.Mutex
+	partial  string
+	awaiting bool
+	session  escalationResponder
+	prompter approvalPrompter
+	notify   eventNotifier
+	// alert is told about every request an operator has to answer, in the
+	// modal or at Codex's own prompt; see withAlert.
+	alert   func(command string)
+	pending map[string]bool
+	recent  map[string]time.Time
+}
+
+// newEscalationWatcher returns nil when the policy leaves every request to
+// Codex, so callers can skip wiring it in.
+func newEscalationWatcher(policy escalationPolicy, log *operatorLog, audit *auditLog) *escalationWatcher {
+	if !policy.active() {
+		return nil
+	}
+	return buildEscalationWatcher(policy, log, audit)
+}
+
+// withAlert sets alert on w, creating a watcher when the policy left it nil
+// so requests Codex asks about itself are still noticed.
+func (w *escalationWatcher) withAlert(policy escalationPolicy, log *operatorLog, audit *auditLog, alert func(command string)) *escalationWatcher {
+	if alert == nil {
+		return w
+	}
+	if w == nil {
+		w = buildEscalationWatcher(policy, log, audit)
+	}
+	w.alert = alert
+	return w
+}
+
+func buildEscalationWatcher(policy escalationPolicy, log *operatorLog, audit *auditLog) *escalationWatcher {
+	return &escalationWatcher{
+		policy:  policy,
+		log:     log,
+		audit:   audit,
+		now:     time.Now,
+		pending: map[string]bool{},
+		recent:  map[string]time.Time{},
+	}
+}
+
+// attach connects the running session and, when a TUI is up, the approval
+// modal and its event log.
+func (w *escalationWatcher) attach(session escalationResponder, prompter approvalPrompter, notify eventNotifier) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.session = session
+	w.prompter = prompter
+	w.notify = notify
+}
+
+// Write scans complete output lines for approval requests. It never fails
+// so it can sit alongside the transcript writer.
+func (w *escalationWatcher) Write(p []byte) (int, error) {
+	if w == nil {
+		return len(p), nil
+	}
+	w.mu.Lock()
+	text := w.partial + strings.ReplaceAll(string(p), "\r", "\n")
+	lines := strings.Split(text, "\n")
+	w.partial = lines[len(lines)-1]
+	var prompts []func()
+	for _, line := range lines[:len(lines)-1] {
+		if ask := w.observeLineLocked(line); ask != nil {
+			prompts = append(prompts, ask)
+		}
+	}
+	w.mu.Unlock()
+	// The modal calls back into the watcher, so it is opened unlocked.
+	for _, ask := range prompts {
+		ask()
+	}
+	return len(p), nil
+}
+
+func (w *escalationWatcher) observeLineLocked(line string) func() {
+	line = strings.TrimSpace(ansiSequence.ReplaceAllString(line, ""))
+	if w.awaiting {
+		if line == "" {
+			return nil
+		}
+		w.awaiting = false
+		return w.handleLocked(escalationCommand(line))
+	}
+	match := w.policy.request.FindStringSubmatch(line)
+	if match == nil {
+		return nil
+	}
+	var command string
+	if len(match) > 1 {
+		command = escalationCommand(match[1])
+	}
+	if command == "" {
+		w.awaiting = true
+		return nil
+	}
+	return w.handleLocked(command)
+}
+
+// escalationCommand strips the shell prompt and quoting Codex puts around a
+// requested command.
+func escalationCommand(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "$ ")
+	return strings.Trim(strings.TrimSpace(text), "`")
+}
+
+func (w *escalationWatcher) handleLocked(command string) func() {
+	now := w.now()
+	if w.pending[command] {
+		return nil
+	}
+	if last, ok := w.recent[command]; ok && now.Sub(last) < escalationRepeatWindow {
+		return nil
+	}
+	w.recent[command] = now
+	if pattern, ok := w.policy.autoApproves(command); ok {
+		w.respondLocked(true, fmt.Sprintf("auto-approved %s (matches %s)", command, pattern))
+		return nil
+	}
+	if w.policy.action == config.EscalationDeny {
+		w.respondLocked(false, fmt.Sprintf("denied %s (escalation action is deny)", command))
+		return nil
+	}
+	if w.alert != nil {
+		w.alert(command)
+	}
+	if w.policy.action == config.EscalationPrompt {
+		if w.prompter == nil {
+			w.recordLocked(fmt.Sprintf("left %s to Codex's prompt (no TUI to ask in)", command))
+			return nil
+		}
+		w.pending[command] = true
+		prompter := w.prompter
+		return func() {
+			prompter.RequestApproval(command, func(approved bool) {
+				w.decide(command, approved)
+			})
+		}
+	}
+	return nil
+}
+
+// decide applies the operator's answer from the approval modal.
+func (w *escalationWatcher) decide(command string, approved bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.pending, command)
+	w.recent[command] = w.now()
+	verdict := "denied"
+	if approved {
+		verdict = "approved"
+	}
+	w.audit.record(auditEscalation, fmt.Sprintf("%s %s", verdict, command))
+	w.respondLocked(approved, fmt.Sprintf("operator %s %s", verdict, command))
+}
+
+func (w *escalationWatcher) respondLocked(approved bool, message string) {
+	input := escalationDenyInput
+	if approved {
+		input = escalationApproveInput
+	}
+	if w.session == nil {
+		message += "; session not ready, answer not sent"
+	} else if _, err := w.session.WriteInput([]byte(input)); err != nil {
+		message += fmt.Sprintf("; sending answer failed: %v", err)
+	}
+	w.recordLocked(message)
+}
+
+func (w *escalationWatcher) recordLocked(message string) {
+	w.log.record(operatorEventEscalation, message)
+	if w.notify != nil {
+		w.notify(operatorEventEscalation, message)
+	}
+}
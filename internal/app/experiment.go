@@ -0,0 +1,598 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/footer"
+)
+
+// Experiment modes.
+const (
+	experimentModeSimulate = "simulate"
+	experimentModeLive     = "live"
+)
+
+// experimentStatusError marks a variant that never produced a ledger
+// entry, for example because its worktree could not be created.
+const experimentStatusError = "error"
+
+const experimentContractTemplate = `Experiment contract for bead %s:
+- This session is one variant of a prompt experiment; other variants attempt the same bead separately.
+- Work only on %s. Read it with "bd show %s --json", but do not claim, close, create, or update beads: every variant shares the same bd data.
+- Implement the bead, run the checks that cover what you changed, and commit on the current branch.
+- Emit STATUS: success once the work is committed and the checks pass. Otherwise emit STATUS: needs_help with ESCALATION explaining the blocker.`
+
+// experimentArm is one variant's session in an obi experiment run.
+type experimentArm struct {
+	ID      string
+	Variant string
+	BeadID  string
+}
+
+// experimentRecord is one line of experiments.log: how a variant did.
+type experimentRecord struct {
+	ExperimentID string    `json:"experiment_id"`
+	Mode         string    `json:"mode"`
+	Variant      string    `json:"variant"`
+	VariantPath  string    `json:"variant_path"`
+	EpicID       string    `json:"epic_id"`
+	BeadID       string    `json:"bead_id"`
+	RunID        string    `json:"run_id,omitempty"`
+	Status       string    `json:"status"`
+	Error        string    `json:"error,omitempty"`
+	Model        string    `json:"codex_model,omitempty"`
+	DurationMs   int64     `json:"duration_ms"`
+	TokensUsed   int64     `json:"tokens_used,omitempty"`
+	CostUSD      float64   `json:"cost_usd,omitempty"`
+	Commits      int       `json:"commits,omitempty"`
+	Verified     *bool     `json:"verified,omitempty"`
+	Worktree     string    `json:"worktree,omitempty"`
+	Branch       string    `json:"branch,omitempty"`
+	CompletedAt  time.Time `json:"completed_at"`
+}
+
+// variantsFlag is the repeatable --variants flag; each value is a file or
+// a glob.
+type variantsFlag []string
+
+func (v *variantsFlag) String() string {
+	if v == nil {
+		return ""
+	}
+	return strings.Join(*v, ",")
+}
+
+func (v *variantsFlag) Set(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("--variants expects a file or glob")
+	}
+	*v = append(*v, value)
+	return nil
+}
+
+// experimentDir holds the experiment ledger and the results log, transcripts
+// and worktrees of experiment sessions, next to the results log at logPath.
+// Keeping them apart means variants never count toward --resume, obi list
+// or the omnibus summary.
+func experimentDir(logPath string) string {
+	return filepath.Join(filepath.Dir(logPath), "experiments")
+}
+
+func experimentLogPath(logPath string) string {
+	return filepath.Join(experimentDir(logPath), "experiments.log")
+}
+
+func runExperiment(args []string) error {
+	if len(args) > 0 && args[0] == "report" {
+		return runExperimentReport(args[1:])
+	}
+	fs := newCommandFlags("experiment", "obi experiment --variants <files|glob> --bead <id> --simulate|--live [options]",
+		"Run one bead once per prompt variant and report which variant did best. Each variant file\nsets prompt, base_prompt and/or a [codex] table. --live runs Codex in a fresh git worktree\nper variant; --simulate plays a fakecodex scenario instead. obi experiment report [id]\nprints an earlier experiment's results again.")
+	var opts goOptions
+	var patterns variantsFlag
+	var bead string
+	var live bool
+	fs.StringVar(&opts.configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.StringVar(&opts.profile, "profile", "", "apply this [profiles.*] overlay (defaults to $OBI_PROFILE)")
+	fs.Var(&patterns, "variants", "variant TOML files or a glob such as 'prompts/*.toml' (repeatable)")
+	fs.StringVar(&bead, "bead", "", "the bead every variant works on")
+	fs.Var(&opts.simulate, "simulate", "play a fakecodex scenario (default success) instead of running Codex")
+	fs.BoolVar(&live, "live", false, "run Codex for real, each variant in its own git worktree")
+	fs.BoolVar(&opts.noTUI, "no-tui", false, "disable the interactive TUI (stream raw Codex output)")
+	fs.BoolVar(&opts.ci, "ci", false, "non-interactive mode: fail when the fenced report and legacy footer disagree instead of prompting")
+	fs.BoolVar(&opts.yes, "yes", false, "start without the confirmation prompt")
+	if _, err := fs.parse(expandVariantArgs(args)); err != nil {
+		return err
+	}
+	mode := experimentModeLive
+	switch {
+	case opts.simulate != "" && live:
+		return errors.New("choose one of --simulate and --live")
+	case opts.simulate != "":
+		mode = experimentModeSimulate
+	case !live:
+		return errors.New("obi experiment needs --simulate to rehearse or --live to run Codex")
+	}
+	bead = strings.TrimSpace(bead)
+	if bead == "" {
+		return errors.New("obi experiment needs --bead <id>")
+	}
+	paths, err := variantPaths(patterns)
+	if err != nil {
+		return err
+	}
+	if len(paths) < 2 {
+		return errors.New("an experiment needs at least two variant files; copy the epic prompt into one to use it as the baseline")
+	}
+	variants, err := config.LoadExperimentVariants(paths)
+	if err != nil {
+		return err
+	}
+
+	resolvedPath, cfg, err := loadConfig(opts.configPath, opts.profile)
+	if err != nil {
+		return err
+	}
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+	base, err := experimentBasePlan(cfg, bead)
+	if err != nil {
+		return err
+	}
+	base.RepoRoot = repoRootForConfig(resolvedPath)
+	base.ConfigDigest = configDigest(resolvedPath)
+	if mode == experimentModeLive {
+		if err := ensureCleanTree(cfg, base.RepoRoot, resolvedPath); err != nil {
+			return err
+		}
+		if err := ensureScheduleOpen(cfg, time.Now()); err != nil {
+			return err
+		}
+		if out, err := gitOutput(base.RepoRoot, "status", "--porcelain"); err == nil && out != "" {
+			fmt.Println("Note: uncommitted changes are not part of the variants' worktrees, which start from HEAD.")
+		}
+	} else {
+		if _, err := simulateConfig(cfg); err != nil {
+			return err
+		}
+		// Simulated variants share the checkout; live ones resolve the
+		// epic's dir inside their own worktree.
+		if err := applyRunContext(&base, "", nil); err != nil {
+			return err
+		}
+	}
+	// Variants are not epic progress: no webhooks, notifications or
+	// entries in the epic's results log.
+	cfg.Webhooks = config.WebhooksConfig{}
+	cfg.Notify.Webhook = ""
+	sessionLog := filepath.Join(experimentDir(logPath), filepath.Base(logPath))
+	cfg.ResultsLog = sessionLog
+
+	id := time.Now().UTC().Format("20060102-150405")
+	fmt.Print(formatExperimentPlan(id, mode, base, variants))
+	fmt.Printf("Sessions are logged to %s; outcomes to %s.\n", sessionLog, experimentLogPath(logPath))
+	if cfg.ConfirmBeforeRunValue() && !opts.yes {
+		ok, err := promptForConfirmation()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("experiment cancelled")
+		}
+	}
+
+	// The session's own relay treats a first Ctrl+C as a soft stop of the
+	// running variant; only a second one ends the experiment.
+	interrupted := make(chan os.Signal, 2)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+
+	var records []experimentRecord
+	for i, variant := range variants {
+		arm := experimentArm{ID: id, Variant: variant.Name, BeadID: bead}
+		plan := variantPlan(base, variant, arm)
+		rec := experimentRecord{ExperimentID: id, Mode: mode, Variant: variant.Name, VariantPath: variant.Path, EpicID: base.EpicID, BeadID: bead}
+		fmt.Printf("\n=== Variant %d/%d: %s ===\n", i+1, len(variants), variant.Name)
+		var runErr error
+		if mode == experimentModeLive {
+			rec.Worktree = filepath.Join(experimentDir(logPath), "worktrees", id, variant.Name)
+			rec.Branch = "obi/experiment-" + id + "-" + variant.Name
+			if runErr = addGitWorktree(base.RepoRoot, rec.Worktree, rec.Branch); runErr == nil {
+				plan.Dir = rebaseRunDir(base.Dir, base.RepoRoot, rec.Worktree)
+				plan.RepoRoot = rec.Worktree
+				runErr = applyRunContext(&plan, "", nil)
+			}
+		} else {
+			runErr = applySimulation(&plan, string(opts.simulate))
+		}
+		if runErr == nil {
+			_, runErr = executeSession(plan, opts, cfg, sessionLog, false, false)
+		}
+		rec = fillExperimentRecord(rec, sessionLog, runErr)
+		if err := appendExperimentRecord(experimentLogPath(logPath), rec); err != nil {
+			diag.Logger().Warn("experiment log write failed", "variant", variant.Name, "err", err)
+		}
+		records = append(records, rec)
+
+		if interrupts := drainSignals(interrupted); interrupts >= 2 {
+			if i+1 < len(variants) {
+				fmt.Printf("Interrupted; skipping the remaining %d variant(s).\n", len(variants)-i-1)
+			}
+			fmt.Print("\n" + formatExperimentReport(records))
+			return newExitError("experiment interrupted")
+		} else if interrupts == 1 && i+1 < len(variants) {
+			fmt.Println("Ctrl+C soft-stopped this variant; press it twice to stop the experiment.")
+		}
+	}
+	fmt.Print("\n" + formatExperimentReport(records))
+	return nil
+}
+
+// expandVariantArgs lets an unquoted glob through: the shell expands
+// --variants prompts/*.toml into several files, and every argument after
+// --variants up to the next flag becomes one more --variants value.
+func expandVariantArgs(args []string) []string {
+	var out []string
+	// pending is set while --variants still waits for its own value.
+	inVariants, pending := false, false
+	for _, arg := range args {
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		switch {
+		case strings.HasPrefix(arg, "-"):
+			inVariants = name == "variants"
+			pending = inVariants && !strings.Contains(arg, "=")
+		case inVariants && !pending:
+			out = append(out, "--variants")
+		default:
+			pending = false
+		}
+		out = append(out, arg)
+	}
+	return out
+}
+
+// variantPaths expands the --variants values in order, dropping repeats. A
+// glob that matches nothing is an error, since a typo would otherwise
+// quietly shrink the experiment.
+func variantPaths(patterns []string) ([]string, error) {
+	var paths []string
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, fmt.Errorf("--variants %q: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("--variants %q matches no files", pattern)
+			}
+		}
+		for _, path := range matches {
+			if clean := filepath.Clean(path); !seen[clean] {
+				seen[clean] = true
+				paths = append(paths, clean)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// experimentBasePlan finds the epic that owns beadID in obi.toml and
+// prepares its session.
+func experimentBasePlan(cfg *config.Config, beadID string) (sessionPlan, error) {
+	epicID := parentEpicID(beadID)
+	if epicID == "" {
+		return sessionPlan{}, fmt.Errorf("bead %s is not part of an epic; experiments run epic beads", beadID)
+	}
+	for key, epic := range cfg.Epics {
+		if strings.EqualFold(epic.ID, epicID) {
+			return prepareSession(cfg, key)
+		}
+	}
+	return sessionPlan{}, fmt.Errorf("bead %s belongs to %s, which is not an epic in obi.toml", beadID, epicID)
+}
+
+// variantPlan applies variant to base. The session is pinned to the bead,
+// so it neither follows the epic's branch policy nor checks for
+// duplicates: every variant may well share a prompt with an earlier run.
+func variantPlan(base sessionPlan, variant config.ExperimentVariant, arm experimentArm) sessionPlan {
+	plan := base
+	if prompt := strings.TrimSpace(variant.Prompt); prompt != "" {
+		plan.EpicPrompt = prompt
+	}
+	if prompt := strings.TrimSpace(variant.BasePrompt); prompt != "" {
+		plan.BasePrompt = prompt
+	}
+	plan.Codex = variant.Codex(base.Codex)
+	plan.Env = append([]string(nil), base.Env...)
+	plan.Branch = ""
+	plan.BeadIDOverride = arm.BeadID
+	plan.Experiment = &arm
+	return plan
+}
+
+// drainSignals counts the signals waiting on ch without blocking.
+func drainSignals(ch <-chan os.Signal) int {
+	n := 0
+	for {
+		select {
+		case <-ch:
+			n++
+		default:
+			return n
+		}
+	}
+}
+
+// rebaseRunDir maps the epic's working directory, relative to the repo root
+// or absolute inside it, into a worktree of it. Without one Codex would
+// start wherever obi was run, so the worktree itself is the default.
+func rebaseRunDir(dir, root, worktree string) string {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return worktree
+	}
+	if !filepath.IsAbs(dir) {
+		return filepath.Join(worktree, dir)
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return worktree
+	}
+	return filepath.Join(worktree, rel)
+}
+
+func experimentContract(plan sessionPlan) string {
+	bead := plan.Experiment.BeadID
+	return fmt.Sprintf(experimentContractTemplate, bead, bead, bead)
+}
+
+// fillExperimentRecord copies the outcome of rec's session from its ledger
+// entry. Without one, the session never ran to the end and runErr says
+// why.
+func fillExperimentRecord(rec experimentRecord, sessionLog string, runErr error) experimentRecord {
+	rec.CompletedAt = time.Now().UTC()
+	entries, _ := ledgerEntriesForEpic(sessionLog, rec.EpicID)
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Experiment != rec.ExperimentID || entry.Variant != rec.Variant {
+			continue
+		}
+		rec.RunID = entry.RunID
+		rec.Status = entry.Status
+		rec.Model = entry.CodexModel
+		rec.DurationMs = runDuration(entry).Milliseconds()
+		rec.TokensUsed = entry.TokensUsed
+		rec.CostUSD = entry.CostUSD
+		rec.CompletedAt = entry.CompletedAt
+		if entry.Git != nil {
+			rec.Commits = len(entry.Git.Commits)
+		}
+		if v := entry.Verification; v != nil {
+			passed := v.Passed
+			rec.Verified = &passed
+		}
+		return rec
+	}
+	rec.Status = experimentStatusError
+	rec.Error = "no ledger entry"
+	if runErr != nil {
+		rec.Error = firstLine(runErr.Error())
+	}
+	return rec
+}
+
+func appendExperimentRecord(path string, rec experimentRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode experiment record: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create experiment log dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open experiment log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write experiment log: %w", err)
+	}
+	return nil
+}
+
+func readExperimentRecords(path string) ([]experimentRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var records []experimentRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), ledgerScanMaxBytes)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec experimentRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, n, err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read experiment log: %w", err)
+	}
+	return records, nil
+}
+
+func runExperimentReport(args []string) error {
+	fs := newCommandFlags("experiment report", "obi experiment report [experiment-id] [options]",
+		"Print the results of an experiment from experiments.log, the latest one by default.\nExperiment IDs may be shortened to any unambiguous prefix.", "experiment-id")
+	var configPath string
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	positional, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	_, cfg, err := loadConfig(configPath, "")
+	if err != nil {
+		return err
+	}
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+	path := experimentLogPath(logPath)
+	records, err := readExperimentRecords(path)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Printf("No experiments recorded yet (%s).\n", path)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	selected, err := selectExperiment(records, positionalArg(positional, 0))
+	if err != nil {
+		return fmt.Errorf("%w in %s", err, path)
+	}
+	fmt.Print(formatExperimentReport(selected))
+	return nil
+}
+
+// selectExperiment returns the records of the experiment whose ID starts
+// with id, or of the latest experiment when id is empty.
+func selectExperiment(records []experimentRecord, id string) ([]experimentRecord, error) {
+	id = strings.TrimSpace(id)
+	if id == "" && len(records) > 0 {
+		id = records[len(records)-1].ExperimentID
+	}
+	var match string
+	var selected []experimentRecord
+	for _, rec := range records {
+		if id == "" || !strings.HasPrefix(rec.ExperimentID, id) {
+			continue
+		}
+		if match != "" && rec.ExperimentID != match {
+			return nil, fmt.Errorf("experiment ID %q matches both %s and %s; give more of it", id, match, rec.ExperimentID)
+		}
+		match = rec.ExperimentID
+		selected = append(selected, rec)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no experiment %q recorded", id)
+	}
+	return selected, nil
+}
+
+func formatExperimentPlan(id, mode string, base sessionPlan, variants []config.ExperimentVariant) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Experiment %s (%s): %d variants of %s (%s), one session each on the same bead.\n", id, mode, len(variants), base.EpicName, base.EpicID)
+	rows := [][]string{{"variant", "file", "model"}}
+	for _, v := range variants {
+		rows = append(rows, []string{v.Name, v.Path, dashIfEmpty(v.Codex(base.Codex).Model)})
+	}
+	b.WriteString(formatTextTable(rows))
+	return b.String()
+}
+
+// rankExperiment orders records best first: successful runs, then the
+// cheapest (by cost, or tokens without a price), then the fastest. Ties
+// keep their run order.
+func rankExperiment(records []experimentRecord) []experimentRecord {
+	ranked := append([]experimentRecord(nil), records...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if ra, rb := experimentStatusRank(a.Status), experimentStatusRank(b.Status); ra != rb {
+			return ra < rb
+		}
+		if a.CostUSD > 0 && b.CostUSD > 0 && a.CostUSD != b.CostUSD {
+			return a.CostUSD < b.CostUSD
+		}
+		if a.TokensUsed > 0 && b.TokensUsed > 0 && a.TokensUsed != b.TokensUsed {
+			return a.TokensUsed < b.TokensUsed
+		}
+		return a.DurationMs < b.DurationMs
+	})
+	return ranked
+}
+
+func experimentStatusRank(status string) int {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case footer.StatusSuccess:
+		return 0
+	case footer.StatusFailure:
+		return 1
+	case ledgerStatusUnparsed:
+		return 2
+	}
+	return 3
+}
+
+// formatExperimentReport ranks one experiment's variants in a table and
+// names the best one, if any succeeded.
+func formatExperimentReport(records []experimentRecord) string {
+	if len(records) == 0 {
+		return "No variants ran.\n"
+	}
+	first := records[0]
+	var b strings.Builder
+	fmt.Fprintf(&b, "Experiment %s (%s) on bead %s:\n", first.ExperimentID, first.Mode, first.BeadID)
+	ranked := rankExperiment(records)
+	rows := [][]string{{"rank", "variant", "status", "duration", "tokens", "cost", "commits", "verify", "run"}}
+	for i, rec := range ranked {
+		status := rec.Status
+		if rec.Error != "" {
+			status += ": " + rec.Error
+		}
+		cost := "-"
+		if rec.CostUSD > 0 {
+			cost = fmt.Sprintf("$%.2f", rec.CostUSD)
+		}
+		verify := "-"
+		if rec.Verified != nil {
+			verify = "failed"
+			if *rec.Verified {
+				verify = "passed"
+			}
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1), rec.Variant, status,
+			(time.Duration(rec.DurationMs) * time.Millisecond).String(),
+			strconv.FormatInt(rec.TokensUsed, 10), cost, strconv.Itoa(rec.Commits), verify, dashIfEmpty(rec.RunID),
+		})
+	}
+	b.WriteString(formatTextTable(rows))
+	if best := ranked[0]; experimentStatusRank(best.Status) == 0 {
+		fmt.Fprintf(&b, "Best: %s\n", best.Variant)
+	} else {
+		b.WriteString("No variant succeeded; read the transcripts before trusting any of them.\n")
+	}
+	var worktrees []string
+	for _, rec := range records {
+		if rec.Worktree != "" && rec.RunID != "" {
+			worktrees = append(worktrees, fmt.Sprintf("  %s: %s (branch %s)", rec.Variant, rec.Worktree, rec.Branch))
+		}
+	}
+	if len(worktrees) > 0 {
+		b.WriteString("Each variant's work stays in its worktree for review; git worktree remove <path> discards one:\n")
+		b.WriteString(strings.Join(worktrees, "\n") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestExpandVariantArgsAcceptsShellExpandedGlobs(t *testing.T) {
+	args := []string{"--variants", "a.toml", "b.toml", "--bead", "api-1.3", "--variants=c.toml", "d.toml", "--live"}
+	got := strings.Join(expandVariantArgs(args), " ")
+	want := "--variants a.toml --variants b.toml --bead api-1.3 --variants=c.toml --variants d.toml --live"
+	if got != want {
+		t.Fatalf("args = %q, want %q", got, want)
+	}
+}
+
+func TestVariantPathsExpandsGlobs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.toml", "a.toml", "notes.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("prompt = \"x\"\n"), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	paths, err := variantPaths([]string{filepath.Join(dir, "*.toml"), filepath.Join(dir, "a.toml")})
+	if err != nil {
+		t.Fatalf("variantPaths: %v", err)
+	}
+	if len(paths) != 2 || filepath.Base(paths[0]) != "a.toml" || filepath.Base(paths[1]) != "b.toml" {
+		t.Fatalf("paths = %q", paths)
+	}
+	if _, err := variantPaths([]string{filepath.Join(dir, "*.yaml")}); err == nil || !strings.Contains(err.Error(), "matches no files") {
+		t.Fatalf("expected an error for an empty glob, got %v", err)
+	}
+}
+
+func TestVariantPlanPinsTheBead(t *testing.T) {
+	cfg := &config.Config{
+		BasePrompt: "base",
+		Codex:      config.CodexConfig{Binary: "codex", Model: "gpt"},
+		Epics:      map[string]config.EpicConfig{"api": {ID: "api-1", Name: "API", Prompt: "epic prompt", Branch: "epic/api"}},
+	}
+	base, err := experimentBasePlan(cfg, "api-1.3")
+	if err != nil {
+		t.Fatalf("experimentBasePlan: %v", err)
+	}
+	if _, err := experimentBasePlan(cfg, "web-2.1"); err == nil {
+		t.Fatal("a bead of an unknown epic should be rejected")
+	}
+	variant := config.ExperimentVariant{Name: "terse", Prompt: "Be brief.", CodexOverride: &config.CodexConfig{Model: "gpt-big"}}
+	plan := variantPlan(base, variant, experimentArm{ID: "20260301-090000", Variant: "terse", BeadID: "api-1.3"})
+	if plan.EpicPrompt != "Be brief." || plan.BasePrompt != "base" || plan.Codex.Model != "gpt-big" || plan.Branch != "" || plan.BeadIDOverride != "api-1.3" {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	prompt := buildPrompt(plan)
+	if !strings.Contains(prompt, "Experiment contract for bead api-1.3") || strings.Contains(prompt, "Epic completion contract") {
+		t.Fatalf("prompt should carry the experiment contract only:\n%s", prompt)
+	}
+	if got := rebaseRunDir("/repo/services/api", "/repo", "/wt/terse"); got != "/wt/terse/services/api" {
+		t.Fatalf("rebased dir = %q", got)
+	}
+	if got := rebaseRunDir("services/api", "/repo", "/wt/terse"); got != "/wt/terse/services/api" {
+		t.Fatalf("rebased relative dir = %q", got)
+	}
+	if got := rebaseRunDir("", "/repo", "/wt/terse"); got != "/wt/terse" {
+		t.Fatalf("default dir = %q", got)
+	}
+}
+
+func TestFillExperimentRecordReadsTheVariantsLedgerEntry(t *testing.T) {
+	sessionLog := filepath.Join(t.TempDir(), "results.log")
+	done := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, entry := range []ledgerEntry{
+		{RunID: "run-a", EpicID: "api-1", Status: "success", Experiment: "exp-1", Variant: "terse", DurationMs: 90_000, TokensUsed: 1200, CompletedAt: done,
+			Git: &gitMetadata{Commits: []string{"abc"}}, Verification: &verificationResult{Passed: true}},
+		{RunID: "run-b", EpicID: "api-1", Status: "success", Experiment: "exp-0", Variant: "terse", CompletedAt: done},
+	} {
+		if err := appendLedgerEntry(sessionLog, entry); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	rec := fillExperimentRecord(experimentRecord{ExperimentID: "exp-1", Variant: "terse", EpicID: "api-1"}, sessionLog, nil)
+	if rec.RunID != "run-a" || rec.Status != "success" || rec.TokensUsed != 1200 || rec.Commits != 1 || rec.Verified == nil || !*rec.Verified {
+		t.Fatalf("record = %+v", rec)
+	}
+	rec = fillExperimentRecord(experimentRecord{ExperimentID: "exp-1", Variant: "verbose", EpicID: "api-1"}, sessionLog, os.ErrPermission)
+	if rec.Status != experimentStatusError || rec.Error != os.ErrPermission.Error() {
+		t.Fatalf("record without a session = %+v", rec)
+	}
+}
+
+func TestFormatExperimentReportRanksVariants(t *testing.T) {
+	records := []experimentRecord{
+		{ExperimentID: "exp-1", Mode: experimentModeLive, BeadID: "api-1.3", Variant: "baseline", Status: "success", DurationMs: 600_000, TokensUsed: 50000, RunID: "run-a", Worktree: "/wt/baseline", Branch: "obi/experiment-exp-1-baseline"},
+		{ExperimentID: "exp-1", Mode: experimentModeLive, BeadID: "api-1.3", Variant: "terse", Status: "success", DurationMs: 450_000, TokensUsed: 40000, RunID: "run-b", Worktree: "/wt/terse", Branch: "obi/experiment-exp-1-terse"},
+		{ExperimentID: "exp-1", Mode: experimentModeLive, BeadID: "api-1.3", Variant: "stuck", Status: "needs_help", DurationMs: 60_000, TokensUsed: 9000, RunID: "run-c"},
+		{ExperimentID: "exp-1", Mode: experimentModeLive, BeadID: "api-1.3", Variant: "broken", Status: experimentStatusError, Error: "git worktree add: exit status 128"},
+	}
+	var order []string
+	for _, rec := range rankExperiment(records) {
+		order = append(order, rec.Variant)
+	}
+	if got := strings.Join(order, ","); got != "terse,baseline,stuck,broken" {
+		t.Fatalf("ranking = %s", got)
+	}
+	out := formatExperimentReport(records)
+	for _, want := range []string{
+		"Experiment exp-1 (live) on bead api-1.3:\n",
+		"Best: terse\n",
+		"error: git worktree add: exit status 128",
+		"  terse: /wt/terse (branch obi/experiment-exp-1-terse)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("report missing %q:\n%s", want, out)
+		}
+	}
+	if out := formatExperimentReport(records[2:]); !strings.Contains(out, "No variant succeeded") {
+		t.Fatalf("report without a success:\n%s", out)
+	}
+}
+
+func TestExperimentLogRoundTripAndSelect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "experiments", "experiments.log")
+	for _, rec := range []experimentRecord{
+		{ExperimentID: "20260301-090000", Variant: "a", Status: "success"},
+		{ExperimentID: "20260301-090000", Variant: "b", Status: "needs_help"},
+		{ExperimentID: "20260302-100000", Variant: "a", Status: "success"},
+	} {
+		if err := appendExperimentRecord(path, rec); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	records, err := readExperimentRecords(path)
+	if err != nil || len(records) != 3 {
+		t.Fatalf("read = %+v, %v", records, err)
+	}
+	if latest, err := selectExperiment(records, ""); err != nil || len(latest) != 1 || latest[0].ExperimentID != "20260302-100000" {
+		t.Fatalf("latest = %+v, %v", latest, err)
+	}
+	if first, err := selectExperiment(records, "20260301"); err != nil || len(first) != 2 {
+		t.Fatalf("by prefix = %+v, %v", first, err)
+	}
+	if _, err := selectExperiment(records, "2026030"); err == nil || !strings.Contains(err.Error(), "matches both") {
+		t.Fatalf("expected an ambiguity error, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"syscall"
+)
+
+// failureTailBytes is how much of the end of Codex's output is searched for
+// a crash cause; earlier errors were usually handled during the session.
+// Only Codex's own error lines in it count (see codexErrorLines), so a 401
+// or "Killed" in a test log does not.
+const failureTailBytes = 8 * 1024
+
+// Failure kinds recorded in the ledger's failure_kind for non-zero exits.
+const (
+	failureAuth          = "auth"
+	failureRateLimit     = "rate_limit"
+	failureSandboxDenied = "sandbox_denied"
+	failureOOM           = "oom"
+	failureNetwork       = "network"
+	failureUnknown       = "unknown"
+)
+
+// failureRule maps a crash signature to its kind and what the operator
+// should do about it.
+type failureRule struct {
+	kind    string
+	pattern *regexp.Regexp
+	hint    string
+}
+
+// failureRules are checked in order; rate limits come before network
+// errors because providers often report both.
+var failureRules = []failureRule{
+	{
+		kind:    failureAuth,
+		pattern: regexp.MustCompile(`(?i)(\b401\b|unauthorized|invalid[_ ]api[_ ]key|incorrect api key|not logged in|authentication (failed|error|required)|(token|session) (has )?expired|codex login)`),
+		hint:    "Codex could not authenticate. Run `codex login` (or check OPENAI_API_KEY) and relaunch.",
+	},
+	{
+		kind:    failureRateLimit,
+		pattern: regexp.MustCompile(`(?i)(\b429\b|rate[_ ]?limit|too many requests|insufficient_quota|quota exceeded|usage limit)`),
+		hint:    "The provider rate-limited the run or the quota is used up. Wait and rerun, or list other models in codex.fallback_models.",
+	},
+	{
+		kind:    failureSandboxDenied,
+		pattern: regexp.MustCompile(`(?i)(sandbox[^\n]{0,60}(denied|blocked|violation|not permitted)|operation not permitted|seatbelt|landlock)`),
+		hint:    "The sandbox blocked something Codex needed. Check codex.sandbox and [escalation], or grant the access, then rerun.",
+	},
+	{
+		kind:    failureOOM,
+		pattern: regexp.MustCompile(`(?i)(out of memory|oom[- ]?kill|cannot allocate memory|\bkilled\b)`),
+		hint:    "Codex, or a command it ran, ran out of memory and was killed. Free memory or narrow the task, then rerun.",
+	},
+	{
+		kind:    failureNetwork,
+		pattern: regexp.MustCompile(`(?i)(connection (refused|reset|closed|timed out)|network (error|is unreachable)|could not resolve host|no such host|tls handshake|stream disconnected|econnreset|etimedout|eai_again|error sending request)`),
+		hint:    "Codex lost its connection to the provider. Check the network or proxy settings and rerun.",
+	},
+}
+
+// classifyCodexFailure names the likely cause of a non-zero Codex exit from
+// the error lines at the tail of its output, or failureUnknown. A SIGKILL,
+// whether Codex died of it (signal) or a wrapper reported it as exit code
+// 137, counts as an out-of-memory kill when nothing else matches.
+func classifyCodexFailure(exitCode int, signal syscall.Signal, output string) string {
+	if len(output) > failureTailBytes {
+		output = output[len(output)-failureTailBytes:]
+	}
+	errorLines := codexErrorLines(output)
+	for _, rule := range failureRules {
+		if rule.pattern.MatchString(errorLines) {
+			return rule.kind
+		}
+	}
+	if signal == syscall.SIGKILL || exitCode == 137 {
+		return failureOOM
+	}
+	return failureUnknown
+}
+
+// failureHint names kind and its remediation on one line, or returns ""
+// when kind has none.
+func failureHint(kind string) string {
+	for _, rule := range failureRules {
+		if rule.kind == kind {
+			return fmt.Sprintf("Likely cause (%s): %s", kind, rule.hint)
+		}
+	}
+	return ""
+}
+
+// codexExitMessage explains a non-zero Codex exit, with the remediation for
+// its failure kind when one is known.
+func codexExitMessage(exitCode int, kind string) string {
+	msg := fmt.Sprintf("codex exited with status %d", exitCode)
+	if hint := failureHint(kind); hint != "" {
+		msg += "\n" + hint
+	}
+	return msg
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestClassifyCodexFailure(t *testing.T) {
+	cases := []struct {
+		exit   int
+		signal syscall.Signal
+		output string
+		want   string
+	}{
+		{1, 0, "Error: unexpected status 401 Unauthorized: invalid_api_key", failureAuth},
+		{1, 0, "ERROR: You are not logged in. Run `codex login` first.", failureAuth},
+		{1, 0, "stream error: 429 Too Many Requests; retrying... giving up", failureRateLimit},
+		{1, 0, "ERROR: insufficient_quota: You exceeded your current quota", failureRateLimit},
+		{1, 0, "ERROR: sandbox denied write to /etc/hosts", failureSandboxDenied},
+		{1, 0, "[2026-03-01T09:00:00] ERROR exec failed: Operation not permitted (os error 1)", failureSandboxDenied},
+		{1, 0, "ERROR: out of memory", failureOOM},
+		{137, 0, "running go test ./...", failureOOM},
+		{-1, syscall.SIGKILL, "running go test ./...", failureOOM},
+		{1, 0, "ERROR: error sending request for url (https://api.openai.com/v1/responses): connection reset by peer", failureNetwork},
+		{1, 0, "stream error: stream disconnected before completion", failureNetwork},
+		{2, 0, "panic: index out of range", failureUnknown},
+		// Output of what Codex ran is not Codex failing.
+		{1, 0, "--- FAIL: TestUpload\n    upload_test.go:12: got 401 Unauthorized\nKilled", failureUnknown},
+		{1, 0, "FATAL ERROR: Reached heap limit Allocation failed - JavaScript heap out of memory", failureUnknown},
+	}
+	for _, tc := range cases {
+		if got := classifyCodexFailure(tc.exit, tc.signal, tc.output); got != tc.want {
+			t.Errorf("classifyCodexFailure(%d, %v, %q) = %q, want %q", tc.exit, tc.signal, tc.output, got, tc.want)
+		}
+	}
+}
+
+func TestClassifyCodexFailureOnlyReadsTheTail(t *testing.T) {
+	// A permission error Codex recovered from early on is not the crash.
+	output := "ERROR: Operation not permitted\n" + strings.Repeat("x", failureTailBytes) + "\nERROR: connection refused"
+	if got := classifyCodexFailure(1, 0, output); got != failureNetwork {
+		t.Fatalf("kind = %q, want %q", got, failureNetwork)
+	}
+}
+
+func TestCodexExitMessageAddsHint(t *testing.T) {
+	if got := codexExitMessage(3, failureUnknown); got != "codex exited with status 3" {
+		t.Fatalf("unknown message = %q", got)
+	}
+	got := codexExitMessage(1, failureAuth)
+	if !strings.HasPrefix(got, "codex exited with status 1\nLikely cause (auth): ") || !strings.Contains(got, "codex login") {
+		t.Fatalf("auth message = %q", got)
+	}
+}
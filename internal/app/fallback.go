@@ -0,0 +1,90 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// defaultModelLabel stands in for an unset codex.model in fallback notices
+// and the ledger's fallback_from list.
+const defaultModelLabel = "(default)"
+
+// modelUnavailablePattern matches the provider errors Codex prints when the
+// requested model does not exist, is not enabled for the account, or is
+// over capacity. Anything else is a real failure and is not retried.
+var modelUnavailablePattern = regexp.MustCompile(`(?i)(model_not_found|model[^\n]{0,80}(does not exist|not found|not available|unavailable|not supported)|at capacity|over capacity|server_overloaded|overloaded_error|service unavailable|\b503\b)`)
+
+// codexErrorLinePattern matches the lines Codex itself prints when a request
+// or the run fails ("ERROR: …", "[2025-03-01T09:00:00] ERROR …", "Error: …",
+// "stream error: …"). Output of the commands and tests Codex ran does not
+// start this way, so a "503" in a test log is not a provider error.
+var codexErrorLinePattern = regexp.MustCompile(`^(?:\[[^\]]*\]\s*)?(?:ERROR\b|Error:|stream error\b)`)
+
+// codexErrorLines returns the lines of output Codex printed about its own
+// failures, escape sequences removed, joined by newlines.
+func codexErrorLines(output string) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(transcriptEscapePattern.ReplaceAllString(line, ""))
+		if codexErrorLinePattern.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func modelUnavailable(output string) bool {
+	return modelUnavailablePattern.MatchString(codexErrorLines(output))
+}
+
+// nextFallbackModel returns the first entry of codex.fallback_models that is
+// neither the current model nor one already tried.
+func nextFallbackModel(codex config.CodexConfig, tried []string) (string, bool) {
+	seen := map[string]bool{modelLabel(codex.Model): true}
+	for _, model := range tried {
+		seen[model] = true
+	}
+	for _, candidate := range codex.FallbackModels {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" || seen[candidate] {
+			continue
+		}
+		return candidate, true
+	}
+	return "", false
+}
+
+func modelLabel(model string) string {
+	if strings.TrimSpace(model) == "" {
+		return defaultModelLabel
+	}
+	return model
+}
+
+// modelFallback aborts a session attempt whose model was unavailable so
+// executeSession can relaunch it with the next model in the chain.
+type modelFallback struct {
+	model string
+	next  string
+}
+
+func (e *modelFallback) Error() string {
+	return fmt.Sprintf("model %s unavailable; relaunching with %s", e.model, e.next)
+}
+
+// fallbackFor reports whether a finished attempt should be relaunched: Codex
+// exited non-zero on its own without a fenced report, one of its error lines
+// says the model is unavailable, and the chain still has an untried model.
+func fallbackFor(plan sessionPlan, exitCode int, aborted bool, output string, reportErr error) *modelFallback {
+	if reportErr == nil || exitCode == 0 || aborted || !modelUnavailable(output) {
+		return nil
+	}
+	next, ok := nextFallbackModel(plan.Codex, plan.FallbackFrom)
+	if !ok {
+		return nil
+	}
+	return &modelFallback{model: modelLabel(plan.Codex.Model), next: next}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestModelUnavailable(t *testing.T) {
+	cases := map[string]bool{
+		"ERROR: model_not_found: The model `gpt-x` does not exist":               true,
+		"stream error: The model gpt-5-codex is not available for your account":  true,
+		"[2026-03-01T09:00:00] ERROR: We're currently at capacity, please retry": true,
+		"\x1b[31mERROR\x1b[0m: unexpected status 503 Service Unavailable":        true,
+		"ERROR: sandbox denied write to /etc/hosts":                              false,
+		"tests failed: 3 of 40":                        false,
+		"error: model_not_found (from a test fixture)": false,
+		"--- FAIL: TestUpload\n    upload_test.go:40: got 503 Service Unavailable, at capacity": false,
+	}
+	for output, want := range cases {
+		if got := modelUnavailable(output); got != want {
+			t.Errorf("modelUnavailable(%q) = %v, want %v", output, got, want)
+		}
+	}
+}
+
+func TestNextFallbackModelSkipsCurrentAndTried(t *testing.T) {
+	codex := config.CodexConfig{
+		Model:          "gpt-5-codex-high",
+		FallbackModels: []string{"gpt-5-codex-high", "gpt-5-codex-medium", " ", "gpt-5-mini"},
+	}
+	next, ok := nextFallbackModel(codex, nil)
+	if !ok || next != "gpt-5-codex-medium" {
+		t.Fatalf("expected gpt-5-codex-medium, got %q (%v)", next, ok)
+	}
+	codex.Model = next
+	next, ok = nextFallbackModel(codex, []string{"gpt-5-codex-high"})
+	if !ok || next != "gpt-5-mini" {
+		t.Fatalf("expected gpt-5-mini, got %q (%v)", next, ok)
+	}
+	codex.Model = next
+	if next, ok := nextFallbackModel(codex, []string{"gpt-5-codex-high", "gpt-5-codex-medium"}); ok {
+		t.Fatalf("expected chain to be exhausted, got %q", next)
+	}
+}
+
+func TestFallbackForRequiresMissingReport(t *testing.T) {
+	plan := sessionPlan{Codex: config.CodexConfig{FallbackModels: []string{"gpt-5-mini"}}}
+	output := "[2026-03-01T09:00:00] ERROR: model_not_found: the model gpt-9 does not exist"
+	missing := errors.New("fenced report incomplete")
+	if fallback := fallbackFor(plan, 1, false, output, nil); fallback != nil {
+		t.Fatalf("expected no fallback when the report parsed, got %v", fallback)
+	}
+	if fallback := fallbackFor(plan, 0, false, output, missing); fallback != nil {
+		t.Fatalf("expected no fallback after a clean exit, got %v", fallback)
+	}
+	if fallback := fallbackFor(plan, 130, true, output, missing); fallback != nil {
+		t.Fatalf("expected no fallback after an abort, got %v", fallback)
+	}
+	if fallback := fallbackFor(plan, 1, false, "exec curl\n503 Service Unavailable\nat capacity\n", missing); fallback != nil {
+		t.Fatalf("expected tool output to be ignored, got %v", fallback)
+	}
+	fallback := fallbackFor(plan, 1, false, output, missing)
+	if fallback == nil {
+		t.Fatalf("expected fallback")
+	}
+	if fallback.model != defaultModelLabel || fallback.next != "gpt-5-mini" {
+		t.Fatalf("unexpected fallback %+v", fallback)
+	}
+	if fallback := fallbackFor(plan, 1, false, "ERROR: panic: boom", missing); fallback != nil {
+		t.Fatalf("expected no fallback for unrelated failures, got %v", fallback)
+	}
+}
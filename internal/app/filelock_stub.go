@@ -0,0 +1,11 @@
+//go:build !darwin && !linux
+
+package app
+
+import "os"
+
+// openLockedFile opens path, creating it. This platform has no flock, so
+// the file is not locked and concurrent writers are not kept apart.
+func openLockedFile(path string, wait bool) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+}
@@ -0,0 +1,38 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// openLockedFile opens path, creating it, and takes an exclusive flock on
+// it. With wait it blocks until the lock is free; otherwise it returns
+// errLockHeld. Closing the file releases the lock, as does the process
+// exiting, so a crashed holder never leaves a stale lock behind.
+func openLockedFile(path string, wait bool) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	how := syscall.LOCK_EX
+	if !wait {
+		how |= syscall.LOCK_NB
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), how)
+		if !errors.Is(err, syscall.EINTR) {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errLockHeld
+		}
+		return nil, err
+	}
+	return f, nil
+}
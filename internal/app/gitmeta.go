@@ -0,0 +1,84 @@
+package app
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+)
+
+// gitMetadata is the checkout state recorded with each run. Commits lists
+// the commits between HeadBefore and HeadAfter, newest first.
+type gitMetadata struct {
+	Branch     string   `json:"branch,omitempty"`
+	HeadBefore string   `json:"head_before,omitempty"`
+	HeadAfter  string   `json:"head_after,omitempty"`
+	Commits    []string `json:"commits,omitempty"`
+	// Dirty reports uncommitted changes left when the run ended.
+	Dirty bool `json:"dirty,omitempty"`
+	// BranchAfter is set when the run ended on a different branch than it
+	// started on; "HEAD" means a detached checkout.
+	BranchAfter string `json:"branch_after,omitempty"`
+}
+
+// gitRunDir is where a plan's git state is read: its working directory
+// when set, otherwise the repo root.
+func gitRunDir(plan sessionPlan) string {
+	if plan.Dir != "" {
+		return plan.Dir
+	}
+	return plan.RepoRoot
+}
+
+// captureGitBefore records the branch and HEAD before Codex starts. It is
+// nil outside a git checkout so the ledger simply omits the field.
+func captureGitBefore(dir string) *gitMetadata {
+	head, err := gitOutput(dir, "rev-parse", "HEAD")
+	if err != nil || head == "" {
+		return nil
+	}
+	meta := &gitMetadata{HeadBefore: head}
+	if branch, err := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+		meta.Branch = branch
+	}
+	return meta
+}
+
+// finish fills in HEAD, the new commits, the dirty flag, and any branch
+// switch after the run.
+func (m *gitMetadata) finish(dir string) *gitMetadata {
+	if m == nil {
+		return nil
+	}
+	if branch, err := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+		before := m.Branch
+		if before == "" {
+			before = "HEAD"
+		}
+		if branch != before {
+			m.BranchAfter = branch
+		}
+	}
+	if head, err := gitOutput(dir, "rev-parse", "HEAD"); err == nil {
+		m.HeadAfter = head
+	}
+	if m.HeadAfter != "" && m.HeadAfter != m.HeadBefore {
+		if out, err := gitOutput(dir, "rev-list", m.HeadBefore+".."+m.HeadAfter); err == nil && out != "" {
+			m.Commits = strings.Fields(out)
+		}
+	}
+	if out, err := gitOutput(dir, "status", "--porcelain"); err == nil {
+		m.Dirty = out != ""
+	}
+	return m
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
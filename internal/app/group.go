@@ -0,0 +1,135 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// groupPrefix marks an obi go target naming an epic group ("group:backend").
+const groupPrefix = "group:"
+
+func groupTarget(alias string) (string, bool) {
+	alias = strings.TrimSpace(alias)
+	if len(alias) < len(groupPrefix) || !strings.EqualFold(alias[:len(groupPrefix)], groupPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(alias[len(groupPrefix):]), true
+}
+
+// runGroupLoop runs the epic loop for every epic in the group, in key order,
+// skipping epics with no ready beads. Per-epic summaries are replaced by one
+// combined summary once every epic has run out of work.
+func runGroupLoop(group string, opts goOptions, cfg *config.Config, logPath, repoRoot, cfgDigest string) error {
+	keys := cfg.GroupEpics(group)
+	if len(keys) == 0 {
+		if names := cfg.GroupNames(); len(names) > 0 {
+			return fmt.Errorf("unknown epic group %q (available: %s)", group, strings.Join(names, ", "))
+		}
+		return fmt.Errorf("unknown epic group %q: no epic sets group = \"...\"", group)
+	}
+	if opts.wait {
+		return errors.New("--wait is not supported with group targets")
+	}
+
+	maybeSyncBeads(cfg, repoRoot)
+
+	var plans []sessionPlan
+	for i, key := range keys {
+		plan, err := prepareSession(cfg, key)
+		if err != nil {
+			return err
+		}
+		plan.RepoRoot = repoRoot
+		plan.ConfigDigest = cfgDigest
+		if err := applyRunContext(&plan, opts.dir, opts.env); err != nil {
+			return err
+		}
+		if err := loadContextFiles(&plan, opts.context); err != nil {
+			return err
+		}
+		plan.OperatorNote = strings.TrimSpace(opts.note)
+		if opts.resume {
+			if err := enableResume(&plan, logPath); err != nil {
+				return err
+			}
+		} else {
+			offerResume(&plan, logPath, opts, !opts.ci && cfg.ConfirmBeforeRunValue(), os.Stdin, os.Stdout)
+		}
+		if err := enforceBranchPolicy(cfg, plan); err != nil {
+			return err
+		}
+		plans = append(plans, plan)
+
+		fmt.Printf("=== Group %s: epic %d/%d, %s (%s) ===\n\n", group, i+1, len(keys), plan.EpicName, plan.EpicID)
+		hasWork, err := readyWorkAvailable(plan)
+		if err != nil {
+			return err
+		}
+		if !hasWork {
+			fmt.Printf("No ready beads for %s (%s); skipping.\n\n", plan.EpicName, plan.EpicID)
+			continue
+		}
+		finished, err := runEpicSessions(plan, opts, cfg, logPath)
+		if err != nil {
+			return err
+		}
+		if !finished {
+			fmt.Printf("Group %s stopped at %s; remaining epics were not run.\n", group, plan.EpicID)
+			return nil
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("All epics in group %s are out of ready beads.\n", group)
+	return maybeRunGroupSummarizer(group, plans, opts, cfg, logPath)
+}
+
+// maybeRunGroupSummarizer writes one omnibus summary covering the completed
+// beads of every epic in the group.
+func maybeRunGroupSummarizer(group string, plans []sessionPlan, opts goOptions, cfg *config.Config, logPath string) error {
+	summaryCfg := cfg.SummaryConfigValue()
+	if summaryCfg.MaxCommits <= 0 || strings.TrimSpace(summaryCfg.Prompt) == "" {
+		fmt.Println("Omnibus summarizer disabled via config; skipping.")
+		return nil
+	}
+
+	entries, total, err := loadGroupSummaryEntries(logPath, plans, summaryCfg.MaxCommits)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No completed beads found in the ledger; skipping omnibus summary.")
+		return nil
+	}
+
+	plan := plans[0]
+	plan.EpicKey = ""
+	plan.Alias = groupPrefix + group
+	plan.EpicID = groupPrefix + group
+	plan.EpicName = fmt.Sprintf("Group %s", group)
+	return runSummarySession(plan, opts, cfg, logPath, entries, total)
+}
+
+func loadGroupSummaryEntries(logPath string, plans []sessionPlan, maxCommits int) ([]summaryEntry, int, error) {
+	var entries []summaryEntry
+	for _, plan := range plans {
+		epicEntries, _, err := loadSummaryEntries(logPath, plan.EpicID, 0)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, epicEntries...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CompletedAt.Before(entries[j].CompletedAt)
+	})
+	total := len(entries)
+	if maxCommits > 0 && total > maxCommits {
+		entries = entries[total-maxCommits:]
+	}
+	return entries, total, nil
+}
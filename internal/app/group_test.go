@@ -0,0 +1,65 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestGroupTarget(t *testing.T) {
+	cases := map[string]struct {
+		group string
+		ok    bool
+	}{
+		"group:backend": {"backend", true},
+		"GROUP:api ":    {"api", true},
+		"backend":       {"", false},
+		"group":         {"", false},
+	}
+	for input, want := range cases {
+		group, ok := groupTarget(input)
+		if group != want.group || ok != want.ok {
+			t.Fatalf("groupTarget(%q) = %q, %v; want %q, %v", input, group, ok, want.group, want.ok)
+		}
+	}
+}
+
+func TestRunGroupLoopRejectsUnknownGroup(t *testing.T) {
+	cfg := &config.Config{Epics: map[string]config.EpicConfig{
+		"api": {ID: "obi-api", Group: "backend"},
+	}}
+	err := runGroupLoop("frontend", goOptions{}, cfg, "", "", "")
+	if err == nil || err.Error() != `unknown epic group "frontend" (available: backend)` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadGroupSummaryEntriesMergesEpicsByTime(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "results.log")
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries := []ledgerEntry{
+		{EpicID: "obi-api", BeadID: "obi-api.1", Status: "success", CommitSummary: "api one", CompletedAt: base},
+		{EpicID: "obi-db", BeadID: "obi-db.1", Status: "success", CommitSummary: "db one", CompletedAt: base.Add(time.Hour)},
+		{EpicID: "obi-api", BeadID: "obi-api.2", Status: "success", CommitSummary: "api two", CompletedAt: base.Add(2 * time.Hour)},
+		{EpicID: "obi-ui", BeadID: "obi-ui.1", Status: "success", CommitSummary: "ui one", CompletedAt: base.Add(3 * time.Hour)},
+	}
+	for _, entry := range entries {
+		if err := appendLedgerEntry(logPath, entry); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	plans := []sessionPlan{{EpicID: "obi-api"}, {EpicID: "obi-db"}}
+	got, total, err := loadGroupSummaryEntries(logPath, plans, 2)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if total != 3 || len(got) != 2 {
+		t.Fatalf("expected newest 2 of 3 entries, got %d of %d", len(got), total)
+	}
+	if got[0].BeadID != "obi-db.1" || got[1].BeadID != "obi-api.2" {
+		t.Fatalf("entries not merged chronologically: %+v", got)
+	}
+}
@@ -3,7 +3,17 @@ package app
 import (
 	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"time"
+)
+
+const (
+	defaultReadyWaitInterval = 30 * time.Second
+	defaultReadyWaitTimeout  = 2 * time.Hour
+	// readyWaitMaxInterval caps the poll backoff so new work is noticed
+	// within a few minutes even after a long wait.
+	readyWaitMaxInterval = 5 * time.Minute
 )
 
 func ensureReadyWork(plan sessionPlan) error {
@@ -38,25 +48,29 @@ func hasReadyIssueForPlan(plan sessionPlan, readyIssues []readyIssue) (bool, err
 	}
 
 	skip := plan.resumeSkipSet()
-	var skippedMatches int
+	// Beads skipped at a checkpoint are the operator's choice, so running
+	// out of beads because of them is no resume problem.
+	skipped := beadSet(plan.SkippedBeads)
+	var completedMatches int
 
 	for _, issue := range readyIssues {
 		if strings.EqualFold(issue.IssueType, "epic") {
 			continue
 		}
 		if issueBelongsToEpic(issue.ID, plan.EpicID) {
-			if skip != nil {
-				if _, ok := skip[strings.ToLower(issue.ID)]; ok {
-					skippedMatches++
-					continue
+			id := strings.ToLower(issue.ID)
+			if _, ok := skip[id]; ok {
+				if _, ok := skipped[id]; !ok {
+					completedMatches++
 				}
+				continue
 			}
 			return true, nil
 		}
 	}
 
-	if plan.ResumeEnabled && skip != nil && skippedMatches > 0 {
-		return false, fmt.Errorf("resume requested but every ready bead for %s is already logged as completed; create new beads or rerun without --resume", plan.EpicID)
+	if plan.ResumeEnabled && completedMatches > 0 {
+		return false, fmt.Errorf("resume requested but every ready bead for %s is already logged as completed or was skipped; create new beads or rerun without --resume", plan.EpicID)
 	}
 
 	return false, nil
@@ -65,3 +79,64 @@ func hasReadyIssueForPlan(plan sessionPlan, readyIssues []readyIssue) (bool, err
 func missingReadyBeadsWarning(epicID string) string {
 	return fmt.Sprintf("no ready beads with prefix %s were returned by `bd ready --json -n %s`. Rename or recreate tasks as %s.<suffix> before rerunning.", epicID, readyFetchLimit, epicID)
 }
+
+// readyPoller waits for ready beads, doubling the poll interval after each
+// empty check up to readyWaitMaxInterval (or the starting interval, if larger).
+type readyPoller struct {
+	check    func() (bool, error)
+	sleep    func(time.Duration)
+	now      func() time.Time
+	interval time.Duration
+	// timeout bounds the total wait; zero waits indefinitely.
+	timeout time.Duration
+	out     io.Writer
+}
+
+func (p readyPoller) wait(epicID string) error {
+	hasWork, err := p.check()
+	if err != nil || hasWork {
+		return err
+	}
+	interval := p.interval
+	if interval <= 0 {
+		interval = defaultReadyWaitInterval
+	}
+	maxInterval := readyWaitMaxInterval
+	if interval > maxInterval {
+		maxInterval = interval
+	}
+	limit := "indefinitely"
+	if p.timeout > 0 {
+		limit = "up to " + p.timeout.String()
+	}
+	fmt.Fprintf(p.out, "No ready beads for %s yet; waiting %s for work to appear.\n", epicID, limit)
+
+	started := p.now()
+	for {
+		delay := interval
+		if p.timeout > 0 {
+			remaining := p.timeout - p.now().Sub(started)
+			if remaining <= 0 {
+				return fmt.Errorf("no ready beads for %s appeared within %s", epicID, p.timeout)
+			}
+			if delay > remaining {
+				delay = remaining
+			}
+		}
+		fmt.Fprintf(p.out, "  next check in %s\n", delay)
+		p.sleep(delay)
+
+		hasWork, err := p.check()
+		if err != nil {
+			return err
+		}
+		if hasWork {
+			fmt.Fprintf(p.out, "Ready beads found for %s after %s.\n", epicID, p.now().Sub(started).Round(time.Second))
+			return nil
+		}
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
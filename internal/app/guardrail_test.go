@@ -1,8 +1,12 @@
 package app
 
 import (
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestHasReadyIssueForPlanMatchesEpic(t *testing.T) {
@@ -79,3 +83,88 @@ func TestParentEpicIDHandlesMissingSuffix(t *testing.T) {
 		t.Fatalf("expected empty parent for single-level id, got %s", got)
 	}
 }
+
+func TestSyncBeadsRunsInDirAndReportsFailures(t *testing.T) {
+	dir := t.TempDir()
+	if err := syncBeads("touch synced", dir); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "synced")); err != nil {
+		t.Fatalf("expected command to run in %s: %v", dir, err)
+	}
+
+	err := syncBeads("echo 'remote unreachable' >&2; exit 3", dir)
+	if err == nil || !strings.Contains(err.Error(), "remote unreachable") {
+		t.Fatalf("expected failure with output detail, got %v", err)
+	}
+}
+
+func TestReadyPollerBacksOffUntilWorkAppears(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	checks := 0
+	var sleeps []time.Duration
+	var out strings.Builder
+	poller := readyPoller{
+		check: func() (bool, error) {
+			checks++
+			return checks == 5, nil
+		},
+		sleep: func(d time.Duration) {
+			sleeps = append(sleeps, d)
+			now = now.Add(d)
+		},
+		now:      func() time.Time { return now },
+		interval: 2 * time.Minute,
+		out:      &out,
+	}
+	if err := poller.wait("obi-a1"); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	want := []time.Duration{2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	if len(sleeps) != len(want) {
+		t.Fatalf("sleeps = %v, want %v", sleeps, want)
+	}
+	for i := range want {
+		if sleeps[i] != want[i] {
+			t.Fatalf("sleeps = %v, want %v", sleeps, want)
+		}
+	}
+	if !strings.Contains(out.String(), "waiting indefinitely") || !strings.Contains(out.String(), "after 16m0s") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestReadyPollerStopsAtTimeout(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	var sleeps []time.Duration
+	poller := readyPoller{
+		check: func() (bool, error) { return false, nil },
+		sleep: func(d time.Duration) {
+			sleeps = append(sleeps, d)
+			now = now.Add(d)
+		},
+		now:      func() time.Time { return now },
+		interval: 30 * time.Second,
+		timeout:  time.Minute,
+		out:      io.Discard,
+	}
+	err := poller.wait("obi-a1")
+	if err == nil || !strings.Contains(err.Error(), "within 1m0s") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if len(sleeps) != 2 || sleeps[1] != 30*time.Second {
+		t.Fatalf("expected final sleep clipped to remaining time, got %v", sleeps)
+	}
+}
+
+func TestReadyPollerReturnsImmediatelyWhenReady(t *testing.T) {
+	poller := readyPoller{
+		check: func() (bool, error) { return true, nil },
+		sleep: func(time.Duration) { t.Fatalf("should not sleep") },
+		now:   time.Now,
+		out:   io.Discard,
+	}
+	if err := poller.wait("obi-a1"); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+}
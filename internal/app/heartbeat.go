@@ -0,0 +1,179 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
+)
+
+const (
+	stateStart     = "start"
+	stateHeartbeat = "heartbeat"
+	stateEnd       = "end"
+)
+
+// stateRecord is one line of the live-session state file. A session writes
+// start, then a heartbeat every interval, then end; a session whose last
+// record is not end and whose heartbeats stopped is likely a hung or killed
+// obi process.
+type stateRecord struct {
+	Time       time.Time `json:"time"`
+	Kind       string    `json:"kind"`
+	SessionID  string    `json:"session_id"`
+	EpicID     string    `json:"epic_id,omitempty"`
+	Alias      string    `json:"alias,omitempty"`
+	PID        int       `json:"pid"`
+	Host       string    `json:"host,omitempty"`
+	IntervalMs int64     `json:"interval_ms,omitempty"`
+	ElapsedMs  int64     `json:"elapsed_ms"`
+	Bytes      int64     `json:"bytes_streamed"`
+	Phase      string    `json:"phase,omitempty"`
+	ExitCode   *int      `json:"exit_code,omitempty"`
+}
+
+// heartbeat appends start/heartbeat/end records for one running session.
+// Write failures are reported once on stderr and never stop the session.
+type heartbeat struct {
+	path     string
+	base     stateRecord
+	progress func() interactive.Progress
+	now      func() time.Time
+
+	mu       sync.Mutex
+	warned   bool
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// startHeartbeat records the session start and then a heartbeat every
+// interval until stop is called. interval <= 0 disables it entirely.
+func startHeartbeat(path string, interval time.Duration, plan sessionPlan, sessionID string, progress func() interactive.Progress) *heartbeat {
+	if path == "" || interval <= 0 {
+		return nil
+	}
+	host, _ := os.Hostname()
+	h := &heartbeat{
+		path: path,
+		base: stateRecord{
+			SessionID:  sessionID,
+			EpicID:     plan.EpicID,
+			Alias:      plan.Alias,
+			PID:        os.Getpid(),
+			Host:       host,
+			IntervalMs: interval.Milliseconds(),
+		},
+		progress: progress,
+		now:      time.Now,
+		done:     make(chan struct{}),
+	}
+	h.write(stateStart, nil)
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-h.done:
+				return
+			case <-ticker.C:
+				h.write(stateHeartbeat, nil)
+			}
+		}
+	}()
+	return h
+}
+
+// stop ends the heartbeat loop and records the end of the session. Only the
+// first call writes; later calls are no-ops.
+func (h *heartbeat) stop(exitCode *int) {
+	if h == nil {
+		return
+	}
+	h.stopOnce.Do(func() {
+		close(h.done)
+		h.wg.Wait()
+		h.write(stateEnd, exitCode)
+	})
+}
+
+func (h *heartbeat) write(kind string, exitCode *int) {
+	rec := h.base
+	rec.Time = h.now().UTC()
+	rec.Kind = kind
+	rec.ExitCode = exitCode
+	if h.progress != nil {
+		p := h.progress()
+		if !p.StartedAt.IsZero() {
+			rec.ElapsedMs = rec.Time.Sub(p.StartedAt).Milliseconds()
+		}
+		rec.Bytes = p.BytesStreamed
+		rec.Phase = string(p.Phase)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := appendStateRecord(h.path, rec); err != nil && !h.warned {
+		h.warned = true
+		diag.Logger().Warn("state file write failed; later failures are not reported", "path", h.path, "err", err)
+	}
+}
+
+// lockStateFile serializes the writers of the state file at path: session
+// heartbeats and obi clean, which replaces the file. Like lockLedger it
+// locks a file of its own. Close the returned file to release it.
+func lockStateFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("ensure state dir: %w", err)
+	}
+	lock, err := openLockedFile(path+".lock", true)
+	if err != nil {
+		return nil, fmt.Errorf("lock state file: %w", err)
+	}
+	return lock, nil
+}
+
+func appendStateRecord(path string, rec stateRecord) error {
+	lock, err := lockStateFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	return appendJSONLine(path, rec)
+}
+
+func readStateRecords(path string) ([]stateRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []stateRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), ledgerScanMaxBytes)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec stateRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			// A crash mid-write can leave a torn last line; skip it.
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan state file: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
+)
+
+func TestHeartbeatWritesStartBeatsAndEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.log")
+	started := time.Now()
+	progress := func() interactive.Progress {
+		return interactive.Progress{StartedAt: started, BytesStreamed: 2048, Phase: interactive.PhaseTesting}
+	}
+	beat := startHeartbeat(path, 10*time.Millisecond, sessionPlan{EpicID: "obi-x", Alias: "x"}, "sess-1", progress)
+	time.Sleep(35 * time.Millisecond)
+	code := 0
+	beat.stop(&code)
+	beat.stop(nil)
+
+	records, err := readStateRecords(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(records) < 3 {
+		t.Fatalf("expected start, heartbeats, end; got %d records", len(records))
+	}
+	if records[0].Kind != stateStart || records[len(records)-1].Kind != stateEnd {
+		t.Fatalf("unexpected record kinds: first %s, last %s", records[0].Kind, records[len(records)-1].Kind)
+	}
+	beatRec := records[1]
+	if beatRec.Kind != stateHeartbeat || beatRec.Bytes != 2048 || beatRec.Phase != "testing" || beatRec.SessionID != "sess-1" || beatRec.Alias != "x" {
+		t.Fatalf("unexpected heartbeat record: %+v", beatRec)
+	}
+	if end := records[len(records)-1]; end.ExitCode == nil || *end.ExitCode != 0 {
+		t.Fatalf("end record should carry the exit code: %+v", end)
+	}
+}
+
+func TestStartHeartbeatDisabled(t *testing.T) {
+	if beat := startHeartbeat(filepath.Join(t.TempDir(), "state.log"), 0, sessionPlan{}, "s", nil); beat != nil {
+		t.Fatalf("interval 0 should disable heartbeats")
+	}
+}
+
+func TestSummarizeSessionsClassifiesHealth(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	minute := time.Minute.Milliseconds()
+	exit := 0
+	records := []stateRecord{
+		{Time: now.Add(-20 * time.Minute), Kind: stateStart, SessionID: "done", PID: 10, Host: "here", IntervalMs: minute},
+		{Time: now.Add(-10 * time.Minute), Kind: stateEnd, SessionID: "done", PID: 10, Host: "here", IntervalMs: minute, ExitCode: &exit},
+		{Time: now.Add(-30 * time.Second), Kind: stateHeartbeat, SessionID: "live", PID: 11, Host: "here", IntervalMs: minute},
+		{Time: now.Add(-10 * time.Minute), Kind: stateHeartbeat, SessionID: "hung", PID: 12, Host: "there", IntervalMs: minute},
+		{Time: now.Add(-30 * time.Second), Kind: stateHeartbeat, SessionID: "gone", PID: 13, Host: "here", IntervalMs: minute},
+	}
+	alive := func(pid int) bool { return pid != 13 }
+
+	got := map[string]string{}
+	for _, st := range summarizeSessions(records, now, "here", alive) {
+		got[st.Last.SessionID] = st.Health
+	}
+	want := map[string]string{"done": "finished", "live": "live", "hung": "stale", "gone": "gone"}
+	for session, health := range want {
+		if got[session] != health {
+			t.Fatalf("session %s: got %q, want %q (all: %v)", session, got[session], health, got)
+		}
+	}
+	if n := len(unfinishedSessions(summarizeSessions(records, now, "here", alive))); n != 3 {
+		t.Fatalf("expected 3 unfinished sessions, got %d", n)
+	}
+}
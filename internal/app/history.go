@@ -0,0 +1,124 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// untypedCommit labels summaries with no Conventional Commits type.
+const untypedCommit = "untyped"
+
+func runHistory(args []string) error {
+	fs := newCommandFlags("history", "obi history [alias] [options]",
+		"List recorded runs newest first with their commit type, scope, and subject.\nWithout an alias, lists every epic.", "alias")
+	var configPath, kind, since string
+	var limit int
+	var asJSON bool
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.StringVar(&kind, "type", "", "only show runs whose commit type matches (e.g. fix, feat, or untyped)")
+	fs.StringVar(&since, "since", "", "only show runs completed within this window (e.g. 24h, 7d) or since a date (YYYY-MM-DD)")
+	fs.IntVar(&limit, "limit", 20, "show at most this many runs (0 for all)")
+	fs.BoolVar(&asJSON, "json", false, "print raw ledger entries as JSON lines")
+	positional, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+
+	var cutoff time.Time
+	if since != "" {
+		if cutoff, err = parseDigestSince(since, time.Now()); err != nil {
+			return err
+		}
+	}
+	_, cfg, err := loadConfig(configPath, "")
+	if err != nil {
+		return err
+	}
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+	entries, err := ledgerEntriesForEpic(logPath, reportEpicID(cfg, positionalArg(positional, 0)))
+	if err != nil {
+		if errors.Is(err, errLedgerNotFound) {
+			fmt.Printf("No runs recorded yet (%s).\n", logPath)
+			return nil
+		}
+		return err
+	}
+	entries = filterHistory(entries, kind, cutoff, limit)
+
+	if asJSON {
+		for _, entry := range entries {
+			line, err := json.Marshal(entry)
+			if err != nil {
+				return fmt.Errorf("encode ledger entry: %w", err)
+			}
+			fmt.Println(string(line))
+		}
+		return nil
+	}
+	fmt.Print(formatHistory(entries))
+	return nil
+}
+
+// filterHistory keeps entries of the given commit type completed at or
+// after cutoff, newest first, capped at limit (0 means no cap). Entries
+// logged before types were recorded are typed from their summary.
+func filterHistory(entries []ledgerEntry, kind string, cutoff time.Time, limit int) []ledgerEntry {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	var out []ledgerEntry
+	for _, entry := range entries {
+		if !cutoff.IsZero() && entry.CompletedAt.Before(cutoff) {
+			continue
+		}
+		if kind != "" && historyType(entry) != kind {
+			continue
+		}
+		out = append(out, entry)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CompletedAt.After(out[j].CompletedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+func historyType(entry ledgerEntry) string {
+	if kind := entryCommitType(entry); kind != "" {
+		return kind
+	}
+	return untypedCommit
+}
+
+func formatHistory(entries []ledgerEntry) string {
+	if len(entries) == 0 {
+		return "No matching runs.\n"
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		parts := parseCommitSummary(entry.CommitSummary)
+		kind := historyType(entry)
+		if parts.Scope != "" {
+			kind += "(" + parts.Scope + ")"
+		}
+		if parts.Breaking {
+			kind += "!"
+		}
+		bead := entry.BeadID
+		if bead == "" {
+			bead = "-"
+		}
+		subject := parts.Subject
+		if subject == "" {
+			subject = "(no commit summary)"
+		}
+		fmt.Fprintf(&b, "%s  %-10s  %-22s  %-16s  %s\n",
+			entry.CompletedAt.Local().Format("2006-01-02 15:04"), entry.Status, bead, kind, subject)
+	}
+	return b.String()
+}
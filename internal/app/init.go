@@ -2,15 +2,19 @@ package app
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/codexexec"
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
@@ -29,7 +33,11 @@ type bdEpic struct {
 }
 
 func runInit(args []string) error {
-	if len(args) > 0 {
+	fs := newCommandFlags("init", "obi init", "Scaffold obi.toml in the current directory (or refresh it if it already exists).")
+	if _, err := fs.parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
 		return fmt.Errorf("obi init takes no arguments")
 	}
 
@@ -44,7 +52,7 @@ func runInit(args []string) error {
 	} else if !os.IsNotExist(statErr) {
 		return statErr
 	}
-	_, err = refreshAtPath(path, false)
+	_, err = refreshAtPath(path, refreshOptions{})
 	if err != nil {
 		return err
 	}
@@ -84,6 +92,8 @@ func expandPath(path string) (string, error) {
 type refreshOptions struct {
 	configPath string
 	silent     bool
+	// seedPrompts starts each new epic's prompt from its bd description.
+	seedPrompts bool
 }
 
 func runRefresh(args []string) error {
@@ -97,27 +107,33 @@ func runRefresh(args []string) error {
 		return err
 	}
 
-	summary, err := refreshAtPath(path, opts.silent)
+	summary, err := refreshAtPath(path, opts)
 	if err != nil {
 		return err
 	}
 	if !opts.silent {
-		fmt.Printf("Done! %d epics → %s (kept %d, added %d, removed %d).\n",
-			summary.total, filepath.Base(path), summary.kept, summary.added, summary.removed)
+		fmt.Printf("Done! %d epics → %s (kept %d, added %d, restored %d, archived %d, ignored %d).\n",
+			summary.total, filepath.Base(path), summary.kept, summary.added, summary.restored, summary.archived, summary.ignored)
+		if summary.seeded > 0 {
+			fmt.Printf("Seeded %d new epic prompt(s) from bd descriptions; review them before running `obi go`.\n", summary.seeded)
+		}
+		if len(summary.undescribed) > 0 {
+			fmt.Printf("bd show failed for %s; their prompts start empty.\n", strings.Join(summary.undescribed, ", "))
+		}
 	}
 	return nil
 }
 
 func parseRefreshOptions(args []string) (refreshOptions, error) {
-	fs := flag.NewFlagSet("refresh", flag.ContinueOnError)
-	fs.SetOutput(io.Discard)
+	fs := newCommandFlags("refresh", "obi refresh [options]", "Sync obi.toml with open bead epics, archiving closed ones.")
 
 	var opts refreshOptions
 	fs.StringVar(&opts.configPath, "config", "", "path to obi.toml (defaults to nearest or ./obi.toml)")
 	fs.BoolVar(&opts.silent, "silent", false, "suppress summary output")
+	fs.BoolVar(&opts.seedPrompts, "seed-prompts", false, "start each new epic's prompt from its bd description")
 
-	if err := fs.Parse(args); err != nil {
-		return refreshOptions{}, fmt.Errorf("parse flags: %w", err)
+	if _, err := fs.parse(args); err != nil {
+		return refreshOptions{}, err
 	}
 	return opts, nil
 }
@@ -126,6 +142,9 @@ func determineRefreshPath(flagPath string) (string, error) {
 	if flagPath != "" {
 		return expandPath(flagPath)
 	}
+	if env := os.Getenv("OBI_CONFIG"); env != "" {
+		return expandPath(env)
+	}
 	path, found, err := findExistingConfigUpwards()
 	if err != nil {
 		return "", err
@@ -156,10 +175,19 @@ func findExistingConfigUpwards() (string, bool, error) {
 	return filepath.Join(wd, "obi.toml"), false, nil
 }
 
-func refreshAtPath(path string, silent bool) (refreshSummary, error) {
-	logger := refreshLogger{enabled: !silent}
+func refreshAtPath(path string, opts refreshOptions) (refreshSummary, error) {
+	logger := refreshLogger{enabled: !opts.silent}
 	logger.Printf("Scanning bead epics via `bd epic status --json`...\n")
+	// bd and the config file do not depend on each other; read both at once.
+	var existingCfg *config.Config
+	var cfgErr error
+	loaded := make(chan struct{})
+	go func() {
+		defer close(loaded)
+		existingCfg, cfgErr = loadConfigIfExists(path)
+	}()
 	epics, err := listEpics()
+	<-loaded
 	if err != nil {
 		return refreshSummary{}, err
 	}
@@ -169,9 +197,8 @@ func refreshAtPath(path string, silent bool) (refreshSummary, error) {
 	}
 	logger.Printf("Found %d open epics (from %d total).\n", len(active), len(epics))
 
-	existingCfg, err := loadConfigIfExists(path)
-	if err != nil {
-		return refreshSummary{}, err
+	if cfgErr != nil {
+		return refreshSummary{}, cfgErr
 	}
 	if existingCfg == nil {
 		logger.Printf("No existing obi config; a new file will be created at %s\n", path)
@@ -179,10 +206,22 @@ func refreshAtPath(path string, silent bool) (refreshSummary, error) {
 		logger.Printf("Loaded existing config at %s\n", path)
 	}
 
-	updatedCfg, summary, err := buildConfig(active, existingCfg, logger)
+	updatedCfg, summary, err := buildConfig(active, existingCfg, opts.seedPrompts, logger)
 	if err != nil {
 		return refreshSummary{}, err
 	}
+	fragmentDir, split, err := configFragmentDir(path)
+	if err != nil {
+		return refreshSummary{}, err
+	}
+	if split {
+		fragment := filepath.Join(fragmentDir, config.EpicsFragmentName)
+		logger.Printf("Writing epics to %s (other fragments are left untouched)...\n", fragment)
+		if err := writeEpicsFragment(fragment, updatedCfg); err != nil {
+			return refreshSummary{}, err
+		}
+		return summary, nil
+	}
 	logger.Printf("Writing config to %s...\n", filepath.Base(path))
 	if err := writeConfigFile(path, updatedCfg); err != nil {
 		return refreshSummary{}, err
@@ -221,10 +260,17 @@ If you aren't familiar with beads or beads is not installed, stop now and report
 There may be other Codex instances working elsewhere in the repo—ignore their activity unless it causes conflicts; if it does, stop immediately and report the situation.`
 
 type refreshSummary struct {
-	total   int
-	kept    int
-	added   int
-	removed int
+	total    int
+	kept     int
+	added    int
+	restored int
+	archived int
+	ignored  int
+	// seeded counts new epics whose prompt came from --seed-prompts.
+	seeded int
+	// undescribed lists epics whose bd show failed; they were named from
+	// their titles alone.
+	undescribed []string
 }
 
 type refreshLogger struct {
@@ -238,6 +284,83 @@ func (l refreshLogger) Printf(format string, args ...interface{}) {
 	fmt.Printf(format, args...)
 }
 
+// spin shows label with a spinner and the elapsed seconds until stop is
+// called. It draws nothing unless stdout is a terminal.
+func (l refreshLogger) spin(label string) (stop func()) {
+	if !l.enabled || !stdoutIsTerminal() {
+		return func() {}
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		frames := []string{"|", "/", "-", "\\"}
+		start := time.Now()
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			fmt.Printf("\r%s %s (%ds)", frames[i%len(frames)], label, int(time.Since(start).Seconds()))
+			select {
+			case <-done:
+				fmt.Print("\r\x1b[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// refreshProgress counts finished steps, redrawing "label n/total" in
+// place on a terminal and printing only notes elsewhere.
+type refreshProgress struct {
+	logger   refreshLogger
+	label    string
+	total    int
+	finished int
+	live     bool
+}
+
+func (l refreshLogger) progress(label string, total int) *refreshProgress {
+	p := &refreshProgress{logger: l, label: label, total: total, live: l.enabled && stdoutIsTerminal()}
+	p.draw()
+	return p
+}
+
+func (p *refreshProgress) draw() {
+	if p.live {
+		fmt.Printf("\r\x1b[K%s %d/%d", p.label, p.finished, p.total)
+	}
+}
+
+func (p *refreshProgress) step() {
+	p.finished++
+	p.draw()
+}
+
+// note prints a line above the progress counter.
+func (p *refreshProgress) note(line string) {
+	if p.live {
+		fmt.Print("\r\x1b[K")
+	}
+	p.logger.Printf("%s\n", line)
+	p.draw()
+}
+
+func (p *refreshProgress) done() {
+	if p.live {
+		fmt.Println()
+	}
+}
+
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 func filterActiveEpics(epics []bdEpic) []bdEpic {
 	var active []bdEpic
 	for _, e := range epics {
@@ -256,6 +379,13 @@ func loadConfigIfExists(path string) (*config.Config, error) {
 		}
 		return nil, err
 	}
+	sources, err := config.Sources(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, nil
+	}
 	cfg, err := config.Load(path)
 	if err != nil {
 		return nil, err
@@ -263,7 +393,7 @@ func loadConfigIfExists(path string) (*config.Config, error) {
 	return cfg, nil
 }
 
-func buildConfig(epics []bdEpic, existing *config.Config, logger refreshLogger) (*config.Config, refreshSummary, error) {
+func buildConfig(epics []bdEpic, existing *config.Config, seedPrompts bool, logger refreshLogger) (*config.Config, refreshSummary, error) {
 	newCfg := &config.Config{
 		ResultsLog:       "./obi-results.log",
 		BasePrompt:       defaultBasePrompt,
@@ -289,6 +419,50 @@ func buildConfig(epics []bdEpic, existing *config.Config, logger refreshLogger)
 			newCfg.Issues = &copy
 		}
 		newCfg.Summary = existing.Summary
+		newCfg.TUI = existing.TUI
+		newCfg.Redaction = existing.Redaction
+		newCfg.Notify = existing.Notify
+		newCfg.Escalation = existing.Escalation
+		newCfg.Style = existing.Style
+		newCfg.Webhooks = existing.Webhooks
+		newCfg.Prompt = existing.Prompt
+		newCfg.RecordEnvironment = existing.RecordEnvironment
+		newCfg.Guardrails = existing.Guardrails
+		newCfg.Schedule = existing.Schedule
+		newCfg.Update = existing.Update
+		newCfg.Beads = existing.Beads
+		newCfg.AuditLog = existing.AuditLog
+		newCfg.TranscriptMaxMB = existing.TranscriptMaxMB
+		newCfg.TranscriptNameTemplate = existing.TranscriptNameTemplate
+		newCfg.Transcript = existing.Transcript
+		newCfg.Artifacts = existing.Artifacts
+		newCfg.StateFile = existing.StateFile
+		newCfg.HeartbeatMinutes = existing.HeartbeatMinutes
+		if len(existing.Profiles) > 0 {
+			newCfg.Profiles = map[string]config.ProfileConfig{}
+			for name, profile := range existing.Profiles {
+				newCfg.Profiles[name] = profile
+			}
+		}
+		if len(existing.Templates) > 0 {
+			newCfg.Templates = map[string]config.TemplateConfig{}
+			for name, tmpl := range existing.Templates {
+				newCfg.Templates[name] = tmpl
+			}
+		}
+		if len(existing.Phases) > 0 {
+			newCfg.Phases = map[string][]string{}
+			for name, patterns := range existing.Phases {
+				newCfg.Phases[name] = append([]string{}, patterns...)
+			}
+		}
+		newCfg.Archive.Ignore = append([]string(nil), existing.Archive.Ignore...)
+		if len(existing.Archive.Epics) > 0 {
+			newCfg.Archive.Epics = map[string]config.EpicConfig{}
+			for key, epic := range existing.Archive.Epics {
+				newCfg.Archive.Epics[key] = epic
+			}
+		}
 		if strings.TrimSpace(newCfg.Summary.Prompt) == "" {
 			newCfg.Summary.Prompt = config.DefaultSummaryPrompt
 		}
@@ -309,25 +483,62 @@ func buildConfig(epics []bdEpic, existing *config.Config, logger refreshLogger)
 		}
 	}
 
-	usedAliases := map[string]struct{}{}
-	for _, epic := range existingEpics {
-		if alias := strings.ToLower(strings.TrimSpace(epic.Alias)); alias != "" {
-			usedAliases[alias] = struct{}{}
+	summary := refreshSummary{}
+
+	var tracked []bdEpic
+	for _, e := range epics {
+		if newCfg.Archive.Ignores(e.Epic.ID) {
+			logger.Printf("Ignoring %s (listed in [archive] ignore).\n", e.Epic.ID)
+			summary.ignored++
+			continue
 		}
+		tracked = append(tracked, e)
 	}
+	epics = tracked
 
-	summary := refreshSummary{}
+	// Reopened epics come back from the archive with their customizations.
+	restored := map[string]struct{}{}
+	for _, e := range epics {
+		key := sanitizeKey(e.Epic.ID)
+		if _, ok := existingEpics[key]; ok {
+			continue
+		}
+		if archived, ok := newCfg.Archive.Epics[key]; ok {
+			existingEpics[key] = archived
+			delete(newCfg.Archive.Epics, key)
+			restored[key] = struct{}{}
+		}
+	}
+
+	usedAliases := map[string]struct{}{}
+	for _, group := range []map[string]config.EpicConfig{existingEpics, newCfg.Archive.Epics} {
+		for _, epic := range group {
+			if alias := strings.ToLower(strings.TrimSpace(epic.Alias)); alias != "" {
+				usedAliases[alias] = struct{}{}
+			}
+			for _, former := range epic.FormerAliases {
+				usedAliases[strings.ToLower(strings.TrimSpace(former))] = struct{}{}
+			}
+		}
+	}
 
 	aliasRequests := map[string]aliasRequest{}
 	for _, e := range epics {
 		key := sanitizeKey(e.Epic.ID)
 		if epicCfg, ok := existingEpics[key]; ok {
 			if strings.TrimSpace(epicCfg.Alias) == "" {
-				aliasRequests[key] = aliasRequest{Key: key, Title: e.Epic.Title, Description: e.Epic.Description}
+				aliasRequests[key] = aliasRequest{Key: key, ID: e.Epic.ID, Title: e.Epic.Title, Description: e.Epic.Description}
 			}
 			continue
 		}
-		aliasRequests[key] = aliasRequest{Key: key, Title: e.Epic.Title, Description: e.Epic.Description}
+		aliasRequests[key] = aliasRequest{Key: key, ID: e.Epic.ID, Title: e.Epic.Title, Description: e.Epic.Description}
+	}
+
+	if seedPrompts {
+		for id := range describeEpics(aliasRequests, fetchBeadDetails, logger) {
+			summary.undescribed = append(summary.undescribed, id)
+		}
+		sort.Strings(summary.undescribed)
 	}
 
 	if len(aliasRequests) > 0 {
@@ -335,7 +546,7 @@ func buildConfig(epics []bdEpic, existing *config.Config, logger refreshLogger)
 	} else {
 		logger.Printf("All epics already have aliases; skipping Codex request.\n")
 	}
-	aliasSuggestions, err := generateAliasesBatch(newCfg.Codex, aliasRequests)
+	aliasSuggestions, err := generateAliasesBatch(newCfg.Codex, aliasRequests, logger)
 	if err != nil {
 		return nil, summary, err
 	}
@@ -350,27 +561,41 @@ func buildConfig(epics []bdEpic, existing *config.Config, logger refreshLogger)
 				epicCfg.Alias = alias
 			}
 			newCfg.Epics[key] = epicCfg
-			summary.kept++
+			if _, ok := restored[key]; ok {
+				summary.restored++
+			} else {
+				summary.kept++
+			}
 			continue
 		}
 
 		alias := finalizeAlias(key, e, aliasSuggestions, usedAliases)
 
+		var prompt string
+		if seedPrompts {
+			// aliasRequests holds the description bd show filled in.
+			if prompt = seedPrompt(aliasRequests[key].Description); prompt != "" {
+				summary.seeded++
+			}
+		}
 		newCfg.Epics[key] = config.EpicConfig{
 			Name:   e.Epic.Title,
 			ID:     e.Epic.ID,
-			Prompt: "",
+			Prompt: prompt,
 			Alias:  alias,
 		}
 		summary.added++
 	}
 
-	if existing != nil {
-		for key := range existingEpics {
-			if _, ok := newCfg.Epics[key]; !ok {
-				summary.removed++
-			}
+	for key, epic := range existingEpics {
+		if _, ok := newCfg.Epics[key]; ok {
+			continue
+		}
+		if newCfg.Archive.Epics == nil {
+			newCfg.Archive.Epics = map[string]config.EpicConfig{}
 		}
+		newCfg.Archive.Epics[key] = epic
+		summary.archived++
 	}
 
 	if len(newCfg.Epics) == 0 {
@@ -389,6 +614,7 @@ func fallbackString(val, def string) string {
 
 type aliasRequest struct {
 	Key         string
+	ID          string
 	Title       string
 	Description string
 }
@@ -409,49 +635,313 @@ func writeConfigFile(path string, cfg *config.Config) error {
 	sb.WriteString("# Each epic exposes a single alias used with `obi go <alias>`.\n\n")
 
 	sb.WriteString(fmt.Sprintf("results_log = %q\n", cfg.ResultsLog))
+	if cfg.AuditLog != "" {
+		sb.WriteString(fmt.Sprintf("audit_log = %q\n", cfg.AuditLog))
+	}
+	if cfg.TranscriptMaxMB > 0 {
+		sb.WriteString(fmt.Sprintf("transcript_max_mb = %d\n", cfg.TranscriptMaxMB))
+	}
+	if cfg.TranscriptNameTemplate != "" {
+		sb.WriteString(fmt.Sprintf("transcript_name_template = %q\n", cfg.TranscriptNameTemplate))
+	}
+	if cfg.StateFile != "" {
+		sb.WriteString(fmt.Sprintf("state_file = %q\n", cfg.StateFile))
+	}
+	if cfg.HeartbeatMinutes != nil {
+		sb.WriteString(fmt.Sprintf("heartbeat_minutes = %d\n", *cfg.HeartbeatMinutes))
+	}
+	if cfg.RecordEnvironment {
+		sb.WriteString("record_environment = true\n")
+	}
 	sb.WriteString(fmt.Sprintf("confirm_before_run = %t\n", cfg.ConfirmBeforeRunValue()))
-	sb.WriteString(fmt.Sprintf("base_prompt = \"\"\"%s\"\"\"\n\n", escapeTripleQuotes(cfg.BasePrompt)))
+	sb.WriteString(fmt.Sprintf("base_prompt = %s\n\n", promptValue(cfg.BasePrompt)))
 
 	if cfg.Issues != nil {
 		sb.WriteString("[\"issues outside epics\"]\n")
-		if strings.TrimSpace(cfg.Issues.Prompt) == "" {
-			sb.WriteString("prompt = \"\"\"\"\"\"\n\n")
-		} else {
-			sb.WriteString(fmt.Sprintf("prompt = \"\"\"%s\"\"\"\n\n", escapeTripleQuotes(cfg.Issues.Prompt)))
-		}
+		sb.WriteString(fmt.Sprintf("prompt = %s\n\n", promptValue(cfg.Issues.Prompt)))
 	} else {
 		sb.WriteString("# Add an \"issues outside epics\" section to control `obi go` default behavior.\n\n")
 	}
 
 	if codexProvided(cfg.Codex) {
 		sb.WriteString("[codex]\n")
-		writeNonEmpty := func(key, value string) {
-			if value != "" {
-				sb.WriteString(fmt.Sprintf("%s = %q\n", key, value))
-			}
-		}
-		writeNonEmpty("binary", cfg.Codex.Binary)
-		writeNonEmpty("model", cfg.Codex.Model)
-		writeNonEmpty("sandbox", cfg.Codex.Sandbox)
-		writeNonEmpty("approval", cfg.Codex.Approval)
-		if len(cfg.Codex.ExtraArgs) > 0 {
-			sb.WriteString(fmt.Sprintf("extra_args = [%s]\n", formatStringSlice(cfg.Codex.ExtraArgs)))
-		}
+		writeCodexFields(&sb, cfg.Codex)
 		sb.WriteString("\n")
 	} else {
 		sb.WriteString("# Uncomment to override Codex defaults for this repo (use GPT-5 class models only).\n")
 		sb.WriteString("# [codex]\n")
 		sb.WriteString("# model = \"gpt-5-codex-medium\"\n")
 		sb.WriteString("# sandbox = \"workspace-write\"\n")
-		sb.WriteString("# approval = \"on-request\"\n\n")
+		sb.WriteString("# approval = \"on-request\"\n")
+		sb.WriteString("# alias_timeout_seconds = 120  # then init/refresh fall back to local aliases; 0 waits forever\n\n")
 	}
 
 	summaryCfg := cfg.SummaryConfigValue()
 	sb.WriteString("[summary]\n")
-	sb.WriteString(fmt.Sprintf("prompt = \"\"\"%s\"\"\"\n", escapeTripleQuotes(summaryCfg.Prompt)))
+	sb.WriteString(fmt.Sprintf("prompt = %s\n", promptValue(summaryCfg.Prompt)))
 	sb.WriteString(fmt.Sprintf("max_commits = %d\n", summaryCfg.MaxCommits))
-	sb.WriteString(fmt.Sprintf("chunk_size = %d\n\n", summaryCfg.ChunkSize))
+	sb.WriteString(fmt.Sprintf("chunk_size = %d\n", summaryCfg.ChunkSize))
+	if summaryCfg.Review {
+		sb.WriteString("review = true\n")
+	}
+	sb.WriteString("\n")
+	if command := strings.TrimSpace(summaryCfg.PullRequest.Command); command != "" {
+		sb.WriteString("[summary.pull_request]\n")
+		sb.WriteString(fmt.Sprintf("command = %q\n\n", command))
+	} else {
+		sb.WriteString("# Uncomment to open a pull request with the omnibus summary once an epic finishes.\n")
+		sb.WriteString("# [summary.pull_request]\n")
+		sb.WriteString("# command = \"gh pr create --title {{.Title}} --body-file {{.BodyFile}}\"\n\n")
+	}
+
+	writeTUISection(&sb, cfg.TUI)
+
+	if tr := cfg.Transcript; len(tr.Include) > 0 || tr.DropANSI || tr.StripProgress {
+		sb.WriteString("[transcript]\n")
+		if len(tr.Include) > 0 {
+			sb.WriteString(fmt.Sprintf("include = [%s]\n", formatStringSlice(tr.Include)))
+		}
+		if tr.DropANSI {
+			sb.WriteString("drop_ansi = true\n")
+		}
+		if tr.StripProgress {
+			sb.WriteString("strip_progress = true\n")
+		}
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString("# Uncomment for compact transcripts: plain text, only the final version of redrawn lines.\n")
+		sb.WriteString("# [transcript]\n")
+		sb.WriteString("# include = [\"codex\", \"operator\"]\n")
+		sb.WriteString("# drop_ansi = true\n")
+		sb.WriteString("# strip_progress = true\n\n")
+	}
+
+	if art := cfg.Artifacts; len(art.Paths) > 0 || art.MaxMB > 0 {
+		sb.WriteString("[artifacts]\n")
+		if len(art.Paths) > 0 {
+			sb.WriteString(fmt.Sprintf("paths = [%s]\n", formatStringSlice(art.Paths)))
+		}
+		if art.MaxMB > 0 {
+			sb.WriteString(fmt.Sprintf("max_mb = %d\n", art.MaxMB))
+		}
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString("# Uncomment to keep copies of files each run leaves behind, listed in its ledger entry.\n")
+		sb.WriteString("# [artifacts]\n")
+		sb.WriteString("# paths = [\"coverage.out\", \"build/*.log\"]\n")
+		sb.WriteString("# max_mb = 100\n\n")
+	}
+
+	if cfg.Prompt.IncludeReadyList {
+		sb.WriteString("[prompt]\n")
+		sb.WriteString("include_ready_list = true\n\n")
+	} else {
+		sb.WriteString("# Uncomment to embed the epic's `bd ready --json` beads in each session prompt.\n")
+		sb.WriteString("# [prompt]\n")
+		sb.WriteString("# include_ready_list = true\n\n")
+	}
+
+	if guard := cfg.Guardrails; guard.RequireCleanTree || len(guard.AllowDirty) > 0 || len(guard.ProtectedBranches) > 0 || len(guard.AllowedPaths) > 0 || len(guard.ForbiddenPaths) > 0 || guard.AbortCleanup != "" {
+		sb.WriteString("[guardrails]\n")
+		if guard.RequireCleanTree {
+			sb.WriteString("require_clean_tree = true\n")
+		}
+		if len(guard.AllowDirty) > 0 {
+			sb.WriteString(fmt.Sprintf("allow_dirty = [%s]\n", formatStringSlice(guard.AllowDirty)))
+		}
+		if len(guard.ProtectedBranches) > 0 {
+			sb.WriteString(fmt.Sprintf("protected_branches = [%s]\n", formatStringSlice(guard.ProtectedBranches)))
+		}
+		if len(guard.AllowedPaths) > 0 {
+			sb.WriteString(fmt.Sprintf("allowed_paths = [%s]\n", formatStringSlice(guard.AllowedPaths)))
+		}
+		if len(guard.ForbiddenPaths) > 0 {
+			sb.WriteString(fmt.Sprintf("forbidden_paths = [%s]\n", formatStringSlice(guard.ForbiddenPaths)))
+		}
+		if guard.AbortCleanup != "" {
+			sb.WriteString(fmt.Sprintf("abort_cleanup = %q\n", guard.AbortCleanup))
+		}
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString("# Uncomment to refuse `obi go` while git shows uncommitted changes outside obi's own files,\n")
+		sb.WriteString("# or while a protected branch is checked out; soft-stop sessions that change files out of scope;\n")
+		sb.WriteString("# offer a cleanup command after an aborted session.\n")
+		sb.WriteString("# [guardrails]\n")
+		sb.WriteString("# require_clean_tree = true\n")
+		sb.WriteString("# allow_dirty = [\".beads/\"]\n")
+		sb.WriteString("# protected_branches = [\"main\", \"release/*\"]\n")
+		sb.WriteString("# allowed_paths = [\"services/api/\", \"docs/\"]\n")
+		sb.WriteString("# forbidden_paths = [\".github/\", \"*.lock\"]\n")
+		sb.WriteString("# abort_cleanup = \"git checkout -- {{.Paths}} && git clean -fd -- {{.Paths}}\"\n\n")
+	}
+
+	if sched := cfg.Schedule; sched.AllowedHours != "" || len(sched.BlackoutDates) > 0 || len(sched.Jobs) > 0 {
+		sb.WriteString("[schedule]\n")
+		if sched.AllowedHours != "" {
+			sb.WriteString(fmt.Sprintf("allowed_hours = %q\n", sched.AllowedHours))
+		}
+		if len(sched.BlackoutDates) > 0 {
+			sb.WriteString(fmt.Sprintf("blackout_dates = [%s]\n", formatStringSlice(sched.BlackoutDates)))
+		}
+		for _, job := range sched.Jobs {
+			sb.WriteString("\n[[schedule.jobs]]\n")
+			if job.Name != "" {
+				sb.WriteString(fmt.Sprintf("name = %q\n", job.Name))
+			}
+			sb.WriteString(fmt.Sprintf("cron = %q\n", job.Cron))
+			if job.Alias != "" {
+				sb.WriteString(fmt.Sprintf("alias = %q\n", job.Alias))
+			}
+			if job.Template != "" {
+				sb.WriteString(fmt.Sprintf("template = %q\n", job.Template))
+			}
+		}
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString("# Uncomment to keep `obi go` inside approved hours and out of release freezes (local time),\n")
+		sb.WriteString("# and to let `obi schedule` run an alias or template on a cron expression.\n")
+		sb.WriteString("# [schedule]\n")
+		sb.WriteString("# allowed_hours = \"08:00-19:00\"\n")
+		sb.WriteString("# blackout_dates = [\"2026-12-20..2027-01-02\"]\n")
+		sb.WriteString("# [[schedule.jobs]]\n")
+		sb.WriteString("# cron = \"0 3 * * 1\"           # Mondays at 03:00\n")
+		sb.WriteString("# template = \"deps-update\"\n\n")
+	}
+
+	if cfg.Redaction.Live {
+		sb.WriteString("[redaction]\n")
+		sb.WriteString("live = true\n\n")
+	} else {
+		sb.WriteString("# Uncomment to scrub OBI_REDACT secrets from the live TUI stream too (e.g., when screen-sharing).\n")
+		sb.WriteString("# [redaction]\n")
+		sb.WriteString("# live = true\n\n")
+	}
 
+	if cfg.Beads.Sync || cfg.Beads.SyncCommand != "" {
+		sb.WriteString("[beads]\n")
+		if cfg.Beads.Sync {
+			sb.WriteString("sync = true\n")
+		}
+		if cfg.Beads.SyncCommand != "" {
+			sb.WriteString(fmt.Sprintf("sync_command = %q\n", cfg.Beads.SyncCommand))
+		}
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString("# Uncomment to refresh remote-backed bead data before every ready check.\n")
+		sb.WriteString("# [beads]\n")
+		sb.WriteString("# sync = true               # runs `bd sync`; failures only warn\n")
+		sb.WriteString("# sync_command = \"bd sync --pull\"\n\n")
+	}
+
+	if webhook := strings.TrimSpace(cfg.Notify.Webhook); webhook != "" {
+		sb.WriteString("[notify]\n")
+		sb.WriteString(fmt.Sprintf("webhook = %q\n\n", webhook))
+	} else {
+		sb.WriteString("# Uncomment to let `obi report digest --post` send the digest to a chat webhook.\n")
+		sb.WriteString("# [notify]\n")
+		sb.WriteString("# webhook = \"https://hooks.slack.com/services/...\"\n\n")
+	}
+
+	if releaseURL := strings.TrimSpace(cfg.Update.ReleaseURL); releaseURL != "" {
+		sb.WriteString("[update]\n")
+		sb.WriteString(fmt.Sprintf("release_url = %q\n\n", releaseURL))
+	} else {
+		sb.WriteString("# Uncomment to let `obi --version --verbose` check for a newer release.\n")
+		sb.WriteString("# [update]\n")
+		sb.WriteString("# release_url = \"https://api.github.com/repos/<owner>/<repo>/releases/latest\"\n\n")
+	}
+
+	if style := cfg.Style; style != (config.StyleConfig{}) {
+		sb.WriteString("[style]\n")
+		if style.CommitLanguage != "" {
+			sb.WriteString(fmt.Sprintf("commit_language = %q\n", style.CommitLanguage))
+		}
+		if style.CommitConvention != "" {
+			sb.WriteString(fmt.Sprintf("commit_convention = %q\n", style.CommitConvention))
+		}
+		if style.MaxSubjectLength > 0 {
+			sb.WriteString(fmt.Sprintf("max_subject_length = %d\n", style.MaxSubjectLength))
+		}
+		if style.Enforce != "" {
+			sb.WriteString(fmt.Sprintf("enforce = %q\n", style.Enforce))
+		}
+		sb.WriteString("\n")
+	}
+
+	if esc := cfg.Escalation; esc.Action != "" || len(esc.AutoApprove) > 0 || esc.RequestPattern != "" {
+		sb.WriteString("[escalation]\n")
+		writeEscalationFields(&sb, esc)
+		sb.WriteString("\n")
+	}
+
+	writeWebhooksSection(&sb, cfg.Webhooks)
+
+	writePhasesSection(&sb, cfg.Phases)
+	writeProfilesSection(&sb, cfg.Profiles)
+	writeTemplatesSection(&sb, cfg.Templates)
+	writeEpicSections(&sb, cfg)
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// configFragmentDir reports whether the config at path is split into
+// fragments, in which case refresh only owns the epics fragment. A missing
+// path is a fresh single-file config.
+func configFragmentDir(path string) (string, bool, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", false, nil
+	}
+	return config.FragmentDir(path)
+}
+
+// saveEpicConfig writes cfg back after an epic edit the way refresh does:
+// only the epics fragment of a split config, otherwise the whole file. It
+// returns the file written.
+func saveEpicConfig(path string, cfg *config.Config) (string, error) {
+	target, split, err := epicConfigTarget(path)
+	if err != nil {
+		return "", err
+	}
+	if split {
+		return target, writeEpicsFragment(target, cfg)
+	}
+	return target, writeConfigFile(target, cfg)
+}
+
+// epicConfigTarget names the file saveEpicConfig writes for the config at
+// path: the epics fragment when split is true, otherwise path itself.
+func epicConfigTarget(path string) (string, bool, error) {
+	fragmentDir, split, err := configFragmentDir(path)
+	if err != nil {
+		return "", false, err
+	}
+	if split {
+		return filepath.Join(fragmentDir, config.EpicsFragmentName), true, nil
+	}
+	return path, false, nil
+}
+
+// writeEpicsFragment writes the refresh-owned [epic.*] and [archive] tables
+// of a split config.
+func writeEpicsFragment(path string, cfg *config.Config) error {
+	var sb strings.Builder
+	sb.WriteString("# Managed by `obi refresh`. Edited epic values survive a refresh, but comments do not;\n")
+	sb.WriteString("# keep hand-written settings in the other fragments of this directory.\n\n")
+	writeEpicSections(&sb, cfg)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+func writeEpicSections(sb *strings.Builder, cfg *config.Config) {
 	keys := make([]string, 0, len(cfg.Epics))
 	for key := range cfg.Epics {
 		keys = append(keys, key)
@@ -459,26 +949,330 @@ func writeConfigFile(path string, cfg *config.Config) error {
 	sort.Strings(keys)
 
 	for _, key := range keys {
-		e := cfg.Epics[key]
-		sb.WriteString(fmt.Sprintf("[epic.%s]\n", key))
-		if strings.TrimSpace(e.Alias) != "" {
-			sb.WriteString(fmt.Sprintf("alias = %q\n", e.Alias))
-		} else {
-			sb.WriteString(fmt.Sprintf("alias = %q\n", key))
-		}
-		sb.WriteString(fmt.Sprintf("name = %q\n", e.Name))
-		sb.WriteString(fmt.Sprintf("prompt = %q\n", normalizeSingleLine(e.Prompt)))
-		sb.WriteString(fmt.Sprintf("id = %q\n", e.ID))
-		if e.Tool != "" {
-			sb.WriteString(fmt.Sprintf("tool = %q\n", e.Tool))
+		writeEpicTable(sb, "epic."+key, key, cfg.Epics[key])
+	}
+
+	writeArchiveSection(sb, cfg.Archive)
+}
+
+func writeEpicTable(sb *strings.Builder, table, key string, e config.EpicConfig) {
+	sb.WriteString(fmt.Sprintf("[%s]\n", table))
+	if strings.TrimSpace(e.Alias) != "" {
+		sb.WriteString(fmt.Sprintf("alias = %q\n", e.Alias))
+	} else {
+		sb.WriteString(fmt.Sprintf("alias = %q\n", key))
+	}
+	sb.WriteString(fmt.Sprintf("name = %q\n", normalizeSingleLine(e.Name)))
+	sb.WriteString(fmt.Sprintf("prompt = %s\n", promptValue(e.Prompt)))
+	sb.WriteString(fmt.Sprintf("id = %q\n", e.ID))
+	if e.Tool != "" {
+		sb.WriteString(fmt.Sprintf("tool = %q\n", e.Tool))
+	}
+	if e.Dir != "" {
+		sb.WriteString(fmt.Sprintf("dir = %q\n", e.Dir))
+	}
+	if e.Group != "" {
+		sb.WriteString(fmt.Sprintf("group = %q\n", e.Group))
+	}
+	if e.Branch != "" {
+		sb.WriteString(fmt.Sprintf("branch = %q\n", e.Branch))
+	}
+	if len(e.FormerAliases) > 0 {
+		sb.WriteString(fmt.Sprintf("former_aliases = [%s]\n", formatStringSlice(e.FormerAliases)))
+	}
+	if len(e.ContextFiles) > 0 {
+		sb.WriteString(fmt.Sprintf("context_files = [%s]\n", formatStringSlice(e.ContextFiles)))
+	}
+	if e.Verify.Command != "" {
+		sb.WriteString(fmt.Sprintf("\n[%s.verify]\n", table))
+		sb.WriteString(fmt.Sprintf("command = %q\n", e.Verify.Command))
+	}
+	if e.Escalation != nil {
+		sb.WriteString(fmt.Sprintf("\n[%s.escalation]\n", table))
+		writeEscalationFields(sb, *e.Escalation)
+	}
+	if len(e.Env) > 0 {
+		sb.WriteString(fmt.Sprintf("\n[%s.env]\n", table))
+		for _, entry := range envFromMap(e.Env) {
+			key, value, _ := strings.Cut(entry, "=")
+			sb.WriteString(fmt.Sprintf("%q = %q\n", key, value))
+		}
+	}
+	if len(e.Secrets.FromEnv) > 0 {
+		sb.WriteString(fmt.Sprintf("\n[%s.secrets]\n", table))
+		sb.WriteString(fmt.Sprintf("from_env = [%s]\n", formatStringSlice(e.Secrets.FromEnv)))
+	}
+	sb.WriteString("\n")
+}
+
+func writeWebhooksSection(sb *strings.Builder, hooks config.WebhooksConfig) {
+	if len(hooks.Endpoints) == 0 {
+		sb.WriteString("# Uncomment to POST each finished session's ledger entry, signed with HMAC-SHA256, to chat-ops endpoints.\n")
+		sb.WriteString("# [webhooks]\n")
+		sb.WriteString("# endpoints = [\"https://chatops.example/obi\"]\n")
+		sb.WriteString("# secret_env = \"OBI_WEBHOOK_SECRET\"\n\n")
+		return
+	}
+	sb.WriteString("[webhooks]\n")
+	sb.WriteString(fmt.Sprintf("endpoints = [%s]\n", formatStringSlice(hooks.Endpoints)))
+	if hooks.SecretEnv != "" {
+		sb.WriteString(fmt.Sprintf("secret_env = %q\n", hooks.SecretEnv))
+	}
+	if hooks.Retries != nil {
+		sb.WriteString(fmt.Sprintf("retries = %d\n", *hooks.Retries))
+	}
+	if hooks.TranscriptURL != "" {
+		sb.WriteString(fmt.Sprintf("transcript_url = %q\n", hooks.TranscriptURL))
+	}
+	sb.WriteString("\n")
+}
+
+func writeEscalationFields(sb *strings.Builder, esc config.EscalationConfig) {
+	if esc.Action != "" {
+		sb.WriteString(fmt.Sprintf("action = %q\n", esc.Action))
+	}
+	if len(esc.AutoApprove) > 0 {
+		sb.WriteString(fmt.Sprintf("auto_approve = [%s]\n", formatStringSlice(esc.AutoApprove)))
+	}
+	if esc.RequestPattern != "" {
+		sb.WriteString(fmt.Sprintf("request_pattern = %q\n", esc.RequestPattern))
+	}
+}
+
+func writeCodexFields(sb *strings.Builder, codex config.CodexConfig) {
+	writeNonEmpty := func(key, value string) {
+		if value != "" {
+			sb.WriteString(fmt.Sprintf("%s = %q\n", key, value))
+		}
+	}
+	writeNonEmpty("binary", codex.Binary)
+	writeNonEmpty("model", codex.Model)
+	writeNonEmpty("sandbox", codex.Sandbox)
+	writeNonEmpty("approval", codex.Approval)
+	if len(codex.ExtraArgs) > 0 {
+		sb.WriteString(fmt.Sprintf("extra_args = [%s]\n", formatStringSlice(codex.ExtraArgs)))
+	}
+	if len(codex.FallbackModels) > 0 {
+		sb.WriteString(fmt.Sprintf("fallback_models = [%s]\n", formatStringSlice(codex.FallbackModels)))
+	}
+	if len(codex.ForbiddenArgs) > 0 {
+		sb.WriteString(fmt.Sprintf("forbidden_args = [%s]\n", formatStringSlice(codex.ForbiddenArgs)))
+	}
+	if len(codex.ForbiddenEnv) > 0 {
+		sb.WriteString(fmt.Sprintf("forbidden_env = [%s]\n", formatStringSlice(codex.ForbiddenEnv)))
+	}
+	if codex.AliasTimeoutSeconds != nil {
+		sb.WriteString(fmt.Sprintf("alias_timeout_seconds = %d\n", *codex.AliasTimeoutSeconds))
+	}
+}
+
+func writeArchiveSection(sb *strings.Builder, archive config.ArchiveConfig) {
+	if len(archive.Ignore) == 0 && len(archive.Epics) == 0 {
+		sb.WriteString("# Closed epics are moved under [archive.epic.*] so their prompts survive refresh.\n")
+		sb.WriteString("# [archive]\n")
+		sb.WriteString("# ignore = [\"epic-id\"]   # open epics refresh should never add\n")
+		return
+	}
+	sb.WriteString("# Epics refresh no longer tracks. Archived epics are restored if they reopen.\n")
+	sb.WriteString("[archive]\n")
+	sb.WriteString(fmt.Sprintf("ignore = [%s]\n\n", formatStringSlice(archive.Ignore)))
+
+	keys := make([]string, 0, len(archive.Epics))
+	for key := range archive.Epics {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writeEpicTable(sb, "archive.epic."+key, key, archive.Epics[key])
+	}
+}
+
+func writeProfilesSection(sb *strings.Builder, profiles map[string]config.ProfileConfig) {
+	if len(profiles) == 0 {
+		sb.WriteString("# Uncomment to add an overlay selected with `obi go --profile ci` or OBI_PROFILE=ci.\n")
+		sb.WriteString("# [profiles.ci]\n")
+		sb.WriteString("# confirm_before_run = false\n")
+		sb.WriteString("# [profiles.ci.codex]\n")
+		sb.WriteString("# approval = \"never\"\n\n")
+		return
+	}
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		profile := profiles[name]
+		table := fmt.Sprintf("profiles.%q", name)
+		sb.WriteString(fmt.Sprintf("[%s]\n", table))
+		if profile.ConfirmBeforeRun != nil {
+			sb.WriteString(fmt.Sprintf("confirm_before_run = %t\n", *profile.ConfirmBeforeRun))
+		}
+		if profile.Codex != nil {
+			sb.WriteString(fmt.Sprintf("\n[%s.codex]\n", table))
+			writeCodexFields(sb, *profile.Codex)
+		}
+		if profile.Redaction != nil {
+			sb.WriteString(fmt.Sprintf("\n[%s.redaction]\n", table))
+			sb.WriteString(fmt.Sprintf("live = %t\n", profile.Redaction.Live))
+		}
+		if profile.Notify != nil {
+			sb.WriteString(fmt.Sprintf("\n[%s.notify]\n", table))
+			sb.WriteString(fmt.Sprintf("webhook = %q\n", profile.Notify.Webhook))
 		}
 		sb.WriteString("\n")
 	}
+}
 
-	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
-		return fmt.Errorf("write %s: %w", path, err)
+func writeTemplatesSection(sb *strings.Builder, templates map[string]config.TemplateConfig) {
+	if len(templates) == 0 {
+		sb.WriteString("# Uncomment to add a recurring chore outside bd, run with `obi run deps-update`.\n")
+		sb.WriteString("# [template.deps-update]\n")
+		sb.WriteString("# prompt = \"Update dependencies to their latest compatible versions and fix any breakage.\"\n")
+		sb.WriteString("# every = \"7d\"                # `obi run` lists it as due once a week\n")
+		sb.WriteString("# [template.deps-update.codex]\n")
+		sb.WriteString("# model = \"gpt-5-codex-mini\"\n\n")
+		return
 	}
-	return nil
+	for _, name := range sortedTemplateNames(templates) {
+		tmpl := templates[name]
+		table := fmt.Sprintf("template.%q", name)
+		sb.WriteString(fmt.Sprintf("[%s]\n", table))
+		if tmpl.Name != "" {
+			sb.WriteString(fmt.Sprintf("name = %q\n", tmpl.Name))
+		}
+		sb.WriteString(fmt.Sprintf("prompt = %s\n", promptValue(tmpl.Prompt)))
+		if tmpl.Every != "" {
+			sb.WriteString(fmt.Sprintf("every = %q\n", tmpl.Every))
+		}
+		if tmpl.Dir != "" {
+			sb.WriteString(fmt.Sprintf("dir = %q\n", tmpl.Dir))
+		}
+		if tmpl.CodexOverride != nil {
+			sb.WriteString(fmt.Sprintf("\n[%s.codex]\n", table))
+			writeCodexFields(sb, *tmpl.CodexOverride)
+		}
+		if tmpl.Verify.Command != "" {
+			sb.WriteString(fmt.Sprintf("\n[%s.verify]\n", table))
+			sb.WriteString(fmt.Sprintf("command = %q\n", tmpl.Verify.Command))
+		}
+		if len(tmpl.Env) > 0 {
+			sb.WriteString(fmt.Sprintf("\n[%s.env]\n", table))
+			for _, entry := range envFromMap(tmpl.Env) {
+				key, value, _ := strings.Cut(entry, "=")
+				sb.WriteString(fmt.Sprintf("%q = %q\n", key, value))
+			}
+		}
+		sb.WriteString("\n")
+	}
+}
+
+func writePhasesSection(sb *strings.Builder, phases map[string][]string) {
+	if len(phases) == 0 {
+		sb.WriteString("# Uncomment to change how Codex output is classified into phases (regexes per phase;\n")
+		sb.WriteString("# a listed phase replaces its built-in patterns, an empty list disables it).\n")
+		sb.WriteString("# [phases]\n")
+		sb.WriteString("# testing = [\"\\\\bgo test\\\\b\", \"\\\\bbats\\\\b\"]\n")
+		sb.WriteString("# reviewing = [\"(?i)self-review\"]\n\n")
+		return
+	}
+	names := make([]string, 0, len(phases))
+	for name := range phases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sb.WriteString("[phases]\n")
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("%q = [%s]\n", name, formatStringSlice(phases[name])))
+	}
+	sb.WriteString("\n")
+}
+
+func writeTUISection(sb *strings.Builder, tuiCfg config.TUIConfig) {
+	colors := tuiCfg.Colors
+	colorsSet := colors.Accent != "" || colors.Success != "" || colors.Warning != "" || colors.Failure != "" || colors.Dim != ""
+	if tuiCfg.Theme == "" && tuiCfg.Timestamps == "" && tuiCfg.SeparatorMinutes <= 0 && tuiCfg.StallMinutes == nil && !tuiCfg.StallNotify && tuiCfg.EventBuffer <= 0 && !tuiCfg.CollapseProgress && !tuiCfg.WrapLines && tuiCfg.Scrollback <= 0 && len(tuiCfg.SoftStopReasons) == 0 && tuiCfg.UsdPerMTok <= 0 && tuiCfg.Layout == (config.TUILayoutConfig{}) && tuiCfg.Attention == "" && len(tuiCfg.AttentionEvents) == 0 && !colorsSet {
+		sb.WriteString("# Uncomment to change the TUI palette (dark, light, or none; NO_COLOR always wins).\n")
+		sb.WriteString("# [tui]\n")
+		sb.WriteString("# theme = \"dark\"\n")
+		sb.WriteString("# timestamps = \"relative\"   # off, clock, or relative (toggle live with 't')\n")
+		sb.WriteString("# separator_minutes = 5\n")
+		sb.WriteString("# stall_minutes = 5         # 0 disables the \"no output\" header warning\n")
+		sb.WriteString("# stall_notify = true       # ring the bell and log a notice when a stall starts\n")
+		sb.WriteString("# event_buffer = 64         # raise if the TUI reports dropped events\n")
+		sb.WriteString("# collapse_progress = true  # fold spinner rewrites into one line in the pane and transcript\n")
+		sb.WriteString("# wrap_lines = true         # soft-wrap long log lines instead of truncating them\n")
+		sb.WriteString("# soft_stop_reasons = [\"meeting starting\", \"wrong approach\", \"budget exhausted\"]  # quick-pick on 's'\n")
+		sb.WriteString("# usd_per_mtok = 5.0        # price tokens for the {cost} placeholder\n")
+		sb.WriteString("# attention = \"bell\"        # bell, osc9, or both when a session needs you (confirm, approval, needs_help, exit)\n")
+		sb.WriteString("# [tui.layout]              # placeholders: {epic} {epic_id} {bead} {status} {elapsed} {tokens} {cost} {phase} {hotkeys}\n")
+		sb.WriteString("# status = \"{status} | {elapsed} | Cost: {cost}\"\n\n")
+		return
+	}
+	sb.WriteString("[tui]\n")
+	if tuiCfg.Theme != "" {
+		sb.WriteString(fmt.Sprintf("theme = %q\n", tuiCfg.Theme))
+	}
+	if tuiCfg.Timestamps != "" {
+		sb.WriteString(fmt.Sprintf("timestamps = %q\n", tuiCfg.Timestamps))
+	}
+	if tuiCfg.SeparatorMinutes > 0 {
+		sb.WriteString(fmt.Sprintf("separator_minutes = %d\n", tuiCfg.SeparatorMinutes))
+	}
+	if tuiCfg.StallMinutes != nil {
+		sb.WriteString(fmt.Sprintf("stall_minutes = %d\n", *tuiCfg.StallMinutes))
+	}
+	if tuiCfg.StallNotify {
+		sb.WriteString("stall_notify = true\n")
+	}
+	if tuiCfg.EventBuffer > 0 {
+		sb.WriteString(fmt.Sprintf("event_buffer = %d\n", tuiCfg.EventBuffer))
+	}
+	if tuiCfg.CollapseProgress {
+		sb.WriteString("collapse_progress = true\n")
+	}
+	if tuiCfg.WrapLines {
+		sb.WriteString("wrap_lines = true\n")
+	}
+	if tuiCfg.Scrollback > 0 {
+		sb.WriteString(fmt.Sprintf("scrollback = %d\n", tuiCfg.Scrollback))
+	}
+	if len(tuiCfg.SoftStopReasons) > 0 {
+		sb.WriteString(fmt.Sprintf("soft_stop_reasons = [%s]\n", formatStringSlice(tuiCfg.SoftStopReasons)))
+	}
+	if tuiCfg.Attention != "" {
+		sb.WriteString(fmt.Sprintf("attention = %q\n", tuiCfg.Attention))
+	}
+	if len(tuiCfg.AttentionEvents) > 0 {
+		sb.WriteString(fmt.Sprintf("attention_events = [%s]\n", formatStringSlice(tuiCfg.AttentionEvents)))
+	}
+	if tuiCfg.UsdPerMTok > 0 {
+		sb.WriteString(fmt.Sprintf("usd_per_mtok = %g\n", tuiCfg.UsdPerMTok))
+	}
+	if colorsSet {
+		sb.WriteString("\n[tui.colors]\n")
+		writeNonEmpty := func(key, value string) {
+			if value != "" {
+				sb.WriteString(fmt.Sprintf("%s = %q\n", key, value))
+			}
+		}
+		writeNonEmpty("accent", colors.Accent)
+		writeNonEmpty("success", colors.Success)
+		writeNonEmpty("warning", colors.Warning)
+		writeNonEmpty("failure", colors.Failure)
+		writeNonEmpty("dim", colors.Dim)
+	}
+	if layout := tuiCfg.Layout; layout != (config.TUILayoutConfig{}) {
+		sb.WriteString("\n[tui.layout]\n")
+		for _, field := range []struct{ key, value string }{
+			{"title", layout.Title}, {"context", layout.Context}, {"status", layout.Status}, {"footer", layout.Footer},
+		} {
+			if field.value != "" {
+				sb.WriteString(fmt.Sprintf("%s = %q\n", field.key, field.value))
+			}
+		}
+	}
+	sb.WriteString("\n")
 }
 
 func formatStringSlice(values []string) string {
@@ -493,6 +1287,66 @@ func escapeTripleQuotes(s string) string {
 	return strings.ReplaceAll(s, "\"\"\"", "\\\"\\\"\\\"")
 }
 
+// promptValue renders a prompt as a TOML value: one quoted line, or a
+// multi-line string when the prompt spans lines (e.g. after obi edit). Line
+// endings and trailing whitespace are normalized, so rewriting a config
+// that was loaded from this output reproduces it byte for byte.
+func promptValue(prompt string) string {
+	prompt = normalizePromptText(prompt)
+	if !strings.Contains(prompt, "\n") {
+		return fmt.Sprintf("%q", normalizeSingleLine(prompt))
+	}
+	return tomlMultiline(prompt)
+}
+
+// tomlMultiline quotes s as a multi-line TOML string, preferring a literal
+// string so backslashes in prompts stay as written.
+func tomlMultiline(s string) string {
+	if !strings.Contains(s, "'''") {
+		return "'''\n" + s + "'''"
+	}
+	escaped := strings.ReplaceAll(s, `\`, `\\`)
+	return `"""` + "\n" + escapeTripleQuotes(escaped) + `"""`
+}
+
+// seedPromptMaxBytes caps a prompt seeded from a bd description; longer
+// descriptions are cut at a line or word break.
+const seedPromptMaxBytes = 2000
+
+// seedPrompt turns a bd epic description into a starting epic prompt:
+// control characters are dropped, runs of blank lines collapse to one, and
+// long text is cut with a note that it was truncated.
+func seedPrompt(description string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			return r
+		}
+		return -1
+	}, strings.ReplaceAll(description, "\r\n", "\n"))
+	text := normalizePromptText(cleaned)
+	for strings.Contains(text, "\n\n\n") {
+		text = strings.ReplaceAll(text, "\n\n\n", "\n\n")
+	}
+	if len(text) <= seedPromptMaxBytes {
+		return text
+	}
+	cut := truncate(text, seedPromptMaxBytes)
+	if i := strings.LastIndexAny(cut, "\n "); i > seedPromptMaxBytes/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "\n[Description truncated; see bd show for the rest.]"
+}
+
+// normalizePromptText converts line endings to \n, drops trailing
+// whitespace from every line, and trims blank lines around the text.
+func normalizePromptText(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
 func normalizeSingleLine(s string) string {
 	trimmed := strings.TrimSpace(strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n"))
 	if trimmed == "" {
@@ -506,7 +1360,8 @@ func normalizeSingleLine(s string) string {
 }
 
 func codexProvided(c config.CodexConfig) bool {
-	return c.Binary != "" || c.Model != "" || c.Sandbox != "" || c.Approval != "" || len(c.ExtraArgs) > 0
+	return c.Binary != "" || c.Model != "" || c.Sandbox != "" || c.Approval != "" || len(c.ExtraArgs) > 0 || len(c.FallbackModels) > 0 ||
+		len(c.ForbiddenArgs) > 0 || len(c.ForbiddenEnv) > 0 || c.AliasTimeoutSeconds != nil
 }
 
 func fallbackAlias(title string) string {
@@ -553,7 +1408,10 @@ func boolPtr(val bool) *bool {
 	return &b
 }
 
-func generateAliasesBatch(codexCfg config.CodexConfig, requests map[string]aliasRequest) (map[string]string, error) {
+// generateAliasesBatch asks Codex to name the requested epics. When Codex
+// does not answer within codex.alias_timeout_seconds it returns no
+// suggestions, so finalizeAlias derives every alias from the epic title.
+func generateAliasesBatch(codexCfg config.CodexConfig, requests map[string]aliasRequest, logger refreshLogger) (map[string]string, error) {
 	if len(requests) == 0 {
 		return map[string]string{}, nil
 	}
@@ -580,7 +1438,20 @@ func generateAliasesBatch(codexCfg config.CodexConfig, requests map[string]alias
 	if err != nil {
 		return nil, err
 	}
-	output, err := runCodexCapture(inv)
+	ctx := context.Background()
+	timeout := codexCfg.AliasTimeout()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	stop := logger.spin("Waiting for Codex to suggest aliases")
+	output, err := runCodexCapture(ctx, inv)
+	stop()
+	if errors.Is(err, context.DeadlineExceeded) {
+		logger.Printf("Codex did not answer within %s; deriving aliases from epic titles instead.\n", timeout)
+		return map[string]string{}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -597,19 +1468,31 @@ func generateAliasesBatch(codexCfg config.CodexConfig, requests map[string]alias
 	return parsed, nil
 }
 
+// truncate cuts s to at most max bytes, backing off to a rune boundary so
+// the result stays valid UTF-8 and a character is never split.
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
 	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
 	return s[:max]
 }
 
-func runCodexCapture(inv codexexec.Invocation) (string, error) {
-	cmd := exec.Command(inv.Binary, inv.Args...)
+// runCodexCapture runs inv and returns its stdout. When ctx ends first,
+// Codex is killed and the error wraps ctx.Err().
+func runCodexCapture(ctx context.Context, inv codexexec.Invocation) (string, error) {
+	cmd := exec.CommandContext(ctx, inv.Binary, inv.Args...)
+	// Children Codex started may hold the pipes open after it is killed.
+	cmd.WaitDelay = time.Second
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", fmt.Errorf("codex exec: %w", ctxErr)
+		}
 		return "", fmt.Errorf("codex exec failed: %v\n%s", err, stderr.String())
 	}
 	return stdout.String(), nil
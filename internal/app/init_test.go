@@ -1,6 +1,14 @@
 package app
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
 
 func TestSanitizeKey(t *testing.T) {
 	if got := sanitizeKey("abc-def"); got != "abc_def" {
@@ -56,3 +64,382 @@ func TestFilterActiveEpicsIncludesEligibleOpen(t *testing.T) {
 		t.Fatalf("expected open epic even if eligible_for_close; got %d", len(got))
 	}
 }
+
+func testBDEpic(id, title string) bdEpic {
+	var e bdEpic
+	e.Epic.ID = id
+	e.Epic.Title = title
+	e.Epic.Status = "open"
+	return e
+}
+
+func TestBuildConfigArchivesRemovedAndRestoresReopenedEpics(t *testing.T) {
+	existing := &config.Config{
+		Epics: map[string]config.EpicConfig{
+			"obi_foo": {Name: "Foo", ID: "obi-foo", Alias: "foo", Prompt: "keep me"},
+			"obi_bar": {Name: "Bar", ID: "obi-bar", Alias: "bar", Prompt: "custom bar"},
+		},
+		Archive: config.ArchiveConfig{
+			Epics: map[string]config.EpicConfig{
+				"obi_baz": {Name: "Baz", ID: "obi-baz", Alias: "baz", Prompt: "old baz"},
+			},
+		},
+	}
+	epics := []bdEpic{testBDEpic("obi-foo", "Foo"), testBDEpic("obi-baz", "Baz")}
+
+	cfg, summary, err := buildConfig(epics, existing, false, refreshLogger{})
+	if err != nil {
+		t.Fatalf("buildConfig: %v", err)
+	}
+	if summary.kept != 1 || summary.restored != 1 || summary.archived != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if got := cfg.Epics["obi_baz"]; got.Prompt != "old baz" || got.Alias != "baz" {
+		t.Fatalf("expected restored epic to keep customizations, got %+v", got)
+	}
+	if _, ok := cfg.Archive.Epics["obi_baz"]; ok {
+		t.Fatalf("restored epic should leave the archive")
+	}
+	if got := cfg.Archive.Epics["obi_bar"]; got.Prompt != "custom bar" {
+		t.Fatalf("expected closed epic archived with prompt, got %+v", got)
+	}
+}
+
+func TestBuildConfigSkipsIgnoredEpics(t *testing.T) {
+	existing := &config.Config{
+		Epics: map[string]config.EpicConfig{
+			"obi_foo":   {Name: "Foo", ID: "obi-foo", Alias: "foo"},
+			"obi_noise": {Name: "Noise", ID: "obi-noise", Alias: "noise"},
+		},
+		Archive: config.ArchiveConfig{Ignore: []string{"OBI-NOISE"}},
+	}
+	epics := []bdEpic{testBDEpic("obi-foo", "Foo"), testBDEpic("obi-noise", "Noise")}
+
+	cfg, summary, err := buildConfig(epics, existing, false, refreshLogger{})
+	if err != nil {
+		t.Fatalf("buildConfig: %v", err)
+	}
+	if _, ok := cfg.Epics["obi_noise"]; ok {
+		t.Fatalf("ignored epic should not be tracked")
+	}
+	if summary.ignored != 1 || summary.archived != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if len(cfg.Archive.Ignore) != 1 {
+		t.Fatalf("ignore list should be preserved, got %v", cfg.Archive.Ignore)
+	}
+}
+
+func TestWriteConfigFileRoundTripsArchiveAndRunContext(t *testing.T) {
+	aliasTimeout := 30
+	cfg := &config.Config{
+		ResultsLog: "./obi-results.log",
+		Epics: map[string]config.EpicConfig{
+			"obi_foo": {Name: "Foo", ID: "obi-foo", Alias: "foo", Dir: "svc", Env: map[string]string{"FEATURE_X": "on"}, Secrets: config.EpicSecretsConfig{FromEnv: []string{"STRIPE_TEST_KEY"}}, Branch: "epic/{{.Alias}}", FormerAliases: []string{"fooold"}, Verify: config.VerifyConfig{Command: "go test ./..."}, Escalation: &config.EscalationConfig{Action: config.EscalationDeny}},
+		},
+		Archive: config.ArchiveConfig{
+			Ignore: []string{"obi-noise"},
+			Epics: map[string]config.EpicConfig{
+				"obi_bar": {Name: "Bar", ID: "obi-bar", Alias: "bar", Prompt: "custom bar"},
+			},
+		},
+		Phases:     map[string][]string{"testing": {`\bbats\b`}},
+		Notify:     config.NotifyConfig{Webhook: "https://hooks.example/base"},
+		Beads:      config.BeadsConfig{Sync: true, SyncCommand: "bd sync --pull"},
+		Escalation: config.EscalationConfig{Action: config.EscalationPrompt, AutoApprove: []string{`^go test`}},
+		Style:      config.StyleConfig{CommitConvention: config.CommitConventional, MaxSubjectLength: 72, Enforce: config.StyleFail},
+		Webhooks:   config.WebhooksConfig{Endpoints: []string{"https://chatops.example/obi"}, SecretEnv: "HOOK_KEY"},
+		Codex:      config.CodexConfig{ForbiddenArgs: []string{"--ask-for-approval=never --sandbox=danger-full-access"}, ForbiddenEnv: []string{"CODEX_UNSAFE"}, AliasTimeoutSeconds: &aliasTimeout},
+		TUI:        config.TUIConfig{Scrollback: 20000, SoftStopReasons: []string{"meeting starting", "wrong approach"}, Attention: "both", AttentionEvents: []string{"approval", "needs_help"}},
+		Prompt:     config.PromptConfig{IncludeReadyList: true},
+		Summary:    config.SummaryConfig{Review: true, PullRequest: config.PullRequestConfig{Command: "gh pr create --title {{.Title}} --body-file {{.BodyFile}}"}},
+		Schedule: config.ScheduleConfig{AllowedHours: "08:00-19:00", BlackoutDates: []string{"2026-12-24", "2026-12-28..2027-01-02"}, Jobs: []config.ScheduleJob{
+			{Name: "weekly-deps", Cron: "0 3 * * 1", Template: "deps-update"},
+			{Cron: "@daily", Alias: "foo"},
+		}},
+		Update:     config.UpdateConfig{ReleaseURL: "https://example.com/releases/latest"},
+		Guardrails: config.GuardrailsConfig{RequireCleanTree: true, AllowDirty: []string{".beads/"}, ProtectedBranches: []string{"main", "release/*"}, AllowedPaths: []string{"services/api/"}, ForbiddenPaths: []string{".github/", "*.lock"}, AbortCleanup: "git checkout -- {{.Paths}}"},
+		Profiles: map[string]config.ProfileConfig{
+			"ci": {
+				ConfirmBeforeRun: boolPtr(false),
+				Codex:            &config.CodexConfig{Approval: "never"},
+				Notify:           &config.NotifyConfig{Webhook: "https://hooks.example/ci"},
+			},
+		},
+		Templates: map[string]config.TemplateConfig{
+			"deps-update": {
+				Prompt:        "Update dependencies.\nRun the tests.",
+				Every:         "7d",
+				CodexOverride: &config.CodexConfig{Model: "gpt-mini"},
+				Env:           map[string]string{"GOFLAGS": "-mod=mod"},
+			},
+		},
+		RecordEnvironment:      true,
+		TranscriptNameTemplate: "{{.Alias}}/{{.Date}}-{{.SessionID}}.log",
+		Transcript:             config.TranscriptConfig{Include: []string{"codex"}, DropANSI: true, StripProgress: true},
+		Artifacts:              config.ArtifactsConfig{Paths: []string{"coverage.out", "build/*.log"}, MaxMB: 20},
+	}
+	path := filepath.Join(t.TempDir(), "obi.toml")
+	if err := writeConfigFile(path, cfg); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	loaded, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded.Epics) != 1 {
+		t.Fatalf("archived epics must not be active, got %v", loaded.Epics)
+	}
+	if got := loaded.Archive.Epics["obi_bar"]; got.Prompt != "custom bar" {
+		t.Fatalf("archived epic lost its prompt: %+v", got)
+	}
+	if foo := loaded.Epics["obi_foo"]; foo.Dir != "svc" || foo.Env["FEATURE_X"] != "on" || len(foo.Secrets.FromEnv) != 1 || foo.Secrets.FromEnv[0] != "STRIPE_TEST_KEY" || foo.Branch != "epic/{{.Alias}}" || len(foo.FormerAliases) != 1 {
+		t.Fatalf("epic dir/env lost: %+v", foo)
+	}
+	if got := loaded.Epics["obi_foo"].Verify.Command; got != "go test ./..." {
+		t.Fatalf("verify command lost: %q", got)
+	}
+	if !loaded.Archive.Ignores("obi-noise") {
+		t.Fatalf("ignore list lost: %+v", loaded.Archive)
+	}
+	if got := loaded.Phases["testing"]; len(got) != 1 || got[0] != `\bbats\b` {
+		t.Fatalf("phase patterns lost: %v", loaded.Phases)
+	}
+	if loaded.Notify.Webhook != "https://hooks.example/base" {
+		t.Fatalf("notify webhook lost: %+v", loaded.Notify)
+	}
+	if loaded.Beads.SyncCommandValue() != "bd sync --pull" || !loaded.Beads.Sync {
+		t.Fatalf("beads sync lost: %+v", loaded.Beads)
+	}
+	if esc := loaded.Escalation; esc.Action != config.EscalationPrompt || len(esc.AutoApprove) != 1 || esc.AutoApprove[0] != `^go test` {
+		t.Fatalf("escalation policy lost: %+v", esc)
+	}
+	if esc := loaded.Epics["obi_foo"].Escalation; esc == nil || esc.Action != config.EscalationDeny {
+		t.Fatalf("epic escalation override lost: %+v", esc)
+	}
+	if hooks := loaded.Webhooks; len(hooks.Endpoints) != 1 || hooks.SecretEnvValue() != "HOOK_KEY" {
+		t.Fatalf("webhooks lost: %+v", hooks)
+	}
+	if loaded.Style != cfg.Style {
+		t.Fatalf("style rules lost: %+v", loaded.Style)
+	}
+	if codex := loaded.Codex; len(codex.ForbiddenArgs) != 1 || codex.ForbiddenArgs[0] != cfg.Codex.ForbiddenArgs[0] || len(codex.ForbiddenEnv) != 1 || codex.AliasTimeout() != 30*time.Second {
+		t.Fatalf("codex prohibitions lost: %+v", codex)
+	}
+	if loaded.TranscriptNameTemplate != cfg.TranscriptNameTemplate {
+		t.Fatalf("transcript_name_template lost: %q", loaded.TranscriptNameTemplate)
+	}
+	if tr := loaded.Transcript; len(tr.Include) != 1 || tr.Include[0] != "codex" || !tr.DropANSI || !tr.StripProgress {
+		t.Fatalf("transcript filters lost: %+v", tr)
+	}
+	if art := loaded.Artifacts; len(art.Paths) != 2 || art.Paths[1] != "build/*.log" || art.MaxMB != 20 {
+		t.Fatalf("artifacts lost: %+v", art)
+	}
+	if !loaded.RecordEnvironment {
+		t.Fatal("record_environment lost")
+	}
+	if guard := loaded.Guardrails; !guard.RequireCleanTree || len(guard.AllowDirty) != 1 || guard.AllowDirty[0] != ".beads/" || len(guard.ProtectedBranches) != 2 || guard.ProtectedBranches[1] != "release/*" ||
+		len(guard.AllowedPaths) != 1 || guard.AllowedPaths[0] != "services/api/" || len(guard.ForbiddenPaths) != 2 || guard.ForbiddenPaths[1] != "*.lock" ||
+		guard.AbortCleanup != "git checkout -- {{.Paths}}" {
+		t.Fatalf("guardrails lost: %+v", guard)
+	}
+	if !loaded.Prompt.IncludeReadyList {
+		t.Fatalf("prompt settings lost: %+v", loaded.Prompt)
+	}
+	if got := loaded.Summary.PullRequest.Command; got != "gh pr create --title {{.Title}} --body-file {{.BodyFile}}" {
+		t.Fatalf("summary.pull_request lost: %q", got)
+	}
+	if tmpl := loaded.Templates["deps-update"]; tmpl.Prompt != "Update dependencies.\nRun the tests." || tmpl.Every != "7d" ||
+		tmpl.CodexOverride == nil || tmpl.CodexOverride.Model != "gpt-mini" || tmpl.Env["GOFLAGS"] != "-mod=mod" {
+		t.Fatalf("template lost: %+v", tmpl)
+	}
+	if sched := loaded.Schedule; sched.AllowedHours != "08:00-19:00" || len(sched.BlackoutDates) != 2 || sched.BlackoutDates[1] != "2026-12-28..2027-01-02" {
+		t.Fatalf("schedule lost: %+v", sched)
+	}
+	if jobs := loaded.Schedule.Jobs; len(jobs) != 2 || jobs[0] != cfg.Schedule.Jobs[0] || jobs[1] != cfg.Schedule.Jobs[1] {
+		t.Fatalf("schedule jobs lost: %+v", jobs)
+	}
+	if loaded.Update.ReleaseURL != cfg.Update.ReleaseURL {
+		t.Fatalf("update.release_url lost: %q", loaded.Update.ReleaseURL)
+	}
+	if !loaded.Summary.Review {
+		t.Fatal("summary.review lost")
+	}
+	if len(loaded.TUI.SoftStopReasons) != 2 || loaded.TUI.SoftStopReasons[1] != "wrong approach" {
+		t.Fatalf("soft_stop_reasons lost: %v", loaded.TUI.SoftStopReasons)
+	}
+	if loaded.TUI.Attention != "both" || len(loaded.TUI.AttentionEvents) != 2 || loaded.TUI.AttentionEvents[1] != "needs_help" {
+		t.Fatalf("tui attention lost: %+v", loaded.TUI)
+	}
+	if loaded.TUI.Scrollback != 20000 {
+		t.Fatalf("tui scrollback lost: %+v", loaded.TUI)
+	}
+	ci := loaded.Profiles["ci"]
+	if ci.ConfirmBeforeRun == nil || *ci.ConfirmBeforeRun || ci.Codex == nil || ci.Codex.Approval != "never" || ci.Notify == nil || ci.Redaction != nil {
+		t.Fatalf("profile lost: %+v", ci)
+	}
+}
+
+func TestWriteEpicsFragmentLeavesOtherFragments(t *testing.T) {
+	dir := t.TempDir()
+	base := "results_log = \"./obi-results.log\"\n\n[codex]\nmodel = \"gpt-5\" # hand-tuned\n"
+	basePath := filepath.Join(dir, "base.toml")
+	if err := os.WriteFile(basePath, []byte(base), 0o644); err != nil {
+		t.Fatalf("write base: %v", err)
+	}
+
+	fragmentDir, split, err := configFragmentDir(dir)
+	if err != nil || !split || fragmentDir != dir {
+		t.Fatalf("configFragmentDir = %q, %v, %v", fragmentDir, split, err)
+	}
+	cfg := &config.Config{
+		Epics: map[string]config.EpicConfig{
+			"obi_foo": {Name: "Foo", ID: "obi-foo", Alias: "foo"},
+		},
+		Archive: config.ArchiveConfig{Ignore: []string{"obi-noise"}},
+	}
+	if err := writeEpicsFragment(filepath.Join(dir, config.EpicsFragmentName), cfg); err != nil {
+		t.Fatalf("write fragment: %v", err)
+	}
+
+	got, err := os.ReadFile(basePath)
+	if err != nil || string(got) != base {
+		t.Fatalf("base fragment changed: %q, %v", got, err)
+	}
+	loaded, err := config.Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Codex.Model != "gpt-5" || loaded.Epics["obi_foo"].Alias != "foo" || !loaded.Archive.Ignores("obi-noise") {
+		t.Fatalf("merged config wrong: %+v", loaded)
+	}
+}
+
+func TestConfigFragmentDirMissingPathIsSingleFile(t *testing.T) {
+	if _, split, err := configFragmentDir(filepath.Join(t.TempDir(), "obi.toml")); err != nil || split {
+		t.Fatalf("missing config should be a single file, got %v, %v", split, err)
+	}
+}
+
+func TestGenerateAliasesBatchFallsBackOnTimeout(t *testing.T) {
+	codex := filepath.Join(t.TempDir(), "codex")
+	if err := os.WriteFile(codex, []byte("#!/bin/sh\nexec sleep 30\n"), 0o755); err != nil {
+		t.Fatalf("write fake codex: %v", err)
+	}
+	timeout := 1
+	requests := map[string]aliasRequest{"obi_foo": {Key: "obi_foo", Title: "Billing cleanup"}}
+	start := time.Now()
+	got, err := generateAliasesBatch(config.CodexConfig{Binary: codex, AliasTimeoutSeconds: &timeout}, requests, refreshLogger{})
+	if err != nil {
+		t.Fatalf("generateAliasesBatch: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no suggestions after the timeout, got %v", got)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("the hung Codex call was not canceled (%s)", elapsed)
+	}
+	used := map[string]struct{}{}
+	if alias := finalizeAlias("obi_foo", bdEpic{}, got, used); alias == "" {
+		t.Fatal("expected a local alias")
+	}
+}
+
+func TestRefreshOutputIsByteIdentical(t *testing.T) {
+	heartbeat := 2
+	existing := &config.Config{
+		ResultsLog:       "./obi-results.log",
+		HeartbeatMinutes: &heartbeat,
+		BasePrompt:       "\nUse `\\d+` to match IDs.  \r\nKeep \"\"\" quotes.\n\n",
+		Issues:           &config.IssuesConfig{Prompt: "Pick any bead.   \n"},
+		Epics: map[string]config.EpicConfig{
+			"obi_foo": {Name: "Foo  ", ID: "obi-foo", Alias: "foo", Prompt: "Line one  \r\n\r\nLine two\t\n", Env: map[string]string{"B": "2", "A": "1", "C": "3"}},
+			"obi_bar": {Name: "Bar", ID: "obi-bar", Alias: "bar"},
+		},
+		Phases:    map[string][]string{"testing": {`\bbats\b`}, "reviewing": {"(?i)self-review"}, "alpha": {"a"}},
+		Profiles:  map[string]config.ProfileConfig{"night": {}, "day": {}, "ci": {}},
+		Templates: map[string]config.TemplateConfig{"zeta": {Prompt: "z"}, "deps-update": {Prompt: "Update deps.  \nRun tests.\n"}},
+	}
+	epics := []bdEpic{testBDEpic("obi-foo", "Foo"), testBDEpic("obi-bar", "Bar")}
+
+	path := filepath.Join(t.TempDir(), "obi.toml")
+	var outputs []string
+	for i := 0; i < 3; i++ {
+		cfg, _, err := buildConfig(epics, existing, false, refreshLogger{})
+		if err != nil {
+			t.Fatalf("buildConfig: %v", err)
+		}
+		if err := writeConfigFile(path, cfg); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		outputs = append(outputs, string(data))
+		if existing, err = config.Load(path); err != nil {
+			t.Fatalf("load after refresh %d: %v\n%s", i+1, err, data)
+		}
+	}
+	if outputs[1] != outputs[0] || outputs[2] != outputs[1] {
+		t.Fatalf("refresh output changed between runs:\n--- first\n%s\n--- second\n%s", outputs[0], outputs[1])
+	}
+	if got := existing.BasePrompt; !strings.Contains(got, "`\\d+`") || !strings.Contains(got, `"""`) {
+		t.Fatalf("base prompt not preserved: %q", got)
+	}
+	if strings.Contains(outputs[0], " \n") || strings.Contains(outputs[0], "\t\n") || strings.Contains(outputs[0], "\r") {
+		t.Fatalf("trailing whitespace left in output:\n%s", outputs[0])
+	}
+}
+
+func TestTruncateKeepsRunesWhole(t *testing.T) {
+	if got := truncate("naïve", 3); got != "na" {
+		t.Fatalf("truncate split a rune: %q", got)
+	}
+	if got := truncate("abc", 5); got != "abc" {
+		t.Fatalf("truncate changed a short string: %q", got)
+	}
+}
+
+func TestSeedPromptCleansAndTruncatesDescriptions(t *testing.T) {
+	got := seedPrompt("\r\nShip the billing export.  \r\n\r\n\r\n\r\nCover refunds\x07 too.\n")
+	if got != "Ship the billing export.\n\nCover refunds too." {
+		t.Fatalf("seedPrompt = %q", got)
+	}
+	long := seedPrompt(strings.Repeat("word ", seedPromptMaxBytes))
+	if len(long) > seedPromptMaxBytes+100 || !strings.HasSuffix(long, "[Description truncated; see bd show for the rest.]") || strings.Contains(long, "wor\n") {
+		t.Fatalf("long description not cut at a word: %q", long[len(long)-80:])
+	}
+}
+
+func TestBuildConfigSeedsNewEpicPromptsOnRequest(t *testing.T) {
+	codex := filepath.Join(t.TempDir(), "codex")
+	if err := os.WriteFile(codex, []byte("#!/bin/sh\necho '{\"obi_bar\":\"bar\"}'\n"), 0o755); err != nil {
+		t.Fatalf("write fake codex: %v", err)
+	}
+	foo := testBDEpic("obi-foo", "Foo")
+	foo.Epic.Description = "Existing epics keep their prompt."
+	bar := testBDEpic("obi-bar", "Bar")
+	bar.Epic.Description = "Rework the bar importer.  "
+
+	for _, seed := range []bool{false, true} {
+		existing := &config.Config{
+			Codex: config.CodexConfig{Binary: codex},
+			Epics: map[string]config.EpicConfig{"obi_foo": {Name: "Foo", ID: "obi-foo", Alias: "foo"}},
+		}
+		cfg, summary, err := buildConfig([]bdEpic{foo, bar}, existing, seed, refreshLogger{})
+		if err != nil {
+			t.Fatalf("buildConfig: %v", err)
+		}
+		if got := cfg.Epics["obi_foo"].Prompt; got != "" {
+			t.Fatalf("existing epic prompt changed: %q", got)
+		}
+		want, wantSeeded := "", 0
+		if seed {
+			want, wantSeeded = "Rework the bar importer.", 1
+		}
+		if got := cfg.Epics["obi_bar"]; got.Prompt != want || got.Alias != "bar" || summary.seeded != wantSeeded {
+			t.Fatalf("seed=%t: new epic = %+v, seeded %d", seed, got, summary.seeded)
+		}
+	}
+}
@@ -8,19 +8,38 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/footer"
 )
 
-const ledgerSchemaVersion = "obi.v2"
+// ledgerSchemaVersion is written on every new entry. Older entries stay
+// readable; obi ledger migrate upgrades them in place.
+const ledgerSchemaVersion = "obi.v3"
 
-var (
-	errLedgerNotFound = errors.New("results log not found")
-	ledgerUpgradeOnce sync.Map
-)
+// ledgerStatusUnparsed marks runs whose process finished but whose report
+// could not be parsed; ParseError says why.
+const ledgerStatusUnparsed = "unparsed"
+
+var errLedgerNotFound = errors.New("results log not found")
+
+// errLockHeld is returned by openLockedFile when another process holds the
+// lock and the caller chose not to wait.
+var errLockHeld = errors.New("locked by another process")
+
+// lockLedger serializes the writers of the results log at path: appends,
+// and obi ledger migrate and verify --fix, which replace the file. The lock
+// lives in a file of its own because those rewrites swap the log's inode.
+// Close the returned file to release it.
+func lockLedger(path string) (*os.File, error) {
+	lock, err := openLockedFile(path+".lock", true)
+	if err != nil {
+		return nil, fmt.Errorf("lock results log: %w", err)
+	}
+	return lock, nil
+}
 
 type ledgerEntry struct {
 	SchemaVersion  string    `json:"schema_version"`
@@ -46,10 +65,84 @@ type ledgerEntry struct {
 	CodexSandbox   string    `json:"codex_sandbox,omitempty"`
 	CodexApproval  string    `json:"codex_approval,omitempty"`
 	CodexExtraArgs []string  `json:"codex_extra_args,omitempty"`
-	ConfigDigest   string    `json:"config_digest,omitempty"`
-	PromptHash     string    `json:"prompt_hash,omitempty"`
-	Redacted       bool      `json:"redacted,omitempty"`
+	// FallbackFrom lists models that were unavailable before CodexModel
+	// served the run.
+	FallbackFrom   []string              `json:"fallback_from,omitempty"`
+	ConfigDigest   string                `json:"config_digest,omitempty"`
+	PromptHash     string                `json:"prompt_hash,omitempty"`
+	Redacted       bool                  `json:"redacted,omitempty"`
 	OperatorEvents []operatorLedgerEvent `json:"operator_events,omitempty"`
+	DroppedEvents  map[string]int        `json:"dropped_events,omitempty"`
+	PhaseDurations map[string]int64      `json:"phase_durations_ms,omitempty"`
+	Verification   *verificationResult   `json:"verification,omitempty"`
+	TokensUsed     int64                 `json:"tokens_used,omitempty"`
+	Profile        string                `json:"profile,omitempty"`
+	ReportConflict *reportConflict       `json:"report_conflict,omitempty"`
+	ParseError     string                `json:"parse_error,omitempty"`
+	// FailureKind classifies a non-zero Codex exit from its output: auth,
+	// rate_limit, sandbox_denied, oom, network, or unknown.
+	FailureKind string `json:"failure_kind,omitempty"`
+	// TranscriptOmitted counts bytes cut from the transcript by transcript_max_mb.
+	TranscriptOmitted int64 `json:"transcript_omitted_bytes,omitempty"`
+	// ArtifactsDir holds the copies of the [artifacts] files this run left;
+	// Artifacts lists them relative to it.
+	ArtifactsDir string   `json:"artifacts_dir,omitempty"`
+	Artifacts    []string `json:"artifacts,omitempty"`
+	// Experiment and Variant name the obi experiment run and variant this
+	// session belonged to.
+	Experiment string `json:"experiment,omitempty"`
+	Variant    string `json:"experiment_variant,omitempty"`
+	// ScopeViolations lists files the session changed outside
+	// guardrails.allowed_paths or inside forbidden_paths, as "path (reason)".
+	ScopeViolations []string `json:"scope_violations,omitempty"`
+	// StyleViolations lists the [style] rules the commit summary broke.
+	StyleViolations []string `json:"style_violations,omitempty"`
+	// Commit* hold the commit summary split into Conventional Commits parts.
+	// CommitTypeInferred marks a type guessed from a plain summary's verb.
+	CommitType         string `json:"commit_type,omitempty"`
+	CommitScope        string `json:"commit_scope,omitempty"`
+	CommitSubject      string `json:"commit_subject,omitempty"`
+	CommitBreaking     bool   `json:"commit_breaking,omitempty"`
+	CommitTypeInferred bool   `json:"commit_type_inferred,omitempty"`
+	// PromptPath is the redacted copy of the prompt, with the session ID
+	// replaced by a placeholder, that the next confirmation diffs against.
+	PromptPath string `json:"prompt_path,omitempty"`
+	// Exploratory marks a --read-only run; resume never counts it.
+	Exploratory bool `json:"exploratory,omitempty"`
+	// AttemptGroup ties together every attempt at the same bead: a run
+	// joins the group of the bead's previous unsuccessful run, or starts
+	// one named after its own RunID.
+	AttemptGroup string `json:"attempt_group,omitempty"`
+	// Git records the checkout before and after the run.
+	Git *gitMetadata `json:"git,omitempty"`
+	// CostUSD prices TokensUsed at [tui] usd_per_mtok when it is set.
+	CostUSD float64 `json:"cost_usd,omitempty"`
+	// Environment describes the host and tool versions when
+	// record_environment is on.
+	Environment *runEnvironment `json:"environment,omitempty"`
+	// PullRequestURL is the PR [summary.pull_request] opened after a
+	// successful omnibus summary.
+	PullRequestURL string `json:"pull_request_url,omitempty"`
+	// CodexSessionID is Codex's own session (thread) ID, for codex resume
+	// and provider-side logs; empty when Codex did not print one.
+	CodexSessionID string `json:"codex_session_id,omitempty"`
+	// ContinuedFrom is the run whose Codex session this one resumed with
+	// obi go --continue-codex; the two share an attempt group.
+	ContinuedFrom string `json:"continued_from,omitempty"`
+	// Template names the [template.x] chore an obi run session ran.
+	Template string `json:"template,omitempty"`
+	// Simulated names the fakecodex scenario an obi go --simulate run
+	// played; such entries go to the simulate/ ledger, never results.log.
+	Simulated string `json:"simulated,omitempty"`
+	// Degraded explains why Codex ran on pipes instead of a PTY, as in a
+	// container without /dev/ptmx; empty for a normal session.
+	Degraded string `json:"degraded,omitempty"`
+	// AbortCleanup records what became of [guardrails] abort_cleanup after
+	// an aborted session: ran, failed, declined or skipped.
+	AbortCleanup string `json:"abort_cleanup,omitempty"`
+	// SummaryRaw keeps Codex's omnibus summary when the operator edited it
+	// under [summary] review; CommitSummary and CommitDetails hold the edit.
+	SummaryRaw *summaryDraft `json:"summary_raw,omitempty"`
 }
 
 const ledgerScanMaxBytes = 8 * 1024 * 1024
@@ -58,14 +151,22 @@ func appendLedgerEntry(path string, entry ledgerEntry) error {
 	if path == "" {
 		return fmt.Errorf("empty results log path")
 	}
-	if err := ensureLedgerSchema(path); err != nil {
-		return err
-	}
 	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
 		return fmt.Errorf("ensure log dir: %w", err)
 	}
 
+	lock, err := lockLedger(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+
 	entry.SchemaVersion = ledgerSchemaVersion
+	if entry.RunID == "" {
+		entry.RunID = entry.SessionID
+	}
+	grouper := loadAttemptIndex(path)
+	entry.AttemptGroup = grouper.group(entry)
 	entry.CommitSummary = strings.TrimSpace(entry.CommitSummary)
 	entry.CommitDetails = strings.TrimSpace(entry.CommitDetails)
 	entry.Escalation = strings.TrimSpace(entry.Escalation)
@@ -85,6 +186,9 @@ func appendLedgerEntry(path string, entry ledgerEntry) error {
 	if _, err := f.Write(append(record, '\n')); err != nil {
 		return fmt.Errorf("write ledger: %w", err)
 	}
+	if info, err := f.Stat(); err == nil {
+		saveAttemptIndex(path, info.Size(), grouper)
+	}
 	return nil
 }
 
@@ -98,6 +202,68 @@ func durationMillis(start, end time.Time) int64 {
 	return end.Sub(start).Milliseconds()
 }
 
+var tokensUsedPattern = regexp.MustCompile(`(?i)tokens used:?\s*([0-9][0-9,]*)`)
+
+// parseTokensUsed returns the last "tokens used" total Codex printed, or 0.
+func parseTokensUsed(output string) int64 {
+	matches := tokensUsedPattern.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return 0
+	}
+	digits := strings.ReplaceAll(matches[len(matches)-1][1], ",", "")
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// codexSessionPattern finds the session ID Codex prints in its banner
+// ("session id: <uuid>"), in --json thread events ("thread_id":"<uuid>"), or
+// in its closing "codex resume <uuid>" hint.
+var codexSessionPattern = regexp.MustCompile(`(?i)(?:session[ _]id|thread[ _]id)"?\s*[:=]\s*"?([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})|codex resume ([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})`)
+
+// parseCodexSessionID returns the first session ID Codex printed, skipping
+// obiSessionID, which the prompt echo and fenced report also carry.
+func parseCodexSessionID(output, obiSessionID string) string {
+	for _, match := range codexSessionPattern.FindAllStringSubmatch(output, -1) {
+		id := match[1]
+		if id == "" {
+			id = match[2]
+		}
+		if !strings.EqualFold(id, obiSessionID) {
+			return strings.ToLower(id)
+		}
+	}
+	return ""
+}
+
+// recordUnparsedRun logs a run whose report could not be parsed so the time
+// spent (and any commits Codex made) stays auditable, then returns the
+// error that stops the loop.
+func recordUnparsedRun(logPath string, entry ledgerEntry, plan sessionPlan, webhooks *webhookSender, output, parseErr string) error {
+	entry.Status = ledgerStatusUnparsed
+	entry.ParseError = parseErr
+	entry.BeadID = detectBeadID(plan, output)
+	if plan.BeadIDOverride != "" {
+		entry.BeadID = plan.BeadIDOverride
+	}
+	if err := appendLedgerEntry(logPath, entry); err != nil {
+		return fmt.Errorf("%s (recording unparsed run also failed: %w)", parseErr, err)
+	}
+	webhooks.deliver(entry)
+	printExitBanner(os.Stdout, plan, entry)
+	detail := parseErr
+	if entry.TranscriptPath != "" {
+		detail += "; transcript: " + entry.TranscriptPath
+	}
+	msg := fmt.Sprintf("%s (logged as %s, exit code %d)", detail, ledgerStatusUnparsed, entry.ExitCode)
+	if hint := failureHint(entry.FailureKind); hint != "" {
+		msg += "\n" + hint
+	}
+	return newExitError(msg)
+}
+
 func detectBeadID(plan sessionPlan, texts ...string) string {
 	root := strings.ToLower(strings.TrimSpace(plan.EpicID))
 	if root == "" {
@@ -139,6 +305,9 @@ func completedBeadsFromLedger(path, epicID string) ([]string, error) {
 	var completed []string
 	seen := map[string]struct{}{}
 	for _, entry := range entries {
+		if entry.Exploratory {
+			continue
+		}
 		status := strings.ToLower(strings.TrimSpace(entry.Status))
 		switch status {
 		case "":
@@ -160,6 +329,9 @@ func completedBeadsFromLedger(path, epicID string) ([]string, error) {
 				bead = "unknown bead"
 			}
 			return nil, fmt.Errorf("session %s ended with status=%s for %s; resolve it before resuming", entry.SessionID, status, bead)
+		case ledgerStatusUnparsed:
+			// No trustworthy report, so the bead is not treated as done.
+			continue
 		default:
 			return nil, fmt.Errorf("ledger entry for session %s has unknown status %q", entry.SessionID, entry.Status)
 		}
@@ -203,99 +375,84 @@ func ledgerEntriesForEpic(path, epicID string) ([]ledgerEntry, error) {
 	return entries, nil
 }
 
-func ensureLedgerSchema(path string) error {
-	if strings.TrimSpace(path) == "" {
-		return nil
-	}
-	if _, ok := ledgerUpgradeOnce.Load(path); ok {
-		return nil
-	}
-	needsUpgrade, err := ledgerNeedsUpgrade(path)
-	if err != nil {
-		return err
-	}
-	if needsUpgrade {
-		if err := upgradeLedgerFile(path); err != nil {
-			return err
-		}
-	}
-	ledgerUpgradeOnce.Store(path, struct{}{})
-	return nil
+// attemptGrouper assigns attempt groups by replaying a ledger in order. A
+// bead's runs share one group until one of them succeeds; the next run on
+// that bead starts a fresh group.
+type attemptGrouper struct {
+	open map[string]string
 }
 
-func ledgerNeedsUpgrade(path string) (bool, error) {
-	info, err := os.Stat(path)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			ledgerUpgradeOnce.Store(path, struct{}{})
-			return false, nil
-		}
-		return false, err
-	}
-	if info.IsDir() {
-		return false, fmt.Errorf("results log path %s is a directory", path)
-	}
-	f, err := os.Open(path)
-	if err != nil {
-		return false, err
+func newAttemptGrouper(entries []ledgerEntry) *attemptGrouper {
+	g := &attemptGrouper{open: map[string]string{}}
+	for _, entry := range entries {
+		g.group(entry)
 	}
-	defer f.Close()
+	return g
+}
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-		if strings.Contains(line, `"schema_version":"`+ledgerSchemaVersion+`"`) {
-			return false, nil
-		}
-		return true, nil
+// attemptIndex is the attempt grouper's state saved beside the results log
+// as <log>.attempts, so an append need not replay the whole ledger. It is
+// only trusted while the log still has the size it was saved for;
+// otherwise the ledger is replayed once and the index rewritten.
+type attemptIndex struct {
+	LedgerSize int64             `json:"ledger_size"`
+	Open       map[string]string `json:"open"`
+}
+
+func attemptIndexPath(logPath string) string {
+	return logPath + ".attempts"
+}
+
+// loadAttemptIndex returns the grouper for the next append to the log at
+// path. Call it with the ledger lock held. An unreadable ledger only costs
+// the grouping, never the entry.
+func loadAttemptIndex(path string) *attemptGrouper {
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
 	}
-	if err := scanner.Err(); err != nil {
-		return false, err
+	if data, err := os.ReadFile(attemptIndexPath(path)); err == nil {
+		var index attemptIndex
+		if json.Unmarshal(data, &index) == nil && index.LedgerSize == size && index.Open != nil {
+			return &attemptGrouper{open: index.Open}
+		}
 	}
-	return false, nil
+	previous, _ := ledgerEntriesForEpic(path, "")
+	return newAttemptGrouper(previous)
 }
 
-func upgradeLedgerFile(path string) error {
-	data, err := os.ReadFile(path)
+// saveAttemptIndex records g for a log of size bytes. A failed write only
+// means the next append replays the ledger.
+func saveAttemptIndex(path string, size int64, g *attemptGrouper) {
+	data, err := json.Marshal(attemptIndex{LedgerSize: size, Open: g.open})
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil
-		}
-		return err
+		return
 	}
+	_ = os.WriteFile(attemptIndexPath(path), data, 0o600)
+}
 
-	lines := strings.Split(string(data), "\n")
-	var upgraded []string
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" {
-			continue
-		}
-		var payload map[string]interface{}
-		if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
-			return fmt.Errorf("parse ledger entry during upgrade: %w", err)
-		}
-		payload["schema_version"] = ledgerSchemaVersion
-		encoded, err := json.Marshal(payload)
-		if err != nil {
-			return fmt.Errorf("encode upgraded entry: %w", err)
-		}
-		upgraded = append(upgraded, string(encoded))
+// group returns entry's attempt group and records it for later runs.
+// Groups already on the entry are kept; runs without a bead get their own.
+func (g *attemptGrouper) group(entry ledgerEntry) string {
+	group := strings.TrimSpace(entry.AttemptGroup)
+	bead := strings.ToLower(strings.TrimSpace(entry.BeadID))
+	key := strings.ToLower(strings.TrimSpace(entry.EpicID)) + "\x00" + bead
+	if group == "" && bead != "" {
+		group = g.open[key]
 	}
-
-	temp := path + ".upgrade"
-	content := strings.Join(upgraded, "\n")
-	if len(upgraded) > 0 {
-		content += "\n"
+	if group == "" {
+		group = entry.RunID
+		if group == "" {
+			group = entry.SessionID
+		}
 	}
-	if err := os.WriteFile(temp, []byte(content), 0o600); err != nil {
-		return fmt.Errorf("write upgraded ledger: %w", err)
+	if bead == "" {
+		return group
 	}
-	if err := os.Rename(temp, path); err != nil {
-		return fmt.Errorf("replace upgraded ledger: %w", err)
+	if strings.EqualFold(strings.TrimSpace(entry.Status), footer.StatusSuccess) {
+		delete(g.open, key)
+	} else {
+		g.open[key] = group
 	}
-	return nil
+	return group
 }
@@ -0,0 +1,479 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ledgerMigrateProgressMin is the log size above which obi ledger migrate
+// reports progress; smaller logs finish before a report would help.
+const ledgerMigrateProgressMin = 16 << 20
+
+// ledgerMigration is the outcome of upgrading a results log to the current
+// schema.
+type ledgerMigration struct {
+	Entries    int
+	Upgraded   int
+	BackupPath string
+}
+
+// ledgerMigrateOptions controls migrateLedger. Progress, when set, is
+// called with the bytes of the original log converted so far.
+type ledgerMigrateOptions struct {
+	Backup   bool
+	Now      time.Time
+	Progress func(done, total int64)
+}
+
+var errMigrationInterrupted = errors.New("ledger migration interrupted; the results log was not changed")
+
+func runLedger(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("obi ledger requires a subcommand ('migrate' or 'verify')")
+	}
+	switch args[0] {
+	case "migrate":
+		return runLedgerMigrate(args[1:])
+	case "verify":
+		return runLedgerVerify(args[1:])
+	default:
+		return fmt.Errorf("unknown ledger subcommand %q", args[0])
+	}
+}
+
+func runLedgerMigrate(args []string) error {
+	fs := newCommandFlags("ledger migrate", "obi ledger migrate [options]",
+		"Upgrade results log entries to schema "+ledgerSchemaVersion+". The original is backed up next to it,\nthe upgrade is written to a temporary file and verified, and only then renamed into place.")
+	var configPath string
+	var dryRun, noBackup bool
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.BoolVar(&dryRun, "dry-run", false, "report what would be upgraded without writing anything")
+	fs.BoolVar(&noBackup, "no-backup", false, "skip the timestamped backup of the original log")
+	if _, err := fs.parse(args); err != nil {
+		return err
+	}
+
+	_, cfg, err := loadConfig(configPath, "")
+	if err != nil {
+		return err
+	}
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		scan, err := scanLedgerForMigration(logPath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %d of %d entries would be upgraded to %s\n", logPath, scan.legacy, scan.entries, ledgerSchemaVersion)
+		return nil
+	}
+
+	// Ctrl+C stops between lines; the temporary file is removed and the
+	// results log is left as it was.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	lastDecile := int64(-1)
+	m, err := migrateLedger(ctx, logPath, ledgerMigrateOptions{
+		Backup: !noBackup,
+		Now:    time.Now(),
+		Progress: func(done, total int64) {
+			if total < ledgerMigrateProgressMin {
+				return
+			}
+			if decile := done * 10 / total; decile != lastDecile {
+				lastDecile = decile
+				fmt.Fprintf(os.Stderr, "obi: migrated %d%% (%s of %s)\n", decile*10, formatBytes(done), formatBytes(total))
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if m.Upgraded == 0 {
+		fmt.Printf("%s: all %d entries already use %s\n", logPath, m.Entries, ledgerSchemaVersion)
+		return nil
+	}
+	fmt.Printf("%s: upgraded %d of %d entries to %s\n", logPath, m.Upgraded, m.Entries, ledgerSchemaVersion)
+	if m.BackupPath != "" {
+		fmt.Printf("Backup: %s\n", m.BackupPath)
+	}
+	return nil
+}
+
+// migrateLedger upgrades the results log at path without ever leaving it
+// half-written. The log is streamed line by line, so it never has to fit in
+// memory: the original is copied to a backup (when requested), the upgrade
+// is written beside it as <path>.migrate, fsynced, and verified against the
+// original, and only then renamed into place. Nothing is written when
+// every entry is already current.
+func migrateLedger(ctx context.Context, path string, opts ledgerMigrateOptions) (ledgerMigration, error) {
+	before, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ledgerMigration{}, fmt.Errorf("%w: %s", errLedgerNotFound, path)
+		}
+		return ledgerMigration{}, err
+	}
+	// Holding the ledger lock keeps a finishing run from appending an entry
+	// the rename would then lose; it waits until the migration is done.
+	lock, err := lockLedger(path)
+	if err != nil {
+		return ledgerMigration{}, err
+	}
+	defer lock.Close()
+	scan, err := scanLedgerForMigration(path)
+	if err != nil {
+		return ledgerMigration{}, err
+	}
+	m := ledgerMigration{Entries: scan.entries, Upgraded: scan.legacy}
+	if m.Upgraded == 0 {
+		return m, nil
+	}
+
+	perm := before.Mode().Perm()
+	if opts.Backup {
+		m.BackupPath = fmt.Sprintf("%s.bak-%s", path, opts.Now.UTC().Format("20060102T150405Z"))
+		if err := copyFileSynced(path, m.BackupPath, perm); err != nil {
+			os.Remove(m.BackupPath)
+			return ledgerMigration{}, fmt.Errorf("back up results log: %w", err)
+		}
+	}
+	temp := path + ".migrate"
+	fail := func(err error) (ledgerMigration, error) {
+		os.Remove(temp)
+		return ledgerMigration{}, err
+	}
+	if err := convertLedgerFile(ctx, path, temp, perm, scan.runIDs, before.Size(), opts.Progress); err != nil {
+		return fail(err)
+	}
+	if err := verifyLedgerMigration(ctx, path, temp); err != nil {
+		if errors.Is(err, errMigrationInterrupted) {
+			return fail(err)
+		}
+		return fail(fmt.Errorf("verify migrated log (original left untouched): %w", err))
+	}
+	if ctx.Err() != nil {
+		return fail(errMigrationInterrupted)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		return fail(fmt.Errorf("replace results log: %w", err))
+	}
+	syncDir(filepath.Dir(path))
+	os.Remove(attemptIndexPath(path))
+	return m, nil
+}
+
+// ledgerMigrationScan is the read-only first pass over a log: how many
+// entries need upgrading, and the run IDs current entries already hold so
+// upgraded ones can avoid them.
+type ledgerMigrationScan struct {
+	entries int
+	legacy  int
+	runIDs  map[string]bool
+}
+
+func scanLedgerForMigration(path string) (ledgerMigrationScan, error) {
+	scan := ledgerMigrationScan{runIDs: map[string]bool{}}
+	err := forEachLedgerLine(context.Background(), path, nil, func(n int, line []byte) error {
+		var entry struct {
+			SchemaVersion string `json:"schema_version"`
+			RunID         string `json:"run_id"`
+		}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("parse results log line %d: %w", n, err)
+		}
+		scan.entries++
+		if entry.SchemaVersion != ledgerSchemaVersion {
+			scan.legacy++
+		} else if entry.RunID != "" {
+			scan.runIDs[entry.RunID] = true
+		}
+		return nil
+	})
+	return scan, err
+}
+
+// forEachLedgerLine streams the non-blank lines of path to fn with their
+// 1-based line numbers, stopping early when ctx is cancelled. read, when
+// set, is called with the bytes consumed so far.
+func forEachLedgerLine(ctx context.Context, path string, read func(int64), fn func(n int, line []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s", errLedgerNotFound, path)
+		}
+		return fmt.Errorf("open ledger: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), ledgerScanMaxBytes)
+	var consumed int64
+	for n := 1; scanner.Scan(); n++ {
+		if ctx.Err() != nil {
+			return errMigrationInterrupted
+		}
+		raw := scanner.Bytes()
+		consumed += int64(len(raw)) + 1
+		if read != nil {
+			read(consumed)
+		}
+		line := strings.TrimSpace(string(raw))
+		if line == "" {
+			continue
+		}
+		if err := fn(n, []byte(line)); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan ledger: %w", err)
+	}
+	return nil
+}
+
+// convertLedgerFile writes the upgraded form of src to dst and fsyncs it.
+func convertLedgerFile(ctx context.Context, src, dst string, perm os.FileMode, runIDs map[string]bool, total int64, progress func(done, total int64)) error {
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("create migrated log: %w", err)
+	}
+	defer out.Close()
+	w := bufio.NewWriter(out)
+	grouper := newAttemptGrouper(nil)
+	var read func(int64)
+	if progress != nil {
+		read = func(done int64) { progress(done, total) }
+	}
+	err = forEachLedgerLine(ctx, src, read, func(n int, line []byte) error {
+		upgraded, err := migrateLedgerLine(line, grouper, runIDs)
+		if err != nil {
+			return fmt.Errorf("results log line %d: %w", n, err)
+		}
+		if _, err := w.Write(upgraded); err != nil {
+			return fmt.Errorf("write migrated log: %w", err)
+		}
+		return w.WriteByte('\n')
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write migrated log: %w", err)
+	}
+	if err := out.Sync(); err != nil {
+		return fmt.Errorf("sync migrated log: %w", err)
+	}
+	return out.Close()
+}
+
+// migrateLedgerLine upgrades one entry older than the current schema,
+// keeping its existing fields (including ones this obi does not know) and
+// filling in the new ones: run_id, attempt_group, and the commit_* parts.
+// Current entries are returned untouched but still feed the grouper.
+func migrateLedgerLine(line []byte, grouper *attemptGrouper, runIDs map[string]bool) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, errors.New("not a JSON object")
+	}
+	var entry ledgerEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return nil, err
+	}
+	if entry.SchemaVersion == ledgerSchemaVersion {
+		grouper.group(entry)
+		return line, nil
+	}
+
+	if entry.RunID == "" {
+		entry.RunID = legacyRunID(entry, line)
+	}
+	entry.RunID = uniqueRunID(entry.RunID, runIDs)
+	entry.AttemptGroup = grouper.group(entry)
+	updates := map[string]interface{}{
+		"schema_version": ledgerSchemaVersion,
+		"run_id":         entry.RunID,
+		"attempt_group":  entry.AttemptGroup,
+	}
+	if entry.CommitType == "" && strings.TrimSpace(entry.CommitSummary) != "" {
+		applyCommitParts(&entry, entry.CommitSummary)
+		updates["commit_type"] = entry.CommitType
+		updates["commit_subject"] = entry.CommitSubject
+		if entry.CommitScope != "" {
+			updates["commit_scope"] = entry.CommitScope
+		}
+		if entry.CommitBreaking {
+			updates["commit_breaking"] = true
+		}
+		if entry.CommitTypeInferred {
+			updates["commit_type_inferred"] = true
+		}
+	}
+	for key, value := range updates {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("encode migrated entry: %w", err)
+		}
+		fields[key] = encoded
+	}
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("encode migrated entry: %w", err)
+	}
+	return encoded, nil
+}
+
+// legacyRunID names a run that predates run IDs after its session, or
+// after a hash of its line when even that is missing.
+func legacyRunID(entry ledgerEntry, raw []byte) string {
+	if id := strings.TrimSpace(entry.SessionID); id != "" {
+		return id
+	}
+	sum := sha256.Sum256(raw)
+	return "legacy-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// uniqueRunID suffixes id until it is unused, then claims it.
+func uniqueRunID(id string, taken map[string]bool) string {
+	candidate := id
+	for n := 2; taken[candidate]; n++ {
+		candidate = id + "-" + strconv.Itoa(n)
+	}
+	taken[candidate] = true
+	return candidate
+}
+
+// verifyLedgerMigration streams a migrated log alongside the original and
+// checks that it has the same number of entries, all on the current schema
+// with unique run IDs, and that every original field is unchanged apart
+// from schema_version, fields that were empty, and run IDs suffixed to make
+// them unique.
+func verifyLedgerMigration(ctx context.Context, originalPath, migratedPath string) error {
+	migrated, err := os.Open(migratedPath)
+	if err != nil {
+		return err
+	}
+	defer migrated.Close()
+	next := bufio.NewScanner(migrated)
+	next.Buffer(make([]byte, 0, 64*1024), ledgerScanMaxBytes)
+	nextLine := func() ([]byte, bool) {
+		for next.Scan() {
+			if line := strings.TrimSpace(next.Text()); line != "" {
+				return []byte(line), true
+			}
+		}
+		return nil, false
+	}
+
+	runIDs := map[string]bool{}
+	count := 0
+	err = forEachLedgerLine(ctx, originalPath, nil, func(_ int, line []byte) error {
+		count++
+		upgraded, ok := nextLine()
+		if !ok {
+			return fmt.Errorf("entry count changed: migrated log ends after %d entries", count-1)
+		}
+		return verifyLedgerEntry(count, line, upgraded, runIDs)
+	})
+	if err != nil {
+		return err
+	}
+	if _, extra := nextLine(); extra {
+		return fmt.Errorf("entry count changed: migrated log has more than %d entries", count)
+	}
+	return next.Err()
+}
+
+func verifyLedgerEntry(n int, original, migrated []byte, runIDs map[string]bool) error {
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(original, &before); err != nil {
+		return fmt.Errorf("entry %d: %w", n, err)
+	}
+	if err := json.Unmarshal(migrated, &after); err != nil {
+		return fmt.Errorf("entry %d: %w", n, err)
+	}
+	if after["schema_version"] != ledgerSchemaVersion {
+		return fmt.Errorf("entry %d has schema_version %v", n, after["schema_version"])
+	}
+	runID, _ := after["run_id"].(string)
+	if runID == "" {
+		return fmt.Errorf("entry %d has no run_id", n)
+	}
+	if before["schema_version"] != ledgerSchemaVersion && runIDs[runID] {
+		return fmt.Errorf("entry %d reuses run_id %s", n, runID)
+	}
+	runIDs[runID] = true
+	for key, value := range before {
+		if key == "schema_version" || value == "" || value == nil {
+			continue
+		}
+		if old, ok := value.(string); ok && key == "run_id" && strings.HasPrefix(runID, old+"-") {
+			continue // suffixed to make a duplicate unique
+		}
+		if !reflect.DeepEqual(value, after[key]) {
+			return fmt.Errorf("entry %d changed field %s", n, key)
+		}
+	}
+	return nil
+}
+
+// countLegacyLedgerEntries reports how many entries obi ledger migrate
+// would upgrade; a missing log has none.
+func countLegacyLedgerEntries(path string) (int, error) {
+	scan, err := scanLedgerForMigration(path)
+	if err != nil {
+		if errors.Is(err, errLedgerNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return scan.legacy, nil
+}
+
+func copyFileSynced(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// syncDir flushes a rename to disk. Some platforms cannot fsync a
+// directory, so failures are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+}
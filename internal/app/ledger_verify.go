@@ -0,0 +1,400 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/footer"
+)
+
+// Problem kinds reported by obi ledger verify.
+const (
+	ledgerProblemCorrupt       = "corrupt"
+	ledgerProblemSchema        = "schema"
+	ledgerProblemRunID         = "run_id"
+	ledgerProblemMissingBead   = "missing_bead_id"
+	ledgerProblemMissingStatus = "missing_status"
+	ledgerProblemUnknownStatus = "unknown_status"
+	ledgerProblemBlocksResume  = "blocks_resume"
+)
+
+// ledgerProblem is one finding of obi ledger verify. Fixable problems are
+// the ones --fix repairs without guessing: corrupt lines are moved aside,
+// duplicate or missing run IDs are renamed, and a missing bead ID is filled
+// in when the commit text names exactly one bead of the epic.
+type ledgerProblem struct {
+	Line    int
+	Kind    string
+	Detail  string
+	Fixable bool
+}
+
+// ledgerVerification is the outcome of a full scan of the results log.
+type ledgerVerification struct {
+	Entries  int
+	Problems []ledgerProblem
+}
+
+func (v ledgerVerification) fixable() int {
+	n := 0
+	for _, problem := range v.Problems {
+		if problem.Fixable {
+			n++
+		}
+	}
+	return n
+}
+
+func runLedgerVerify(args []string) error {
+	fs := newCommandFlags("ledger verify", "obi ledger verify [options]",
+		"Scan the whole results log for corrupt lines, schema drift, duplicate run IDs, successes\nwithout a bead ID, and entries that would make --resume or the summary fail.")
+	var configPath string
+	var fix, noBackup bool
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.BoolVar(&fix, "fix", false, "repair the problems that are safe to fix; the rest are only reported")
+	fs.BoolVar(&noBackup, "no-backup", false, "with --fix, skip the timestamped backup of the original log")
+	if _, err := fs.parse(args); err != nil {
+		return err
+	}
+
+	_, cfg, err := loadConfig(configPath, "")
+	if err != nil {
+		return err
+	}
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	v, err := verifyLedger(ctx, logPath)
+	if err != nil {
+		return err
+	}
+	if fix && v.fixable() > 0 {
+		repair, err := repairLedger(ctx, logPath, ledgerMigrateOptions{Backup: !noBackup, Now: time.Now()})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: repaired %d problems\n", logPath, repair.Fixed)
+		if repair.BackupPath != "" {
+			fmt.Printf("Backup: %s\n", repair.BackupPath)
+		}
+		if repair.QuarantinePath != "" {
+			fmt.Printf("Corrupt lines: %s\n", repair.QuarantinePath)
+		}
+		if v, err = verifyLedger(ctx, logPath); err != nil {
+			return err
+		}
+	}
+
+	if len(v.Problems) == 0 {
+		fmt.Printf("%s: %d entries, no problems found\n", logPath, v.Entries)
+		return nil
+	}
+	fmt.Printf("%s: %d entries, %d problems\n\n", logPath, v.Entries, len(v.Problems))
+	fmt.Print(formatLedgerProblems(v.Problems))
+	if n := v.fixable(); n > 0 {
+		fmt.Printf("\n%d can be repaired with obi ledger verify --fix.\n", n)
+	}
+	for _, problem := range v.Problems {
+		if problem.Kind == ledgerProblemSchema {
+			fmt.Println("Run obi ledger migrate to upgrade entries on an older schema.")
+			break
+		}
+	}
+	return newExitError(fmt.Sprintf("results log has %d problems", len(v.Problems)))
+}
+
+func formatLedgerProblems(problems []ledgerProblem) string {
+	rows := [][]string{{"Line", "Problem", "Detail", "Fix"}}
+	for _, problem := range problems {
+		fix := "manual"
+		if problem.Fixable {
+			fix = "--fix"
+		}
+		rows = append(rows, []string{strconv.Itoa(problem.Line), problem.Kind, problem.Detail, fix})
+	}
+	return formatTextTable(rows)
+}
+
+// verifyLedger streams the results log and reports every problem it finds.
+// It never writes.
+func verifyLedger(ctx context.Context, path string) (ledgerVerification, error) {
+	var v ledgerVerification
+	checker := newLedgerChecker()
+	err := forEachLedgerLine(ctx, path, nil, func(n int, line []byte) error {
+		v.Entries++
+		problems, _ := checker.check(n, line)
+		v.Problems = append(v.Problems, problems...)
+		return nil
+	})
+	if err != nil {
+		return ledgerVerification{}, err
+	}
+	v.Problems = append(v.Problems, checker.resumeBlockers()...)
+	sort.SliceStable(v.Problems, func(i, j int) bool { return v.Problems[i].Line < v.Problems[j].Line })
+	return v, nil
+}
+
+// ledgerRepair is the outcome of obi ledger verify --fix.
+type ledgerRepair struct {
+	Fixed          int
+	BackupPath     string
+	QuarantinePath string
+}
+
+// repairLedger rewrites the results log with the fixable problems repaired,
+// using the same backup, temporary file, and rename obi ledger migrate
+// does. Corrupt lines are moved to <path>.corrupt-<timestamp> rather than
+// deleted.
+func repairLedger(ctx context.Context, path string, opts ledgerMigrateOptions) (ledgerRepair, error) {
+	before, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ledgerRepair{}, fmt.Errorf("%w: %s", errLedgerNotFound, path)
+		}
+		return ledgerRepair{}, err
+	}
+	// As in migrateLedger, the lock makes a finishing run wait for the
+	// rename instead of appending an entry it would lose.
+	lock, err := lockLedger(path)
+	if err != nil {
+		return ledgerRepair{}, err
+	}
+	defer lock.Close()
+	perm := before.Mode().Perm()
+	stamp := opts.Now.UTC().Format("20060102T150405Z")
+	var r ledgerRepair
+	if opts.Backup {
+		r.BackupPath = fmt.Sprintf("%s.bak-%s", path, stamp)
+		if err := copyFileSynced(path, r.BackupPath, perm); err != nil {
+			os.Remove(r.BackupPath)
+			return ledgerRepair{}, fmt.Errorf("back up results log: %w", err)
+		}
+	}
+
+	temp := path + ".repair"
+	var corrupt []byte
+	fail := func(err error) (ledgerRepair, error) {
+		os.Remove(temp)
+		return ledgerRepair{}, err
+	}
+	out, err := os.OpenFile(temp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return ledgerRepair{}, fmt.Errorf("create repaired log: %w", err)
+	}
+	defer out.Close()
+	w := bufio.NewWriter(out)
+	checker := newLedgerChecker()
+	err = forEachLedgerLine(ctx, path, nil, func(n int, line []byte) error {
+		problems, repaired := checker.check(n, line)
+		for _, problem := range problems {
+			if problem.Fixable {
+				r.Fixed++
+			}
+		}
+		if repaired == nil {
+			corrupt = append(append(corrupt, line...), '\n')
+			return nil
+		}
+		if _, err := w.Write(repaired); err != nil {
+			return fmt.Errorf("write repaired log: %w", err)
+		}
+		return w.WriteByte('\n')
+	})
+	if err != nil {
+		return fail(err)
+	}
+	if err := w.Flush(); err != nil {
+		return fail(fmt.Errorf("write repaired log: %w", err))
+	}
+	if err := out.Sync(); err != nil {
+		return fail(fmt.Errorf("sync repaired log: %w", err))
+	}
+	if err := out.Close(); err != nil {
+		return fail(fmt.Errorf("write repaired log: %w", err))
+	}
+	if len(corrupt) > 0 {
+		r.QuarantinePath = fmt.Sprintf("%s.corrupt-%s", path, stamp)
+		if err := os.WriteFile(r.QuarantinePath, corrupt, perm); err != nil {
+			return fail(fmt.Errorf("save corrupt lines: %w", err))
+		}
+	}
+	if ctx.Err() != nil {
+		return fail(errMigrationInterrupted)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		return fail(fmt.Errorf("replace results log: %w", err))
+	}
+	syncDir(filepath.Dir(path))
+	os.Remove(attemptIndexPath(path))
+	return r, nil
+}
+
+// ledgerChecker carries the state a scan needs across lines: the run IDs
+// seen so far and, per epic, the needs_help entries that stop --resume.
+type ledgerChecker struct {
+	runIDs   map[string]int
+	blockers map[string][]ledgerProblem
+	epics    []string
+}
+
+func newLedgerChecker() *ledgerChecker {
+	return &ledgerChecker{runIDs: map[string]int{}, blockers: map[string][]ledgerProblem{}}
+}
+
+// check inspects line n and returns its problems together with the line as
+// --fix would write it, or nil when the line should be moved aside.
+func (c *ledgerChecker) check(n int, line []byte) ([]ledgerProblem, []byte) {
+	var fields map[string]json.RawMessage
+	var entry ledgerEntry
+	if err := json.Unmarshal(line, &fields); err != nil || fields == nil {
+		return []ledgerProblem{{Line: n, Kind: ledgerProblemCorrupt, Detail: corruptDetail(err), Fixable: true}}, nil
+	}
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return []ledgerProblem{{Line: n, Kind: ledgerProblemCorrupt, Detail: corruptDetail(err), Fixable: true}}, nil
+	}
+
+	var problems []ledgerProblem
+	updates := map[string]string{}
+	if entry.SchemaVersion != ledgerSchemaVersion {
+		version := entry.SchemaVersion
+		if version == "" {
+			version = "none"
+		}
+		problems = append(problems, ledgerProblem{Line: n, Kind: ledgerProblemSchema, Detail: fmt.Sprintf("schema_version %s, expected %s", version, ledgerSchemaVersion)})
+	} else {
+		// Older entries get their run IDs from obi ledger migrate.
+		runID := strings.TrimSpace(entry.RunID)
+		switch first, dup := c.runIDs[runID]; {
+		case runID == "":
+			fixed := c.claimRunID(legacyRunID(entry, line), n)
+			problems = append(problems, ledgerProblem{Line: n, Kind: ledgerProblemRunID, Detail: "missing run_id; --fix sets " + fixed, Fixable: true})
+			updates["run_id"] = fixed
+		case dup:
+			fixed := c.claimRunID(runID, n)
+			problems = append(problems, ledgerProblem{Line: n, Kind: ledgerProblemRunID, Detail: fmt.Sprintf("run_id %s also on line %d; --fix renames it %s", runID, first, fixed), Fixable: true})
+			updates["run_id"] = fixed
+		default:
+			c.runIDs[runID] = n
+		}
+	}
+
+	if !entry.Exploratory {
+		switch status := strings.ToLower(strings.TrimSpace(entry.Status)); status {
+		case "":
+			problems = append(problems, ledgerProblem{Line: n, Kind: ledgerProblemMissingStatus, Detail: fmt.Sprintf("session %s has no status; --resume refuses the epic", entry.SessionID)})
+		case footer.StatusSuccess:
+			if strings.TrimSpace(entry.BeadID) != "" {
+				break
+			}
+			if bead := inferLedgerBeadID(entry); bead != "" {
+				problems = append(problems, ledgerProblem{Line: n, Kind: ledgerProblemMissingBead, Detail: fmt.Sprintf("success without bead_id; commit text names %s", bead), Fixable: true})
+				updates["bead_id"] = bead
+			} else {
+				problems = append(problems, ledgerProblem{Line: n, Kind: ledgerProblemMissingBead, Detail: fmt.Sprintf("success without bead_id (session %s); --resume refuses the epic", entry.SessionID)})
+			}
+		case footer.StatusFailure:
+			c.noteBlocker(entry, n)
+		case ledgerStatusUnparsed:
+			// Recorded on purpose; resume simply does not count it.
+		default:
+			problems = append(problems, ledgerProblem{Line: n, Kind: ledgerProblemUnknownStatus, Detail: fmt.Sprintf("status %q is not success, needs_help, or unparsed", entry.Status)})
+		}
+	}
+
+	if len(updates) == 0 {
+		return problems, line
+	}
+	for key, value := range updates {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return problems, line
+		}
+		fields[key] = encoded
+	}
+	repaired, err := json.Marshal(fields)
+	if err != nil {
+		return problems, line
+	}
+	return problems, repaired
+}
+
+// claimRunID suffixes id until it is unused and records it as seen on n.
+func (c *ledgerChecker) claimRunID(id string, n int) string {
+	candidate := id
+	for i := 2; ; i++ {
+		if _, taken := c.runIDs[candidate]; !taken {
+			break
+		}
+		candidate = id + "-" + strconv.Itoa(i)
+	}
+	c.runIDs[candidate] = n
+	return candidate
+}
+
+func (c *ledgerChecker) noteBlocker(entry ledgerEntry, n int) {
+	epic := strings.ToLower(strings.TrimSpace(entry.EpicID))
+	if _, ok := c.blockers[epic]; !ok {
+		c.epics = append(c.epics, epic)
+	}
+	bead := strings.TrimSpace(entry.BeadID)
+	if bead == "" {
+		bead = "unknown bead"
+	}
+	c.blockers[epic] = append(c.blockers[epic], ledgerProblem{
+		Line:   n,
+		Kind:   ledgerProblemBlocksResume,
+		Detail: fmt.Sprintf("needs_help on %s (session %s) stops --resume and the summary for %s", bead, entry.SessionID, entry.EpicID),
+	})
+}
+
+// resumeBlockers lists the needs_help entries found. Like --resume itself,
+// it does not let a later success on the same bead clear one.
+func (c *ledgerChecker) resumeBlockers() []ledgerProblem {
+	var problems []ledgerProblem
+	for _, epic := range c.epics {
+		problems = append(problems, c.blockers[epic]...)
+	}
+	return problems
+}
+
+// inferLedgerBeadID returns the one bead of the entry's epic its commit
+// text names, or "" when it names none or several.
+func inferLedgerBeadID(entry ledgerEntry) string {
+	epic := strings.TrimSpace(entry.EpicID)
+	if epic == "" || epic == "issues" {
+		return ""
+	}
+	pattern := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(epic) + `\.[a-z0-9][a-z0-9.-]*`)
+	found := ""
+	for _, match := range pattern.FindAllString(entry.CommitSummary+"\n"+entry.CommitDetails, -1) {
+		match = strings.TrimRight(match, ".-")
+		if found != "" && !strings.EqualFold(found, match) {
+			return ""
+		}
+		found = match
+	}
+	return found
+}
+
+func corruptDetail(err error) string {
+	if err == nil {
+		return "not a JSON object"
+	}
+	return truncate(err.Error(), 80)
+}
@@ -3,24 +3,24 @@ package app
 import (
 	"bytes"
 	"encoding/json"
-	"flag"
+	"errors"
 	"fmt"
-	"io"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
 )
 
 func runList(args []string) error {
-	fs := flag.NewFlagSet("list", flag.ContinueOnError)
-	fs.SetOutput(io.Discard)
+	fs := newCommandFlags("list", "obi list [options]", "Show available epics, aliases, and ready/total bead counts.")
 	var configPath string
 	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
-	if err := fs.Parse(args); err != nil {
-		return fmt.Errorf("parse flags: %w", err)
+	if _, err := fs.parse(args); err != nil {
+		return err
 	}
 
 	resolved, err := config.ResolvePath(configPath)
@@ -32,6 +32,8 @@ func runList(args []string) error {
 		return err
 	}
 
+	maybeSyncBeads(cfg, repoRootForConfig(resolved))
+
 	readyIssues, readyErr := fetchReadyIssues()
 	loose := summarizeLooseIssues(readyIssues, readyErr)
 	printLooseIssuesBlock(cfg, loose)
@@ -50,6 +52,12 @@ func runList(args []string) error {
 	repoPath := repoRootForConfig(resolved)
 	fmt.Printf("Epics in %s:\n", repoPath)
 	rows := buildEpicRows(cfg.Epics, readyCounts, totalCounts)
+	entries, ledgerErr := loadListLedger(cfg)
+	if ledgerErr == nil {
+		now := time.Now()
+		attachNeedsHelp(rows, summarizeNeedsHelp(entries, openIssues), now)
+		attachLastRuns(rows, entries, now)
+	}
 	fmt.Print(formatEpicRows(rows))
 
 	if readyErr != nil {
@@ -58,6 +66,9 @@ func runList(args []string) error {
 	if openErr != nil {
 		fmt.Printf("\nOpen-counts unavailable: %s\n", openErr)
 	}
+	if ledgerErr != nil {
+		fmt.Printf("\nNeeds-help counts and last runs unavailable: %s\n", ledgerErr)
+	}
 
 	warnings := collectZeroReady(rows)
 	if len(warnings) > 0 {
@@ -107,9 +118,19 @@ type epicRow struct {
 	Alias      string
 	Name       string
 	EpicID     string
+	Group      string
 	ReadyCount *int
 	TotalCount *int
 	Warn       bool
+	// NeedsHelp counts beads whose latest run ended in needs_help;
+	// NeedsHelpAge is how long the oldest of them has been waiting.
+	NeedsHelp    *int
+	NeedsHelpAge time.Duration
+	// LastRun is the epic's most recent ledger entry; LastRunKnown is false
+	// when the results log could not be read.
+	LastRun      *ledgerEntry
+	LastRunAge   time.Duration
+	LastRunKnown bool
 }
 
 func buildEpicRows(epics map[string]config.EpicConfig, readyCounts, totalCounts map[string]int) []epicRow {
@@ -123,6 +144,7 @@ func buildEpicRows(epics map[string]config.EpicConfig, readyCounts, totalCounts
 			Alias:  epicAliasHandle(key, epic),
 			Name:   epic.Name,
 			EpicID: epic.ID,
+			Group:  strings.ToLower(strings.TrimSpace(epic.Group)),
 		}
 		if readyCounts != nil {
 			val := readyCounts[epic.ID]
@@ -146,17 +168,29 @@ func formatEpicRows(rows []epicRow) string {
 	}
 	aliasWidth := len("Alias")
 	readyWidth := len("Ready/Total")
+	helpWidth := len("Needs help")
+	lastWidth := len("Last run")
 	nameWidth := len("Name")
 	idWidth := len("Epic ID")
 	readyTexts := make([]string, len(rows))
+	helpTexts := make([]string, len(rows))
+	lastTexts := make([]string, len(rows))
 	for i, row := range rows {
 		readyTexts[i] = readyTotalText(row)
+		helpTexts[i] = needsHelpText(row)
+		lastTexts[i] = lastRunText(row)
 		if len(row.Alias) > aliasWidth {
 			aliasWidth = len(row.Alias)
 		}
 		if len(readyTexts[i]) > readyWidth {
 			readyWidth = len(readyTexts[i])
 		}
+		if len(helpTexts[i]) > helpWidth {
+			helpWidth = len(helpTexts[i])
+		}
+		if len(lastTexts[i]) > lastWidth {
+			lastWidth = len(lastTexts[i])
+		}
 		if len(row.Name) > nameWidth {
 			nameWidth = len(row.Name)
 		}
@@ -164,25 +198,118 @@ func formatEpicRows(rows []epicRow) string {
 			idWidth = len(row.EpicID)
 		}
 	}
+	groups := groupEpicRows(rows)
+	subtotals := make([][2]string, len(groups))
+	for gi, group := range groups {
+		sum := subtotalRow(rows, group.Rows)
+		subtotals[gi] = [2]string{readyTotalText(sum), needsHelpText(sum)}
+		if len(subtotals[gi][0]) > readyWidth {
+			readyWidth = len(subtotals[gi][0])
+		}
+	}
 	var b strings.Builder
-	fmt.Fprintf(&b, "  %-*s  %-*s  %-*s  %-*s\n", aliasWidth, "Alias", readyWidth, "Ready/Total", nameWidth, "Name", idWidth, "Epic ID")
-	fmt.Fprintf(&b, "  %-*s  %-*s  %-*s  %-*s\n",
+	fmt.Fprintf(&b, "  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s\n", aliasWidth, "Alias", readyWidth, "Ready/Total", helpWidth, "Needs help", lastWidth, "Last run", nameWidth, "Name", idWidth, "Epic ID")
+	fmt.Fprintf(&b, "  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s\n",
 		aliasWidth, strings.Repeat("-", aliasWidth),
 		readyWidth, strings.Repeat("-", readyWidth),
+		helpWidth, strings.Repeat("-", helpWidth),
+		lastWidth, strings.Repeat("-", lastWidth),
 		nameWidth, strings.Repeat("-", nameWidth),
 		idWidth, strings.Repeat("-", idWidth),
 	)
-	for i, row := range rows {
-		fmt.Fprintf(&b, "  %-*s  %-*s  %-*s  %-*s\n",
-			aliasWidth, row.Alias,
-			readyWidth, readyTexts[i],
-			nameWidth, row.Name,
-			idWidth, row.EpicID,
-		)
+	for gi, group := range groups {
+		grouped := len(groups) > 1 || group.Name != ""
+		if grouped {
+			label := "(no group)"
+			if group.Name != "" {
+				label = groupPrefix + group.Name
+			}
+			fmt.Fprintf(&b, "  %s\n", label)
+		}
+		for _, i := range group.Rows {
+			row := rows[i]
+			fmt.Fprintf(&b, "  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s\n",
+				aliasWidth, row.Alias,
+				readyWidth, readyTexts[i],
+				helpWidth, helpTexts[i],
+				lastWidth, lastTexts[i],
+				nameWidth, row.Name,
+				idWidth, row.EpicID,
+			)
+		}
+		if grouped {
+			fmt.Fprintf(&b, "  %-*s  %-*s  %s\n", aliasWidth, "subtotal", readyWidth, subtotals[gi][0], subtotals[gi][1])
+		}
 	}
 	return b.String()
 }
 
+type epicRowGroup struct {
+	Name string
+	Rows []int
+}
+
+// groupEpicRows partitions row indexes by group name, sorted, with
+// ungrouped epics last.
+func groupEpicRows(rows []epicRow) []epicRowGroup {
+	index := map[string]int{}
+	var groups []epicRowGroup
+	for i, row := range rows {
+		gi, ok := index[row.Group]
+		if !ok {
+			gi = len(groups)
+			index[row.Group] = gi
+			groups = append(groups, epicRowGroup{Name: row.Group})
+		}
+		groups[gi].Rows = append(groups[gi].Rows, i)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].Name == "") != (groups[j].Name == "") {
+			return groups[j].Name == ""
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// subtotalRow sums the counts of the given rows; a count any row is missing
+// stays unknown in the sum.
+func subtotalRow(rows []epicRow, indexes []int) epicRow {
+	var sum epicRow
+	ready, total, help := 0, 0, 0
+	readyKnown, totalKnown, helpKnown := true, true, true
+	for _, i := range indexes {
+		if rows[i].ReadyCount == nil {
+			readyKnown = false
+		} else {
+			ready += *rows[i].ReadyCount
+		}
+		if rows[i].TotalCount == nil {
+			totalKnown = false
+		} else {
+			total += *rows[i].TotalCount
+		}
+		if rows[i].NeedsHelp == nil {
+			helpKnown = false
+		} else {
+			help += *rows[i].NeedsHelp
+			if rows[i].NeedsHelpAge > sum.NeedsHelpAge {
+				sum.NeedsHelpAge = rows[i].NeedsHelpAge
+			}
+		}
+	}
+	if readyKnown {
+		sum.ReadyCount = ptrInt(ready)
+	}
+	if totalKnown {
+		sum.TotalCount = ptrInt(total)
+	}
+	if helpKnown {
+		sum.NeedsHelp = ptrInt(help)
+	}
+	return sum
+}
+
 func readyTotalText(row epicRow) string {
 	ready := "?"
 	total := "?"
@@ -199,6 +326,111 @@ func readyTotalText(row epicRow) string {
 	return text
 }
 
+func needsHelpText(row epicRow) string {
+	switch {
+	case row.NeedsHelp == nil:
+		return "?"
+	case *row.NeedsHelp == 0:
+		return "-"
+	}
+	age := strings.TrimSuffix(timeAgo(row.NeedsHelpAge), " ago")
+	return fmt.Sprintf("%d (oldest %s)", *row.NeedsHelp, age)
+}
+
+// lastRunText shows the final status and age of the epic's latest run, or
+// "-" when it has never run.
+func lastRunText(row epicRow) string {
+	switch {
+	case !row.LastRunKnown:
+		return "?"
+	case row.LastRun == nil:
+		return "-"
+	}
+	status := strings.TrimSpace(row.LastRun.Status)
+	if status == "" {
+		status = "unknown"
+	}
+	text := fmt.Sprintf("%s %s", status, timeAgo(row.LastRunAge))
+	if row.LastRun.Exploratory {
+		text += " (read-only)"
+	}
+	return text
+}
+
+// needsHelpBacklog is an epic's beads whose latest run ended in needs_help.
+type needsHelpBacklog struct {
+	Count  int
+	Oldest time.Time
+}
+
+// loadListLedger reads every results-log entry for the epic table; a
+// missing results log means no epic has run yet.
+func loadListLedger(cfg *config.Config) ([]ledgerEntry, error) {
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ledgerEntriesForEpic(logPath, "")
+	if err != nil {
+		if errors.Is(err, errLedgerNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return entries, nil
+}
+
+// summarizeNeedsHelp groups outstanding escalations by lowercase epic ID.
+// Beads bd reports as closed are not counted.
+func summarizeNeedsHelp(entries []ledgerEntry, issues []listIssue) map[string]needsHelpBacklog {
+	closed := map[string]bool{}
+	for _, issue := range issues {
+		if strings.EqualFold(issue.Status, "closed") {
+			closed[strings.ToLower(issue.ID)] = true
+		}
+	}
+	backlog := map[string]needsHelpBacklog{}
+	for _, entry := range openEscalations(entries, closed) {
+		epic := strings.ToLower(strings.TrimSpace(entry.EpicID))
+		item := backlog[epic]
+		item.Count++
+		if item.Oldest.IsZero() || entry.CompletedAt.Before(item.Oldest) {
+			item.Oldest = entry.CompletedAt
+		}
+		backlog[epic] = item
+	}
+	return backlog
+}
+
+func attachNeedsHelp(rows []epicRow, backlog map[string]needsHelpBacklog, now time.Time) {
+	for i := range rows {
+		item := backlog[strings.ToLower(rows[i].EpicID)]
+		rows[i].NeedsHelp = ptrInt(item.Count)
+		if item.Count > 0 {
+			rows[i].NeedsHelpAge = now.Sub(item.Oldest)
+		}
+	}
+}
+
+// attachLastRuns fills each row with the latest ledger entry for its epic.
+func attachLastRuns(rows []epicRow, entries []ledgerEntry, now time.Time) {
+	latest := map[string]*ledgerEntry{}
+	for i := range entries {
+		entry := &entries[i]
+		epic := strings.ToLower(strings.TrimSpace(entry.EpicID))
+		if prev, ok := latest[epic]; !ok || !entry.CompletedAt.Before(prev.CompletedAt) {
+			latest[epic] = entry
+		}
+	}
+	for i := range rows {
+		rows[i].LastRunKnown = true
+		if entry, ok := latest[strings.ToLower(rows[i].EpicID)]; ok {
+			rows[i].LastRun = entry
+			rows[i].LastRunAge = now.Sub(entry.CompletedAt)
+		}
+	}
+}
+
 type zeroReadyWarning struct {
 	Alias   string
 	EpicID  string
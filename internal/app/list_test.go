@@ -3,6 +3,7 @@ package app
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
 )
@@ -84,3 +85,97 @@ func TestCollectZeroReadyIncludesGuardrailMessage(t *testing.T) {
 		t.Fatalf("expected total count 3, got %d", warnings[0].Total)
 	}
 }
+
+func TestFormatEpicRowsGroupsWithSubtotals(t *testing.T) {
+	epics := map[string]config.EpicConfig{
+		"api":  {Name: "API", ID: "obi-api", Alias: "api", Group: "Backend"},
+		"db":   {Name: "DB", ID: "obi-db", Alias: "db", Group: "backend"},
+		"misc": {Name: "Misc", ID: "obi-misc", Alias: "misc"},
+		"ui":   {Name: "UI", ID: "obi-ui", Alias: "ui", Group: "frontend"},
+	}
+	ready := map[string]int{"obi-api": 2, "obi-db": 1, "obi-misc": 0, "obi-ui": 3}
+	total := map[string]int{"obi-api": 4, "obi-db": 3, "obi-misc": 2, "obi-ui": 3}
+	output := formatEpicRows(buildEpicRows(epics, ready, total))
+
+	backend := strings.Index(output, "group:backend")
+	frontend := strings.Index(output, "group:frontend")
+	ungrouped := strings.Index(output, "(no group)")
+	if backend == -1 || frontend == -1 || ungrouped == -1 {
+		t.Fatalf("expected group headers: %s", output)
+	}
+	if !(backend < frontend && frontend < ungrouped) {
+		t.Fatalf("groups should be sorted with ungrouped last: %s", output)
+	}
+	if !strings.Contains(output[backend:frontend], "3/7") {
+		t.Fatalf("expected backend subtotal 3/7: %s", output)
+	}
+}
+
+func TestFormatEpicRowsWithoutGroupsHasNoHeaders(t *testing.T) {
+	rows := buildEpicRows(map[string]config.EpicConfig{"foo": {Name: "Foo", ID: "obi-foo"}}, nil, nil)
+	if output := formatEpicRows(rows); strings.Contains(output, "subtotal") || strings.Contains(output, "(no group)") {
+		t.Fatalf("ungrouped config should render a flat table: %s", output)
+	}
+}
+
+func TestNeedsHelpBacklogInEpicRows(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	entries := []ledgerEntry{
+		{EpicID: "obi-api", BeadID: "obi-api.1", Status: "needs_help", CompletedAt: now.Add(-72 * time.Hour)},
+		{EpicID: "obi-api", BeadID: "obi-api.2", Status: "needs_help", CompletedAt: now.Add(-3 * time.Hour)},
+		{EpicID: "obi-api", BeadID: "obi-api.3", Status: "needs_help", CompletedAt: now.Add(-5 * time.Hour)},
+		{EpicID: "obi-api", BeadID: "obi-api.3", Status: "success", CompletedAt: now.Add(-4 * time.Hour)},
+		{EpicID: "obi-db", BeadID: "obi-db.1", Status: "needs_help", CompletedAt: now.Add(-96 * time.Hour)},
+	}
+	issues := []listIssue{{ID: "obi-db.1", Status: "closed"}}
+	backlog := summarizeNeedsHelp(entries, issues)
+	if got := backlog["obi-api"]; got.Count != 2 || !got.Oldest.Equal(now.Add(-72*time.Hour)) {
+		t.Fatalf("unexpected api backlog: %+v", got)
+	}
+	if _, ok := backlog["obi-db"]; ok {
+		t.Fatalf("closed bead should not count as stuck: %+v", backlog)
+	}
+
+	epics := map[string]config.EpicConfig{
+		"api": {Name: "API", ID: "obi-api", Alias: "api"},
+		"db":  {Name: "DB", ID: "obi-db", Alias: "db"},
+	}
+	rows := buildEpicRows(epics, nil, nil)
+	attachNeedsHelp(rows, backlog, now)
+	output := formatEpicRows(rows)
+	if !strings.Contains(output, "Needs help") || !strings.Contains(output, "2 (oldest 3d)") {
+		t.Fatalf("expected needs-help column: %s", output)
+	}
+	if formatEpicRows(buildEpicRows(epics, nil, nil)) == output {
+		t.Fatalf("rows without ledger data should render unknown counts")
+	}
+}
+
+func TestLastRunColumnInEpicRows(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	entries := []ledgerEntry{
+		{EpicID: "obi-api", Status: "failed", CompletedAt: now.Add(-48 * time.Hour)},
+		{EpicID: "OBI-API", Status: "success", CompletedAt: now.Add(-2 * time.Hour)},
+		{EpicID: "obi-api", Status: "needs_help", CompletedAt: now.Add(-30 * time.Hour)},
+		{EpicID: "obi-docs", Status: "success", Exploratory: true, CompletedAt: now.Add(-72 * time.Hour)},
+	}
+	epics := map[string]config.EpicConfig{
+		"api":  {Name: "API", ID: "obi-api", Alias: "api"},
+		"db":   {Name: "DB", ID: "obi-db", Alias: "db"},
+		"docs": {Name: "Docs", ID: "obi-docs", Alias: "docs"},
+	}
+	rows := buildEpicRows(epics, nil, nil)
+	if got := lastRunText(rows[0]); got != "?" {
+		t.Fatalf("expected unknown last run before the ledger is read, got %q", got)
+	}
+	attachLastRuns(rows, entries, now)
+	want := map[string]string{"api": "success 2h0m ago", "db": "-", "docs": "success 3d ago (read-only)"}
+	for _, row := range rows {
+		if got := lastRunText(row); got != want[row.Alias] {
+			t.Fatalf("%s last run = %q, want %q", row.Alias, got, want[row.Alias])
+		}
+	}
+	if output := formatEpicRows(rows); !strings.Contains(output, "Last run") || !strings.Contains(output, "success 2h0m ago") {
+		t.Fatalf("expected last-run column: %s", output)
+	}
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+)
+
+// logSettings is the diagnostics configuration in force, so a --log-level
+// or --log-file given after the command name can change it once the
+// command's own flag parser has found it.
+var logSettings struct {
+	mu          sync.Mutex
+	level, path string
+	close       func() error
+}
+
+// configureLogging takes the global --log-level and --log-file flags that
+// precede the command name out of args and installs the diagnostics
+// logger. OBI_LOG_LEVEL and OBI_LOG_FILE supply the defaults. The same
+// flags after the command name are handled by commandFlags.parse, which
+// knows which arguments are values of the command's own flags. The
+// returned func closes the log file.
+func configureLogging(args []string) ([]string, func(), error) {
+	level, path := os.Getenv(diag.EnvLevel), os.Getenv(diag.EnvFile)
+	i := 0
+	for ; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") || !isLogFlag(name) {
+			break
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("flag %s requires a value", args[i])
+			}
+			i++
+			value = args[i]
+		}
+		if name == "log-level" {
+			level = value
+		} else {
+			path = value
+		}
+	}
+	if err := installLogging(level, path); err != nil {
+		return nil, nil, err
+	}
+	return args[i:], func() {
+		logSettings.mu.Lock()
+		defer logSettings.mu.Unlock()
+		if err := logSettings.close(); err != nil {
+			fmt.Fprintf(os.Stderr, "obi: log file: %v\n", err)
+		}
+		logSettings.close = func() error { return nil }
+	}, nil
+}
+
+func isLogFlag(name string) bool {
+	return name == "log-level" || name == "log-file"
+}
+
+// setLogFlag applies a global logging flag found among a command's flags.
+func setLogFlag(name, value string) error {
+	logSettings.mu.Lock()
+	level, path := logSettings.level, logSettings.path
+	logSettings.mu.Unlock()
+	if name == "log-level" {
+		level = value
+	} else {
+		path = value
+	}
+	return installLogging(level, path)
+}
+
+func installLogging(level, path string) error {
+	logSettings.mu.Lock()
+	defer logSettings.mu.Unlock()
+	closeFn, err := diag.Configure(level, path)
+	if err != nil {
+		return err
+	}
+	if logSettings.close != nil {
+		if err := logSettings.close(); err != nil {
+			diag.Logger().Warn("log file close failed", "err", err)
+		}
+	}
+	logSettings.level, logSettings.path, logSettings.close = level, path, closeFn
+	return nil
+}
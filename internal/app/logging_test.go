@@ -0,0 +1,53 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+)
+
+func TestConfigureLoggingStripsGlobalFlags(t *testing.T) {
+	defer diag.Set(diag.Logger())()
+	t.Setenv(diag.EnvLevel, "")
+	t.Setenv(diag.EnvFile, "")
+	path := filepath.Join(t.TempDir(), "obi.log")
+
+	rest, closeLog, err := configureLogging([]string{"--log-level", "debug", "--version", "--verbose"})
+	if err != nil {
+		t.Fatalf("configureLogging: %v", err)
+	}
+	if want := []string{"--version", "--verbose"}; !reflect.DeepEqual(rest, want) {
+		t.Fatalf("rest = %q, want %q", rest, want)
+	}
+
+	// After the command name, the command's parser tells a logging flag
+	// from the value of one of its own flags.
+	fs := newCommandFlags("ask", "obi ask", "", "question")
+	var note string
+	fs.StringVar(&note, "note", "", "")
+	positional, err := fs.parse([]string{"--note", "--log-level", "--log-file=" + path, "--", "--log-level"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if note != "--log-level" || !reflect.DeepEqual(positional, []string{"--log-level"}) {
+		t.Fatalf("note = %q, positional = %q", note, positional)
+	}
+	diag.Logger().Debug("probe")
+	closeLog()
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "msg=probe") {
+		t.Fatalf("log = %q, %v", data, err)
+	}
+
+	if _, _, err := configureLogging([]string{"--log-level"}); err == nil || !strings.Contains(err.Error(), "requires a value") {
+		t.Fatalf("missing value err = %v", err)
+	}
+	t.Setenv(diag.EnvLevel, "chatty")
+	if _, _, err := configureLogging([]string{"list"}); err == nil || !strings.Contains(err.Error(), "chatty") {
+		t.Fatalf("env level err = %v", err)
+	}
+}
@@ -3,16 +3,41 @@ package app
 import (
 	"fmt"
 	"io"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
 )
 
+// hintAckLines caps how much of Codex's reply to a hint or soft stop is kept.
+const hintAckLines = 5
+
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\a]*(\a|\x1b\\)`)
+
 type operatorEventKind string
 
 const (
 	operatorEventHint     operatorEventKind = "hint"
 	operatorEventSoftStop operatorEventKind = "soft_stop"
+	// Acknowledgments hold the first lines Codex printed after a hint or
+	// soft stop, so the ledger shows how the agent reacted.
+	operatorEventHintAck     operatorEventKind = "hint_ack"
+	operatorEventSoftStopAck operatorEventKind = "soft_stop_ack"
+	// operatorEventStall labels stall notices shown in the TUI; it is never
+	// recorded in the ledger because no operator acted.
+	operatorEventStall operatorEventKind = "stall"
+	// operatorEventNote records the --note the operator added to the
+	// prompt at launch.
+	operatorEventNote operatorEventKind = "note"
+	// operatorEventDegraded warns in the TUI that Codex runs without a PTY.
+	operatorEventDegraded operatorEventKind = "degraded"
+	// operatorEventEpicState repeats the pre-loop epic snapshot in the TUI.
+	operatorEventEpicState operatorEventKind = "epic_state"
+	// operatorEventEscalation records how an approval request from Codex
+	// was answered, whether by policy or by the operator.
+	operatorEventEscalation operatorEventKind = "escalation"
 )
 
 type operatorEvent struct {
@@ -27,6 +52,17 @@ type operatorLog struct {
 	now      func() time.Time
 	writer   io.Writer
 	writerMu sync.Mutex
+	ack      *ackCapture
+}
+
+// ackCapture collects Codex output following an operator action. When the
+// terminal echoes the injected marker block, the echo is skipped so only the
+// agent's own reply is kept.
+type ackCapture struct {
+	kind        operatorEventKind
+	partial     string
+	lines       []string
+	skipToBlank bool
 }
 
 func newOperatorLog(writer io.Writer) *operatorLog {
@@ -53,6 +89,117 @@ func (l *operatorLog) record(kind operatorEventKind, message string) {
 	l.writeMirror(kind, message)
 }
 
+// expectAck starts capturing Codex's reply to an operator action. Callers
+// invoke it before injecting the marker so a fast echo is not missed, and
+// call cancelAck if the injection fails.
+func (l *operatorLog) expectAck(kind operatorEventKind) {
+	if l == nil {
+		return
+	}
+	ackKind, ok := ackKindFor(kind)
+	if !ok {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushAckLocked("(no output from Codex before the next operator action)")
+	l.ack = &ackCapture{kind: ackKind}
+}
+
+func (l *operatorLog) cancelAck() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ack = nil
+}
+
+func ackKindFor(kind operatorEventKind) (operatorEventKind, bool) {
+	switch kind {
+	case operatorEventHint:
+		return operatorEventHintAck, true
+	case operatorEventSoftStop:
+		return operatorEventSoftStopAck, true
+	default:
+		return "", false
+	}
+}
+
+// Write feeds Codex output to a pending acknowledgment capture. It never
+// fails so it can sit alongside the transcript writer.
+func (l *operatorLog) Write(p []byte) (int, error) {
+	if l == nil {
+		return len(p), nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.ack == nil {
+		return len(p), nil
+	}
+	text := l.ack.partial + strings.ReplaceAll(string(p), "\r", "\n")
+	parts := strings.Split(text, "\n")
+	l.ack.partial = parts[len(parts)-1]
+	for _, line := range parts[:len(parts)-1] {
+		l.observeAckLineLocked(line)
+		if l.ack == nil {
+			break
+		}
+	}
+	return len(p), nil
+}
+
+func (l *operatorLog) observeAckLineLocked(line string) {
+	line = strings.TrimSpace(ansiSequence.ReplaceAllString(line, ""))
+	if strings.Contains(line, interactive.HumanHintMarker) || strings.Contains(line, interactive.SoftStopMarker) {
+		l.ack.lines = nil
+		l.ack.skipToBlank = true
+		return
+	}
+	if line == "" {
+		l.ack.skipToBlank = false
+		return
+	}
+	if l.ack.skipToBlank {
+		return
+	}
+	l.ack.lines = append(l.ack.lines, line)
+	if len(l.ack.lines) >= hintAckLines {
+		l.flushAckLocked("")
+	}
+}
+
+// finishAcks records whatever reply was captured before the session ended.
+func (l *operatorLog) finishAcks() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.ack != nil && !l.ack.skipToBlank {
+		if rest := strings.TrimSpace(ansiSequence.ReplaceAllString(l.ack.partial, "")); rest != "" {
+			l.ack.lines = append(l.ack.lines, rest)
+		}
+	}
+	l.flushAckLocked("(no output from Codex before the session ended)")
+}
+
+func (l *operatorLog) flushAckLocked(emptyNote string) {
+	if l.ack == nil {
+		return
+	}
+	message := strings.Join(l.ack.lines, "\n")
+	if message == "" {
+		message = emptyNote
+	}
+	l.entries = append(l.entries, operatorEvent{
+		Kind:    l.ack.kind,
+		Message: message,
+		Time:    l.now(),
+	})
+	l.ack = nil
+}
+
 func (l *operatorLog) events() []operatorEvent {
 	if l == nil {
 		return nil
@@ -98,6 +245,8 @@ func (l *operatorLog) writeMirror(kind operatorEventKind, message string) {
 		label = "operator hint"
 	case operatorEventSoftStop:
 		label = "operator soft-stop"
+	case operatorEventEscalation:
+		label = "escalation"
 	}
 	line := fmt.Sprintf("\n[obi %s] %s\n", label, message)
 	l.writerMu.Lock()
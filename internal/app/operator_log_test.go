@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
 )
 
 func TestOperatorLogLedgerEventsRedactsAndMirrors(t *testing.T) {
@@ -32,3 +34,55 @@ func TestOperatorLogLedgerEventsRedactsAndMirrors(t *testing.T) {
 		t.Fatalf("expected mirror output for hint, got %q", buf.String())
 	}
 }
+
+func TestOperatorLogCapturesReplyAfterEchoedHint(t *testing.T) {
+	log := newOperatorLog(nil)
+	log.now = func() time.Time { return time.Unix(0, 0) }
+
+	log.expectAck(operatorEventHint)
+	log.record(operatorEventHint, "check the migrations")
+	_, _ = log.Write([]byte("\n\n" + interactive.HumanHintMarker + " sess-1\nHint: |\n  check the migrations\n\n"))
+	_, _ = log.Write([]byte("\x1b[32mGot it\x1b[0m, looking at migrations now.\nReading db/"))
+	_, _ = log.Write([]byte("migrate.go\n"))
+	log.finishAcks()
+
+	events := log.ledgerEvents(nil)
+	if len(events) != 2 {
+		t.Fatalf("expected hint plus ack, got %+v", events)
+	}
+	ack := events[1]
+	if ack.Kind != string(operatorEventHintAck) {
+		t.Fatalf("unexpected ack kind %q", ack.Kind)
+	}
+	if ack.Message != "Got it, looking at migrations now.\nReading db/migrate.go" {
+		t.Fatalf("unexpected ack message %q", ack.Message)
+	}
+}
+
+func TestOperatorLogAckStopsAfterLineLimit(t *testing.T) {
+	log := newOperatorLog(nil)
+	log.expectAck(operatorEventSoftStop)
+	for i := 0; i < hintAckLines+3; i++ {
+		_, _ = log.Write([]byte("wrapping up\n"))
+	}
+	log.finishAcks()
+
+	events := log.events()
+	if len(events) != 1 || events[0].Kind != operatorEventSoftStopAck {
+		t.Fatalf("expected a single soft-stop ack, got %+v", events)
+	}
+	if got := strings.Count(events[0].Message, "wrapping up"); got != hintAckLines {
+		t.Fatalf("expected %d captured lines, got %d", hintAckLines, got)
+	}
+}
+
+func TestOperatorLogRecordsSilentAck(t *testing.T) {
+	log := newOperatorLog(nil)
+	log.expectAck(operatorEventHint)
+	log.finishAcks()
+
+	events := log.events()
+	if len(events) != 1 || !strings.Contains(events[0].Message, "no output") {
+		t.Fatalf("expected a no-output ack, got %+v", events)
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
+)
+
+// sessionPhaseRules merges the [phases] config with the built-in heuristics.
+// A configured phase replaces the built-in patterns of the same name (an
+// empty list disables it); new phase names are checked after the built-ins.
+func sessionPhaseRules(overrides map[string][]string) ([]interactive.PhaseRule, error) {
+	configured := make(map[string][]string, len(overrides))
+	for name, patterns := range overrides {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			return nil, fmt.Errorf("[phases] entries need a phase name")
+		}
+		configured[key] = patterns
+	}
+
+	var rules []interactive.PhaseRule
+	addRules := func(phase string, patterns []string) error {
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid [phases] pattern for %s: %w", phase, err)
+			}
+			rules = append(rules, interactive.PhaseRule{Phase: interactive.Phase(phase), Pattern: re})
+		}
+		return nil
+	}
+
+	builtin := map[string]bool{}
+	for _, def := range interactive.DefaultPhasePatterns {
+		name := string(def.Phase)
+		builtin[name] = true
+		patterns, ok := configured[name]
+		if !ok {
+			patterns = []string{def.Pattern}
+		}
+		if err := addRules(name, patterns); err != nil {
+			return nil, err
+		}
+	}
+
+	custom := make([]string, 0, len(configured))
+	for name := range configured {
+		if !builtin[name] {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	for _, name := range custom {
+		if err := addRules(name, configured[name]); err != nil {
+			return nil, err
+		}
+	}
+	if rules == nil {
+		rules = []interactive.PhaseRule{}
+	}
+	return rules, nil
+}
+
+// phaseDurationMillis converts runner phase durations for the ledger.
+func phaseDurationMillis(durations map[interactive.Phase]time.Duration) map[string]int64 {
+	if len(durations) == 0 {
+		return nil
+	}
+	out := make(map[string]int64, len(durations))
+	for phase, d := range durations {
+		out[string(phase)] = d.Milliseconds()
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
+)
+
+func TestSessionPhaseRulesOverridesAndExtendsDefaults(t *testing.T) {
+	rules, err := sessionPhaseRules(map[string][]string{
+		"Testing":   {`\bbats\b`},
+		"planning":  {},
+		"reviewing": {`(?i)self-review`},
+	})
+	if err != nil {
+		t.Fatalf("sessionPhaseRules: %v", err)
+	}
+	classify := func(line string) interactive.Phase {
+		for _, rule := range rules {
+			if rule.Pattern.MatchString(line) {
+				return rule.Phase
+			}
+		}
+		return ""
+	}
+	cases := map[string]interactive.Phase{
+		"bats test/obi.bats":   interactive.PhaseTesting,
+		"go test ./...":        "",
+		"bd ready --json":      "",
+		"git commit -m wip":    interactive.PhaseCommitting,
+		"Starting self-review": "reviewing",
+	}
+	for line, want := range cases {
+		if got := classify(line); got != want {
+			t.Fatalf("classify(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestSessionPhaseRulesRejectsBadPattern(t *testing.T) {
+	_, err := sessionPhaseRules(map[string][]string{"testing": {"("}})
+	if err == nil || !strings.Contains(err.Error(), "invalid [phases] pattern for testing") {
+		t.Fatalf("expected pattern error, got %v", err)
+	}
+}
+
+func TestPhaseDurationMillis(t *testing.T) {
+	if got := phaseDurationMillis(nil); got != nil {
+		t.Fatalf("expected nil for no phases, got %v", got)
+	}
+	got := phaseDurationMillis(map[interactive.Phase]time.Duration{interactive.PhaseEditing: 1500 * time.Millisecond})
+	if got["editing"] != 1500 {
+		t.Fatalf("unexpected millis %v", got)
+	}
+}
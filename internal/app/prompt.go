@@ -2,7 +2,12 @@ package app
 
 import (
 	"fmt"
+	"os"
+	"sort"
 	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
 )
 
 const (
@@ -20,38 +25,161 @@ const (
 - Only emit STATUS: success after the bead is closed. Otherwise emit STATUS: needs_help with ESCALATION explaining the blocker.`
 )
 
+// The ready section lists at most readyListMaxBeads beads and cuts titles
+// at readyListTitleChars so a large backlog cannot crowd out the prompt.
+const (
+	readyListMaxBeads   = 30
+	readyListTitleChars = 100
+)
+
+// promptSessionPlaceholder stands in for the per-run session UUID in prompts
+// printed by obi prompt, so the output is stable across invocations.
+const promptSessionPlaceholder = "<session-id>"
+
+// promptSection is one named block of the composed session prompt.
+type promptSection struct {
+	Name string
+	Text string
+}
+
 // buildPrompt merges base prompt text, epic-specific prompt, and metadata.
 func buildPrompt(plan sessionPlan) string {
 	if plan.Mode == sessionModeSummary {
 		return buildSummaryPrompt(plan)
 	}
 
-	var sections []string
+	var texts []string
+	for _, section := range promptSections(plan) {
+		texts = append(texts, section.Text)
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n\n"))
+}
+
+// promptSections lists the work-mode prompt blocks in the order buildPrompt
+// joins them; empty optional blocks are omitted.
+func promptSections(plan sessionPlan) []promptSection {
+	var sections []promptSection
 
 	if trimmed := strings.TrimSpace(plan.BasePrompt); trimmed != "" {
-		sections = append(sections, trimmed)
+		sections = append(sections, promptSection{Name: "base", Text: trimmed})
 	}
 	if trimmed := strings.TrimSpace(plan.EpicPrompt); trimmed != "" {
-		sections = append(sections, trimmed)
+		sections = append(sections, promptSection{Name: "epic", Text: trimmed})
+	}
+	if text := formatContextFiles(plan.ContextFiles); text != "" {
+		sections = append(sections, promptSection{Name: "context", Text: text})
+	}
+	if note := strings.TrimSpace(plan.OperatorNote); note != "" {
+		sections = append(sections, promptSection{Name: "note", Text: "Operator note (this run only):\n" + note})
 	}
 
 	metaLines := []string{fmt.Sprintf("Epic ID: %s", plan.EpicID)}
+	if plan.Mode == sessionModeTemplate {
+		metaLines = []string{fmt.Sprintf("Template: %s", plan.Alias)}
+	}
 	if plan.Tool != "" {
 		metaLines = append(metaLines, fmt.Sprintf("Tool: %s", plan.Tool))
 	}
 
-	sections = append(sections, strings.Join(metaLines, "\n"))
+	sections = append(sections, promptSection{Name: "metadata", Text: strings.Join(metaLines, "\n")})
+
+	if text := formatReadyList(plan); text != "" {
+		sections = append(sections, promptSection{Name: "ready", Text: text})
+	}
+
+	if instructions := continuationInstructions(plan); instructions != "" {
+		sections = append(sections, promptSection{Name: "continue", Text: instructions})
+	}
 
 	if instructions := resumeInstructions(plan); instructions != "" {
-		sections = append(sections, instructions)
+		sections = append(sections, promptSection{Name: "resume", Text: instructions})
+	}
+
+	if len(plan.SkippedBeads) > 0 {
+		text := "The operator skipped these beads for the rest of this run; do not pick them:\n- " + strings.Join(plan.SkippedBeads, "\n- ")
+		sections = append(sections, promptSection{Name: "skipped", Text: text})
 	}
 
-	sections = append(sections, completionContract(plan))
+	sections = append(sections, promptSection{Name: "contract", Text: completionContract(plan)})
 
-	return strings.TrimSpace(strings.Join(sections, "\n\n"))
+	return sections
+}
+
+// loadReadyList fills plan.ReadyBeads from bd ready --json when [prompt]
+// include_ready_list is set: the epic's ready beads, or loose issues for
+// the issues target, minus epics and beads resume already finished.
+// Template chores never consult bd and experiments are pinned to one bead,
+// so they get none. A
+// failed lookup only warns, since Codex can still run bd ready itself.
+func loadReadyList(plan *sessionPlan, cfg *config.Config) {
+	plan.ReadyBeads = nil
+	if !cfg.Prompt.IncludeReadyList || plan.ReadOnly || plan.Experiment != nil || plan.Mode != sessionModeWork {
+		return
+	}
+	issues, err := fetchReadyIssues()
+	if err != nil {
+		fmt.Printf("Warning: ready list left out of the prompt (%v).\n", err)
+		return
+	}
+	plan.ReadyBeads = readyBeadsForPlan(*plan, issues)
+}
+
+func readyBeadsForPlan(plan sessionPlan, issues []readyIssue) []readyIssue {
+	loose := plan.EpicID == "" || plan.EpicID == "issues"
+	skip := plan.resumeSkipSet()
+	var beads []readyIssue
+	for _, issue := range issues {
+		if strings.EqualFold(issue.IssueType, "epic") {
+			continue
+		}
+		if loose {
+			if parentEpicID(issue.ID) != "" {
+				continue
+			}
+		} else if !issueBelongsToEpic(issue.ID, plan.EpicID) {
+			continue
+		}
+		if _, done := skip[strings.ToLower(issue.ID)]; done {
+			continue
+		}
+		beads = append(beads, issue)
+	}
+	return beads
+}
+
+func formatReadyList(plan sessionPlan) string {
+	if len(plan.ReadyBeads) == 0 {
+		return ""
+	}
+	lines := []string{fmt.Sprintf("Ready beads at launch (bd ready --json, %d):", len(plan.ReadyBeads))}
+	for i, bead := range plan.ReadyBeads {
+		if i == readyListMaxBeads {
+			lines = append(lines, fmt.Sprintf("- … %d more; run bd ready --json for the full list.", len(plan.ReadyBeads)-i))
+			break
+		}
+		line := "- " + bead.ID
+		if kind := strings.TrimSpace(bead.IssueType); kind != "" {
+			line += " [" + kind + "]"
+		}
+		if title := strings.TrimSpace(firstLine(bead.Title)); title != "" {
+			line += " " + truncate(title, readyListTitleChars)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "The list may be stale by the time you read it; claiming a bead still goes through bd.")
+	return strings.Join(lines, "\n")
 }
 
 func completionContract(plan sessionPlan) string {
+	if plan.ReadOnly {
+		return exploratoryContract(plan)
+	}
+	if plan.Experiment != nil {
+		return experimentContract(plan)
+	}
+	if plan.Mode == sessionModeTemplate {
+		return templateContract(plan)
+	}
 	if plan.EpicID == "" || plan.EpicID == "issues" {
 		return issuesCompletionContract
 	}
@@ -62,6 +190,11 @@ func completionContract(plan sessionPlan) string {
 	return fmt.Sprintf(epicCompletionTemplate, name, plan.EpicID, plan.EpicID, plan.EpicID, plan.EpicID)
 }
 
+// resumePromptRecentBeads caps how many completed beads the resume section
+// names; older ones are only counted. The ready-bead guardrail and the
+// ready list still use the full skip set.
+const resumePromptRecentBeads = 10
+
 func resumeInstructions(plan sessionPlan) string {
 	if !plan.ResumeEnabled {
 		return ""
@@ -72,7 +205,12 @@ func resumeInstructions(plan sessionPlan) string {
 	lines := []string{
 		"Resume mode is active – skip the beads already finished during this run:",
 	}
-	for _, id := range plan.ResumeCompletedBeads {
+	recent := plan.ResumeCompletedBeads
+	if earlier := len(recent) - resumePromptRecentBeads; earlier > 0 {
+		recent = recent[earlier:]
+		lines = append(lines, fmt.Sprintf("- %d earlier bead(s) already completed (not listed)", earlier))
+	}
+	for _, id := range recent {
 		lines = append(lines, fmt.Sprintf("- %s", id))
 	}
 	return strings.Join(lines, "\n")
@@ -94,6 +232,9 @@ func buildSummaryPrompt(plan sessionPlan) string {
 		}
 	}
 	sections = append(sections, strings.Join(metaLines, "\n"))
+	if text := formatSummaryTypes(plan.SummaryChunks); text != "" {
+		sections = append(sections, text)
+	}
 
 	for _, chunk := range plan.SummaryChunks {
 		if text := formatSummaryChunk(chunk); text != "" {
@@ -106,6 +247,38 @@ func buildSummaryPrompt(plan sessionPlan) string {
 	return strings.TrimSpace(strings.Join(sections, "\n\n"))
 }
 
+// formatSummaryTypes lists the beads under each commit type, most common
+// type first, so the summarizer can group the message by type.
+func formatSummaryTypes(chunks []summaryChunk) string {
+	beads := map[string][]string{}
+	var order []string
+	for _, chunk := range chunks {
+		for _, entry := range chunk.Entries {
+			kind := entry.CommitType
+			if kind == "" {
+				kind = untypedCommit
+			}
+			if _, ok := beads[kind]; !ok {
+				order = append(order, kind)
+			}
+			bead := strings.TrimSpace(entry.BeadID)
+			if bead == "" {
+				bead = "(unidentified bead)"
+			}
+			beads[kind] = append(beads[kind], bead)
+		}
+	}
+	if len(order) == 0 {
+		return ""
+	}
+	sort.SliceStable(order, func(i, j int) bool { return len(beads[order[i]]) > len(beads[order[j]]) })
+	lines := []string{"Commits by type (group the message along these lines):"}
+	for _, kind := range order {
+		lines = append(lines, fmt.Sprintf("- %s (%d): %s", kind, len(beads[kind]), strings.Join(beads[kind], ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func formatSummaryChunk(chunk summaryChunk) string {
 	if len(chunk.Entries) == 0 {
 		return ""
@@ -140,3 +313,94 @@ func indentMultiline(text, prefix string) string {
 	}
 	return strings.Join(lines, "\n")
 }
+
+// reviewablePrompt composes plan's prompt with the session ID replaced by
+// promptSessionPlaceholder.
+func reviewablePrompt(cfg *config.Config, plan sessionPlan) (string, error) {
+	style, err := sessionStyle(cfg, plan)
+	if err != nil {
+		return "", err
+	}
+	return interactive.ComposePrompt(buildPrompt(plan), promptSessionPlaceholder, style.guidance()...) + "\n", nil
+}
+
+func runPrompt(args []string) error {
+	fs := newCommandFlags("prompt", "obi prompt [alias] [options]",
+		"Print the prompt obi go would send for an alias, with the session ID replaced\nby "+promptSessionPlaceholder+" so the output can be reviewed and diffed.", "alias")
+	var configPath, profile, outPath, dirFlag string
+	var envFlags, contextFlags []string
+	var note string
+	var resume, readOnly bool
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.StringVar(&profile, "profile", "", "apply this [profiles.*] overlay (defaults to $OBI_PROFILE)")
+	fs.BoolVar(&resume, "resume", false, "include the resume section as obi go --resume would")
+	fs.BoolVar(&readOnly, "read-only", false, "compose the exploratory prompt obi go --read-only would send")
+	fs.StringVar(&outPath, "out", "", "write the prompt to this file instead of stdout")
+	fs.StringVar(&outPath, "o", "", "shorthand for --out")
+	fs.StringVar(&dirFlag, "dir", "", "resolve as if obi go --dir were given")
+	fs.Var((*envFlag)(&envFlags), "env", "resolve as if obi go --env KEY=VAL were given (repeatable)")
+	fs.Var((*contextFlag)(&contextFlags), "context", "resolve as if obi go --context PATH were given (repeatable)")
+	fs.StringVar(&note, "note", "", "resolve as if obi go --note TEXT were given")
+
+	positional, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	alias := positionalArg(positional, 0)
+
+	resolved, cfg, err := loadConfig(configPath, profile)
+	if err != nil {
+		return err
+	}
+
+	var plan sessionPlan
+	if strings.TrimSpace(alias) == "" {
+		if cfg.Issues == nil {
+			return fmt.Errorf("obi prompt needs an alias: obi.toml has no \"issues outside epics\" section")
+		}
+		plan = planFromIssues(cfg)
+	} else {
+		if _, ok := groupTarget(alias); ok {
+			return fmt.Errorf("obi prompt takes a single epic, not a group")
+		}
+		if plan, err = prepareSession(cfg, alias); err != nil {
+			return err
+		}
+	}
+	plan.RepoRoot = repoRootForConfig(resolved)
+	plan.ConfigDigest = configDigest(resolved)
+	if err := applyRunContext(&plan, dirFlag, envFlags); err != nil {
+		return err
+	}
+	if err := loadContextFiles(&plan, contextFlags); err != nil {
+		return err
+	}
+	plan.OperatorNote = strings.TrimSpace(note)
+	if resume {
+		logPath, err := cfg.ResultsLogPath()
+		if err != nil {
+			return err
+		}
+		if err := enableResume(&plan, logPath); err != nil {
+			return err
+		}
+	}
+	if readOnly {
+		applyReadOnly(&plan)
+	}
+	loadReadyList(&plan, cfg)
+
+	prompt, err := reviewablePrompt(cfg, plan)
+	if err != nil {
+		return err
+	}
+	if outPath == "" {
+		fmt.Print(prompt)
+		return nil
+	}
+	if err := os.WriteFile(outPath, []byte(prompt), 0o644); err != nil {
+		return fmt.Errorf("write prompt: %w", err)
+	}
+	fmt.Printf("Wrote prompt for %s to %s\n", plan.EpicID, outPath)
+	return nil
+}
@@ -1,8 +1,13 @@
 package app
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
 )
 
 func TestBuildPromptIncludesAllSections(t *testing.T) {
@@ -39,6 +44,48 @@ func TestBuildPromptHandlesMissingSections(t *testing.T) {
 	}
 }
 
+func TestReadyListSectionFiltersAndCaps(t *testing.T) {
+	issues := []readyIssue{
+		{ID: "obi-foo", IssueType: "epic", Title: "The epic"},
+		{ID: "obi-foo.1", IssueType: "task", Title: "Wire the flag"},
+		{ID: "obi-foo.2", IssueType: "bug", Title: "Already finished"},
+		{ID: "obi-bar.1", IssueType: "task", Title: "Other epic"},
+		{ID: "loose-1", IssueType: "task", Title: "Loose issue"},
+	}
+	plan := sessionPlan{EpicID: "obi-foo", ResumeCompletedBeads: []string{"OBI-FOO.2"}}
+	plan.ReadyBeads = readyBeadsForPlan(plan, issues)
+
+	got := buildPrompt(plan)
+	if !strings.Contains(got, "Ready beads at launch (bd ready --json, 1):\n- obi-foo.1 [task] Wire the flag") {
+		t.Fatalf("expected the epic's ready bead, got %q", got)
+	}
+	for _, unwanted := range []string{"The epic", "Already finished", "Other epic", "Loose issue"} {
+		if strings.Contains(got, unwanted) {
+			t.Fatalf("prompt should not list %q: %q", unwanted, got)
+		}
+	}
+	if ready, contract := strings.Index(got, "Ready beads"), strings.Index(got, "Epic completion contract"); ready > contract {
+		t.Fatalf("ready list should precede the contract: %q", got)
+	}
+
+	loose := readyBeadsForPlan(sessionPlan{EpicID: "issues"}, issues)
+	if len(loose) != 1 || loose[0].ID != "loose-1" {
+		t.Fatalf("issues target should list loose beads only, got %+v", loose)
+	}
+
+	plan.ReadyBeads = nil
+	for i := 0; i < readyListMaxBeads+5; i++ {
+		plan.ReadyBeads = append(plan.ReadyBeads, readyIssue{ID: fmt.Sprintf("obi-foo.%d", i), Title: strings.Repeat("x", 300)})
+	}
+	text := formatReadyList(plan)
+	if strings.Count(text, "\n- obi-foo.") != readyListMaxBeads || !strings.Contains(text, "- … 5 more") {
+		t.Fatalf("expected the list capped at %d beads, got %q", readyListMaxBeads, text)
+	}
+	if strings.Contains(text, strings.Repeat("x", readyListTitleChars+1)) {
+		t.Fatal("expected long titles to be cut")
+	}
+}
+
 func TestCompletionContractForIssues(t *testing.T) {
 	plan := sessionPlan{
 		EpicID: "issues",
@@ -49,6 +96,27 @@ func TestCompletionContractForIssues(t *testing.T) {
 	}
 }
 
+func TestReadOnlyPlanUsesExploratoryContract(t *testing.T) {
+	plan := sessionPlan{
+		EpicID:        "automatic-octo-barnacle-d4c",
+		EpicName:      "Demo",
+		VerifyCommand: "go test ./...",
+		Codex:         config.CodexConfig{Sandbox: "workspace-write", ExtraArgs: []string{"--search", "-c", "sandbox_mode=danger-full-access", "--dangerously-bypass-approvals-and-sandbox"}},
+		Escalation:    config.EscalationConfig{Action: config.EscalationPrompt, AutoApprove: []string{"go test( .*)?"}},
+	}
+	applyReadOnly(&plan)
+	if plan.Codex.Sandbox != readOnlySandbox || plan.VerifyCommand != "" || strings.Join(plan.Codex.ExtraArgs, " ") != "--search" {
+		t.Fatalf("unexpected read-only plan: %+v", plan)
+	}
+	if plan.Escalation.Action != config.EscalationDeny || len(plan.Escalation.AutoApprove) != 0 {
+		t.Fatalf("read-only runs must deny escalations, got %+v", plan.Escalation)
+	}
+	got := buildPrompt(plan)
+	if !strings.Contains(got, "Exploratory session for Demo") || strings.Contains(got, "bd close") {
+		t.Fatalf("expected exploratory contract without closing beads, got %q", got)
+	}
+}
+
 func TestBuildPromptIncludesResumeSection(t *testing.T) {
 	plan := sessionPlan{
 		EpicID:               "automatic-octo-barnacle-d4c",
@@ -64,6 +132,23 @@ func TestBuildPromptIncludesResumeSection(t *testing.T) {
 	}
 }
 
+func TestResumeInstructionsListsOnlyRecentBeads(t *testing.T) {
+	plan := sessionPlan{EpicID: "obi-foo", ResumeEnabled: true}
+	for i := 1; i <= resumePromptRecentBeads+7; i++ {
+		plan.ResumeCompletedBeads = append(plan.ResumeCompletedBeads, fmt.Sprintf("obi-foo.%d", i))
+	}
+	got := resumeInstructions(plan)
+	if !strings.Contains(got, "- 7 earlier bead(s) already completed") {
+		t.Fatalf("expected earlier beads to be counted, got %q", got)
+	}
+	if strings.Contains(got, "- obi-foo.7\n") || !strings.Contains(got, "- obi-foo.8\n") || !strings.HasSuffix(got, "- obi-foo.17") {
+		t.Fatalf("expected only the most recent beads listed, got %q", got)
+	}
+	if _, ok := plan.resumeSkipSet()["obi-foo.1"]; !ok {
+		t.Fatal("the skip set must still cover beads left out of the prompt")
+	}
+}
+
 func TestBuildSummaryPrompt(t *testing.T) {
 	plan := sessionPlan{
 		EpicID:          "automatic-octo-barnacle-d4c",
@@ -97,3 +182,102 @@ func TestBuildSummaryPrompt(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildPromptAppendsContextFiles(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "ARCH.md"), []byte("# Architecture\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	big := strings.Repeat("x", contextFileMaxBytes+10)
+	if err := os.WriteFile(filepath.Join(root, "big.txt"), []byte(big), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	plan := sessionPlan{
+		EpicID:       "automatic-octo-barnacle-xyz",
+		EpicPrompt:   "Epic text",
+		RepoRoot:     root,
+		ContextPaths: []string{"docs/ARCH.md", "big.txt"},
+	}
+	if err := loadContextFiles(&plan, []string{filepath.Join(root, "docs", "ARCH.md")}); err != nil {
+		t.Fatalf("loadContextFiles: %v", err)
+	}
+	if len(plan.ContextFiles) != 2 {
+		t.Fatalf("expected duplicate path to be dropped, got %+v", contextFilePaths(plan.ContextFiles))
+	}
+
+	got := buildPrompt(plan)
+	for _, part := range []string{"===== docs/ARCH.md =====\n# Architecture", "===== big.txt =====", "[obi: 10 bytes truncated]"} {
+		if !strings.Contains(got, part) {
+			t.Fatalf("expected prompt to include %q", part)
+		}
+	}
+	if strings.Index(got, "Epic text") > strings.Index(got, "docs/ARCH.md") || strings.Index(got, "docs/ARCH.md") > strings.Index(got, "Epic ID:") {
+		t.Fatalf("expected context between epic prompt and metadata")
+	}
+
+	plan.ContextPaths = []string{"missing.md"}
+	if err := loadContextFiles(&plan, nil); err == nil || !strings.Contains(err.Error(), "context file") {
+		t.Fatalf("expected missing context file error, got %v", err)
+	}
+}
+
+func TestRunPromptWritesComposedPrompt(t *testing.T) {
+	root := t.TempDir()
+	configPath := filepath.Join(root, "obi.toml")
+	configText := "base_prompt = \"Base text\"\n\n[epic.foo]\nname = \"Foo\"\nid = \"obi-foo\"\nprompt = \"Epic text\"\n"
+	if err := os.WriteFile(configPath, []byte(configText), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	outPath := filepath.Join(root, "prompt.txt")
+	if err := runPrompt([]string{"foo", "--config", configPath, "--out", outPath}); err != nil {
+		t.Fatalf("runPrompt: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read prompt: %v", err)
+	}
+	got := string(data)
+	plan := sessionPlan{BasePrompt: "Base text", EpicPrompt: "Epic text", EpicID: "obi-foo", EpicName: "Foo"}
+	if !strings.HasPrefix(got, buildPrompt(plan)+"\n\n") {
+		t.Fatalf("expected prompt body first, got:\n%s", got)
+	}
+	if !strings.Contains(got, "```obi:"+promptSessionPlaceholder) {
+		t.Fatalf("expected placeholder session fence, got:\n%s", got)
+	}
+}
+
+func TestRunPromptAddsOperatorNote(t *testing.T) {
+	root := t.TempDir()
+	configPath := filepath.Join(root, "obi.toml")
+	configText := "base_prompt = \"Base text\"\n\n[epic.foo]\nname = \"Foo\"\nid = \"obi-foo\"\nprompt = \"Epic text\"\n"
+	if err := os.WriteFile(configPath, []byte(configText), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	outPath := filepath.Join(root, "prompt.txt")
+	if err := runPrompt([]string{"foo", "--config", configPath, "--note", "  Focus on the API layer first ", "--out", outPath}); err != nil {
+		t.Fatalf("runPrompt: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read prompt: %v", err)
+	}
+	got := string(data)
+	note := "Operator note (this run only):\nFocus on the API layer first\n\nEpic ID: obi-foo"
+	if !strings.Contains(got, "Epic text\n\n"+note) {
+		t.Fatalf("expected the note after the epic prompt, got:\n%s", got)
+	}
+}
+
+func TestOperatorNoteIsRecordedAsEvent(t *testing.T) {
+	log := newOperatorLog(nil)
+	log.record(operatorEventNote, "")
+	log.record(operatorEventNote, "Focus on the API layer first")
+	events := log.ledgerEvents(nil)
+	if len(events) != 1 || events[0].Kind != "note" || events[0].Message != "Focus on the API layer first" {
+		t.Fatalf("events = %+v", events)
+	}
+}
@@ -0,0 +1,215 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// promptDiffContext is how many unchanged lines surround each hunk.
+const promptDiffContext = 3
+
+// maxPromptDiffCells bounds the line-matching table; larger prompt pairs are
+// reported as changed without a line diff.
+const maxPromptDiffCells = 4_000_000
+
+// promptCopyPath stores the prompt a session was launched with next to its
+// transcript.
+func promptCopyPath(logPath, sessionID string) string {
+	return filepath.Join(transcriptDirFor(logPath), sanitizeFilename(sessionID)+".prompt.txt")
+}
+
+// storedPrompt returns prompt with its session ID replaced by the
+// placeholder and secrets redacted, which is how prompts are kept on disk
+// and compared.
+func storedPrompt(prompt, sessionID string) string {
+	if sessionID != "" {
+		prompt = strings.ReplaceAll(prompt, sessionID, promptSessionPlaceholder)
+	}
+	redacted, _ := redactText(prompt, redactionSecrets())
+	return redacted
+}
+
+func writePromptCopy(path, prompt string) error {
+	if err := ensureTranscriptDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(prompt), 0o600); err != nil {
+		return fmt.Errorf("write prompt copy: %w", err)
+	}
+	return nil
+}
+
+// previousPrompt finds the latest run of epicID whose stored prompt can
+// still be read. Missing ledgers and pruned prompt files yield ok=false.
+func previousPrompt(logPath, epicID string) (ledgerEntry, string, bool) {
+	entries, err := ledgerEntriesForEpic(logPath, epicID)
+	if err != nil {
+		return ledgerEntry{}, "", false
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		path := strings.TrimSpace(entries[i].PromptPath)
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return ledgerEntry{}, "", false
+		}
+		return entries[i], string(data), true
+	}
+	return ledgerEntry{}, "", false
+}
+
+// formatPromptChanges describes how prompt differs from the one the
+// previous run of the epic was given, for the confirmation screen.
+func formatPromptChanges(prev ledgerEntry, prevPrompt, prompt string, now time.Time) string {
+	label := fmt.Sprintf("previous run (%s, %s)", prev.SessionID, timeAgo(now.Sub(prev.CompletedAt)))
+	if strings.TrimSpace(prevPrompt) == strings.TrimSpace(prompt) {
+		return fmt.Sprintf("Prompt unchanged since the %s.\n", label)
+	}
+	diff, ok := unifiedDiff(label, "this run", prevPrompt, prompt, promptDiffContext)
+	if !ok {
+		return fmt.Sprintf("Prompt changed since the %s (too large to diff).\n", label)
+	}
+	return "Changes since the previous run's prompt:\n" + diff
+}
+
+type diffLine struct {
+	op   byte // ' ', '-', or '+'
+	text string
+}
+
+// diffLines matches a and b by longest common subsequence. It reports
+// false when the inputs are too large to compare.
+func diffLines(a, b []string) ([]diffLine, bool) {
+	if (len(a)+1)*(len(b)+1) > maxPromptDiffCells {
+		return nil, false
+	}
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var out []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, diffLine{'-', a[i]})
+			i++
+		default:
+			out = append(out, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		out = append(out, diffLine{'+', b[j]})
+	}
+	return out, true
+}
+
+// unifiedDiff renders a unified diff of oldText and newText with the given
+// number of context lines. It is empty when the texts match.
+func unifiedDiff(oldLabel, newLabel, oldText, newText string, context int) (string, bool) {
+	lines, ok := diffLines(splitDiffLines(oldText), splitDiffLines(newText))
+	if !ok {
+		return "", false
+	}
+	var changes []int
+	for i, line := range lines {
+		if line.op != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return "", true
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldLabel, newLabel)
+	for k := 0; k < len(changes); {
+		// Merge changes whose context would overlap into one hunk.
+		last := k
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*context+1 {
+			last++
+		}
+		start := changes[k] - context
+		if start < 0 {
+			start = 0
+		}
+		end := changes[last] + context + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+		writeDiffHunk(&b, lines, start, end)
+		k = last + 1
+	}
+	return b.String(), true
+}
+
+func writeDiffHunk(b *strings.Builder, lines []diffLine, start, end int) {
+	oldStart, newStart := 1, 1
+	for _, line := range lines[:start] {
+		if line.op != '+' {
+			oldStart++
+		}
+		if line.op != '-' {
+			newStart++
+		}
+	}
+	oldCount, newCount := 0, 0
+	for _, line := range lines[start:end] {
+		if line.op != '+' {
+			oldCount++
+		}
+		if line.op != '-' {
+			newCount++
+		}
+	}
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, line := range lines[start:end] {
+		b.WriteByte(line.op)
+		b.WriteString(line.text)
+		b.WriteByte('\n')
+	}
+}
+
+func splitDiffLines(text string) []string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnifiedDiffShowsChangedLinesWithContext(t *testing.T) {
+	oldText := "a\nb\nc\nd\ne\nf\ng\nh\n"
+	newText := "a\nb\nc\nD\ne\nf\ng\nh\ni\n"
+	got, ok := unifiedDiff("old", "new", oldText, newText, 1)
+	if !ok {
+		t.Fatal("expected a diff")
+	}
+	want := "--- old\n+++ new\n" +
+		"@@ -3,3 +3,3 @@\n c\n-d\n+D\n e\n" +
+		"@@ -8,1 +8,2 @@\n h\n+i\n"
+	if got != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiffEmptyWhenEqual(t *testing.T) {
+	if got, ok := unifiedDiff("old", "new", "same\n", "same", 3); !ok || got != "" {
+		t.Fatalf("expected no diff, got %q", got)
+	}
+}
+
+func TestPreviousPromptSkipsPrunedCopies(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "results.log")
+	kept := promptCopyPath(logPath, "s1")
+	if err := writePromptCopy(kept, "first prompt\n"); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, entry := range []ledgerEntry{
+		{SessionID: "s1", EpicID: "epic", Status: "success", CompletedAt: now.Add(-2 * time.Hour), PromptPath: kept},
+		{SessionID: "s2", EpicID: "epic", Status: "success", CompletedAt: now.Add(-time.Hour), PromptPath: promptCopyPath(logPath, "s2")},
+		{SessionID: "s3", EpicID: "other", Status: "success", CompletedAt: now, PromptPath: kept},
+	} {
+		if err := appendLedgerEntry(logPath, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prev, text, ok := previousPrompt(logPath, "epic")
+	if !ok || prev.SessionID != "s1" || text != "first prompt\n" {
+		t.Fatalf("unexpected previous prompt: %v %q %+v", ok, text, prev)
+	}
+
+	out := formatPromptChanges(prev, text, "second prompt", now)
+	if !strings.Contains(out, "-first prompt\n+second prompt\n") {
+		t.Fatalf("expected diff in output, got:\n%s", out)
+	}
+	if out := formatPromptChanges(prev, text, "first prompt", now); !strings.HasPrefix(out, "Prompt unchanged") {
+		t.Fatalf("expected unchanged notice, got %q", out)
+	}
+}
+
+func TestStoredPromptReplacesSessionID(t *testing.T) {
+	got := storedPrompt("report in ```obi:abc-123", "abc-123")
+	if got != "report in ```obi:"+promptSessionPlaceholder {
+		t.Fatalf("unexpected stored prompt %q", got)
+	}
+}
+
+func TestCleanTreatsPromptCopiesAsTranscriptFiles(t *testing.T) {
+	if got := transcriptSession("abc.prompt.txt"); got != "abc" {
+		t.Fatalf("expected abc, got %q", got)
+	}
+}
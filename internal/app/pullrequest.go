@@ -0,0 +1,155 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+)
+
+// pullRequestURLPattern finds the URL a PR-creation command prints.
+var pullRequestURLPattern = regexp.MustCompile(`https?://\S+`)
+
+// pullRequestFields are the values a [summary.pull_request] command can
+// use. Each is shell-quoted before rendering.
+type pullRequestFields struct {
+	Title    string
+	Body     string
+	BodyFile string
+	Branch   string
+	EpicID   string
+	EpicName string
+	Alias    string
+}
+
+// pullRequestBodyPath stores the PR body next to the summary transcript.
+func pullRequestBodyPath(logPath, sessionID string) string {
+	return filepath.Join(transcriptDirFor(logPath), sanitizeFilename(sessionID)+".pr.md")
+}
+
+// newPullRequestFields builds the PR from a successful omnibus summary: its
+// commit summary becomes the title and its details the body.
+func newPullRequestFields(plan sessionPlan, entry ledgerEntry, bodyFile string) pullRequestFields {
+	title := strings.TrimSpace(firstLine(entry.CommitSummary))
+	if title == "" {
+		title = fmt.Sprintf("%s (%s)", plan.EpicName, plan.EpicID)
+	}
+	body := entry.CommitDetails
+	if strings.TrimSpace(body) == "" {
+		body = entry.CommitSummary
+	}
+	branch := plan.Branch
+	if branch == "" && entry.Git != nil {
+		branch = entry.Git.Branch
+	}
+	return pullRequestFields{
+		Title:    title,
+		Body:     body,
+		BodyFile: bodyFile,
+		Branch:   branch,
+		EpicID:   plan.EpicID,
+		EpicName: plan.EpicName,
+		Alias:    plan.Alias,
+	}
+}
+
+// renderPullRequestCommand expands tmpl with every field shell-quoted, so
+// "gh pr create --title {{.Title}} --body-file {{.BodyFile}}" is safe to
+// pass to sh -c whatever the summary contains.
+func renderPullRequestCommand(tmpl string, fields pullRequestFields) (string, error) {
+	parsed, err := template.New("pull_request").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("summary.pull_request.command: %w", err)
+	}
+	quoted := pullRequestFields{
+		Title:    shellQuote(fields.Title),
+		Body:     shellQuote(fields.Body),
+		BodyFile: shellQuote(fields.BodyFile),
+		Branch:   shellQuote(fields.Branch),
+		EpicID:   shellQuote(fields.EpicID),
+		EpicName: shellQuote(fields.EpicName),
+		Alias:    shellQuote(fields.Alias),
+	}
+	var b strings.Builder
+	if err := parsed.Execute(&b, quoted); err != nil {
+		return "", fmt.Errorf("summary.pull_request.command: %w", err)
+	}
+	return b.String(), nil
+}
+
+// shellQuote wraps value in single quotes for sh.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// openPullRequest runs [summary.pull_request] command after a successful
+// omnibus summary and returns the PR URL it printed. Failures only warn:
+// the epic's work is already committed and the summary is still recorded.
+func openPullRequest(cfg *config.Config, plan sessionPlan, entry ledgerEntry, logPath string) string {
+	tmpl := strings.TrimSpace(cfg.Summary.PullRequest.Command)
+	if tmpl == "" {
+		return ""
+	}
+	bodyFile := pullRequestBodyPath(logPath, entry.SessionID)
+	fields := newPullRequestFields(plan, entry, bodyFile)
+	if err := ensureTranscriptDir(filepath.Dir(bodyFile)); err != nil {
+		fmt.Fprintf(os.Stderr, "obi: pull request not opened: %v\n", err)
+		return ""
+	}
+	if err := os.WriteFile(bodyFile, []byte(strings.TrimSpace(fields.Body)+"\n"), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "obi: pull request not opened: write body: %v\n", err)
+		return ""
+	}
+	command, err := renderPullRequestCommand(tmpl, fields)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "obi: pull request not opened: %v\n", err)
+		return ""
+	}
+
+	fmt.Printf("\nOpening pull request: %s\n", firstLine(tmpl))
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = gitRunDir(plan)
+	cmd.Env = append(os.Environ(), plan.Env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "obi: pull request command failed (%v): %s\n", err, truncate(strings.TrimSpace(stderr.String()), 500))
+		return ""
+	}
+	url := pullRequestURL(stdout.String())
+	if url == "" {
+		fmt.Fprintln(os.Stderr, "obi: pull request command printed no URL; nothing recorded in the ledger.")
+		return ""
+	}
+	fmt.Printf("Pull request: %s\n", url)
+	return url
+}
+
+// pullRequestURL returns the last URL in out; gh pr create prints it last.
+func pullRequestURL(out string) string {
+	matches := pullRequestURLPattern.FindAllString(out, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1]
+}
+
+// notifyPullRequest posts the new PR to the [notify] webhook when one is set.
+func notifyPullRequest(cfg *config.Config, entry ledgerEntry) {
+	webhook := strings.TrimSpace(cfg.Notify.Webhook)
+	if webhook == "" || entry.PullRequestURL == "" {
+		return
+	}
+	text := fmt.Sprintf("obi opened a pull request for %s (%s): %s", entry.EpicName, entry.EpicID, entry.PullRequestURL)
+	if err := postDigest(webhook, text); err != nil {
+		diag.Logger().Warn("notify webhook failed", "err", err)
+	}
+}
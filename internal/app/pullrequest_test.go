@@ -0,0 +1,79 @@
+package app
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestRenderPullRequestCommandQuotesFields(t *testing.T) {
+	fields := pullRequestFields{Title: "feat: it's done; rm -rf /", BodyFile: "/tmp/a b.md", Branch: "epic/api"}
+	got, err := renderPullRequestCommand("gh pr create --title {{.Title}} --body-file {{.BodyFile}} --head {{.Branch}}", fields)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	want := `gh pr create --title 'feat: it'\''s done; rm -rf /' --body-file '/tmp/a b.md' --head 'epic/api'`
+	if got != want {
+		t.Fatalf("rendered %q, want %q", got, want)
+	}
+	if _, err := renderPullRequestCommand("gh pr create --title {{.Subject}}", fields); err == nil || !strings.Contains(err.Error(), "summary.pull_request.command") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestPullRequestURLTakesLastURL(t *testing.T) {
+	out := "Warning: see https://docs.example/help\nhttps://github.com/acme/repo/pull/42\n"
+	if got := pullRequestURL(out); got != "https://github.com/acme/repo/pull/42" {
+		t.Fatalf("pullRequestURL = %q", got)
+	}
+	if got := pullRequestURL("no link here\n"); got != "" {
+		t.Fatalf("pullRequestURL = %q, want empty", got)
+	}
+}
+
+func TestOpenPullRequestRunsCommandAndNotifies(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	var posted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posted = string(body)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "results.log")
+	cfg := &config.Config{
+		Summary: config.SummaryConfig{PullRequest: config.PullRequestConfig{Command: "cat {{.BodyFile}} > pr-body.txt && echo https://github.com/acme/repo/pull/7"}},
+		Notify:  config.NotifyConfig{Webhook: srv.URL},
+	}
+	plan := sessionPlan{EpicID: "obi-api", EpicName: "API", RepoRoot: dir}
+	entry := ledgerEntry{SessionID: "sess-1", EpicID: "obi-api", EpicName: "API", CommitSummary: "feat(api): ship v2", CommitDetails: "- obi-api.1 added routes\n- obi-api.2 added docs"}
+
+	entry.PullRequestURL = openPullRequest(cfg, plan, entry, logPath)
+	if entry.PullRequestURL != "https://github.com/acme/repo/pull/7" {
+		t.Fatalf("url = %q", entry.PullRequestURL)
+	}
+	body, err := os.ReadFile(filepath.Join(dir, "pr-body.txt"))
+	if err != nil || !strings.Contains(string(body), "obi-api.2 added docs") {
+		t.Fatalf("body file = %q, %v", body, err)
+	}
+
+	notifyPullRequest(cfg, entry)
+	if !strings.Contains(posted, "pull/7") {
+		t.Fatalf("notify payload = %q", posted)
+	}
+
+	cfg.Summary.PullRequest.Command = "exit 3"
+	if got := openPullRequest(cfg, plan, entry, logPath); got != "" {
+		t.Fatalf("failing command returned %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/codexexec"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+)
+
+// readOnlySandbox is the Codex sandbox obi go --read-only forces.
+const readOnlySandbox = "read-only"
+
+const exploratoryContractTemplate = `Exploratory session for %s (%s):
+- The sandbox is read-only. Investigate and report; do not edit files, commit, or change bead status.
+- You may inspect beads with "bd ready --json" and "bd show <id> --json".
+- Put your findings in the report details and a one-line summary in commit_msg; no commit will be made.
+- Emit STATUS: success once the investigation is done. Otherwise emit STATUS: needs_help with ESCALATION explaining what blocked it.`
+
+// applyReadOnly turns plan into an exploratory session: Codex runs in the
+// read-only sandbox, escalation requests are denied, the prompt drops the
+// close-the-bead contract, and no verification runs because nothing
+// changes.
+func applyReadOnly(plan *sessionPlan) {
+	plan.ReadOnly = true
+	plan.Codex = readOnlyCodex(plan.Codex)
+	plan.Escalation = config.EscalationConfig{Action: config.EscalationDeny, RequestPattern: plan.Escalation.RequestPattern}
+	plan.VerifyCommand = ""
+}
+
+// readOnlyCodex pins codex to the read-only sandbox. extra_args come after
+// --sandbox on the command line, so any that choose or widen the sandbox
+// are dropped rather than left to override it.
+func readOnlyCodex(codex config.CodexConfig) config.CodexConfig {
+	codex.Sandbox = readOnlySandbox
+	kept, dropped := codexexec.StripSandboxArgs(codex.ExtraArgs)
+	if len(dropped) > 0 {
+		diag.Logger().Warn("dropped sandbox arguments from codex extra_args for a read-only run", "args", strings.Join(dropped, " "))
+	}
+	codex.ExtraArgs = kept
+	return codex
+}
+
+func exploratoryContract(plan sessionPlan) string {
+	name := strings.TrimSpace(plan.EpicName)
+	if name == "" {
+		name = plan.EpicID
+	}
+	return fmt.Sprintf(exploratoryContractTemplate, name, plan.EpicID)
+}
@@ -2,13 +2,20 @@ package app
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
 )
 
-const readyFetchLimit = "200"
+const (
+	readyFetchLimit  = "200"
+	beadsSyncTimeout = 2 * time.Minute
+)
 
 type readyIssue struct {
 	ID          string `json:"id"`
@@ -32,6 +39,38 @@ func fetchReadyIssues() ([]readyIssue, error) {
 	return parseReadyIssues(stdout.Bytes())
 }
 
+// syncBeads runs the configured sync command in dir so ready checks see the
+// latest bead data.
+func syncBeads(command, dir string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), beadsSyncTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s: timed out after %s", command, beadsSyncTimeout)
+	}
+	if err != nil {
+		if detail := strings.TrimSpace(string(out)); detail != "" {
+			return fmt.Errorf("%s: %s: %s", command, err, firstLine(detail))
+		}
+		return fmt.Errorf("%s: %w", command, err)
+	}
+	return nil
+}
+
+// maybeSyncBeads runs [beads] sync when configured. A failed sync only warns:
+// stale local data is still better than refusing to run.
+func maybeSyncBeads(cfg *config.Config, dir string) {
+	command := cfg.Beads.SyncCommandValue()
+	if command == "" {
+		return
+	}
+	if err := syncBeads(command, dir); err != nil {
+		fmt.Printf("Warning: bead sync failed (%v); using local bead data.\n", err)
+	}
+}
+
 func parseReadyIssues(data []byte) ([]readyIssue, error) {
 	var issues []readyIssue
 	if err := json.Unmarshal(data, &issues); err != nil {
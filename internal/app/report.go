@@ -0,0 +1,290 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// unattributedBead groups ledger runs that never identified a bead.
+const unattributedBead = "(no bead)"
+
+// beadReport aggregates every ledger run attributed to one bead.
+type beadReport struct {
+	BeadID      string    `json:"bead_id"`
+	EpicID      string    `json:"epic_id"`
+	Alias       string    `json:"alias,omitempty"`
+	Attempts    int       `json:"attempts"`
+	DurationMs  int64     `json:"duration_ms"`
+	TokensUsed  int64     `json:"tokens_used,omitempty"`
+	CostUSD     float64   `json:"cost_usd,omitempty"`
+	FinalStatus string    `json:"final_status"`
+	LastRun     time.Time `json:"last_run"`
+	Summary     string    `json:"summary,omitempty"`
+	Commits     []string  `json:"commits,omitempty"`
+}
+
+func runReport(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("obi report requires a report name ('beads' or 'digest')")
+	}
+
+	name := args[0]
+	rest := args[1:]
+
+	switch name {
+	case "beads":
+		return runReportBeads(rest)
+	case "digest":
+		return runReportDigest(rest)
+	default:
+		return fmt.Errorf("unknown report %q", name)
+	}
+}
+
+func runReportBeads(args []string) error {
+	fs := newCommandFlags("report beads", "obi report beads [options]",
+		"Aggregate the results log per bead: attempts, time, tokens, final status, and linked commits.")
+	var configPath, epic, format string
+	var usdPerMTok float64
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.StringVar(&epic, "epic", "", "only report beads from this epic alias or ID")
+	fs.StringVar(&format, "format", "table", "output format: table, json, or markdown")
+	fs.Float64Var(&usdPerMTok, "usd-per-mtok", 0, "estimate cost at this USD price per million tokens")
+	if _, err := fs.parse(args); err != nil {
+		return err
+	}
+	if !validReportFormat(format) {
+		return fmt.Errorf("unknown report format %q (want table, json, or markdown)", format)
+	}
+
+	resolved, err := config.ResolvePath(configPath)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(resolved)
+	if err != nil {
+		return err
+	}
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+
+	entries, err := ledgerEntriesForEpic(logPath, reportEpicID(cfg, epic))
+	if err != nil {
+		if errors.Is(err, errLedgerNotFound) {
+			return fmt.Errorf("results log %s not found; run obi go at least once first", logPath)
+		}
+		return err
+	}
+
+	reports := buildBeadReports(entries, usdPerMTok)
+	repoRoot := repoRootForConfig(resolved)
+	attachBeadCommits(reports, func(beadID string) ([]string, error) {
+		return gitCommitsForBead(repoRoot, beadID)
+	})
+
+	out, err := formatBeadReports(reports, format)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// reportEpicID resolves --epic through the config, falling back to the raw
+// value so archived epics and the issues block can still be reported.
+func reportEpicID(cfg *config.Config, requested string) string {
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		return ""
+	}
+	if _, target, err := resolveEpic(cfg, requested); err == nil && target.ID != "" {
+		return target.ID
+	}
+	return requested
+}
+
+func validReportFormat(format string) bool {
+	switch format {
+	case "table", "json", "markdown":
+		return true
+	}
+	return false
+}
+
+// buildBeadReports groups ledger entries by bead in order of first attempt.
+// The final status and summary come from the most recent run.
+func buildBeadReports(entries []ledgerEntry, usdPerMTok float64) []beadReport {
+	var reports []beadReport
+	index := map[string]int{}
+	for _, entry := range entries {
+		bead := strings.TrimSpace(entry.BeadID)
+		if bead == "" {
+			bead = unattributedBead
+		}
+		key := strings.ToLower(bead)
+		i, ok := index[key]
+		if !ok {
+			i = len(reports)
+			index[key] = i
+			reports = append(reports, beadReport{BeadID: bead, EpicID: entry.EpicID, Alias: entry.Alias})
+		}
+		r := &reports[i]
+		r.Attempts++
+		r.DurationMs += entry.DurationMs
+		r.TokensUsed += entry.TokensUsed
+		if !entry.CompletedAt.Before(r.LastRun) {
+			r.LastRun = entry.CompletedAt
+			r.FinalStatus = entry.Status
+			r.Summary = entry.CommitSummary
+		}
+	}
+	if usdPerMTok > 0 {
+		for i := range reports {
+			reports[i].CostUSD = float64(reports[i].TokensUsed) / 1e6 * usdPerMTok
+		}
+	}
+	return reports
+}
+
+// attachBeadCommits fills in linked commits; lookup failures leave the
+// list empty rather than failing the whole report.
+func attachBeadCommits(reports []beadReport, lookup func(string) ([]string, error)) {
+	for i := range reports {
+		if reports[i].BeadID == unattributedBead {
+			continue
+		}
+		commits, err := lookup(reports[i].BeadID)
+		if err != nil {
+			continue
+		}
+		reports[i].Commits = commits
+	}
+}
+
+// gitCommitsForBead lists short hashes of commits whose message mentions beadID.
+func gitCommitsForBead(repoRoot, beadID string) ([]string, error) {
+	cmd := exec.Command("git", "-C", repoRoot, "log", "--all", "--format=%h", "-i", "-F", "--grep="+beadID)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log for %s: %w", beadID, err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+func formatBeadReports(reports []beadReport, format string) (string, error) {
+	switch format {
+	case "json":
+		if reports == nil {
+			reports = []beadReport{}
+		}
+		data, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("marshal bead report: %w", err)
+		}
+		return string(data) + "\n", nil
+	case "markdown":
+		return formatBeadReportsMarkdown(reports), nil
+	default:
+		return formatBeadReportsTable(reports), nil
+	}
+}
+
+var beadReportHeaders = []string{"Bead", "Epic", "Attempts", "Time", "Tokens", "Cost", "Status", "Commits"}
+
+func beadReportCells(r beadReport) []string {
+	tokens := "-"
+	if r.TokensUsed > 0 {
+		tokens = strconv.FormatInt(r.TokensUsed, 10)
+	}
+	cost := "-"
+	if r.CostUSD > 0 {
+		cost = fmt.Sprintf("$%.2f", r.CostUSD)
+	}
+	commits := "-"
+	if len(r.Commits) > 0 {
+		commits = strings.Join(r.Commits, " ")
+	}
+	epic := r.Alias
+	if epic == "" {
+		epic = r.EpicID
+	}
+	return []string{
+		r.BeadID,
+		epic,
+		strconv.Itoa(r.Attempts),
+		roundedMillis(r.DurationMs),
+		tokens,
+		cost,
+		r.FinalStatus,
+		commits,
+	}
+}
+
+func formatBeadReportsTable(reports []beadReport) string {
+	if len(reports) == 0 {
+		return "No runs recorded yet.\n"
+	}
+	rows := [][]string{beadReportHeaders}
+	for _, r := range reports {
+		rows = append(rows, beadReportCells(r))
+	}
+	return formatTextTable(rows)
+}
+
+// formatTextTable aligns rows into columns under a dashed rule; the first
+// row is the header.
+func formatTextTable(rows [][]string) string {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	dashes := make([]string, len(widths))
+	for i, w := range widths {
+		dashes[i] = strings.Repeat("-", w)
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		parts := make([]string, len(row))
+		for i, cell := range row {
+			parts[i] = fmt.Sprintf("%-*s", widths[i], cell)
+		}
+		fmt.Fprintf(&b, "  %s\n", strings.TrimRight(strings.Join(parts, "  "), " "))
+	}
+	writeRow(rows[0])
+	writeRow(dashes)
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+func formatBeadReportsMarkdown(reports []beadReport) string {
+	if len(reports) == 0 {
+		return "_No runs recorded yet._\n"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "| %s |\n", strings.Join(beadReportHeaders, " | "))
+	fmt.Fprintf(&b, "|%s\n", strings.Repeat(" --- |", len(beadReportHeaders)))
+	for _, r := range reports {
+		cells := beadReportCells(r)
+		for i, cell := range cells {
+			cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+	}
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func reportTestEntries() []ledgerEntry {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	return []ledgerEntry{
+		{EpicID: "obi-a1", Alias: "alpha", BeadID: "obi-a1.1", Status: "needs_help", CommitSummary: "stuck", DurationMs: 60000, TokensUsed: 1000, CompletedAt: base},
+		{EpicID: "obi-a1", Alias: "alpha", BeadID: "obi-a1.2", Status: "success", CommitSummary: "add parser", DurationMs: 30000, TokensUsed: 500, CompletedAt: base.Add(time.Hour)},
+		{EpicID: "obi-a1", Alias: "alpha", BeadID: "OBI-A1.1", Status: "success", CommitSummary: "fix migrations", DurationMs: 90000, TokensUsed: 2000, CompletedAt: base.Add(2 * time.Hour)},
+		{EpicID: "obi-a1", Alias: "alpha", Status: "success", DurationMs: 1000, CompletedAt: base.Add(3 * time.Hour)},
+	}
+}
+
+func TestBuildBeadReportsAggregatesPerBead(t *testing.T) {
+	reports := buildBeadReports(reportTestEntries(), 10)
+	if len(reports) != 3 {
+		t.Fatalf("expected 3 beads, got %+v", reports)
+	}
+	first := reports[0]
+	if first.BeadID != "obi-a1.1" || first.Attempts != 2 || first.DurationMs != 150000 || first.TokensUsed != 3000 {
+		t.Fatalf("unexpected aggregate: %+v", first)
+	}
+	if first.FinalStatus != "success" || first.Summary != "fix migrations" {
+		t.Fatalf("expected latest run to set final status, got %+v", first)
+	}
+	if first.CostUSD < 0.0299 || first.CostUSD > 0.0301 {
+		t.Fatalf("unexpected cost %f", first.CostUSD)
+	}
+	if reports[2].BeadID != unattributedBead {
+		t.Fatalf("expected unattributed group last, got %+v", reports[2])
+	}
+}
+
+func TestAttachBeadCommitsSkipsUnattributedAndErrors(t *testing.T) {
+	reports := buildBeadReports(reportTestEntries(), 0)
+	var looked []string
+	attachBeadCommits(reports, func(id string) ([]string, error) {
+		looked = append(looked, id)
+		if id == "obi-a1.2" {
+			return nil, errors.New("git unavailable")
+		}
+		return []string{"abc1234"}, nil
+	})
+	if len(looked) != 2 {
+		t.Fatalf("expected lookups for attributed beads only, got %v", looked)
+	}
+	if len(reports[0].Commits) != 1 || reports[1].Commits != nil {
+		t.Fatalf("unexpected commits: %+v", reports)
+	}
+}
+
+func TestFormatBeadReports(t *testing.T) {
+	reports := buildBeadReports(reportTestEntries()[:2], 0)
+	reports[0].Commits = []string{"abc1234", "def5678"}
+
+	table, err := formatBeadReports(reports, "table")
+	if err != nil {
+		t.Fatalf("table: %v", err)
+	}
+	if !strings.Contains(table, "Bead") || !strings.Contains(table, "obi-a1.1  alpha") || !strings.Contains(table, "abc1234 def5678") {
+		t.Fatalf("unexpected table:\n%s", table)
+	}
+	if !strings.Contains(table, "1m0s") {
+		t.Fatalf("expected rounded duration in table:\n%s", table)
+	}
+
+	md, err := formatBeadReports(reports, "markdown")
+	if err != nil {
+		t.Fatalf("markdown: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(md), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[1], "| --- |") {
+		t.Fatalf("unexpected markdown:\n%s", md)
+	}
+
+	raw, err := formatBeadReports(reports, "json")
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	var decoded []beadReport
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil || len(decoded) != 2 {
+		t.Fatalf("unexpected json %q: %v", raw, err)
+	}
+
+	empty, _ := formatBeadReports(nil, "json")
+	if strings.TrimSpace(empty) != "[]" {
+		t.Fatalf("expected empty json array, got %q", empty)
+	}
+}
+
+func TestReportEpicIDResolvesAliasOrFallsBack(t *testing.T) {
+	cfg := &config.Config{Epics: map[string]config.EpicConfig{"obi_a1": {ID: "obi-a1", Alias: "alpha"}}}
+	if got := reportEpicID(cfg, "alpha"); got != "obi-a1" {
+		t.Fatalf("expected alias resolution, got %q", got)
+	}
+	if got := reportEpicID(cfg, "obi-old"); got != "obi-old" {
+		t.Fatalf("expected raw fallback, got %q", got)
+	}
+}
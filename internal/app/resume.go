@@ -1,7 +1,9 @@
 package app
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"strings"
 )
 
@@ -20,3 +22,44 @@ func enableResume(plan *sessionPlan, logPath string) error {
 	plan.ResumeCompletedBeads = completed
 	return nil
 }
+
+// offerResume turns resume on when the ledger already records finished
+// beads for the epic and neither --resume nor --fresh was passed. It asks
+// first when ask is set (unattended runs just say so); the default answer,
+// and the answer on EOF, is to resume. A ledger that --resume would
+// refuse (e.g. an unresolved needs_help) leaves the run fresh.
+func offerResume(plan *sessionPlan, logPath string, opts goOptions, ask bool, in io.Reader, out io.Writer) {
+	if opts.resume || opts.fresh || opts.readOnly || plan.EpicID == "" || plan.EpicID == "issues" {
+		return
+	}
+	completed, err := completedBeadsFromLedger(logPath, plan.EpicID)
+	if err != nil || len(completed) == 0 {
+		return
+	}
+	question := fmt.Sprintf("%d bead(s) already completed for %s (%s) — resume and skip them? [Y/n]: ", len(completed), plan.EpicName, plan.EpicID)
+	if !ask {
+		fmt.Fprintf(out, "%d bead(s) already completed for %s (%s); resuming. Pass --fresh to ignore them.\n", len(completed), plan.EpicName, plan.EpicID)
+	} else if !askResume(in, out, question) {
+		return
+	}
+	plan.ResumeEnabled = true
+	plan.ResumeCompletedBeads = completed
+}
+
+func askResume(in io.Reader, out io.Writer, question string) bool {
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprint(out, question)
+		input, err := reader.ReadString('\n')
+		choice := strings.TrimSpace(strings.ToLower(input))
+		switch {
+		case choice == "" || choice == "y" || choice == "yes":
+			return true
+		case choice == "n" || choice == "no":
+			return false
+		case err != nil:
+			return true
+		}
+		fmt.Fprintln(out, "Please respond with Y or n.")
+	}
+}
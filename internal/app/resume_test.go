@@ -1,7 +1,9 @@
 package app
 
 import (
+	"bytes"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -103,3 +105,69 @@ func TestEnableResumeFailsWhenBeadMissing(t *testing.T) {
 		t.Fatalf("expected error when bead id missing")
 	}
 }
+
+func TestEnableResumeSkipsExploratoryRuns(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "results.log")
+
+	for _, entry := range []ledgerEntry{
+		{SessionID: "a", EpicID: "automatic-octo-barnacle-d4c", Status: footer.StatusSuccess, BeadID: "automatic-octo-barnacle-d4c.1", Exploratory: true},
+		{SessionID: "b", EpicID: "automatic-octo-barnacle-d4c", Status: footer.StatusFailure, Exploratory: true},
+		{SessionID: "c", EpicID: "automatic-octo-barnacle-d4c", Status: footer.StatusSuccess, BeadID: "automatic-octo-barnacle-d4c.2"},
+	} {
+		if err := appendLedgerEntry(logPath, entry); err != nil {
+			t.Fatalf("append entry: %v", err)
+		}
+	}
+
+	plan := sessionPlan{EpicID: "automatic-octo-barnacle-d4c"}
+	if err := enableResume(&plan, logPath); err != nil {
+		t.Fatalf("enableResume: %v", err)
+	}
+	if len(plan.ResumeCompletedBeads) != 1 || plan.ResumeCompletedBeads[0] != "automatic-octo-barnacle-d4c.2" {
+		t.Fatalf("exploratory runs should not count toward resume, got %v", plan.ResumeCompletedBeads)
+	}
+}
+
+func TestOfferResumeAsksWhenLedgerHasSuccesses(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "results.log")
+	entry := ledgerEntry{SessionID: "s1", EpicID: "obi-api", BeadID: "obi-api.1", Status: footer.StatusSuccess, CommitSummary: "feat: one", StartedAt: time.Unix(0, 0), CompletedAt: time.Unix(0, 0)}
+	if err := appendLedgerEntry(logPath, entry); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	var out bytes.Buffer
+	plan := sessionPlan{EpicID: "obi-api", EpicName: "API"}
+	offerResume(&plan, logPath, goOptions{}, true, strings.NewReader("maybe\n\n"), &out)
+	if !plan.ResumeEnabled || len(plan.ResumeCompletedBeads) != 1 {
+		t.Fatalf("expected resume after default answer, got %+v", plan)
+	}
+	if !strings.Contains(out.String(), "1 bead(s) already completed for API (obi-api)") || !strings.Contains(out.String(), "Please respond with Y or n.") {
+		t.Fatalf("prompt output = %q", out.String())
+	}
+
+	plan = sessionPlan{EpicID: "obi-api"}
+	offerResume(&plan, logPath, goOptions{}, true, strings.NewReader("n\n"), &out)
+	if plan.ResumeEnabled {
+		t.Fatal("answering n should start fresh")
+	}
+
+	plan = sessionPlan{EpicID: "obi-api"}
+	offerResume(&plan, logPath, goOptions{fresh: true}, true, strings.NewReader(""), &out)
+	if plan.ResumeEnabled {
+		t.Fatal("--fresh should skip the offer")
+	}
+
+	out.Reset()
+	plan = sessionPlan{EpicID: "obi-api"}
+	offerResume(&plan, logPath, goOptions{}, false, strings.NewReader(""), &out)
+	if !plan.ResumeEnabled || !strings.Contains(out.String(), "Pass --fresh") {
+		t.Fatalf("unattended run should resume with a note, got %+v / %q", plan, out.String())
+	}
+
+	plan = sessionPlan{EpicID: "obi-other"}
+	offerResume(&plan, logPath, goOptions{}, true, strings.NewReader(""), &out)
+	if plan.ResumeEnabled {
+		t.Fatal("an epic without history should not resume")
+	}
+}
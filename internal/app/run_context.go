@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// envFlag collects repeatable --env KEY=VAL values.
+type envFlag []string
+
+func (e *envFlag) String() string {
+	if e == nil {
+		return ""
+	}
+	return strings.Join(*e, ",")
+}
+
+func (e *envFlag) Set(value string) error {
+	key, _, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("--env expects KEY=VAL, got %q", value)
+	}
+	*e = append(*e, value)
+	return nil
+}
+
+// applyRunContext layers CLI --dir/--env over the epic's dir/env and
+// validates the resulting working directory.
+func applyRunContext(plan *sessionPlan, dirFlag string, envFlags []string) error {
+	plan.Env = mergeEnv(plan.Env, envFlags)
+
+	dir := strings.TrimSpace(plan.Dir)
+	base := plan.RepoRoot
+	if flagDir := strings.TrimSpace(dirFlag); flagDir != "" {
+		dir = flagDir
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		base = wd
+	}
+	if dir == "" {
+		plan.Dir = ""
+		return nil
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(base, dir)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("codex working directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("codex working directory %s is not a directory", dir)
+	}
+	plan.Dir = filepath.Clean(dir)
+	return nil
+}
+
+// mergeEnv returns base with overrides applied; an override replaces any
+// base entry with the same key, and new keys are appended in order.
+func mergeEnv(base, overrides []string) []string {
+	if len(overrides) == 0 {
+		return base
+	}
+	merged := append([]string(nil), base...)
+	index := map[string]int{}
+	for i, entry := range merged {
+		key, _, _ := strings.Cut(entry, "=")
+		index[key] = i
+	}
+	for _, entry := range overrides {
+		key, _, _ := strings.Cut(entry, "=")
+		if i, ok := index[key]; ok {
+			merged[i] = entry
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, entry)
+	}
+	return merged
+}
+
+// envFromMap converts a config env table into sorted KEY=VAL entries.
+func envFromMap(env map[string]string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	entries := make([]string, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, key+"="+env[key])
+	}
+	return entries
+}
+
+// envKeys lists variable names only so values (often tokens) stay off screen.
+func envKeys(env []string) []string {
+	keys := make([]string, 0, len(env))
+	for _, entry := range env {
+		key, _, _ := strings.Cut(entry, "=")
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// epicSecretEnv reads the epic's secrets.from_env variables from obi's
+// environment, returning KEY=VALUE entries for Codex and the values to
+// redact. A missing or empty variable is an error rather than a session
+// that silently runs without it.
+func epicSecretEnv(names []string) ([]string, []string, error) {
+	var env, values []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		value := os.Getenv(name)
+		if value == "" {
+			return nil, nil, fmt.Errorf("epic secret %s is not set in the environment; export it or drop it from secrets.from_env", name)
+		}
+		env = append(env, name+"="+value)
+		values = append(values, value)
+	}
+	return env, values, nil
+}
+
+// childEnv is the environment for a command obi runs on the session's
+// behalf: obi's own without the unset names, then env.
+func childEnv(env, unset []string) []string {
+	drop := make(map[string]struct{}, len(unset))
+	for _, name := range unset {
+		drop[name] = struct{}{}
+	}
+	var merged []string
+	for _, entry := range os.Environ() {
+		key, _, _ := strings.Cut(entry, "=")
+		if _, ok := drop[key]; !ok {
+			merged = append(merged, entry)
+		}
+	}
+	return append(merged, env...)
+}
+
+// foreignSecrets lists the secrets.from_env names other epics declare, which
+// Codex must not inherit in a session that did not ask for them.
+func foreignSecrets(cfg *config.Config, own []string) []string {
+	keep := map[string]struct{}{}
+	for _, name := range own {
+		keep[strings.TrimSpace(name)] = struct{}{}
+	}
+	var names []string
+	for _, name := range cfg.SecretNames() {
+		if _, ok := keep[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
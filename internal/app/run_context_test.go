@@ -0,0 +1,97 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestMergeEnvOverridesByKey(t *testing.T) {
+	got := mergeEnv([]string{"A=1", "B=2"}, []string{"B=override", "C=3"})
+	want := []string{"A=1", "B=override", "C=3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mergeEnv=%v want %v", got, want)
+	}
+}
+
+func TestEpicSecretEnvReadsValuesForRedaction(t *testing.T) {
+	t.Setenv("OBI_TEST_STRIPE", "sk_test_123")
+	env, values, err := epicSecretEnv([]string{"OBI_TEST_STRIPE"})
+	if err != nil {
+		t.Fatalf("epicSecretEnv: %v", err)
+	}
+	if !reflect.DeepEqual(env, []string{"OBI_TEST_STRIPE=sk_test_123"}) || !reflect.DeepEqual(values, []string{"sk_test_123"}) {
+		t.Fatalf("env=%v values=%v", env, values)
+	}
+
+	t.Setenv("OBI_TEST_MISSING", "")
+	_, _, err = epicSecretEnv([]string{"OBI_TEST_MISSING"})
+	if err == nil || !strings.Contains(err.Error(), "OBI_TEST_MISSING") {
+		t.Fatalf("expected missing secret error, got %v", err)
+	}
+}
+
+func TestForeignSecretsSkipsOwnNames(t *testing.T) {
+	cfg := &config.Config{Epics: map[string]config.EpicConfig{
+		"pay":  {Secrets: config.EpicSecretsConfig{FromEnv: []string{"STRIPE_KEY", "SHARED"}}},
+		"ship": {Secrets: config.EpicSecretsConfig{FromEnv: []string{"SHIPPO_KEY", "SHARED"}}},
+	}}
+	if got := foreignSecrets(cfg, []string{"STRIPE_KEY", "SHARED"}); !reflect.DeepEqual(got, []string{"SHIPPO_KEY"}) {
+		t.Fatalf("foreignSecrets=%v", got)
+	}
+}
+
+func TestEnvFlagRejectsMissingKey(t *testing.T) {
+	var env envFlag
+	if err := env.Set("FEATURE=on"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := env.Set("=oops"); err == nil {
+		t.Fatalf("expected error for empty key")
+	}
+	if err := env.Set("NOEQUALS"); err == nil {
+		t.Fatalf("expected error for missing '='")
+	}
+	if len(env) != 1 {
+		t.Fatalf("unexpected env values: %v", env)
+	}
+}
+
+func TestParseGoOptionsCollectsEnvAndDir(t *testing.T) {
+	opts, err := parseGoOptions([]string{"foo", "--env", "A=1", "--dir", "sub", "--env=B=2"})
+	if err != nil {
+		t.Fatalf("parseGoOptions: %v", err)
+	}
+	if opts.dir != "sub" || !reflect.DeepEqual(opts.env, []string{"A=1", "B=2"}) {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+}
+
+func TestApplyRunContextResolvesEpicDirFromRepoRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "svc"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	plan := sessionPlan{RepoRoot: root, Dir: "svc", Env: []string{"A=epic"}}
+	if err := applyRunContext(&plan, "", []string{"A=cli"}); err != nil {
+		t.Fatalf("applyRunContext: %v", err)
+	}
+	if plan.Dir != filepath.Join(root, "svc") {
+		t.Fatalf("expected dir under repo root, got %q", plan.Dir)
+	}
+	if !reflect.DeepEqual(plan.Env, []string{"A=cli"}) {
+		t.Fatalf("expected CLI env to win, got %v", plan.Env)
+	}
+}
+
+func TestApplyRunContextRejectsMissingDir(t *testing.T) {
+	plan := sessionPlan{RepoRoot: t.TempDir()}
+	err := applyRunContext(&plan, filepath.Join(plan.RepoRoot, "missing"), nil)
+	if err == nil || !strings.Contains(err.Error(), "codex working directory") {
+		t.Fatalf("expected missing dir error, got %v", err)
+	}
+}
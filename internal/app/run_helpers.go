@@ -9,17 +9,21 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
 )
 
 const redactionEnv = "OBI_REDACT"
 
-func openTranscriptWriter(logPath, overridePath, sessionID string) (io.WriteCloser, string, error) {
+// openTranscriptWriter creates the session transcript: overridePath when
+// set, otherwise the file tmpl names under the transcripts directory.
+func openTranscriptWriter(logPath, overridePath, tmpl string, fields transcriptFields) (io.WriteCloser, string, error) {
 	target := strings.TrimSpace(overridePath)
 	if target != "" {
 		if err := ensureTranscriptDir(filepath.Dir(target)); err != nil {
 			return nil, "", err
 		}
-		f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o600)
 		if err != nil {
 			return nil, "", fmt.Errorf("open transcript: %w", err)
 		}
@@ -29,26 +33,31 @@ func openTranscriptWriter(logPath, overridePath, sessionID string) (io.WriteClos
 	if strings.TrimSpace(logPath) == "" {
 		return nil, "", fmt.Errorf("transcript storage requires results log path or explicit --out target")
 	}
-	if strings.TrimSpace(sessionID) == "" {
+	if strings.TrimSpace(fields.SessionID) == "" {
 		return nil, "", fmt.Errorf("session id required to name transcript")
 	}
 
-	baseDir := filepath.Dir(logPath)
-	transcriptDir := filepath.Join(baseDir, "transcripts")
-	if err := ensureTranscriptDir(transcriptDir); err != nil {
+	rel, err := transcriptRelPath(tmpl, fields)
+	if err != nil {
+		return nil, "", err
+	}
+	target = filepath.Join(transcriptDirFor(logPath), rel)
+	if err := ensureTranscriptDir(filepath.Dir(target)); err != nil {
 		return nil, "", err
 	}
 
-	filename := fmt.Sprintf("%s.log", sanitizeFilename(sessionID))
-	target = filepath.Join(transcriptDir, filename)
-
-	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o600)
 	if err != nil {
 		return nil, "", fmt.Errorf("open transcript: %w", err)
 	}
 	return f, target, nil
 }
 
+// transcriptDirFor returns where per-session transcripts live next to the results log.
+func transcriptDirFor(logPath string) string {
+	return filepath.Join(filepath.Dir(logPath), "transcripts")
+}
+
 func ensureTranscriptDir(path string) error {
 	if path == "" {
 		path = "."
@@ -101,16 +110,30 @@ func splitSecrets(raw string) []string {
 	return secrets
 }
 
+// configDigest hashes every file that makes up the config, so edits to any
+// fragment change the digest.
 func configDigest(path string) string {
-	data, err := os.ReadFile(path)
+	sources, err := config.Sources(path)
 	if err != nil {
 		return ""
 	}
-	sum := sha256.Sum256(data)
-	return hex.EncodeToString(sum[:])
+	hash := sha256.New()
+	for _, source := range sources {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return ""
+		}
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
 }
 
-func promptHash(prompt string) string {
+// promptHash hashes prompt with its session ID replaced by the placeholder,
+// so reruns of an unchanged prompt hash the same.
+func promptHash(prompt, sessionID string) string {
+	if sessionID != "" {
+		prompt = strings.ReplaceAll(prompt, sessionID, promptSessionPlaceholder)
+	}
 	sum := sha256.Sum256([]byte(prompt))
 	return hex.EncodeToString(sum[:])
 }
@@ -139,6 +162,15 @@ type lockedWriter struct {
 	w  io.Writer
 }
 
+// sessionTee fans the redacted session stream out to the transcript and the
+// operator log (which watches for replies to hints and soft stops).
+func sessionTee(transcript io.Writer, opLog *operatorLog) io.Writer {
+	if transcript == nil {
+		return opLog
+	}
+	return io.MultiWriter(transcript, opLog)
+}
+
 func newLockedWriter(w io.Writer) *lockedWriter {
 	if w == nil {
 		return nil
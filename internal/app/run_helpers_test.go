@@ -11,7 +11,7 @@ func TestOpenTranscriptWriterCreatesDefaultFile(t *testing.T) {
 	dir := t.TempDir()
 	logPath := filepath.Join(dir, "obi-results.log")
 
-	w, path, err := openTranscriptWriter(logPath, "", "session-ABC")
+	w, path, err := openTranscriptWriter(logPath, "", "", transcriptFields{SessionID: "session-ABC"})
 	if err != nil {
 		t.Fatalf("open writer: %v", err)
 	}
@@ -32,7 +32,7 @@ func TestOpenTranscriptWriterHonorsOverride(t *testing.T) {
 	dir := t.TempDir()
 	target := filepath.Join(dir, "logs", "session.txt")
 
-	w, path, err := openTranscriptWriter("", target, "ignored")
+	w, path, err := openTranscriptWriter("", target, "", transcriptFields{SessionID: "ignored"})
 	if err != nil {
 		t.Fatalf("open writer: %v", err)
 	}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// runEnvVersionTimeout bounds each version probe so a hung tool cannot
+// delay the session.
+const runEnvVersionTimeout = 5 * time.Second
+
+// runEnvironment is the host a run executed on, recorded with
+// record_environment = true. Versions are the first line each tool prints
+// for --version; a tool that is missing or fails leaves its field empty.
+type runEnvironment struct {
+	ObiGo        string `json:"obi_go"`
+	OS           string `json:"os"`
+	Arch         string `json:"arch"`
+	Hostname     string `json:"hostname,omitempty"`
+	GoVersion    string `json:"go_version,omitempty"`
+	CodexVersion string `json:"codex_version,omitempty"`
+	BdVersion    string `json:"bd_version,omitempty"`
+	// GitRemote is the origin URL with any credentials stripped.
+	GitRemote string `json:"git_remote,omitempty"`
+}
+
+// captureRunEnvironment probes the tool versions and checkout of a run in
+// dir, where codexBinary is the Codex executable the run launches.
+func captureRunEnvironment(codexBinary, dir string) *runEnvironment {
+	env := &runEnvironment{
+		ObiGo:        runtime.Version(),
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		GoVersion:    toolVersion(dir, "go", "version"),
+		CodexVersion: toolVersion(dir, codexBinary, "--version"),
+		BdVersion:    toolVersion(dir, "bd", "--version"),
+	}
+	env.Hostname, _ = os.Hostname()
+	if remote, err := gitOutput(dir, "remote", "get-url", "origin"); err == nil {
+		env.GitRemote = redactRemoteURL(remote)
+	}
+	return env
+}
+
+func toolVersion(dir, binary string, args ...string) string {
+	if binary == "" {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), runEnvVersionTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = dir
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return ""
+	}
+	return truncate(firstLine(strings.TrimSpace(stdout.String())), 120)
+}
+
+// redactRemoteURL drops the user and password from an http(s) remote, where
+// tokens often live. scp-style remotes (git@host:path) only name a user.
+func redactRemoteURL(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.User == nil || u.Host == "" {
+		return remote
+	}
+	u.User = nil
+	return u.String()
+}
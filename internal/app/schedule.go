@@ -0,0 +1,134 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+const scheduleDateLayout = "2006-01-02"
+
+// runSchedule is a parsed [schedule] block. The zero value allows any time.
+type runSchedule struct {
+	// hours is the allowed_hours text for messages; empty means all day.
+	hours      string
+	start, end int // minutes after local midnight
+	blackouts  []blackoutRange
+}
+
+// blackoutRange covers whole local days, first through last inclusive.
+type blackoutRange struct {
+	label       string
+	first, last time.Time
+}
+
+// parseSchedule validates allowed_hours ("HH:MM-HH:MM", wrapping past
+// midnight when the end is earlier) and blackout_dates ("YYYY-MM-DD" or
+// "YYYY-MM-DD..YYYY-MM-DD") in loc.
+func parseSchedule(cfg config.ScheduleConfig, loc *time.Location) (runSchedule, error) {
+	var sched runSchedule
+	if hours := strings.TrimSpace(cfg.AllowedHours); hours != "" {
+		from, to, ok := strings.Cut(hours, "-")
+		start, err1 := parseClock(from)
+		end, err2 := parseClock(to)
+		if !ok || err1 != nil || err2 != nil || start == end {
+			return runSchedule{}, fmt.Errorf("schedule.allowed_hours %q: want a window such as \"08:00-19:00\"", cfg.AllowedHours)
+		}
+		sched.hours, sched.start, sched.end = hours, start, end
+	}
+	for _, raw := range cfg.BlackoutDates {
+		value := strings.TrimSpace(raw)
+		from, to, isRange := strings.Cut(value, "..")
+		if !isRange {
+			to = from
+		}
+		first, err1 := time.ParseInLocation(scheduleDateLayout, strings.TrimSpace(from), loc)
+		last, err2 := time.ParseInLocation(scheduleDateLayout, strings.TrimSpace(to), loc)
+		if err1 != nil || err2 != nil || last.Before(first) {
+			return runSchedule{}, fmt.Errorf("schedule.blackout_dates %q: want YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD", raw)
+		}
+		sched.blackouts = append(sched.blackouts, blackoutRange{label: value, first: first, last: last})
+	}
+	return sched, nil
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// blocked explains why now falls outside the schedule, or returns "".
+func (s runSchedule) blocked(now time.Time) string {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, b := range s.blackouts {
+		if !day.Before(b.first) && !day.After(b.last) {
+			return fmt.Sprintf("%s is a blackout date (%s)", day.Format(scheduleDateLayout), b.label)
+		}
+	}
+	if s.hours == "" {
+		return ""
+	}
+	minute := now.Hour()*60 + now.Minute()
+	inside := minute >= s.start && minute < s.end
+	if s.start > s.end {
+		inside = minute >= s.start || minute < s.end
+	}
+	if !inside {
+		return fmt.Sprintf("%s is outside allowed hours %s", now.Format("15:04"), s.hours)
+	}
+	return ""
+}
+
+// closesAt is the next moment after now the schedule stops allowing runs:
+// the end of the hours window or the start of a blackout, whichever comes
+// first. It is zero when the schedule never closes.
+func (s runSchedule) closesAt(now time.Time) time.Time {
+	var closes time.Time
+	earliest := func(t time.Time) {
+		if t.After(now) && (closes.IsZero() || t.Before(closes)) {
+			closes = t
+		}
+	}
+	if s.hours != "" {
+		end := time.Date(now.Year(), now.Month(), now.Day(), s.end/60, s.end%60, 0, 0, now.Location())
+		if !end.After(now) {
+			end = end.AddDate(0, 0, 1)
+		}
+		earliest(end)
+	}
+	for _, b := range s.blackouts {
+		earliest(b.first)
+	}
+	return closes
+}
+
+// ensureScheduleOpen refuses to start obi go outside the [schedule] window.
+func ensureScheduleOpen(cfg *config.Config, now time.Time) error {
+	sched, err := parseSchedule(cfg.Schedule, now.Location())
+	if err != nil {
+		return err
+	}
+	if reason := sched.blocked(now); reason != "" {
+		return newExitError(fmt.Sprintf("Not launching Codex: %s ([schedule]).", reason))
+	}
+	return nil
+}
+
+// watchSchedule soft-stops the running session when the schedule closes,
+// so a session that straddles the boundary wraps up instead of committing
+// during a freeze. The returned func cancels the watch.
+func watchSchedule(sched runSchedule, session signalSession, now time.Time) func() {
+	closes := sched.closesAt(now)
+	if closes.IsZero() {
+		return func() {}
+	}
+	timer := time.AfterFunc(closes.Sub(now), func() {
+		_ = session.SoftStop("obi [schedule]: " + sched.blocked(closes))
+	})
+	return func() { timer.Stop() }
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestRunScheduleBlocksOutsideWindow(t *testing.T) {
+	sched, err := parseSchedule(config.ScheduleConfig{AllowedHours: "08:00-19:00", BlackoutDates: []string{"2026-12-24", "2026-12-28..2027-01-02"}}, time.UTC)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	at := func(s string) time.Time {
+		t.Helper()
+		ts, err := time.ParseInLocation("2006-01-02 15:04", s, time.UTC)
+		if err != nil {
+			t.Fatalf("parse time: %v", err)
+		}
+		return ts
+	}
+	for now, want := range map[string]string{
+		"2026-10-16 08:00": "",
+		"2026-10-16 18:59": "",
+		"2026-10-16 19:00": "outside allowed hours 08:00-19:00",
+		"2026-10-16 07:30": "outside allowed hours",
+		"2026-12-24 10:00": "2026-12-24 is a blackout date",
+		"2027-01-01 10:00": "blackout date (2026-12-28..2027-01-02)",
+		"2027-01-03 10:00": "",
+	} {
+		got := sched.blocked(at(now))
+		if (want == "") != (got == "") || !strings.Contains(got, want) {
+			t.Fatalf("blocked(%s) = %q, want %q", now, got, want)
+		}
+	}
+
+	if got := sched.closesAt(at("2026-10-16 09:00")); !got.Equal(at("2026-10-16 19:00")) {
+		t.Fatalf("closesAt = %v", got)
+	}
+	if got := sched.closesAt(at("2026-12-23 22:00")); !got.Equal(at("2026-12-24 00:00")) {
+		t.Fatalf("closesAt before blackout = %v", got)
+	}
+}
+
+func TestRunScheduleWrapsPastMidnight(t *testing.T) {
+	sched, err := parseSchedule(config.ScheduleConfig{AllowedHours: "22:00-06:00"}, time.UTC)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	night := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+	if got := sched.blocked(night); got != "" {
+		t.Fatalf("23:00 blocked: %q", got)
+	}
+	if got := sched.blocked(night.Add(8 * time.Hour)); got == "" {
+		t.Fatal("07:00 should be outside 22:00-06:00")
+	}
+	if got := sched.closesAt(night); !got.Equal(time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC)) {
+		t.Fatalf("closesAt = %v", got)
+	}
+}
+
+func TestParseScheduleRejectsBadValues(t *testing.T) {
+	for _, cfg := range []config.ScheduleConfig{
+		{AllowedHours: "8am-7pm"},
+		{AllowedHours: "09:00-09:00"},
+		{BlackoutDates: []string{"12/24"}},
+		{BlackoutDates: []string{"2027-01-02..2026-12-28"}},
+	} {
+		if _, err := parseSchedule(cfg, time.UTC); err == nil || !strings.Contains(err.Error(), "schedule.") {
+			t.Fatalf("%+v: expected error, got %v", cfg, err)
+		}
+	}
+}
+
+type fakeSoftStopper struct{ reasons chan string }
+
+func (f fakeSoftStopper) SoftStop(reason string) error {
+	f.reasons <- reason
+	return nil
+}
+
+func (f fakeSoftStopper) Abort() error { return nil }
+
+func TestWatchScheduleSoftStopsWhenWindowCloses(t *testing.T) {
+	now := time.Now()
+	// A blackout starting a moment from now stands in for midnight.
+	freeze := now.Add(50 * time.Millisecond)
+	sched := runSchedule{blackouts: []blackoutRange{{label: "freeze", first: freeze, last: freeze}}}
+	stopper := fakeSoftStopper{reasons: make(chan string, 1)}
+	cancel := watchSchedule(sched, stopper, now)
+	defer cancel()
+	select {
+	case reason := <-stopper.reasons:
+		if !strings.HasPrefix(reason, "obi [schedule]") {
+			t.Fatalf("soft stop reason = %q", reason)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("schedule watch never soft-stopped the session")
+	}
+
+	if cancel := watchSchedule(runSchedule{}, stopper, now); cancel == nil {
+		t.Fatal("an open schedule should still return a cancel func")
+	}
+}
@@ -0,0 +1,384 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+)
+
+// Events obi schedule appends to schedule.log.
+const (
+	scheduleEventStarted  = "started"
+	scheduleEventFinished = "finished"
+	scheduleEventSkipped  = "skipped"
+)
+
+// scheduledJob is a validated [[schedule.jobs]] entry.
+type scheduledJob struct {
+	name string
+	cron cronSpec
+	// args are the obi arguments that run the job, e.g. ["go", "api"].
+	args []string
+}
+
+// target is the command line the job runs, for logs and notifications.
+func (j scheduledJob) target() string {
+	return "obi " + strings.Join(j.args, " ")
+}
+
+// scheduleEvent is one line of schedule.log.
+type scheduleEvent struct {
+	Time       time.Time `json:"time"`
+	Job        string    `json:"job"`
+	Target     string    `json:"target"`
+	Event      string    `json:"event"`
+	Reason     string    `json:"reason,omitempty"`
+	ExitCode   *int      `json:"exit_code,omitempty"`
+	DurationMs int64     `json:"duration_ms,omitempty"`
+}
+
+// scheduleLock is what schedule.lock holds while a job runs. PID is the
+// job's process once it has started, the daemon's until then.
+type scheduleLock struct {
+	PID       int       `json:"pid"`
+	Job       string    `json:"job"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// scheduler fires jobs on their cron minutes, one at a time: a job that
+// comes due while another holds the lock is skipped and recorded, never
+// queued.
+type scheduler struct {
+	jobs     []scheduledJob
+	window   runSchedule
+	logPath  string
+	lockPath string
+	webhook  string
+	out      io.Writer
+	// launch runs one job to completion and returns its exit code, calling
+	// started with the job's PID once it is running.
+	launch func(job scheduledJob, started func(pid int)) (int, error)
+
+	wg sync.WaitGroup
+}
+
+func runScheduler(args []string) error {
+	fs := newCommandFlags("schedule", "obi schedule [options]",
+		"Run the [[schedule.jobs]] entries unattended: each job launches obi go <alias> or\nobi run <template> with --ci --yes --no-tui when its cron expression matches.")
+	var configPath, profile string
+	var once, list bool
+	fs.StringVar(&configPath, "config", "", "path to obi config")
+	fs.StringVar(&profile, "profile", "", "apply this [profiles.*] overlay to every job (defaults to $OBI_PROFILE)")
+	fs.BoolVar(&once, "once", false, "fire the jobs due this minute, wait for them, and exit (for system cron)")
+	fs.BoolVar(&list, "list", false, "print each job with its next run time and exit")
+	if _, err := fs.parse(args); err != nil {
+		return err
+	}
+
+	resolved, cfg, err := loadConfig(configPath, profile)
+	if err != nil {
+		return err
+	}
+	jobs, err := parseScheduledJobs(cfg, resolved, config.ResolveProfile(profile))
+	if err != nil {
+		return err
+	}
+	if list {
+		printScheduledJobs(os.Stdout, jobs, time.Now())
+		return nil
+	}
+	s, err := newScheduler(cfg, jobs, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if once {
+		s.tick(time.Now().Truncate(time.Minute))
+		s.wg.Wait()
+		return nil
+	}
+	return s.run()
+}
+
+// parseScheduledJobs validates [[schedule.jobs]] and builds the obi command
+// line each one runs against the config at configPath.
+func parseScheduledJobs(cfg *config.Config, configPath, profile string) ([]scheduledJob, error) {
+	if len(cfg.Schedule.Jobs) == 0 {
+		return nil, errors.New("no [[schedule.jobs]] entries in obi.toml; add one with cron = \"...\" and an alias or template")
+	}
+	var jobs []scheduledJob
+	seen := map[string]bool{}
+	for i, raw := range cfg.Schedule.Jobs {
+		alias, tmpl := strings.TrimSpace(raw.Alias), strings.TrimSpace(raw.Template)
+		label := fmt.Sprintf("schedule.jobs[%d]", i)
+		if (alias == "") == (tmpl == "") {
+			return nil, fmt.Errorf("%s: set exactly one of alias or template", label)
+		}
+		var args []string
+		if alias != "" {
+			if _, _, err := resolveEpic(cfg, alias); err != nil {
+				if _, ok := groupTarget(alias); !ok {
+					return nil, fmt.Errorf("%s: %w", label, err)
+				}
+			}
+			args = []string{"go", alias}
+		} else {
+			if _, ok := cfg.Templates[tmpl]; !ok {
+				return nil, fmt.Errorf("%s: unknown template %q", label, tmpl)
+			}
+			args = []string{"run", tmpl}
+		}
+		spec, err := parseCron(raw.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", label, err)
+		}
+		name := strings.TrimSpace(raw.Name)
+		if name == "" {
+			name = alias + tmpl
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s: job name %q is used twice; set name = \"...\" to tell them apart", label, name)
+		}
+		seen[name] = true
+		args = append(args, "--ci", "--yes", "--no-tui", "--config", configPath)
+		if profile != "" {
+			args = append(args, "--profile", profile)
+		}
+		jobs = append(jobs, scheduledJob{name: name, cron: spec, args: args})
+	}
+	return jobs, nil
+}
+
+func printScheduledJobs(w io.Writer, jobs []scheduledJob, now time.Time) {
+	for _, job := range jobs {
+		next := "never"
+		if at := job.cron.next(now); !at.IsZero() {
+			next = at.Format("2006-01-02 15:04 MST")
+		}
+		fmt.Fprintf(w, "%s (%s): %s, next %s\n", job.name, job.cron.text, strings.Join(job.args[:2], " "), next)
+	}
+}
+
+func newScheduler(cfg *config.Config, jobs []scheduledJob, out io.Writer) (*scheduler, error) {
+	window, err := parseSchedule(cfg.Schedule, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	logPath, err := cfg.ScheduleLogPath()
+	if err != nil {
+		return nil, err
+	}
+	lockPath, err := cfg.ScheduleLockPath()
+	if err != nil {
+		return nil, err
+	}
+	return &scheduler{
+		jobs:     jobs,
+		window:   window,
+		logPath:  logPath,
+		lockPath: lockPath,
+		webhook:  strings.TrimSpace(cfg.Notify.Webhook),
+		out:      out,
+		launch:   launchScheduledJob,
+	}, nil
+}
+
+// run fires jobs at the top of every minute until SIGINT or SIGTERM, then
+// waits for the running job. The job shares the daemon's process group, so
+// a Ctrl-C reaches its session too.
+func (s *scheduler) run() error {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	fmt.Fprintf(s.out, "obi schedule: %d job(s); logging to %s. Ctrl-C to stop.\n", len(s.jobs), s.logPath)
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case sig := <-sigCh:
+			timer.Stop()
+			fmt.Fprintf(s.out, "obi schedule: %v received; waiting for the running job to finish.\n", sig)
+			s.wg.Wait()
+			return nil
+		case <-timer.C:
+			s.tick(next)
+		}
+	}
+}
+
+// tick fires every job whose cron matches the minute at, in config order.
+func (s *scheduler) tick(at time.Time) {
+	for _, job := range s.jobs {
+		if job.cron.matches(at) {
+			s.fire(job, at)
+		}
+	}
+}
+
+// fire starts job in the background unless the schedule window is closed
+// or another job holds the lock; either way the outcome is recorded.
+func (s *scheduler) fire(job scheduledJob, at time.Time) {
+	if reason := s.window.blocked(at); reason != "" {
+		s.skip(job, at, reason+" ([schedule])")
+		return
+	}
+	lock, holder, err := acquireScheduleLock(s.lockPath, job.name, at)
+	if err != nil {
+		s.skip(job, at, err.Error())
+		return
+	}
+	if holder != nil {
+		s.skip(job, at, fmt.Sprintf("overlaps job %s, running since %s (pid %d)", holder.Job, holder.StartedAt.Format("15:04"), holder.PID))
+		return
+	}
+	s.record(scheduleEvent{Time: at, Job: job.name, Target: job.target(), Event: scheduleEventStarted})
+	fmt.Fprintf(s.out, "obi schedule: starting %s: %s\n", job.name, job.target())
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer releaseScheduleLock(lock)
+		started := time.Now()
+		code, err := s.launch(job, func(pid int) {
+			if err := writeScheduleLock(lock, scheduleLock{PID: pid, Job: job.name, StartedAt: at}); err != nil {
+				diag.Logger().Warn("schedule lock update failed", "path", s.lockPath, "job", job.name, "err", err)
+			}
+		})
+		event := scheduleEvent{Time: time.Now(), Job: job.name, Target: job.target(), Event: scheduleEventFinished, ExitCode: &code, DurationMs: time.Since(started).Milliseconds()}
+		if err != nil {
+			event.Reason = err.Error()
+		}
+		s.record(event)
+		text := fmt.Sprintf("obi schedule: %s finished with exit code %d after %s", job.name, code, time.Since(started).Round(time.Second))
+		if event.Reason != "" {
+			text += " (" + event.Reason + ")"
+		}
+		fmt.Fprintln(s.out, text)
+		s.notify(text)
+	}()
+}
+
+func (s *scheduler) skip(job scheduledJob, at time.Time, reason string) {
+	s.record(scheduleEvent{Time: at, Job: job.name, Target: job.target(), Event: scheduleEventSkipped, Reason: reason})
+	text := fmt.Sprintf("obi schedule: skipped %s: %s", job.name, reason)
+	fmt.Fprintln(s.out, text)
+	s.notify(text)
+}
+
+// record appends event to schedule.log; a write failure only warns so the
+// daemon keeps running.
+func (s *scheduler) record(event scheduleEvent) {
+	if err := appendScheduleEvent(s.logPath, event); err != nil {
+		diag.Logger().Warn("schedule log write failed", "path", s.logPath, "job", event.Job, "err", err)
+	}
+}
+
+func (s *scheduler) notify(text string) {
+	if s.webhook == "" {
+		return
+	}
+	if err := postDigest(s.webhook, text); err != nil {
+		diag.Logger().Warn("notify webhook failed", "err", err)
+	}
+}
+
+func appendScheduleEvent(path string, event scheduleEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode schedule event: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open schedule log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write schedule log: %w", err)
+	}
+	return nil
+}
+
+// acquireScheduleLock takes an exclusive flock on the lock file for job and
+// records the job in it. When another daemon holds the lock, the job it
+// recorded is returned instead. The flock dies with its daemon, so the
+// file never has to be cleaned up; but a record still naming a live
+// process means that daemon died while its job kept running, and the job
+// still counts as the holder.
+func acquireScheduleLock(path, job string, at time.Time) (*os.File, *scheduleLock, error) {
+	lock, err := openLockedFile(path, false)
+	if err != nil && !errors.Is(err, errLockHeld) {
+		return nil, nil, fmt.Errorf("take schedule lock: %w", err)
+	}
+	var holder scheduleLock
+	if raw, readErr := os.ReadFile(path); readErr == nil && len(raw) > 0 {
+		_ = json.Unmarshal(raw, &holder)
+	}
+	if errors.Is(err, errLockHeld) {
+		return nil, &holder, nil
+	}
+	if holder.PID > 0 && holder.PID != os.Getpid() && processAlive(holder.PID) {
+		lock.Close()
+		return nil, &holder, nil
+	}
+	if err := writeScheduleLock(lock, scheduleLock{PID: os.Getpid(), Job: job, StartedAt: at}); err != nil {
+		releaseScheduleLock(lock)
+		return nil, nil, err
+	}
+	return lock, nil, nil
+}
+
+// releaseScheduleLock clears the record, so no later daemon mistakes a
+// reused PID for a running job, and drops the lock.
+func releaseScheduleLock(lock *os.File) {
+	_ = lock.Truncate(0)
+	lock.Close()
+}
+
+// writeScheduleLock replaces the record in the held lock file.
+func writeScheduleLock(lock *os.File, record scheduleLock) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := lock.Truncate(0); err != nil {
+		return fmt.Errorf("write schedule lock: %w", err)
+	}
+	if _, err := lock.WriteAt(data, 0); err != nil {
+		return fmt.Errorf("write schedule lock: %w", err)
+	}
+	return nil
+}
+
+// launchScheduledJob runs job as a child obi process with the daemon's
+// stdout and stderr and no stdin, so nothing can wait on an operator.
+func launchScheduledJob(job scheduledJob, started func(pid int)) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return -1, fmt.Errorf("locate obi binary: %w", err)
+	}
+	cmd := exec.Command(exe, job.args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return -1, err
+	}
+	started(cmd.Process.Pid)
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return -1, err
+	}
+	return 0, nil
+}
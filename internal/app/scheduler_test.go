@@ -0,0 +1,173 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func schedulerTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return &config.Config{
+		ResultsLog: filepath.Join(t.TempDir(), "results.log"),
+		Epics:      map[string]config.EpicConfig{"api": {Name: "API", ID: "bd-api", Alias: "api"}},
+		Templates:  map[string]config.TemplateConfig{"deps-update": {Prompt: "Bump dependencies."}},
+		Schedule: config.ScheduleConfig{Jobs: []config.ScheduleJob{
+			{Cron: "0 3 * * *", Template: "deps-update"},
+			{Name: "api-nightly", Cron: "0 3 * * *", Alias: "api"},
+		}},
+	}
+}
+
+func readScheduleEvents(t *testing.T, path string) []scheduleEvent {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open schedule log: %v", err)
+	}
+	defer f.Close()
+	var events []scheduleEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event scheduleEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("parse %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestParseScheduledJobsBuildsCommandLines(t *testing.T) {
+	cfg := schedulerTestConfig(t)
+	jobs, err := parseScheduledJobs(cfg, "/repo/obi.toml", "ci")
+	if err != nil {
+		t.Fatalf("parseScheduledJobs: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].name != "deps-update" || jobs[1].name != "api-nightly" {
+		t.Fatalf("jobs = %+v", jobs)
+	}
+	if got := jobs[0].target(); got != "obi run deps-update --ci --yes --no-tui --config /repo/obi.toml --profile ci" {
+		t.Fatalf("template target = %q", got)
+	}
+	if got := strings.Join(jobs[1].args[:2], " "); got != "go api" {
+		t.Fatalf("alias args = %q", got)
+	}
+
+	cfg.Schedule.Jobs = append(cfg.Schedule.Jobs, config.ScheduleJob{Cron: "@daily", Alias: "api", Template: "deps-update"})
+	if _, err := parseScheduledJobs(cfg, "/repo/obi.toml", ""); err == nil || !strings.Contains(err.Error(), "exactly one of alias or template") {
+		t.Fatalf("err = %v", err)
+	}
+	cfg.Schedule.Jobs[2] = config.ScheduleJob{Cron: "@daily", Alias: "nope"}
+	if _, err := parseScheduledJobs(cfg, "/repo/obi.toml", ""); err == nil || !strings.Contains(err.Error(), "schedule.jobs[2]") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestSchedulerSkipsOverlappingJobs(t *testing.T) {
+	cfg := schedulerTestConfig(t)
+	jobs, err := parseScheduledJobs(cfg, "/repo/obi.toml", "")
+	if err != nil {
+		t.Fatalf("parseScheduledJobs: %v", err)
+	}
+	s, err := newScheduler(cfg, jobs, io.Discard)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	release := make(chan struct{})
+	var launched []string
+	s.launch = func(job scheduledJob, started func(pid int)) (int, error) {
+		started(4242)
+		launched = append(launched, job.name)
+		<-release
+		return 1, nil
+	}
+
+	at := time.Date(2026, 10, 16, 3, 0, 0, 0, time.Local)
+	s.tick(at)
+	s.tick(at.Add(time.Minute)) // matches nothing
+	close(release)
+	s.wg.Wait()
+
+	if len(launched) != 1 || launched[0] != "deps-update" {
+		t.Fatalf("launched = %v", launched)
+	}
+	if data, err := os.ReadFile(s.lockPath); err != nil || len(data) != 0 {
+		t.Fatalf("lock should be released and cleared, got %q, %v", data, err)
+	}
+	events := readScheduleEvents(t, s.logPath)
+	if len(events) != 3 {
+		t.Fatalf("events = %+v", events)
+	}
+	if events[0].Event != scheduleEventStarted || events[0].Job != "deps-update" {
+		t.Fatalf("first event = %+v", events[0])
+	}
+	if events[1].Event != scheduleEventSkipped || events[1].Job != "api-nightly" || !strings.Contains(events[1].Reason, "overlaps job deps-update") {
+		t.Fatalf("second event = %+v", events[1])
+	}
+	if events[2].Event != scheduleEventFinished || events[2].ExitCode == nil || *events[2].ExitCode != 1 {
+		t.Fatalf("third event = %+v", events[2])
+	}
+}
+
+func TestSchedulerSkipsOutsideWindow(t *testing.T) {
+	cfg := schedulerTestConfig(t)
+	cfg.Schedule.AllowedHours = "08:00-19:00"
+	jobs, err := parseScheduledJobs(cfg, "/repo/obi.toml", "")
+	if err != nil {
+		t.Fatalf("parseScheduledJobs: %v", err)
+	}
+	s, err := newScheduler(cfg, jobs, io.Discard)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	s.launch = func(job scheduledJob, _ func(int)) (int, error) {
+		t.Fatalf("%s launched outside allowed hours", job.name)
+		return 0, nil
+	}
+	s.tick(time.Date(2026, 10, 16, 3, 0, 0, 0, time.Local))
+	s.wg.Wait()
+	events := readScheduleEvents(t, s.logPath)
+	if len(events) != 2 || events[0].Event != scheduleEventSkipped || !strings.Contains(events[0].Reason, "outside allowed hours") {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestAcquireScheduleLockTakesOverStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.lock")
+	stale, _ := json.Marshal(scheduleLock{PID: 1 << 30, Job: "old"})
+	if err := os.WriteFile(path, stale, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	lock, holder, err := acquireScheduleLock(path, "new", time.Now())
+	if err != nil || holder != nil || lock == nil {
+		t.Fatalf("acquire = %+v, %v", holder, err)
+	}
+	if err := writeScheduleLock(lock, scheduleLock{PID: 4242, Job: "new"}); err != nil {
+		t.Fatalf("record job pid: %v", err)
+	}
+	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
+		_, holder, err = acquireScheduleLock(path, "second", time.Now())
+		if err != nil || holder == nil || holder.Job != "new" || holder.PID != 4242 {
+			t.Fatalf("second acquire = %+v, %v", holder, err)
+		}
+	}
+	releaseScheduleLock(lock)
+
+	// A daemon that died mid-job leaves its lock free but the job running.
+	orphan, _ := json.Marshal(scheduleLock{PID: os.Getppid(), Job: "orphan"})
+	if err := os.WriteFile(path, orphan, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if lock, holder, err := acquireScheduleLock(path, "third", time.Now()); err != nil || lock != nil || holder == nil || holder.Job != "orphan" {
+		t.Fatalf("orphaned job should hold the lock, got %+v, %v", holder, err)
+	}
+}
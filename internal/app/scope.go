@@ -0,0 +1,250 @@
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// scopeCheckInterval is how often a running session's changes are compared
+// against guardrails.allowed_paths and forbidden_paths.
+const scopeCheckInterval = 15 * time.Second
+
+// scopeGuard watches the paths Codex changes during one session. Paths
+// already dirty at launch are not held against it while their content stays
+// as it was, and obi's own files never are.
+type scopeGuard struct {
+	dir       string
+	top       string
+	base      string
+	allowed   []string
+	forbidden []string
+	exempt    []string
+	// baseline maps each path dirty at launch to its content digest then.
+	baseline map[string]string
+
+	mu         sync.Mutex
+	violations map[string]string
+}
+
+// newScopeGuard snapshots the working tree in dir before Codex starts. It
+// returns nil when none of allowed_paths, forbidden_paths and abort_cleanup
+// is set, or when dir is not a git checkout.
+func newScopeGuard(cfg *config.Config, dir, configPath string) *scopeGuard {
+	rules := cfg.Guardrails
+	if len(rules.AllowedPaths) == 0 && len(rules.ForbiddenPaths) == 0 && strings.TrimSpace(rules.AbortCleanup) == "" {
+		return nil
+	}
+	top, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	base, err := gitOutput(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil
+	}
+	g := &scopeGuard{
+		dir:        dir,
+		top:        top,
+		base:       base,
+		allowed:    rules.AllowedPaths,
+		forbidden:  rules.ForbiddenPaths,
+		exempt:     obiOwnedPaths(cfg, top, configPath),
+		baseline:   map[string]string{},
+		violations: map[string]string{},
+	}
+	if entries, err := g.status(); err == nil {
+		for _, entry := range entries {
+			g.baseline[entry.Path] = worktreeDigest(filepath.Join(top, filepath.FromSlash(entry.Path)))
+		}
+	}
+	return g
+}
+
+// worktreeDigest fingerprints the file at path as it is on disk: a hash of
+// its content, of a symlink's target, or a marker for a missing file.
+func worktreeDigest(path string) string {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "missing"
+	}
+	h := sha256.New()
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "unreadable"
+		}
+		h.Write([]byte("link\x00" + target))
+	case info.Mode().IsRegular():
+		f, err := os.Open(path)
+		if err != nil {
+			return "unreadable"
+		}
+		defer f.Close()
+		h.Write([]byte(fmt.Sprintf("file\x00%o\x00", info.Mode().Perm())))
+		if _, err := io.Copy(h, f); err != nil {
+			return "unreadable"
+		}
+	default:
+		return "other:" + info.Mode().Type().String()
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (g *scopeGuard) status() ([]dirtyEntry, error) {
+	cmd := exec.Command("git", "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	cmd.Dir = g.dir
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+	return parsePorcelainZ(stdout.Bytes()), nil
+}
+
+// changedPaths lists paths, relative to the git root, that Codex committed
+// or left changed since the guard was created. A path dirty at launch only
+// counts once its content differs from the launch snapshot; a committed
+// path always counts, since the commit took it in either way.
+func (g *scopeGuard) changedPaths() ([]string, error) {
+	entries, err := g.status()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var paths []string
+	add := func(p string) {
+		if p == "" || seen[p] {
+			return
+		}
+		seen[p] = true
+		paths = append(paths, p)
+	}
+	if committed, err := gitOutput(g.dir, "diff", "--name-only", "--no-renames", g.base, "HEAD"); err == nil {
+		for _, p := range strings.Split(committed, "\n") {
+			add(strings.TrimSpace(p))
+		}
+	}
+	for _, entry := range entries {
+		if digest, ok := g.baseline[entry.Path]; ok && !seen[entry.Path] &&
+			worktreeDigest(filepath.Join(g.top, filepath.FromSlash(entry.Path))) == digest {
+			continue
+		}
+		add(entry.Path)
+	}
+	return paths, nil
+}
+
+// cleanupPaths lists the paths abort_cleanup may discard: those Codex
+// changed this session, less obi's own files. Edits the operator made
+// before launch stay out unless Codex changed the same file again.
+func (g *scopeGuard) cleanupPaths() ([]string, error) {
+	if g == nil {
+		return nil, fmt.Errorf("the session's changes are unknown outside a git checkout")
+	}
+	paths, err := g.changedPaths()
+	if err != nil {
+		return nil, err
+	}
+	kept := paths[:0]
+	for _, p := range paths {
+		if !pathAllowed(p, g.exempt) {
+			kept = append(kept, p)
+		}
+	}
+	return kept, nil
+}
+
+// check records any new out-of-scope changes and returns them as
+// "path (reason)" lines.
+func (g *scopeGuard) check() []string {
+	paths, err := g.changedPaths()
+	if err != nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var fresh []string
+	for _, p := range paths {
+		if _, ok := g.violations[p]; ok || pathAllowed(p, g.exempt) {
+			continue
+		}
+		if reason := scopeViolation(p, g.allowed, g.forbidden); reason != "" {
+			g.violations[p] = reason
+			fresh = append(fresh, fmt.Sprintf("%s (%s)", p, reason))
+		}
+	}
+	return fresh
+}
+
+// recorded returns every violation seen so far, sorted by path.
+func (g *scopeGuard) recorded() []string {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var out []string
+	for p, reason := range g.violations {
+		out = append(out, fmt.Sprintf("%s (%s)", p, reason))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// watch checks every interval and soft-stops the session the first time a
+// change falls outside the allowed scope. The returned func stops the watch
+// and runs one last check so late changes still reach the ledger.
+func (g *scopeGuard) watch(session signalSession, interval time.Duration) func() {
+	if g == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if fresh := g.check(); len(fresh) > 0 {
+					_ = session.SoftStop("obi [guardrails]: Codex changed files outside its allowed scope: " + strings.Join(fresh, ", "))
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		g.check()
+	}
+}
+
+// scopeViolation explains why p breaks the path rules, or returns "".
+// forbidden_paths wins over allowed_paths; an empty allowed_paths allows
+// everything not forbidden.
+func scopeViolation(p string, allowed, forbidden []string) string {
+	if pathAllowed(p, forbidden) {
+		return "matches guardrails.forbidden_paths"
+	}
+	if len(allowed) > 0 && !pathAllowed(p, allowed) {
+		return "outside guardrails.allowed_paths"
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestScopeViolation(t *testing.T) {
+	allowed := []string{"services/api/", "docs/*.md"}
+	forbidden := []string{"services/api/secrets.go", "*.lock"}
+	for p, want := range map[string]string{
+		"services/api/handler.go": "",
+		"docs/intro.md":           "",
+		"services/api/secrets.go": "forbidden_paths",
+		"go.lock":                 "forbidden_paths",
+		"services/web/app.go":     "outside guardrails.allowed_paths",
+	} {
+		got := scopeViolation(p, allowed, forbidden)
+		if (want == "") != (got == "") || !strings.Contains(got, want) {
+			t.Fatalf("scopeViolation(%q) = %q, want %q", p, got, want)
+		}
+	}
+	if got := scopeViolation("anything.go", nil, forbidden); got != "" {
+		t.Fatalf("empty allowed_paths should allow %q, got %q", "anything.go", got)
+	}
+}
+
+func TestScopeGuardSoftStopsOnOutOfScopeChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=obi", "-c", "user.email=obi@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name string) {
+		t.Helper()
+		full := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	write("README.md")
+	git("add", ".")
+	git("commit", "-q", "-m", "root")
+	write("notes.txt") // dirty before the session; never held against Codex
+
+	cfg := &config.Config{
+		ResultsLog: filepath.Join(repo, "obi-results.log"),
+		Guardrails: config.GuardrailsConfig{AllowedPaths: []string{"api/"}, ForbiddenPaths: []string{"api/vendor/"}},
+	}
+	guard := newScopeGuard(cfg, repo, filepath.Join(repo, "obi.toml"))
+	if guard == nil {
+		t.Fatal("expected a scope guard")
+	}
+
+	write("api/handler.go")
+	write("obi-results.log")
+	if fresh := guard.check(); len(fresh) != 0 {
+		t.Fatalf("in-scope changes flagged: %q", fresh)
+	}
+
+	stopper := fakeSoftStopper{reasons: make(chan string, 1)}
+	stop := guard.watch(stopper, 10*time.Millisecond)
+	write("web/app.go")
+	git("add", "web/app.go")
+	git("commit", "-q", "-m", "codex commit")
+	select {
+	case reason := <-stopper.reasons:
+		if !strings.Contains(reason, "web/app.go (outside guardrails.allowed_paths)") {
+			t.Fatalf("soft stop reason = %q", reason)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("scope watch never soft-stopped the session")
+	}
+	write("api/vendor/lib.go")
+	stop()
+	// Editing a file that was already dirty at launch counts.
+	if err := os.WriteFile(filepath.Join(repo, "notes.txt"), []byte("changed by codex\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	guard.check()
+
+	got := strings.Join(guard.recorded(), "; ")
+	if got != "api/vendor/lib.go (matches guardrails.forbidden_paths); notes.txt (outside guardrails.allowed_paths); web/app.go (outside guardrails.allowed_paths)" {
+		t.Fatalf("recorded = %q", got)
+	}
+
+	if newScopeGuard(&config.Config{}, repo, "") != nil {
+		t.Fatal("expected no guard without path rules")
+	}
+}
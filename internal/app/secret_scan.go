@@ -0,0 +1,112 @@
+package app
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+const (
+	entropyMinLength  = 20
+	entropyThreshold  = 4.0
+	secretPlaceholder = "[REDACTED]"
+)
+
+type secretPattern struct {
+	kind    string
+	pattern *regexp.Regexp
+}
+
+// knownSecretPatterns match token formats with well-known prefixes.
+var knownSecretPatterns = []secretPattern{
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?(-----END [A-Z ]*PRIVATE KEY-----|$)`)},
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"OpenAI key", regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9_-]{20,}`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"JWT", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+}
+
+var entropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/=_-]{20,}`)
+
+// secretFinding records one suspected secret (the value itself is not kept).
+type secretFinding struct {
+	Kind string
+}
+
+// scrubDetectedSecrets redacts known token formats and high-entropy strings
+// from text, returning the scrubbed text plus what was found.
+func scrubDetectedSecrets(text string) (string, []secretFinding) {
+	if strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+	var findings []secretFinding
+	out := text
+	for _, p := range knownSecretPatterns {
+		out = p.pattern.ReplaceAllStringFunc(out, func(string) string {
+			findings = append(findings, secretFinding{Kind: p.kind})
+			return secretPlaceholder
+		})
+	}
+	out = entropyCandidate.ReplaceAllStringFunc(out, func(candidate string) string {
+		if !looksHighEntropy(candidate) {
+			return candidate
+		}
+		findings = append(findings, secretFinding{Kind: "high-entropy string"})
+		return secretPlaceholder
+	})
+	return out, findings
+}
+
+// looksHighEntropy flags mixed letter/digit runs whose Shannon entropy exceeds
+// what hex digests (commit SHAs, checksums) can reach.
+func looksHighEntropy(candidate string) bool {
+	if len(candidate) < entropyMinLength {
+		return false
+	}
+	var hasLetter, hasDigit bool
+	for _, r := range candidate {
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			hasLetter = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return false
+	}
+	return shannonEntropy(candidate) > entropyThreshold
+}
+
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func findingKinds(findings []secretFinding) []string {
+	seen := map[string]struct{}{}
+	var kinds []string
+	for _, f := range findings {
+		if _, ok := seen[f.Kind]; ok {
+			continue
+		}
+		seen[f.Kind] = struct{}{}
+		kinds = append(kinds, f.Kind)
+	}
+	return kinds
+}
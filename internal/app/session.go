@@ -13,6 +13,8 @@ type sessionMode int
 const (
 	sessionModeWork sessionMode = iota
 	sessionModeSummary
+	// sessionModeTemplate is an obi run chore; see planFromTemplate.
+	sessionModeTemplate
 )
 
 type sessionPlan struct {
@@ -34,6 +36,48 @@ type sessionPlan struct {
 	SummaryIncluded      int
 	SummaryTotal         int
 	BeadIDOverride       string
+	Dir                  string
+	Env                  []string
+	SecretEnv            []string
+	VerifyCommand        string
+	ContextPaths         []string
+	ContextFiles         []contextFile
+	// OperatorNote is the --note text added to this run's prompt.
+	OperatorNote string
+	// SkippedBeads were skipped at a --checkpoint; the prompt tells Codex
+	// to leave them for the rest of the loop.
+	SkippedBeads []string
+	Escalation   config.EscalationConfig
+	// FallbackFrom lists the models that were unavailable before
+	// Codex.Model, oldest first.
+	FallbackFrom []string
+	// StateBanner is the epic snapshot echoed into the TUI log; only the
+	// first session of a loop carries one.
+	StateBanner string
+	// InLoop marks a session of the epic loop, which looks for the next
+	// ready bead itself once the session ends.
+	InLoop bool
+	// CheckDuplicates asks for the duplicate-prompt check before launch;
+	// later sessions of a loop reuse the same prompt on purpose.
+	CheckDuplicates bool
+	// ReadOnly marks an exploratory --read-only session; see applyReadOnly.
+	ReadOnly bool
+	// ReadyBeads are the beads listed in the ready section of the prompt;
+	// see loadReadyList.
+	ReadyBeads []readyIssue
+	// Continue is the earlier run whose Codex session the next session
+	// resumes (obi go --continue-codex); only the first session of a loop
+	// carries one.
+	Continue *codexContinuation
+	// Branch is the rendered [epic.x] branch the sessions must run on, or
+	// empty to stay on the current branch.
+	Branch string
+	// Simulated is the fakecodex scenario obi go --simulate plays instead
+	// of running Codex; see applySimulation.
+	Simulated string
+	// Experiment pins the session to one bead as a variant of obi
+	// experiment; see experiment.go.
+	Experiment *experimentArm
 }
 
 func prepareSession(cfg *config.Config, requestedAlias string) (sessionPlan, error) {
@@ -41,16 +85,28 @@ func prepareSession(cfg *config.Config, requestedAlias string) (sessionPlan, err
 	if err != nil {
 		return sessionPlan{}, err
 	}
-	return sessionPlan{
-		EpicKey:    key,
-		EpicName:   target.Name,
-		Alias:      aliasFromRequest(requestedAlias, key, target),
-		EpicID:     target.ID,
-		Tool:       target.Tool,
-		EpicPrompt: target.Prompt,
-		BasePrompt: cfg.BasePrompt,
-		Codex:      cfg.EffectiveCodex(target),
-	}, nil
+	plan := sessionPlan{
+		EpicKey:       key,
+		EpicName:      target.Name,
+		Alias:         aliasFromRequest(requestedAlias, key, target),
+		EpicID:        target.ID,
+		Tool:          target.Tool,
+		EpicPrompt:    target.Prompt,
+		BasePrompt:    cfg.BasePrompt,
+		Codex:         cfg.EffectiveCodex(target),
+		Dir:           target.Dir,
+		Env:           envFromMap(target.Env),
+		SecretEnv:     target.Secrets.FromEnv,
+		VerifyCommand: strings.TrimSpace(target.Verify.Command),
+		ContextPaths:  target.ContextFiles,
+		Escalation:    cfg.EffectiveEscalation(target),
+	}
+	branch, err := renderEpicBranch(target.Branch, plan)
+	if err != nil {
+		return sessionPlan{}, err
+	}
+	plan.Branch = branch
+	return plan, nil
 }
 
 func resolveEpic(cfg *config.Config, requested string) (string, config.EpicConfig, error) {
@@ -78,6 +134,15 @@ func resolveEpic(cfg *config.Config, requested string) (string, config.EpicConfi
 	if matchedKey != "" {
 		return matchedKey, cfg.Epics[matchedKey], nil
 	}
+	// A renamed epic still answers to its former aliases so history
+	// queries using the old name keep working.
+	for key, tgt := range cfg.Epics {
+		for _, former := range tgt.FormerAliases {
+			if strings.EqualFold(strings.TrimSpace(former), requested) {
+				return key, tgt, nil
+			}
+		}
+	}
 	return "", config.EpicConfig{}, fmt.Errorf("unknown epic or alias %q", requested)
 }
 
@@ -85,17 +150,26 @@ func aliasFromRequest(_ string, key string, epic config.EpicConfig) string {
 	return epicAliasHandle(key, epic)
 }
 
+// resumeSkipSet holds the lowercased IDs of the beads this run must not
+// pick: those already completed and those skipped at a checkpoint.
 func (p sessionPlan) resumeSkipSet() map[string]struct{} {
-	if len(p.ResumeCompletedBeads) == 0 {
-		return nil
-	}
-	set := make(map[string]struct{}, len(p.ResumeCompletedBeads))
-	for _, bead := range p.ResumeCompletedBeads {
-		normalized := strings.ToLower(strings.TrimSpace(bead))
-		if normalized == "" {
-			continue
+	return beadSet(p.ResumeCompletedBeads, p.SkippedBeads)
+}
+
+// beadSet holds the lowercased IDs in lists, or is nil when there are none.
+func beadSet(lists ...[]string) map[string]struct{} {
+	var set map[string]struct{}
+	for _, list := range lists {
+		for _, bead := range list {
+			normalized := strings.ToLower(strings.TrimSpace(bead))
+			if normalized == "" {
+				continue
+			}
+			if set == nil {
+				set = map[string]struct{}{}
+			}
+			set[normalized] = struct{}{}
 		}
-		set[normalized] = struct{}{}
 	}
 	return set
 }
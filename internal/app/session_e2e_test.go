@@ -84,6 +84,110 @@ func TestExecuteSessionWithFakeCodexNeedsHelp(t *testing.T) {
 	}
 }
 
+func TestExecuteSessionFallsBackWhenModelUnavailable(t *testing.T) {
+	t.Setenv("OBI_PIPE_LAUNCHER", "1")
+	fake := buildFakeCodexBinary(t)
+	t.Setenv("FAKE_CODEX_SCENARIO", "success")
+	t.Setenv("FAKE_CODEX_UNAVAILABLE_MODELS", "gpt-5-codex-high,gpt-5-codex-medium")
+
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "results.log")
+	plan, cfg := newTestPlan(logPath, fake, tempDir)
+	plan.Codex.Model = "gpt-5-codex-high"
+	plan.Codex.FallbackModels = []string{"gpt-5-codex-medium", "gpt-5-mini"}
+
+	outcome, err := executeSession(plan, goOptions{noTUI: true}, cfg, logPath, false, false)
+	if err != nil {
+		t.Fatalf("executeSession (fallback): %v", err)
+	}
+	if outcome.Status != footer.StatusSuccess {
+		t.Fatalf("expected success outcome, got %s", outcome.Status)
+	}
+
+	entries := readLedger(t, logPath)
+	if len(entries) != 3 {
+		t.Fatalf("expected the two failed attempts and the run to be logged, got %d entries", len(entries))
+	}
+	for i, model := range []string{"gpt-5-codex-high", "gpt-5-codex-medium"} {
+		if entries[i].Status != ledgerStatusUnparsed || entries[i].CodexModel != model || !strings.Contains(entries[i].Escalation, "unavailable") {
+			t.Fatalf("unexpected failed attempt %d: %+v", i, entries[i])
+		}
+	}
+	last := entries[2]
+	if last.CodexModel != "gpt-5-mini" {
+		t.Fatalf("expected codex_model gpt-5-mini, got %q", last.CodexModel)
+	}
+	if got := strings.Join(last.FallbackFrom, ","); got != "gpt-5-codex-high,gpt-5-codex-medium" {
+		t.Fatalf("unexpected fallback_from %q", got)
+	}
+}
+
+func TestExecuteSessionVerifyFailureDowngradesSuccess(t *testing.T) {
+	t.Setenv("OBI_PIPE_LAUNCHER", "1")
+	fake := buildFakeCodexBinary(t)
+	t.Setenv("FAKE_CODEX_SCENARIO", "success")
+
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "results.log")
+	plan, cfg := newTestPlan(logPath, fake, tempDir)
+	plan.VerifyCommand = "echo tests broke; exit 3"
+	opts := goOptions{noTUI: true}
+
+	if _, err := executeSession(plan, opts, cfg, logPath, false, false); err == nil {
+		t.Fatalf("expected executeSession to fail when verification fails")
+	}
+
+	entries := readLedger(t, logPath)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 ledger entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Status != footer.StatusFailure {
+		t.Fatalf("expected downgrade to needs_help, got %s", entry.Status)
+	}
+	if entry.Verification == nil || entry.Verification.Passed || entry.Verification.ExitCode != 3 {
+		t.Fatalf("unexpected verification record: %+v", entry.Verification)
+	}
+	data, err := os.ReadFile(entry.Verification.OutputPath)
+	if err != nil {
+		t.Fatalf("read verification output: %v", err)
+	}
+	if !strings.Contains(string(data), "tests broke") {
+		t.Fatalf("expected captured verification output, got %q", data)
+	}
+}
+
+func TestExecuteSessionStyleViolationFailsRun(t *testing.T) {
+	t.Setenv("OBI_PIPE_LAUNCHER", "1")
+	fake := buildFakeCodexBinary(t)
+	t.Setenv("FAKE_CODEX_SCENARIO", "success")
+
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "results.log")
+	plan, cfg := newTestPlan(logPath, fake, tempDir)
+	cfg.Style = config.StyleConfig{CommitConvention: config.CommitConventional, Enforce: config.StyleFail}
+	plan.VerifyCommand = "exit 0"
+
+	if _, err := executeSession(plan, goOptions{noTUI: true}, cfg, logPath, false, false); err == nil {
+		t.Fatalf("expected executeSession to fail on a style violation")
+	}
+
+	entries := readLedger(t, logPath)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 ledger entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Status != footer.StatusFailure || len(entry.StyleViolations) != 1 {
+		t.Fatalf("expected needs_help with one style violation, got %s %q", entry.Status, entry.StyleViolations)
+	}
+	if !strings.Contains(entry.Escalation, "violates [style]") {
+		t.Fatalf("unexpected escalation %q", entry.Escalation)
+	}
+	if entry.Verification != nil {
+		t.Fatalf("expected verification to be skipped after a style failure")
+	}
+}
+
 func TestExecuteSessionWithFakeCodexMalformedReport(t *testing.T) {
 	t.Setenv("OBI_PIPE_LAUNCHER", "1")
 	fake := buildFakeCodexBinary(t)
@@ -94,11 +198,20 @@ func TestExecuteSessionWithFakeCodexMalformedReport(t *testing.T) {
 	plan, cfg := newTestPlan(logPath, fake, tempDir)
 	opts := goOptions{noTUI: true}
 
-	if _, err := executeSession(plan, opts, cfg, logPath, false, false); err == nil {
-		t.Fatalf("expected executeSession to fail for malformed scenario")
+	_, err := executeSession(plan, opts, cfg, logPath, false, false)
+	if err == nil || !strings.Contains(err.Error(), "parse fenced report") {
+		t.Fatalf("expected executeSession to fail for malformed scenario, got %v", err)
+	}
+	entries := readLedgerAllowMissing(t, logPath)
+	if len(entries) != 1 {
+		t.Fatalf("expected one unparsed ledger entry for malformed run, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Status != ledgerStatusUnparsed || !strings.Contains(entry.ParseError, "parse fenced report") {
+		t.Fatalf("unexpected unparsed entry: %+v", entry)
 	}
-	if entries := readLedgerAllowMissing(t, logPath); len(entries) != 0 {
-		t.Fatalf("expected no ledger entries for malformed run, got %d", len(entries))
+	if entry.TranscriptPath == "" || entry.CommitSummary != "" || entry.SessionID == "" {
+		t.Fatalf("expected transcript and session recorded without report fields: %+v", entry)
 	}
 }
 
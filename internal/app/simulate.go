@@ -0,0 +1,90 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/fakecodex"
+)
+
+// SimulateEnv is set in the environment of the obi binary that obi go
+// --simulate launches in place of Codex. The binary then plays the named
+// fakecodex scenario instead of running a command.
+const SimulateEnv = "OBI_SIMULATE"
+
+// simulateFlag is --simulate[=scenario]; the bare flag plays success.
+type simulateFlag string
+
+func (f *simulateFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return string(*f)
+}
+
+func (f *simulateFlag) Set(value string) error {
+	switch value = strings.TrimSpace(value); value {
+	case "false":
+		*f = ""
+		return nil
+	case "true", "":
+		value = "success"
+	}
+	if _, ok := fakecodex.Scenarios[value]; !ok {
+		return fmt.Errorf("--simulate: unknown scenario %q (want %s)", value, strings.Join(fakecodex.Names(), ", "))
+	}
+	*f = simulateFlag(value)
+	return nil
+}
+
+func (f *simulateFlag) IsBoolFlag() bool { return true }
+
+// simulationDir holds the ledger, transcripts and audit log of simulated
+// runs, next to the results log at logPath.
+func simulationDir(logPath string) string {
+	return filepath.Join(filepath.Dir(logPath), "simulate")
+}
+
+// simulateConfig keeps a simulated run away from everything a real run
+// leaves behind: its ledger, transcripts and audit log go to
+// simulationDir, and nothing is posted to webhooks. It returns the
+// simulated results log.
+func simulateConfig(cfg *config.Config) (string, error) {
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return "", err
+	}
+	cfg.ResultsLog = filepath.Join(simulationDir(logPath), filepath.Base(logPath))
+	cfg.AuditLog = ""
+	cfg.Webhooks = config.WebhooksConfig{}
+	cfg.Notify.Webhook = ""
+	return cfg.ResultsLog, nil
+}
+
+// applySimulation makes plan launch this obi binary as a fake Codex that
+// plays scenario. The prompt, guardrails, verification and TUI run as
+// usual; only bead sync is skipped, since a rehearsal should not push or
+// pull the bead database.
+func applySimulation(plan *sessionPlan, scenario string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("--simulate: %w", err)
+	}
+	plan.Simulated = scenario
+	plan.Codex.Binary = exe
+	plan.Codex.FallbackModels = nil
+	return nil
+}
+
+// codexProcessEnv is env for the Codex process alone. A simulated run adds
+// SimulateEnv here rather than to plan.Env, so verify.command, the abort
+// cleanup and the pull request command never see it.
+func codexProcessEnv(plan sessionPlan, env []string) []string {
+	if plan.Simulated == "" {
+		return env
+	}
+	return append(append([]string(nil), env...), SimulateEnv+"="+plan.Simulated)
+}
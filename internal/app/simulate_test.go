@@ -0,0 +1,80 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/footer"
+)
+
+func TestParseGoOptionsSimulate(t *testing.T) {
+	opts, err := parseGoOptions([]string{"api", "--simulate"})
+	if err != nil || opts.simulate != "success" || opts.aliasInput != "api" {
+		t.Fatalf("bare flag: %+v, %v", opts, err)
+	}
+	opts, err = parseGoOptions([]string{"--simulate=long_logs", "api"})
+	if err != nil || opts.simulate != "long_logs" {
+		t.Fatalf("scenario: %+v, %v", opts, err)
+	}
+	if _, err := parseGoOptions([]string{"api", "--simulate=flaky"}); err == nil || !strings.Contains(err.Error(), "needs_help") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestSimulateConfigMovesLogs(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		ResultsLog: filepath.Join(dir, "results.log"),
+		AuditLog:   filepath.Join(dir, "audit.log"),
+		Webhooks:   config.WebhooksConfig{Endpoints: []string{"https://example.com/hook"}},
+		Notify:     config.NotifyConfig{Webhook: "https://example.com/notify"},
+	}
+	logPath, err := simulateConfig(cfg)
+	if err != nil {
+		t.Fatalf("simulateConfig: %v", err)
+	}
+	if want := filepath.Join(dir, "simulate", "results.log"); logPath != want {
+		t.Fatalf("log path = %q, want %q", logPath, want)
+	}
+	if audit, _ := cfg.AuditLogPath(); audit != filepath.Join(dir, "simulate", "audit.log") {
+		t.Fatalf("audit log = %q", audit)
+	}
+	if len(cfg.Webhooks.Endpoints) != 0 || cfg.Notify.Webhook != "" {
+		t.Fatalf("webhooks still set: %+v %+v", cfg.Webhooks, cfg.Notify)
+	}
+}
+
+func TestExecuteSessionSimulated(t *testing.T) {
+	t.Setenv("OBI_PIPE_LAUNCHER", "1")
+	binary := filepath.Join(t.TempDir(), "obi")
+	cmd := exec.Command("go", "build", "-o", binary, "./cmd/obi")
+	cmd.Dir = projectRoot(t)
+	cmd.Env = os.Environ()
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("build obi: %v\n%s", err, output)
+	}
+
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "results.log")
+	plan, cfg := newTestPlan(logPath, "codex", tempDir)
+	if err := applySimulation(&plan, "needs_help"); err != nil {
+		t.Fatalf("applySimulation: %v", err)
+	}
+	if len(plan.Env) != 0 {
+		t.Fatalf("the scenario should reach only Codex, got plan env %q", plan.Env)
+	}
+	// The test binary is not obi; launch the one built above.
+	plan.Codex.Binary = binary
+
+	if _, err := executeSession(plan, goOptions{noTUI: true}, cfg, logPath, false, false); err == nil {
+		t.Fatal("expected the needs_help scenario to stop the session")
+	}
+	entries := readLedger(t, logPath)
+	if len(entries) != 1 || entries[0].Simulated != "needs_help" || entries[0].Status != footer.StatusFailure {
+		t.Fatalf("entries = %+v", entries)
+	}
+}
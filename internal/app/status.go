@@ -0,0 +1,183 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// staleHeartbeatGrace is added to two missed intervals before a session
+// without an end record is reported as stale.
+const staleHeartbeatGrace = 30 * time.Second
+
+// sessionStatus is the latest state-file record for one session plus the
+// verdict obi status derives from it.
+type sessionStatus struct {
+	Last   stateRecord `json:"last"`
+	Health string      `json:"health"`
+	Detail string      `json:"detail,omitempty"`
+}
+
+func runStatus(args []string) error {
+	fs := newCommandFlags("status", "obi status [options]",
+		"Show running sessions from the heartbeat state file and flag any whose obi process\nstopped sending heartbeats or has exited.")
+	var configPath string
+	var all, asJSON bool
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.BoolVar(&all, "all", false, "include finished sessions")
+	fs.BoolVar(&asJSON, "json", false, "print one JSON object per session")
+	if _, err := fs.parse(args); err != nil {
+		return err
+	}
+
+	_, cfg, err := loadConfig(configPath, "")
+	if err != nil {
+		return err
+	}
+	path, err := cfg.StateFilePath()
+	if err != nil {
+		return err
+	}
+	records, err := readStateRecords(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("No sessions recorded yet (%s).\n", path)
+			return nil
+		}
+		return err
+	}
+
+	host, _ := os.Hostname()
+	statuses := summarizeSessions(records, time.Now(), host, processAlive)
+	if !all {
+		statuses = unfinishedSessions(statuses)
+	}
+
+	if asJSON {
+		for _, st := range statuses {
+			line, err := json.Marshal(st)
+			if err != nil {
+				return fmt.Errorf("encode status: %w", err)
+			}
+			fmt.Println(string(line))
+		}
+		return nil
+	}
+	if len(statuses) == 0 {
+		fmt.Println("No running sessions.")
+		return nil
+	}
+	fmt.Print(formatSessionStatuses(statuses))
+	return nil
+}
+
+// summarizeSessions keeps the latest record per session, newest first, and
+// classifies each one.
+func summarizeSessions(records []stateRecord, now time.Time, host string, alive func(int) bool) []sessionStatus {
+	latest := map[string]stateRecord{}
+	for _, rec := range records {
+		if prev, ok := latest[rec.SessionID]; !ok || !rec.Time.Before(prev.Time) {
+			latest[rec.SessionID] = rec
+		}
+	}
+	statuses := make([]sessionStatus, 0, len(latest))
+	for _, rec := range latest {
+		health, detail := sessionHealth(rec, now, host, alive)
+		statuses = append(statuses, sessionStatus{Last: rec, Health: health, Detail: detail})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Last.Time.After(statuses[j].Last.Time)
+	})
+	return statuses
+}
+
+func unfinishedSessions(statuses []sessionStatus) []sessionStatus {
+	var out []sessionStatus
+	for _, st := range statuses {
+		if st.Last.Kind != stateEnd {
+			out = append(out, st)
+		}
+	}
+	return out
+}
+
+// sessionHealth returns live, stale, gone, or finished. Liveness of the
+// process is only checked for sessions recorded on this host.
+func sessionHealth(rec stateRecord, now time.Time, host string, alive func(int) bool) (string, string) {
+	if rec.Kind == stateEnd {
+		if rec.ExitCode != nil {
+			return "finished", fmt.Sprintf("exit %d", *rec.ExitCode)
+		}
+		return "finished", ""
+	}
+	if rec.Host == host && rec.PID > 0 && alive != nil && !alive(rec.PID) {
+		return "gone", fmt.Sprintf("obi process %d is no longer running", rec.PID)
+	}
+	silence := now.Sub(rec.Time)
+	limit := 2*time.Duration(rec.IntervalMs)*time.Millisecond + staleHeartbeatGrace
+	if rec.IntervalMs > 0 && silence > limit {
+		return "stale", fmt.Sprintf("no heartbeat for %s; obi may be hung", silence.Round(time.Second))
+	}
+	return "live", ""
+}
+
+func formatSessionStatuses(statuses []sessionStatus) string {
+	var b strings.Builder
+	now := time.Now()
+	for _, st := range statuses {
+		rec := st.Last
+		session := rec.SessionID
+		if len(session) > 8 {
+			session = session[:8]
+		}
+		target := rec.Alias
+		if target == "" {
+			target = rec.EpicID
+		}
+		if target == "" {
+			target = "issues"
+		}
+		phase := rec.Phase
+		if phase == "" {
+			phase = "-"
+		}
+		fmt.Fprintf(&b, "%-8s  %-8s  %-16s  pid %d  phase %-10s  elapsed %s  %s streamed  last record %s",
+			st.Health, session, target, rec.PID, phase, roundedMillis(rec.ElapsedMs), formatBytes(rec.Bytes), timeAgo(now.Sub(rec.Time)))
+		if st.Detail != "" {
+			fmt.Fprintf(&b, "  (%s)", st.Detail)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// processAlive reports whether pid still exists. Windows cannot probe with
+// signal 0, so it always reports true there and staleness is judged from
+// heartbeats alone.
+func processAlive(pid int) bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
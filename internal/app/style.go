@@ -0,0 +1,95 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// conventionalTypes are the Conventional Commits types obi accepts.
+var conventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// commitStyle is a validated [style] block.
+type commitStyle struct {
+	language   string
+	convention string
+	maxSubject int
+	fail       bool
+}
+
+func newCommitStyle(cfg config.StyleConfig) (commitStyle, error) {
+	style := commitStyle{
+		language:   strings.TrimSpace(cfg.CommitLanguage),
+		convention: strings.ToLower(strings.TrimSpace(cfg.CommitConvention)),
+		maxSubject: cfg.MaxSubjectLength,
+	}
+	if style.convention != "" && style.convention != config.CommitConventional {
+		return commitStyle{}, fmt.Errorf("invalid style.commit_convention %q (want %q or empty)", cfg.CommitConvention, config.CommitConventional)
+	}
+	if style.maxSubject < 0 {
+		return commitStyle{}, fmt.Errorf("style.max_subject_length must not be negative")
+	}
+	switch strings.ToLower(strings.TrimSpace(cfg.Enforce)) {
+	case "", config.StyleWarn:
+	case config.StyleFail:
+		style.fail = true
+	default:
+		return commitStyle{}, fmt.Errorf("invalid style.enforce %q (want %s or %s)", cfg.Enforce, config.StyleWarn, config.StyleFail)
+	}
+	return style, nil
+}
+
+// sessionStyle returns the [style] rules that apply to plan. Exploratory
+// runs make no commit, so none apply to them.
+func sessionStyle(cfg *config.Config, plan sessionPlan) (commitStyle, error) {
+	if plan.ReadOnly {
+		return commitStyle{}, nil
+	}
+	return newCommitStyle(cfg.Style)
+}
+
+// guidance returns the rules added to the fenced-report instructions.
+func (s commitStyle) guidance() []string {
+	var lines []string
+	if s.language != "" {
+		lines = append(lines, fmt.Sprintf("Write commit_msg and details in %s.", s.language))
+	}
+	if s.convention == config.CommitConventional {
+		lines = append(lines, fmt.Sprintf("commit_msg must follow Conventional Commits: <type>(<optional scope>): <subject>, where type is one of %s.", strings.Join(conventionalTypes, ", ")))
+	}
+	if s.maxSubject > 0 {
+		lines = append(lines, fmt.Sprintf("Keep commit_msg to at most %d characters.", s.maxSubject))
+	}
+	return lines
+}
+
+// violations lists the ways summary breaks the checkable rules. The
+// language rule is left to the reviewer.
+func (s commitStyle) violations(summary string) []string {
+	summary = strings.TrimSpace(firstLine(summary))
+	var out []string
+	if s.convention == config.CommitConventional && !isConventionalSummary(summary) {
+		out = append(out, "commit summary is not a Conventional Commits subject")
+	}
+	if n := utf8.RuneCountInString(summary); s.maxSubject > 0 && n > s.maxSubject {
+		out = append(out, fmt.Sprintf("commit summary is %d characters (max_subject_length %d)", n, s.maxSubject))
+	}
+	return out
+}
+
+// isConventionalSummary reports whether summary has a Conventional Commits
+// header with one of the accepted types.
+func isConventionalSummary(summary string) bool {
+	parts := parseCommitSummary(summary)
+	if parts.Type == "" || parts.Inferred || strings.ContainsAny(parts.Scope, " \t") {
+		return false
+	}
+	for _, kind := range conventionalTypes {
+		if parts.Type == kind {
+			return true
+		}
+	}
+	return false
+}
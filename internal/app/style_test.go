@@ -0,0 +1,58 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestCommitStyleGuidanceAndViolations(t *testing.T) {
+	style, err := newCommitStyle(config.StyleConfig{
+		CommitLanguage:   "German",
+		CommitConvention: "Conventional",
+		MaxSubjectLength: 30,
+		Enforce:          "fail",
+	})
+	if err != nil {
+		t.Fatalf("newCommitStyle: %v", err)
+	}
+	if !style.fail {
+		t.Fatalf("expected enforce=fail to be honored")
+	}
+	guidance := strings.Join(style.guidance(), "\n")
+	for _, want := range []string{"in German", "Conventional Commits", "at most 30 characters"} {
+		if !strings.Contains(guidance, want) {
+			t.Fatalf("guidance missing %q:\n%s", want, guidance)
+		}
+	}
+
+	cases := map[string]int{
+		"feat(tui): add modal":     0,
+		"fix!: drop legacy footer": 0,
+		"Add modal":                1,
+		"feat: add a considerably longer subject line": 1,
+		"Added a considerably longer subject line":     2,
+	}
+	for summary, want := range cases {
+		if got := style.violations(summary + "\n\nbody"); len(got) != want {
+			t.Errorf("violations(%q) = %q, want %d", summary, got, want)
+		}
+	}
+}
+
+func TestCommitStyleRejectsUnknownSettings(t *testing.T) {
+	if _, err := newCommitStyle(config.StyleConfig{CommitConvention: "gitmoji"}); err == nil {
+		t.Fatalf("expected unknown convention to fail")
+	}
+	if _, err := newCommitStyle(config.StyleConfig{Enforce: "block"}); err == nil {
+		t.Fatalf("expected unknown enforce mode to fail")
+	}
+	style, err := newCommitStyle(config.StyleConfig{})
+	if err != nil {
+		t.Fatalf("empty style: %v", err)
+	}
+	if len(style.guidance()) != 0 || len(style.violations("anything at all")) != 0 {
+		t.Fatalf("expected an empty [style] to add no rules")
+	}
+}
@@ -15,6 +15,7 @@ type summaryEntry struct {
 	BeadID        string
 	CommitSummary string
 	CommitDetails string
+	CommitType    string
 	CompletedAt   time.Time
 }
 
@@ -39,6 +40,13 @@ func maybeRunSummarizer(plan sessionPlan, opts goOptions, cfg *config.Config, lo
 		return nil
 	}
 
+	return runSummarySession(plan, opts, cfg, logPath, entries, total)
+}
+
+// runSummarySession launches the omnibus summarizer over entries, which
+// hold the newest of total recorded completions.
+func runSummarySession(plan sessionPlan, opts goOptions, cfg *config.Config, logPath string, entries []summaryEntry, total int) error {
+	summaryCfg := cfg.SummaryConfigValue()
 	chunks := chunkSummaryEntries(entries, summaryCfg.ChunkSize)
 
 	summaryPlan := plan
@@ -95,6 +103,7 @@ func loadSummaryEntries(path, epicID string, maxCommits int) ([]summaryEntry, in
 				BeadID:        strings.TrimSpace(entry.BeadID),
 				CommitSummary: summary,
 				CommitDetails: details,
+				CommitType:    historyType(entry),
 				CompletedAt:   entry.CompletedAt,
 			})
 		case footer.StatusFailure:
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// summaryReviewScissors separates the draft from the help text below it.
+// Everything from this line on is ignored, so lines starting with '#', such
+// as Markdown headings in the body, are kept.
+const summaryReviewScissors = "# ------------------------ >8 ------------------------"
+
+// summaryReviewHelp trails the draft the operator edits.
+const summaryReviewHelp = "\n" + summaryReviewScissors + `
+# Do not change or remove the line above; everything below it is ignored.
+# Edit the omnibus summary above it. The first line is the commit summary
+# and everything after it is the body.
+# Delete everything above the line to keep Codex's version unchanged.
+`
+
+// summaryDraft is an omnibus summary as Codex reported it or as the
+// operator left it after review.
+type summaryDraft struct {
+	CommitSummary string `json:"commit_summary"`
+	CommitDetails string `json:"commit_details"`
+}
+
+func (d summaryDraft) text() string {
+	return strings.TrimSpace(d.CommitSummary) + "\n\n" + strings.TrimSpace(d.CommitDetails) + "\n"
+}
+
+// reviewSummary writes draft to a temporary file, lets edit change it, and
+// reads it back. changed is false when the operator emptied the file or left
+// it as it was.
+func reviewSummary(draft summaryDraft, edit func(path string) error) (summaryDraft, bool, error) {
+	f, err := os.CreateTemp("", "obi-summary-*.md")
+	if err != nil {
+		return draft, false, fmt.Errorf("create summary draft: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	original := draft.text() + summaryReviewHelp
+	_, err = f.WriteString(original)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return draft, false, fmt.Errorf("write summary draft: %w", err)
+	}
+
+	if err := edit(path); err != nil {
+		return draft, false, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return draft, false, fmt.Errorf("read summary draft: %w", err)
+	}
+	if string(data) == original {
+		return draft, false, nil
+	}
+	edited, ok := parseSummaryDraft(string(data))
+	if !ok || edited == (summaryDraft{CommitSummary: strings.TrimSpace(draft.CommitSummary), CommitDetails: strings.TrimSpace(draft.CommitDetails)}) {
+		return draft, false, nil
+	}
+	return edited, true, nil
+}
+
+// parseSummaryDraft drops the scissors line and everything after it, then
+// splits the rest into the first non-blank line and the body after it; ok
+// is false when nothing is left.
+func parseSummaryDraft(text string) (summaryDraft, bool) {
+	var kept []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == summaryReviewScissors {
+			break
+		}
+		kept = append(kept, line)
+	}
+	body := strings.TrimSpace(strings.Join(kept, "\n"))
+	if body == "" {
+		return summaryDraft{}, false
+	}
+	summary, details, _ := strings.Cut(body, "\n")
+	return summaryDraft{CommitSummary: strings.TrimSpace(summary), CommitDetails: strings.TrimSpace(details)}, true
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReviewSummaryKeepsEditsAndHeadingsAndIgnoresHelp(t *testing.T) {
+	draft := summaryDraft{CommitSummary: "feat(api): ship v2", CommitDetails: "- obi-api.1 added routes"}
+	edit := func(path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(string(data), "feat(api): ship v2\n\n- obi-api.1 added routes\n") {
+			t.Fatalf("draft = %q", data)
+		}
+		return os.WriteFile(path, []byte("feat(api): ship the v2 routes\n\n## Changes\n- obi-api.1 added routes\n- reviewed by hand\n"+summaryReviewHelp+"# trailing note\n"), 0o600)
+	}
+	got, changed, err := reviewSummary(draft, edit)
+	if err != nil || !changed {
+		t.Fatalf("review = %+v, %v, %v", got, changed, err)
+	}
+	want := summaryDraft{CommitSummary: "feat(api): ship the v2 routes", CommitDetails: "## Changes\n- obi-api.1 added routes\n- reviewed by hand"}
+	if got != want {
+		t.Fatalf("edited = %+v, want %+v", got, want)
+	}
+}
+
+func TestReviewSummaryUnchangedOrEmptiedKeepsDraft(t *testing.T) {
+	draft := summaryDraft{CommitSummary: "feat: done", CommitDetails: "body"}
+	for name, edit := range map[string]func(string) error{
+		"untouched": func(string) error { return nil },
+		"emptied":   func(path string) error { return os.WriteFile(path, []byte("\n"+summaryReviewHelp), 0o600) },
+		"cleared":   func(path string) error { return os.WriteFile(path, nil, 0o600) },
+	} {
+		got, changed, err := reviewSummary(draft, edit)
+		if err != nil || changed || got != draft {
+			t.Fatalf("%s: review = %+v, %v, %v", name, got, changed, err)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const tailPollInterval = 500 * time.Millisecond
+
+func runTail(args []string) error {
+	fs := newCommandFlags("tail", "obi tail <session-id|--latest> [options]",
+		"Follow a session's transcript read-only until the run is logged (Ctrl+C stops watching,\nnot the session). A transcript path also works, e.g. one written via obi go --out.", "session")
+	var configPath string
+	var latest, noFollow bool
+	fs.StringVar(&configPath, "config", "", "path to obi.toml (defaults to nearest)")
+	fs.BoolVar(&latest, "latest", false, "follow the most recently written transcript")
+	fs.BoolVar(&noFollow, "no-follow", false, "print the transcript so far and exit")
+	positional, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	target := positionalArg(positional, 0)
+	if latest == (target != "") {
+		return errors.New("obi tail needs exactly one of a session ID or --latest")
+	}
+
+	_, cfg, err := loadConfig(configPath, "")
+	if err != nil {
+		return err
+	}
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+
+	var path string
+	if latest {
+		path, err = latestTranscript(transcriptDirFor(logPath))
+	} else {
+		path, err = transcriptPathFor(logPath, target)
+	}
+	if err != nil {
+		return err
+	}
+	sessionID := transcriptSession(filepath.Base(path))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "obi: following %s (read-only)\n", path)
+	finished := func() bool {
+		if noFollow {
+			return true
+		}
+		return sessionLogged(logPath, sessionID)
+	}
+	if err := followTranscript(ctx, path, os.Stdout, tailPollInterval, finished); err != nil {
+		return err
+	}
+	if ctx.Err() == nil && !noFollow {
+		fmt.Fprintf(os.Stderr, "\nobi: session %s finished; see the results log for its status.\n", sessionID)
+	}
+	return nil
+}
+
+// transcriptPathFor maps a session ID (or an explicit path) to a transcript,
+// looking through transcript_name_template subdirectories when the session
+// is not at <session-id>.log.
+func transcriptPathFor(logPath, target string) (string, error) {
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		return target, nil
+	}
+	dir := transcriptDirFor(logPath)
+	path := filepath.Join(dir, sanitizeFilename(target)+".log")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("stat transcript: %w", err)
+	}
+	files, err := transcriptFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	session := sanitizeFilename(target)
+	for _, file := range files {
+		name := filepath.Base(file)
+		if isSessionTranscript(name) && transcriptSession(name) == session {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("no transcript for session %s in %s", target, dir)
+}
+
+// latestTranscript returns the most recently modified session transcript,
+// ignoring verification logs.
+func latestTranscript(dir string) (string, error) {
+	matches, err := transcriptFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	var newest string
+	var newestMod time.Time
+	for _, path := range matches {
+		if !isSessionTranscript(filepath.Base(path)) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestMod) {
+			newest, newestMod = path, info.ModTime()
+		}
+	}
+	if newest == "" {
+		return "", fmt.Errorf("no transcripts found in %s", dir)
+	}
+	return newest, nil
+}
+
+// sessionLogged reports whether the ledger already has an entry for the
+// session, which obi writes once the run is over.
+func sessionLogged(logPath, sessionID string) bool {
+	entries, err := ledgerEntriesForEpic(logPath, "")
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if strings.EqualFold(sanitizeFilename(entry.SessionID), sessionID) {
+			return true
+		}
+	}
+	return false
+}
+
+// followTranscript copies path to out as it grows. Once a poll finds no new
+// data and finished reports true, it drains what is left and returns;
+// cancelling ctx stops immediately.
+func followTranscript(ctx context.Context, path string, out io.Writer, interval time.Duration, finished func() bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open transcript: %w", err)
+	}
+	defer f.Close()
+
+	for {
+		n, err := io.Copy(out, f)
+		if err != nil {
+			return fmt.Errorf("read transcript: %w", err)
+		}
+		if n == 0 {
+			if err := skipTruncation(f, out); err != nil {
+				return err
+			}
+		}
+		if n == 0 && finished() {
+			if _, err := io.Copy(out, f); err != nil {
+				return fmt.Errorf("read transcript: %w", err)
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// skipTruncation jumps to the current end of the file when transcript_max_mb
+// has cut the transcript below the read position.
+func skipTruncation(f *os.File, out io.Writer) error {
+	pos, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("read transcript: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat transcript: %w", err)
+	}
+	if info.Size() >= pos {
+		return nil
+	}
+	fmt.Fprintln(out, "\n[obi tail: transcript was truncated by transcript_max_mb; skipping ahead]")
+	_, err = f.Seek(0, io.SeekEnd)
+	return err
+}
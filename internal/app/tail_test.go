@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu sync.Mutex
+	sb strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.String()
+}
+
+func TestFollowTranscriptStreamsUntilFinished(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.log")
+	if err := os.WriteFile(path, []byte("first\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var out syncBuffer
+	var mu sync.Mutex
+	done := false
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- followTranscript(context.Background(), path, &out, 5*time.Millisecond, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return done
+		})
+	}()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(out.String(), "first") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	f.WriteString("second\n")
+	f.Close()
+	mu.Lock()
+	done = true
+	mu.Unlock()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("follow: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("followTranscript did not return after finish")
+	}
+	if got := out.String(); got != "first\nsecond\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestFollowTranscriptStopsOnCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.log")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := followTranscript(ctx, path, &syncBuffer{}, time.Hour, func() bool { return false }); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+}
+
+func TestTranscriptLookup(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "results.log")
+	transcripts := transcriptDirFor(logPath)
+	if err := os.MkdirAll(transcripts, 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	old := filepath.Join(transcripts, "old.log")
+	recent := filepath.Join(transcripts, "recent.log")
+	verify := filepath.Join(transcripts, "recent.verify.log")
+	for i, p := range []string{old, recent, verify} {
+		if err := os.WriteFile(p, []byte("x"), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		mod := time.Now().Add(time.Duration(i-3) * time.Minute)
+		if err := os.Chtimes(p, mod, mod); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+
+	if got, err := latestTranscript(transcripts); err != nil || got != recent {
+		t.Fatalf("latestTranscript = %q, %v; want %q", got, err, recent)
+	}
+	if got, err := transcriptPathFor(logPath, "old"); err != nil || got != old {
+		t.Fatalf("transcriptPathFor(old) = %q, %v", got, err)
+	}
+	if got, err := transcriptPathFor(logPath, verify); err != nil || got != verify {
+		t.Fatalf("explicit paths should pass through, got %q, %v", got, err)
+	}
+	if _, err := transcriptPathFor(logPath, "missing"); err == nil || !strings.Contains(err.Error(), "no transcript for session missing") {
+		t.Fatalf("expected missing transcript error, got %v", err)
+	}
+	if _, err := latestTranscript(filepath.Join(dir, "none")); err == nil {
+		t.Fatalf("expected error for empty transcript dir")
+	}
+}
+
+func TestSkipTruncationJumpsToEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s.log")
+	if err := os.WriteFile(path, []byte(strings.Repeat("a", 100)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(100, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("head\n[cut]\ntail\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out syncBuffer
+	if err := skipTruncation(f, &out); err != nil {
+		t.Fatalf("skip: %v", err)
+	}
+	if !strings.Contains(out.String(), "truncated") {
+		t.Fatalf("expected a notice, got %q", out.String())
+	}
+	if pos, _ := f.Seek(0, io.SeekCurrent); pos != int64(len("head\n[cut]\ntail\n")) {
+		t.Fatalf("expected to resume at the new end, got %d", pos)
+	}
+}
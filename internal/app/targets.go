@@ -31,6 +31,9 @@ func aliasHandles(cfg *config.Config) []string {
 			set[id] = struct{}{}
 		}
 	}
+	for _, group := range cfg.GroupNames() {
+		set[groupPrefix+group] = struct{}{}
+	}
 	handles := make([]string, 0, len(set))
 	for handle := range set {
 		handles = append(handles, handle)
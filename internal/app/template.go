@@ -0,0 +1,209 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// templateEpicPrefix marks the EpicID template sessions are logged under so
+// they never collide with a bd epic.
+const templateEpicPrefix = "template:"
+
+const templateContractTemplate = `Chore contract for %s:
+- This session is a recurring chore, not bd work: do not claim, create, or close beads.
+- Do the chore described above and run the checks that cover what you changed.
+- Put a one-line summary of the change in commit_msg.
+- Emit STATUS: success once the chore is done (or there was nothing to do). Otherwise emit STATUS: needs_help with ESCALATION explaining the blocker.`
+
+func runRun(args []string) error {
+	fs := newCommandFlags("run", "obi run [template] [options]",
+		"Run a [template.<name>] chore: one Codex session with the template's prompt that never\nconsults bd. Without a name, lists the templates and when each last ran.", "template")
+
+	var opts goOptions
+	fs.StringVar(&opts.configPath, "config", "", "path to obi config")
+	fs.StringVar(&opts.profile, "profile", "", "apply this [profiles.*] overlay (defaults to $OBI_PROFILE)")
+	fs.StringVar(&opts.outPath, "out", "", "tee codex stdout/stderr to this file")
+	fs.StringVar(&opts.outPath, "o", "", "shorthand for --out")
+	fs.BoolVar(&opts.noTUI, "no-tui", false, "disable the interactive TUI (stream raw Codex output)")
+	fs.StringVar(&opts.dir, "dir", "", "run Codex in this directory (overrides the template's dir)")
+	fs.Var((*envFlag)(&opts.env), "env", "set KEY=VAL in the Codex environment (repeatable; overrides the template's env)")
+	fs.Var((*contextFlag)(&opts.context), "context", "append this file to the prompt (repeatable)")
+	fs.StringVar(&opts.note, "note", "", "add an operator note for this run to the prompt")
+	fs.BoolVar(&opts.ci, "ci", false, "non-interactive mode: fail when the fenced report and legacy footer disagree instead of prompting")
+	fs.BoolVar(&opts.skipDups, "skip-duplicates", false, "don't launch when a recent successful run used the identical prompt")
+	fs.BoolVar(&opts.yes, "yes", false, "launch without the confirmation prompt, as with confirm_before_run = false")
+
+	positional, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSpace(positionalArg(positional, 0))
+
+	resolvedPath, cfg, err := loadConfig(opts.configPath, opts.profile)
+	if err != nil {
+		return err
+	}
+	logPath, err := cfg.ResultsLogPath()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return listTemplates(os.Stdout, cfg, logPath, time.Now())
+	}
+	if opts.yes {
+		autoConfirm := false
+		cfg.ConfirmBeforeRun = &autoConfirm
+	}
+
+	plan, err := planFromTemplate(cfg, name)
+	if err != nil {
+		return err
+	}
+	plan.RepoRoot = repoRootForConfig(resolvedPath)
+	plan.ConfigDigest = configDigest(resolvedPath)
+	if err := applyRunContext(&plan, opts.dir, opts.env); err != nil {
+		return err
+	}
+	if err := loadContextFiles(&plan, opts.context); err != nil {
+		return err
+	}
+	plan.OperatorNote = strings.TrimSpace(opts.note)
+	if err := ensureCleanTree(cfg, gitRunDir(plan), resolvedPath); err != nil {
+		return err
+	}
+	if err := enforceBranchPolicy(cfg, plan); err != nil {
+		return err
+	}
+	if err := ensureScheduleOpen(cfg, time.Now()); err != nil {
+		return err
+	}
+
+	plan.CheckDuplicates = true
+	_, err = executeSession(plan, opts, cfg, logPath, cfg.ConfirmBeforeRunValue(), !cfg.ConfirmBeforeRunValue())
+	return err
+}
+
+// planFromTemplate builds the session for [template.<name>]. Its EpicID is
+// "template:<name>", which is also the bead ID every run is logged with.
+func planFromTemplate(cfg *config.Config, name string) (sessionPlan, error) {
+	tmpl, ok := cfg.Templates[name]
+	if !ok {
+		if len(cfg.Templates) == 0 {
+			return sessionPlan{}, fmt.Errorf("unknown template %q; obi.toml has no [template.*] sections", name)
+		}
+		return sessionPlan{}, fmt.Errorf("unknown template %q (known: %s)", name, strings.Join(sortedTemplateNames(cfg.Templates), ", "))
+	}
+	if strings.TrimSpace(tmpl.Prompt) == "" {
+		return sessionPlan{}, fmt.Errorf("template %s has no prompt", name)
+	}
+	displayName := strings.TrimSpace(tmpl.Name)
+	if displayName == "" {
+		displayName = name
+	}
+	epicID := templateEpicPrefix + name
+	return sessionPlan{
+		EpicKey:        name,
+		EpicName:       displayName,
+		Alias:          name,
+		EpicID:         epicID,
+		EpicPrompt:     tmpl.Prompt,
+		BasePrompt:     cfg.BasePrompt,
+		Codex:          cfg.TemplateCodex(tmpl),
+		Mode:           sessionModeTemplate,
+		BeadIDOverride: epicID,
+		Dir:            tmpl.Dir,
+		Env:            envFromMap(tmpl.Env),
+		VerifyCommand:  strings.TrimSpace(tmpl.Verify.Command),
+		Escalation:     cfg.Escalation,
+	}, nil
+}
+
+func templateContract(plan sessionPlan) string {
+	return fmt.Sprintf(templateContractTemplate, plan.EpicName)
+}
+
+func sortedTemplateNames(templates map[string]config.TemplateConfig) []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// listTemplates prints each template with its last run and, when it sets
+// every, whether it is due again.
+func listTemplates(w io.Writer, cfg *config.Config, logPath string, now time.Time) error {
+	if len(cfg.Templates) == 0 {
+		fmt.Fprintln(w, "No [template.*] sections in obi.toml.")
+		return nil
+	}
+	entries, err := ledgerEntriesForEpic(logPath, "")
+	if err != nil && !errors.Is(err, errLedgerNotFound) {
+		return err
+	}
+	last := map[string]ledgerEntry{}
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry.EpicID, templateEpicPrefix)
+		if !ok {
+			continue
+		}
+		if prev, seen := last[name]; !seen || entry.CompletedAt.After(prev.CompletedAt) {
+			last[name] = entry
+		}
+	}
+
+	fmt.Fprintln(w, "Templates:")
+	for _, name := range sortedTemplateNames(cfg.Templates) {
+		tmpl := cfg.Templates[name]
+		line := "  " + name
+		if title := strings.TrimSpace(tmpl.Name); title != "" && title != name {
+			line += " (" + title + ")"
+		}
+		every := strings.TrimSpace(tmpl.Every)
+		if every != "" {
+			line += ", every " + every
+		}
+		entry, ran := last[name]
+		if ran {
+			line += fmt.Sprintf(", last run %s (%s)", timeAgo(now.Sub(entry.CompletedAt)), entry.Status)
+		} else {
+			line += ", never run"
+		}
+		if every != "" {
+			interval, err := parseTemplateEvery(every)
+			switch {
+			case err != nil:
+				line += fmt.Sprintf(" [%v]", err)
+			case !ran || now.Sub(entry.CompletedAt) >= interval:
+				line += " [due]"
+			}
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w, "Run `obi run <template>` to start one.")
+	return nil
+}
+
+// parseTemplateEvery reads a template's every hint: a day count ("7d") or a
+// Go duration ("12h").
+func parseTemplateEvery(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid every %q (want e.g. 7d or 12h)", value)
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func templateTestConfig() *config.Config {
+	return &config.Config{
+		BasePrompt: "Base prompt",
+		Codex:      config.CodexConfig{Model: "gpt", Sandbox: "workspace-write"},
+		Templates: map[string]config.TemplateConfig{
+			"deps-update": {
+				Name:          "Dependency update",
+				Prompt:        "Bump dependencies.",
+				Every:         "7d",
+				CodexOverride: &config.CodexConfig{Model: "gpt-mini"},
+				Env:           map[string]string{"GOFLAGS": "-mod=mod"},
+			},
+			"lint": {Prompt: "Fix lint warnings."},
+		},
+	}
+}
+
+func TestPlanFromTemplateBypassesBd(t *testing.T) {
+	cfg := templateTestConfig()
+	plan, err := planFromTemplate(cfg, "deps-update")
+	if err != nil {
+		t.Fatalf("planFromTemplate: %v", err)
+	}
+	if plan.EpicID != "template:deps-update" || plan.BeadIDOverride != plan.EpicID || plan.Mode != sessionModeTemplate {
+		t.Fatalf("plan = %+v", plan)
+	}
+	if plan.Codex.Model != "gpt-mini" || plan.Codex.Sandbox != "workspace-write" {
+		t.Fatalf("codex = %+v", plan.Codex)
+	}
+	if len(plan.Env) != 1 || plan.Env[0] != "GOFLAGS=-mod=mod" {
+		t.Fatalf("env = %v", plan.Env)
+	}
+
+	prompt := buildPrompt(plan)
+	for _, want := range []string{"Base prompt", "Bump dependencies.", "Template: deps-update", "Chore contract for Dependency update", "do not claim, create, or close beads"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "bd ready") || strings.Contains(prompt, "Epic ID:") {
+		t.Fatalf("template prompt should not mention bd work:\n%s", prompt)
+	}
+
+	cfg.Prompt.IncludeReadyList = true
+	loadReadyList(&plan, cfg)
+	if plan.ReadyBeads != nil {
+		t.Fatalf("template sessions must not load a ready list, got %v", plan.ReadyBeads)
+	}
+}
+
+func TestPlanFromTemplateUnknownNamesKnownTemplates(t *testing.T) {
+	_, err := planFromTemplate(templateTestConfig(), "release")
+	if err == nil || !strings.Contains(err.Error(), "known: deps-update, lint") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestListTemplatesMarksDueTemplates(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	logPath := filepath.Join(t.TempDir(), "results.log")
+	for _, entry := range []ledgerEntry{
+		{RunID: "r1", SessionID: "r1", EpicID: "template:deps-update", Status: "success", CompletedAt: now.Add(-10 * 24 * time.Hour)},
+		{RunID: "r2", SessionID: "r2", EpicID: "template:deps-update", Status: "success", CompletedAt: now.Add(-2 * 24 * time.Hour)},
+		{RunID: "r3", SessionID: "r3", EpicID: "bd-foo", Status: "success", CompletedAt: now},
+	} {
+		if err := appendLedgerEntry(logPath, entry); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	cfg := templateTestConfig()
+	var out bytes.Buffer
+	if err := listTemplates(&out, cfg, logPath, now); err != nil {
+		t.Fatalf("listTemplates: %v", err)
+	}
+	got := out.String()
+	if !strings.Contains(got, "deps-update (Dependency update), every 7d, last run 2d ago (success)\n") {
+		t.Fatalf("deps-update should be listed as not due:\n%s", got)
+	}
+	if !strings.Contains(got, "  lint, never run\n") {
+		t.Fatalf("lint line missing:\n%s", got)
+	}
+
+	out.Reset()
+	if err := listTemplates(&out, cfg, logPath, now.Add(6*24*time.Hour)); err != nil {
+		t.Fatalf("listTemplates: %v", err)
+	}
+	if !strings.Contains(out.String(), "last run 8d ago (success) [due]") {
+		t.Fatalf("deps-update should be due:\n%s", out.String())
+	}
+}
+
+func TestParseTemplateEvery(t *testing.T) {
+	cases := map[string]time.Duration{"7d": 7 * 24 * time.Hour, "12h": 12 * time.Hour}
+	for value, want := range cases {
+		got, err := parseTemplateEvery(value)
+		if err != nil || got != want {
+			t.Fatalf("parseTemplateEvery(%q) = %v, %v", value, got, err)
+		}
+	}
+	if _, err := parseTemplateEvery("weekly"); err == nil {
+		t.Fatal("expected an error for weekly")
+	}
+}
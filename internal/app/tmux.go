@@ -0,0 +1,140 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+)
+
+// tmuxWindowEnv marks the obi process --tmux started in its own window, so
+// it runs the session instead of opening yet another window.
+const tmuxWindowEnv = "OBI_TMUX_WINDOW"
+
+// launchInTmux reruns this obi go command in a new tmux window named after
+// the target and returns once the window is open.
+func launchInTmux(args []string, opts goOptions) error {
+	if os.Getenv("TMUX") == "" {
+		return errors.New("--tmux opens a window in the current tmux session; run obi inside tmux")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("--tmux: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("--tmux: %w", err)
+	}
+	name := tmuxWindowName(opts.aliasInput)
+	out, err := exec.Command("tmux", tmuxNewWindowArgs(name, cwd, os.Environ(), exe, args)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return fmt.Errorf("tmux new-window: %s", firstLine(strings.TrimSpace(string(exitErr.Stderr))))
+		}
+		return fmt.Errorf("tmux new-window: %w", err)
+	}
+	window := strings.TrimSpace(string(out))
+	// Naming the window turns automatic-rename off; the session's status
+	// updates also need allow-rename, which tmux disables by default.
+	if err := exec.Command("tmux", "set-window-option", "-t", window, "allow-rename", "on").Run(); err != nil {
+		diag.Logger().Warn("tmux set-window-option failed; the window name will not follow the session", "window", window, "err", err)
+	}
+	fmt.Printf("Started obi go in tmux window %s (%s).\n", name, window)
+	return nil
+}
+
+// tmuxNewWindowArgs builds the tmux command that opens the window. A new
+// window starts in the tmux server's directory and environment, not obi's,
+// so the command passes the working directory with -c and every variable
+// of env with -e (other than tmux's own, which it sets per window). The
+// window is created with remain-on-exit, so a run that fails early leaves
+// its output on screen instead of closing the window.
+func tmuxNewWindowArgs(name, cwd string, env []string, exe string, args []string) []string {
+	tmuxArgs := []string{"new-window", "-P", "-F", "#{window_id}", "-n", name, "-c", cwd}
+	for _, entry := range env {
+		key, _, _ := strings.Cut(entry, "=")
+		if key == "" || key == "TMUX" || key == "TMUX_PANE" || key == tmuxWindowEnv {
+			continue
+		}
+		tmuxArgs = append(tmuxArgs, "-e", entry)
+	}
+	tmuxArgs = append(tmuxArgs, "-e", tmuxWindowEnv+"=1", "--", exe, "go")
+	tmuxArgs = append(tmuxArgs, args...)
+	// The chained command applies to the window just created.
+	return append(tmuxArgs, ";", "set-window-option", "remain-on-exit", "on")
+}
+
+// tmuxWindowName is the initial name of the --tmux window.
+func tmuxWindowName(aliasInput string) string {
+	target := strings.TrimSpace(aliasInput)
+	if target == "" {
+		target = "issues"
+	}
+	return "obi:" + target
+}
+
+// tmuxStatus renames the --tmux window to "<alias>[/<bead>] <state>" with
+// the screen title sequence, which tmux and GNU screen both honor. A nil
+// tmuxStatus does nothing.
+type tmuxStatus struct {
+	mu    sync.Mutex
+	out   io.Writer
+	alias string
+	bead  string
+	state string
+}
+
+// newTmuxStatus returns nil unless this process runs in a window that
+// --tmux opened.
+func newTmuxStatus(opts goOptions, plan sessionPlan, out io.Writer) *tmuxStatus {
+	if !opts.tmux || os.Getenv(tmuxWindowEnv) == "" {
+		return nil
+	}
+	alias := plan.Alias
+	if strings.TrimSpace(alias) == "" {
+		alias = plan.EpicName
+	}
+	return &tmuxStatus{out: out, alias: alias}
+}
+
+// setState shows the session state, e.g. running or needs_help.
+func (s *tmuxStatus) setState(state string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+	s.render()
+}
+
+// setBead shows the bead Codex claimed.
+func (s *tmuxStatus) setBead(bead string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bead == bead {
+		return
+	}
+	s.bead = bead
+	s.render()
+}
+
+func (s *tmuxStatus) render() {
+	name := s.alias
+	if s.bead != "" {
+		name += "/" + s.bead
+	}
+	if s.state != "" {
+		name += " " + s.state
+	}
+	fmt.Fprint(s.out, "\x1bk"+oscSafe(name)+"\x1b\\")
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLaunchInTmuxNeedsTmux(t *testing.T) {
+	t.Setenv("TMUX", "")
+	err := launchInTmux([]string{"api", "--tmux"}, goOptions{aliasInput: "api", tmux: true})
+	if err == nil || !strings.Contains(err.Error(), "inside tmux") {
+		t.Fatalf("err = %v", err)
+	}
+	if got := tmuxWindowName(""); got != "obi:issues" {
+		t.Fatalf("window name = %q", got)
+	}
+}
+
+func TestTmuxNewWindowArgsCarryDirAndEnvironment(t *testing.T) {
+	env := []string{"TMUX=/tmp/tmux-1/default,1,0", "OPENAI_API_KEY=sk-1", "TMUX_PANE=%1", "OBI_PROFILE=ci"}
+	got := strings.Join(tmuxNewWindowArgs("obi:api", "/repo", env, "/bin/obi", []string{"api", "--tmux"}), " ")
+	want := "new-window -P -F #{window_id} -n obi:api -c /repo -e OPENAI_API_KEY=sk-1 -e OBI_PROFILE=ci -e " + tmuxWindowEnv + "=1 -- /bin/obi go api --tmux ; set-window-option remain-on-exit on"
+	if got != want {
+		t.Fatalf("args = %q\nwant   %q", got, want)
+	}
+}
+
+func TestTmuxStatusRenamesWindow(t *testing.T) {
+	plan := sessionPlan{Alias: "api"}
+	t.Setenv(tmuxWindowEnv, "")
+	if s := newTmuxStatus(goOptions{tmux: true}, plan, &bytes.Buffer{}); s != nil {
+		t.Fatal("expected no status outside a --tmux window")
+	}
+	var quiet *tmuxStatus
+	quiet.setState("running")
+
+	t.Setenv(tmuxWindowEnv, "1")
+	var out bytes.Buffer
+	s := newTmuxStatus(goOptions{tmux: true}, plan, &out)
+	s.setState("running")
+	s.setBead("bd-12")
+	s.setBead("bd-12")
+	s.setState("needs\x1b_help")
+	want := "\x1bkapi running\x1b\\" + "\x1bkapi/bd-12 running\x1b\\" + "\x1bkapi/bd-12 needs _help\x1b\\"
+	if got := out.String(); got != want {
+		t.Fatalf("wrote %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// cappedTranscript keeps a transcript file under maxBytes while the session
+// is still running. The first half of the budget holds the head of the
+// session; once the file outgrows the budget, everything between the head
+// and the most recent quarter is cut and replaced by a truncation marker.
+type cappedTranscript struct {
+	f        *os.File
+	maxBytes int64
+	headMax  int64
+	keepTail int64
+
+	size    int64
+	headLen int64
+	omitted int64
+}
+
+func newCappedTranscript(f *os.File, maxBytes int64) *cappedTranscript {
+	return &cappedTranscript{
+		f:        f,
+		maxBytes: maxBytes,
+		headMax:  maxBytes / 2,
+		keepTail: maxBytes / 4,
+	}
+}
+
+func (c *cappedTranscript) Write(p []byte) (int, error) {
+	n, err := c.f.Write(p)
+	c.size += int64(n)
+	if c.headLen < c.headMax {
+		c.headLen += int64(n)
+		if c.headLen > c.headMax {
+			c.headLen = c.headMax
+		}
+	}
+	if err != nil {
+		return n, err
+	}
+	if c.size > c.maxBytes {
+		if err := c.compact(); err != nil {
+			return n, fmt.Errorf("truncate transcript: %w", err)
+		}
+	}
+	return n, nil
+}
+
+// compact rewrites the file as head + marker + the last keepTail bytes.
+func (c *cappedTranscript) compact() error {
+	tail := make([]byte, c.keepTail)
+	if _, err := c.f.ReadAt(tail, c.size-c.keepTail); err != nil && err != io.EOF {
+		return err
+	}
+	// Everything after the head except the kept tail (and any previous
+	// marker, which is rewritten below) is gone now.
+	c.omitted += c.size - c.headLen - c.keepTail - int64(len(c.marker()))
+	if c.omitted < 0 {
+		c.omitted = 0
+	}
+	marker := c.marker()
+	if err := c.f.Truncate(c.headLen); err != nil {
+		return err
+	}
+	if _, err := c.f.Seek(c.headLen, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(c.f, marker); err != nil {
+		return err
+	}
+	if _, err := c.f.Write(tail); err != nil {
+		return err
+	}
+	c.size = c.headLen + int64(len(marker)) + c.keepTail
+	return nil
+}
+
+func (c *cappedTranscript) marker() string {
+	if c.omitted == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n\n[obi: transcript truncated here; %d bytes omitted to stay under transcript_max_mb]\n\n", c.omitted)
+}
+
+// Omitted reports how many bytes have been cut from the transcript.
+func (c *cappedTranscript) Omitted() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.omitted
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// transcriptEscapePattern matches CSI and OSC sequences, the other
+// two-byte escapes, and bells.
+var transcriptEscapePattern = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[()][0-9A-Za-z]|[@-Z\\-_])|\x07`)
+
+// maxFilterSegment bounds how much of a line without line breaks the filter
+// holds before writing it out as is.
+const maxFilterSegment = 64 << 10
+
+// transcriptWriters splits the session transcript by [transcript] include:
+// codex receives Codex's output, filtered as configured, and operator
+// obi's notes. A nil writer drops that stream. filter is nil when Codex's
+// output passes through untouched; Flush it once the session ends.
+func transcriptWriters(cfg config.TranscriptConfig, transcript io.Writer) (codex, operator io.Writer, filter *transcriptFilter, err error) {
+	if transcript == nil {
+		return nil, nil, nil, nil
+	}
+	// config.Load has validated cfg; a config built in code may not be.
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, nil, err
+	}
+	include := map[string]bool{}
+	for _, raw := range cfg.Include {
+		include[strings.ToLower(strings.TrimSpace(raw))] = true
+	}
+	all := len(include) == 0
+	if all || include[config.TranscriptStreamOperator] {
+		operator = transcript
+	}
+	if all || include[config.TranscriptStreamCodex] {
+		codex = transcript
+		if cfg.DropANSI || cfg.StripProgress {
+			filter = &transcriptFilter{w: transcript, dropANSI: cfg.DropANSI, stripProgress: cfg.StripProgress}
+			codex = filter
+		}
+	}
+	return codex, operator, filter, nil
+}
+
+// transcriptFilter compacts Codex's output for the transcript. It works on
+// the segments between carriage returns and newlines, which escape
+// sequences never span. With stripProgress a segment ended by a bare "\r"
+// is held until the next one shows whether it was redrawn, so only the
+// last non-blank version of each line is written, ending in "\n".
+type transcriptFilter struct {
+	w             io.Writer
+	dropANSI      bool
+	stripProgress bool
+
+	segment []byte
+	last    []byte
+}
+
+func (f *transcriptFilter) Write(p []byte) (int, error) {
+	var out []byte
+	for _, b := range p {
+		switch {
+		case b == '\n':
+			out = append(append(out, f.lineText()...), '\n')
+			f.segment, f.last = f.segment[:0], nil
+		case b == '\r' && f.stripProgress:
+			if !blankSegment(f.segment) {
+				f.last = append(f.last[:0], f.segment...)
+			}
+			f.segment = f.segment[:0]
+		case b == '\r':
+			out = append(append(out, f.clean(f.segment)...), '\r')
+			f.segment = f.segment[:0]
+		default:
+			f.segment = append(f.segment, b)
+			if len(f.segment) >= maxFilterSegment {
+				out = append(out, f.lineText()...)
+				f.segment, f.last = f.segment[:0], nil
+			}
+		}
+	}
+	if len(out) > 0 {
+		if _, err := f.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes the unterminated end of the stream.
+func (f *transcriptFilter) Flush() error {
+	if f == nil {
+		return nil
+	}
+	text := f.lineText()
+	f.segment, f.last = f.segment[:0], nil
+	if text == "" {
+		return nil
+	}
+	_, err := io.WriteString(f.w, text)
+	return err
+}
+
+// lineText is the cleaned current segment or, when it is blank after a
+// redraw, the last version of the line that had text.
+func (f *transcriptFilter) lineText() string {
+	if f.stripProgress && blankSegment(f.segment) && len(f.last) > 0 {
+		return f.clean(f.last)
+	}
+	return f.clean(f.segment)
+}
+
+func (f *transcriptFilter) clean(segment []byte) string {
+	if !f.dropANSI {
+		return string(segment)
+	}
+	return transcriptEscapePattern.ReplaceAllString(string(segment), "")
+}
+
+// blankSegment reports whether segment holds nothing but whitespace and
+// escape sequences, as when a line is cleared before it is redrawn.
+func blankSegment(segment []byte) bool {
+	return strings.TrimSpace(transcriptEscapePattern.ReplaceAllString(string(segment), "")) == ""
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+// filterChunks writes each chunk separately, as the PTY delivers them.
+func filterChunks(t *testing.T, cfg config.TranscriptConfig, chunks ...string) string {
+	t.Helper()
+	var out strings.Builder
+	codex, _, filter, err := transcriptWriters(cfg, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chunk := range chunks {
+		if _, err := codex.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := filter.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestTranscriptFilterDropsANSI(t *testing.T) {
+	cfg := config.TranscriptConfig{DropANSI: true}
+	got := filterChunks(t, cfg, "\x1b[1;32mok\x1b[", "0m done\r\n\x1b]0;codex\x07title set\n\x07tail")
+	if want := "ok done\r\ntitle set\ntail"; got != want {
+		t.Fatalf("filtered %q, want %q", got, want)
+	}
+}
+
+func TestTranscriptFilterStripsProgress(t *testing.T) {
+	cfg := config.TranscriptConfig{StripProgress: true}
+	got := filterChunks(t, cfg,
+		"Working 1s\r", "Working 2s\rWorking 3s\r",
+		"\x1b[2K\r\n",
+		"plain line\r\n",
+		"Build 10%\rBuild 100%\nlast\r",
+	)
+	want := "Working 3s\nplain line\nBuild 100%\nlast"
+	if got != want {
+		t.Fatalf("filtered %q, want %q", got, want)
+	}
+}
+
+func TestTranscriptWritersHonorInclude(t *testing.T) {
+	var out strings.Builder
+	codex, operator, filter, err := transcriptWriters(config.TranscriptConfig{Include: []string{"Operator"}}, &out)
+	if err != nil || codex != nil || operator == nil || filter != nil {
+		t.Fatalf("operator only: codex=%v operator=%v filter=%v err=%v", codex, operator, filter, err)
+	}
+	codex, operator, _, err = transcriptWriters(config.TranscriptConfig{Include: []string{"codex"}}, &out)
+	if err != nil || codex == nil || operator != nil {
+		t.Fatalf("codex only: codex=%v operator=%v err=%v", codex, operator, err)
+	}
+	if _, _, _, err := transcriptWriters(config.TranscriptConfig{Include: []string{"tui"}}, &out); err == nil || !strings.Contains(err.Error(), "transcript.include") {
+		t.Fatalf("expected include error, got %v", err)
+	}
+}
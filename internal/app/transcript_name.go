@@ -0,0 +1,168 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// transcriptNameProbe stands in for the session ID when checking that a
+// transcript_name_template keeps names unique.
+const transcriptNameProbe = "00000000-0000-4000-8000-000000000000"
+
+// sessionUUIDPattern finds the session ID inside a templated transcript name.
+var sessionUUIDPattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+
+// transcriptFields are the values a transcript_name_template can use. Each
+// is reduced to filename-safe characters before rendering.
+type transcriptFields struct {
+	Date      string
+	Time      string
+	Alias     string
+	EpicKey   string
+	EpicID    string
+	BeadID    string
+	SessionID string
+}
+
+// newTranscriptFields describes a session starting at now.
+func newTranscriptFields(plan sessionPlan, sessionID string, now time.Time) transcriptFields {
+	alias := strings.TrimSpace(plan.Alias)
+	if alias == "" {
+		alias = plan.EpicKey
+	}
+	if alias == "" {
+		alias = plan.EpicID
+	}
+	return transcriptFields{
+		Date:      now.Format("2006-01-02"),
+		Time:      now.Format("150405"),
+		Alias:     alias,
+		EpicKey:   plan.EpicKey,
+		EpicID:    plan.EpicID,
+		BeadID:    plan.BeadIDOverride,
+		SessionID: sessionID,
+	}
+}
+
+func (f transcriptFields) sanitized() transcriptFields {
+	clean := func(value string) string {
+		if strings.TrimSpace(value) == "" {
+			return ""
+		}
+		return sanitizeFilename(value)
+	}
+	return transcriptFields{
+		Date:      clean(f.Date),
+		Time:      clean(f.Time),
+		Alias:     clean(f.Alias),
+		EpicKey:   clean(f.EpicKey),
+		EpicID:    clean(f.EpicID),
+		BeadID:    clean(f.BeadID),
+		SessionID: clean(f.SessionID),
+	}
+}
+
+// transcriptRelPath names a transcript relative to the transcripts
+// directory: <session-id>.log, or tmpl rendered with fields. Slashes in
+// tmpl create subdirectories, e.g. "{{.Alias}}/{{.Date}}-{{.SessionID}}.log".
+func transcriptRelPath(tmpl string, fields transcriptFields) (string, error) {
+	if strings.TrimSpace(tmpl) == "" {
+		return sanitizeFilename(fields.SessionID) + ".log", nil
+	}
+	if err := validateTranscriptTemplate(tmpl); err != nil {
+		return "", err
+	}
+	return renderTranscriptName(tmpl, fields.sanitized())
+}
+
+// validateTranscriptTemplate rejects templates that fail to parse, escape
+// the transcripts directory, or could give two sessions the same name.
+func validateTranscriptTemplate(tmpl string) error {
+	probe := transcriptFields{Date: "2006-01-02", Time: "150405", Alias: "alias", EpicKey: "key", EpicID: "epic", BeadID: "bead", SessionID: transcriptNameProbe}
+	name, err := renderTranscriptName(tmpl, probe)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(name, transcriptNameProbe) {
+		return errors.New("transcript_name_template must include {{.SessionID}} so each transcript stays unique and obi tail can find it")
+	}
+	if !strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".verify.log") {
+		return errors.New("transcript_name_template must end in .log (and not .verify.log)")
+	}
+	return nil
+}
+
+func renderTranscriptName(tmpl string, fields transcriptFields) (string, error) {
+	parsed, err := template.New("transcript").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("transcript_name_template: %w", err)
+	}
+	var b strings.Builder
+	if err := parsed.Execute(&b, fields); err != nil {
+		return "", fmt.Errorf("transcript_name_template: %w", err)
+	}
+	name := filepath.Clean(filepath.FromSlash(b.String()))
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("transcript_name_template: %q leaves the transcripts directory", b.String())
+	}
+	return name, nil
+}
+
+// renameTranscript moves a templated transcript to the name fields now
+// give it, once the session's bead is known. It returns the path the
+// transcript ends up at; on failure the transcript stays where it was.
+func renameTranscript(logPath, current, tmpl string, fields transcriptFields) (string, error) {
+	if strings.TrimSpace(tmpl) == "" || current == "" {
+		return current, nil
+	}
+	rel, err := transcriptRelPath(tmpl, fields)
+	if err != nil {
+		return current, err
+	}
+	target := filepath.Join(transcriptDirFor(logPath), rel)
+	if target == current {
+		return current, nil
+	}
+	if err := ensureTranscriptDir(filepath.Dir(target)); err != nil {
+		return current, err
+	}
+	if err := os.Rename(current, target); err != nil {
+		return current, fmt.Errorf("rename transcript: %w", err)
+	}
+	return target, nil
+}
+
+// isSessionTranscript reports whether a transcripts-directory file name is a
+// session transcript rather than a verify log or prompt copy.
+func isSessionTranscript(name string) bool {
+	return strings.HasSuffix(name, ".log") && !strings.HasSuffix(name, ".verify.log")
+}
+
+// transcriptFiles lists every regular file under the transcripts
+// directory, including the per-epic subdirectories a template may create.
+func transcriptFiles(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.Type().IsRegular() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list transcripts: %w", err)
+	}
+	return paths, nil
+}
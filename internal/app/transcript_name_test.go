@@ -0,0 +1,90 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranscriptRelPathRendersTemplate(t *testing.T) {
+	const session = "0b5e8a52-1c3d-4e6f-8a9b-0c1d2e3f4a5b"
+	start := time.Date(2026, 10, 16, 9, 30, 5, 0, time.UTC)
+	fields := newTranscriptFields(sessionPlan{EpicKey: "obi_api", Alias: "api/v2", EpicID: "obi-api"}, session, start)
+
+	if got, err := transcriptRelPath("", fields); err != nil || got != session+".log" {
+		t.Fatalf("default name = %q, %v", got, err)
+	}
+	got, err := transcriptRelPath("{{.Alias}}/{{.Date}}-{{.BeadID}}-{{.SessionID}}.log", fields)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if want := filepath.Join("api_v2", "2026-10-16--"+session+".log"); got != want {
+		t.Fatalf("rendered %q, want %q", got, want)
+	}
+
+	for tmpl, wantErr := range map[string]string{
+		"{{.Date}}-{{.Alias}}.log":       "{{.SessionID}}",
+		"{{.SessionID}}.txt":             "end in .log",
+		"../{{.SessionID}}.log":          "leaves the transcripts directory",
+		"{{.Branch}}-{{.SessionID}}.log": "transcript_name_template",
+		"{{.SessionID}.log":              "transcript_name_template",
+	} {
+		if _, err := transcriptRelPath(tmpl, fields); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("template %q: expected error containing %q, got %v", tmpl, wantErr, err)
+		}
+	}
+}
+
+func TestTemplatedTranscriptIsFoundAndRenamedForBead(t *testing.T) {
+	const session = "0b5e8a52-1c3d-4e6f-8a9b-0c1d2e3f4a5b"
+	const tmpl = "{{.Alias}}/{{.BeadID}}-{{.SessionID}}.log"
+	logPath := filepath.Join(t.TempDir(), "results.log")
+	fields := newTranscriptFields(sessionPlan{EpicKey: "obi_api", Alias: "api"}, session, time.Now())
+
+	w, path, err := openTranscriptWriter(logPath, "", tmpl, fields)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := w.Write([]byte("output\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	w.Close()
+	if want := filepath.Join(transcriptDirFor(logPath), "api", "-"+session+".log"); path != want {
+		t.Fatalf("transcript at %q, want %q", path, want)
+	}
+	if got, err := transcriptPathFor(logPath, session); err != nil || got != path {
+		t.Fatalf("transcriptPathFor = %q, %v", got, err)
+	}
+	if got := transcriptSession(filepath.Base(path)); got != session {
+		t.Fatalf("transcriptSession = %q", got)
+	}
+
+	fields.BeadID = "obi-api.3"
+	renamed, err := renameTranscript(logPath, path, tmpl, fields)
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if want := filepath.Join(transcriptDirFor(logPath), "api", "obi-api_3-"+session+".log"); renamed != want {
+		t.Fatalf("renamed to %q, want %q", renamed, want)
+	}
+	if got, err := latestTranscript(transcriptDirFor(logPath)); err != nil || got != renamed {
+		t.Fatalf("latestTranscript = %q, %v", got, err)
+	}
+
+	// The verify log stays flat next to the subdirectories and still
+	// counts as belonging to the renamed transcript.
+	verify := verifyOutputPath(logPath, session)
+	if err := os.WriteFile(verify, []byte("ok\n"), 0o600); err != nil {
+		t.Fatalf("write verify log: %v", err)
+	}
+	now := time.Now()
+	plan, err := buildCleanPlan(logPath, filepath.Join(filepath.Dir(logPath), "state.log"), now.AddDate(0, 0, -30), now, "box", func(int) bool { return false })
+	if err != nil {
+		t.Fatalf("buildCleanPlan: %v", err)
+	}
+	if len(plan.Removals) != 0 {
+		t.Fatalf("expected nothing to clean, got %+v", plan.Removals)
+	}
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCappedTranscriptKeepsHeadAndTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.log")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o600)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+
+	const maxBytes = 4000
+	capped := newCappedTranscript(f, maxBytes)
+	var total int64
+	for i := 0; i < 500; i++ {
+		line := fmt.Sprintf("line %04d %s\n", i, strings.Repeat("x", 40))
+		if _, err := capped.Write([]byte(line)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		total += int64(len(line))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	got := string(data)
+	if int64(len(data)) > maxBytes+200 {
+		t.Fatalf("transcript grew to %d bytes", len(data))
+	}
+	if !strings.HasPrefix(got, "line 0000 ") {
+		t.Fatalf("head lost: %q", got[:40])
+	}
+	if !strings.HasSuffix(got, "line 0499 "+strings.Repeat("x", 40)+"\n") {
+		t.Fatalf("tail lost: %q", got[len(got)-60:])
+	}
+	if strings.Count(got, "[obi: transcript truncated here;") != 1 {
+		t.Fatalf("expected exactly one truncation marker:\n%s", got)
+	}
+	marker := fmt.Sprintf("%d bytes omitted", capped.Omitted())
+	if !strings.Contains(got, marker) {
+		t.Fatalf("marker does not report %s", marker)
+	}
+	kept := int64(len(data)) - int64(len(capped.marker()))
+	if kept+capped.Omitted() != total {
+		t.Fatalf("kept %d + omitted %d != written %d", kept, capped.Omitted(), total)
+	}
+}
+
+func TestCappedTranscriptUnderLimitIsUntouched(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.log")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o600)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+
+	capped := newCappedTranscript(f, 1<<20)
+	if _, err := capped.Write([]byte("short session\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if capped.Omitted() != 0 {
+		t.Fatalf("nothing should be omitted")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "short session\n" {
+		t.Fatalf("unexpected transcript %q", data)
+	}
+}
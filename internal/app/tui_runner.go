@@ -5,11 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/tui"
 )
@@ -22,6 +26,10 @@ type sessionDisplay struct {
 	inputCancel context.CancelFunc
 	inputDone   chan error
 	stopOnce    sync.Once
+	// router also hosts the escalation approval modal.
+	router *tui.InputRouter
+	// undivert sends obi's warnings back to stderr once the shell is gone.
+	undivert func()
 }
 
 func (d *sessionDisplay) Stop() {
@@ -29,19 +37,22 @@ func (d *sessionDisplay) Stop() {
 		return
 	}
 	d.stopOnce.Do(func() {
+		if d.undivert != nil {
+			d.undivert()
+		}
 		close(d.release)
 		if d.inputCancel != nil {
 			d.inputCancel()
 		}
 		if d.inputDone != nil {
 			if err := <-d.inputDone; err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, io.EOF) {
-				fmt.Fprintf(os.Stderr, "tui input: %v\n", err)
+				diag.Logger().Warn("tui input stopped", "err", err)
 			}
 		}
 		d.cancel()
 		if d.done != nil {
 			if err := <-d.done; err != nil && err != context.Canceled {
-				fmt.Fprintf(os.Stderr, "tui shell: %v\n", err)
+				diag.Logger().Warn("tui shell stopped", "err", err)
 			}
 		}
 	})
@@ -70,7 +81,95 @@ func (d *sessionDisplay) notifyEvent(kind operatorEventKind, message string) {
 	}
 }
 
-func startSessionTUI(handle *interactive.SessionHandle, plan sessionPlan, log *operatorLog) (*sessionDisplay, error) {
+// degradedNotice warns that Codex runs on pipes because no PTY was
+// available, or returns "" for a normal session.
+func degradedNotice(handle *interactive.SessionHandle) string {
+	note := handle.Degraded()
+	if note == "" {
+		return ""
+	}
+	return note + "; Codex may behave differently without a TTY."
+}
+
+// sessionTheme resolves the configured palette; a non-empty NO_COLOR value
+// (https://no-color.org) always disables styling.
+func sessionTheme(cfg config.TUIConfig, noColor string) (tui.Theme, error) {
+	if noColor != "" {
+		return tui.MonochromeTheme(), nil
+	}
+	return tui.NewTheme(cfg.Theme, tui.ThemeColors{
+		Accent:  cfg.Colors.Accent,
+		Success: cfg.Colors.Success,
+		Warning: cfg.Colors.Warning,
+		Failure: cfg.Colors.Failure,
+		Dim:     cfg.Colors.Dim,
+	})
+}
+
+// sessionTUISettings carries the resolved [tui] config into startSessionTUI.
+type sessionTUISettings struct {
+	shellOpts   []tui.Option
+	stallAfter  time.Duration
+	stallNotify bool
+	// attention alerts the operator to a stall when stallNotify is set.
+	attention *attentionNotifier
+	// transcriptPath backs scrollback past the in-memory buffer.
+	transcriptPath string
+	// softStopReasons are the quick-pick presets for the 's' hotkey.
+	softStopReasons []string
+	// diffBase is the HEAD the 'd' overlay diffs against; empty outside git.
+	diffBase string
+	// onBeadClaim, if set, hears about each bead Codex claims.
+	onBeadClaim func(bead string)
+}
+
+// loadSessionTUISettings translates the [tui] config block into shell options.
+func loadSessionTUISettings(cfg config.TUIConfig, noColor string) (sessionTUISettings, error) {
+	theme, err := sessionTheme(cfg, noColor)
+	if err != nil {
+		return sessionTUISettings{}, err
+	}
+	timestamps, err := tui.ParseTimestampMode(cfg.Timestamps)
+	if err != nil {
+		return sessionTUISettings{}, err
+	}
+	opts := []tui.Option{tui.WithTheme(theme), tui.WithTimestamps(timestamps)}
+	if cfg.SeparatorMinutes > 0 {
+		opts = append(opts, tui.WithLogSeparators(time.Duration(cfg.SeparatorMinutes)*time.Minute))
+	}
+	if cfg.CollapseProgress {
+		opts = append(opts, tui.WithProgressCollapse(interactive.DefaultCollapseWindow))
+	}
+	if cfg.WrapLines {
+		opts = append(opts, tui.WithLineWrap(true))
+	}
+	if cfg.Scrollback > 0 {
+		opts = append(opts, tui.WithMaxLogs(cfg.Scrollback))
+	}
+	layout := tui.Layout{Title: cfg.Layout.Title, Context: cfg.Layout.Context, Status: cfg.Layout.Status, Footer: cfg.Layout.Footer}
+	for _, field := range []struct{ key, template string }{
+		{"title", layout.Title}, {"context", layout.Context}, {"status", layout.Status}, {"footer", layout.Footer},
+	} {
+		if err := tui.ValidateLayoutTemplate(field.template); err != nil {
+			return sessionTUISettings{}, fmt.Errorf("tui.layout.%s: %w", field.key, err)
+		}
+	}
+	opts = append(opts, tui.WithLayout(layout))
+	if cfg.UsdPerMTok > 0 {
+		opts = append(opts, tui.WithCostRate(cfg.UsdPerMTok))
+	}
+	if len(cfg.SoftStopReasons) > tui.MaxSoftStopPresets {
+		return sessionTUISettings{}, fmt.Errorf("tui.soft_stop_reasons: at most %d presets (one digit each), got %d", tui.MaxSoftStopPresets, len(cfg.SoftStopReasons))
+	}
+	return sessionTUISettings{
+		shellOpts:       opts,
+		stallAfter:      cfg.StallThresholdValue(),
+		stallNotify:     cfg.StallNotify,
+		softStopReasons: cfg.SoftStopReasons,
+	}, nil
+}
+
+func startSessionTUI(handle *interactive.SessionHandle, plan sessionPlan, log *operatorLog, audit *auditLog, settings sessionTUISettings) (*sessionDisplay, error) {
 	if handle == nil {
 		return nil, nil
 	}
@@ -80,10 +179,30 @@ func startSessionTUI(handle *interactive.SessionHandle, plan sessionPlan, log *o
 	}
 
 	header := fmt.Sprintf("Obi session · %s (%s)", plan.EpicName, plan.EpicID)
-	shell := tui.NewShell(
+	display := &sessionDisplay{}
+	opts := append([]tui.Option{
 		tui.WithHeader(header),
-		tui.WithFooterHints([]string{"p: pause", "h: hint", "s: soft stop", "q: abort"}),
-	)
+		tui.WithFooterHints([]string{"p: pause", "h: hint", "i: input", "b: bead", "d: diff", "w: wrap", "t: timestamps", "s: soft stop", "q: abort"}),
+		tui.WithBeadDetails(func(ctx context.Context, beadID string) (tui.BeadDetails, error) {
+			details, err := fetchBeadDetails(ctx, beadID)
+			if err != nil {
+				diag.Logger().Warn("bead details unavailable", "bead", beadID, "err", err)
+			}
+			return details, err
+		}),
+		tui.WithDiffView(gitDiffFetcher(gitRunDir(plan), settings.diffBase)),
+		tui.WithStallDetection(settings.stallAfter, func(silence time.Duration) {
+			if !settings.stallNotify {
+				return
+			}
+			settings.attention.alert(attentionStall, fmt.Sprintf("no output from Codex for %s (%s)", silence.Round(time.Second), plan.Alias))
+			display.notifyEvent(operatorEventStall, fmt.Sprintf("No output from Codex for %s; consider a hint (h) or soft stop (s).", silence.Round(time.Second)))
+		}),
+	}, settings.shellOpts...)
+	if settings.transcriptPath != "" {
+		opts = append(opts, tui.WithScrollbackFile(settings.transcriptPath))
+	}
+	shell := tui.NewShell(opts...)
 	shell.UpdateStatus(func(line *tui.StatusLine) {
 		line.EpicAlias = plan.Alias
 		if strings.TrimSpace(line.EpicAlias) == "" {
@@ -98,7 +217,9 @@ func startSessionTUI(handle *interactive.SessionHandle, plan sessionPlan, log *o
 	release := make(chan struct{})
 	events := make(chan interactive.SessionEvent, 64)
 
+	claims := &beadClaimWatcher{}
 	go func() {
+		defer tui.RecoverPanic()
 		defer close(events)
 		for {
 			select {
@@ -107,6 +228,18 @@ func startSessionTUI(handle *interactive.SessionHandle, plan sessionPlan, log *o
 					<-release
 					return
 				}
+				if evt.Type == interactive.EventLogChunk && evt.Stream != "" {
+					if bead := claims.observe(evt.Chunk); bead != "" {
+						shell.UpdateStatus(func(line *tui.StatusLine) {
+							if line.BeadID != bead {
+								line.BeadID, line.BeadTitle = bead, ""
+							}
+						})
+						if settings.onBeadClaim != nil {
+							settings.onBeadClaim(bead)
+						}
+					}
+				}
 				events <- evt
 			case <-release:
 				return
@@ -116,6 +249,7 @@ func startSessionTUI(handle *interactive.SessionHandle, plan sessionPlan, log *o
 
 	done := make(chan error, 1)
 	go func() {
+		defer tui.RecoverPanic()
 		done <- shell.Run(ctx, events)
 	}()
 
@@ -135,29 +269,38 @@ func startSessionTUI(handle *interactive.SessionHandle, plan sessionPlan, log *o
 		line.RunStatus = string(interactive.StateRunning)
 	})
 
-	display := &sessionDisplay{
-		shell:   shell,
-		cancel:  cancel,
-		done:    done,
-		release: release,
+	display.shell = shell
+	// stderr is hidden behind the raw-mode screen; show obi's own warnings
+	// in the shell's message area until Stop.
+	display.undivert = diag.Divert(func(_ slog.Level, text string) { shell.Warn(text) })
+	display.cancel = cancel
+	display.done = done
+	display.release = release
+	if plan.StateBanner != "" {
+		display.notifyEvent(operatorEventEpicState, plan.StateBanner)
 	}
+	display.notifyEvent(operatorEventDegraded, degradedNotice(handle))
 
 	controls := &sessionControlsAdapter{
 		session: handle,
 		log:     log,
+		audit:   audit,
 		notify:  display.notifyEvent,
 	}
 	hintSubmitter := &hintSubmitterAdapter{
 		session: handle,
 		log:     log,
+		audit:   audit,
 		notify:  display.notifyEvent,
 	}
-	router := tui.NewInputRouter(controls, shell, tui.WithHintSubmitter(hintSubmitter))
+	router := tui.NewInputRouter(controls, auditedShell{Shell: shell, audit: audit}, tui.WithHintSubmitter(hintSubmitter), tui.WithSoftStopPresets(settings.softStopReasons))
+	display.router = router
 
 	inputCtx, inputCancel := context.WithCancel(context.Background())
 	display.inputCancel = inputCancel
 	display.inputDone = make(chan error, 1)
 	go func() {
+		defer tui.RecoverPanic()
 		reader := shell.InputReader()
 		if reader == nil {
 			display.inputDone <- io.EOF
@@ -167,13 +310,20 @@ func startSessionTUI(handle *interactive.SessionHandle, plan sessionPlan, log *o
 	}()
 
 	go func() {
+		defer tui.RecoverPanic()
 		ticker := time.NewTicker(time.Second)
 		defer ticker.Stop()
+		reported := 0
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
+				drops := handle.DroppedEvents()
+				if total := totalDrops(drops); total > reported {
+					reported = total
+					diag.Logger().Warn(fmt.Sprintf("display fell behind; %d events dropped so far (%s); raise [tui] event_buffer to reduce drops", total, formatDropCounts(drops)))
+				}
 				shell.RequestRender()
 			}
 		}
@@ -182,11 +332,46 @@ func startSessionTUI(handle *interactive.SessionHandle, plan sessionPlan, log *o
 	return display, nil
 }
 
+// droppedEventCounts converts runner drop counts for the ledger; counts are
+// only meaningful when something was consuming the event stream.
+func droppedEventCounts(drops map[interactive.SessionEventType]int, consumed bool) map[string]int {
+	if !consumed || len(drops) == 0 {
+		return nil
+	}
+	out := make(map[string]int, len(drops))
+	for kind, n := range drops {
+		out[string(kind)] = n
+	}
+	return out
+}
+
+func totalDrops(drops map[interactive.SessionEventType]int) int {
+	total := 0
+	for _, n := range drops {
+		total += n
+	}
+	return total
+}
+
+func formatDropCounts(drops map[interactive.SessionEventType]int) string {
+	kinds := make([]string, 0, len(drops))
+	for kind := range drops {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%s: %d", kind, drops[interactive.SessionEventType(kind)]))
+	}
+	return strings.Join(parts, ", ")
+}
+
 type eventNotifier func(operatorEventKind, string)
 
 type sessionControlsAdapter struct {
 	session *interactive.SessionHandle
 	log     *operatorLog
+	audit   *auditLog
 	notify  eventNotifier
 }
 
@@ -201,10 +386,13 @@ func (s *sessionControlsAdapter) SoftStop(reason string) error {
 	if s.session == nil {
 		return errors.New("session controls unavailable")
 	}
+	s.log.expectAck(operatorEventSoftStop)
 	if err := s.session.SoftStop(reason); err != nil {
+		s.log.cancelAck()
 		return err
 	}
 	s.log.record(operatorEventSoftStop, reason)
+	s.audit.record(auditSoftStop, reason)
 	if s.notify != nil {
 		s.notify(operatorEventSoftStop, fmt.Sprintf("Soft stop requested: %s", reason))
 	}
@@ -215,12 +403,14 @@ func (s *sessionControlsAdapter) Abort() error {
 	if s.session == nil {
 		return errors.New("session controls unavailable")
 	}
+	s.audit.record(auditAbort, "hotkey 'q'")
 	return s.session.Abort()
 }
 
 type hintSubmitterAdapter struct {
 	session *interactive.SessionHandle
 	log     *operatorLog
+	audit   *auditLog
 	notify  eventNotifier
 }
 
@@ -232,10 +422,13 @@ func (h *hintSubmitterAdapter) SubmitHint(text string) error {
 	if trimmed == "" {
 		return nil
 	}
+	h.log.expectAck(operatorEventHint)
 	if err := h.session.SubmitHint(trimmed); err != nil {
+		h.log.cancelAck()
 		return err
 	}
 	h.log.record(operatorEventHint, trimmed)
+	h.audit.record(auditHint, trimmed)
 	if h.notify != nil {
 		h.notify(operatorEventHint, fmt.Sprintf("Hint sent: %s", trimmed))
 	}
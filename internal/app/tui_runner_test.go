@@ -0,0 +1,27 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
+)
+
+func TestDroppedEventCountsOnlyWhenConsumed(t *testing.T) {
+	drops := map[interactive.SessionEventType]int{
+		interactive.EventLogChunk:    3,
+		interactive.EventStateChange: 1,
+	}
+	if got := droppedEventCounts(drops, false); got != nil {
+		t.Fatalf("expected drops ignored without a consumer, got %v", got)
+	}
+	got := droppedEventCounts(drops, true)
+	if got["log_chunk"] != 3 || got["state_change"] != 1 {
+		t.Fatalf("unexpected ledger counts: %v", got)
+	}
+	if total := totalDrops(drops); total != 4 {
+		t.Fatalf("expected 4 total drops, got %d", total)
+	}
+	if text := formatDropCounts(drops); text != "log_chunk: 3, state_change: 1" {
+		t.Fatalf("unexpected drop summary %q", text)
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// verificationResult records the epic's verify.command run in the ledger.
+type verificationResult struct {
+	Command    string `json:"command"`
+	Passed     bool   `json:"passed"`
+	ExitCode   int    `json:"exit_code"`
+	OutputPath string `json:"output_path,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// verifyOutputPath stores verification output next to the session transcript.
+func verifyOutputPath(logPath, sessionID string) string {
+	return filepath.Join(transcriptDirFor(logPath), sanitizeFilename(sessionID)+".verify.log")
+}
+
+// runVerification runs command via sh -c in dir, capturing combined output
+// to outputPath. It gets the environment Codex got: env on top of obi's
+// own, without the unset names (other epics' secrets). The saved output
+// has secrets redacted. A command that fails to start counts as a failed
+// check; only problems writing the output file are returned as errors.
+func runVerification(command, dir string, env, unset, secrets []string, outputPath string) (verificationResult, error) {
+	res := verificationResult{Command: command, OutputPath: outputPath}
+	if err := ensureTranscriptDir(filepath.Dir(outputPath)); err != nil {
+		return res, err
+	}
+	out, err := os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return res, fmt.Errorf("open verification output: %w", err)
+	}
+	defer out.Close()
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = childEnv(env, unset)
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	started := time.Now()
+	runErr := cmd.Run()
+	res.DurationMs = time.Since(started).Milliseconds()
+
+	var exitErr *exec.ExitError
+	switch {
+	case runErr == nil:
+		res.Passed = true
+	case errors.As(runErr, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+	default:
+		res.ExitCode = -1
+		fmt.Fprintf(out, "obi: run verification: %v\n", runErr)
+	}
+	if err := out.Close(); err != nil {
+		return res, fmt.Errorf("write verification output: %w", err)
+	}
+	return res, redactFile(outputPath, secrets)
+}
+
+// redactFile rewrites path with secrets masked, leaving it alone when none
+// occur.
+func redactFile(path string, secrets []string) error {
+	if len(secrets) == 0 {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read verification output: %w", err)
+	}
+	redacted, changed := redactText(string(data), secrets)
+	if !changed {
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(redacted), 0o600); err != nil {
+		return fmt.Errorf("redact verification output: %w", err)
+	}
+	return nil
+}
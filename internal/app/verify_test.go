@@ -0,0 +1,69 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunVerificationPassesInDirWithEnv(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "marker"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outPath := filepath.Join(t.TempDir(), "nested", "verify.log")
+
+	res, err := runVerification(`test -f marker && echo "flag=$VERIFY_FLAG"`, dir, []string{"VERIFY_FLAG=on"}, nil, nil, outPath)
+	if err != nil {
+		t.Fatalf("runVerification: %v", err)
+	}
+	if !res.Passed || res.ExitCode != 0 {
+		t.Fatalf("expected pass, got %+v", res)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "flag=on" {
+		t.Fatalf("unexpected output %q", data)
+	}
+}
+
+func TestRunVerificationReportsExitCode(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "verify.log")
+	res, err := runVerification("echo oops >&2; exit 2", t.TempDir(), nil, nil, nil, outPath)
+	if err != nil {
+		t.Fatalf("runVerification: %v", err)
+	}
+	if res.Passed || res.ExitCode != 2 {
+		t.Fatalf("expected exit 2 failure, got %+v", res)
+	}
+	if data, _ := os.ReadFile(outPath); !strings.Contains(string(data), "oops") {
+		t.Fatalf("expected stderr captured, got %q", data)
+	}
+}
+
+func TestRunVerificationDropsForeignSecretsAndRedacts(t *testing.T) {
+	t.Setenv("OTHER_EPIC_TOKEN", "leaked-value")
+	outPath := filepath.Join(t.TempDir(), "verify.log")
+	command := `echo "other=${OTHER_EPIC_TOKEN:-unset} own=$OWN_TOKEN"`
+	res, err := runVerification(command, t.TempDir(), []string{"OWN_TOKEN=own-value"}, []string{"OTHER_EPIC_TOKEN"}, []string{"own-value"}, outPath)
+	if err != nil || !res.Passed {
+		t.Fatalf("runVerification = %+v, %v", res, err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); strings.Contains(got, "leaked-value") || strings.Contains(got, "own-value") || !strings.HasPrefix(got, "other=unset own=") {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestVerifyOutputPathSitsWithTranscripts(t *testing.T) {
+	got := verifyOutputPath("/tmp/obi/results.log", "sess:1")
+	if got != filepath.Join("/tmp/obi/transcripts", "sess_1.verify.log") {
+		t.Fatalf("unexpected path %q", got)
+	}
+}
@@ -1,16 +1,34 @@
 package app
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
 	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var (
 	semver = "0.2.0"
 	gitSHA string
+	// buildDate is an RFC 3339 timestamp set with -ldflags -X; builds from
+	// a checkout fall back to the commit time Go records.
+	buildDate string
 )
 
+// releaseCheckTimeout bounds the [update] release_url request.
+const releaseCheckTimeout = 5 * time.Second
+
 // Version returns the semantic version plus the current git SHA (if known).
-// Override via -ldflags "-X github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/app.semver=x.y.z -X github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/app.gitSHA=abcdef1".
+// Override via -ldflags "-X github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/app.semver=x.y.z -X github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/app.gitSHA=abcdef1"
+// (and app.buildDate=2026-10-16T12:00:00Z for --version --verbose).
 func Version() string {
 	if sha := resolveGitSHA(); sha != "" {
 		return semver + " (" + sha + ")"
@@ -40,3 +58,195 @@ func shortSHA(val string) string {
 	}
 	return val
 }
+
+// RunVersion handles obi --version. With --verbose it also prints the build
+// metadata, the codex and bd versions found on PATH, and, when [update]
+// release_url is set, whether a newer release is out.
+func RunVersion(args []string) error {
+	fs := newCommandFlags("--version", "obi --version [--verbose] [--config path]",
+		"Print the obi version. --verbose adds what a bug report needs.")
+	var verbose bool
+	var configPath string
+	fs.BoolVar(&verbose, "verbose", false, "print build metadata, component versions, and any newer release")
+	fs.StringVar(&configPath, "config", "", "path to obi config (for codex.binary and [update])")
+	if _, err := fs.parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if !verbose {
+		fmt.Println(Version())
+		return nil
+	}
+
+	codexBinary := "codex"
+	var untrustedCodex string
+	var releaseURL string
+	// Outside a repo there is no config; the defaults still apply.
+	if _, cfg, err := loadConfig(configPath, ""); err == nil {
+		switch {
+		case cfg.Codex.Binary == "" || cfg.Codex.Binary == codexBinary:
+		case configPath != "" || os.Getenv("OBI_CONFIG") != "":
+			codexBinary = cfg.Codex.Binary
+		default:
+			// An obi.toml found in the current directory may come from
+			// any checkout, so its binary is named but not run.
+			untrustedCodex = cfg.Codex.Binary
+		}
+		releaseURL = strings.TrimSpace(cfg.Update.ReleaseURL)
+	} else if configPath != "" {
+		return err
+	}
+
+	w := os.Stdout
+	writeBuildMetadata(w, readBuildMetadata())
+	writeVersionField(w, "codex", componentVersion(codexBinary))
+	if untrustedCodex != "" {
+		fmt.Fprintf(w, "  %-8s obi.toml sets codex.binary = %q; pass --config to check that one\n", "", untrustedCodex)
+	}
+	writeVersionField(w, "bd", componentVersion("bd"))
+	if releaseURL != "" {
+		client := &http.Client{Timeout: releaseCheckTimeout}
+		writeVersionField(w, "update", describeUpdate(semver, releaseURL, client))
+	}
+	return nil
+}
+
+// buildMetadata is what obi knows about its own build.
+type buildMetadata struct {
+	Version  string
+	Commit   string
+	Modified bool
+	Date     string
+	Go       string
+}
+
+func readBuildMetadata() buildMetadata {
+	meta := buildMetadata{Version: semver, Commit: gitSHA, Date: buildDate, Go: runtime.Version()}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return meta
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if meta.Commit == "" {
+				meta.Commit = setting.Value
+			}
+		case "vcs.time":
+			if meta.Date == "" {
+				meta.Date = setting.Value
+			}
+		case "vcs.modified":
+			meta.Modified = setting.Value == "true"
+		}
+	}
+	return meta
+}
+
+func writeBuildMetadata(w io.Writer, meta buildMetadata) {
+	fmt.Fprintf(w, "obi %s\n", meta.Version)
+	commit := meta.Commit
+	switch {
+	case commit == "":
+		commit = "unknown"
+	case meta.Modified:
+		commit += " (modified)"
+	}
+	writeVersionField(w, "commit", commit)
+	date := meta.Date
+	if date == "" {
+		date = "unknown"
+	}
+	writeVersionField(w, "built", date)
+	writeVersionField(w, "go", fmt.Sprintf("%s %s/%s", meta.Go, runtime.GOOS, runtime.GOARCH))
+}
+
+func writeVersionField(w io.Writer, name, value string) {
+	fmt.Fprintf(w, "  %-8s %s\n", name+":", value)
+}
+
+// componentVersion is the first line binary prints for --version, or a note
+// that it could not be run.
+func componentVersion(binary string) string {
+	if version := toolVersion("", binary, "--version"); version != "" {
+		return version
+	}
+	return fmt.Sprintf("not found (%s --version failed)", binary)
+}
+
+// latestRelease is the part of a release feed obi reads.
+type latestRelease struct {
+	TagName string `json:"tag_name"`
+	Version string `json:"version"`
+	HTMLURL string `json:"html_url"`
+}
+
+// describeUpdate compares current with the latest release at url.
+func describeUpdate(current, url string, client *http.Client) string {
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Sprintf("check failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Sprintf("check failed: %s returned %s", url, resp.Status)
+	}
+	var release latestRelease
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&release); err != nil {
+		return fmt.Sprintf("check failed: decode %s: %v", url, err)
+	}
+	latest := strings.TrimSpace(release.TagName)
+	if latest == "" {
+		latest = strings.TrimSpace(release.Version)
+	}
+	if latest == "" {
+		return fmt.Sprintf("check failed: %s names no tag_name or version", url)
+	}
+	if compareVersions(latest, current) <= 0 {
+		return fmt.Sprintf("up to date (latest %s)", latest)
+	}
+	if release.HTMLURL != "" {
+		return fmt.Sprintf("%s available: %s", latest, release.HTMLURL)
+	}
+	return latest + " available"
+}
+
+// compareVersions orders dotted versions such as v0.10.1 numerically,
+// ignoring a leading v and any -prerelease or +build suffix.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if cut := strings.IndexAny(version, "-+"); cut >= 0 {
+		version = version[:cut]
+	}
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
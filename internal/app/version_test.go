@@ -1,6 +1,12 @@
 package app
 
-import "testing"
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
 
 func TestVersionIncludesSemver(t *testing.T) {
 	origSemver := semver
@@ -33,3 +39,57 @@ func TestVersionIncludesGitSHA(t *testing.T) {
 		t.Fatalf("expected semver + short sha, got %q", got)
 	}
 }
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"v0.10.0", "0.9.3", 1},
+		{"0.2.0", "v0.2.0", 0},
+		{"0.2", "0.2.0", 0},
+		{"v0.2.1-rc.1", "0.2.1", 0},
+		{"0.2.0", "0.3.0", -1},
+	}
+	for _, tc := range cases {
+		if got := compareVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestDescribeUpdate(t *testing.T) {
+	body := `{"tag_name": "v0.3.0", "html_url": "https://example.com/releases/v0.3.0"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	if got := describeUpdate("0.2.0", srv.URL, srv.Client()); got != "v0.3.0 available: https://example.com/releases/v0.3.0" {
+		t.Fatalf("newer = %q", got)
+	}
+	if got := describeUpdate("0.3.0", srv.URL, srv.Client()); got != "up to date (latest v0.3.0)" {
+		t.Fatalf("current = %q", got)
+	}
+	if got := describeUpdate("0.2.0", srv.URL+"/missing", srv.Client()); !strings.HasPrefix(got, "check failed: ") || !strings.Contains(got, "404") {
+		t.Fatalf("missing = %q", got)
+	}
+	body = `{"version": "0.2.0"}`
+	if got := describeUpdate("0.2.0", srv.URL, srv.Client()); got != "up to date (latest 0.2.0)" {
+		t.Fatalf("version field = %q", got)
+	}
+}
+
+func TestWriteBuildMetadata(t *testing.T) {
+	var out strings.Builder
+	writeBuildMetadata(&out, buildMetadata{Version: "0.2.0", Commit: "abcdef123456", Modified: true, Go: "go1.22.1"})
+	for _, want := range []string{"obi 0.2.0\n", "  commit:  abcdef123456 (modified)\n", "  built:   unknown\n", "  go:      go1.22.1 "} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("missing %q in:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package app
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+)
+
+const (
+	webhookEventCompleted = "session.completed"
+	webhookTimeout        = 10 * time.Second
+	webhookBackoff        = time.Second
+	webhookSignatureKey   = "X-Obi-Signature"
+	webhookEventKey       = "X-Obi-Event"
+)
+
+// webhookPayload is the JSON body posted to each [webhooks] endpoint. The
+// entry uses the public ledger schema so receivers see stable fields.
+type webhookPayload struct {
+	Event         string      `json:"event"`
+	Entry         LedgerEntry `json:"entry"`
+	TranscriptURL string      `json:"transcript_url,omitempty"`
+}
+
+// webhookDelivery is one line of webhooks.log. Endpoint is reduced to its
+// host because chat webhook URLs usually embed a token.
+type webhookDelivery struct {
+	Time       time.Time `json:"time"`
+	SessionID  string    `json:"session_id"`
+	EpicID     string    `json:"epic_id,omitempty"`
+	Endpoint   string    `json:"endpoint"`
+	Attempts   int       `json:"attempts"`
+	Delivered  bool      `json:"delivered"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Signed     bool      `json:"signed"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// webhookSender posts finished sessions to the configured endpoints. A nil
+// sender (no endpoints) does nothing, and delivery problems are reported
+// without failing the run.
+type webhookSender struct {
+	endpoints     []string
+	secret        []byte
+	retries       int
+	transcriptURL string
+	logPath       string
+	client        *http.Client
+	sleep         func(time.Duration)
+	now           func() time.Time
+}
+
+func newWebhookSender(cfg config.WebhooksConfig, logPath string) *webhookSender {
+	var endpoints []string
+	for _, endpoint := range cfg.Endpoints {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+	return &webhookSender{
+		endpoints:     endpoints,
+		secret:        []byte(os.Getenv(cfg.SecretEnvValue())),
+		retries:       cfg.RetriesValue(),
+		transcriptURL: strings.TrimSpace(cfg.TranscriptURL),
+		logPath:       logPath,
+		client:        &http.Client{Timeout: webhookTimeout},
+		sleep:         time.Sleep,
+		now:           time.Now,
+	}
+}
+
+// deliver posts entry to every endpoint and appends each outcome to the
+// delivery log.
+func (s *webhookSender) deliver(entry ledgerEntry) {
+	if s == nil {
+		return
+	}
+	body, err := json.Marshal(webhookPayload{
+		Event:         webhookEventCompleted,
+		Entry:         publicLedgerEntry(entry),
+		TranscriptURL: s.transcriptLink(entry),
+	})
+	if err != nil {
+		diag.Logger().Error("encode webhook payload", "session", entry.SessionID, "err", err)
+		return
+	}
+	for _, endpoint := range s.endpoints {
+		record := s.post(endpoint, body)
+		record.SessionID = entry.SessionID
+		record.EpicID = entry.EpicID
+		if record.Delivered {
+			fmt.Printf("Webhook %s: delivered (HTTP %d).\n", record.Endpoint, record.StatusCode)
+		} else {
+			fmt.Printf("Warning: webhook %s failed after %d attempt(s): %s\n", record.Endpoint, record.Attempts, record.Error)
+		}
+		if err := appendWebhookDelivery(s.logPath, record); err != nil {
+			diag.Logger().Warn("webhook log write failed", "path", s.logPath, "err", err)
+		}
+	}
+}
+
+// post sends body to endpoint, retrying network errors, 429s, and 5xx
+// responses with doubling backoff.
+func (s *webhookSender) post(endpoint string, body []byte) webhookDelivery {
+	record := webhookDelivery{Endpoint: webhookHost(endpoint), Signed: len(s.secret) > 0}
+	if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		record.Error = "invalid endpoint URL (want http or https)"
+		record.Time = s.now().UTC()
+		return record
+	}
+	backoff := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		record.Attempts = attempt
+		status, err := s.send(endpoint, body)
+		record.StatusCode = status
+		if err == nil {
+			record.Delivered = true
+			record.Error = ""
+			break
+		}
+		record.Error = err.Error()
+		if attempt > s.retries || !retryableWebhookStatus(status) {
+			break
+		}
+		s.sleep(backoff)
+		backoff *= 2
+	}
+	record.Time = s.now().UTC()
+	return record
+}
+
+func (s *webhookSender) send(endpoint string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("build request for %s: %v", webhookHost(endpoint), err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventKey, webhookEventCompleted)
+	if len(s.secret) > 0 {
+		req.Header.Set(webhookSignatureKey, webhookSignature(s.secret, body))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// Drop the URL from the error; it may carry a token.
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookSignature is "sha256=" plus the hex HMAC-SHA256 of body.
+func webhookSignature(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryableWebhookStatus reports whether a failed attempt is worth
+// repeating: transport errors (status 0), rate limits, and server errors.
+func retryableWebhookStatus(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
+
+func (s *webhookSender) transcriptLink(entry ledgerEntry) string {
+	path := strings.TrimSpace(entry.TranscriptPath)
+	if path == "" {
+		return ""
+	}
+	if s.transcriptURL != "" {
+		return strings.NewReplacer(
+			"{session}", sanitizeFilename(entry.SessionID),
+			"{file}", filepath.Base(path),
+		).Replace(s.transcriptURL)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// webhookHosts lists the configured endpoints by host, for display.
+func webhookHosts(endpoints []string) []string {
+	var hosts []string
+	for _, endpoint := range endpoints {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			hosts = append(hosts, webhookHost(endpoint))
+		}
+	}
+	return hosts
+}
+
+func webhookHost(endpoint string) string {
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "(invalid endpoint)"
+}
+
+func appendWebhookDelivery(path string, record webhookDelivery) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode webhook delivery: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create webhook log dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open webhook log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write webhook log: %w", err)
+	}
+	return nil
+}
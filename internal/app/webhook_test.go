@@ -0,0 +1,98 @@
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestWebhookSenderSignsAndRetries(t *testing.T) {
+	var calls int
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(webhookSignatureKey)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	t.Setenv("OBI_TEST_HOOK_SECRET", "s3cret")
+	logPath := filepath.Join(t.TempDir(), "webhooks.log")
+	sender := newWebhookSender(config.WebhooksConfig{
+		Endpoints:     []string{server.URL},
+		SecretEnv:     "OBI_TEST_HOOK_SECRET",
+		TranscriptURL: "https://ci.example/transcripts/{file}",
+	}, logPath)
+	var slept []time.Duration
+	sender.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	sender.deliver(ledgerEntry{SessionID: "abc", EpicID: "obi-1", Status: "success", TranscriptPath: "/tmp/t/abc.log"})
+
+	if calls != 2 || len(slept) != 1 || slept[0] != webhookBackoff {
+		t.Fatalf("expected one retry after %v, got calls=%d slept=%v", webhookBackoff, calls, slept)
+	}
+	if want := webhookSignature([]byte("s3cret"), body); signature != want {
+		t.Fatalf("signature %q, want %q", signature, want)
+	}
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Event != webhookEventCompleted || payload.Entry.SessionID != "abc" || payload.TranscriptURL != "https://ci.example/transcripts/abc.log" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read delivery log: %v", err)
+	}
+	var record webhookDelivery
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("decode delivery: %v", err)
+	}
+	if !record.Delivered || record.Attempts != 2 || record.StatusCode != http.StatusNoContent || !record.Signed || record.SessionID != "abc" {
+		t.Fatalf("unexpected delivery record: %+v", record)
+	}
+	if strings.Contains(string(data), server.URL) {
+		t.Fatalf("delivery log should not contain the full endpoint URL: %s", data)
+	}
+}
+
+func TestWebhookSenderStopsOnClientErrors(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	logPath := filepath.Join(t.TempDir(), "webhooks.log")
+	sender := newWebhookSender(config.WebhooksConfig{Endpoints: []string{server.URL}, SecretEnv: "OBI_TEST_UNSET_SECRET"}, logPath)
+	sender.sleep = func(time.Duration) { t.Fatal("4xx responses should not be retried") }
+	record := sender.post(server.URL, []byte(`{}`))
+	if calls != 1 || record.Delivered || record.StatusCode != http.StatusForbidden || record.Signed {
+		t.Fatalf("unexpected delivery: calls=%d %+v", calls, record)
+	}
+}
+
+func TestNewWebhookSenderNilWithoutEndpoints(t *testing.T) {
+	sender := newWebhookSender(config.WebhooksConfig{Endpoints: []string{" "}}, "")
+	if sender != nil {
+		t.Fatalf("expected nil sender, got %+v", sender)
+	}
+	sender.deliver(ledgerEntry{SessionID: "abc"})
+}
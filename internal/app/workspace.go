@@ -0,0 +1,413 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/footer"
+)
+
+// workspaceRootEnv hands a parallel workspace child the worktree it runs
+// in. The child treats it as the repo root, so verification, bead sync,
+// context files and the epic's dir all resolve inside the worktree rather
+// than the checkout the config lives in.
+const workspaceRootEnv = "OBI_WORKSPACE_ROOT"
+
+// workspaceRun is one repo's part of obi go --workspace. Runs, Succeeded,
+// DurationMs, and LastStatus are read back from the repo's results log
+// once every repo has finished.
+type workspaceRun struct {
+	Repo     config.WorkspaceRepo
+	Root     string
+	LogPath  string
+	EpicID   string
+	Worktree string
+	Err      error
+
+	Runs       int
+	Succeeded  int
+	DurationMs int64
+	LastStatus string
+}
+
+// runWorkspace runs the epic loop for opts.workspace in every repo of the
+// workspace file, one after another or (with --parallel) all at once, and
+// prints one report covering them all. A repo that fails does not stop the
+// others; the command fails afterwards if any did.
+func runWorkspace(opts goOptions) error {
+	if err := validateWorkspaceOptions(opts); err != nil {
+		return err
+	}
+	path, err := config.ResolveWorkspacePath(opts.workspaceFile)
+	if err != nil {
+		return err
+	}
+	ws, err := config.LoadWorkspace(path)
+	if err != nil {
+		return err
+	}
+	// Repos run from their own roots, so --context paths are pinned to
+	// where obi was started.
+	for i, ctxPath := range opts.context {
+		if abs, err := filepath.Abs(ctxPath); err == nil {
+			opts.context[i] = abs
+		}
+	}
+
+	started := time.Now()
+	runs := make([]workspaceRun, len(ws.Repos))
+	for i, repo := range ws.Repos {
+		runs[i] = prepareWorkspaceRun(repo, opts)
+	}
+	if opts.parallel {
+		if err := runWorkspaceParallel(runs, opts, started); err != nil {
+			return err
+		}
+	} else {
+		for i := range runs {
+			if runs[i].Err != nil {
+				continue
+			}
+			fmt.Printf("=== Workspace: repo %d/%d, %s (%s) ===\n\n", i+1, len(runs), runs[i].Repo.Name, runs[i].Root)
+			runs[i].Err = runWorkspaceRepo(runs[i], opts)
+			fmt.Println()
+		}
+	}
+
+	for i := range runs {
+		collectWorkspaceRun(&runs[i], started)
+	}
+	fmt.Printf("=== Workspace %s: %s ===\n\n", opts.workspace, ws.Path)
+	fmt.Print(formatWorkspaceReport(runs))
+	failed := 0
+	for _, run := range runs {
+		if run.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return newExitError(fmt.Sprintf("%d of %d workspace repos did not finish cleanly", failed, len(runs)))
+	}
+	return nil
+}
+
+func validateWorkspaceOptions(opts goOptions) error {
+	switch {
+	case opts.aliasInput != "":
+		return fmt.Errorf("--workspace already names the epic; drop the extra argument %q", opts.aliasInput)
+	case opts.configPath != "":
+		return errors.New("--config is not supported with --workspace; each repo's config comes from workspace.toml")
+	case opts.dir != "":
+		return errors.New("--dir is not supported with --workspace")
+	case opts.outPath != "":
+		return errors.New("--out is not supported with --workspace; transcripts are kept per repo")
+	case opts.readOnly:
+		return errors.New("--read-only is not supported with --workspace")
+	case opts.wait:
+		return errors.New("--wait is not supported with --workspace")
+	case opts.continueCodex != "":
+		return errors.New("--continue-codex resumes one run and is not supported with --workspace")
+	}
+	if _, ok := groupTarget(opts.workspace); ok {
+		return errors.New("--workspace takes an epic alias, not a group")
+	}
+	return nil
+}
+
+// prepareWorkspaceRun resolves a repo's config, root, results log, and the
+// epic the alias names there. Failures are recorded on the run so the
+// report still lists the repo.
+func prepareWorkspaceRun(repo config.WorkspaceRepo, opts goOptions) workspaceRun {
+	run := workspaceRun{Repo: repo}
+	resolved, cfg, err := loadConfig(repo.Config, workspaceProfile(repo, opts))
+	if err != nil {
+		run.Err = err
+		return run
+	}
+	run.Root = repoRootForConfig(resolved)
+	if run.LogPath, err = cfg.ResultsLogPath(); err != nil {
+		run.Err = err
+		return run
+	}
+	_, epic, err := cfg.Epic(opts.workspace)
+	if err != nil {
+		run.Err = err
+		return run
+	}
+	run.EpicID = epic.ID
+	return run
+}
+
+func workspaceProfile(repo config.WorkspaceRepo, opts goOptions) string {
+	if repo.Profile != "" {
+		return repo.Profile
+	}
+	return opts.profile
+}
+
+// runWorkspaceRepo runs the epic loop in-process from the repo's root,
+// where bd looks for its database. Repos run one at a time, so changing the
+// process's directory is safe; obi returns to where it started before the
+// next repo, and stops if it cannot.
+func runWorkspaceRepo(run workspaceRun, opts goOptions) (err error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(run.Root); err != nil {
+		return err
+	}
+	defer func() {
+		if cdErr := os.Chdir(wd); cdErr != nil && err == nil {
+			err = fmt.Errorf("return to %s: %w", wd, cdErr)
+		}
+	}()
+
+	opts.aliasInput = opts.workspace
+	opts.workspace = ""
+	opts.configPath = run.Repo.Config
+	opts.profile = workspaceProfile(run.Repo, opts)
+	return runGoTarget(opts)
+}
+
+// runWorkspaceParallel gives each repo a fresh git worktree on its own
+// branch and runs obi go there as a child process, so Codex sessions in
+// different repos never share a checkout, a working directory, or a
+// terminal. Output is prefixed with the repo name. The operator confirms
+// once for all repos.
+func runWorkspaceParallel(runs []workspaceRun, opts goOptions, started time.Time) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate obi binary: %w", err)
+	}
+	stamp := started.UTC().Format("20060102-150405")
+	for i := range runs {
+		if runs[i].Err != nil {
+			continue
+		}
+		runs[i].Worktree, runs[i].Err = addWorkspaceWorktree(runs[i], opts.workspace, stamp)
+	}
+
+	fmt.Printf("Running %s in parallel:\n", opts.workspace)
+	pending := 0
+	for _, run := range runs {
+		if run.Err != nil {
+			fmt.Printf("  %s: skipped (%v)\n", run.Repo.Name, run.Err)
+			continue
+		}
+		pending++
+		fmt.Printf("  %s: %s in %s\n", run.Repo.Name, run.EpicID, run.Worktree)
+	}
+	if pending == 0 {
+		return nil
+	}
+	if !opts.yes {
+		ok, err := promptForConfirmation()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("workspace run cancelled")
+		}
+	}
+
+	var outMu sync.Mutex
+	var wg sync.WaitGroup
+	for i := range runs {
+		if runs[i].Err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(run *workspaceRun) {
+			defer wg.Done()
+			prefix := "[" + run.Repo.Name + "] "
+			stdout := &linePrefixWriter{mu: &outMu, out: os.Stdout, prefix: prefix}
+			stderr := &linePrefixWriter{mu: &outMu, out: os.Stderr, prefix: prefix}
+			cmd := exec.Command(exe, workspaceChildArgs(*run, opts)...)
+			cmd.Dir = run.Worktree
+			cmd.Env = append(os.Environ(), workspaceRootEnv+"="+run.Worktree)
+			cmd.Stdout = stdout
+			cmd.Stderr = stderr
+			run.Err = cmd.Run()
+			stdout.Flush()
+			stderr.Flush()
+		}(&runs[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+// addWorkspaceWorktree creates obi/<alias>-<stamp> from the repo's HEAD in a
+// worktree next to the results log.
+func addWorkspaceWorktree(run workspaceRun, alias, stamp string) (string, error) {
+	name := alias + "-" + stamp
+	path := filepath.Join(filepath.Dir(run.LogPath), "worktrees", run.Repo.Name, name)
+	if err := addGitWorktree(run.Root, path, "obi/"+name); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// addGitWorktree checks out a new branch from root's HEAD at path.
+func addGitWorktree(root, path, branch string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create worktree dir: %w", err)
+	}
+	cmd := exec.Command("git", "-C", root, "worktree", "add", "-b", branch, path, "HEAD")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return fmt.Errorf("git worktree add: %s: %s", err, firstLine(detail))
+		}
+		return fmt.Errorf("git worktree add: %w", err)
+	}
+	return nil
+}
+
+// workspaceChildArgs is the obi go command line for one repo of a parallel
+// run. The TUI and confirmation prompt are off since children share the
+// terminal. The worktree is passed in workspaceRootEnv rather than --dir so
+// the epic's own dir still applies, inside the worktree.
+func workspaceChildArgs(run workspaceRun, opts goOptions) []string {
+	args := []string{"go", opts.workspace, "--config", run.Repo.Config, "--no-tui", "--yes"}
+	if profile := workspaceProfile(run.Repo, opts); profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	if opts.resume {
+		args = append(args, "--resume")
+	}
+	if opts.fresh {
+		args = append(args, "--fresh")
+	}
+	if opts.ci {
+		args = append(args, "--ci")
+	}
+	if opts.skipDups {
+		args = append(args, "--skip-duplicates")
+	}
+	for _, kv := range opts.env {
+		args = append(args, "--env", kv)
+	}
+	for _, path := range opts.context {
+		args = append(args, "--context", path)
+	}
+	if note := strings.TrimSpace(opts.note); note != "" {
+		args = append(args, "--note", note)
+	}
+	return args
+}
+
+// workspaceRepoRoot returns the repo root for a run whose config resolved to
+// checkoutRoot: the worktree of a parallel workspace child, otherwise the
+// checkout itself. dir is the epic's dir, moved into the worktree when it
+// is an absolute path inside the checkout.
+func workspaceRepoRoot(checkoutRoot, dir string) (string, string) {
+	worktree := strings.TrimSpace(os.Getenv(workspaceRootEnv))
+	if worktree == "" || worktree == checkoutRoot {
+		return checkoutRoot, dir
+	}
+	if filepath.IsAbs(dir) {
+		dir = rebaseRunDir(dir, checkoutRoot, worktree)
+	}
+	return worktree, dir
+}
+
+// collectWorkspaceRun tallies the runs the repo logged since the workspace
+// started.
+func collectWorkspaceRun(run *workspaceRun, started time.Time) {
+	if run.LogPath == "" || run.EpicID == "" {
+		return
+	}
+	entries, err := ledgerEntriesForEpic(run.LogPath, run.EpicID)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.StartedAt.Before(started) || (entry.RepoRoot != "" && entry.RepoRoot != run.Root && entry.RepoRoot != run.Worktree) {
+			continue
+		}
+		run.Runs++
+		run.DurationMs += entry.DurationMs
+		run.LastStatus = entry.Status
+		if strings.EqualFold(entry.Status, footer.StatusSuccess) {
+			run.Succeeded++
+		}
+	}
+}
+
+var workspaceReportHeaders = []string{"Repo", "Epic", "Runs", "Succeeded", "Time", "Last status", "Result"}
+
+func formatWorkspaceReport(runs []workspaceRun) string {
+	rows := [][]string{workspaceReportHeaders}
+	for _, run := range runs {
+		epic, last := run.EpicID, run.LastStatus
+		if epic == "" {
+			epic = "-"
+		}
+		if last == "" {
+			last = "-"
+		}
+		result := "done"
+		if run.Err != nil {
+			result = truncate(firstLine(run.Err.Error()), 60)
+		}
+		rows = append(rows, []string{
+			run.Repo.Name,
+			epic,
+			strconv.Itoa(run.Runs),
+			strconv.Itoa(run.Succeeded),
+			roundedMillis(run.DurationMs),
+			last,
+			result,
+		})
+	}
+	return formatTextTable(rows)
+}
+
+// linePrefixWriter prefixes each complete line with prefix, writing whole
+// lines under mu so output from concurrent children never interleaves
+// mid-line.
+type linePrefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (w *linePrefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.writeLine(w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush writes a trailing partial line, if any.
+func (w *linePrefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *linePrefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, _ = io.WriteString(w.out, w.prefix)
+	_, _ = w.out.Write(line)
+}
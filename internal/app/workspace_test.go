@@ -0,0 +1,104 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+)
+
+func TestValidateWorkspaceOptionsRejectsSingleRepoFlags(t *testing.T) {
+	for _, opts := range []goOptions{
+		{workspace: "backend", aliasInput: "other"},
+		{workspace: "backend", configPath: "obi.toml"},
+		{workspace: "backend", dir: "sub"},
+		{workspace: "backend", readOnly: true},
+		{workspace: "group:backend"},
+	} {
+		if err := validateWorkspaceOptions(opts); err == nil {
+			t.Fatalf("expected %+v to be rejected", opts)
+		}
+	}
+	if err := validateWorkspaceOptions(goOptions{workspace: "backend", resume: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunGoRejectsContinueCodexWithWorkspace(t *testing.T) {
+	err := runGo([]string{"--workspace", "backend", "--continue-codex", "run-1"})
+	if err == nil || !strings.Contains(err.Error(), "--continue-codex") {
+		t.Fatalf("expected --continue-codex to be rejected, got %v", err)
+	}
+}
+
+func TestCollectWorkspaceRunCountsRunsSinceStart(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "results.log")
+	started := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, e := range []ledgerEntry{
+		{SessionID: "old", RepoRoot: "/repo/api", EpicID: "api-1", BeadID: "api-1.1", Status: "success", StartedAt: started.Add(-time.Hour), CompletedAt: started.Add(-time.Hour)},
+		{SessionID: "a", RepoRoot: "/repo/api", EpicID: "api-1", BeadID: "api-1.2", Status: "success", StartedAt: started.Add(time.Minute), CompletedAt: started.Add(3 * time.Minute)},
+		{SessionID: "b", RepoRoot: "/repo/api", EpicID: "api-1", BeadID: "api-1.3", Status: "needs_help", StartedAt: started.Add(4 * time.Minute), CompletedAt: started.Add(5 * time.Minute)},
+		{SessionID: "c", RepoRoot: "/repo/other", EpicID: "api-1", BeadID: "api-1.4", Status: "success", StartedAt: started.Add(time.Minute), CompletedAt: started.Add(2 * time.Minute)},
+	} {
+		if err := appendLedgerEntry(logPath, e); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	run := workspaceRun{Repo: config.WorkspaceRepo{Name: "api"}, Root: "/repo/api", LogPath: logPath, EpicID: "api-1", Err: newExitError("Codex requested escalation; stopping.")}
+	collectWorkspaceRun(&run, started)
+	if run.Runs != 2 || run.Succeeded != 1 || run.LastStatus != "needs_help" || run.DurationMs != 3*60*1000 {
+		t.Fatalf("unexpected tally %+v", run)
+	}
+
+	missing := workspaceRun{Repo: config.WorkspaceRepo{Name: "billing"}, Err: errors.New("unknown epic \"backend\"")}
+	report := formatWorkspaceReport([]workspaceRun{run, missing})
+	for _, want := range []string{"Repo", "api", "api-1", "needs_help", "Codex requested escalation", "billing", "unknown epic"} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestWorkspaceChildArgsPassThroughRunOptions(t *testing.T) {
+	run := workspaceRun{Repo: config.WorkspaceRepo{Name: "api", Config: "/repo/api/obi.toml", Profile: "ci"}, Worktree: "/logs/worktrees/api/backend-1"}
+	args := strings.Join(workspaceChildArgs(run, goOptions{workspace: "backend", resume: true, env: []string{"A=1"}, note: "Focus on the API"}), " ")
+	want := "go backend --config /repo/api/obi.toml --no-tui --yes --profile ci --resume --env A=1 --note Focus on the API"
+	if args != want {
+		t.Fatalf("expected %q, got %q", want, args)
+	}
+}
+
+func TestWorkspaceRepoRootMovesTheRunIntoTheWorktree(t *testing.T) {
+	t.Setenv(workspaceRootEnv, "")
+	if root, dir := workspaceRepoRoot("/repo/api", "/repo/api/svc"); root != "/repo/api" || dir != "/repo/api/svc" {
+		t.Fatalf("outside a workspace: %q, %q", root, dir)
+	}
+	t.Setenv(workspaceRootEnv, "/logs/worktrees/api/backend-1")
+	for dir, want := range map[string]string{
+		"":              "",
+		"svc":           "svc",
+		"/repo/api/svc": "/logs/worktrees/api/backend-1/svc",
+	} {
+		root, got := workspaceRepoRoot("/repo/api", dir)
+		if root != "/logs/worktrees/api/backend-1" || got != want {
+			t.Fatalf("dir %q: root %q, dir %q; want dir %q", dir, root, got, want)
+		}
+	}
+}
+
+func TestLinePrefixWriterPrefixesWholeLines(t *testing.T) {
+	var out strings.Builder
+	var mu sync.Mutex
+	w := &linePrefixWriter{mu: &mu, out: &out, prefix: "[api] "}
+	w.Write([]byte("first li"))
+	w.Write([]byte("ne\nsecond\nthi"))
+	w.Flush()
+	if got := out.String(); got != "[api] first line\n[api] second\n[api] thi\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
@@ -3,6 +3,7 @@ package codexexec
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
 )
@@ -37,11 +38,213 @@ func Build(cfg config.CodexConfig, prompt string) (Invocation, error) {
 		args = append(args, cfg.ExtraArgs...)
 	}
 
+	if err := checkForbiddenArgs(cfg.ForbiddenArgs, args[1:]); err != nil {
+		return Invocation{}, err
+	}
+
 	args = append(args, prompt)
 
 	return Invocation{Binary: bin, Args: args}, nil
 }
 
+// BuildResume is Build for a follow-up turn in an earlier Codex session:
+// codex exec [flags] resume <sessionID> <prompt>, so Codex keeps the
+// context it had when that session ended.
+func BuildResume(cfg config.CodexConfig, sessionID, prompt string) (Invocation, error) {
+	sessionID = strings.TrimSpace(sessionID)
+	if sessionID == "" || strings.HasPrefix(sessionID, "-") {
+		return Invocation{}, fmt.Errorf("invalid codex session id %q", sessionID)
+	}
+	inv, err := Build(cfg, prompt)
+	if err != nil {
+		return Invocation{}, err
+	}
+	last := len(inv.Args) - 1
+	inv.Args = append(inv.Args[:last:last], "resume", sessionID, prompt)
+	return inv, nil
+}
+
+// shortFlags maps codex's short flags to the long names rules are written with.
+var shortFlags = map[string]string{
+	"-m": "--model",
+	"-s": "--sandbox",
+	"-a": "--ask-for-approval",
+}
+
+type flagValue struct {
+	name  string
+	value string
+	set   bool
+}
+
+// configOverrideKeys maps the config keys a -c/--config override can set to
+// the flags that set the same thing, so rules written against the flags
+// also catch "-c sandbox_mode=danger-full-access".
+var configOverrideKeys = map[string]string{
+	"sandbox_mode":    "--sandbox",
+	"approval_policy": "--ask-for-approval",
+	"model":           "--model",
+}
+
+// configOverrideFlag translates a key=value override into the equivalent
+// flag and value; the value's TOML quotes are dropped.
+func configOverrideFlag(override string) (string, string, bool) {
+	key, value, ok := strings.Cut(override, "=")
+	if !ok {
+		return "", "", false
+	}
+	flag, known := configOverrideKeys[strings.TrimSpace(key)]
+	if !known {
+		return "", "", false
+	}
+	return flag, strings.Trim(strings.TrimSpace(value), `"'`), true
+}
+
+// parseFlags reads args as codex flags, accepting both "--flag value" and
+// "--flag=value". Config overrides of the sandbox, approval policy and model
+// are read as those flags. Non-flag tokens that do not follow a flag are
+// ignored.
+func parseFlags(args []string) []flagValue {
+	var flags []flagValue
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		flag := flagValue{name: arg}
+		if name, value, ok := strings.Cut(arg, "="); ok {
+			flag = flagValue{name: name, value: value, set: true}
+		} else if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			flag.value, flag.set = args[i+1], true
+			i++
+		}
+		if long, ok := shortFlags[flag.name]; ok {
+			flag.name = long
+		}
+		if configFlags[flag.name] && flag.set {
+			if long, value, ok := configOverrideFlag(flag.value); ok {
+				flag = flagValue{name: long, value: value, set: true}
+			}
+		}
+		flags = append(flags, flag)
+	}
+	return flags
+}
+
+// checkForbiddenArgs reports the first rule whose terms all appear in args.
+func checkForbiddenArgs(rules, args []string) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	present := parseFlags(args)
+	for _, rule := range rules {
+		fields := strings.Fields(rule)
+		if len(fields) == 0 {
+			return fmt.Errorf("invalid codex.forbidden_args rule %q: it is empty", rule)
+		}
+		var matched []string
+		for _, field := range fields {
+			if !strings.HasPrefix(field, "-") {
+				return fmt.Errorf("invalid codex.forbidden_args rule %q: %q is not a flag (write --flag or --flag=value)", rule, field)
+			}
+			hit, ok := findFlag(present, parseFlags([]string{field})[0])
+			if !ok {
+				matched = nil
+				break
+			}
+			matched = append(matched, hit)
+		}
+		if matched != nil {
+			return fmt.Errorf("codex invocation refused: forbidden_args rule %q matches %s; remove it from the codex settings, extra_args, or the active profile", rule, strings.Join(matched, " with "))
+		}
+	}
+	return nil
+}
+
+func findFlag(present []flagValue, term flagValue) (string, bool) {
+	for _, flag := range present {
+		if flag.name != term.name || (term.set && flag.value != term.value) {
+			continue
+		}
+		if flag.set {
+			return flag.name + " " + flag.value, true
+		}
+		return flag.name, true
+	}
+	return "", false
+}
+
+// CheckEnv refuses env entries (KEY=VALUE) that a forbidden_env rule names.
+// Values are left out of the error since they may be secrets.
+func CheckEnv(cfg config.CodexConfig, env []string) error {
+	for _, rule := range cfg.ForbiddenEnv {
+		rule = strings.TrimSpace(rule)
+		ruleKey, ruleValue, hasValue := strings.Cut(rule, "=")
+		if ruleKey == "" {
+			return fmt.Errorf("invalid codex.forbidden_env rule %q: want NAME or NAME=value", rule)
+		}
+		for _, entry := range env {
+			key, value, _ := strings.Cut(entry, "=")
+			if key != ruleKey || (hasValue && value != ruleValue) {
+				continue
+			}
+			return fmt.Errorf("codex environment refused: %s is set, which forbidden_env rule %q prohibits; drop it from the epic env or --env", key, rule)
+		}
+	}
+	return nil
+}
+
+// sandboxSwitches loosen the sandbox on their own.
+var sandboxSwitches = map[string]bool{
+	"--dangerously-bypass-approvals-and-sandbox": true,
+	"--yolo":      true,
+	"--full-auto": true,
+}
+
+// sandboxValueFlags choose or widen the sandbox with the value they take.
+var sandboxValueFlags = map[string]bool{"--sandbox": true, "-s": true, "--add-dir": true}
+
+// configFlags override a Codex config key with the key=value they take.
+var configFlags = map[string]bool{"--config": true, "-c": true}
+
+// sandboxConfigKey reports whether a -c/--config override (key=value) sets
+// one of the sandbox_* keys.
+func sandboxConfigKey(override string) bool {
+	key, _, _ := strings.Cut(override, "=")
+	return strings.HasPrefix(strings.TrimSpace(key), "sandbox")
+}
+
+// StripSandboxArgs removes the arguments that choose or widen the sandbox:
+// --sandbox/-s, --add-dir, --full-auto, the bypass switches, and -c/--config
+// overrides of sandbox_* keys. It returns the other arguments and the ones
+// it removed.
+func StripSandboxArgs(args []string) (kept, dropped []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, inline := strings.Cut(arg, "=")
+		if sandboxSwitches[name] {
+			dropped = append(dropped, arg)
+			continue
+		}
+		if !sandboxValueFlags[name] && !configFlags[name] {
+			kept = append(kept, arg)
+			continue
+		}
+		group := []string{arg}
+		if !inline && i+1 < len(args) {
+			value = args[i+1]
+			group = append(group, value)
+			i++
+		}
+		if configFlags[name] && !sandboxConfigKey(value) {
+			kept = append(kept, group...)
+		} else {
+			dropped = append(dropped, group...)
+		}
+	}
+	return kept, dropped
+}
+
 func (inv Invocation) String() string {
 	return fmt.Sprintf("%s %v", inv.Binary, inv.Args)
 }
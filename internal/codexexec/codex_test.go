@@ -1,6 +1,7 @@
 package codexexec
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
@@ -44,3 +45,81 @@ func TestBuildIncludesFlags(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildRefusesForbiddenCombination(t *testing.T) {
+	cfg := config.CodexConfig{
+		Approval:      "never",
+		ExtraArgs:     []string{"-s", "danger-full-access"},
+		ForbiddenArgs: []string{"--ask-for-approval=never --sandbox=danger-full-access"},
+	}
+	_, err := Build(cfg, "prompt")
+	if err == nil || !strings.Contains(err.Error(), "--ask-for-approval never with --sandbox danger-full-access") {
+		t.Fatalf("expected forbidden combination error, got %v", err)
+	}
+
+	cfg.ExtraArgs = []string{"--sandbox=workspace-write"}
+	if _, err := Build(cfg, "prompt"); err != nil {
+		t.Fatalf("partial match should be allowed: %v", err)
+	}
+
+	for _, extra := range [][]string{{"-c", "sandbox_mode=danger-full-access"}, {`--config=sandbox_mode="danger-full-access"`}} {
+		cfg.ExtraArgs = extra
+		if _, err := Build(cfg, "prompt"); err == nil || !strings.Contains(err.Error(), "--sandbox danger-full-access") {
+			t.Fatalf("expected config override %q to be refused, got %v", extra, err)
+		}
+	}
+}
+
+func TestBuildForbiddenFlagWithoutValue(t *testing.T) {
+	cfg := config.CodexConfig{
+		ExtraArgs:     []string{"--dangerously-bypass-approvals-and-sandbox"},
+		ForbiddenArgs: []string{"--dangerously-bypass-approvals-and-sandbox"},
+	}
+	if _, err := Build(cfg, "prompt"); err == nil {
+		t.Fatal("expected bare flag to be refused")
+	}
+	cfg.ForbiddenArgs = []string{"sandbox=danger-full-access"}
+	if _, err := Build(cfg, "prompt"); err == nil || !strings.Contains(err.Error(), "invalid codex.forbidden_args rule") {
+		t.Fatalf("expected invalid rule error, got %v", err)
+	}
+}
+
+func TestCheckEnv(t *testing.T) {
+	cfg := config.CodexConfig{ForbiddenEnv: []string{"CODEX_UNSAFE", "RUST_LOG=trace"}}
+	if err := CheckEnv(cfg, []string{"RUST_LOG=info", "FEATURE_X=on"}); err != nil {
+		t.Fatalf("unexpected refusal: %v", err)
+	}
+	err := CheckEnv(cfg, []string{"CODEX_UNSAFE=hunter2"})
+	if err == nil || !strings.Contains(err.Error(), "CODEX_UNSAFE is set") || strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("expected refusal naming the key only, got %v", err)
+	}
+	if err := CheckEnv(cfg, []string{"RUST_LOG=trace"}); err == nil {
+		t.Fatal("expected NAME=value rule to match")
+	}
+}
+
+func TestBuildResumeContinuesSession(t *testing.T) {
+	inv, err := BuildResume(config.CodexConfig{Model: "gpt-5-codex"}, "0199a213-81c0-7800-8aa1-bbab2a035a53", "prompt")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	want := "exec --model gpt-5-codex resume 0199a213-81c0-7800-8aa1-bbab2a035a53 prompt"
+	if got := strings.Join(inv.Args, " "); got != want {
+		t.Fatalf("args = %q, want %q", got, want)
+	}
+	if _, err := BuildResume(config.CodexConfig{}, " ", "prompt"); err == nil {
+		t.Fatal("expected an error for an empty session id")
+	}
+}
+
+func TestStripSandboxArgs(t *testing.T) {
+	args := []string{"--search", "--sandbox", "workspace-write", "-s=danger-full-access", "--full-auto", "-c", "model_reasoning_effort=high",
+		"--config", "sandbox_mode=danger-full-access", "--config=sandbox_workspace_write.network_access=true", "--add-dir", "/tmp", "--yolo"}
+	kept, dropped := StripSandboxArgs(args)
+	if got := strings.Join(kept, " "); got != "--search -c model_reasoning_effort=high" {
+		t.Fatalf("kept = %q", got)
+	}
+	if len(dropped) != len(args)-3 {
+		t.Fatalf("dropped = %q", dropped)
+	}
+}
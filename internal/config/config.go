@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pelletier/go-toml/v2"
 )
 
 const (
 	envConfigPath        = "OBI_CONFIG"
+	envProfile           = "OBI_PROFILE"
 	defaultConfigName    = "obi.toml"
 	DefaultSummaryPrompt = `You will receive commit summaries and detailed notes for every bead completed in this epic. Your job is to write one cohesive, multi-line commit message (subject line + detailed body) that captures the entire story so humans can understand what shipped.
 
@@ -21,6 +24,16 @@ Guidelines:
 - If information appears truncated or missing, acknowledge the limitation rather than inventing details.`
 	DefaultSummaryMaxCommits = 20
 	DefaultSummaryChunkSize  = 5
+	DefaultStallMinutes      = 5
+	DefaultBeadsSyncCommand  = "bd sync"
+	DefaultHeartbeatMinutes  = 1
+	// DefaultAliasTimeoutSeconds bounds the Codex call that names new epics.
+	DefaultAliasTimeoutSeconds = 120
+	// DefaultArtifactsMaxMB caps the files one run copies under [artifacts].
+	DefaultArtifactsMaxMB = 100
+	// EpicsFragmentName is the fragment obi refresh rewrites when the config
+	// is split across a directory; every other fragment is left alone.
+	EpicsFragmentName = "epics.toml"
 )
 
 // Config represents the root obi configuration stored in TOML.
@@ -32,6 +45,184 @@ type Config struct {
 	Issues           *IssuesConfig         `toml:"issues outside epics"`
 	ConfirmBeforeRun *bool                 `toml:"confirm_before_run"`
 	Summary          SummaryConfig         `toml:"summary"`
+	TUI              TUIConfig             `toml:"tui"`
+	Redaction        RedactionConfig       `toml:"redaction"`
+	Archive          ArchiveConfig         `toml:"archive"`
+	Notify           NotifyConfig          `toml:"notify"`
+	Beads            BeadsConfig           `toml:"beads"`
+	// Profiles are named overlays (e.g. [profiles.ci]) applied by ApplyProfile.
+	Profiles map[string]ProfileConfig `toml:"profiles"`
+	// ActiveProfile names the overlay ApplyProfile merged in, if any.
+	ActiveProfile string `toml:"-"`
+	// AuditLog overrides where operator actions are appended (default:
+	// audit.log next to the results log).
+	AuditLog string `toml:"audit_log"`
+	// StateFile overrides where live-session heartbeats are appended
+	// (default: state.log next to the results log).
+	StateFile string `toml:"state_file"`
+	// HeartbeatMinutes is how often a running session appends a heartbeat
+	// to the state file (default 1; 0 disables).
+	HeartbeatMinutes *int `toml:"heartbeat_minutes"`
+	// TranscriptMaxMB caps each session transcript; past the cap the middle
+	// of the transcript is cut (0 means unlimited).
+	TranscriptMaxMB int `toml:"transcript_max_mb"`
+	// TranscriptNameTemplate names transcripts under the transcripts
+	// directory with text/template fields such as {{.Date}}, {{.Alias}} and
+	// {{.SessionID}}; slashes create subdirectories. Empty keeps
+	// <session-id>.log.
+	TranscriptNameTemplate string `toml:"transcript_name_template"`
+	// Transcript filters what each session transcript keeps.
+	Transcript TranscriptConfig `toml:"transcript"`
+	// ConfigDir names a directory of *.toml fragments merged over this file
+	// in lexical order. Relative paths resolve against this file's directory.
+	ConfigDir string `toml:"config_dir"`
+	// Phases maps a phase name to the regexes that mark its start in Codex
+	// output, replacing the built-in patterns for that phase.
+	Phases map[string][]string `toml:"phases"`
+	// Escalation decides how approval and sandbox escalation requests
+	// detected in Codex output are answered.
+	Escalation EscalationConfig `toml:"escalation"`
+	// Style sets commit message rules for the fenced report.
+	Style StyleConfig `toml:"style"`
+	// Webhooks posts a signed copy of every finished session's ledger
+	// entry to chat-ops endpoints.
+	Webhooks WebhooksConfig `toml:"webhooks"`
+	// Prompt adds generated blocks to the session prompt.
+	Prompt PromptConfig `toml:"prompt"`
+	// RecordEnvironment stores the hostname, OS, tool versions, and git
+	// remote with each ledger entry. Off by default since the ledger may
+	// be shared.
+	RecordEnvironment bool `toml:"record_environment"`
+	// Guardrails are preflight checks obi go runs before launching Codex.
+	Guardrails GuardrailsConfig `toml:"guardrails"`
+	// Schedule limits when obi go may launch Codex.
+	Schedule ScheduleConfig `toml:"schedule"`
+	// Templates are recurring chores outside bd (e.g. [template.deps-update])
+	// run with obi run <name>.
+	Templates map[string]TemplateConfig `toml:"template"`
+	// Update opts in to the new-release check of obi --version --verbose.
+	Update UpdateConfig `toml:"update"`
+	// Artifacts are files copied out of the working tree after each run.
+	Artifacts ArtifactsConfig `toml:"artifacts"`
+}
+
+// ArtifactsConfig keeps copies of the files a session produced, so review
+// does not depend on the working tree staying as Codex left it.
+type ArtifactsConfig struct {
+	// Paths are globs relative to the directory Codex ran in, such as
+	// "coverage.out" or "build/*.log"; a matching directory is copied
+	// whole.
+	Paths []string `toml:"paths"`
+	// MaxMB caps what one run copies (default 100); files past the cap
+	// are skipped with a warning.
+	MaxMB int `toml:"max_mb"`
+}
+
+// MaxBytes returns the per-run artifact budget in bytes.
+func (a ArtifactsConfig) MaxBytes() int64 {
+	if a.MaxMB <= 0 {
+		return DefaultArtifactsMaxMB << 20
+	}
+	return int64(a.MaxMB) << 20
+}
+
+// UpdateConfig points obi --version --verbose at a release feed. Nothing is
+// fetched unless ReleaseURL is set.
+type UpdateConfig struct {
+	// ReleaseURL returns JSON naming the latest release as tag_name (the
+	// GitHub releases API shape) or version, with an optional html_url.
+	ReleaseURL string `toml:"release_url"`
+}
+
+// ScheduleConfig restricts unattended runs to approved windows, in local
+// time. Outside them obi go refuses to start, a loop stops before its next
+// session, and a running session is soft-stopped.
+type ScheduleConfig struct {
+	// AllowedHours is a daily window such as "08:00-19:00"; an end earlier
+	// than the start wraps past midnight. Empty allows any hour.
+	AllowedHours string `toml:"allowed_hours"`
+	// BlackoutDates lists days ("2026-12-24") or inclusive ranges
+	// ("2026-12-20..2027-01-02") when obi go never runs.
+	BlackoutDates []string `toml:"blackout_dates"`
+	// Jobs are the [[schedule.jobs]] entries obi schedule launches.
+	Jobs []ScheduleJob `toml:"jobs"`
+}
+
+// ScheduleJob runs an epic alias (obi go) or a template (obi run) whenever
+// its five-field cron expression matches, in local time.
+type ScheduleJob struct {
+	// Name labels the job in schedule.log; it defaults to the target.
+	Name     string `toml:"name"`
+	Cron     string `toml:"cron"`
+	Alias    string `toml:"alias"`
+	Template string `toml:"template"`
+}
+
+// TranscriptConfig trims session transcripts for archiving. The zero value
+// keeps the full redacted capture.
+type TranscriptConfig struct {
+	// Include lists the streams written: "codex" for Codex's output and
+	// "operator" for obi's notes on hints, soft stops and escalations.
+	// Empty includes both.
+	Include []string `toml:"include"`
+	// DropANSI removes escape sequences (colors, cursor movement) from
+	// Codex's output.
+	DropANSI bool `toml:"drop_ansi"`
+	// StripProgress keeps only the final version of a line Codex redrew
+	// with carriage returns, such as a spinner or progress bar. It runs
+	// before [tui] collapse_progress, which then finds no redraws to count
+	// in the transcript.
+	StripProgress bool `toml:"strip_progress"`
+}
+
+// Streams [transcript] include accepts.
+const (
+	TranscriptStreamCodex    = "codex"
+	TranscriptStreamOperator = "operator"
+)
+
+// Validate rejects unknown include streams.
+func (t TranscriptConfig) Validate() error {
+	for _, raw := range t.Include {
+		switch strings.ToLower(strings.TrimSpace(raw)) {
+		case TranscriptStreamCodex, TranscriptStreamOperator:
+		default:
+			return fmt.Errorf("transcript.include %q: want %s or %s", raw, TranscriptStreamCodex, TranscriptStreamOperator)
+		}
+	}
+	return nil
+}
+
+// GuardrailsConfig holds the opt-in launch checks.
+type GuardrailsConfig struct {
+	// RequireCleanTree aborts obi go when git status shows changes outside
+	// obi's own files and AllowDirty.
+	RequireCleanTree bool `toml:"require_clean_tree"`
+	// AllowDirty lists paths relative to the git root that may be dirty: a
+	// file, a directory (everything below it), or a glob such as "*.md".
+	AllowDirty []string `toml:"allow_dirty"`
+	// ProtectedBranches lists branch names or globs (e.g. "release/*") obi
+	// go refuses to run Codex on.
+	ProtectedBranches []string `toml:"protected_branches"`
+	// AllowedPaths and ForbiddenPaths limit where Codex may change files,
+	// using the same path forms as AllowDirty. A session that changes a
+	// forbidden path, or one outside a non-empty AllowedPaths, is
+	// soft-stopped.
+	AllowedPaths   []string `toml:"allowed_paths"`
+	ForbiddenPaths []string `toml:"forbidden_paths"`
+	// AbortCleanup is a shell command obi go offers to run from the git
+	// root after a session is aborted, e.g. "git checkout -- {{.Paths}} &&
+	// git clean -fd -- {{.Paths}}". {{.Paths}} expands to the quoted
+	// AllowedPaths, or "." when there are none. Nothing runs unless the
+	// operator confirms.
+	AbortCleanup string `toml:"abort_cleanup"`
+}
+
+// PromptConfig controls optional prompt sections obi fills in at launch.
+type PromptConfig struct {
+	// IncludeReadyList embeds the epic's beads from bd ready --json so
+	// Codex can pick one without a tool round-trip.
+	IncludeReadyList bool `toml:"include_ready_list"`
 }
 
 // EpicConfig declares how a specific domain/epic should be handled.
@@ -43,6 +234,35 @@ type EpicConfig struct {
 	Alias         string       `toml:"alias"`
 	Filters       EpicFilters  `toml:"filters"`
 	CodexOverride *CodexConfig `toml:"codex"`
+	// Dir runs Codex in this directory (relative paths resolve from the repo root).
+	Dir string `toml:"dir"`
+	// Env adds variables to the Codex process environment.
+	Env map[string]string `toml:"env"`
+	// Verify runs a command after Codex reports success.
+	Verify VerifyConfig `toml:"verify"`
+	// Group clusters related epics in obi list and lets `obi go group:<name>`
+	// run them back to back.
+	Group string `toml:"group"`
+	// ContextFiles are appended to the prompt (relative paths resolve from
+	// the repo root), each under a header and capped in size.
+	ContextFiles []string `toml:"context_files"`
+	// Escalation overrides the top-level [escalation] policy key by key.
+	Escalation *EscalationConfig `toml:"escalation"`
+	// Secrets passes variables from obi's environment to this epic's Codex
+	// only and redacts their values from transcripts and the ledger.
+	Secrets EpicSecretsConfig `toml:"secrets"`
+	// Branch is the git branch this epic's sessions run on, checked out (or
+	// created from HEAD) before launch. It is a template over Alias, EpicKey,
+	// and EpicID, e.g. "epic/{{.Alias}}".
+	Branch string `toml:"branch"`
+	// FormerAliases are the aliases `obi alias rename` replaced; they still
+	// resolve to this epic so older ledger entries stay reachable.
+	FormerAliases []string `toml:"former_aliases"`
+}
+
+// EpicSecretsConfig names the environment variables an epic may see.
+type EpicSecretsConfig struct {
+	FromEnv []string `toml:"from_env"`
 }
 
 // EpicFilters are optional bd filters that scope ready issues.
@@ -58,11 +278,225 @@ type IssuesConfig struct {
 	Filters EpicFilters `toml:"filters"`
 }
 
+// TemplateConfig is a session that needs no epic or bead: Codex follows
+// Prompt and reports as usual, but bd is never consulted.
+type TemplateConfig struct {
+	Name          string       `toml:"name"`
+	Prompt        string       `toml:"prompt"`
+	CodexOverride *CodexConfig `toml:"codex"`
+	// Dir runs Codex in this directory (relative paths resolve from the repo root).
+	Dir string `toml:"dir"`
+	// Env adds variables to the Codex process environment.
+	Env map[string]string `toml:"env"`
+	// Verify runs a command after Codex reports success.
+	Verify VerifyConfig `toml:"verify"`
+	// Every is how often the chore should run, as a day count ("7d") or a
+	// Go duration ("12h"); obi run lists templates past it as due. It is a
+	// hint only: nothing launches on its own.
+	Every string `toml:"every"`
+}
+
 // SummaryConfig controls the omnibus commit summarizer.
 type SummaryConfig struct {
 	Prompt     string `toml:"prompt"`
 	MaxCommits int    `toml:"max_commits"`
 	ChunkSize  int    `toml:"chunk_size"`
+	// Review opens a successful omnibus summary in $VISUAL or $EDITOR so the
+	// operator can reword it before it is recorded.
+	Review bool `toml:"review"`
+	// PullRequest opens a pull request once the omnibus summary succeeds.
+	PullRequest PullRequestConfig `toml:"pull_request"`
+}
+
+// PullRequestConfig is stack-up mode: a command, e.g. "gh pr create --title
+// {{.Title}} --body-file {{.BodyFile}}", run via sh -c after the summary.
+// Template fields (Title, Body, BodyFile, Branch, EpicID, EpicName, Alias)
+// are shell-quoted. An empty command disables it.
+type PullRequestConfig struct {
+	Command string `toml:"command"`
+}
+
+// TUIConfig controls the interactive shell appearance.
+type TUIConfig struct {
+	Theme            string         `toml:"theme"`
+	Timestamps       string         `toml:"timestamps"`
+	SeparatorMinutes int            `toml:"separator_minutes"`
+	StallMinutes     *int           `toml:"stall_minutes"`
+	StallNotify      bool           `toml:"stall_notify"`
+	EventBuffer      int            `toml:"event_buffer"`
+	Colors           TUIColorConfig `toml:"colors"`
+	// CollapseProgress folds rapid carriage-return rewrites of a progress
+	// line into one line with an update counter, in the pane and transcript.
+	CollapseProgress bool `toml:"collapse_progress"`
+	// WrapLines soft-wraps long log lines instead of truncating them.
+	WrapLines bool `toml:"wrap_lines"`
+	// Scrollback is how many log lines the pane keeps in memory (0 keeps
+	// the default of 5000).
+	Scrollback int `toml:"scrollback"`
+	// SoftStopReasons are offered as a numbered quick-pick when 's' is
+	// pressed; empty stops at once with the default reason.
+	SoftStopReasons []string `toml:"soft_stop_reasons"`
+	// UsdPerMTok prices tokens for the {cost} layout placeholder.
+	UsdPerMTok float64         `toml:"usd_per_mtok"`
+	Layout     TUILayoutConfig `toml:"layout"`
+	// Attention alerts the terminal when a session needs the operator:
+	// "bell", "osc9" (a desktop notification), or "both". Empty or "off"
+	// stays quiet.
+	Attention string `toml:"attention"`
+	// AttentionEvents limits the alerts to some of confirm, approval,
+	// needs_help, exit, and stall; empty means all of them.
+	AttentionEvents []string `toml:"attention_events"`
+}
+
+// TUILayoutConfig holds template strings for the TUI header lines and footer
+// legend, e.g. status = "{status} | {tokens} tok | {cost}". Empty fields keep
+// the built-in layout.
+type TUILayoutConfig struct {
+	Title   string `toml:"title"`
+	Context string `toml:"context"`
+	Status  string `toml:"status"`
+	Footer  string `toml:"footer"`
+}
+
+// VerifyConfig checks an epic's success claim independently of Codex.
+type VerifyConfig struct {
+	// Command runs via sh -c in the repo root; a non-zero exit downgrades
+	// the run to needs_help.
+	Command string `toml:"command"`
+}
+
+// Commit conventions and enforcement modes for [style].
+const (
+	CommitConventional = "conventional"
+	StyleWarn          = "warn"
+	StyleFail          = "fail"
+)
+
+// StyleConfig describes how Codex should write commit messages. The rules
+// are added to the fenced-report instructions and checked after parsing.
+type StyleConfig struct {
+	// CommitLanguage names the language for commit_msg and details.
+	CommitLanguage string `toml:"commit_language"`
+	// CommitConvention is "conventional" for Conventional Commits, or empty.
+	CommitConvention string `toml:"commit_convention"`
+	// MaxSubjectLength caps commit_msg in characters (0 means no cap).
+	MaxSubjectLength int `toml:"max_subject_length"`
+	// Enforce is "warn" (default) to report violations or "fail" to treat
+	// the run as needs_help.
+	Enforce string `toml:"enforce"`
+}
+
+// Escalation actions for requests no auto_approve pattern matches.
+const (
+	EscalationIgnore = "ignore"
+	EscalationPrompt = "prompt"
+	EscalationDeny   = "deny"
+)
+
+// EscalationConfig controls how obi answers Codex approval and sandbox
+// escalation requests.
+type EscalationConfig struct {
+	// Action applies when no auto_approve pattern matches: "prompt" asks
+	// the operator in a TUI modal, "deny" refuses, and "ignore" (default)
+	// leaves the request to Codex's own prompt.
+	Action string `toml:"action"`
+	// AutoApprove lists regexes; a requested command matching any of them
+	// is approved without asking.
+	AutoApprove []string `toml:"auto_approve"`
+	// RequestPattern replaces the built-in regex that recognizes an
+	// approval request line. A capture group, if present, is the command.
+	RequestPattern string `toml:"request_pattern"`
+}
+
+// TUIColorConfig overrides individual theme colors (names or 0-255 indexes).
+type TUIColorConfig struct {
+	Accent  string `toml:"accent"`
+	Success string `toml:"success"`
+	Warning string `toml:"warning"`
+	Failure string `toml:"failure"`
+	Dim     string `toml:"dim"`
+}
+
+// RedactionConfig controls where OBI_REDACT secrets are scrubbed.
+type RedactionConfig struct {
+	// Live also redacts the on-screen stream (useful when screen-sharing).
+	Live bool `toml:"live"`
+}
+
+// BeadsConfig controls how obi reads the bead database.
+type BeadsConfig struct {
+	// Sync runs DefaultBeadsSyncCommand before ready checks so remote-backed
+	// bead databases are evaluated fresh.
+	Sync bool `toml:"sync"`
+	// SyncCommand replaces the default sync command (run via sh -c in the
+	// repo root); setting it also enables syncing.
+	SyncCommand string `toml:"sync_command"`
+}
+
+// NotifyConfig configures where obi posts reports such as the run digest.
+type NotifyConfig struct {
+	// Webhook receives a JSON POST of the form {"text": "..."}, which Slack
+	// incoming webhooks and most chat bridges accept.
+	Webhook string `toml:"webhook"`
+}
+
+// Webhook defaults.
+const (
+	DefaultWebhookSecretEnv = "OBI_WEBHOOK_SECRET"
+	DefaultWebhookRetries   = 3
+)
+
+// WebhooksConfig lists endpoints that receive a JSON POST (the ledger entry
+// plus a transcript URL) when a session finishes.
+type WebhooksConfig struct {
+	Endpoints []string `toml:"endpoints"`
+	// SecretEnv names the environment variable holding the HMAC-SHA256
+	// signing key (default OBI_WEBHOOK_SECRET). Payloads are sent unsigned
+	// when it is empty.
+	SecretEnv string `toml:"secret_env"`
+	// Retries is how many times a failed delivery is retried with backoff
+	// (default 3).
+	Retries *int `toml:"retries"`
+	// TranscriptURL builds the transcript link from {session} and {file}
+	// (the transcript's base name); empty uses a file:// URL.
+	TranscriptURL string `toml:"transcript_url"`
+}
+
+// SecretEnvValue returns the signing-key variable name, with the default.
+func (w WebhooksConfig) SecretEnvValue() string {
+	if name := strings.TrimSpace(w.SecretEnv); name != "" {
+		return name
+	}
+	return DefaultWebhookSecretEnv
+}
+
+// RetriesValue returns the retry count, with the default.
+func (w WebhooksConfig) RetriesValue() int {
+	if w.Retries == nil {
+		return DefaultWebhookRetries
+	}
+	if *w.Retries < 0 {
+		return 0
+	}
+	return *w.Retries
+}
+
+// ProfileConfig overrides top-level settings for one environment. Unset
+// fields leave the base config untouched.
+type ProfileConfig struct {
+	Codex            *CodexConfig     `toml:"codex"`
+	ConfirmBeforeRun *bool            `toml:"confirm_before_run"`
+	Redaction        *RedactionConfig `toml:"redaction"`
+	Notify           *NotifyConfig    `toml:"notify"`
+}
+
+// ArchiveConfig holds epics that refresh no longer tracks as active.
+type ArchiveConfig struct {
+	// Ignore lists epic IDs that refresh never adds to [epic.*].
+	Ignore []string `toml:"ignore"`
+	// Epics preserves closed or ignored epics (and their prompts) so they
+	// can be restored if the epic reopens.
+	Epics map[string]EpicConfig `toml:"epic"`
 }
 
 // CodexConfig controls how codex CLI should be invoked.
@@ -72,30 +506,154 @@ type CodexConfig struct {
 	Sandbox   string   `toml:"sandbox"`
 	Approval  string   `toml:"approval"`
 	ExtraArgs []string `toml:"extra_args"`
+	// FallbackModels are tried in order when a session fails because the
+	// configured model is unavailable or over capacity.
+	FallbackModels []string `toml:"fallback_models"`
+	// ForbiddenArgs lists argument combinations Build refuses to emit. Each
+	// rule is space-separated terms such as "--sandbox=danger-full-access"
+	// or "--search"; a rule matches when every term is present.
+	ForbiddenArgs []string `toml:"forbidden_args"`
+	// ForbiddenEnv lists variables ("NAME" or "NAME=value") that configured
+	// or --env variables may not set for a Codex session.
+	ForbiddenEnv []string `toml:"forbidden_env"`
+	// AliasTimeoutSeconds bounds the Codex call obi init and refresh make to
+	// name new epics; when it expires, local aliases are used. 0 waits
+	// indefinitely.
+	AliasTimeoutSeconds *int `toml:"alias_timeout_seconds"`
 }
 
-// Load reads and parses the provided TOML file.
+// Load reads and parses the config at path. path may be a single TOML file
+// or a directory of *.toml fragments; see Sources for the merge order.
 func Load(path string) (*Config, error) {
-	bytes, err := os.ReadFile(path)
+	sources, err := Sources(path)
 	if err != nil {
-		return nil, fmt.Errorf("read config: %w", err)
+		return nil, err
 	}
 
 	var cfg Config
-	if err := toml.Unmarshal(bytes, &cfg); err != nil {
-		return nil, fmt.Errorf("parse config: %w", err)
+	if len(sources) == 1 && sources[0] == path {
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read config: %w", err)
+		}
+		if err := toml.Unmarshal(bytes, &cfg); err != nil {
+			return nil, fmt.Errorf("parse config: %w", err)
+		}
+	} else if err := loadFragments(sources, &cfg); err != nil {
+		return nil, err
 	}
 
 	if cfg.Epics == nil {
 		cfg.Epics = map[string]EpicConfig{}
 	}
-	if len(cfg.Epics) == 0 && cfg.Issues == nil {
-		return nil, errors.New("config must define at least one [epic.*] section or an \"issues outside epics\" block")
+	if len(cfg.Epics) == 0 && cfg.Issues == nil && len(cfg.Templates) == 0 {
+		return nil, errors.New("config must define at least one [epic.*] section, an \"issues outside epics\" block, or a [template.*] section")
+	}
+	if err := cfg.Transcript.Validate(); err != nil {
+		return nil, err
 	}
 
 	return &cfg, nil
 }
 
+// Sources lists the files that make up the config at path, in merge order:
+// the file itself followed by its config_dir fragments, or just the
+// fragments when path is a directory. Fragments are *.toml files sorted by
+// name, so base.toml, codex.toml, epics.toml merge in that order.
+func Sources(path string) ([]string, error) {
+	dir, ok, err := FragmentDir(path)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []string{path}, nil
+	}
+	var sources []string
+	if dir != path {
+		sources = append(sources, path)
+	}
+	fragments, err := filepath.Glob(filepath.Join(dir, "*.toml"))
+	if err != nil {
+		return nil, fmt.Errorf("list config fragments: %w", err)
+	}
+	sort.Strings(fragments)
+	return append(sources, fragments...), nil
+}
+
+// FragmentDir reports the fragment directory for the config at path: path
+// itself when it is a directory, or the file's config_dir. ok is false for a
+// plain single-file config.
+func FragmentDir(path string) (string, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false, fmt.Errorf("read config: %w", err)
+	}
+	if info.IsDir() {
+		return path, true, nil
+	}
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("read config: %w", err)
+	}
+	var head struct {
+		ConfigDir string `toml:"config_dir"`
+	}
+	if err := toml.Unmarshal(bytes, &head); err != nil {
+		return "", false, fmt.Errorf("parse config: %w", err)
+	}
+	dir := strings.TrimSpace(head.ConfigDir)
+	if dir == "" {
+		return "", false, nil
+	}
+	if dir[0] != '~' && !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(path), dir)
+	}
+	dir, err = expandPath(dir)
+	if err != nil {
+		return "", false, fmt.Errorf("resolve config_dir: %w", err)
+	}
+	return dir, true, nil
+}
+
+// loadFragments merges the sources table by table, later files winning
+// key by key, and decodes the result into cfg.
+func loadFragments(sources []string, cfg *Config) error {
+	merged := map[string]any{}
+	for _, source := range sources {
+		bytes, err := os.ReadFile(source)
+		if err != nil {
+			return fmt.Errorf("read config: %w", err)
+		}
+		var fragment map[string]any
+		if err := toml.Unmarshal(bytes, &fragment); err != nil {
+			return fmt.Errorf("parse config %s: %w", filepath.Base(source), err)
+		}
+		mergeTables(merged, fragment)
+	}
+	bytes, err := toml.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("merge config fragments: %w", err)
+	}
+	if err := toml.Unmarshal(bytes, cfg); err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+	return nil
+}
+
+// mergeTables copies src into dst, recursing into tables present in both.
+// Arrays and scalars are replaced rather than appended.
+func mergeTables(dst, src map[string]any) {
+	for key, value := range src {
+		if table, ok := value.(map[string]any); ok {
+			if existing, ok := dst[key].(map[string]any); ok {
+				mergeTables(existing, table)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
 // ResolvePath picks the config location via precedence: flag, env, default path.
 func ResolvePath(flagPath string) (string, error) {
 	if flagPath != "" {
@@ -107,6 +665,56 @@ func ResolvePath(flagPath string) (string, error) {
 	return searchLocalConfig()
 }
 
+// ResolveProfile picks the profile name via precedence: flag, then env.
+func ResolveProfile(flagProfile string) string {
+	if p := strings.TrimSpace(flagProfile); p != "" {
+		return p
+	}
+	return strings.TrimSpace(os.Getenv(envProfile))
+}
+
+// ApplyProfile merges the named [profiles.*] overlay into c. An empty name is
+// a no-op; an unknown name is an error so typos don't silently run with the
+// base settings.
+func (c *Config) ApplyProfile(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return fmt.Errorf("unknown profile %q: config defines no [profiles.*] sections", name)
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	if profile.Codex != nil {
+		c.Codex = mergeCodex(c.Codex, *profile.Codex)
+	}
+	if profile.ConfirmBeforeRun != nil {
+		val := *profile.ConfirmBeforeRun
+		c.ConfirmBeforeRun = &val
+	}
+	if profile.Redaction != nil {
+		c.Redaction = *profile.Redaction
+	}
+	if profile.Notify != nil {
+		c.Notify = *profile.Notify
+	}
+	c.ActiveProfile = name
+	return nil
+}
+
+// ProfileNames returns the configured profile names in sorted order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func expandPath(path string) (string, error) {
 	if path == "" {
 		return "", errors.New("empty path")
@@ -122,23 +730,7 @@ func expandPath(path string) (string, error) {
 }
 
 func searchLocalConfig() (string, error) {
-	wd, err := os.Getwd()
-	if err != nil {
-		return "", fmt.Errorf("resolve working dir: %w", err)
-	}
-	dir := wd
-	for {
-		candidate := filepath.Join(dir, defaultConfigName)
-		if _, err := os.Stat(candidate); err == nil {
-			return candidate, nil
-		}
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			break
-		}
-		dir = parent
-	}
-	return "", fmt.Errorf("could not find %s in current directory or parents", defaultConfigName)
+	return searchLocalFile(defaultConfigName)
 }
 
 // Epic fetches a named epic by key, alias, or epic ID.
@@ -186,6 +778,55 @@ func (c *Config) Epic(name string) (string, EpicConfig, error) {
 	return "", EpicConfig{}, fmt.Errorf("unknown epic %q", requested)
 }
 
+// GroupEpics returns the keys of epics in the named group (case-insensitive),
+// sorted.
+func (c *Config) GroupEpics(name string) []string {
+	name = strings.TrimSpace(name)
+	var keys []string
+	for key, epic := range c.Epics {
+		if name != "" && strings.EqualFold(strings.TrimSpace(epic.Group), name) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SecretNames returns the variables any epic lists in secrets.from_env,
+// sorted and deduplicated.
+func (c *Config) SecretNames() []string {
+	set := map[string]struct{}{}
+	for _, epic := range c.Epics {
+		for _, name := range epic.Secrets.FromEnv {
+			if name = strings.TrimSpace(name); name != "" {
+				set[name] = struct{}{}
+			}
+		}
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GroupNames returns the distinct epic groups in sorted order.
+func (c *Config) GroupNames() []string {
+	set := map[string]struct{}{}
+	for _, epic := range c.Epics {
+		if group := strings.ToLower(strings.TrimSpace(epic.Group)); group != "" {
+			set[group] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // ConfirmBeforeRunValue returns whether obi go should pause before executing Codex.
 func (c *Config) ConfirmBeforeRunValue() bool {
 	if c.ConfirmBeforeRun == nil {
@@ -209,6 +850,41 @@ func (c *Config) SummaryConfigValue() SummaryConfig {
 	return cfg
 }
 
+// StallThresholdValue returns how long Codex may stay silent before the TUI
+// flags a stall (default 5m; stall_minutes = 0 disables detection).
+func (c TUIConfig) StallThresholdValue() time.Duration {
+	minutes := DefaultStallMinutes
+	if c.StallMinutes != nil {
+		minutes = *c.StallMinutes
+	}
+	if minutes <= 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// SyncCommandValue returns the command to run before ready checks, or "" when
+// syncing is disabled.
+func (b BeadsConfig) SyncCommandValue() string {
+	if cmd := strings.TrimSpace(b.SyncCommand); cmd != "" {
+		return cmd
+	}
+	if b.Sync {
+		return DefaultBeadsSyncCommand
+	}
+	return ""
+}
+
+// Ignores reports whether refresh should skip the given epic ID.
+func (a ArchiveConfig) Ignores(epicID string) bool {
+	for _, id := range a.Ignore {
+		if strings.EqualFold(strings.TrimSpace(id), strings.TrimSpace(epicID)) {
+			return true
+		}
+	}
+	return false
+}
+
 // ResultsLogPath returns the configured results log location (with default).
 func (c *Config) ResultsLogPath() (string, error) {
 	if c.ResultsLog != "" {
@@ -221,6 +897,103 @@ func (c *Config) ResultsLogPath() (string, error) {
 	return filepath.Join(dir, "obi", "results.log"), nil
 }
 
+// AuditLogPath returns the operator audit log location (with default).
+func (c *Config) AuditLogPath() (string, error) {
+	if c.AuditLog != "" {
+		return expandPath(c.AuditLog)
+	}
+	logPath, err := c.ResultsLogPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(logPath), "audit.log"), nil
+}
+
+// WebhookLogPath returns where webhook delivery results are appended:
+// webhooks.log next to the results log.
+func (c *Config) WebhookLogPath() (string, error) {
+	logPath, err := c.ResultsLogPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(logPath), "webhooks.log"), nil
+}
+
+// ScheduleLogPath returns where obi schedule records started, finished, and
+// skipped jobs: schedule.log next to the results log.
+func (c *Config) ScheduleLogPath() (string, error) {
+	logPath, err := c.ResultsLogPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(logPath), "schedule.log"), nil
+}
+
+// ScheduleLockPath returns the lock obi schedule holds while a job runs:
+// schedule.lock next to the results log.
+func (c *Config) ScheduleLockPath() (string, error) {
+	logPath, err := c.ResultsLogPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(logPath), "schedule.lock"), nil
+}
+
+// QALogPath returns where obi ask records questions and answers: qa.log
+// next to the results log.
+func (c *Config) QALogPath() (string, error) {
+	logPath, err := c.ResultsLogPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(logPath), "qa.log"), nil
+}
+
+// StateFilePath returns the live-session state file location (with default).
+func (c *Config) StateFilePath() (string, error) {
+	if c.StateFile != "" {
+		return expandPath(c.StateFile)
+	}
+	logPath, err := c.ResultsLogPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(logPath), "state.log"), nil
+}
+
+// AliasTimeout returns how long alias generation may wait for Codex
+// (default 2m; 0 means no limit).
+func (c CodexConfig) AliasTimeout() time.Duration {
+	seconds := DefaultAliasTimeoutSeconds
+	if c.AliasTimeoutSeconds != nil {
+		seconds = *c.AliasTimeoutSeconds
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// HeartbeatInterval returns how often sessions record a heartbeat (0 disables).
+func (c *Config) HeartbeatInterval() time.Duration {
+	minutes := DefaultHeartbeatMinutes
+	if c.HeartbeatMinutes != nil {
+		minutes = *c.HeartbeatMinutes
+	}
+	if minutes <= 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// TranscriptMaxBytes returns the transcript cap in bytes (0 means unlimited).
+func (c *Config) TranscriptMaxBytes() int64 {
+	if c.TranscriptMaxMB <= 0 {
+		return 0
+	}
+	return int64(c.TranscriptMaxMB) << 20
+}
+
 // EffectiveCodex merges default codex config with optional epic override.
 func (c *Config) EffectiveCodex(t EpicConfig) CodexConfig {
 	if t.CodexOverride == nil {
@@ -229,6 +1002,33 @@ func (c *Config) EffectiveCodex(t EpicConfig) CodexConfig {
 	return mergeCodex(c.Codex, *t.CodexOverride)
 }
 
+// TemplateCodex merges the base codex config with a template override.
+func (c *Config) TemplateCodex(t TemplateConfig) CodexConfig {
+	if t.CodexOverride == nil {
+		return c.Codex
+	}
+	return mergeCodex(c.Codex, *t.CodexOverride)
+}
+
+// EffectiveEscalation merges the [escalation] policy with an optional epic
+// override; set fields replace their defaults.
+func (c *Config) EffectiveEscalation(t EpicConfig) EscalationConfig {
+	merged := c.Escalation
+	if t.Escalation == nil {
+		return merged
+	}
+	if t.Escalation.Action != "" {
+		merged.Action = t.Escalation.Action
+	}
+	if len(t.Escalation.AutoApprove) > 0 {
+		merged.AutoApprove = append([]string{}, t.Escalation.AutoApprove...)
+	}
+	if t.Escalation.RequestPattern != "" {
+		merged.RequestPattern = t.Escalation.RequestPattern
+	}
+	return merged
+}
+
 func mergeCodex(base, override CodexConfig) CodexConfig {
 	merged := base
 	if override.Binary != "" {
@@ -246,5 +1046,21 @@ func mergeCodex(base, override CodexConfig) CodexConfig {
 	if len(override.ExtraArgs) > 0 {
 		merged.ExtraArgs = append([]string{}, override.ExtraArgs...)
 	}
+	if len(override.FallbackModels) > 0 {
+		merged.FallbackModels = append([]string{}, override.FallbackModels...)
+	}
+	if override.AliasTimeoutSeconds != nil {
+		merged.AliasTimeoutSeconds = override.AliasTimeoutSeconds
+	}
+	// Prohibitions accumulate so a profile or epic can never lift one.
+	merged.ForbiddenArgs = appendRules(base.ForbiddenArgs, override.ForbiddenArgs)
+	merged.ForbiddenEnv = appendRules(base.ForbiddenEnv, override.ForbiddenEnv)
 	return merged
 }
+
+func appendRules(base, extra []string) []string {
+	if len(extra) == 0 {
+		return base
+	}
+	return append(append([]string{}, base...), extra...)
+}
@@ -3,7 +3,9 @@ package config_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
 )
@@ -166,6 +168,62 @@ func TestEpicLookupByKey(t *testing.T) {
 	}
 }
 
+func TestEpicSecretsParse(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "obi.toml")
+	body := sampleConfig + `
+[epic.foo.secrets]
+FROM_ENV = ["STRIPE_TEST_KEY", "STRIPE_WEBHOOK_KEY"]
+
+[epic.bar.secrets]
+from_env = ["STRIPE_TEST_KEY", "GITHUB_TOKEN"]
+`
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := strings.Join(cfg.Epics["foo"].Secrets.FromEnv, ","); got != "STRIPE_TEST_KEY,STRIPE_WEBHOOK_KEY" {
+		t.Fatalf("foo secrets = %q", got)
+	}
+	if got := strings.Join(cfg.SecretNames(), ","); got != "GITHUB_TOKEN,STRIPE_TEST_KEY,STRIPE_WEBHOOK_KEY" {
+		t.Fatalf("SecretNames = %q", got)
+	}
+}
+
+func TestLoadAcceptsTemplateOnlyConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "obi.toml")
+	body := `[codex]
+model = "gpt"
+sandbox = "workspace-write"
+
+[template.deps-update]
+prompt = "Bump dependencies"
+every = "7d"
+
+[template.deps-update.codex]
+model = "gpt-mini"
+`
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	tmpl, ok := cfg.Templates["deps-update"]
+	if !ok || tmpl.Prompt != "Bump dependencies" || tmpl.Every != "7d" {
+		t.Fatalf("template = %+v", tmpl)
+	}
+	codex := cfg.TemplateCodex(tmpl)
+	if codex.Model != "gpt-mini" || codex.Sandbox != "workspace-write" {
+		t.Fatalf("TemplateCodex = %+v", codex)
+	}
+}
+
 func TestConfirmBeforeRunValue(t *testing.T) {
 	var cfg config.Config
 	if !cfg.ConfirmBeforeRunValue() {
@@ -222,3 +280,278 @@ func TestEpicLookupByID(t *testing.T) {
 		t.Fatalf("unexpected id lookup: %s -> %+v", name, epic)
 	}
 }
+
+func TestApplyProfileOverlaysSettings(t *testing.T) {
+	aliasTimeout := 300
+	cfg := config.Config{
+		Codex:     config.CodexConfig{Model: "gpt", Approval: "on-request"},
+		Redaction: config.RedactionConfig{Live: true},
+		Notify:    config.NotifyConfig{Webhook: "https://hooks.example/base"},
+		Profiles: map[string]config.ProfileConfig{
+			"ci": {
+				Codex:            &config.CodexConfig{Approval: "never", AliasTimeoutSeconds: &aliasTimeout},
+				ConfirmBeforeRun: boolPtr(false),
+				Redaction:        &config.RedactionConfig{Live: false},
+			},
+			"local": {},
+		},
+	}
+	if err := cfg.ApplyProfile(""); err != nil || cfg.ActiveProfile != "" {
+		t.Fatalf("empty profile should be a no-op: %v", err)
+	}
+	if err := cfg.ApplyProfile("ci"); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if cfg.Codex.Model != "gpt" || cfg.Codex.Approval != "never" || cfg.Codex.AliasTimeout() != 300*time.Second {
+		t.Fatalf("expected codex overlay merged onto base, got %+v", cfg.Codex)
+	}
+	if cfg.ConfirmBeforeRunValue() || cfg.Redaction.Live {
+		t.Fatalf("expected confirm and redaction overridden, got %+v", cfg)
+	}
+	if cfg.Notify.Webhook != "https://hooks.example/base" || cfg.ActiveProfile != "ci" {
+		t.Fatalf("unset profile fields must keep base values, got %+v", cfg)
+	}
+
+	err := cfg.ApplyProfile("prod")
+	if err == nil || !strings.Contains(err.Error(), "available: ci, local") {
+		t.Fatalf("expected unknown profile error listing names, got %v", err)
+	}
+}
+
+func TestProfileCannotLiftForbiddenRules(t *testing.T) {
+	cfg := config.Config{
+		Codex: config.CodexConfig{
+			ForbiddenArgs: []string{"--sandbox=danger-full-access"},
+			ForbiddenEnv:  []string{"CODEX_UNSAFE"},
+		},
+		Profiles: map[string]config.ProfileConfig{
+			"ci": {Codex: &config.CodexConfig{Sandbox: "danger-full-access", ForbiddenArgs: []string{"--search"}}},
+		},
+	}
+	if err := cfg.ApplyProfile("ci"); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if got := strings.Join(cfg.Codex.ForbiddenArgs, ","); got != "--sandbox=danger-full-access,--search" {
+		t.Fatalf("expected profile rules appended to base rules, got %q", got)
+	}
+	if got := strings.Join(cfg.Codex.ForbiddenEnv, ","); got != "CODEX_UNSAFE" {
+		t.Fatalf("expected base env rules kept, got %q", got)
+	}
+}
+
+func TestResolveProfilePrecedence(t *testing.T) {
+	t.Setenv("OBI_PROFILE", "ci")
+	if got := config.ResolveProfile("local"); got != "local" {
+		t.Fatalf("flag should win, got %q", got)
+	}
+	if got := config.ResolveProfile(""); got != "ci" {
+		t.Fatalf("expected env fallback, got %q", got)
+	}
+}
+
+func TestBeadsSyncCommandValue(t *testing.T) {
+	cases := []struct {
+		cfg  config.BeadsConfig
+		want string
+	}{
+		{config.BeadsConfig{}, ""},
+		{config.BeadsConfig{Sync: true}, "bd sync"},
+		{config.BeadsConfig{SyncCommand: " ./scripts/pull-beads "}, "./scripts/pull-beads"},
+	}
+	for _, tc := range cases {
+		if got := tc.cfg.SyncCommandValue(); got != tc.want {
+			t.Fatalf("SyncCommandValue(%+v) = %q, want %q", tc.cfg, got, tc.want)
+		}
+	}
+}
+
+func TestLoadMergesFragmentDirectory(t *testing.T) {
+	dir := t.TempDir()
+	fragments := map[string]string{
+		"base.toml":  "results_log = \"~/base.log\"\nconfirm_before_run = true\n\n[codex]\nmodel = \"gpt\"\nsandbox = \"workspace-write\"\n",
+		"codex.toml": "confirm_before_run = false\n\n[codex]\nmodel = \"gpt-5\"\n",
+		"epics.toml": "[epic.foo]\nname = \"Foo\"\nid = \"obi-foo\"\nalias = \"foo\"\n",
+		"notes.txt":  "not toml = [",
+	}
+	for name, body := range fragments {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	sources, err := config.Sources(dir)
+	if err != nil {
+		t.Fatalf("sources: %v", err)
+	}
+	var names []string
+	for _, source := range sources {
+		names = append(names, filepath.Base(source))
+	}
+	if got := strings.Join(names, ","); got != "base.toml,codex.toml,epics.toml" {
+		t.Fatalf("unexpected merge order %s", got)
+	}
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Codex.Model != "gpt-5" || cfg.Codex.Sandbox != "workspace-write" {
+		t.Fatalf("codex tables not merged key by key: %+v", cfg.Codex)
+	}
+	if cfg.ConfirmBeforeRunValue() {
+		t.Fatalf("later fragment should win for scalars")
+	}
+	if cfg.ResultsLog != "~/base.log" {
+		t.Fatalf("results_log lost: %q", cfg.ResultsLog)
+	}
+	if _, ok := cfg.Epics["foo"]; !ok {
+		t.Fatalf("epics fragment not loaded: %+v", cfg.Epics)
+	}
+}
+
+func TestLoadMergesConfigDirOverFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "obi.toml")
+	if err := os.WriteFile(path, []byte("config_dir = \"obi.d\"\nresults_log = \"~/obi.log\"\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	fragDir := filepath.Join(dir, "obi.d")
+	if err := os.Mkdir(fragDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(fragDir, "epics.toml"), []byte("[epic.foo]\nname = \"Foo\"\nid = \"obi-foo\"\n"), 0o600); err != nil {
+		t.Fatalf("write fragment: %v", err)
+	}
+
+	fragments, ok, err := config.FragmentDir(path)
+	if err != nil || !ok || fragments != fragDir {
+		t.Fatalf("FragmentDir = %q, %v, %v", fragments, ok, err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.ResultsLog != "~/obi.log" {
+		t.Fatalf("base file settings lost: %q", cfg.ResultsLog)
+	}
+	if _, ok := cfg.Epics["foo"]; !ok {
+		t.Fatalf("config_dir fragments not merged: %+v", cfg.Epics)
+	}
+}
+
+func TestLoadRejectsUnknownTranscriptStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "obi.toml")
+	body := "[epic.api]\nid = \"api-1\"\n\n[transcript]\ninclude = [\"codex\", \"tui\"]\n"
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := config.Load(path); err == nil || !strings.Contains(err.Error(), `transcript.include "tui"`) {
+		t.Fatalf("expected an include error at load, got %v", err)
+	}
+}
+
+func TestLoadFragmentParseErrorNamesFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "codex.toml"), []byte("model = ["), 0o600); err != nil {
+		t.Fatalf("write fragment: %v", err)
+	}
+	_, err := config.Load(dir)
+	if err == nil || !strings.Contains(err.Error(), "codex.toml") {
+		t.Fatalf("expected parse error naming the fragment, got %v", err)
+	}
+}
+
+func TestLoadWorkspaceResolvesRepoConfigs(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "api")
+	if err := os.MkdirAll(api, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	wsPath := filepath.Join(root, "ws", "workspace.toml")
+	if err := os.MkdirAll(filepath.Dir(wsPath), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	data := `[[repo]]
+config = "../api"
+
+[[repo]]
+name = "billing"
+config = "/srv/billing/obi.toml"
+profile = "ci"
+`
+	if err := os.WriteFile(wsPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("write workspace: %v", err)
+	}
+
+	ws, err := config.LoadWorkspace(wsPath)
+	if err != nil {
+		t.Fatalf("load workspace: %v", err)
+	}
+	if len(ws.Repos) != 2 {
+		t.Fatalf("expected 2 repos, got %+v", ws.Repos)
+	}
+	if got := ws.Repos[0]; got.Name != "api" || got.Config != filepath.Join(api, "obi.toml") {
+		t.Fatalf("expected directory config to resolve to api/obi.toml, got %+v", got)
+	}
+	if got := ws.Repos[1]; got.Name != "billing" || got.Config != "/srv/billing/obi.toml" || got.Profile != "ci" {
+		t.Fatalf("unexpected second repo %+v", got)
+	}
+}
+
+func TestLoadWorkspaceRejectsDuplicateNames(t *testing.T) {
+	wsPath := filepath.Join(t.TempDir(), "workspace.toml")
+	data := "[[repo]]\nname = \"api\"\nconfig = \"a/obi.toml\"\n\n[[repo]]\nname = \"API\"\nconfig = \"b/obi.toml\"\n"
+	if err := os.WriteFile(wsPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("write workspace: %v", err)
+	}
+	if _, err := config.LoadWorkspace(wsPath); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Fatalf("expected duplicate name error, got %v", err)
+	}
+	if err := os.WriteFile(wsPath, []byte("# no repos\n"), 0o600); err != nil {
+		t.Fatalf("write workspace: %v", err)
+	}
+	if _, err := config.LoadWorkspace(wsPath); err == nil {
+		t.Fatalf("expected error for workspace without repos")
+	}
+}
+
+func TestLoadExperimentVariants(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("write variant: %v", err)
+		}
+		return path
+	}
+	terse := write("terse.toml", "prompt = \"Be brief.\"\n")
+	model := write("model.toml", "name = \"big-model\"\n\n[codex]\nmodel = \"gpt-big\"\n")
+	variants, err := config.LoadExperimentVariants([]string{terse, model})
+	if err != nil {
+		t.Fatalf("load variants: %v", err)
+	}
+	if variants[0].Name != "terse" || variants[0].Prompt != "Be brief." || variants[1].Name != "big-model" {
+		t.Fatalf("unexpected variants %+v", variants)
+	}
+	base := config.CodexConfig{Model: "gpt", Sandbox: "workspace-write"}
+	if got := variants[1].Codex(base); got.Model != "gpt-big" || got.Sandbox != "workspace-write" {
+		t.Fatalf("codex override not merged: %+v", got)
+	}
+	if got := variants[0].Codex(base); got.Model != "gpt" {
+		t.Fatalf("variant without [codex] changed the model: %+v", got)
+	}
+
+	for name, data := range map[string]string{
+		"empty.toml":    "name = \"empty\"\n",
+		"bad-name.toml": "name = \"a b\"\nprompt = \"x\"\n",
+	} {
+		if _, err := config.LoadExperimentVariants([]string{write(name, data)}); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+	dup := write("TERSE.toml", "prompt = \"Be briefer.\"\n")
+	if _, err := config.LoadExperimentVariants([]string{terse, dup}); err == nil || !strings.Contains(err.Error(), "used by both") {
+		t.Fatalf("expected duplicate name error, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// variantNamePattern keeps variant names usable in branch and directory
+// names.
+var variantNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ExperimentVariant is one prompt variant file for obi experiment. Prompt
+// replaces the epic's prompt and BasePrompt, when set, replaces
+// base_prompt; [codex] is merged key by key onto the epic's settings.
+type ExperimentVariant struct {
+	// Path is the file the variant was loaded from.
+	Path string `toml:"-"`
+	// Name labels the variant in reports, branches and directories; it
+	// defaults to the file name without its extension.
+	Name          string       `toml:"name"`
+	Prompt        string       `toml:"prompt"`
+	BasePrompt    string       `toml:"base_prompt"`
+	CodexOverride *CodexConfig `toml:"codex"`
+}
+
+// Codex merges the variant's [codex] table onto base.
+func (v ExperimentVariant) Codex(base CodexConfig) CodexConfig {
+	if v.CodexOverride == nil {
+		return base
+	}
+	return mergeCodex(base, *v.CodexOverride)
+}
+
+// LoadExperimentVariants reads the variant files at paths. Each must change
+// something, and names must be unique.
+func LoadExperimentVariants(paths []string) ([]ExperimentVariant, error) {
+	var variants []ExperimentVariant
+	seen := map[string]string{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read variant: %w", err)
+		}
+		var v ExperimentVariant
+		if err := toml.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("parse variant %s: %w", path, err)
+		}
+		v.Path = path
+		v.Name = strings.TrimSpace(v.Name)
+		if v.Name == "" {
+			v.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		if !variantNamePattern.MatchString(v.Name) {
+			return nil, fmt.Errorf("variant %s: name %q may only use letters, digits, '.', '-' and '_'", path, v.Name)
+		}
+		if strings.TrimSpace(v.Prompt) == "" && strings.TrimSpace(v.BasePrompt) == "" && v.CodexOverride == nil {
+			return nil, fmt.Errorf("variant %s sets none of prompt, base_prompt or [codex]", path)
+		}
+		key := strings.ToLower(v.Name)
+		if other, ok := seen[key]; ok {
+			return nil, fmt.Errorf("variant name %q is used by both %s and %s", v.Name, other, path)
+		}
+		seen[key] = path
+		variants = append(variants, v)
+	}
+	return variants, nil
+}
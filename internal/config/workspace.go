@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	envWorkspacePath     = "OBI_WORKSPACE"
+	defaultWorkspaceName = "workspace.toml"
+)
+
+// Workspace lists the repos obi go --workspace drives with one epic alias.
+type Workspace struct {
+	// Path is the workspace file the repos were loaded from.
+	Path  string          `toml:"-"`
+	Repos []WorkspaceRepo `toml:"repo"`
+}
+
+// WorkspaceRepo is one [[repo]] entry. Config may name an obi.toml or the
+// directory holding one; relative paths are resolved against the workspace
+// file. Name defaults to the config's directory name.
+type WorkspaceRepo struct {
+	Name    string `toml:"name"`
+	Config  string `toml:"config"`
+	Profile string `toml:"profile"`
+}
+
+// ResolveWorkspacePath picks the workspace file via precedence: flag, then
+// $OBI_WORKSPACE, then the nearest workspace.toml in the working directory
+// or its parents.
+func ResolveWorkspacePath(flagPath string) (string, error) {
+	if flagPath != "" {
+		return expandPath(flagPath)
+	}
+	if env := os.Getenv(envWorkspacePath); env != "" {
+		return expandPath(env)
+	}
+	return searchLocalFile(defaultWorkspaceName)
+}
+
+// LoadWorkspace reads and validates a workspace file, resolving each repo's
+// config to an absolute obi.toml path.
+func LoadWorkspace(path string) (*Workspace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read workspace: %w", err)
+	}
+	var ws Workspace
+	if err := toml.Unmarshal(data, &ws); err != nil {
+		return nil, fmt.Errorf("parse workspace: %w", err)
+	}
+	ws.Path = path
+	if len(ws.Repos) == 0 {
+		return nil, fmt.Errorf("workspace %s must list at least one [[repo]]", path)
+	}
+	base := filepath.Dir(path)
+	seen := map[string]bool{}
+	for i := range ws.Repos {
+		repo := &ws.Repos[i]
+		cfgPath := strings.TrimSpace(repo.Config)
+		if cfgPath == "" {
+			return nil, fmt.Errorf("workspace repo #%d is missing config", i+1)
+		}
+		if strings.HasPrefix(cfgPath, "~") {
+			if cfgPath, err = expandPath(cfgPath); err != nil {
+				return nil, err
+			}
+		} else if !filepath.IsAbs(cfgPath) {
+			cfgPath = filepath.Join(base, cfgPath)
+		}
+		if info, err := os.Stat(cfgPath); err == nil && info.IsDir() {
+			cfgPath = filepath.Join(cfgPath, defaultConfigName)
+		}
+		repo.Config = filepath.Clean(cfgPath)
+		repo.Name = strings.TrimSpace(repo.Name)
+		if repo.Name == "" {
+			repo.Name = filepath.Base(filepath.Dir(repo.Config))
+		}
+		key := strings.ToLower(repo.Name)
+		if seen[key] {
+			return nil, fmt.Errorf("workspace repo name %q is used more than once", repo.Name)
+		}
+		seen[key] = true
+		repo.Profile = strings.TrimSpace(repo.Profile)
+	}
+	return &ws, nil
+}
+
+// searchLocalFile walks up from the working directory looking for name.
+func searchLocalFile(name string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("resolve working dir: %w", err)
+	}
+	dir := wd
+	for {
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return "", fmt.Errorf("could not find %s in current directory or parents", name)
+}
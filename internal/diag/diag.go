@@ -0,0 +1,168 @@
+// Package diag is the logger for obi's own diagnostics: internal failures
+// and lifecycle details that explain a bug report but are not worth
+// interrupting the operator for. By default warnings and errors go to
+// stderr; obi --log-file sends everything at --log-level to a file instead,
+// which is the only way to see them while the TUI owns the screen.
+package diag
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// Environment defaults for the --log-level and --log-file flags.
+const (
+	EnvLevel = "OBI_LOG_LEVEL"
+	EnvFile  = "OBI_LOG_FILE"
+)
+
+var (
+	current atomic.Pointer[slog.Logger]
+	// toStderr records whether the current logger writes to stderr, which
+	// Divert must not forward to while the TUI owns the terminal.
+	toStderr atomic.Bool
+)
+
+func init() {
+	current.Store(New(os.Stderr, slog.LevelWarn))
+	toStderr.Store(true)
+}
+
+// Logger returns the process-wide diagnostics logger.
+func Logger() *slog.Logger {
+	return current.Load()
+}
+
+// Set replaces the process-wide logger and returns a func restoring the
+// previous one.
+func Set(logger *slog.Logger) (restore func()) {
+	return install(logger, false)
+}
+
+func install(logger *slog.Logger, stderr bool) (restore func()) {
+	prevStderr := toStderr.Swap(stderr)
+	prev := current.Swap(logger)
+	return func() {
+		current.Store(prev)
+		toStderr.Store(prevStderr)
+	}
+}
+
+// Divert hands warnings and errors to sink as "message key=value ..." until
+// restore is called, for a full-screen UI to show in place of stderr.
+// Records still reach a log file; stderr gets nothing in the meantime.
+func Divert(sink func(level slog.Level, text string)) (restore func()) {
+	var next slog.Handler
+	if !toStderr.Load() {
+		next = Logger().Handler()
+	}
+	return install(slog.New(&divertHandler{sink: sink, next: next}), false)
+}
+
+// divertHandler passes records at warn and above to sink and every record
+// next enables to next, when there is one.
+type divertHandler struct {
+	sink  func(slog.Level, string)
+	next  slog.Handler
+	attrs []slog.Attr
+}
+
+func (h *divertHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelWarn || (h.next != nil && h.next.Enabled(ctx, level))
+}
+
+func (h *divertHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelWarn {
+		var b strings.Builder
+		b.WriteString(record.Message)
+		write := func(attr slog.Attr) bool {
+			fmt.Fprintf(&b, " %s=%v", attr.Key, attr.Value)
+			return true
+		}
+		for _, attr := range h.attrs {
+			write(attr)
+		}
+		record.Attrs(write)
+		h.sink(record.Level, b.String())
+	}
+	if h.next != nil && h.next.Enabled(ctx, record.Level) {
+		return h.next.Handle(ctx, record)
+	}
+	return nil
+}
+
+func (h *divertHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := &divertHandler{sink: h.sink, attrs: append(append([]slog.Attr(nil), h.attrs...), attrs...)}
+	if h.next != nil {
+		clone.next = h.next.WithAttrs(attrs)
+	}
+	return clone
+}
+
+// WithGroup is not used by obi; the sink sees group members unqualified.
+func (h *divertHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	if h.next != nil {
+		clone.next = h.next.WithGroup(name)
+	}
+	return &clone
+}
+
+// New returns a text logger writing records at level and above to w.
+// Records to stderr omit the timestamp, which the terminal makes redundant.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	if w == os.Stderr {
+		opts.ReplaceAttr = func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		}
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// ParseLevel accepts debug, info, warn (or warning), and error.
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "", "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("log level %q: want debug, info, warn, or error", value)
+}
+
+// Configure installs the process-wide logger for level, appending to path
+// when it is set and writing to stderr otherwise. The returned func closes
+// the log file.
+func Configure(level, path string) (closeFn func() error, err error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(path) == "" {
+		install(New(os.Stderr, lvl), true)
+		return func() error { return nil }, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("log file: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("log file: %w", err)
+	}
+	Set(New(f, lvl).With("pid", os.Getpid()))
+	return f.Close, nil
+}
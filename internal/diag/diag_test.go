@@ -0,0 +1,87 @@
+package diag
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for value, want := range map[string]slog.Level{"": slog.LevelWarn, "DEBUG": slog.LevelDebug, "info": slog.LevelInfo, "warning": slog.LevelWarn, " error ": slog.LevelError} {
+		if got, err := ParseLevel(value); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("trace"); err == nil {
+		t.Fatal("expected an error for trace")
+	}
+}
+
+func TestConfigureWritesFileAtLevel(t *testing.T) {
+	defer Set(Logger())()
+	path := filepath.Join(t.TempDir(), "logs", "obi.log")
+	closeFn, err := Configure("info", path)
+	if err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	Logger().Debug("hidden")
+	Logger().Info("codex started", "binary", "codex")
+	if err := closeFn(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	text := string(data)
+	if strings.Contains(text, "hidden") || !strings.Contains(text, `msg="codex started" pid=`) || !strings.Contains(text, "binary=codex") {
+		t.Fatalf("log = %q", text)
+	}
+	if _, err := Configure("loud", ""); err == nil {
+		t.Fatal("expected an error for an unknown level")
+	}
+}
+
+func TestDivertSendsWarningsToSinkAndFile(t *testing.T) {
+	defer Set(Logger())()
+	path := filepath.Join(t.TempDir(), "obi.log")
+	closeFn, err := Configure("debug", path)
+	if err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	defer closeFn()
+
+	var got []string
+	restore := Divert(func(level slog.Level, text string) { got = append(got, level.String()+" "+text) })
+	Logger().Debug("codex started")
+	Logger().With("session", "s1").Warn("audit log write failed", "err", "disk full")
+	restore()
+	Logger().Warn("after restore")
+
+	if len(got) != 1 || got[0] != "WARN audit log write failed session=s1 err=disk full" {
+		t.Fatalf("sink got %q", got)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	for _, want := range []string{"codex started", "audit log write failed", "after restore"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("log file missing %q: %q", want, data)
+		}
+	}
+}
+
+func TestDivertKeepsStderrQuiet(t *testing.T) {
+	defer Set(Logger())()
+	if _, err := Configure("warn", ""); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	restore := Divert(func(slog.Level, string) {})
+	defer restore()
+	if h, ok := Logger().Handler().(*divertHandler); !ok || h.next != nil {
+		t.Fatalf("handler = %#v, want a divert handler with no stderr fallthrough", Logger().Handler())
+	}
+}
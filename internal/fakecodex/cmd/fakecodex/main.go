@@ -2,40 +2,46 @@ package main
 
 import (
 	"fmt"
-	"io"
 	"os"
+	"strings"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/fakecodex"
 )
 
-const envScenario = "FAKE_CODEX_SCENARIO"
+const (
+	envScenario = "FAKE_CODEX_SCENARIO"
+	// envUnavailableModels lists comma-separated --model values the fake
+	// rejects with a provider error, for exercising model fallback.
+	envUnavailableModels = "FAKE_CODEX_UNAVAILABLE_MODELS"
+)
 
 func main() {
 	name := os.Getenv(envScenario)
 	if name == "" {
 		name = "success"
 	}
-	scenario := fakecodex.Lookup(name)
-
-	prompt := readPrompt()
-	ctx := fakecodex.Context{
-		SessionID: fakecodex.ExtractSessionID(prompt),
-		Prompt:    prompt,
-	}
-	if err := scenario.Run(ctx, os.Stdout, os.Stderr); err != nil {
-		fmt.Fprintf(os.Stderr, "fakecodex: %v\n", err)
+	if model := modelArg(); modelUnavailable(model) {
+		fmt.Fprintf(os.Stderr, "ERROR: model_not_found: The model `%s` does not exist or you do not have access to it.\n", model)
 		os.Exit(1)
 	}
-	os.Exit(scenario.ExitCode)
+
+	os.Exit(fakecodex.Exec(name, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
 }
 
-func readPrompt() string {
-	if len(os.Args) > 1 {
-		return os.Args[len(os.Args)-1]
+func modelArg() string {
+	for i, arg := range os.Args[1:] {
+		if arg == "--model" && i+2 < len(os.Args) {
+			return os.Args[i+2]
+		}
 	}
-	data, err := io.ReadAll(os.Stdin)
-	if err != nil {
-		return ""
+	return ""
+}
+
+func modelUnavailable(model string) bool {
+	for _, name := range strings.Split(os.Getenv(envUnavailableModels), ",") {
+		if name = strings.TrimSpace(name); name != "" && name == model {
+			return true
+		}
 	}
-	return string(data)
+	return false
 }
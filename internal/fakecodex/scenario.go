@@ -41,6 +41,37 @@ func ExtractSessionID(prompt string) string {
 	return matches[1]
 }
 
+// Exec plays the named scenario the way the fakecodex binary does and
+// returns its exit code. The prompt is the last argument, or stdin when
+// there are no arguments.
+func Exec(name string, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	scenario := Lookup(name)
+	prompt := readPrompt(args, stdin)
+	ctx := Context{
+		SessionID: ExtractSessionID(prompt),
+		Prompt:    prompt,
+	}
+	if err := scenario.Run(ctx, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "fakecodex: %v\n", err)
+		return 1
+	}
+	return scenario.ExitCode
+}
+
+func readPrompt(args []string, stdin io.Reader) string {
+	if len(args) > 0 {
+		return args[len(args)-1]
+	}
+	if stdin == nil {
+		return ""
+	}
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
 // Run writes the scenario's scripted output to the provided streams.
 func (s Scenario) Run(ctx Context, stdout, stderr io.Writer) error {
 	if stdout == nil {
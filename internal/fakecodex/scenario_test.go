@@ -44,3 +44,17 @@ func TestLookupUnknownFallsBackToSuccess(t *testing.T) {
 		t.Fatalf("expected fallback scenario to be success, got %s", fallback.Name)
 	}
 }
+
+func TestExecReadsPromptFromLastArgument(t *testing.T) {
+	var out, errOut bytes.Buffer
+	code := Exec("needs_help", []string{"exec", "--model", "m", "work\n```obi:s-1\n"}, nil, &out, &errOut)
+	if code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+	if !strings.Contains(out.String(), "```obi:s-1\nstatus: needs_help") {
+		t.Fatalf("stdout = %q", out.String())
+	}
+	if got := Exec("success", nil, strings.NewReader("```obi:s-2\n"), &out, &errOut); got != 0 || !strings.Contains(out.String(), "```obi:s-2") {
+		t.Fatalf("stdin prompt: code %d, stdout %q", got, out.String())
+	}
+}
@@ -1,5 +1,7 @@
 package fakecodex
 
+import "sort"
+
 // Built-in deterministic scenarios referenced by FAKE_CODEX_SCENARIO.
 var Scenarios = map[string]Scenario{
 	"success": {
@@ -42,6 +44,16 @@ var Scenarios = map[string]Scenario{
 	},
 }
 
+// Names lists the built-in scenarios in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(Scenarios))
+	for name := range Scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Lookup returns a named scenario, falling back to success when unknown.
 func Lookup(name string) Scenario {
 	if scenario, ok := Scenarios[name]; ok {
@@ -0,0 +1,182 @@
+package interactive
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// DefaultCollapseWindow is how quickly a progress line must be rewritten to
+// be folded into the previous one.
+const DefaultCollapseWindow = 2 * time.Second
+
+var ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// CollapseKey reduces a progress line to the part that stays the same across
+// rewrites: escape sequences, spinner and bar glyphs, digits, and trailing
+// dots are dropped, so "⠋ Working 3s" and "⠙ Working 4s" share a key. Blank
+// lines have an empty key and never collapse.
+func CollapseKey(line string) string {
+	line = ansiEscapePattern.ReplaceAllString(line, "")
+	if strings.TrimSpace(line) == "" {
+		return ""
+	}
+	var b strings.Builder
+	pendingSpace := false
+	for _, r := range line {
+		switch {
+		case unicode.IsDigit(r) || isProgressGlyph(r):
+			continue
+		case unicode.IsSpace(r):
+			pendingSpace = b.Len() > 0
+			continue
+		}
+		if pendingSpace {
+			b.WriteByte(' ')
+			pendingSpace = false
+		}
+		b.WriteRune(r)
+	}
+	key := strings.TrimRight(b.String(), ".…%")
+	if key == "" {
+		// A bare spinner still collapses with the next bare spinner.
+		return "\x00"
+	}
+	return key
+}
+
+// CollapseMarker is appended to a line that absorbed n rewrites.
+func CollapseMarker(n int) string {
+	return fmt.Sprintf(" [×%d]", n)
+}
+
+func isProgressGlyph(r rune) bool {
+	switch {
+	case r >= 0x2800 && r <= 0x28FF: // braille spinners
+		return true
+	case r >= 0x2580 && r <= 0x259F: // block elements (progress bars)
+		return true
+	case r >= 0x25D0 && r <= 0x25D3, r >= 0x25F4 && r <= 0x25F7: // circle quadrants
+		return true
+	}
+	return strings.ContainsRune(`|/-\●•·`, r)
+}
+
+// LineCollapser is an io.Writer that folds carriage-return rewrites of the
+// same progress line into one line with an update counter. Lines ending in
+// "\n" pass straight through; a "\r"-terminated line is held until the next
+// line shows whether it was rewritten. Call Flush when the stream ends.
+type LineCollapser struct {
+	mu      sync.Mutex
+	w       io.Writer
+	window  time.Duration
+	now     func() time.Time
+	partial string
+	afterCR bool
+
+	held    bool
+	heldTxt string
+	heldKey string
+	updates int
+	heldAt  time.Time
+}
+
+// NewLineCollapser wraps w; window <= 0 uses DefaultCollapseWindow.
+func NewLineCollapser(w io.Writer, window time.Duration) *LineCollapser {
+	if window <= 0 {
+		window = DefaultCollapseWindow
+	}
+	return &LineCollapser{w: w, window: window, now: time.Now}
+}
+
+func (c *LineCollapser) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data := string(p)
+	if c.afterCR && strings.HasPrefix(data, "\n") {
+		// The previous chunk ended mid-"\r\n"; the held line was a plain line.
+		if err := c.flushHeld("\r\n"); err != nil {
+			return 0, err
+		}
+		data = data[1:]
+	}
+	c.afterCR = strings.HasSuffix(data, "\r")
+	data = c.partial + data
+	c.partial = ""
+	for {
+		i := strings.IndexAny(data, "\r\n")
+		if i < 0 {
+			c.partial = data
+			break
+		}
+		term := data[i : i+1]
+		if strings.HasPrefix(data[i:], "\r\n") {
+			term = "\r\n"
+		}
+		if err := c.line(data[:i], term); err != nil {
+			return 0, err
+		}
+		data = data[i+len(term):]
+	}
+	return len(p), nil
+}
+
+func (c *LineCollapser) line(text, term string) error {
+	now := c.now()
+	key := CollapseKey(text)
+	if c.held && key != "" && key == c.heldKey && now.Sub(c.heldAt) <= c.window {
+		c.heldTxt = text
+		c.updates++
+		c.heldAt = now
+		if term != "\r" {
+			return c.flushHeld(term)
+		}
+		return nil
+	}
+	if err := c.flushHeld("\r"); err != nil {
+		return err
+	}
+	if term == "\r" {
+		c.held, c.heldTxt, c.heldKey, c.updates, c.heldAt = true, text, key, 0, now
+		return nil
+	}
+	_, err := io.WriteString(c.w, text+term)
+	return err
+}
+
+// flushHeld writes the held line. A collapsed line always ends in a newline
+// so its counter stays visible; otherwise term is kept as received.
+func (c *LineCollapser) flushHeld(term string) error {
+	if !c.held {
+		return nil
+	}
+	c.held = false
+	text := c.heldTxt
+	if c.updates > 0 {
+		text += CollapseMarker(c.updates + 1)
+		if term == "\r" {
+			term = "\n"
+		}
+	}
+	_, err := io.WriteString(c.w, text+term)
+	return err
+}
+
+// Flush writes any held line and unterminated partial line.
+func (c *LineCollapser) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.flushHeld("\r"); err != nil {
+		return err
+	}
+	if c.partial == "" {
+		return nil
+	}
+	_, err := io.WriteString(c.w, c.partial)
+	c.partial = ""
+	return err
+}
@@ -0,0 +1,62 @@
+package interactive
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestCollapseKeyIgnoresSpinnerAndCounters(t *testing.T) {
+	if a, b := CollapseKey("⠋ Working 3s"), CollapseKey("⠙ Working 14s..."); a != b {
+		t.Fatalf("expected shared key, got %q vs %q", a, b)
+	}
+	if a, b := CollapseKey("\x1b[32m▓▓░░ 40%\x1b[0m"), CollapseKey("▓▓▓░ 75%"); a != b {
+		t.Fatalf("expected progress bars to share a key, got %q vs %q", a, b)
+	}
+	if CollapseKey("Working") == CollapseKey("Reading files") {
+		t.Fatalf("different messages must not share a key")
+	}
+	if CollapseKey("   ") != "" {
+		t.Fatalf("blank lines should have an empty key")
+	}
+}
+
+func TestLineCollapserFoldsRapidRewrites(t *testing.T) {
+	var out bytes.Buffer
+	c := NewLineCollapser(&out, time.Second)
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	writes := []string{"start\n", "⠋ Working 1s\r", "⠙ Working 2s\r", "⠹ Work", "ing 3s\r", "\n", "done"}
+	for _, w := range writes {
+		now = now.Add(100 * time.Millisecond)
+		if _, err := c.Write([]byte(w)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	want := "start\n⠹ Working 3s [×3]\r\ndone"
+	if got := out.String(); got != want {
+		t.Fatalf("unexpected output:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestLineCollapserKeepsSingleAndSlowRewrites(t *testing.T) {
+	var out bytes.Buffer
+	c := NewLineCollapser(&out, time.Second)
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.Write([]byte("Working 1s\r"))
+	now = now.Add(5 * time.Second)
+	c.Write([]byte("Working 6s\r\n"))
+	c.Write([]byte("line\n"))
+	if err := c.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if got, want := out.String(), "Working 1s\rWorking 6s\r\nline\n"; got != want {
+		t.Fatalf("unexpected output:\n got %q\nwant %q", got, want)
+	}
+}
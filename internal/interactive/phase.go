@@ -0,0 +1,149 @@
+package interactive
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Phase names a coarse stage of Codex's work inferred from its output.
+type Phase string
+
+const (
+	PhasePlanning   Phase = "planning"
+	PhaseEditing    Phase = "editing"
+	PhaseTesting    Phase = "testing"
+	PhaseCommitting Phase = "committing"
+)
+
+// PhaseRule moves the session into Phase when an output line matches Pattern.
+type PhaseRule struct {
+	Phase   Phase
+	Pattern *regexp.Regexp
+}
+
+// DefaultPhasePatterns holds the built-in heuristics for each phase, in the
+// order they are checked.
+var DefaultPhasePatterns = []struct {
+	Phase   Phase
+	Pattern string
+}{
+	{PhaseCommitting, `(?i)\bgit commit\b|\bbd close\b`},
+	{PhaseTesting, `(?i)\b(go test|go vet|npm (run )?test|yarn test|pnpm test|pytest|cargo test|make (test|check)|jest|vitest)\b`},
+	{PhaseEditing, `(?i)apply_patch|\*\*\* (begin patch|update file|add file|delete file)|\b(edited|editing|wrote|writing) [\w./-]+\.\w+`},
+	{PhasePlanning, `(?i)^\s*(plan|planning|thinking)\b|\bupdate_plan\b|\bbd (ready|show|list)\b`},
+}
+
+// DefaultPhaseRules compiles DefaultPhasePatterns.
+func DefaultPhaseRules() []PhaseRule {
+	rules := make([]PhaseRule, 0, len(DefaultPhasePatterns))
+	for _, p := range DefaultPhasePatterns {
+		rules = append(rules, PhaseRule{Phase: p.Phase, Pattern: regexp.MustCompile(p.Pattern)})
+	}
+	return rules
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// phaseTracker classifies complete output lines against its rules, emits an
+// EventPhaseChange whenever the phase changes, and accumulates the time
+// spent in each phase.
+type phaseTracker struct {
+	rules []PhaseRule
+	emit  eventEmitter
+
+	mu        sync.Mutex
+	partial   map[string]string
+	current   Phase
+	since     time.Time
+	durations map[Phase]time.Duration
+}
+
+func newPhaseTracker(rules []PhaseRule, emit eventEmitter) *phaseTracker {
+	return &phaseTracker{rules: rules, emit: emit, partial: map[string]string{}}
+}
+
+func (t *phaseTracker) observe(stream, chunk string) {
+	if t == nil || len(t.rules) == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	text := t.partial[stream] + chunk
+	for {
+		idx := strings.IndexByte(text, '\n')
+		if idx < 0 {
+			break
+		}
+		t.classifyLocked(text[:idx])
+		text = text[idx+1:]
+	}
+	t.partial[stream] = text
+}
+
+func (t *phaseTracker) classifyLocked(line string) {
+	line = strings.TrimRight(ansiEscape.ReplaceAllString(line, ""), "\r")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	for _, rule := range t.rules {
+		if rule.Pattern == nil || !rule.Pattern.MatchString(line) {
+			continue
+		}
+		if rule.Phase == t.current {
+			return
+		}
+		at := t.emit.now()
+		t.closeLocked(at)
+		t.current = rule.Phase
+		t.since = at
+		t.emit.phase(rule.Phase)
+		return
+	}
+}
+
+func (t *phaseTracker) closeLocked(at time.Time) {
+	if t.current == "" || at.Before(t.since) {
+		return
+	}
+	if t.durations == nil {
+		t.durations = map[Phase]time.Duration{}
+	}
+	t.durations[t.current] += at.Sub(t.since)
+}
+
+// currentPhase returns the phase the session is in right now.
+func (t *phaseTracker) currentPhase() Phase {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.current
+}
+
+// finish classifies any trailing partial lines, closes the current phase at
+// the given time, and returns the per-phase durations (nil when no phase
+// was ever detected).
+func (t *phaseTracker) finish(at time.Time) map[Phase]time.Duration {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for stream, text := range t.partial {
+		t.classifyLocked(text)
+		delete(t.partial, stream)
+	}
+	t.closeLocked(at)
+	t.current = ""
+	if len(t.durations) == 0 {
+		return nil
+	}
+	out := make(map[Phase]time.Duration, len(t.durations))
+	for phase, d := range t.durations {
+		out[phase] = d
+	}
+	return out
+}
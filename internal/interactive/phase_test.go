@@ -0,0 +1,63 @@
+package interactive
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPhaseTrackerEmitsChangesAndDurations(t *testing.T) {
+	clock := time.Unix(0, 0)
+	events := make(chan SessionEvent, 16)
+	emitter := eventEmitter{sink: events, now: func() time.Time { return clock }}
+	tracker := newPhaseTracker(DefaultPhaseRules(), emitter)
+
+	tracker.observe(StreamStdout, "Planning the change\nbd show obi-12\n")
+	clock = clock.Add(10 * time.Second)
+	tracker.observe(StreamStdout, "\x1b[1mapply_patch\x1b[0m <<'EOF'\n*** Update File: main.go\n")
+	clock = clock.Add(20 * time.Second)
+	tracker.observe(StreamStderr, "$ go te")
+	tracker.observe(StreamStderr, "st ./...\n")
+	clock = clock.Add(30 * time.Second)
+	tracker.observe(StreamStdout, "git commit -m 'done'\n")
+	clock = clock.Add(5 * time.Second)
+
+	durations := tracker.finish(clock)
+	close(events)
+
+	var phases []Phase
+	for evt := range events {
+		if evt.Type != EventPhaseChange {
+			t.Fatalf("unexpected event type %q", evt.Type)
+		}
+		phases = append(phases, evt.Phase)
+	}
+	want := []Phase{PhasePlanning, PhaseEditing, PhaseTesting, PhaseCommitting}
+	if len(phases) != len(want) {
+		t.Fatalf("expected phases %v, got %v", want, phases)
+	}
+	for i := range want {
+		if phases[i] != want[i] {
+			t.Fatalf("expected phases %v, got %v", want, phases)
+		}
+	}
+
+	expect := map[Phase]time.Duration{
+		PhasePlanning:   10 * time.Second,
+		PhaseEditing:    20 * time.Second,
+		PhaseTesting:    30 * time.Second,
+		PhaseCommitting: 5 * time.Second,
+	}
+	for phase, d := range expect {
+		if durations[phase] != d {
+			t.Fatalf("expected %s to last %s, got %s", phase, d, durations[phase])
+		}
+	}
+}
+
+func TestPhaseTrackerWithoutRulesReportsNothing(t *testing.T) {
+	tracker := newPhaseTracker([]PhaseRule{}, eventEmitter{now: time.Now})
+	tracker.observe(StreamStdout, "go test ./...\n")
+	if got := tracker.finish(time.Now()); got != nil {
+		t.Fatalf("expected no durations, got %v", got)
+	}
+}
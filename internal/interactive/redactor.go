@@ -1,12 +1,26 @@
 package interactive
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
-// Redactor scrubs sensitive data from transcripts/tees.
+// Redactor scrubs sensitive data from transcripts/tees. Custom
+// implementations can be passed via StartOptions.Redactor.
 type Redactor interface {
 	Redact(string) string
 }
 
+// BoundaryRedactor is a Redactor whose matches may be split across the
+// chunks of a stream. Pending reports how many trailing bytes of s could be
+// the start of a match that continues in later input; the session stream
+// holds those bytes back and redacts them together with the next chunk.
+// Redactors that do not implement it see each chunk on its own.
+type BoundaryRedactor interface {
+	Redactor
+	Pending(s string) int
+}
+
 // RedactorFunc adapts a function to the Redactor interface.
 type RedactorFunc func(string) string
 
@@ -22,7 +36,9 @@ type secretRedactor struct {
 	secrets []string
 }
 
-func newSecretRedactor(secrets []string) Redactor {
+// NewSecretRedactor replaces every occurrence of the given literal secrets
+// with [REDACTED], including occurrences split across stream chunks.
+func NewSecretRedactor(secrets []string) Redactor {
 	var clean []string
 	for _, secret := range secrets {
 		if strings.TrimSpace(secret) == "" {
@@ -33,7 +49,10 @@ func newSecretRedactor(secrets []string) Redactor {
 	if len(clean) == 0 {
 		return RedactorFunc(func(s string) string { return s })
 	}
-	return secretRedactor{secrets: append([]string(nil), clean...)}
+	// Longest first, so a secret that contains a shorter one is replaced
+	// whole rather than leaving its remainder behind.
+	sort.SliceStable(clean, func(i, j int) bool { return len(clean[i]) > len(clean[j]) })
+	return secretRedactor{secrets: clean}
 }
 
 func (s secretRedactor) Redact(input string) string {
@@ -43,3 +62,21 @@ func (s secretRedactor) Redact(input string) string {
 	}
 	return out
 }
+
+// Pending implements BoundaryRedactor: it returns the length of the longest
+// suffix of input that is a proper prefix of some secret.
+func (s secretRedactor) Pending(input string) int {
+	longest := len(s.secrets[0]) - 1
+	if longest > len(input) {
+		longest = len(input)
+	}
+	for n := longest; n > 0; n-- {
+		suffix := input[len(input)-n:]
+		for _, secret := range s.secrets {
+			if len(secret) > n && strings.HasPrefix(secret, suffix) {
+				return n
+			}
+		}
+	}
+	return 0
+}
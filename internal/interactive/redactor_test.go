@@ -6,7 +6,7 @@ import (
 )
 
 func TestSecretRedactorScrubsMultiWordAndUnicode(t *testing.T) {
-	r := newSecretRedactor([]string{"token", "multi word", "秘密"})
+	r := NewSecretRedactor([]string{"token", "multi word", "秘密"})
 	input := "token + multi word + 秘密 should vanish"
 	if got := r.Redact(input); strings.Count(got, "[REDACTED]") != 3 {
 		t.Fatalf("expected every secret to be redacted, got %q", got)
@@ -14,9 +14,25 @@ func TestSecretRedactorScrubsMultiWordAndUnicode(t *testing.T) {
 }
 
 func TestSecretRedactorHandlesOverlappingSecrets(t *testing.T) {
-	r := newSecretRedactor([]string{"abc", "abcd"})
+	r := NewSecretRedactor([]string{"abc", "abcd"})
 	got := r.Redact("abcd abc")
 	if strings.Count(got, "[REDACTED]") != 2 {
 		t.Fatalf("expected both overlapping tokens to be redacted, got %q", got)
 	}
 }
+
+func TestSecretRedactorPendingReportsSecretPrefix(t *testing.T) {
+	r := NewSecretRedactor([]string{"hunter2", "abc"}).(BoundaryRedactor)
+	cases := map[string]int{
+		"password: hun": 3,
+		"ends with ab":  2,
+		"hunter2":       0,
+		"nothing here":  0,
+		"":              0,
+	}
+	for input, want := range cases {
+		if got := r.Pending(input); got != want {
+			t.Fatalf("Pending(%q)=%d want %d", input, got, want)
+		}
+	}
+}
@@ -11,11 +11,14 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/creack/pty"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/codexexec"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
 )
 
 const (
@@ -24,16 +27,28 @@ const (
 	// HumanHintMarker precedes operator hints injected into Codex.
 	HumanHintMarker = "[[OBI:HUMAN_HINT]]"
 
-	eventBufferSize = 64
-	pipeLauncherEnv = "OBI_PIPE_LAUNCHER"
+	// DefaultEventBufferSize is how many events may queue before new ones
+	// are dropped.
+	DefaultEventBufferSize = 64
+	pipeLauncherEnv        = "OBI_PIPE_LAUNCHER"
+
+	// defaultDrainTimeout bounds how long Wait keeps reading output after the
+	// process exits (or after cancellation) before closing the tty itself.
+	defaultDrainTimeout = 2 * time.Second
 )
 
+// ErrPTYUnavailable marks preflight failures caused by a missing PTY device.
+// Start then falls back to plain pipes instead of failing.
+var ErrPTYUnavailable = errors.New("PTY unavailable")
+
 // SessionRunner launches Codex inside a PTY and surfaces lifecycle controls.
 type SessionRunner struct {
 	launcher  launcher
 	preflight func() error
 	newUUID   func() (string, error)
 	now       func() time.Time
+	eventBuf  int
+	drain     time.Duration
 }
 
 // SessionRunnerOption customizes the SessionRunner (primarily for tests).
@@ -46,6 +61,7 @@ func NewSessionRunner(opts ...SessionRunnerOption) *SessionRunner {
 		preflight: defaultPreflight,
 		newUUID:   randomSessionUUID,
 		now:       time.Now,
+		drain:     defaultDrainTimeout,
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -67,6 +83,13 @@ func WithPreflight(fn func() error) SessionRunnerOption {
 	}
 }
 
+// WithEventBufferSize sets the default event channel capacity.
+func WithEventBufferSize(n int) SessionRunnerOption {
+	return func(r *SessionRunner) {
+		r.eventBuf = n
+	}
+}
+
 // WithUUIDGenerator overrides the session UUID generator (used by tests).
 func WithUUIDGenerator(fn func() (string, error)) SessionRunnerOption {
 	return func(r *SessionRunner) {
@@ -91,9 +114,9 @@ type PreparedPrompt struct {
 	Text      string
 }
 
-// PreparePrompt appends fenced-report instructions to the provided body and
-// returns the final prompt plus a unique session ID.
-func (r *SessionRunner) PreparePrompt(body string) (PreparedPrompt, error) {
+// NewSessionID returns a fresh session ID for prompts that carry no fenced
+// report, such as obi ask questions.
+func (r *SessionRunner) NewSessionID() (string, error) {
 	if r == nil {
 		r = NewSessionRunner()
 	}
@@ -102,17 +125,33 @@ func (r *SessionRunner) PreparePrompt(body string) (PreparedPrompt, error) {
 	}
 	id, err := r.newUUID()
 	if err != nil {
-		return PreparedPrompt{}, fmt.Errorf("generate session id: %w", err)
+		return "", fmt.Errorf("generate session id: %w", err)
+	}
+	return id, nil
+}
+
+// PreparePrompt appends fenced-report instructions to the provided body and
+// returns the final prompt plus a unique session ID. Guidance lines, such as
+// commit message conventions, are added to the instructions.
+func (r *SessionRunner) PreparePrompt(body string, guidance ...string) (PreparedPrompt, error) {
+	id, err := r.NewSessionID()
+	if err != nil {
+		return PreparedPrompt{}, err
 	}
+	return PreparedPrompt{SessionID: id, Text: ComposePrompt(body, id, guidance...)}, nil
+}
+
+// ComposePrompt appends the fenced-report instructions for sessionID to body.
+// PreparePrompt calls it with a fresh ID; callers that only display a prompt
+// can pass a placeholder. Non-empty guidance lines are listed as rules for
+// the report right after its template.
+func ComposePrompt(body, sessionID string, guidance ...string) string {
 	body = strings.TrimSpace(body)
-	instructions := fencedReportInstructions(id)
-	var prompt string
+	instructions := fencedReportInstructions(sessionID, guidance)
 	if body == "" {
-		prompt = instructions
-	} else {
-		prompt = fmt.Sprintf("%s\n\n%s", body, instructions)
+		return instructions
 	}
-	return PreparedPrompt{SessionID: id, Text: prompt}, nil
+	return fmt.Sprintf("%s\n\n%s", body, instructions)
 }
 
 // StartOptions configure a Codex session launch.
@@ -122,10 +161,23 @@ type StartOptions struct {
 	Invocation codexexec.Invocation
 	Stdout     io.Writer
 	Tee        io.Writer
-	Redactor   Redactor
-	Secrets    []string
+	// Redactor scrubs the tee and recorded output; nil redacts Secrets.
+	// A BoundaryRedactor also catches matches split across output chunks.
+	Redactor Redactor
+	Secrets  []string
+	// RedactLive applies the redactor to Stdout and log events too, not just
+	// the tee and recorded output.
+	RedactLive bool
 	Dir        string
 	Env        []string
+	// Unset drops these variables from the inherited environment before Env
+	// is applied, so secrets meant for other epics stay out of this one.
+	Unset []string
+	// EventBufferSize overrides the runner's event channel capacity when > 0.
+	EventBufferSize int
+	// PhaseRules classifies output lines into phases; nil uses
+	// DefaultPhaseRules and an empty slice disables phase detection.
+	PhaseRules []PhaseRule
 }
 
 // SessionHandle exposes lifecycle controls plus result waiting.
@@ -142,6 +194,53 @@ func (h *SessionHandle) Events() <-chan SessionEvent {
 	return h.exec.events
 }
 
+// DroppedEvents reports how many events of each type have been discarded so
+// far because the Events consumer fell behind.
+func (h *SessionHandle) DroppedEvents() map[SessionEventType]int {
+	if h == nil || h.exec == nil {
+		return nil
+	}
+	return h.exec.emitter.drops.snapshot()
+}
+
+// Degraded explains why Codex runs on pipes instead of a PTY, or returns ""
+// when it has one.
+func (h *SessionHandle) Degraded() string {
+	if h == nil || h.exec == nil {
+		return ""
+	}
+	return h.exec.degraded
+}
+
+// Aborted reports whether Abort interrupted the session.
+func (h *SessionHandle) Aborted() bool {
+	if h == nil || h.exec == nil {
+		return false
+	}
+	return h.exec.aborted.Load()
+}
+
+// Progress is a point-in-time snapshot of a running session.
+type Progress struct {
+	StartedAt time.Time
+	// BytesStreamed counts raw output bytes read from Codex so far.
+	BytesStreamed int64
+	// Phase is the most recently detected phase ("" before the first).
+	Phase Phase
+}
+
+// Progress reports how far the session has got, for heartbeats and monitors.
+func (h *SessionHandle) Progress() Progress {
+	if h == nil || h.exec == nil {
+		return Progress{}
+	}
+	return Progress{
+		StartedAt:     h.exec.startedAt,
+		BytesStreamed: h.exec.stream.bytesWritten(),
+		Phase:         h.exec.phases.currentPhase(),
+	}
+}
+
 // SoftStop injects a marker instructing Codex to wrap up gracefully.
 func (h *SessionHandle) SoftStop(reason string) error {
 	if h == nil || h.exec == nil {
@@ -191,6 +290,18 @@ type Result struct {
 	ExitCode    int
 	StartedAt   time.Time
 	CompletedAt time.Time
+	// DroppedEvents counts events discarded because the consumer fell
+	// behind; nil when nothing was dropped.
+	DroppedEvents map[SessionEventType]int
+	// PhaseDurations records time spent in each detected phase; nil when no
+	// phase was detected.
+	PhaseDurations map[Phase]time.Duration
+	// Degraded explains why Codex ran on pipes instead of a PTY; empty for
+	// a normal session.
+	Degraded string
+	// Signal is the signal that killed Codex, or 0 when it exited on its
+	// own; ExitCode is -1 in that case.
+	Signal syscall.Signal
 }
 
 // SessionEventType categorizes events surfaced by the SessionRunner.
@@ -203,6 +314,8 @@ const (
 	EventStateChange SessionEventType = "state_change"
 	// EventExit indicates Codex exited; ExitCode/Error are populated.
 	EventExit SessionEventType = "exit"
+	// EventPhaseChange indicates the Phase field changed.
+	EventPhaseChange SessionEventType = "phase_change"
 )
 
 // SessionState enumerates high-level lifecycle phases.
@@ -215,11 +328,21 @@ const (
 	StateExited   SessionState = "exited"
 )
 
+const (
+	// StreamStdout tags chunks read from the PTY (stderr included when the
+	// launcher merges both streams into the terminal).
+	StreamStdout = "stdout"
+	// StreamStderr tags chunks read from a dedicated stderr pipe.
+	StreamStderr = "stderr"
+)
+
 // SessionEvent is emitted for PTY output, lifecycle changes, and exit.
 type SessionEvent struct {
 	Time     time.Time
 	Type     SessionEventType
 	State    SessionState
+	Phase    Phase
+	Stream   string
 	Chunk    string
 	ExitCode int
 	Error    error
@@ -251,9 +374,21 @@ func (r *SessionRunner) Start(ctx context.Context, opts StartOptions) (*SessionH
 	if runner.now == nil {
 		runner.now = time.Now
 	}
+	if runner.drain <= 0 {
+		runner.drain = defaultDrainTimeout
+	}
 
+	launcher := runner.launcher
+	var degraded string
 	if err := runner.preflight(); err != nil {
-		return nil, err
+		if _, isPTY := launcher.(realLauncher); !isPTY || !errors.Is(err, ErrPTYUnavailable) {
+			return nil, err
+		}
+		// Containers often lack /dev/ptmx; Codex still runs on pipes, though
+		// it may behave differently without a TTY.
+		launcher = pipeLauncher{}
+		degraded = fmt.Sprintf("%v; running Codex on pipes without a TTY", err)
+		diag.Logger().Info("PTY unavailable; falling back to the pipe launcher", "session", opts.SessionID, "err", err)
 	}
 
 	stdout := opts.Stdout
@@ -263,35 +398,59 @@ func (r *SessionRunner) Start(ctx context.Context, opts StartOptions) (*SessionH
 
 	redactor := opts.Redactor
 	if redactor == nil {
-		redactor = newSecretRedactor(opts.Secrets)
+		redactor = NewSecretRedactor(opts.Secrets)
 	}
 
-	events := make(chan SessionEvent, eventBufferSize)
-	emitter := eventEmitter{sink: events, now: runner.now}
+	bufferSize := DefaultEventBufferSize
+	if runner.eventBuf > 0 {
+		bufferSize = runner.eventBuf
+	}
+	if opts.EventBufferSize > 0 {
+		bufferSize = opts.EventBufferSize
+	}
+	events := make(chan SessionEvent, bufferSize)
+	emitter := eventEmitter{sink: events, now: runner.now, drops: &dropCounter{}}
 	emitter.state(StateStarting)
 
 	startedAt := runner.now()
-	handle, err := runner.launcher.Launch(ctx, opts.Invocation, opts.Dir, opts.Env)
+	handle, err := launcher.Launch(ctx, opts.Invocation, opts.Dir, commandEnv(opts.Env, opts.Unset))
 	if err != nil {
 		close(events)
+		diag.Logger().Warn("codex launch failed", "session", opts.SessionID, "binary", opts.Invocation.Binary, "err", err)
 		return nil, err
 	}
+	diag.Logger().Debug("codex started", "session", opts.SessionID, "binary", opts.Invocation.Binary, "dir", opts.Dir, "pipe_launcher", usePipeLauncher() || degraded != "", "event_buffer", bufferSize)
 
 	emitter.state(StateRunning)
 
+	rules := opts.PhaseRules
+	if rules == nil {
+		rules = DefaultPhaseRules()
+	}
+	phases := newPhaseTracker(rules, emitter)
+
 	live := &eventLogWriter{
 		target: stdout,
 		emit:   emitter,
+		stream: StreamStdout,
+		phases: phases,
+	}
+	liveErr := &eventLogWriter{
+		target: stdout,
+		emit:   emitter,
+		stream: StreamStderr,
+		phases: phases,
 	}
 
 	stream := newStreamWriter(live, opts.Tee, redactor)
+	stream.redactLive = opts.RedactLive
 	streamDone := make(chan error, 1)
 	go func() {
-		_, copyErr := io.Copy(stream, handle.tty)
-		streamDone <- copyErr
+		streamDone <- copyStreams(stream, liveErr, handle)
 	}()
 
 	exec := &sessionExecution{
+		ctx:        ctx,
 		runner:     runner,
 		sessionID:  opts.SessionID,
 		prompt:     opts.Prompt,
@@ -301,13 +460,16 @@ func (r *SessionRunner) Start(ctx context.Context, opts StartOptions) (*SessionH
 		streamDone: streamDone,
 		events:     events,
 		emitter:    emitter,
+		phases:     phases,
 		startedAt:  startedAt,
+		degraded:   degraded,
 	}
 	exec.startWait()
 	return &SessionHandle{exec: exec}, nil
 }
 
 type sessionExecution struct {
+	ctx        context.Context
 	runner     *SessionRunner
 	sessionID  string
 	prompt     string
@@ -317,7 +479,9 @@ type sessionExecution struct {
 	streamDone <-chan error
 	events     chan SessionEvent
 	emitter    eventEmitter
+	phases     *phaseTracker
 	startedAt  time.Time
+	degraded   string
 
 	waitOnce   sync.Once
 	resultOnce sync.Once
@@ -328,40 +492,79 @@ type sessionExecution struct {
 	softStopMu     sync.Mutex
 	softStopIssued bool
 	abortOnce      sync.Once
+	aborted        atomic.Bool
 	inputMu        sync.Mutex
 }
 
-// startWait begins monitoring the Codex process and PTY stream.
+// startWait begins monitoring the Codex process and PTY stream. Cancelling
+// the Start context kills the process, closes the tty so stream copying
+// unblocks, and finishes with an error wrapping ctx.Err().
 func (s *sessionExecution) startWait() {
 	s.outcome = make(chan struct{})
+	handle := s.handle
 	go func() {
 		defer close(s.outcome)
 
-		waitErr := s.handle.wait()
-		streamErr := <-s.streamDone
-		_ = s.handle.tty.Close()
+		waitDone := make(chan error, 1)
+		go func() {
+			waitDone <- handle.wait()
+		}()
+
+		var waitErr, ctxErr error
+		select {
+		case waitErr = <-waitDone:
+		case <-s.ctx.Done():
+			ctxErr = s.ctx.Err()
+			diag.Logger().Debug("codex session canceled; killing it", "session", s.sessionID, "err", ctxErr)
+			s.emitter.state(StateStopping)
+			if handle.kill != nil {
+				_ = handle.kill()
+			}
+			closeHandleStreams(handle)
+			select {
+			case waitErr = <-waitDone:
+			case <-time.After(s.runner.drain):
+			}
+		}
+
+		streamErr, forced := s.drainStream(handle, ctxErr != nil)
+		if forced && ctxErr == nil {
+			diag.Logger().Warn("codex output did not reach EOF; closed it after the drain timeout", "session", s.sessionID, "drain", s.runner.drain)
+		}
+		_ = handle.tty.Close()
 
 		output := s.stream.Redacted()
 		completed := s.runner.now()
 
 		res := Result{
-			SessionID:   s.sessionID,
-			Prompt:      s.prompt,
-			Invocation:  s.invocation,
-			Output:      output,
-			StartedAt:   s.startedAt,
-			CompletedAt: completed,
+			SessionID:      s.sessionID,
+			Prompt:         s.prompt,
+			Invocation:     s.invocation,
+			Output:         output,
+			StartedAt:      s.startedAt,
+			CompletedAt:    completed,
+			PhaseDurations: s.phases.finish(completed),
+			Degraded:       s.degraded,
+		}
+
+		if code, ok := exitCodeFrom(waitErr); ok {
+			res.ExitCode = code
 		}
+		res.Signal = signalFrom(waitErr)
+		diag.Logger().Debug("codex exited", "session", s.sessionID, "exit_code", res.ExitCode, "elapsed", completed.Sub(s.startedAt).Round(time.Millisecond), "dropped_events", s.emitter.drops.snapshot())
 
-		if streamErr != nil && !errors.Is(streamErr, io.EOF) && !errors.Is(streamErr, os.ErrClosed) {
+		if ctxErr != nil {
+			s.finish(res, fmt.Errorf("codex session canceled: %w", ctxErr))
+			return
+		}
+
+		if streamErr != nil && !forced && !errors.Is(streamErr, io.EOF) && !errors.Is(streamErr, os.ErrClosed) {
 			s.finish(res, fmt.Errorf("stream codex output: %w", streamErr))
 			return
 		}
 
 		if waitErr != nil {
-			if code, ok := exitCodeFrom(waitErr); ok {
-				res.ExitCode = code
-			} else {
+			if _, ok := exitCodeFrom(waitErr); !ok {
 				s.finish(res, fmt.Errorf("codex run failed: %w", waitErr))
 				return
 			}
@@ -371,6 +574,32 @@ func (s *sessionExecution) startWait() {
 	}()
 }
 
+// drainStream waits for output copying to finish. If the tty never reaches
+// EOF (e.g. a grandchild still holds it), the tty is closed after the drain
+// timeout so Wait cannot block forever; forced reports that case, in which
+// the copy error is an artifact of the close.
+func (s *sessionExecution) drainStream(handle *processHandle, alreadyClosed bool) (error, bool) {
+	select {
+	case err := <-s.streamDone:
+		return err, alreadyClosed
+	case <-time.After(s.runner.drain):
+	}
+	closeHandleStreams(handle)
+	select {
+	case err := <-s.streamDone:
+		return err, true
+	case <-time.After(s.runner.drain):
+		return errors.New("output stream did not close"), false
+	}
+}
+
+func closeHandleStreams(handle *processHandle) {
+	_ = handle.tty.Close()
+	if closer, ok := handle.stderr.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
 func (s *sessionExecution) finish(res Result, runErr error) {
 	s.resultOnce.Do(func() {
 		s.result = res
@@ -388,6 +617,7 @@ func (s *sessionExecution) finish(res Result, runErr error) {
 		s.emitter.state(StateExited)
 	}
 	s.emitter.exit(s.result.ExitCode, evtErr)
+	s.result.DroppedEvents = s.emitter.drops.snapshot()
 	close(s.events)
 }
 
@@ -430,6 +660,7 @@ func (s *sessionExecution) abort() error {
 			abortErr = errors.New("no signal handler available")
 		}
 		if abortErr == nil {
+			s.aborted.Store(true)
 			s.emitter.state(StateStopping)
 		}
 	})
@@ -466,8 +697,9 @@ func (s *sessionExecution) submitHint(text string) error {
 }
 
 type eventEmitter struct {
-	sink chan<- SessionEvent
-	now  func() time.Time
+	sink  chan<- SessionEvent
+	now   func() time.Time
+	drops *dropCounter
 }
 
 func (e eventEmitter) send(evt SessionEvent) {
@@ -477,7 +709,42 @@ func (e eventEmitter) send(evt SessionEvent) {
 	select {
 	case e.sink <- evt:
 	default:
+		e.drops.add(evt.Type)
+	}
+}
+
+// dropCounter tallies events discarded when the sink is full.
+type dropCounter struct {
+	mu     sync.Mutex
+	counts map[SessionEventType]int
+}
+
+func (d *dropCounter) add(t SessionEventType) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.counts == nil {
+		d.counts = map[SessionEventType]int{}
+	}
+	d.counts[t]++
+}
+
+func (d *dropCounter) snapshot() map[SessionEventType]int {
+	if d == nil {
+		return nil
 	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.counts) == 0 {
+		return nil
+	}
+	out := make(map[SessionEventType]int, len(d.counts))
+	for t, n := range d.counts {
+		out[t] = n
+	}
+	return out
 }
 
 func (e eventEmitter) state(state SessionState) {
@@ -488,11 +755,20 @@ func (e eventEmitter) state(state SessionState) {
 	})
 }
 
-func (e eventEmitter) log(chunk string) {
+func (e eventEmitter) log(stream, chunk string) {
+	e.send(SessionEvent{
+		Time:   e.now(),
+		Type:   EventLogChunk,
+		Stream: stream,
+		Chunk:  chunk,
+	})
+}
+
+func (e eventEmitter) phase(phase Phase) {
 	e.send(SessionEvent{
 		Time:  e.now(),
-		Type:  EventLogChunk,
-		Chunk: chunk,
+		Type:  EventPhaseChange,
+		Phase: phase,
 	})
 }
 
@@ -508,6 +784,8 @@ func (e eventEmitter) exit(code int, err error) {
 type eventLogWriter struct {
 	target io.Writer
 	emit   eventEmitter
+	stream string
+	phases *phaseTracker
 }
 
 func (w *eventLogWriter) Write(p []byte) (int, error) {
@@ -519,52 +797,166 @@ func (w *eventLogWriter) Write(p []byte) (int, error) {
 			return 0, err
 		}
 	}
-	w.emit.log(string(p))
+	w.emit.log(w.stream, string(p))
+	w.phases.observe(w.stream, string(p))
 	return len(p), nil
 }
 
+// copyStreams drains the PTY (and the separate stderr pipe, when the launcher
+// provides one) into the shared stream writer.
+func copyStreams(stream *streamWriter, stderrLive io.Writer, handle *processHandle) error {
+	if handle.stderr == nil {
+		_, err := io.Copy(stream, handle.tty)
+		return firstErr(err, stream.flush())
+	}
+	stderrDone := make(chan error, 1)
+	stderrStream := stream.withLive(stderrLive)
+	go func() {
+		_, err := io.Copy(stderrStream, handle.stderr)
+		stderrDone <- err
+	}()
+	_, stdoutErr := io.Copy(stream, handle.tty)
+	stderrErr := <-stderrDone
+	return firstErr(stdoutErr, stderrErr, stream.flush())
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type streamWriter struct {
-	live     io.Writer
-	tee      io.Writer
-	redactor Redactor
-	builder  strings.Builder
+	mu         sync.Mutex
+	main       *streamSource
+	sources    []*streamSource
+	tee        io.Writer
+	redactor   Redactor
+	redactLive bool
+	builder    strings.Builder
+	written    int64
+}
+
+// streamSource is one input feeding a streamWriter. Each source keeps its own
+// carry-over so a secret split across two of its chunks is still redacted,
+// without mixing bytes from interleaved stdout and stderr.
+type streamSource struct {
+	live  io.Writer
+	carry string
 }
 
 func newStreamWriter(live io.Writer, tee io.Writer, redactor Redactor) *streamWriter {
 	if redactor == nil {
 		redactor = RedactorFunc(func(s string) string { return s })
 	}
+	main := &streamSource{live: live}
 	return &streamWriter{
-		live:     live,
+		main:     main,
+		sources:  []*streamSource{main},
 		tee:      tee,
 		redactor: redactor,
 	}
 }
 
 func (w *streamWriter) Write(p []byte) (int, error) {
+	return w.write(w.main, p)
+}
+
+// withLive returns a writer sharing redaction, tee, and the recorded output
+// with w while mirroring raw bytes to a different live target.
+func (w *streamWriter) withLive(live io.Writer) io.Writer {
+	src := &streamSource{live: live}
+	w.mu.Lock()
+	w.sources = append(w.sources, src)
+	w.mu.Unlock()
+	return writerFunc(func(p []byte) (int, error) {
+		return w.write(src, p)
+	})
+}
+
+func (w *streamWriter) write(src *streamSource, p []byte) (int, error) {
 	if len(p) == 0 {
 		return 0, nil
 	}
-	if w.live != nil {
-		if _, err := w.live.Write(p); err != nil {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written += int64(len(p))
+	if src.live != nil && !w.redactLive {
+		if _, err := src.live.Write(p); err != nil {
 			return 0, err
 		}
 	}
-	chunk := string(p)
+	chunk := src.carry + string(p)
+	src.carry = ""
+	if boundary, ok := w.redactor.(BoundaryRedactor); ok {
+		if hold := boundary.Pending(chunk); hold > 0 {
+			src.carry = chunk[len(chunk)-hold:]
+			chunk = chunk[:len(chunk)-hold]
+		}
+	}
+	if err := w.emit(src, chunk); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// emit redacts chunk and writes it to the recorded output and tee, and to
+// the live target when live output is redacted too.
+func (w *streamWriter) emit(src *streamSource, chunk string) error {
+	if chunk == "" {
+		return nil
+	}
 	redacted := w.redactor.Redact(chunk)
+	if src.live != nil && w.redactLive {
+		if _, err := io.WriteString(src.live, redacted); err != nil {
+			return err
+		}
+	}
 	w.builder.WriteString(redacted)
 	if w.tee != nil {
 		if _, err := io.WriteString(w.tee, redacted); err != nil {
-			return 0, err
+			return err
 		}
 	}
-	return len(p), nil
+	return nil
+}
+
+// flush emits bytes held back as a possible secret prefix once the streams
+// have ended.
+func (w *streamWriter) flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, src := range w.sources {
+		chunk := src.carry
+		src.carry = ""
+		if err := w.emit(src, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *streamWriter) bytesWritten() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written
 }
 
 func (w *streamWriter) Redacted() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 	return w.builder.String()
 }
 
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
 func formatSoftStopMessage(sessionID string, reason string) string {
 	var sb strings.Builder
 	sb.WriteString("\n\n")
@@ -615,20 +1007,55 @@ func randomSessionUUID() (string, error) {
 	), nil
 }
 
-func fencedReportInstructions(sessionID string) string {
+func fencedReportInstructions(sessionID string, guidance []string) string {
+	var rules string
+	for _, line := range guidance {
+		if line = strings.TrimSpace(line); line != "" {
+			rules += "- " + line + "\n"
+		}
+	}
+	if rules != "" {
+		rules = "Follow these rules in the report:\n" + rules + "\n"
+	}
 	return fmt.Sprintf(
-		"When you finish the bead, emit a fenced report Obi can parse:\n\n```obi:%s\nstatus: success|needs_help\ncommit_msg: <single-line imperative summary>\ndetails: |\n  <multi-line explanation of everything you changed>\nescalation: <reason>  # required when status=needs_help\n```\n\nIf you receive a line containing %s, finish your current action and emit the fenced report immediately.\n\nAfter the fenced report, also output the legacy footer so older tooling continues to work:\nSTATUS: success|needs_help\nCOMMIT_MSG:\n<same multi-line summary as above>\nESCALATION: <reason>  # only if status=needs_help",
+		"When you finish the bead, emit a fenced report Obi can parse:\n\n```obi:%s\nstatus: success|needs_help\ncommit_msg: <single-line imperative summary>\ndetails: |\n  <multi-line explanation of everything you changed>\nescalation: <reason>  # required when status=needs_help\n```\n\n%sIf you receive a line containing %s, finish your current action and emit the fenced report immediately.\n\nAfter the fenced report, also output the legacy footer so older tooling continues to work:\nSTATUS: success|needs_help\nCOMMIT_MSG:\n<same multi-line summary as above>\nESCALATION: <reason>  # only if status=needs_help",
 		sessionID,
+		rules,
 		SoftStopMarker,
 	)
 }
 
+// commandEnv returns the full environment for Codex: obi's own without the
+// unset names, plus env. It is nil, meaning inherit unchanged, when there is
+// nothing to add or drop.
+func commandEnv(env, unset []string) []string {
+	if len(env) == 0 && len(unset) == 0 {
+		return nil
+	}
+	drop := make(map[string]struct{}, len(unset))
+	for _, name := range unset {
+		drop[name] = struct{}{}
+	}
+	var merged []string
+	for _, entry := range os.Environ() {
+		key, _, _ := strings.Cut(entry, "=")
+		if _, ok := drop[key]; !ok {
+			merged = append(merged, entry)
+		}
+	}
+	return append(merged, env...)
+}
+
+// launcher starts Codex; a nil env inherits obi's environment, otherwise env
+// is the complete environment.
 type launcher interface {
 	Launch(ctx context.Context, inv codexexec.Invocation, dir string, env []string) (*processHandle, error)
 }
 
 type processHandle struct {
-	tty    io.ReadWriteCloser
+	tty io.ReadWriteCloser
+	// stderr is set when the launcher keeps stderr separate from tty.
+	stderr io.Reader
 	wait   func() error
 	kill   func() error
 	signal func(os.Signal) error
@@ -639,8 +1066,8 @@ type realLauncher struct{}
 func (realLauncher) Launch(ctx context.Context, inv codexexec.Invocation, dir string, env []string) (*processHandle, error) {
 	cmd := exec.CommandContext(ctx, inv.Binary, inv.Args...)
 	cmd.Dir = dir
-	if len(env) > 0 {
-		cmd.Env = append(os.Environ(), env...)
+	if env != nil {
+		cmd.Env = env
 	}
 
 	tty, err := pty.Start(cmd)
@@ -673,20 +1100,27 @@ func defaultPreflight() error {
 		return nil
 	}
 	if runtime.GOOS == "windows" {
-		return errors.New("interactive mode requires a Unix-style PTY; Windows is not supported")
+		return fmt.Errorf("%w: Windows has no Unix-style PTY", ErrPTYUnavailable)
 	}
 	if runtime.GOOS == "linux" || runtime.GOOS == "android" {
 		if err := requireDevice("/dev/ptmx"); err != nil {
 			return err
 		}
 	}
+	// The device can exist while /dev/pts is not mounted, so try one.
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		return fmt.Errorf("%w: open PTY: %v", ErrPTYUnavailable, err)
+	}
+	tty.Close()
+	ptmx.Close()
 	return nil
 }
 
 func requireDevice(path string) error {
 	if _, err := os.Stat(path); err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("interactive mode requires %s (PTY unavailable)", path)
+			return fmt.Errorf("%w: %s does not exist", ErrPTYUnavailable, path)
 		}
 		return fmt.Errorf("check %s: %w", path, err)
 	}
@@ -704,49 +1138,50 @@ func (pipeLauncher) Launch(ctx context.Context, inv codexexec.Invocation, dir st
 	if dir != "" {
 		cmd.Dir = dir
 	}
-	if len(env) > 0 {
-		cmd.Env = append(os.Environ(), env...)
+	if env != nil {
+		cmd.Env = env
 	}
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return nil, err
 	}
-	stdout, err := cmd.StdoutPipe()
+	// Plain OS pipes rather than StdoutPipe: cmd.Wait then returns as soon
+	// as Codex exits, even while a grandchild still holds the write ends.
+	// Draining what is left is bounded by the session's drain timeout,
+	// which closes the read ends.
+	stdout, stdoutW, err := os.Pipe()
 	if err != nil {
+		stdin.Close()
 		return nil, err
 	}
-	stderr, err := cmd.StderrPipe()
+	stderr, stderrW, err := os.Pipe()
 	if err != nil {
+		stdin.Close()
+		stdout.Close()
+		stdoutW.Close()
 		return nil, err
 	}
+	cmd.Stdout = stdoutW
+	cmd.Stderr = stderrW
 
-	reader, writer := io.Pipe()
-	var wg sync.WaitGroup
-	wg.Add(2)
-	go func() {
-		defer wg.Done()
-		_, _ = io.Copy(writer, stdout)
-	}()
-	go func() {
-		defer wg.Done()
-		_, _ = io.Copy(writer, stderr)
-	}()
-	go func() {
-		wg.Wait()
-		writer.Close()
-	}()
-
-	if err := cmd.Start(); err != nil {
+	err = cmd.Start()
+	// The child has its own copies of the write ends; keeping ours open
+	// would stop the readers from ever seeing EOF.
+	stdoutW.Close()
+	stderrW.Close()
+	if err != nil {
+		stdin.Close()
+		stdout.Close()
+		stderr.Close()
 		return nil, err
 	}
 
-	tty := &pipeTTY{r: reader, w: stdin}
+	tty := &pipeTTY{r: stdout, w: stdin}
 	return &processHandle{
-		tty: tty,
-		wait: func() error {
-			return cmd.Wait()
-		},
+		tty:    tty,
+		stderr: stderr,
+		wait:   cmd.Wait,
 		kill: func() error {
 			if cmd.Process == nil {
 				return nil
@@ -789,6 +1224,17 @@ func (p *pipeTTY) Close() error {
 	return errW
 }
 
+// signalFrom returns the signal that ended the process waited on, if any.
+func signalFrom(err error) syscall.Signal {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return status.Signal()
+		}
+	}
+	return 0
+}
+
 func exitCodeFrom(err error) (int, bool) {
 	var coder exitCoder
 	if errors.As(err, &coder) {
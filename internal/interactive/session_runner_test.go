@@ -3,10 +3,14 @@ package interactive
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/codexexec"
 )
@@ -33,6 +37,16 @@ func TestPreparePromptAddsFenceAndLegacyFooter(t *testing.T) {
 	}
 }
 
+func TestComposePromptListsReportGuidance(t *testing.T) {
+	text := ComposePrompt("body", "session-1", "Write commit_msg and details in German.", " ")
+	if !strings.Contains(text, "Follow these rules in the report:\n- Write commit_msg and details in German.\n\n") {
+		t.Fatalf("prompt missing guidance: %s", text)
+	}
+	if strings.Contains(ComposePrompt("body", "session-1"), "Follow these rules") {
+		t.Fatalf("expected no rules header without guidance")
+	}
+}
+
 func TestSessionRunnerStreamsOutputAndRedactsSecrets(t *testing.T) {
 	fake := &fakeLauncher{
 		script: "booting\nsuper-secret token\nSTATUS: success\nCOMMIT_MSG:\ndone\n",
@@ -180,9 +194,15 @@ func TestSessionRunnerAbortUsesSignal(t *testing.T) {
 	if err != nil {
 		t.Fatalf("start: %v", err)
 	}
+	if handle.Aborted() {
+		t.Fatal("Aborted before Abort")
+	}
 	if err := handle.Abort(); err != nil {
 		t.Fatalf("abort: %v", err)
 	}
+	if !handle.Aborted() {
+		t.Fatal("Aborted = false after Abort")
+	}
 	if len(fake.lastSignals) == 0 || fake.lastSignals[0] != os.Interrupt {
 		t.Fatalf("expected SIGINT, got %v", fake.lastSignals)
 	}
@@ -219,6 +239,194 @@ func TestSessionHandleWriteInputForwardsToTTY(t *testing.T) {
 	_, _ = handle.Wait()
 }
 
+func TestSessionRunnerCountsDroppedEvents(t *testing.T) {
+	fake := &fakeLauncher{script: "line one\nline two\n"}
+	runner := NewSessionRunner(
+		WithLauncher(fake),
+		WithPreflight(func() error { return nil }),
+		WithEventBufferSize(32),
+	)
+	handle, err := runner.Start(context.Background(), StartOptions{
+		SessionID:       "session-drops",
+		Prompt:          "body",
+		Invocation:      codexexec.Invocation{Binary: "codex"},
+		Stdout:          io.Discard,
+		EventBufferSize: 1,
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	result, err := handle.Wait()
+	if err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if result.DroppedEvents[EventLogChunk] == 0 || result.DroppedEvents[EventStateChange] == 0 {
+		t.Fatalf("expected log and state drops with a 1-slot buffer, got %v", result.DroppedEvents)
+	}
+	if got := handle.DroppedEvents(); got[EventLogChunk] != result.DroppedEvents[EventLogChunk] {
+		t.Fatalf("handle drops %v should match result %v", got, result.DroppedEvents)
+	}
+}
+
+func TestSessionRunnerReportsNoDropsWhenDrained(t *testing.T) {
+	fake := &fakeLauncher{script: "only line\n"}
+	runner := NewSessionRunner(WithLauncher(fake), WithPreflight(func() error { return nil }))
+	handle, err := runner.Start(context.Background(), StartOptions{
+		SessionID:  "session-nodrops",
+		Prompt:     "body",
+		Invocation: codexexec.Invocation{Binary: "codex"},
+		Stdout:     io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	result, err := handle.Wait()
+	if err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if result.DroppedEvents != nil {
+		t.Fatalf("expected no drops with the default buffer, got %v", result.DroppedEvents)
+	}
+}
+
+func TestSessionRunnerCancelUnblocksWait(t *testing.T) {
+	fake := &hangingLauncher{}
+	runner := NewSessionRunner(WithLauncher(fake), WithPreflight(func() error { return nil }))
+	runner.drain = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handle, err := runner.Start(ctx, StartOptions{
+		SessionID:  "session-cancel",
+		Prompt:     "body",
+		Invocation: codexexec.Invocation{Binary: "codex"},
+		Stdout:     io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := handle.Wait()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Wait did not return after cancellation")
+	}
+	if !fake.killed() {
+		t.Fatalf("expected cancellation to kill the process")
+	}
+}
+
+func TestCommandEnvDropsUnsetAndAppendsEnv(t *testing.T) {
+	if env := commandEnv(nil, nil); env != nil {
+		t.Fatalf("expected nil env to inherit, got %d entries", len(env))
+	}
+	t.Setenv("OBI_TEST_OTHER_SECRET", "hidden")
+	t.Setenv("OBI_TEST_KEEP", "kept")
+	env := commandEnv([]string{"OBI_TEST_EXTRA=1"}, []string{"OBI_TEST_OTHER_SECRET"})
+	joined := "\n" + strings.Join(env, "\n") + "\n"
+	if strings.Contains(joined, "OBI_TEST_OTHER_SECRET=") {
+		t.Fatalf("unset variable leaked: %v", env)
+	}
+	if !strings.Contains(joined, "\nOBI_TEST_KEEP=kept\n") || !strings.HasSuffix(joined, "\nOBI_TEST_EXTRA=1\n") {
+		t.Fatalf("expected inherited and extra variables, got %v", env)
+	}
+}
+
+func TestSessionRunnerWaitReturnsWhenTTYNeverEOFs(t *testing.T) {
+	fake := &hangingLauncher{exitImmediately: true}
+	runner := NewSessionRunner(WithLauncher(fake), WithPreflight(func() error { return nil }))
+	runner.drain = 50 * time.Millisecond
+
+	handle, err := runner.Start(context.Background(), StartOptions{
+		SessionID:  "session-stuck",
+		Prompt:     "body",
+		Invocation: codexexec.Invocation{Binary: "codex"},
+		Stdout:     io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := handle.Wait()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected clean exit once the tty is force-closed, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Wait blocked on a tty that never reached EOF")
+	}
+}
+
+// hangingLauncher simulates a process whose tty never EOFs on its own and,
+// unless exitImmediately is set, never exits until killed.
+type hangingLauncher struct {
+	exitImmediately bool
+	mu              sync.Mutex
+	wasKilled       bool
+}
+
+func (h *hangingLauncher) killed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.wasKilled
+}
+
+func (h *hangingLauncher) Launch(context.Context, codexexec.Invocation, string, []string) (*processHandle, error) {
+	tty := &blockingTTY{closed: make(chan struct{})}
+	exited := make(chan struct{})
+	var once sync.Once
+	exit := func() { once.Do(func() { close(exited) }) }
+	if h.exitImmediately {
+		exit()
+	}
+	return &processHandle{
+		tty: tty,
+		wait: func() error {
+			<-exited
+			return exitError{code: 0}
+		},
+		kill: func() error {
+			h.mu.Lock()
+			h.wasKilled = true
+			h.mu.Unlock()
+			exit()
+			return nil
+		},
+	}, nil
+}
+
+type blockingTTY struct {
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (b *blockingTTY) Read([]byte) (int, error) {
+	<-b.closed
+	return 0, os.ErrClosed
+}
+
+func (b *blockingTTY) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (b *blockingTTY) Close() error {
+	b.once.Do(func() { close(b.closed) })
+	return nil
+}
+
 type fakeLauncher struct {
 	script      string
 	waitErr     error
@@ -304,3 +512,71 @@ func (e exitError) Error() string {
 func (e exitError) ExitCode() int {
 	return e.code
 }
+
+func TestSessionRunnerFallsBackToPipesWithoutPTY(t *testing.T) {
+	runner := NewSessionRunner(
+		WithLauncher(realLauncher{}),
+		WithPreflight(func() error { return fmt.Errorf("%w: /dev/ptmx does not exist", ErrPTYUnavailable) }),
+	)
+	handle, err := runner.Start(context.Background(), StartOptions{
+		SessionID:  "session-pipes",
+		Prompt:     "body",
+		Invocation: codexexec.Invocation{Binary: "sh", Args: []string{"-c", "test -t 1 || echo no tty"}},
+		Stdout:     io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if !strings.Contains(handle.Degraded(), "/dev/ptmx does not exist") {
+		t.Fatalf("Degraded() = %q", handle.Degraded())
+	}
+	result, err := handle.Wait()
+	if err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if !strings.Contains(result.Output, "no tty") || result.Degraded != handle.Degraded() {
+		t.Fatalf("result = %+v", result)
+	}
+}
+
+func TestPipeLauncherWaitReturnsWhileGrandchildHoldsOutput(t *testing.T) {
+	runner := NewSessionRunner(WithLauncher(pipeLauncher{}), WithPreflight(func() error { return nil }))
+	runner.drain = 50 * time.Millisecond
+
+	handle, err := runner.Start(context.Background(), StartOptions{
+		SessionID:  "session-grandchild",
+		Prompt:     "body",
+		Invocation: codexexec.Invocation{Binary: "sh", Args: []string{"-c", "sleep 30 & echo started"}},
+		Stdout:     io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	done := make(chan Result, 1)
+	go func() {
+		result, err := handle.Wait()
+		if err != nil {
+			t.Errorf("wait: %v", err)
+		}
+		done <- result
+	}()
+	select {
+	case result := <-done:
+		if !strings.Contains(result.Output, "started") {
+			t.Fatalf("output = %q", result.Output)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait blocked while a grandchild kept stdout open")
+	}
+}
+
+func TestSessionRunnerKeepsOtherPreflightErrors(t *testing.T) {
+	runner := NewSessionRunner(
+		WithLauncher(&fakeLauncher{}),
+		WithPreflight(func() error { return fmt.Errorf("%w: no device", ErrPTYUnavailable) }),
+	)
+	_, err := runner.Start(context.Background(), StartOptions{SessionID: "s", Prompt: "body", Invocation: codexexec.Invocation{Binary: "codex"}})
+	if !errors.Is(err, ErrPTYUnavailable) {
+		t.Fatalf("expected an injected launcher to keep the preflight error, got %v", err)
+	}
+}
@@ -9,7 +9,7 @@ import (
 func TestStreamWriterRedactsTeeButNotLive(t *testing.T) {
 	var live bytes.Buffer
 	var tee bytes.Buffer
-	writer := newStreamWriter(&live, &tee, newSecretRedactor([]string{"SECRET"}))
+	writer := newStreamWriter(&live, &tee, NewSecretRedactor([]string{"SECRET"}))
 
 	data := []byte("hello SECRET world")
 	if _, err := writer.Write(data); err != nil {
@@ -26,3 +26,60 @@ func TestStreamWriterRedactsTeeButNotLive(t *testing.T) {
 		t.Fatalf("recorded buffer should be redacted, got %q", got)
 	}
 }
+
+func TestStreamWriterRedactsLiveWhenRequested(t *testing.T) {
+	var live bytes.Buffer
+	var tee bytes.Buffer
+	writer := newStreamWriter(&live, &tee, NewSecretRedactor([]string{"SECRET"}))
+	writer.redactLive = true
+
+	if _, err := writer.Write([]byte("hello SECRET world")); err != nil {
+		t.Fatalf("write stream: %v", err)
+	}
+	if strings.Contains(live.String(), "SECRET") {
+		t.Fatalf("live output should be redacted, got %q", live.String())
+	}
+	if !strings.Contains(live.String(), "[REDACTED]") {
+		t.Fatalf("live output should show placeholder, got %q", live.String())
+	}
+}
+
+func TestStreamWriterRedactsSecretSplitAcrossChunks(t *testing.T) {
+	var live bytes.Buffer
+	var tee bytes.Buffer
+	writer := newStreamWriter(&live, &tee, NewSecretRedactor([]string{"SECRET"}))
+
+	for _, chunk := range []string{"token=SE", "CR", "ET done, trailing SEC"} {
+		if _, err := writer.Write([]byte(chunk)); err != nil {
+			t.Fatalf("write stream: %v", err)
+		}
+	}
+	if strings.Contains(tee.String(), "SEC") {
+		t.Fatalf("tee should hold back the possible secret prefix, got %q", tee.String())
+	}
+	if live.String() != "token=SECRET done, trailing SEC" {
+		t.Fatalf("live output should be raw and immediate, got %q", live.String())
+	}
+	if err := writer.flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if want := "token=[REDACTED] done, trailing SEC"; tee.String() != want || writer.Redacted() != want {
+		t.Fatalf("tee=%q recorded=%q want %q", tee.String(), writer.Redacted(), want)
+	}
+}
+
+func TestStreamWriterKeepsCarryPerSource(t *testing.T) {
+	var tee bytes.Buffer
+	writer := newStreamWriter(nil, &tee, NewSecretRedactor([]string{"SECRET"}))
+	stderr := writer.withLive(nil)
+
+	writer.Write([]byte("out SEC"))
+	stderr.Write([]byte("err line\n"))
+	writer.Write([]byte("RET\n"))
+	if err := writer.flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if got, want := tee.String(), "out err line\n[REDACTED]\n"; got != want {
+		t.Fatalf("tee=%q want %q", got, want)
+	}
+}
@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// beadFetchTimeout bounds one bead lookup so a hung bd never pins the
+// overlay in its loading state.
+const beadFetchTimeout = 10 * time.Second
+
+// BeadDetails is what the bead overlay shows for the current bead.
+type BeadDetails struct {
+	ID                 string
+	Title              string
+	Status             string
+	Type               string
+	Description        string
+	AcceptanceCriteria string
+}
+
+// BeadFetcher looks up a bead by ID, typically via bd show.
+type BeadFetcher func(ctx context.Context, beadID string) (BeadDetails, error)
+
+// BeadDetailsBindings is implemented by shells that can show the bead
+// overlay; the router checks for it on the 'b' hotkey.
+type BeadDetailsBindings interface {
+	ToggleBeadDetails() bool
+}
+
+// WithBeadDetails enables the 'b' overlay, fetching details with fetch.
+func WithBeadDetails(fetch BeadFetcher) Option {
+	return func(s *Shell) {
+		s.beadFetch = fetch
+	}
+}
+
+// ToggleBeadDetails shows or hides the overlay for the bead in the status
+// line, returning the new visibility. Details are fetched in the background
+// each time the overlay opens, so they reflect edits made during the run.
+func (s *Shell) ToggleBeadDetails() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.beadView = !s.beadView
+	s.beadFetchSeq++
+	s.beadErr = nil
+	s.beadDetails = nil
+	if s.beadView {
+		s.startBeadFetchLocked(s.beadFetchSeq)
+	}
+	s.requestRenderLocked()
+	return s.beadView
+}
+
+// BeadDetailsVisible reports whether the bead overlay is on screen.
+func (s *Shell) BeadDetailsVisible() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.beadView
+}
+
+func (s *Shell) startBeadFetchLocked(seq int) {
+	id := strings.TrimSpace(s.status.BeadID)
+	if id == "" || s.beadFetch == nil {
+		return
+	}
+	fetch := s.beadFetch
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), beadFetchTimeout)
+		defer cancel()
+		details, err := fetch(ctx, id)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if seq != s.beadFetchSeq {
+			return // closed or reopened meanwhile
+		}
+		if err != nil {
+			s.beadErr = err
+		} else {
+			s.beadDetails = &details
+			if s.status.BeadID == id && s.status.BeadTitle == "" {
+				s.status.BeadTitle = details.Title
+			}
+		}
+		s.requestRenderLocked()
+	}()
+}
+
+// beadOverlayLinesLocked renders the overlay, capped at half the screen so
+// the log stays visible.
+func (s *Shell) beadOverlayLinesLocked() []string {
+	if !s.beadView {
+		return nil
+	}
+	id := strings.TrimSpace(s.status.BeadID)
+	var lines []string
+	switch {
+	case id == "":
+		lines = []string{"Bead: none claimed yet (b to close)"}
+	case s.beadFetch == nil:
+		lines = []string{fmt.Sprintf("Bead %s: details unavailable (b to close)", id)}
+	case s.beadErr != nil:
+		lines = []string{fmt.Sprintf("Bead %s: %v (b to close)", id, s.beadErr)}
+	case s.beadDetails == nil:
+		lines = []string{fmt.Sprintf("Bead %s: loading bd show... (b to close)", id)}
+	default:
+		lines = formatBeadDetails(*s.beadDetails, s.width)
+	}
+	limit := s.height / 2
+	if limit < 3 {
+		limit = 3
+	}
+	if len(lines) > limit {
+		lines = append(lines[:limit-1], "  ...")
+	}
+	return lines
+}
+
+func formatBeadDetails(d BeadDetails, width int) []string {
+	header := fmt.Sprintf("Bead %s: %s", d.ID, d.Title)
+	var meta []string
+	for _, value := range []string{d.Type, d.Status} {
+		if strings.TrimSpace(value) != "" {
+			meta = append(meta, value)
+		}
+	}
+	if len(meta) > 0 {
+		header += " [" + strings.Join(meta, ", ") + "]"
+	}
+	lines := []string{truncateToWidth(header+" (b to close)", width)}
+	for _, section := range []struct{ label, text string }{
+		{"Description", d.Description},
+		{"Acceptance criteria", d.AcceptanceCriteria},
+	} {
+		text := strings.TrimSpace(section.text)
+		if text == "" {
+			continue
+		}
+		lines = append(lines, section.label+":")
+		for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+			lines = append(lines, wrapToWidth("  "+line, width, "  ")...)
+		}
+	}
+	return lines
+}
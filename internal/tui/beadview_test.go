@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBeadDetailsOverlay(t *testing.T) {
+	fetched := make(chan string, 1)
+	release := make(chan struct{})
+	shell := NewShell(WithIO(os.Stdin, io.Discard), withTerminal(&fakeTerminal{width: 60, height: 30}),
+		WithBeadDetails(func(_ context.Context, id string) (BeadDetails, error) {
+			fetched <- id
+			<-release
+			return BeadDetails{ID: id, Title: "Add retry", Status: "in_progress", Type: "task",
+				Description: "Retry failed posts.", AcceptanceCriteria: "- 5xx retried\n- 4xx not retried"}, nil
+		}))
+	shell.width, shell.height = 60, 30
+
+	if !shell.ToggleBeadDetails() {
+		t.Fatal("expected overlay to open")
+	}
+	if got := shell.beadOverlayLinesLocked(); len(got) != 1 || !strings.Contains(got[0], "none claimed yet") {
+		t.Fatalf("expected no-bead notice, got %q", got)
+	}
+	shell.ToggleBeadDetails()
+
+	shell.UpdateStatus(func(line *StatusLine) { line.BeadID = "obi-api.3" })
+	shell.ToggleBeadDetails()
+	if id := <-fetched; id != "obi-api.3" {
+		t.Fatalf("fetched %q", id)
+	}
+	if got := strings.Join(shell.beadOverlayLinesLocked(), "\n"); !strings.Contains(got, "loading bd show") {
+		t.Fatalf("expected loading notice, got %q", got)
+	}
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		shell.mu.Lock()
+		got := strings.Join(shell.beadOverlayLinesLocked(), "\n")
+		title := shell.status.BeadTitle
+		shell.mu.Unlock()
+		if strings.Contains(got, "Acceptance criteria:") {
+			for _, want := range []string{"Bead obi-api.3: Add retry [task, in_progress]", "  Retry failed posts.", "  - 4xx not retried"} {
+				if !strings.Contains(got, want) {
+					t.Fatalf("overlay missing %q:\n%s", want, got)
+				}
+			}
+			if title != "Add retry" {
+				t.Fatalf("expected header title filled from bd, got %q", title)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("details never arrived: %q", got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if shell.ToggleBeadDetails() || shell.beadOverlayLinesLocked() != nil {
+		t.Fatal("expected overlay to close")
+	}
+}
+
+func TestBeadDetailsOverlayShowsErrorsAndCaps(t *testing.T) {
+	shell := NewShell(WithIO(os.Stdin, io.Discard), withTerminal(&fakeTerminal{width: 60, height: 8}))
+	shell.width, shell.height = 60, 8
+	shell.status.BeadID = "obi-api.3"
+	shell.beadView = true
+	shell.beadFetch = func(context.Context, string) (BeadDetails, error) { return BeadDetails{}, nil }
+	shell.beadErr = errors.New("bd show: exit status 1")
+	if got := shell.beadOverlayLinesLocked(); len(got) != 1 || !strings.Contains(got[0], "exit status 1") {
+		t.Fatalf("expected error line, got %q", got)
+	}
+	shell.beadErr = nil
+	shell.beadDetails = &BeadDetails{ID: "obi-api.3", Description: strings.Repeat("line\n", 20)}
+	if got := shell.beadOverlayLinesLocked(); len(got) != 4 || got[3] != "  ..." {
+		t.Fatalf("expected overlay capped at half the screen, got %q", got)
+	}
+}
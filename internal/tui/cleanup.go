@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sync"
+)
+
+// terminalReset leaves bracketed paste, shows the cursor, and clears
+// attributes; it undoes everything enterRawMode writes.
+const terminalReset = "\x1b[?2004l\x1b[?25h\x1b[0m"
+
+// rawTerminal is what a shell needs to undo raw mode without taking its own
+// lock, which a panicking goroutine may still hold.
+type rawTerminal struct {
+	term  termAdapter
+	fd    int
+	state *termState
+	out   io.Writer
+}
+
+func (r rawTerminal) reset() {
+	if r.term != nil && r.state != nil && r.fd >= 0 {
+		_ = r.term.restore(r.fd, r.state)
+	}
+	if r.out != nil {
+		_, _ = io.WriteString(r.out, terminalReset)
+	}
+}
+
+var (
+	rawMu     sync.Mutex
+	rawShells = map[*Shell]rawTerminal{}
+	watchOnce sync.Once
+
+	// Swapped in tests.
+	panicOutput io.Writer = os.Stderr
+	exitProcess           = os.Exit
+)
+
+func trackRaw(s *Shell, raw rawTerminal) {
+	rawMu.Lock()
+	rawShells[s] = raw
+	rawMu.Unlock()
+	watchOnce.Do(watchCleanupSignals)
+}
+
+// untrackRaw forgets s and reports whether it was still in raw mode.
+func untrackRaw(s *Shell) (rawTerminal, bool) {
+	rawMu.Lock()
+	defer rawMu.Unlock()
+	raw, ok := rawShells[s]
+	delete(rawShells, s)
+	return raw, ok
+}
+
+// RestoreTerminal puts every shell still in raw mode back to the saved
+// termios and shows the cursor. It is safe to call more than once.
+func RestoreTerminal() {
+	rawMu.Lock()
+	pending := rawShells
+	rawShells = map[*Shell]rawTerminal{}
+	rawMu.Unlock()
+	for _, raw := range pending {
+		raw.reset()
+	}
+}
+
+// RecoverPanic restores the terminal before reporting a panic, so the
+// message and stack land on a usable screen. Defer it at the top of main
+// and of every goroutine that can run while a shell is in raw mode.
+func RecoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	RestoreTerminal()
+	fmt.Fprintf(panicOutput, "panic: %v\n\n%s", r, debug.Stack())
+	exitProcess(2)
+}
+
+// watchCleanupSignals restores the terminal when a signal that would kill
+// obi without running deferred cleanup arrives, then re-raises it so the
+// default behaviour (exit, and a goroutine dump for SIGQUIT) still happens.
+func watchCleanupSignals() {
+	if len(cleanupSignals) == 0 {
+		return
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, cleanupSignals...)
+	go func() {
+		sig := <-ch
+		RestoreTerminal()
+		signal.Reset(cleanupSignals...)
+		reraise(sig)
+	}()
+}
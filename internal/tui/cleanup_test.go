@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRestoreTerminalResetsRawShells(t *testing.T) {
+	out := &bytes.Buffer{}
+	term := &fakeTerminal{width: 80, height: 20}
+	shell := NewShell(WithIO(os.Stdin, out), withTerminal(term))
+	if err := shell.enterRawMode(); err != nil {
+		t.Fatalf("enter raw mode: %v", err)
+	}
+
+	RestoreTerminal()
+	if term.restoreCount != 1 || !strings.HasSuffix(out.String(), terminalReset) {
+		t.Fatalf("expected termios restored and cursor shown, got %d restores, output %q", term.restoreCount, out.String())
+	}
+
+	// The shell's own deferred restore must not reset the screen twice.
+	shell.restoreTerminal()
+	RestoreTerminal()
+	if term.restoreCount != 1 || strings.Count(out.String(), terminalReset) != 1 {
+		t.Fatalf("expected a single restore, got %d restores, output %q", term.restoreCount, out.String())
+	}
+}
+
+func TestRecoverPanicRestoresBeforeReporting(t *testing.T) {
+	out := &bytes.Buffer{}
+	term := &fakeTerminal{width: 80, height: 20}
+	shell := NewShell(WithIO(os.Stdin, out), withTerminal(term))
+	if err := shell.enterRawMode(); err != nil {
+		t.Fatalf("enter raw mode: %v", err)
+	}
+
+	report := &bytes.Buffer{}
+	exitCode := -1
+	panicOutput, exitProcess = report, func(code int) { exitCode = code }
+	defer func() { panicOutput, exitProcess = os.Stderr, os.Exit }()
+
+	func() {
+		defer RecoverPanic()
+		panic("render exploded")
+	}()
+
+	if term.restoreCount != 1 || !strings.HasSuffix(out.String(), terminalReset) {
+		t.Fatalf("expected terminal restored before the report, got %d restores", term.restoreCount)
+	}
+	if exitCode != 2 || !strings.HasPrefix(report.String(), "panic: render exploded") {
+		t.Fatalf("expected panic report and exit 2, got code %d, report %q", exitCode, report.String())
+	}
+}
@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// diffFetchTimeout bounds one git diff so a slow repository never pins the
+// overlay in its loading state.
+const diffFetchTimeout = 10 * time.Second
+
+// DiffFetcher returns the changes made so far: git diff --stat output, or
+// the full patch when full is set.
+type DiffFetcher func(ctx context.Context, full bool) (string, error)
+
+// DiffViewBindings is implemented by shells that can show the diff overlay;
+// the router checks for it on the 'd', 'r', 'j' and 'k' hotkeys.
+type DiffViewBindings interface {
+	CycleDiffView() bool
+	DiffViewVisible() bool
+	RefreshDiffView()
+	ScrollDiffView(pages int)
+}
+
+// WithDiffView enables the 'd' overlay, fetching changes with fetch.
+func WithDiffView(fetch DiffFetcher) Option {
+	return func(s *Shell) {
+		s.diffFetch = fetch
+	}
+}
+
+// diffViewMode is what the diff overlay shows.
+type diffViewMode int
+
+const (
+	diffViewOff diffViewMode = iota
+	diffViewStat
+	diffViewFull
+)
+
+// CycleDiffView steps the overlay from hidden to the --stat summary, then
+// to the full diff, then back to hidden, returning whether it is visible.
+// Every step that shows the overlay fetches afresh.
+func (s *Shell) CycleDiffView() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.diffView = (s.diffView + 1) % (diffViewFull + 1)
+	s.startDiffFetchLocked()
+	s.requestRenderLocked()
+	return s.diffView != diffViewOff
+}
+
+// RefreshDiffView re-runs the fetch for the overlay's current mode.
+func (s *Shell) RefreshDiffView() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.diffView == diffViewOff {
+		return
+	}
+	s.startDiffFetchLocked()
+	s.requestRenderLocked()
+}
+
+// DiffViewVisible reports whether the diff overlay is on screen.
+func (s *Shell) DiffViewVisible() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.diffView != diffViewOff
+}
+
+// ScrollDiffView moves the overlay a page down (positive) or up (negative)
+// through a diff taller than the overlay.
+func (s *Shell) ScrollDiffView(pages int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, rows := s.diffBodyLocked()
+	if rows >= len(body) {
+		return
+	}
+	s.diffScroll += pages * rows
+	if last := len(body) - rows; s.diffScroll > last {
+		s.diffScroll = last
+	}
+	if s.diffScroll < 0 {
+		s.diffScroll = 0
+	}
+	s.requestRenderLocked()
+}
+
+func (s *Shell) startDiffFetchLocked() {
+	s.diffFetchSeq++
+	s.diffScroll = 0
+	s.diffErr = nil
+	s.diffText = nil
+	if s.diffView == diffViewOff || s.diffFetch == nil {
+		return
+	}
+	seq, full, fetch := s.diffFetchSeq, s.diffView == diffViewFull, s.diffFetch
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), diffFetchTimeout)
+		defer cancel()
+		text, err := fetch(ctx, full)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if seq != s.diffFetchSeq {
+			return // closed, switched, or refreshed meanwhile
+		}
+		if err != nil {
+			s.diffErr = err
+		} else {
+			s.diffText = &text
+		}
+		s.requestRenderLocked()
+	}()
+}
+
+// diffOverlayLimitLocked is the overlay's height: half the screen, so the
+// log stays visible.
+func (s *Shell) diffOverlayLimitLocked() int {
+	limit := s.height / 2
+	if limit < 3 {
+		limit = 3
+	}
+	return limit
+}
+
+// diffBodyLocked returns the fetched diff's lines and how many of them fit
+// in the overlay under its title and scroll line.
+func (s *Shell) diffBodyLocked() ([]string, int) {
+	if s.diffText == nil || strings.TrimSpace(*s.diffText) == "" {
+		return nil, 0
+	}
+	var body []string
+	for _, line := range strings.Split(strings.TrimRight(strings.ReplaceAll(*s.diffText, "\r\n", "\n"), "\n"), "\n") {
+		body = append(body, "  "+strings.ReplaceAll(line, "\t", "    "))
+	}
+	rows := s.diffOverlayLimitLocked() - 1
+	if len(body) > rows {
+		rows--
+	}
+	return body, rows
+}
+
+// diffOverlayLinesLocked renders the overlay, capped at half the screen so
+// the log stays visible. A longer diff shows one page at a time; j and k
+// page through it.
+func (s *Shell) diffOverlayLinesLocked() []string {
+	if s.diffView == diffViewOff {
+		return nil
+	}
+	title := "Diff --stat (d: full diff, r: refresh)"
+	if s.diffView == diffViewFull {
+		title = "Diff (d: close, r: refresh)"
+	}
+	switch {
+	case s.diffFetch == nil:
+		return []string{"Diff: unavailable outside a git checkout (d to close)"}
+	case s.diffErr != nil:
+		return []string{fmt.Sprintf("%s: %v", title, s.diffErr)}
+	case s.diffText == nil:
+		return []string{title + ": loading git diff..."}
+	case strings.TrimSpace(*s.diffText) == "":
+		return []string{title + ": no changes yet"}
+	}
+	body, rows := s.diffBodyLocked()
+	if rows >= len(body) {
+		return append([]string{title + ":"}, body...)
+	}
+	start := s.diffScroll
+	if start > len(body)-rows {
+		start = len(body) - rows
+	}
+	end := start + rows
+	lines := []string{fmt.Sprintf("%s, lines %d-%d of %d:", title, start+1, end, len(body))}
+	lines = append(lines, body[start:end]...)
+	if hidden := len(body) - end; hidden > 0 {
+		return append(lines, fmt.Sprintf("  ... %d more line(s) (j: page down, k: page up)", hidden))
+	}
+	return append(lines, "  (end of diff; k: page up)")
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffViewOverlayCyclesAndRefreshes(t *testing.T) {
+	calls := make(chan bool, 4)
+	stat := " main.go | 2 +-\n 1 file changed"
+	shell := NewShell(WithIO(os.Stdin, io.Discard), withTerminal(&fakeTerminal{width: 60, height: 30}),
+		WithDiffView(func(_ context.Context, full bool) (string, error) {
+			calls <- full
+			if full {
+				return "diff --git a/main.go b/main.go\n-old\n+new\n", nil
+			}
+			return stat, nil
+		}))
+	shell.width, shell.height = 60, 30
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			shell.mu.Lock()
+			got := strings.Join(shell.diffOverlayLinesLocked(), "\n")
+			shell.mu.Unlock()
+			if strings.Contains(got, want) {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("overlay never showed %q: %q", want, got)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	if !shell.CycleDiffView() || <-calls {
+		t.Fatal("expected the --stat overlay first")
+	}
+	waitFor("  main.go | 2 +-")
+
+	stat = ""
+	shell.RefreshDiffView()
+	<-calls
+	waitFor("no changes yet")
+
+	if !shell.CycleDiffView() || !<-calls {
+		t.Fatal("expected the full diff second")
+	}
+	waitFor("  +new")
+
+	if shell.CycleDiffView() || shell.DiffViewVisible() || shell.diffOverlayLinesLocked() != nil {
+		t.Fatal("expected the overlay to close on the third press")
+	}
+}
+
+func TestDiffViewOverlayShowsErrorsAndCaps(t *testing.T) {
+	shell := NewShell(WithIO(os.Stdin, io.Discard), withTerminal(&fakeTerminal{width: 60, height: 8}))
+	shell.width, shell.height = 60, 8
+	shell.diffView = diffViewStat
+	if got := shell.diffOverlayLinesLocked(); len(got) != 1 || !strings.Contains(got[0], "unavailable") {
+		t.Fatalf("expected unavailable notice, got %q", got)
+	}
+	shell.diffFetch = func(context.Context, bool) (string, error) { return "", nil }
+	shell.diffErr = errors.New("git diff: exit status 128")
+	if got := shell.diffOverlayLinesLocked(); len(got) != 1 || !strings.Contains(got[0], "exit status 128") {
+		t.Fatalf("expected error line, got %q", got)
+	}
+	shell.diffErr = nil
+	text := strings.Repeat("file | 1 +\n", 10)
+	shell.diffText = &text
+	if got := shell.diffOverlayLinesLocked(); len(got) != 4 || !strings.HasSuffix(got[0], "lines 1-2 of 10:") || got[3] != "  ... 8 more line(s) (j: page down, k: page up)" {
+		t.Fatalf("expected overlay capped at half the screen, got %q", got)
+	}
+	for i := 0; i < 5; i++ {
+		shell.ScrollDiffView(1)
+	}
+	if got := shell.diffOverlayLinesLocked(); !strings.HasSuffix(got[0], "lines 9-10 of 10:") || got[3] != "  (end of diff; k: page up)" {
+		t.Fatalf("expected the last page, got %q", got)
+	}
+	shell.ScrollDiffView(-1)
+	if got := shell.diffOverlayLinesLocked(); !strings.HasSuffix(got[0], "lines 7-8 of 10:") {
+		t.Fatalf("expected the page before the last, got %q", got)
+	}
+}
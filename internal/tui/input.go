@@ -1,14 +1,22 @@
 package tui
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
 	"strings"
+	"sync"
 	"unicode"
 )
 
-const softStopReasonDefault = "Operator requested soft stop (hotkey 's')"
+const (
+	softStopReasonDefault = "Operator requested soft stop (hotkey 's')"
+
+	// Terminals wrap pasted text in these markers once bracketed paste is on.
+	pasteStartMarker = "\x1b[200~"
+	pasteEndMarker   = "\x1b[201~"
+)
 
 // SessionControls exposes the session operations needed for input routing.
 type SessionControls interface {
@@ -26,7 +34,21 @@ type HintSubmitter interface {
 type ShellBindings interface {
 	TogglePause() bool
 	SetHintInput(active bool, text string)
+	SetLineInput(active bool, text string)
 	ToggleHelp() bool
+	CycleTimestamps() TimestampMode
+}
+
+// ApprovalBindings is implemented by shells that can show the approval
+// modal; the router checks for it when an approval is requested.
+type ApprovalBindings interface {
+	SetApprovalPrompt(active bool, request string)
+}
+
+// WrapBindings is implemented by shells that can toggle soft wrapping of
+// long log lines.
+type WrapBindings interface {
+	ToggleWrap() bool
 }
 
 // InputMode identifies the current routing mode.
@@ -37,17 +59,48 @@ const (
 	ModePassthrough InputMode = iota
 	// ModeHint captures characters for the inline hint entry UI.
 	ModeHint
+	// ModeLine edits a line locally and forwards it to Codex on Enter.
+	ModeLine
+	// ModeApproval waits for y (approve) or n/Esc (deny) on a pending
+	// escalation request; every other key is ignored.
+	ModeApproval
+	// ModeSoftStop shows the soft-stop quick-pick: a digit picks a preset,
+	// e types a reason, Enter uses the default and Esc cancels.
+	ModeSoftStop
+	// ModeSoftStopText captures a free-text soft-stop reason.
+	ModeSoftStopText
 )
 
+type approvalRequest struct {
+	request string
+	decide  func(approved bool)
+}
+
 // InputRouter interprets keystrokes, triggering hotkeys or forwarding bytes.
 type InputRouter struct {
+	// mu serializes keystroke handling with approval requests, which
+	// arrive from the output goroutine.
+	mu              sync.Mutex
 	session         SessionControls
 	shell           ShellBindings
 	hints           HintSubmitter
 	mode            InputMode
 	hintBuf         []rune
+	lineBuf         []rune
 	softStopReason  string
 	cancelSequences map[byte]struct{}
+	// softStopPresets, when set, make 's' open the quick-pick instead of
+	// stopping at once.
+	softStopPresets []string
+	stopBuf         []rune
+
+	escBuf   []byte
+	pasting  bool
+	pasteBuf []byte
+
+	approvals []approvalRequest
+	// resumeMode is the mode to restore once the approval queue drains.
+	resumeMode InputMode
 }
 
 // InputOption customizes router behavior.
@@ -70,6 +123,19 @@ func WithSoftStopReason(reason string) InputOption {
 	}
 }
 
+// WithSoftStopPresets offers reasons in a quick-pick when 's' is pressed;
+// only the first MaxSoftStopPresets are used.
+func WithSoftStopPresets(reasons []string) InputOption {
+	return func(r *InputRouter) {
+		r.softStopPresets = nil
+		for _, reason := range reasons {
+			if reason = strings.TrimSpace(reason); reason != "" && len(r.softStopPresets) < MaxSoftStopPresets {
+				r.softStopPresets = append(r.softStopPresets, reason)
+			}
+		}
+	}
+}
+
 // NewInputRouter wires keyboard input to the session and shell bindings.
 func NewInputRouter(session SessionControls, shell ShellBindings, opts ...InputOption) *InputRouter {
 	router := &InputRouter{
@@ -114,30 +180,185 @@ func (r *InputRouter) Run(ctx context.Context, src io.Reader) error {
 	}
 }
 
-// HandleBytes applies routing logic to the provided bytes.
+// HandleBytes applies routing logic to the provided bytes. Bracketed pastes
+// are collected (across calls if needed) and delivered as a single unit so
+// pasted text never triggers hotkeys or reaches Codex one byte at a time.
 func (r *InputRouter) HandleBytes(data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	for _, b := range data {
+		if r.pasting {
+			r.pasteBuf = append(r.pasteBuf, b)
+			if bytes.HasSuffix(r.pasteBuf, []byte(pasteEndMarker)) {
+				content := r.pasteBuf[:len(r.pasteBuf)-len(pasteEndMarker)]
+				r.pasting = false
+				r.pasteBuf = nil
+				if err := r.deliverPaste(content); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if len(r.escBuf) > 0 || b == 0x1b {
+			r.escBuf = append(r.escBuf, b)
+			if string(r.escBuf) == pasteStartMarker {
+				r.escBuf = nil
+				r.pasting = true
+				continue
+			}
+			if strings.HasPrefix(pasteStartMarker, string(r.escBuf)) {
+				continue
+			}
+			if err := r.flushEscape(); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := r.handleByte(b); err != nil {
 			return err
 		}
 	}
+	if len(r.escBuf) > 0 {
+		return r.flushEscape()
+	}
+	return nil
+}
+
+// flushEscape routes a buffered escape sequence that turned out not to be a
+// paste marker. Passthrough forwards it intact (arrow keys, function keys);
+// the edit modes treat a lone ESC as cancel and drop longer sequences.
+func (r *InputRouter) flushEscape() error {
+	seq := r.escBuf
+	r.escBuf = nil
+	if r.mode == ModePassthrough {
+		if r.session == nil {
+			return errors.New("session controls unavailable for pass-through input")
+		}
+		_, err := r.session.WriteInput(seq)
+		return err
+	}
+	if len(seq) == 1 {
+		return r.handleByte(seq[0])
+	}
 	return nil
 }
 
+func (r *InputRouter) deliverPaste(content []byte) error {
+	if len(content) == 0 {
+		return nil
+	}
+	switch r.mode {
+	case ModeApproval:
+		return nil
+	case ModeHint:
+		r.hintBuf = append(r.hintBuf, []rune(flattenPaste(content))...)
+		r.syncHintUI()
+		return nil
+	case ModeLine:
+		r.lineBuf = append(r.lineBuf, []rune(flattenPaste(content))...)
+		r.syncLineUI()
+		return nil
+	case ModeSoftStop:
+		return nil
+	case ModeSoftStopText:
+		r.stopBuf = append(r.stopBuf, []rune(flattenPaste(content))...)
+		r.syncSoftStopUI()
+		return nil
+	default:
+		if r.session == nil {
+			return errors.New("session controls unavailable for paste")
+		}
+		_, err := r.session.WriteInput(content)
+		return err
+	}
+}
+
+// flattenPaste folds pasted newlines into spaces for single-line editors.
+func flattenPaste(content []byte) string {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(text)
+}
+
 // Mode reports the current routing mode.
 func (r *InputRouter) Mode() InputMode {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.mode
 }
 
 // HintText exposes the in-progress hint contents.
 func (r *InputRouter) HintText() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return string(r.hintBuf)
 }
 
+// RequestApproval queues an escalation request for the operator. The modal
+// takes over the keyboard until y, n, or Esc is pressed, then decide runs
+// with the answer and the next queued request (if any) is shown. Hint or
+// line edits in progress resume afterwards.
+func (r *InputRouter) RequestApproval(request string, decide func(approved bool)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.approvals = append(r.approvals, approvalRequest{request: request, decide: decide})
+	if r.mode != ModeApproval {
+		r.resumeMode = r.mode
+		r.mode = ModeApproval
+		r.syncApprovalUI()
+	}
+}
+
+func (r *InputRouter) handleApprovalByte(b byte) error {
+	var approved bool
+	switch unicode.ToLower(rune(b)) {
+	case 'y':
+		approved = true
+	case 'n', 0x1b:
+		approved = false
+	default:
+		return nil
+	}
+	pending := r.approvals[0]
+	r.approvals = r.approvals[1:]
+	if len(r.approvals) == 0 {
+		r.mode = r.resumeMode
+	}
+	r.syncApprovalUI()
+	if pending.decide != nil {
+		pending.decide(approved)
+	}
+	return nil
+}
+
+func (r *InputRouter) syncApprovalUI() {
+	modal, ok := r.shell.(ApprovalBindings)
+	if !ok {
+		return
+	}
+	if r.mode == ModeApproval && len(r.approvals) > 0 {
+		modal.SetApprovalPrompt(true, r.approvals[0].request)
+		return
+	}
+	modal.SetApprovalPrompt(false, "")
+}
+
 func (r *InputRouter) handleByte(b byte) error {
+	if b == ctrlZ {
+		if shell, ok := r.shell.(SuspendBindings); ok {
+			return shell.Suspend()
+		}
+	}
 	switch r.mode {
+	case ModeApproval:
+		return r.handleApprovalByte(b)
 	case ModeHint:
 		return r.handleHintByte(b)
+	case ModeLine:
+		return r.handleLineByte(b)
+	case ModeSoftStop:
+		return r.handleSoftStopPickByte(b)
+	case ModeSoftStopText:
+		return r.handleSoftStopTextByte(b)
 	default:
 		return r.handlePassthroughByte(b)
 	}
@@ -153,15 +374,52 @@ func (r *InputRouter) handlePassthroughByte(b byte) error {
 	case 'h':
 		r.startHintCapture()
 		return nil
-	case 's':
-		if r.session == nil {
-			return errors.New("session controls unavailable for soft stop")
+	case 't':
+		if r.shell != nil {
+			r.shell.CycleTimestamps()
 		}
-		reason := r.softStopReason
-		if strings.TrimSpace(reason) == "" {
-			reason = softStopReasonDefault
+		return nil
+	case 'i':
+		r.startLineCapture()
+		return nil
+	case 'b':
+		if shell, ok := r.shell.(BeadDetailsBindings); ok {
+			shell.ToggleBeadDetails()
+			return nil
+		}
+	case 'd':
+		if shell, ok := r.shell.(DiffViewBindings); ok {
+			shell.CycleDiffView()
+			return nil
+		}
+	case 'r':
+		// Only while the diff overlay is up; otherwise r goes to Codex.
+		if shell, ok := r.shell.(DiffViewBindings); ok && shell.DiffViewVisible() {
+			shell.RefreshDiffView()
+			return nil
+		}
+	case 'j', 'k':
+		// Likewise j and k page through the overlay only while it is up.
+		if shell, ok := r.shell.(DiffViewBindings); ok && shell.DiffViewVisible() {
+			pages := 1
+			if unicode.ToLower(rune(b)) == 'k' {
+				pages = -1
+			}
+			shell.ScrollDiffView(pages)
+			return nil
+		}
+	case 'w':
+		if shell, ok := r.shell.(WrapBindings); ok {
+			shell.ToggleWrap()
+			return nil
+		}
+	case 's':
+		if _, ok := r.shell.(SoftStopBindings); ok && len(r.softStopPresets) > 0 {
+			r.mode = ModeSoftStop
+			r.syncSoftStopUI()
+			return nil
 		}
-		return r.session.SoftStop(reason)
+		return r.softStop(r.softStopReason)
 	case 'q':
 		if r.session == nil {
 			return errors.New("session controls unavailable for abort")
@@ -202,6 +460,60 @@ func (r *InputRouter) handleHintByte(b byte) error {
 	}
 }
 
+func (r *InputRouter) handleLineByte(b byte) error {
+	if _, ok := r.cancelSequences[b]; ok {
+		r.exitLineCapture()
+		return nil
+	}
+	switch b {
+	case '\r', '\n':
+		return r.finalizeLine()
+	case 0x7f, 0x08:
+		if len(r.lineBuf) > 0 {
+			r.lineBuf = r.lineBuf[:len(r.lineBuf)-1]
+			r.syncLineUI()
+		}
+		return nil
+	default:
+		r.lineBuf = append(r.lineBuf, rune(b))
+		r.syncLineUI()
+		return nil
+	}
+}
+
+func (r *InputRouter) startLineCapture() {
+	r.mode = ModeLine
+	r.lineBuf = r.lineBuf[:0]
+	r.syncLineUI()
+}
+
+func (r *InputRouter) exitLineCapture() {
+	r.mode = ModePassthrough
+	r.lineBuf = r.lineBuf[:0]
+	if r.shell != nil {
+		r.shell.SetLineInput(false, "")
+	}
+}
+
+// finalizeLine forwards the edited line to Codex in one write, followed by
+// a carriage return as if the operator had typed it directly.
+func (r *InputRouter) finalizeLine() error {
+	text := string(r.lineBuf)
+	r.exitLineCapture()
+	if r.session == nil {
+		return errors.New("session controls unavailable for line input")
+	}
+	_, err := r.session.WriteInput([]byte(text + "\r"))
+	return err
+}
+
+func (r *InputRouter) syncLineUI() {
+	if r.shell == nil {
+		return
+	}
+	r.shell.SetLineInput(true, string(r.lineBuf))
+}
+
 func (r *InputRouter) startHintCapture() {
 	r.mode = ModeHint
 	r.hintBuf = r.hintBuf[:0]
@@ -233,3 +545,76 @@ func (r *InputRouter) syncHintUI() {
 	}
 	r.shell.SetHintInput(true, string(r.hintBuf))
 }
+
+func (r *InputRouter) handleSoftStopPickByte(b byte) error {
+	switch {
+	case b == 0x1b:
+		r.exitSoftStop()
+		return nil
+	case b == '\r' || b == '\n':
+		r.exitSoftStop()
+		return r.softStop(r.softStopReason)
+	case unicode.ToLower(rune(b)) == 'e':
+		r.mode = ModeSoftStopText
+		r.stopBuf = r.stopBuf[:0]
+		r.syncSoftStopUI()
+		return nil
+	case b >= '1' && b <= '9':
+		idx := int(b - '1')
+		if idx >= len(r.softStopPresets) {
+			return nil
+		}
+		r.exitSoftStop()
+		return r.softStop(r.softStopPresets[idx])
+	}
+	return nil
+}
+
+func (r *InputRouter) handleSoftStopTextByte(b byte) error {
+	if _, ok := r.cancelSequences[b]; ok {
+		r.exitSoftStop()
+		return nil
+	}
+	switch b {
+	case '\r', '\n':
+		text := string(r.stopBuf)
+		r.exitSoftStop()
+		return r.softStop(text)
+	case 0x7f, 0x08:
+		if len(r.stopBuf) > 0 {
+			r.stopBuf = r.stopBuf[:len(r.stopBuf)-1]
+			r.syncSoftStopUI()
+		}
+		return nil
+	default:
+		r.stopBuf = append(r.stopBuf, rune(b))
+		r.syncSoftStopUI()
+		return nil
+	}
+}
+
+// softStop sends reason, or the default when it is blank.
+func (r *InputRouter) softStop(reason string) error {
+	if r.session == nil {
+		return errors.New("session controls unavailable for soft stop")
+	}
+	if strings.TrimSpace(reason) == "" {
+		reason = softStopReasonDefault
+	}
+	return r.session.SoftStop(strings.TrimSpace(reason))
+}
+
+func (r *InputRouter) exitSoftStop() {
+	r.mode = ModePassthrough
+	r.stopBuf = r.stopBuf[:0]
+	r.syncSoftStopUI()
+}
+
+func (r *InputRouter) syncSoftStopUI() {
+	picker, ok := r.shell.(SoftStopBindings)
+	if !ok {
+		return
+	}
+	picker.SetSoftStopPicker(r.mode == ModeSoftStop, r.softStopPresets)
+	picker.SetSoftStopInput(r.mode == ModeSoftStopText, string(r.stopBuf))
+}
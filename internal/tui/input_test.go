@@ -2,6 +2,7 @@ package tui
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -17,6 +18,63 @@ func TestInputRouterPassThrough(t *testing.T) {
 	}
 }
 
+func TestInputRouterSuspendsOnCtrlZ(t *testing.T) {
+	session := &fakeSessionControls{}
+	shell := &fakeShellBindings{}
+	router := NewInputRouter(session, shell)
+	if err := router.HandleBytes([]byte{'h', 'x', ctrlZ}); err != nil {
+		t.Fatalf("handle bytes: %v", err)
+	}
+	if shell.suspends != 1 || session.joinWrites() != "" {
+		t.Fatalf("expected Ctrl+Z to suspend without reaching Codex, got %d suspends, writes %q", shell.suspends, session.joinWrites())
+	}
+	if router.Mode() != ModeHint || router.HintText() != "x" {
+		t.Fatalf("expected hint entry to survive the suspend, got mode %v text %q", router.Mode(), router.HintText())
+	}
+}
+
+func TestInputRouterTogglesBeadDetails(t *testing.T) {
+	session := &fakeSessionControls{}
+	shell := &fakeShellBindings{}
+	if err := NewInputRouter(session, shell).HandleBytes([]byte("b")); err != nil {
+		t.Fatalf("handle bytes: %v", err)
+	}
+	if !shell.beadView || session.joinWrites() != "" {
+		t.Fatalf("expected b to open the bead overlay, got view=%v writes %q", shell.beadView, session.joinWrites())
+	}
+}
+
+func TestInputRouterCyclesDiffView(t *testing.T) {
+	session := &fakeSessionControls{}
+	shell := &fakeShellBindings{}
+	router := NewInputRouter(session, shell)
+	// r reaches Codex while the overlay is closed and refreshes it when open.
+	if err := router.HandleBytes([]byte("rdrd")); err != nil {
+		t.Fatalf("handle bytes: %v", err)
+	}
+	if shell.diffView != 2 || shell.diffRefresh != 1 || session.joinWrites() != "r" {
+		t.Fatalf("expected full diff after one refresh, got view=%d refreshes=%d writes %q", shell.diffView, shell.diffRefresh, session.joinWrites())
+	}
+	// j and k page through the open overlay and reach Codex once it closes.
+	if err := router.HandleBytes([]byte("jjkdj")); err != nil {
+		t.Fatalf("handle bytes: %v", err)
+	}
+	if shell.diffScroll != 1 || session.joinWrites() != "rj" {
+		t.Fatalf("expected one page down, got scroll=%d writes %q", shell.diffScroll, session.joinWrites())
+	}
+}
+
+func TestInputRouterTogglesWrap(t *testing.T) {
+	session := &fakeSessionControls{}
+	shell := &fakeShellBindings{}
+	if err := NewInputRouter(session, shell).HandleBytes([]byte("w")); err != nil {
+		t.Fatalf("handle bytes: %v", err)
+	}
+	if !shell.wrap || session.joinWrites() != "" {
+		t.Fatalf("expected w to toggle wrapping, got wrap=%v writes %q", shell.wrap, session.joinWrites())
+	}
+}
+
 func TestInputRouterHandlesHotkeys(t *testing.T) {
 	session := &fakeSessionControls{}
 	shell := &fakeShellBindings{}
@@ -36,6 +94,13 @@ func TestInputRouterHandlesHotkeys(t *testing.T) {
 		t.Fatalf("expected help overlay to toggle on")
 	}
 
+	if err := router.HandleBytes([]byte("t")); err != nil {
+		t.Fatalf("cycle timestamps: %v", err)
+	}
+	if shell.timestamps != TimestampsClock {
+		t.Fatalf("expected timestamp gutter to cycle to clock, got %v", shell.timestamps)
+	}
+
 	if err := router.HandleBytes([]byte("s")); err != nil {
 		t.Fatalf("soft stop: %v", err)
 	}
@@ -105,8 +170,131 @@ func TestInputRouterHintSubmissionErrorPropagates(t *testing.T) {
 	}
 }
 
+func TestInputRouterBatchesBracketedPaste(t *testing.T) {
+	session := &fakeSessionControls{}
+	shell := &fakeShellBindings{}
+	router := NewInputRouter(session, shell)
+
+	// Split the paste across reads to mimic the 64-byte read buffer.
+	if err := router.HandleBytes([]byte("\x1b[200~pasted qs\nline")); err != nil {
+		t.Fatalf("paste part 1: %v", err)
+	}
+	if err := router.HandleBytes([]byte(" two\x1b[201~")); err != nil {
+		t.Fatalf("paste part 2: %v", err)
+	}
+	if len(session.writes) != 1 || session.writes[0] != "pasted qs\nline two" {
+		t.Fatalf("expected one batched paste write, got %q", session.writes)
+	}
+	if shell.paused || session.abortCount != 0 || len(session.softStops) != 0 {
+		t.Fatalf("pasted text must not trigger hotkeys")
+	}
+}
+
+func TestInputRouterForwardsEscapeSequencesIntact(t *testing.T) {
+	session := &fakeSessionControls{}
+	router := NewInputRouter(session, &fakeShellBindings{})
+	if err := router.HandleBytes([]byte("\x1b[A")); err != nil {
+		t.Fatalf("arrow key: %v", err)
+	}
+	if len(session.writes) != 1 || session.writes[0] != "\x1b[A" {
+		t.Fatalf("expected arrow key forwarded as one write, got %q", session.writes)
+	}
+}
+
+func TestInputRouterLineEditing(t *testing.T) {
+	session := &fakeSessionControls{}
+	shell := &fakeShellBindings{}
+	router := NewInputRouter(session, shell)
+
+	if err := router.HandleBytes([]byte{'i', 'y', 'e', 'p', 0x7f, 's', '\r'}); err != nil {
+		t.Fatalf("line edit: %v", err)
+	}
+	if shell.lineActive {
+		t.Fatalf("expected line mode to exit after Enter")
+	}
+	if len(session.writes) != 1 || session.writes[0] != "yes\r" {
+		t.Fatalf("expected edited line in one write, got %q", session.writes)
+	}
+}
+
+func TestInputRouterSoftStopQuickPick(t *testing.T) {
+	session := &fakeSessionControls{}
+	shell := &fakeShellBindings{}
+	router := NewInputRouter(session, shell, WithSoftStopPresets([]string{"meeting starting", " ", "wrong approach"}))
+
+	if err := router.HandleBytes([]byte("s")); err != nil {
+		t.Fatalf("open picker: %v", err)
+	}
+	if router.Mode() != ModeSoftStop || !shell.stopPicking || strings.Join(shell.stopReasons, "|") != "meeting starting|wrong approach" {
+		t.Fatalf("expected quick-pick with two presets, got mode %v picking=%v reasons=%v", router.Mode(), shell.stopPicking, shell.stopReasons)
+	}
+	if err := router.HandleBytes([]byte("3x2")); err != nil {
+		t.Fatalf("pick: %v", err)
+	}
+	if strings.Join(session.softStops, "|") != "wrong approach" || len(session.writes) != 0 {
+		t.Fatalf("expected out-of-range keys ignored and preset 2 sent, got stops=%v writes=%v", session.softStops, session.writes)
+	}
+	if router.Mode() != ModePassthrough || shell.stopPicking {
+		t.Fatalf("expected picker to close, got mode %v", router.Mode())
+	}
+
+	if err := router.HandleBytes([]byte("seout of tokenz\x7fs\r")); err != nil {
+		t.Fatalf("typed reason: %v", err)
+	}
+	if got := session.softStops[len(session.softStops)-1]; got != "out of tokens" || shell.stopTyping {
+		t.Fatalf("expected typed reason, got %q typing=%v", got, shell.stopTyping)
+	}
+
+	if err := router.HandleBytes([]byte{'s', 0x1b, 's', '\r'}); err != nil {
+		t.Fatalf("cancel then default: %v", err)
+	}
+	if len(session.softStops) != 3 || session.softStops[2] != softStopReasonDefault {
+		t.Fatalf("expected Esc to cancel and Enter to send the default, got %v", session.softStops)
+	}
+}
+
 // --- fakes ---
 
+func TestInputRouterApprovalModal(t *testing.T) {
+	session := &fakeSessionControls{}
+	shell := &fakeShellBindings{}
+	router := NewInputRouter(session, shell)
+
+	if err := router.HandleBytes([]byte("h")); err != nil {
+		t.Fatalf("start hint: %v", err)
+	}
+	var answers []string
+	decide := func(name string) func(bool) {
+		return func(approved bool) {
+			answers = append(answers, fmt.Sprintf("%s=%v", name, approved))
+		}
+	}
+	router.RequestApproval("npm install", decide("npm"))
+	router.RequestApproval("curl example.com", decide("curl"))
+	if router.Mode() != ModeApproval || shell.approval != "npm install" {
+		t.Fatalf("expected approval modal for npm install, got mode %v prompt %q", router.Mode(), shell.approval)
+	}
+
+	if err := router.HandleBytes([]byte("xqy")); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if session.abortCount != 0 || len(session.writes) != 0 {
+		t.Fatalf("expected modal to swallow other keys, got aborts=%d writes=%v", session.abortCount, session.writes)
+	}
+	if shell.approval != "curl example.com" {
+		t.Fatalf("expected next request to be shown, got %q", shell.approval)
+	}
+	if err := router.HandleBytes([]byte{0x1b}); err != nil {
+		t.Fatalf("deny: %v", err)
+	}
+	if got := strings.Join(answers, ","); got != "npm=true,curl=false" {
+		t.Fatalf("unexpected answers %q", got)
+	}
+	if router.Mode() != ModeHint || shell.approval != "" {
+		t.Fatalf("expected hint entry to resume, got mode %v prompt %q", router.Mode(), shell.approval)
+	}
+}
+
 type fakeSessionControls struct {
 	writes     []string
 	softStops  []string
@@ -141,6 +329,63 @@ type fakeShellBindings struct {
 	helpVisible bool
 	hintActive  bool
 	hintText    string
+	timestamps  TimestampMode
+	lineActive  bool
+	lineText    string
+	approval    string
+	suspends    int
+	beadView    bool
+	diffView    int
+	diffScroll  int
+	diffRefresh int
+	wrap        bool
+	stopPicking bool
+	stopReasons []string
+	stopTyping  bool
+	stopText    string
+}
+
+func (f *fakeShellBindings) SetSoftStopPicker(active bool, reasons []string) {
+	f.stopPicking = active
+	f.stopReasons = reasons
+}
+
+func (f *fakeShellBindings) SetSoftStopInput(active bool, text string) {
+	f.stopTyping = active
+	f.stopText = text
+}
+
+func (f *fakeShellBindings) ToggleWrap() bool {
+	f.wrap = !f.wrap
+	return f.wrap
+}
+
+func (f *fakeShellBindings) ToggleBeadDetails() bool {
+	f.beadView = !f.beadView
+	return f.beadView
+}
+
+func (f *fakeShellBindings) CycleDiffView() bool {
+	f.diffView = (f.diffView + 1) % 3
+	return f.diffView != 0
+}
+
+func (f *fakeShellBindings) DiffViewVisible() bool { return f.diffView != 0 }
+
+func (f *fakeShellBindings) RefreshDiffView() { f.diffRefresh++ }
+
+func (f *fakeShellBindings) ScrollDiffView(pages int) { f.diffScroll += pages }
+
+func (f *fakeShellBindings) Suspend() error {
+	f.suspends++
+	return nil
+}
+
+func (f *fakeShellBindings) SetApprovalPrompt(active bool, request string) {
+	if !active {
+		request = ""
+	}
+	f.approval = request
 }
 
 func (f *fakeShellBindings) TogglePause() bool {
@@ -153,11 +398,21 @@ func (f *fakeShellBindings) SetHintInput(active bool, text string) {
 	f.hintText = text
 }
 
+func (f *fakeShellBindings) SetLineInput(active bool, text string) {
+	f.lineActive = active
+	f.lineText = text
+}
+
 func (f *fakeShellBindings) ToggleHelp() bool {
 	f.helpVisible = !f.helpVisible
 	return f.helpVisible
 }
 
+func (f *fakeShellBindings) CycleTimestamps() TimestampMode {
+	f.timestamps = f.timestamps.next()
+	return f.timestamps
+}
+
 type fakeHintSubmitter struct {
 	submissions []string
 	err         error
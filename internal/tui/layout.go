@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Layout assembles the header status lines and the footer legend from
+// template strings. Placeholders such as {epic} or {tokens} are filled from
+// the status line; a " | "-separated segment whose placeholders all render
+// empty is dropped, so "Phase: {phase}" disappears until a phase is known.
+// Empty fields keep the default layout.
+type Layout struct {
+	Title   string
+	Context string
+	Status  string
+	Footer  string
+}
+
+// Default layout templates, matching the built-in header and footer.
+const (
+	DefaultTitleTemplate   = "{title}"
+	DefaultContextTemplate = "Epic: {epic} ({epic_id}) | Bead: {bead} | Phase: {phase}"
+	DefaultStatusTemplate  = "Status: {status} | Elapsed: {elapsed} | Tokens: {tokens}"
+	DefaultFooterTemplate  = "Hotkeys: {hotkeys}"
+)
+
+// LayoutPlaceholders lists the names a layout template may use.
+var LayoutPlaceholders = []string{
+	"title", "epic", "epic_id", "bead", "bead_id", "status", "elapsed",
+	"tokens", "cost", "phase", "hotkeys",
+}
+
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// ValidateLayoutTemplate rejects templates that use unknown placeholders.
+func ValidateLayoutTemplate(template string) error {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		if !knownPlaceholder(match[1]) {
+			return fmt.Errorf("unknown placeholder {%s} (available: {%s})", match[1], strings.Join(LayoutPlaceholders, "}, {"))
+		}
+	}
+	return nil
+}
+
+func knownPlaceholder(name string) bool {
+	for _, known := range LayoutPlaceholders {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+func (l Layout) withDefaults() Layout {
+	if strings.TrimSpace(l.Title) == "" {
+		l.Title = DefaultTitleTemplate
+	}
+	if strings.TrimSpace(l.Context) == "" {
+		l.Context = DefaultContextTemplate
+	}
+	if strings.TrimSpace(l.Status) == "" {
+		l.Status = DefaultStatusTemplate
+	}
+	if strings.TrimSpace(l.Footer) == "" {
+		l.Footer = DefaultFooterTemplate
+	}
+	return l
+}
+
+// expandTemplate fills placeholders from values, dropping " | " segments
+// whose placeholders are all empty. Unknown placeholders are left as-is.
+func expandTemplate(template string, values map[string]string) string {
+	segments := strings.Split(template, " | ")
+	kept := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		matches := placeholderPattern.FindAllStringSubmatch(segment, -1)
+		filled := len(matches) == 0
+		text := placeholderPattern.ReplaceAllStringFunc(segment, func(token string) string {
+			name := token[1 : len(token)-1]
+			value, ok := values[name]
+			if !ok {
+				return token
+			}
+			if value != "" {
+				filled = true
+			}
+			return value
+		})
+		if filled {
+			kept = append(kept, text)
+		}
+	}
+	return strings.Join(kept, " | ")
+}
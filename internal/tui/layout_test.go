@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExpandTemplateDropsEmptySegments(t *testing.T) {
+	values := map[string]string{"epic": "api", "phase": "", "tokens": "12/--"}
+	got := expandTemplate("Epic: {epic} | Phase: {phase} | Tokens: {tokens} | v1", values)
+	if got != "Epic: api | Tokens: 12/-- | v1" {
+		t.Fatalf("unexpected expansion %q", got)
+	}
+}
+
+func TestValidateLayoutTemplateRejectsUnknownPlaceholder(t *testing.T) {
+	if err := ValidateLayoutTemplate("{status} | {cost}"); err != nil {
+		t.Fatalf("valid template rejected: %v", err)
+	}
+	if err := ValidateLayoutTemplate("{status} | {bogus}"); err == nil || !strings.Contains(err.Error(), "{bogus}") {
+		t.Fatalf("expected unknown placeholder error, got %v", err)
+	}
+}
+
+func TestShellRendersCustomLayout(t *testing.T) {
+	buf := &bytes.Buffer{}
+	shell := NewShell(
+		WithIO(os.Stdin, buf),
+		WithFooterHints([]string{"q: abort"}),
+		WithLayout(Layout{Status: "{tokens} tok | Cost: {cost} | {phase}", Footer: "{hotkeys} | {epic}"}),
+		WithCostRate(2),
+		withTerminal(&fakeTerminal{width: 80, height: 10}),
+	)
+	shell.fd = 0
+	shell.UpdateStatus(func(line *StatusLine) {
+		line.EpicAlias = "api"
+		line.Tokens = TokenUsage{Used: 500000, HasUsed: true}
+	})
+	if err := shell.render(); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "500000/-- tok | Cost: $1.00\n") {
+		t.Fatalf("expected custom status line, got %q", out)
+	}
+	if !strings.Contains(out, "q: abort | api") {
+		t.Fatalf("expected custom footer, got %q", out)
+	}
+}
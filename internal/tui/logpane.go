@@ -1,62 +1,219 @@
 package tui
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
 	"strings"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
 )
 
-const defaultMaxLogs = 500
+const defaultMaxLogs = 5000
+
+// scrollbackMaxBytes bounds the text held in the pane whatever the line
+// cap, so a stream of very long lines cannot exhaust memory.
+const scrollbackMaxBytes = 32 << 20
+
+// historyChunk is how many transcript lines one Page Up past the top of
+// the in-memory buffer loads at most.
+const historyChunk = 1000
+
+// logLine is one rendered row plus the stream and time it arrived on.
+type logLine struct {
+	text      string
+	stream    string
+	at        time.Time
+	separator bool
+	// rewrite marks a line ended by a bare carriage return; collapse state
+	// counts the rewrites folded into it.
+	rewrite bool
+	key     string
+	updates int
+}
 
 type logPane struct {
 	maxLines      int
-	lines         []string
-	partial       string
+	separatorGap  time.Duration
+	collapse      time.Duration
+	origin        time.Time
+	lastBucket    int
+	lines         lineRing
+	bytes         int
+	maxBytes      int
+	partial       logLine
 	scroll        int
 	paused        bool
 	pausedLen     int
-	pausedPartial string
+	pausedPartial logLine
+
+	// dropped counts the transcript lines no longer held in memory: those
+	// evicted from lines, less any reloaded into history. history holds
+	// transcript lines loaded by scrolling past the top of lines; it is
+	// kept only while scrolled up and is discarded on return to the
+	// bottom. loadHistory reads transcript lines [start, end).
+	dropped     int
+	history     []logLine
+	historyRaw  int
+	loadHistory func(start, end int) ([]string, error)
 }
 
 func newLogPane(max int) *logPane {
 	if max <= 0 {
 		max = defaultMaxLogs
 	}
-	return &logPane{maxLines: max}
+	return &logPane{maxLines: max, maxBytes: scrollbackMaxBytes}
 }
 
 func (p *logPane) append(chunk string) {
+	p.appendStream(interactive.StreamStdout, chunk, time.Time{})
+}
+
+// appendStream adds a chunk tagged with its source stream and arrival time. A
+// pending partial line keeps the stream and time of the chunk that started it.
+func (p *logPane) appendStream(stream, chunk string, at time.Time) {
 	if chunk == "" {
 		return
 	}
 	chunk = strings.ReplaceAll(chunk, "\r\n", "\n")
-	chunk = strings.ReplaceAll(chunk, "\r", "\n")
 
-	text := p.partial + chunk
-	lines := strings.Split(text, "\n")
-	if len(lines) == 0 {
+	if p.partial.text == "" {
+		p.partial.stream = stream
+		p.partial.at = at
+	}
+	text := p.partial.text + chunk
+	first := p.partial
+	for i := 0; ; i++ {
+		end := strings.IndexAny(text, "\r\n")
+		if end < 0 {
+			p.partial = logLine{text: text, stream: stream, at: at}
+			return
+		}
+		line := logLine{text: text[:end], stream: stream, at: at, rewrite: text[end] == '\r'}
+		if i == 0 {
+			line.stream, line.at = first.stream, first.at
+		}
+		p.addLine(line)
+		text = text[end+1:]
+	}
+}
+
+// setCollapseWindow enables folding of rapid progress-line rewrites (0
+// disables).
+func (p *logPane) setCollapseWindow(window time.Duration) {
+	if window < 0 {
+		window = 0
+	}
+	p.collapse = window
+}
+
+// collapseInto folds line into the previous row when that row was a
+// carriage-return rewrite of the same progress line within the window.
+func (p *logPane) collapseInto(line logLine) bool {
+	if p.collapse <= 0 || p.lines.len() == 0 {
+		return false
+	}
+	if p.paused && p.pausedLen >= p.lines.len() {
+		return false
+	}
+	last := p.lines.at(p.lines.len() - 1)
+	if !last.rewrite || last.separator || last.key == "" || last.key != interactive.CollapseKey(line.text) {
+		return false
+	}
+	if !line.at.IsZero() && !last.at.IsZero() && line.at.Sub(last.at) > p.collapse {
+		return false
+	}
+	last.updates++
+	p.bytes -= len(last.text)
+	last.text = line.text + interactive.CollapseMarker(last.updates+1)
+	p.bytes += len(last.text)
+	last.rewrite = line.rewrite
+	if !line.at.IsZero() {
+		last.at = line.at
+	}
+	return true
+}
+
+// setSeparatorGap enables elapsed-time separator rows every gap (0 disables).
+func (p *logPane) setSeparatorGap(gap time.Duration) {
+	if gap < 0 {
+		gap = 0
+	}
+	p.separatorGap = gap
+}
+
+func (p *logPane) addLine(line logLine) {
+	if p.collapseInto(line) {
 		return
 	}
+	if p.collapse > 0 {
+		line.key = interactive.CollapseKey(line.text)
+	}
+	p.maybeAddSeparator(line.at)
+	p.pushLine(line)
+}
 
-	p.partial = lines[len(lines)-1]
-	for _, line := range lines[:len(lines)-1] {
-		p.addLine(line)
+// maybeAddSeparator inserts a marker row when at crosses into a new gap-sized
+// bucket measured from the first timestamped line.
+func (p *logPane) maybeAddSeparator(at time.Time) {
+	if p.separatorGap <= 0 || at.IsZero() {
+		return
 	}
+	if p.origin.IsZero() {
+		p.origin = at
+		return
+	}
+	bucket := int(at.Sub(p.origin) / p.separatorGap)
+	if bucket <= p.lastBucket {
+		return
+	}
+	p.lastBucket = bucket
+	elapsed := time.Duration(bucket) * p.separatorGap
+	p.pushLine(logLine{
+		text:      fmt.Sprintf("----- %s elapsed -----", formatElapsed(elapsed)),
+		at:        p.origin.Add(elapsed),
+		separator: true,
+	})
 }
 
-func (p *logPane) addLine(line string) {
-	p.lines = append(p.lines, line)
-	if len(p.lines) > p.maxLines {
-		drop := len(p.lines) - p.maxLines
-		if drop > len(p.lines) {
-			drop = len(p.lines)
-		}
-		p.lines = p.lines[drop:]
+func (p *logPane) pushLine(line logLine) {
+	p.bytes += len(line.text)
+	if evicted, ok := p.lines.push(line, p.maxLines); ok {
+		p.evict(evicted)
+	}
+	for p.bytes > p.maxBytes && p.lines.len() > 1 {
+		p.evict(p.lines.popFront())
 	}
-	if p.paused && p.pausedLen > len(p.lines) {
-		p.pausedLen = len(p.lines)
+	if p.paused && p.pausedLen > p.lines.len() {
+		p.pausedLen = p.lines.len()
 	}
 	p.clampScroll()
 }
 
+// evict accounts for a line leaving the buffer. While history is loaded it
+// moves there so the scrolled-back view stays contiguous.
+func (p *logPane) evict(line logLine) {
+	p.bytes -= len(line.text)
+	if len(p.history) > 0 {
+		p.history = append(p.history, line)
+		p.historyRaw += transcriptLines(line)
+		return
+	}
+	p.dropped += transcriptLines(line)
+}
+
+// transcriptLines is how many transcript lines a pane line stands for:
+// separators and obi's own notices are not in the transcript, and a
+// collapsed progress line folds several.
+func transcriptLines(line logLine) int {
+	if line.separator || line.stream == "" {
+		return 0
+	}
+	return 1 + line.updates
+}
+
 func (p *logPane) clampScroll() {
 	if p.scroll < 0 {
 		p.scroll = 0
@@ -77,23 +234,35 @@ func (p *logPane) clampScroll() {
 }
 
 func (p *logPane) flushPartial() {
-	if p.partial == "" {
+	if p.partial.text == "" {
 		return
 	}
 	p.addLine(p.partial)
-	p.partial = ""
+	p.partial = logLine{}
 }
 
 func (p *logPane) visible(height int) []string {
+	lines := p.visibleLines(height)
+	if lines == nil {
+		return nil
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = line.text
+	}
+	return out
+}
+
+func (p *logPane) visibleLines(height int) []logLine {
 	if height <= 0 {
 		return nil
 	}
-	data := p.currentData()
-	if len(data) == 0 {
+	total := p.bufferLength()
+	if total == 0 {
 		return nil
 	}
 
-	end := len(data) - p.scroll
+	end := total - p.scroll
 	if end < 0 {
 		end = 0
 	}
@@ -104,20 +273,69 @@ func (p *logPane) visible(height int) []string {
 	if start > end {
 		start = end
 	}
-	slice := data[start:end]
-	return append([]string{}, slice...)
+	return p.window(start, end)
 }
 
-func (p *logPane) scrollBy(delta int) {
+// scrollBy returns the error that made earlier transcript lines
+// unavailable, for the caller to log once it holds no shell lock.
+func (p *logPane) scrollBy(delta int) error {
 	if delta == 0 {
-		return
+		return nil
 	}
 	p.scroll += delta
+	var err error
+	// Page Up moves by about a screen, so load once the next page would
+	// run past the oldest line.
+	if delta > 0 && p.scroll+delta > p.bufferLength()-1 {
+		err = p.loadEarlier()
+	}
 	p.clampScroll()
+	if p.scroll == 0 {
+		p.dropHistory()
+	}
+	return err
 }
 
 func (p *logPane) resetScroll() {
 	p.scroll = 0
+	p.dropHistory()
+}
+
+// loadEarlier prepends up to historyChunk transcript lines from just above
+// the oldest line in memory. The line accounting is approximate: Codex
+// output the TUI never received (dropped events) shifts the seam by a few
+// lines.
+func (p *logPane) loadEarlier() error {
+	if p.loadHistory == nil || p.dropped <= 0 {
+		return nil
+	}
+	start := p.dropped - historyChunk
+	if start < 0 {
+		start = 0
+	}
+	texts, err := p.loadHistory(start, p.dropped)
+	if err != nil {
+		// An unreadable transcript will not get better; stop trying.
+		p.loadHistory = nil
+		return err
+	}
+	loaded := make([]logLine, 0, len(texts)+len(p.history))
+	for _, text := range texts {
+		loaded = append(loaded, logLine{text: text, stream: interactive.StreamStdout})
+	}
+	p.history = append(loaded, p.history...)
+	p.historyRaw += len(texts)
+	p.dropped = start
+	return nil
+}
+
+func (p *logPane) dropHistory() {
+	if len(p.history) == 0 {
+		return
+	}
+	p.dropped += p.historyRaw
+	p.history = nil
+	p.historyRaw = 0
 }
 
 func (p *logPane) setMax(max int) {
@@ -125,11 +343,11 @@ func (p *logPane) setMax(max int) {
 		max = defaultMaxLogs
 	}
 	p.maxLines = max
-	if len(p.lines) > p.maxLines {
-		p.lines = p.lines[len(p.lines)-p.maxLines:]
+	for p.lines.len() > p.maxLines {
+		p.evict(p.lines.popFront())
 	}
-	if p.paused && p.pausedLen > len(p.lines) {
-		p.pausedLen = len(p.lines)
+	if p.paused && p.pausedLen > p.lines.len() {
+		p.pausedLen = p.lines.len()
 	}
 	p.clampScroll()
 }
@@ -143,11 +361,11 @@ func (p *logPane) setPaused(paused bool) bool {
 	}
 	p.paused = paused
 	if paused {
-		p.pausedLen = len(p.lines)
+		p.pausedLen = p.lines.len()
 		p.pausedPartial = p.partial
 	} else {
 		p.pausedLen = 0
-		p.pausedPartial = ""
+		p.pausedPartial = logLine{}
 	}
 	p.clampScroll()
 	return p.paused
@@ -157,48 +375,143 @@ func (p *logPane) bufferLength() int {
 	if p == nil {
 		return 0
 	}
-	total := len(p.lines)
+	return len(p.history) + p.liveLength()
+}
+
+// liveLength counts the in-memory lines on view: all of them, or the
+// prefix frozen by pause, plus the pending partial line.
+func (p *logPane) liveLength() int {
+	total := p.lines.len()
+	partial := p.partial
 	if p.paused {
-		limit := p.pausedLen
-		if limit < 0 {
-			limit = 0
-		}
-		if limit > len(p.lines) {
-			limit = len(p.lines)
+		total = p.pausedLen
+		if total < 0 {
+			total = 0
 		}
-		total = limit
-		if p.pausedPartial != "" {
-			total++
+		if total > p.lines.len() {
+			total = p.lines.len()
 		}
-		return total
+		partial = p.pausedPartial
 	}
-	if p.partial != "" {
+	if partial.text != "" {
 		total++
 	}
 	return total
 }
 
-func (p *logPane) currentData() []string {
-	if p == nil {
-		return nil
+// window copies lines [start, end) of history, the live lines, and the
+// partial line taken as one sequence.
+func (p *logPane) window(start, end int) []logLine {
+	out := make([]logLine, 0, end-start)
+	for i := start; i < end; i++ {
+		switch {
+		case i < len(p.history):
+			out = append(out, p.history[i])
+		case i-len(p.history) < p.lines.len() && (!p.paused || i-len(p.history) < p.pausedLen):
+			out = append(out, *p.lines.at(i - len(p.history)))
+		case p.paused:
+			out = append(out, p.pausedPartial)
+		default:
+			out = append(out, p.partial)
+		}
 	}
-	data := append([]string{}, p.lines...)
-	if p.paused {
-		limit := p.pausedLen
-		if limit < 0 {
-			limit = 0
+	return out
+}
+
+// readTranscriptLines returns lines [start, end) of a transcript, splitting
+// on carriage returns as well as newlines the way the pane does.
+func readTranscriptLines(path string, start, end int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []string
+	r := bufio.NewReader(f)
+	var line []byte
+	prevCR := false
+	for n := 0; n < end; {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if b == '\n' && prevCR {
+			prevCR = false
+			continue
 		}
-		if limit > len(data) {
-			limit = len(data)
+		prevCR = b == '\r'
+		if b != '\n' && b != '\r' {
+			if n >= start {
+				line = append(line, b)
+			}
+			continue
 		}
-		data = data[:limit]
-		if p.pausedPartial != "" {
-			data = append(data, p.pausedPartial)
+		if n >= start {
+			out = append(out, string(line))
+			line = line[:0]
 		}
-		return data
+		n++
+	}
+	return out, nil
+}
+
+// lineRing is a FIFO of log lines that grows up to its cap and then
+// overwrites the oldest entry in place.
+type lineRing struct {
+	buf   []logLine
+	start int
+	n     int
+}
+
+func (r *lineRing) len() int { return r.n }
+
+func (r *lineRing) at(i int) *logLine {
+	return &r.buf[(r.start+i)%len(r.buf)]
+}
+
+// push appends line, returning the entry it displaced when the ring was
+// already holding capacity lines.
+func (r *lineRing) push(line logLine, capacity int) (logLine, bool) {
+	if r.n < capacity {
+		if r.n == len(r.buf) {
+			r.compact()
+			r.buf = append(r.buf, line)
+		} else {
+			r.buf[(r.start+r.n)%len(r.buf)] = line
+		}
+		r.n++
+		return logLine{}, false
+	}
+	if r.n == 0 {
+		return line, true
+	}
+	evicted := r.buf[r.start]
+	r.buf[r.start] = line
+	r.start = (r.start + 1) % len(r.buf)
+	return evicted, true
+}
+
+func (r *lineRing) popFront() logLine {
+	line := r.buf[r.start]
+	r.buf[r.start] = logLine{}
+	r.start = (r.start + 1) % len(r.buf)
+	r.n--
+	return line
+}
+
+// compact moves the lines to the front of buf so it can grow by append.
+func (r *lineRing) compact() {
+	if r.start == 0 {
+		return
 	}
-	if p.partial != "" {
-		data = append(data, p.partial)
+	lines := make([]logLine, r.n, r.n+1)
+	for i := range lines {
+		lines[i] = *r.at(i)
 	}
-	return data
+	r.buf = lines
+	r.start = 0
 }
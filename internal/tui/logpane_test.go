@@ -2,7 +2,11 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLogPaneAppendAndVisible(t *testing.T) {
@@ -88,3 +92,151 @@ func TestLogPaneTruncatesWhilePaused(t *testing.T) {
 		t.Fatalf("expected older lines to be dropped once truncated, got %v", lines)
 	}
 }
+
+func TestLogPaneTracksStreamPerLine(t *testing.T) {
+	pane := newLogPane(10)
+	pane.appendStream("stdout", "out one\nout ", time.Time{})
+	pane.appendStream("stderr", "tail\nerr line\n", time.Time{})
+
+	lines := pane.visibleLines(10)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %v", lines)
+	}
+	if lines[1].text != "out tail" || lines[1].stream != "stdout" {
+		t.Fatalf("partial line should keep its original stream, got %+v", lines[1])
+	}
+	if lines[2].text != "err line" || lines[2].stream != "stderr" {
+		t.Fatalf("expected stderr line, got %+v", lines[2])
+	}
+}
+
+func TestLogPaneInsertsElapsedSeparators(t *testing.T) {
+	pane := newLogPane(20)
+	pane.setSeparatorGap(5 * time.Minute)
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	pane.appendStream("stdout", "first\n", start)
+	pane.appendStream("stdout", "second\n", start.Add(2*time.Minute))
+	pane.appendStream("stdout", "after quiet\n", start.Add(16*time.Minute))
+
+	lines := pane.visibleLines(10)
+	if len(lines) != 4 {
+		t.Fatalf("expected 3 lines plus separator, got %+v", lines)
+	}
+	if !lines[2].separator || lines[2].text != "----- 15:00 elapsed -----" {
+		t.Fatalf("expected separator before quiet line, got %+v", lines[2])
+	}
+	if lines[3].text != "after quiet" || !lines[3].at.Equal(start.Add(16*time.Minute)) {
+		t.Fatalf("expected timestamped line after separator, got %+v", lines[3])
+	}
+}
+
+func TestLogPaneCollapsesProgressRewrites(t *testing.T) {
+	pane := newLogPane(10)
+	pane.setCollapseWindow(time.Second)
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		pane.appendStream("stdout", fmt.Sprintf("⠋ Working %ds\r", i), start.Add(time.Duration(i)*100*time.Millisecond))
+	}
+	pane.appendStream("stdout", "done\n", start.Add(time.Second))
+
+	lines := pane.visible(10)
+	if len(lines) != 2 {
+		t.Fatalf("expected spinner folded into one row, got %v", lines)
+	}
+	if lines[0] != "⠋ Working 3s [×4]" || lines[1] != "done" {
+		t.Fatalf("unexpected rows: %v", lines)
+	}
+}
+
+func TestLogPaneCollapseKeepsNewlineLinesAndSlowRewrites(t *testing.T) {
+	pane := newLogPane(10)
+	pane.setCollapseWindow(time.Second)
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	pane.appendStream("stdout", "test 1 ok\ntest 2 ok\n", start)
+	pane.appendStream("stdout", "Working 1s\r", start)
+	pane.appendStream("stdout", "Working 9s\r", start.Add(5*time.Second))
+
+	if lines := pane.visible(10); len(lines) != 4 {
+		t.Fatalf("expected newline-terminated and slow rewrites kept, got %v", lines)
+	}
+}
+
+func TestLogPaneRingKeepsNewestLines(t *testing.T) {
+	pane := newLogPane(4)
+	for i := 0; i < 10; i++ {
+		pane.append(fmt.Sprintf("line%d\n", i))
+	}
+	got := pane.visible(10)
+	want := []string{"line6", "line7", "line8", "line9"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("visible = %v, want %v", got, want)
+	}
+	if pane.dropped != 6 {
+		t.Fatalf("dropped = %d, want 6", pane.dropped)
+	}
+
+	pane.setMax(2)
+	if got := pane.visible(10); fmt.Sprint(got) != "[line8 line9]" {
+		t.Fatalf("after shrink visible = %v", got)
+	}
+	pane.setMax(5)
+	pane.append("line10\nline11\n")
+	if got := pane.visible(10); fmt.Sprint(got) != "[line8 line9 line10 line11]" {
+		t.Fatalf("after grow visible = %v", got)
+	}
+}
+
+func TestLogPaneEvictsPastByteBudget(t *testing.T) {
+	pane := newLogPane(100)
+	pane.maxBytes = 20
+	for i := 0; i < 5; i++ {
+		pane.append(fmt.Sprintf("line-%d...\n", i))
+	}
+	got := pane.visible(10)
+	if len(got) != 2 || got[1] != "line-4..." {
+		t.Fatalf("visible = %v, want the two newest lines", got)
+	}
+	if pane.bytes > pane.maxBytes {
+		t.Fatalf("bytes = %d over budget %d", pane.bytes, pane.maxBytes)
+	}
+}
+
+func TestLogPaneScrollsBackIntoTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.log")
+	var transcript strings.Builder
+	pane := newLogPane(3)
+	pane.loadHistory = func(start, end int) ([]string, error) {
+		return readTranscriptLines(path, start, end)
+	}
+	for i := 0; i < 8; i++ {
+		chunk := fmt.Sprintf("line%d\r\n", i)
+		if i == 2 {
+			chunk = "prog 1\rprog 2\n"
+		}
+		transcript.WriteString(chunk)
+		pane.append(chunk)
+	}
+	if err := os.WriteFile(path, []byte(transcript.String()), 0o600); err != nil {
+		t.Fatalf("write transcript: %v", err)
+	}
+
+	pane.scrollBy(3)
+	got := pane.visible(20)
+	want := []string{"line0", "line1", "prog 1", "prog 2", "line3", "line4"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("visible = %v, want %v", got, want)
+	}
+
+	pane.append("line8\n")
+	if got := pane.visible(20); len(got) != 7 || got[0] != "line0" || got[6] != "line5" {
+		t.Fatalf("evicted line must join history while scrolled back, got %v", got)
+	}
+
+	pane.resetScroll()
+	if len(pane.history) != 0 || pane.dropped != 7 {
+		t.Fatalf("history = %d lines, dropped = %d after reset", len(pane.history), pane.dropped)
+	}
+	if got := pane.visible(20); fmt.Sprint(got) != "[line6 line7 line8]" {
+		t.Fatalf("visible after reset = %v", got)
+	}
+}
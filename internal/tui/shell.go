@@ -7,27 +7,78 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
 )
 
 const (
 	headerLines = 4
+	// activityWindow is how recently output must arrive for the spinner to turn.
+	activityWindow = 3 * time.Second
+	// defaultFrameInterval caps redraws at 30 per second.
+	defaultFrameInterval = time.Second / 30
 )
 
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
 var helpOverlayLines = []string{
 	"Help:",
 	"p - Pause/resume log output",
 	"h - Enter hint mode",
-	"s - Request soft stop",
+	"s - Request soft stop (pick a reason when presets are set)",
 	"q - Abort Codex session",
+	"t - Cycle timestamp gutter (off/clock/relative)",
+	"i - Type a line locally, Enter sends it to Codex",
+	"b - Show/hide the current bead (bd show)",
+	"d - Cycle the diff overlay (--stat, full diff, hidden); r refreshes it, j/k page through it",
+	"w - Toggle wrapping of long log lines",
+	"Ctrl+Z - Suspend obi (Codex pauses too; fg to return)",
 	"? - Toggle this overlay",
 }
 
+// TimestampMode selects what the log gutter shows for each line.
+type TimestampMode int
+
+const (
+	// TimestampsOff hides the gutter.
+	TimestampsOff TimestampMode = iota
+	// TimestampsClock shows wall-clock arrival time (HH:MM:SS).
+	TimestampsClock
+	// TimestampsRelative shows time since the session started.
+	TimestampsRelative
+)
+
+// ParseTimestampMode maps config values ("off", "clock", "relative") to a mode.
+func ParseTimestampMode(value string) (TimestampMode, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "off", "none":
+		return TimestampsOff, nil
+	case "clock", "wall":
+		return TimestampsClock, nil
+	case "relative", "elapsed":
+		return TimestampsRelative, nil
+	default:
+		return TimestampsOff, fmt.Errorf("unknown timestamp mode %q (want off, clock, or relative)", value)
+	}
+}
+
+func (m TimestampMode) next() TimestampMode {
+	switch m {
+	case TimestampsOff:
+		return TimestampsClock
+	case TimestampsClock:
+		return TimestampsRelative
+	default:
+		return TimestampsOff
+	}
+}
+
 // TokenUsage captures Codex token metrics shown in the header.
 type TokenUsage struct {
 	Used     int
@@ -45,6 +96,10 @@ type StatusLine struct {
 	RunStatus string
 	StartedAt time.Time
 	Tokens    TokenUsage
+	// LiveRedacted shows a badge when secrets are scrubbed from the live stream.
+	LiveRedacted bool
+	// Phase is the most recent work phase detected in Codex output.
+	Phase string
 }
 
 func (s StatusLine) beadSummary() string {
@@ -74,6 +129,18 @@ func (s StatusLine) tokensSummary() string {
 	return fmt.Sprintf("%s/%s", used, limit)
 }
 
+// costSummary prices the tokens used so far; it is empty without a rate so
+// a "Cost: {cost}" layout segment stays hidden.
+func (s StatusLine) costSummary(usdPerMTok float64) string {
+	if usdPerMTok <= 0 {
+		return ""
+	}
+	if !s.Tokens.HasUsed {
+		return "--"
+	}
+	return fmt.Sprintf("$%.2f", float64(s.Tokens.Used)/1e6*usdPerMTok)
+}
+
 func (s StatusLine) elapsed(now time.Time) string {
 	if s.StartedAt.IsZero() || now.Before(s.StartedAt) {
 		return "00:00"
@@ -109,10 +176,17 @@ type Shell struct {
 
 	header string
 	footer []string
+	layout Layout
+	theme  Theme
+	// usdPerMTok prices tokens for the {cost} layout placeholder (0 hides it).
+	usdPerMTok float64
 
 	pane *logPane
 
 	renderCh chan struct{}
+	// frameInterval caps the redraw rate; render requests arriving faster
+	// are coalesced into the next frame.
+	frameInterval time.Duration
 
 	mu         sync.Mutex
 	session    interactive.SessionState
@@ -122,7 +196,54 @@ type Shell struct {
 	help       bool
 	hintActive bool
 	hintText   string
+	lineActive bool
+	lineText   string
+	// approval is the escalation request awaiting y/n, if any.
+	approval   string
 	status     StatusLine
+	timestamps TimestampMode
+	wrap       bool
+	// suspended is set while obi is stopped by Ctrl+Z; nothing is drawn.
+	suspended bool
+	// stopPicking shows the soft-stop quick-pick over stopReasons;
+	// stopTyping is free-text entry of a reason.
+	stopPicking bool
+	stopReasons []string
+	stopTyping  bool
+	stopText    string
+	// lastFrame holds the rows on screen so render can repaint only those
+	// that changed; nil forces a full redraw.
+	lastFrame []string
+	frameSize [2]int
+
+	// beadView shows the current bead's details; beadFetchSeq discards
+	// lookups that finish after the overlay was closed or reopened.
+	beadFetch    BeadFetcher
+	beadView     bool
+	beadFetchSeq int
+	beadDetails  *BeadDetails
+	beadErr      error
+
+	// diffView shows the changes made so far; diffFetchSeq discards fetches
+	// that finish after the overlay was switched, refreshed or closed.
+	diffFetch    DiffFetcher
+	diffView     diffViewMode
+	diffFetchSeq int
+	diffText     *string
+	diffErr      error
+	// diffScroll is the first diff line the overlay shows.
+	diffScroll int
+
+	now           func() time.Time
+	lastOutput    time.Time
+	spinFrame     int
+	stallAfter    time.Duration
+	stallHandler  func(silence time.Duration)
+	stallNotified bool
+
+	// warnings fill the obi message area; see Warn.
+	warnings  []shellWarning
+	warnTotal int
 }
 
 // Option configures a Shell.
@@ -142,6 +263,77 @@ func WithFooterHints(hints []string) Option {
 	}
 }
 
+// WithLayout replaces the header status lines and footer legend with
+// templates; see Layout for the placeholder rules.
+func WithLayout(layout Layout) Option {
+	return func(s *Shell) {
+		s.layout = layout
+	}
+}
+
+// WithCostRate prices token usage for the {cost} placeholder at usdPerMTok
+// US dollars per million tokens.
+func WithCostRate(usdPerMTok float64) Option {
+	return func(s *Shell) {
+		s.usdPerMTok = usdPerMTok
+	}
+}
+
+// WithTheme sets the color palette used for the header, status, and logs.
+func WithTheme(theme Theme) Option {
+	return func(s *Shell) {
+		s.theme = theme
+	}
+}
+
+// WithTimestamps sets the initial log gutter mode.
+func WithTimestamps(mode TimestampMode) Option {
+	return func(s *Shell) {
+		s.timestamps = mode
+	}
+}
+
+// WithLogSeparators inserts an elapsed-time marker row every gap (0 disables).
+func WithLogSeparators(gap time.Duration) Option {
+	return func(s *Shell) {
+		s.ensurePane()
+		s.pane.setSeparatorGap(gap)
+	}
+}
+
+// WithProgressCollapse folds carriage-return rewrites of the same progress
+// line arriving within window into one row with an update counter (0
+// disables).
+func WithProgressCollapse(window time.Duration) Option {
+	return func(s *Shell) {
+		s.ensurePane()
+		s.pane.setCollapseWindow(window)
+	}
+}
+
+// WithStallDetection shows a "no output" warning once Codex has been silent for
+// threshold (0 disables). The optional handler fires once per silent stretch.
+func WithStallDetection(threshold time.Duration, handler func(silence time.Duration)) Option {
+	return func(s *Shell) {
+		s.stallAfter = threshold
+		s.stallHandler = handler
+	}
+}
+
+// WithLineWrap soft-wraps log lines wider than the terminal onto extra rows
+// instead of truncating them.
+func WithLineWrap(enabled bool) Option {
+	return func(s *Shell) {
+		s.wrap = enabled
+	}
+}
+
+func withClock(now func() time.Time) Option {
+	return func(s *Shell) {
+		s.now = now
+	}
+}
+
 // WithMaxLogs caps the number of log lines buffered in the pane.
 func WithMaxLogs(max int) Option {
 	return func(s *Shell) {
@@ -150,6 +342,21 @@ func WithMaxLogs(max int) Option {
 	}
 }
 
+// WithScrollbackFile lets Page Up continue past the oldest buffered line by
+// reading earlier output back from the session transcript at path.
+func WithScrollbackFile(path string) Option {
+	return func(s *Shell) {
+		s.ensurePane()
+		if path == "" {
+			s.pane.loadHistory = nil
+			return
+		}
+		s.pane.loadHistory = func(start, end int) ([]string, error) {
+			return readTranscriptLines(path, start, end)
+		}
+	}
+}
+
 // WithIO overrides the default stdin/stdout handles.
 func WithIO(in *os.File, out io.Writer) Option {
 	return func(s *Shell) {
@@ -217,6 +424,12 @@ func NewShell(opts ...Option) *Shell {
 	if sh.term == nil {
 		sh.term = systemTerminal{}
 	}
+	if sh.now == nil {
+		sh.now = time.Now
+	}
+	if sh.frameInterval == 0 {
+		sh.frameInterval = defaultFrameInterval
+	}
 	return sh
 }
 
@@ -253,25 +466,66 @@ func (s *Shell) Run(ctx context.Context, events <-chan interactive.SessionEvent)
 	}
 	defer s.restoreTerminal()
 
+	jobSignals := make(chan os.Signal, 2)
+	if suspendSignal != nil {
+		signal.Notify(jobSignals, suspendSignal, continueSignal)
+		defer signal.Stop(jobSignals)
+	}
+
 	if err := s.render(); err != nil {
 		return err
 	}
 
+	// Renders are coalesced: a request inside frameInterval of the last
+	// frame arms a timer instead, and everything that arrives before it
+	// fires is drawn together.
+	lastFrame := time.Now()
+	var frameTimer *time.Timer
+	var frameDue <-chan time.Time
+	defer func() {
+		if frameTimer != nil {
+			frameTimer.Stop()
+		}
+	}()
+	scheduleRender := func() error {
+		if frameDue != nil {
+			return nil
+		}
+		wait := s.frameInterval - time.Since(lastFrame)
+		if wait <= 0 {
+			lastFrame = time.Now()
+			return s.render()
+		}
+		frameTimer = time.NewTimer(wait)
+		frameDue = frameTimer.C
+		return nil
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-s.renderCh:
+		case sig := <-jobSignals:
+			if err := s.handleJobSignal(sig); err != nil {
+				return err
+			}
+		case <-frameDue:
+			frameDue = nil
+			lastFrame = time.Now()
 			if err := s.render(); err != nil {
 				return err
 			}
+		case <-s.renderCh:
+			if err := scheduleRender(); err != nil {
+				return err
+			}
 		case evt, ok := <-events:
 			if !ok {
 				s.flushPending()
 				return s.render()
 			}
 			s.HandleEvent(evt)
-			if err := s.render(); err != nil {
+			if err := scheduleRender(); err != nil {
 				return err
 			}
 		}
@@ -291,11 +545,24 @@ func (s *Shell) HandleEvent(evt interactive.SessionEvent) {
 
 	switch evt.Type {
 	case interactive.EventLogChunk:
-		s.pane.append(evt.Chunk)
+		at := evt.Time
+		if at.IsZero() {
+			at = s.now()
+		}
+		s.pane.appendStream(evt.Stream, evt.Chunk, at)
+		if evt.Stream != "" {
+			// Only Codex output counts as activity; obi's own notices do not.
+			s.lastOutput = at
+			s.stallNotified = false
+		}
 	case interactive.EventStateChange:
 		if evt.State != "" {
 			s.session = evt.State
 		}
+	case interactive.EventPhaseChange:
+		if evt.Phase != "" {
+			s.status.Phase = string(evt.Phase)
+		}
 	case interactive.EventExit:
 		s.pane.flushPartial()
 		s.exitLabel = formatExit(evt)
@@ -308,8 +575,13 @@ func (s *Shell) HandleEvent(evt interactive.SessionEvent) {
 // Scroll adjusts the log pane offset (positive scrolls upward).
 func (s *Shell) Scroll(delta int) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.pane.scrollBy(delta)
+	err := s.pane.scrollBy(delta)
+	s.mu.Unlock()
+	// Logged unlocked: while the shell is up, diag hands warnings back to
+	// Warn, which takes s.mu.
+	if err != nil {
+		diag.Logger().Warn("scrollback past the buffer is unavailable", "err", err)
+	}
 }
 
 // TogglePause flips the paused state and returns the updated value.
@@ -333,6 +605,25 @@ func (s *Shell) Paused() bool {
 	return s.paused
 }
 
+// CycleTimestamps advances the gutter through off, clock, and relative modes.
+func (s *Shell) CycleTimestamps() TimestampMode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timestamps = s.timestamps.next()
+	s.requestRenderLocked()
+	return s.timestamps
+}
+
+// ToggleWrap switches long log lines between soft wrapping and truncation,
+// returning whether wrapping is now on.
+func (s *Shell) ToggleWrap() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wrap = !s.wrap
+	s.requestRenderLocked()
+	return s.wrap
+}
+
 // SetHintInput toggles hint-entry mode and updates the visible text.
 func (s *Shell) SetHintInput(active bool, text string) {
 	s.mu.Lock()
@@ -346,6 +637,34 @@ func (s *Shell) SetHintInput(active bool, text string) {
 	s.requestRenderLocked()
 }
 
+// SetLineInput toggles local line-editing mode and updates the visible text.
+func (s *Shell) SetLineInput(active bool, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lineActive = active
+	if active {
+		s.lineText = text
+	} else {
+		s.lineText = ""
+	}
+	s.requestRenderLocked()
+}
+
+// SetApprovalPrompt shows or clears the escalation approval modal.
+func (s *Shell) SetApprovalPrompt(active bool, request string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if active {
+		s.approval = request
+		if strings.TrimSpace(s.approval) == "" {
+			s.approval = "(unknown command)"
+		}
+	} else {
+		s.approval = ""
+	}
+	s.requestRenderLocked()
+}
+
 // HintInput reports the currently visible hint text.
 func (s *Shell) HintInput() (text string, active bool) {
 	s.mu.Lock()
@@ -412,18 +731,21 @@ func (s *Shell) enterRawMode() error {
 	}
 	s.fd = fd
 	s.state = st
-	s.writeAnsi("\x1b[?25l") // hide cursor
+	trackRaw(s, rawTerminal{term: s.term, fd: fd, state: st, out: s.out})
+	s.writeAnsi("\x1b[?25l")   // hide cursor
+	s.writeAnsi("\x1b[?2004h") // enable bracketed paste
 	s.measureSizeLocked()
+	s.lastFrame = nil
 	return nil
 }
 
 func (s *Shell) restoreTerminal() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if s.term != nil && s.state != nil && s.fd >= 0 {
-		_ = s.term.restore(s.fd, s.state)
+	// RestoreTerminal may have reset the screen already after a panic.
+	if raw, ok := untrackRaw(s); ok {
+		raw.reset()
 	}
-	s.writeAnsi("\x1b[?25h\x1b[0m")
 }
 
 func (s *Shell) writeAnsi(seq string) {
@@ -454,7 +776,12 @@ func (s *Shell) render() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.suspended {
+		return nil
+	}
 	s.measureSizeLocked()
+	s.checkStallLocked()
+	s.pruneWarningsLocked()
 
 	hintLines := s.hintLineCountLocked()
 	footerHeight := s.footerHeightLocked()
@@ -462,31 +789,133 @@ func (s *Shell) render() error {
 	if viewHeight < 1 {
 		viewHeight = 1
 	}
-	logs := s.pane.visible(viewHeight)
+	var rows []string
+	for _, line := range s.pane.visibleLines(viewHeight) {
+		rows = append(rows, s.renderLogRowsLocked(line)...)
+	}
+	if len(rows) > viewHeight {
+		rows = rows[len(rows)-viewHeight:]
+	}
 
-	var buf bytes.Buffer
-	buf.WriteString("\x1b[2J\x1b[H")
-	buf.WriteString(s.renderHeaderLocked())
-	for _, line := range logs {
-		buf.WriteString(truncateToWidth(line, s.width))
-		buf.WriteByte('\n')
+	var frame bytes.Buffer
+	frame.WriteString(s.renderHeaderLocked())
+	for _, row := range rows {
+		frame.WriteString(row)
+		frame.WriteByte('\n')
 	}
-	padLines := viewHeight - len(logs)
+	padLines := viewHeight - len(rows)
 	for i := 0; i < padLines; i++ {
-		buf.WriteByte('\n')
+		frame.WriteByte('\n')
 	}
 	if hintLines > 0 {
-		buf.WriteString(s.renderHintLocked())
+		frame.WriteString(s.renderHintLocked())
 	}
-	buf.WriteByte('\n')
-	buf.WriteString(s.renderFooterLocked())
+	frame.WriteByte('\n')
+	frame.WriteString(s.renderFooterLocked())
 
+	buf := s.frameUpdateLocked(frame.String())
+	if buf.Len() == 0 {
+		return nil
+	}
 	if _, err := buf.WriteTo(s.out); err != nil {
+		s.lastFrame = nil
 		return fmt.Errorf("render tui: %w", err)
 	}
 	return nil
 }
 
+// frameUpdateLocked returns what to write to turn the previous frame into
+// this one. Each row of the frame is one screen row, so when the size and
+// row count are unchanged only the rows that differ are rewritten in place;
+// otherwise the screen is cleared and redrawn. An identical frame writes
+// nothing.
+func (s *Shell) frameUpdateLocked(frame string) *bytes.Buffer {
+	rows := strings.Split(frame, "\n")
+	size := [2]int{s.width, s.height}
+	var buf bytes.Buffer
+	if s.lastFrame == nil || s.frameSize != size || len(rows) != len(s.lastFrame) {
+		buf.WriteString("\x1b[2J\x1b[H")
+		buf.WriteString(frame)
+	} else {
+		for i, row := range rows {
+			if row != s.lastFrame[i] {
+				fmt.Fprintf(&buf, "\x1b[%d;1H%s\x1b[K", i+1, row)
+			}
+		}
+	}
+	s.lastFrame = rows
+	s.frameSize = size
+	return &buf
+}
+
+// wrapContinuation starts each continuation row of a wrapped log line;
+// truncationMarker ends a log line cut at the terminal edge.
+const (
+	wrapContinuation = "↳ "
+	truncationMarker = "…"
+)
+
+// renderLogRowsLocked renders one log line as a single truncated row, or as
+// several rows when soft wrapping is on. Continuation rows get a blank gutter
+// followed by wrapContinuation.
+func (s *Shell) renderLogRowsLocked(line logLine) []string {
+	if !s.wrap || line.separator {
+		return []string{s.renderLogLineLocked(line)}
+	}
+	gutter := s.gutterLocked(line)
+	indent := strings.Repeat(" ", displayWidth(gutter)) + wrapContinuation
+	var rows []string
+	for i, text := range wrapToWidth(gutter+line.text, s.width, indent) {
+		prefix := gutter
+		if i > 0 {
+			prefix = indent
+		}
+		rows = append(rows, s.paintLogRowLocked(line, prefix, text))
+	}
+	return rows
+}
+
+func (s *Shell) renderLogLineLocked(line logLine) string {
+	gutter := s.gutterLocked(line)
+	return s.paintLogRowLocked(line, gutter, clipToWidth(gutter+line.text, s.width, truncationMarker))
+}
+
+func (s *Shell) paintLogRowLocked(line logLine, gutter, text string) string {
+	switch {
+	case line.separator:
+		return s.theme.paint(s.theme.Dim, text)
+	case line.stream == interactive.StreamStderr:
+		return s.theme.paint(s.theme.Dim, text)
+	case gutter != "" && strings.HasPrefix(text, gutter):
+		return s.theme.paint(s.theme.Dim, gutter) + text[len(gutter):]
+	default:
+		return text
+	}
+}
+
+// gutterLocked formats the timestamp prefix for a log line.
+func (s *Shell) gutterLocked(line logLine) string {
+	if s.timestamps == TimestampsOff || line.separator {
+		return ""
+	}
+	if line.at.IsZero() {
+		return strings.Repeat(" ", 9) + "| "
+	}
+	switch s.timestamps {
+	case TimestampsClock:
+		return line.at.Format("15:04:05") + " | "
+	default:
+		origin := s.status.StartedAt
+		if origin.IsZero() {
+			origin = s.pane.origin
+		}
+		if origin.IsZero() || line.at.Before(origin) {
+			return fmt.Sprintf("%8s | ", "+00:00")
+		}
+		return fmt.Sprintf("%8s | ", "+"+formatElapsed(line.at.Sub(origin)))
+	}
+}
+
 func (s *Shell) renderHeaderLocked() string {
 	title := s.header
 	if title == "" {
@@ -500,8 +929,6 @@ func (s *Shell) renderHeaderLocked() string {
 	if epicID == "" {
 		epicID = "-"
 	}
-	bead := s.status.beadSummary()
-	line2 := fmt.Sprintf("Epic: %s (%s) | Bead: %s", alias, epicID, bead)
 
 	statusText := strings.TrimSpace(s.status.RunStatus)
 	if statusText == "" {
@@ -517,24 +944,138 @@ func (s *Shell) renderHeaderLocked() string {
 	if s.paused {
 		segments = append(segments, "PAUSED")
 	}
-	status := strings.Join(segments, "  *  ")
-	elapsed := s.status.elapsed(time.Now())
-	tokens := s.status.tokensSummary()
-	line3 := fmt.Sprintf("Status: %s | Elapsed: %s | Tokens: %s", status, elapsed, tokens)
+	if s.status.LiveRedacted {
+		segments = append(segments, "REDACTED")
+	}
+	if spinner := s.spinnerLocked(); spinner != "" {
+		segments[0] = spinner + " " + segments[0]
+	}
+	stallText := ""
+	if silence, stalled := s.stalledLocked(); stalled {
+		stallText = fmt.Sprintf("no output for %s (h: hint, s: soft stop)", formatSilence(silence))
+		segments = append(segments, stallText)
+	}
+
+	values := s.layoutValuesLocked()
+	values["title"] = title
+	values["epic"] = alias
+	values["epic_id"] = epicID
+	values["status"] = strings.Join(segments, "  *  ")
+
+	layout := s.layout.withDefaults()
+	line1 := truncateToWidth(expandTemplate(layout.Title, values), s.width)
+	line2 := truncateToWidth(expandTemplate(layout.Context, values), s.width)
+	line3 := truncateToWidth(expandTemplate(layout.Status, values), s.width)
+	line3 = paintFirst(line3, statusText, s.theme, s.theme.statusColor(statusText))
+	line3 = paintFirst(line3, stallText, s.theme, s.theme.Warning)
 	return fmt.Sprintf("%s\n%s\n%s\n\n",
-		truncateToWidth(title, s.width),
-		truncateToWidth(line2, s.width),
-		truncateToWidth(line3, s.width),
+		s.theme.paint(s.theme.Accent, line1),
+		line2,
+		line3,
 	)
 }
 
+// layoutValuesLocked fills the placeholders that do not depend on header
+// defaults; the header adds title, epic, and status on top.
+func (s *Shell) layoutValuesLocked() map[string]string {
+	return map[string]string{
+		"title":   s.header,
+		"epic":    strings.TrimSpace(s.status.EpicAlias),
+		"epic_id": strings.TrimSpace(s.status.EpicID),
+		"bead":    s.status.beadSummary(),
+		"bead_id": strings.TrimSpace(s.status.BeadID),
+		"status":  strings.TrimSpace(s.status.RunStatus),
+		"elapsed": s.status.elapsed(s.now()),
+		"tokens":  s.status.tokensSummary(),
+		"cost":    s.status.costSummary(s.usdPerMTok),
+		"phase":   strings.TrimSpace(s.status.Phase),
+		"hotkeys": strings.Join(s.footer, "  *  "),
+	}
+}
+
+// spinnerLocked returns the next activity frame while output is flowing, a
+// frozen frame while the session is quiet, and nothing once Codex exits.
+func (s *Shell) spinnerLocked() string {
+	if s.session == interactive.StateExited || s.status.StartedAt.IsZero() {
+		return ""
+	}
+	if !s.lastOutput.IsZero() && s.now().Sub(s.lastOutput) < activityWindow {
+		s.spinFrame = (s.spinFrame + 1) % len(spinnerFrames)
+	}
+	return spinnerFrames[s.spinFrame]
+}
+
+// stalledLocked reports how long Codex has been silent when that exceeds the
+// configured threshold.
+func (s *Shell) stalledLocked() (time.Duration, bool) {
+	if s.stallAfter <= 0 || s.session == interactive.StateExited {
+		return 0, false
+	}
+	since := s.lastOutput
+	if since.IsZero() {
+		since = s.status.StartedAt
+	}
+	if since.IsZero() {
+		return 0, false
+	}
+	silence := s.now().Sub(since)
+	if silence < s.stallAfter {
+		return 0, false
+	}
+	return silence, true
+}
+
+func (s *Shell) checkStallLocked() {
+	silence, stalled := s.stalledLocked()
+	if !stalled || s.stallNotified {
+		return
+	}
+	s.stallNotified = true
+	if s.stallHandler != nil {
+		// Run outside the render lock so the handler may call back into the shell.
+		go s.stallHandler(silence)
+	}
+}
+
+func formatSilence(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	}
+	return fmt.Sprintf("%dm", int(d/time.Minute))
+}
+
+// paintFirst styles the first occurrence of target inside an already
+// truncated line so width calculations never count escape sequences.
+func paintFirst(line, target string, theme Theme, sgr string) string {
+	if sgr == "" || target == "" {
+		return line
+	}
+	idx := strings.Index(line, target)
+	if idx == -1 {
+		return line
+	}
+	return line[:idx] + theme.paint(sgr, target) + line[idx+len(target):]
+}
+
 func (s *Shell) renderFooterLocked() string {
-	var lines []string
-	if len(s.footer) > 0 {
-		lines = append(lines, fmt.Sprintf("Hotkeys: %s", strings.Join(s.footer, "  *  ")))
+	lines := s.warningLinesLocked()
+	if len(s.footer) > 0 || s.layout.Footer != "" {
+		legend := truncateToWidth(expandTemplate(s.layout.withDefaults().Footer, s.layoutValuesLocked()), s.width)
+		lines = append(lines, paintFirst(legend, "Hotkeys:", s.theme, s.theme.Accent))
 	}
 	if s.help {
-		lines = append(lines, helpOverlayLines...)
+		for _, line := range helpOverlayLines {
+			lines = append(lines, truncateToWidth(line, s.width))
+		}
+	}
+	for _, line := range s.beadOverlayLinesLocked() {
+		lines = append(lines, truncateToWidth(line, s.width))
+	}
+	for _, line := range s.diffOverlayLinesLocked() {
+		lines = append(lines, truncateToWidth(line, s.width))
+	}
+	for _, line := range s.softStopOverlayLinesLocked() {
+		lines = append(lines, truncateToWidth(line, s.width))
 	}
 	if len(lines) == 0 {
 		return "\n"
@@ -552,28 +1093,41 @@ func (s *Shell) footerHeightLocked() int {
 }
 
 func (s *Shell) footerLineCountLocked() int {
-	lines := 0
-	if len(s.footer) > 0 {
+	lines := len(s.warnings)
+	if len(s.footer) > 0 || s.layout.Footer != "" {
 		lines++
 	}
 	if s.help {
 		lines += len(helpOverlayLines)
 	}
+	lines += len(s.beadOverlayLinesLocked())
+	lines += len(s.diffOverlayLinesLocked())
+	lines += len(s.softStopOverlayLinesLocked())
 	return lines
 }
 
 func (s *Shell) hintLineCountLocked() int {
-	if s.hintActive {
+	if s.approval != "" || s.hintActive || s.lineActive || s.stopPicking || s.stopTyping {
 		return 1
 	}
 	return 0
 }
 
 func (s *Shell) renderHintLocked() string {
-	if !s.hintActive {
+	var line string
+	switch {
+	case s.approval != "":
+		line = fmt.Sprintf("Codex requests approval (y=approve, n/Esc=deny): %s", s.approval)
+		return paintFirst(truncateToWidth(line, s.width), "Codex requests approval", s.theme, s.theme.Warning) + "\n"
+	case s.hintActive:
+		line = fmt.Sprintf("Hint (Enter=send, Esc=cancel): %s", s.hintText)
+	case s.lineActive:
+		line = fmt.Sprintf("Input (Enter=send to Codex, Esc=cancel): %s", s.lineText)
+	case s.stopPicking || s.stopTyping:
+		line = s.softStopPromptLocked()
+	default:
 		return ""
 	}
-	line := fmt.Sprintf("Hint (Enter=send, Esc=cancel): %s", s.hintText)
 	return truncateToWidth(line, s.width) + "\n"
 }
 
@@ -584,17 +1138,6 @@ func formatExit(evt interactive.SessionEvent) string {
 	return fmt.Sprintf("exit %d", evt.ExitCode)
 }
 
-func truncateToWidth(line string, width int) string {
-	if width <= 0 {
-		return line
-	}
-	runes := []rune(line)
-	if len(runes) <= width {
-		return line
-	}
-	return string(runes[:width])
-}
-
 type termAdapter interface {
 	makeRaw(fd int) (*termState, error)
 	restore(fd int, state *termState) error
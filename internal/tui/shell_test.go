@@ -3,10 +3,13 @@ package tui
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
 )
@@ -56,6 +59,17 @@ func TestShellHandleEventUpdatesPane(t *testing.T) {
 	}
 }
 
+func TestShellPhaseChangeShowsInHeader(t *testing.T) {
+	shell := NewShell(WithIO(os.Stdin, io.Discard), withTerminal(&fakeTerminal{width: 120, height: 10}))
+	shell.HandleEvent(interactive.SessionEvent{Type: interactive.EventPhaseChange, Phase: interactive.PhaseTesting})
+	shell.mu.Lock()
+	header := shell.renderHeaderLocked()
+	shell.mu.Unlock()
+	if !strings.Contains(header, "Phase: testing") {
+		t.Fatalf("expected phase in header, got %q", header)
+	}
+}
+
 func TestShellTogglePauseFreezesView(t *testing.T) {
 	buf := &bytes.Buffer{}
 	term := &fakeTerminal{width: 60, height: 12}
@@ -112,6 +126,34 @@ func TestShellRenderShowsHintInput(t *testing.T) {
 	}
 }
 
+func TestShellRendersSoftStopQuickPick(t *testing.T) {
+	buf := &bytes.Buffer{}
+	term := &fakeTerminal{width: 80, height: 20}
+	shell := NewShell(WithIO(os.Stdin, buf), withTerminal(term))
+	shell.fd = 0
+
+	shell.SetSoftStopPicker(true, []string{"meeting starting", "wrong approach"})
+	if err := shell.render(); err != nil {
+		t.Fatalf("render with picker: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"1. meeting starting", "2. wrong approach", "e. Type a reason", "1-2=pick"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in quick-pick, got %q", want, output)
+		}
+	}
+
+	buf.Reset()
+	shell.SetSoftStopPicker(false, nil)
+	shell.SetSoftStopInput(true, "flaky CI")
+	if err := shell.render(); err != nil {
+		t.Fatalf("render with typed reason: %v", err)
+	}
+	if output := buf.String(); !strings.Contains(output, "Soft stop reason (Enter=send, Esc=cancel): flaky CI") || strings.Contains(output, "meeting starting") {
+		t.Fatalf("expected free-text prompt only, got %q", output)
+	}
+}
+
 func TestShellToggleHelpOverlay(t *testing.T) {
 	buf := &bytes.Buffer{}
 	term := &fakeTerminal{width: 80, height: 20}
@@ -165,9 +207,11 @@ type fakeTerminal struct {
 	width        int
 	height       int
 	restoreCount int
+	rawCount     int
 }
 
 func (f *fakeTerminal) makeRaw(int) (*termState, error) {
+	f.rawCount++
 	return &termState{}, nil
 }
 
@@ -183,3 +227,203 @@ func (f *fakeTerminal) getSize(int) (int, int, error) {
 func makeExitEvent(code int, err error) interactive.SessionEvent {
 	return interactive.SessionEvent{Type: interactive.EventExit, ExitCode: code, Error: err}
 }
+
+func TestShellRendersTimestampGutter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	shell := NewShell(
+		WithIO(os.Stdin, buf),
+		WithTimestamps(TimestampsRelative),
+		withTerminal(&fakeTerminal{width: 80, height: 12}),
+	)
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	shell.UpdateStatus(func(line *StatusLine) { line.StartedAt = start })
+
+	events := make(chan interactive.SessionEvent, 1)
+	events <- interactive.SessionEvent{Type: interactive.EventLogChunk, Time: start.Add(90 * time.Second), Chunk: "compiling\n"}
+	close(events)
+	if err := shell.Run(context.Background(), events); err != nil {
+		t.Fatalf("shell run: %v", err)
+	}
+	if !strings.Contains(buf.String(), "  +01:30 | compiling") {
+		t.Fatalf("expected relative gutter, got %q", buf.String())
+	}
+
+	if mode := shell.CycleTimestamps(); mode != TimestampsOff {
+		t.Fatalf("expected relative to cycle to off, got %v", mode)
+	}
+}
+
+func TestShellFlagsStallAfterThreshold(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	now := start
+	fired := make(chan time.Duration, 1)
+	buf := &bytes.Buffer{}
+	shell := NewShell(
+		WithIO(os.Stdin, buf),
+		withTerminal(&fakeTerminal{width: 120, height: 12}),
+		withClock(func() time.Time { return now }),
+		WithStallDetection(2*time.Minute, func(silence time.Duration) { fired <- silence }),
+	)
+	shell.UpdateStatus(func(line *StatusLine) { line.StartedAt = start })
+	shell.HandleEvent(interactive.SessionEvent{Type: interactive.EventLogChunk, Stream: interactive.StreamStdout, Time: start, Chunk: "working\n"})
+
+	now = start.Add(time.Minute)
+	if err := shell.render(); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if strings.Contains(buf.String(), "no output for") {
+		t.Fatalf("did not expect stall warning yet: %q", buf.String())
+	}
+
+	now = start.Add(3 * time.Minute)
+	buf.Reset()
+	if err := shell.render(); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(buf.String(), "no output for 3m") {
+		t.Fatalf("expected stall warning, got %q", buf.String())
+	}
+	select {
+	case silence := <-fired:
+		if silence != 3*time.Minute {
+			t.Fatalf("unexpected silence %v", silence)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected stall handler to fire")
+	}
+
+	shell.HandleEvent(interactive.SessionEvent{Type: interactive.EventLogChunk, Stream: interactive.StreamStdout, Time: now, Chunk: "back\n"})
+	buf.Reset()
+	if err := shell.render(); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if strings.Contains(buf.String(), "no output for") {
+		t.Fatalf("stall warning should clear after new output: %q", buf.String())
+	}
+}
+
+func TestShellSoftWrapsLongLogLines(t *testing.T) {
+	buf := &bytes.Buffer{}
+	shell := NewShell(
+		WithIO(os.Stdin, buf),
+		WithTimestamps(TimestampsOff),
+		WithLineWrap(true),
+		withTerminal(&fakeTerminal{width: 10, height: 14}),
+	)
+	events := make(chan interactive.SessionEvent, 1)
+	events <- interactive.SessionEvent{Type: interactive.EventLogChunk, Chunk: "第一行的内容很长很长\n"}
+	close(events)
+	if err := shell.Run(context.Background(), events); err != nil {
+		t.Fatalf("shell run: %v", err)
+	}
+	out := buf.String()
+	// The rows arrive as an in-place update of rows 5 to 7.
+	if !strings.Contains(out, "\x1b[5;1H第一行的内\x1b[K\x1b[6;1H↳ 容很长很\x1b[K\x1b[7;1H↳ 长\x1b[K") {
+		t.Fatalf("expected wrapped CJK rows, got %q", out)
+	}
+}
+
+func TestShellToggleWrapSwitchesToMarkedTruncation(t *testing.T) {
+	buf := &bytes.Buffer{}
+	shell := NewShell(
+		WithIO(os.Stdin, buf),
+		WithTimestamps(TimestampsOff),
+		WithLineWrap(true),
+	)
+	shell.width, shell.height = 10, 14
+	shell.HandleEvent(interactive.SessionEvent{Type: interactive.EventLogChunk, Chunk: "0123456789abcdef\n"})
+	if err := shell.render(); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(buf.String(), "↳ abcdef") {
+		t.Fatalf("expected a continuation row, got %q", buf.String())
+	}
+
+	if shell.ToggleWrap() {
+		t.Fatal("expected wrapping to turn off")
+	}
+	buf.Reset()
+	if err := shell.render(); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "012345678…") || strings.Contains(out, "abcdef") {
+		t.Fatalf("expected a marked truncated row, got %q", out)
+	}
+}
+
+func TestShellRenderRepaintsOnlyChangedRows(t *testing.T) {
+	buf := &bytes.Buffer{}
+	term := &fakeTerminal{width: 60, height: 12}
+	shell := NewShell(WithIO(os.Stdin, buf), withTerminal(term), WithTimestamps(TimestampsOff))
+	shell.fd = 0
+
+	shell.HandleEvent(interactive.SessionEvent{Type: interactive.EventLogChunk, Chunk: "first line\n"})
+	if err := shell.render(); err != nil {
+		t.Fatalf("first render: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "\x1b[2J") {
+		t.Fatalf("expected first frame to clear the screen, got %q", buf.String())
+	}
+
+	buf.Reset()
+	shell.HandleEvent(interactive.SessionEvent{Type: interactive.EventLogChunk, Chunk: "second line\n"})
+	if err := shell.render(); err != nil {
+		t.Fatalf("second render: %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "\x1b[2J") || strings.Contains(out, "Obi Interactive Session") {
+		t.Fatalf("expected only changed rows to be repainted, got %q", out)
+	}
+	if !strings.Contains(out, "second line\x1b[K") {
+		t.Fatalf("expected the new row in place, got %q", out)
+	}
+
+	buf.Reset()
+	if err := shell.render(); err != nil {
+		t.Fatalf("third render: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected an unchanged frame to write nothing, got %q", buf.String())
+	}
+
+	term.width = 70
+	if err := shell.render(); err != nil {
+		t.Fatalf("resized render: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "\x1b[2J") {
+		t.Fatalf("expected a resize to redraw the whole screen, got %q", buf.String())
+	}
+}
+
+// countingWriter counts Write calls; render writes each frame in one call.
+type countingWriter struct {
+	mu     sync.Mutex
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	return len(p), nil
+}
+
+func TestShellRunCoalescesRenders(t *testing.T) {
+	out := &countingWriter{}
+	shell := NewShell(WithIO(os.Stdin, out), withTerminal(&fakeTerminal{width: 80, height: 20}))
+	shell.frameInterval = time.Hour
+
+	events := make(chan interactive.SessionEvent, 500)
+	for i := 0; i < 500; i++ {
+		events <- interactive.SessionEvent{Type: interactive.EventLogChunk, Chunk: fmt.Sprintf("line %d\n", i)}
+	}
+	close(events)
+	if err := shell.Run(context.Background(), events); err != nil {
+		t.Fatalf("shell run: %v", err)
+	}
+	// Raw-mode setup and reset, the first frame, and the final frame.
+	if out.writes > 8 {
+		t.Fatalf("expected a burst of 500 chunks to coalesce, got %d writes", out.writes)
+	}
+}
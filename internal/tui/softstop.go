@@ -0,0 +1,64 @@
+package tui
+
+import "fmt"
+
+// MaxSoftStopPresets is how many reasons the quick-pick can offer; each is
+// chosen with a single digit.
+const MaxSoftStopPresets = 9
+
+// SoftStopBindings is implemented by shells that can show the soft-stop
+// reason quick-pick; without it the router stops with the default reason.
+type SoftStopBindings interface {
+	SetSoftStopPicker(active bool, reasons []string)
+	SetSoftStopInput(active bool, text string)
+}
+
+// SetSoftStopPicker shows or clears the numbered list of soft-stop reasons.
+func (s *Shell) SetSoftStopPicker(active bool, reasons []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopPicking = active
+	if active {
+		s.stopReasons = append([]string(nil), reasons...)
+	} else {
+		s.stopReasons = nil
+	}
+	s.requestRenderLocked()
+}
+
+// SetSoftStopInput toggles free-text entry of a soft-stop reason.
+func (s *Shell) SetSoftStopInput(active bool, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopTyping = active
+	if active {
+		s.stopText = text
+	} else {
+		s.stopText = ""
+	}
+	s.requestRenderLocked()
+}
+
+// softStopOverlayLinesLocked lists the presets above the prompt line while
+// the quick-pick is open.
+func (s *Shell) softStopOverlayLinesLocked() []string {
+	if !s.stopPicking {
+		return nil
+	}
+	lines := []string{"Soft stop reason:"}
+	for i, reason := range s.stopReasons {
+		lines = append(lines, fmt.Sprintf("  %d. %s", i+1, reason))
+	}
+	return append(lines, "  e. Type a reason")
+}
+
+func (s *Shell) softStopPromptLocked() string {
+	if s.stopTyping {
+		return fmt.Sprintf("Soft stop reason (Enter=send, Esc=cancel): %s", s.stopText)
+	}
+	keys := "1"
+	if n := len(s.stopReasons); n > 1 {
+		keys = fmt.Sprintf("1-%d", n)
+	}
+	return fmt.Sprintf("Soft stop (%s=pick, e=type, Enter=default, Esc=cancel)", keys)
+}
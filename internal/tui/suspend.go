@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+)
+
+// ctrlZ is the byte raw mode delivers for Ctrl+Z, since the terminal no
+// longer turns it into SIGTSTP.
+const ctrlZ = 0x1a
+
+// Swapped in tests.
+var suspendProcess = stopSelf
+
+// SuspendBindings is implemented by shells that can hand the terminal back
+// to the launching shell; the router checks for it on Ctrl+Z.
+type SuspendBindings interface {
+	Suspend() error
+}
+
+// Suspend restores the terminal and stops obi, like Ctrl+Z in a cooked
+// terminal. Codex is not signalled, but with obi stopped nothing reads its
+// PTY, so it blocks on its next write until obi is continued (fg). Then raw
+// mode is re-entered and the screen redrawn.
+func (s *Shell) Suspend() error {
+	if suspendSignal == nil {
+		return nil // no job control on this platform
+	}
+	s.mu.Lock()
+	if s.suspended {
+		s.mu.Unlock()
+		return nil
+	}
+	raw, ok := untrackRaw(s)
+	if !ok {
+		s.mu.Unlock()
+		return errors.New("suspend: terminal is not in raw mode")
+	}
+	raw.reset()
+	s.writeAnsi("\r\nobi suspended, and Codex with it. Use fg to return.\r\n")
+	s.suspended = true
+	s.lastFrame = nil
+	s.mu.Unlock()
+
+	stopErr := suspendProcess()
+	// Execution resumes here on SIGCONT.
+	if err := s.resume(); err != nil {
+		return err
+	}
+	return stopErr
+}
+
+// resume re-enters raw mode after a suspend and schedules a full redraw.
+// It does nothing unless the shell is suspended.
+func (s *Shell) resume() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.suspended {
+		return nil
+	}
+	st, err := s.term.makeRaw(s.fd)
+	if err != nil {
+		return fmt.Errorf("re-enable raw mode: %w", err)
+	}
+	s.suspended = false
+	s.state = st
+	trackRaw(s, rawTerminal{term: s.term, fd: s.fd, state: st, out: s.out})
+	s.writeAnsi("\x1b[?25l")   // hide cursor
+	s.writeAnsi("\x1b[?2004h") // enable bracketed paste
+	s.requestRenderLocked()
+	return nil
+}
+
+// handleJobSignal reacts to SIGTSTP sent from outside (kill -TSTP) and to
+// SIGCONT after any stop, including SIGSTOP, which cannot be caught.
+func (s *Shell) handleJobSignal(sig os.Signal) error {
+	diag.Logger().Debug("tui job control signal", "signal", sig.String())
+	switch sig {
+	case suspendSignal:
+		return s.Suspend()
+	case continueSignal:
+		if err := s.resume(); err != nil {
+			return err
+		}
+		s.RequestRender()
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+//go:build darwin || linux
+
+package tui
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestShellSuspendRestoresAndReentersRawMode(t *testing.T) {
+	out := &bytes.Buffer{}
+	term := &fakeTerminal{width: 80, height: 20}
+	shell := NewShell(WithIO(os.Stdin, out), withTerminal(term))
+	if err := shell.enterRawMode(); err != nil {
+		t.Fatalf("enter raw mode: %v", err)
+	}
+
+	var atStop string
+	suspendProcess = func() error {
+		atStop = out.String()
+		if !shell.suspended || term.restoreCount != 1 {
+			t.Errorf("expected terminal restored before stopping, got %d restores", term.restoreCount)
+		}
+		if err := shell.render(); err != nil || out.String() != atStop {
+			t.Errorf("render must not draw while suspended: %v", err)
+		}
+		return nil
+	}
+	defer func() { suspendProcess = stopSelf }()
+
+	if err := shell.Suspend(); err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+	if !strings.Contains(atStop, terminalReset) || !strings.Contains(atStop, "fg to return") {
+		t.Fatalf("expected cursor shown and a notice before stopping, got %q", atStop)
+	}
+	if shell.suspended || term.rawCount != 2 {
+		t.Fatalf("expected raw mode re-entered on continue, got suspended=%v raw=%d", shell.suspended, term.rawCount)
+	}
+	if got := out.String()[len(atStop):]; !strings.Contains(got, "\x1b[?25l") {
+		t.Fatalf("expected cursor hidden again, got %q", got)
+	}
+
+	// A stray SIGCONT without a suspend must not re-save raw termios.
+	if err := shell.handleJobSignal(continueSignal); err != nil || term.rawCount != 2 {
+		t.Fatalf("unexpected re-entry on SIGCONT: %v, raw=%d", err, term.rawCount)
+	}
+	shell.restoreTerminal()
+	if term.restoreCount != 2 {
+		t.Fatalf("expected the resumed state to be restored on exit, got %d", term.restoreCount)
+	}
+}
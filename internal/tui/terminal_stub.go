@@ -2,7 +2,20 @@
 
 package tui
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+)
+
+var cleanupSignals []os.Signal
+
+var suspendSignal, continueSignal os.Signal
+
+func stopSelf() error {
+	return fmt.Errorf("suspending obi is unsupported on this platform")
+}
+
+func reraise(os.Signal) {}
 
 type termState struct{}
 
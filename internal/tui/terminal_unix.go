@@ -4,10 +4,34 @@ package tui
 
 import (
 	"fmt"
+	"os"
 	"syscall"
 	"unsafe"
 )
 
+// cleanupSignals end the process without running deferred functions, so
+// the terminal is restored from a signal handler instead.
+var cleanupSignals = []os.Signal{syscall.SIGQUIT, syscall.SIGABRT}
+
+// suspendSignal and continueSignal drive Ctrl+Z style job control.
+var (
+	suspendSignal  os.Signal = syscall.SIGTSTP
+	continueSignal os.Signal = syscall.SIGCONT
+)
+
+// stopSelf stops obi's own process. Codex runs in its own PTY session and
+// is not signalled, but stalls once its output goes unread. It returns once
+// obi is continued.
+func stopSelf() error {
+	return syscall.Kill(os.Getpid(), syscall.SIGSTOP)
+}
+
+func reraise(sig os.Signal) {
+	if s, ok := sig.(syscall.Signal); ok {
+		_ = syscall.Kill(os.Getpid(), s)
+	}
+}
+
 type termState struct {
 	termios syscall.Termios
 }
@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ThemeDark is the default palette tuned for dark terminal backgrounds.
+	ThemeDark = "dark"
+	// ThemeLight swaps in colors that stay legible on light backgrounds.
+	ThemeLight = "light"
+	// ThemeNone disables all ANSI styling.
+	ThemeNone = "none"
+
+	ansiReset = "\x1b[0m"
+)
+
+// ThemeColors overrides individual palette roles. Values accept color names
+// (red, green, yellow, blue, magenta, cyan, white, black, gray) or a 256-color
+// index such as "208". Empty values keep the theme default.
+type ThemeColors struct {
+	Accent  string
+	Success string
+	Warning string
+	Failure string
+	Dim     string
+}
+
+// Theme holds the SGR parameters used to style each part of the shell.
+type Theme struct {
+	Name    string
+	Accent  string
+	Success string
+	Warning string
+	Failure string
+	Dim     string
+}
+
+var builtinThemes = map[string]Theme{
+	ThemeDark: {
+		Name:    ThemeDark,
+		Accent:  "1;36",
+		Success: "32",
+		Warning: "33",
+		Failure: "31",
+		Dim:     "2",
+	},
+	ThemeLight: {
+		Name:    ThemeLight,
+		Accent:  "1;34",
+		Success: "38;5;28",
+		Warning: "38;5;130",
+		Failure: "38;5;160",
+		Dim:     "38;5;244",
+	},
+	ThemeNone: {Name: ThemeNone},
+}
+
+var colorNames = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+	"gray":    "90",
+	"grey":    "90",
+}
+
+// NewTheme resolves a named theme and applies optional color overrides. An
+// empty name selects the dark theme. The none theme ignores overrides.
+func NewTheme(name string, colors ThemeColors) (Theme, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if key == "" {
+		key = ThemeDark
+	}
+	theme, ok := builtinThemes[key]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown tui theme %q (want dark, light, or none)", name)
+	}
+	if key == ThemeNone {
+		return theme, nil
+	}
+	overrides := []struct {
+		role  string
+		value string
+		dst   *string
+	}{
+		{"accent", colors.Accent, &theme.Accent},
+		{"success", colors.Success, &theme.Success},
+		{"warning", colors.Warning, &theme.Warning},
+		{"failure", colors.Failure, &theme.Failure},
+		{"dim", colors.Dim, &theme.Dim},
+	}
+	for _, o := range overrides {
+		if strings.TrimSpace(o.value) == "" {
+			continue
+		}
+		sgr, err := parseColor(o.value)
+		if err != nil {
+			return Theme{}, fmt.Errorf("tui color %s: %w", o.role, err)
+		}
+		*o.dst = sgr
+	}
+	return theme, nil
+}
+
+// MonochromeTheme returns the theme that emits no ANSI styling.
+func MonochromeTheme() Theme {
+	return builtinThemes[ThemeNone]
+}
+
+func parseColor(value string) (string, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	if sgr, ok := colorNames[v]; ok {
+		return sgr, nil
+	}
+	if n, err := strconv.Atoi(v); err == nil && n >= 0 && n <= 255 {
+		return "38;5;" + strconv.Itoa(n), nil
+	}
+	return "", fmt.Errorf("unsupported color %q", value)
+}
+
+func (t Theme) paint(sgr, text string) string {
+	if sgr == "" || text == "" {
+		return text
+	}
+	return "\x1b[" + sgr + "m" + text + ansiReset
+}
+
+// statusColor picks the palette entry for a run status or session state.
+func (t Theme) statusColor(status string) string {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "success":
+		return t.Success
+	case "stopping":
+		return t.Warning
+	case "needs_help", "failed", "error":
+		return t.Failure
+	default:
+		return ""
+	}
+}
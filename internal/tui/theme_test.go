@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
+)
+
+func TestNewThemeAppliesOverrides(t *testing.T) {
+	theme, err := NewTheme("light", ThemeColors{Accent: "magenta", Failure: "196"})
+	if err != nil {
+		t.Fatalf("new theme: %v", err)
+	}
+	if theme.Accent != "35" {
+		t.Fatalf("expected accent override, got %q", theme.Accent)
+	}
+	if theme.Failure != "38;5;196" {
+		t.Fatalf("expected 256-color failure override, got %q", theme.Failure)
+	}
+	if theme.Success != builtinThemes[ThemeLight].Success {
+		t.Fatalf("expected light success default, got %q", theme.Success)
+	}
+}
+
+func TestNewThemeRejectsUnknownValues(t *testing.T) {
+	if _, err := NewTheme("neon", ThemeColors{}); err == nil {
+		t.Fatalf("expected unknown theme error")
+	}
+	if _, err := NewTheme("dark", ThemeColors{Dim: "chartreuse"}); err == nil {
+		t.Fatalf("expected unknown color error")
+	}
+}
+
+func TestNoneThemeEmitsNoEscapes(t *testing.T) {
+	theme, err := NewTheme("none", ThemeColors{Accent: "red"})
+	if err != nil {
+		t.Fatalf("new theme: %v", err)
+	}
+	if got := theme.paint(theme.Accent, "title"); got != "title" {
+		t.Fatalf("expected plain text, got %q", got)
+	}
+}
+
+func TestShellRendersThemedStatusAndDimStderr(t *testing.T) {
+	buf := &bytes.Buffer{}
+	theme, _ := NewTheme("dark", ThemeColors{})
+	shell := NewShell(
+		WithIO(os.Stdin, buf),
+		WithTheme(theme),
+		withTerminal(&fakeTerminal{width: 80, height: 20}),
+	)
+	shell.UpdateStatus(func(line *StatusLine) { line.RunStatus = "needs_help" })
+
+	events := make(chan interactive.SessionEvent, 2)
+	events <- interactive.SessionEvent{Type: interactive.EventLogChunk, Stream: interactive.StreamStderr, Chunk: "warning: slow\n"}
+	close(events)
+	if err := shell.Run(context.Background(), events); err != nil {
+		t.Fatalf("shell run: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "\x1b[31mneeds_help\x1b[0m") {
+		t.Fatalf("expected red needs_help status, got %q", output)
+	}
+	if !strings.Contains(output, "\x1b[2mwarning: slow\x1b[0m") {
+		t.Fatalf("expected dimmed stderr line, got %q", output)
+	}
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The obi message area shows obi's own non-fatal warnings above the
+// footer, because stderr is unusable while the shell owns the terminal.
+const (
+	maxWarningLines = 2
+	warningLinger   = time.Minute
+)
+
+type shellWarning struct {
+	at   time.Time
+	text string
+}
+
+// Warn shows an obi internal warning in the message area for a minute. Safe
+// for concurrent use.
+func (s *Shell) Warn(text string) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.warnings = append(s.warnings, shellWarning{at: s.now(), text: text})
+	if len(s.warnings) > maxWarningLines {
+		s.warnings = s.warnings[len(s.warnings)-maxWarningLines:]
+	}
+	s.warnTotal++
+	s.requestRenderLocked()
+}
+
+// pruneWarningsLocked drops warnings older than warningLinger; render calls
+// it once per frame so the line count and the lines agree.
+func (s *Shell) pruneWarningsLocked() {
+	now := s.now()
+	kept := s.warnings[:0]
+	for _, w := range s.warnings {
+		if now.Sub(w.at) < warningLinger {
+			kept = append(kept, w)
+		}
+	}
+	s.warnings = kept
+}
+
+func (s *Shell) warningLinesLocked() []string {
+	if len(s.warnings) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(s.warnings))
+	for i, w := range s.warnings {
+		text := w.text
+		if i == len(s.warnings)-1 && s.warnTotal > len(s.warnings) {
+			text += fmt.Sprintf(" (%d warnings this session)", s.warnTotal)
+		}
+		label := "obi " + w.at.Format("15:04:05") + " "
+		lines = append(lines, s.theme.paint(s.theme.Warning, label)+truncateToWidth(text, s.width-displayWidth(label)))
+	}
+	return lines
+}
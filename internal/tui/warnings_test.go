@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/diag"
+)
+
+func TestShellWarningsShowAboveFooterAndExpire(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	now := start
+	buf := &bytes.Buffer{}
+	shell := NewShell(
+		WithIO(os.Stdin, buf),
+		withTerminal(&fakeTerminal{width: 120, height: 12}),
+		withClock(func() time.Time { return now }),
+	)
+	shell.Warn("audit log write failed: disk full")
+	shell.Warn("webhook post failed")
+	now = start.Add(10 * time.Second)
+	shell.Warn("heartbeat write failed\nsecond line")
+
+	if err := shell.render(); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "disk full") {
+		t.Fatalf("expected the oldest warning to be dropped: %q", out)
+	}
+	for _, want := range []string{"obi 09:00:00 ", "webhook post failed", "obi 09:00:10 ", "heartbeat write failed second line (3 warnings this session)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in %q", want, out)
+		}
+	}
+
+	now = start.Add(2 * time.Minute)
+	buf.Reset()
+	if err := shell.render(); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if strings.Contains(buf.String(), "failed") {
+		t.Fatalf("expected warnings to expire: %q", buf.String())
+	}
+}
+
+func TestShellScrollWarnsAboutLostScrollbackWithoutDeadlock(t *testing.T) {
+	shell := NewShell(WithIO(os.Stdin, &bytes.Buffer{}), withTerminal(&fakeTerminal{width: 120, height: 12}), WithMaxLogs(2))
+	defer diag.Divert(func(_ slog.Level, text string) { shell.Warn(text) })()
+	shell.pane.loadHistory = func(start, end int) ([]string, error) {
+		return nil, errors.New("transcript removed")
+	}
+	shell.pane.append("one\ntwo\nthree\nfour\n")
+
+	done := make(chan struct{})
+	go func() {
+		shell.Scroll(5)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Scroll deadlocked while warning")
+	}
+	shell.mu.Lock()
+	defer shell.mu.Unlock()
+	if len(shell.warnings) != 1 || !strings.Contains(shell.warnings[0].text, "transcript removed") {
+		t.Fatalf("warnings = %+v", shell.warnings)
+	}
+}
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// wideRanges lists East Asian Wide/Fullwidth blocks and emoji that terminals
+// draw two cells wide. It is an approximation of UAX #11 that covers the
+// scripts and pictographs Codex output realistically contains.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC},
+	{0x23F0, 0x23F0}, {0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267F, 0x267F}, {0x2693, 0x2693}, {0x26A1, 0x26A1},
+	{0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5}, {0x26CE, 0x26CE},
+	{0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B},
+	{0x2728, 0x2728}, {0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27B0, 0x27B0}, {0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55}, {0x2E80, 0x303E},
+	{0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F}, {0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x1F004, 0x1F004},
+	{0x1F0CF, 0x1F0CF}, {0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A}, {0x1F200, 0x1F251},
+	{0x1F300, 0x1F64F}, {0x1F680, 0x1F6FF}, {0x1F900, 0x1F9FF}, {0x1FA70, 0x1FAFF},
+	{0x20000, 0x3FFFD},
+}
+
+// runeWidth reports how many terminal cells r occupies: 0 for combining
+// marks, joiners, and control characters, 2 for wide characters, else 1.
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7F && r < 0xA0):
+		return 0
+	case r < 0x1100:
+		if unicode.In(r, unicode.Mn, unicode.Me) {
+			return 0
+		}
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1160 && r <= 0x11FF: // Hangul medial vowels and final consonants
+		return 0
+	}
+	for _, rng := range wideRanges {
+		if r < rng[0] {
+			break
+		}
+		if r <= rng[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// escapeLen returns the byte length of the ANSI CSI sequence at the start of
+// s, or 0 when s does not start with one. Escapes take no cells and are
+// never split.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' || s[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7E {
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// displayWidth measures s in terminal cells, ignoring ANSI escapes.
+func displayWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// splitAtWidth returns the longest prefix of s that fits in width cells and
+// the remainder. A wide character that would straddle the edge moves to the
+// remainder; trailing zero-width runes stay with the character they modify.
+func splitAtWidth(s string, width int) (string, string) {
+	used := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w := runeWidth(r)
+		if w > 0 && used+w > width {
+			return s[:i], s[i:]
+		}
+		used += w
+		i += size
+	}
+	return s, ""
+}
+
+func truncateToWidth(line string, width int) string {
+	if width <= 0 || displayWidth(line) <= width {
+		return line
+	}
+	head, _ := splitAtWidth(line, width)
+	return head
+}
+
+// clipToWidth truncates line like truncateToWidth but ends a cut line with
+// marker, so it is visibly incomplete.
+func clipToWidth(line string, width int, marker string) string {
+	if width <= 0 || displayWidth(line) <= width {
+		return line
+	}
+	room := width - displayWidth(marker)
+	if room < 1 {
+		return truncateToWidth(line, width)
+	}
+	head, _ := splitAtWidth(line, room)
+	return head + marker
+}
+
+// wrapToWidth breaks line into rows of at most width cells. Continuation
+// rows are prefixed with indent, whose width is deducted from theirs.
+func wrapToWidth(line string, width int, indent string) []string {
+	if width <= 0 || displayWidth(line) <= width {
+		return []string{line}
+	}
+	rest := width - displayWidth(indent)
+	if rest < 1 {
+		return []string{truncateToWidth(line, width)}
+	}
+	head, tail := splitAtWidth(line, width)
+	rows := []string{head}
+	for tail != "" {
+		if head, tail = splitAtWidth(tail, rest); head == "" {
+			// A single character wider than the row; emit it anyway.
+			_, size := utf8.DecodeRuneInString(tail)
+			head, tail = tail[:size], tail[size:]
+		}
+		rows = append(rows, indent+head)
+	}
+	return rows
+}
@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisplayWidthCountsWideAndZeroWidthRunes(t *testing.T) {
+	cases := map[string]int{
+		"plain":              5,
+		"日本語":                6,
+		"✅ done":             7,
+		"é":                 1,
+		"\x1b[31mred\x1b[0m": 3,
+		"한국":                 4,
+	}
+	for input, want := range cases {
+		if got := displayWidth(input); got != want {
+			t.Fatalf("displayWidth(%q)=%d want %d", input, got, want)
+		}
+	}
+}
+
+func TestTruncateToWidthNeverSplitsWideRunes(t *testing.T) {
+	if got := truncateToWidth("日本語テキスト", 5); got != "日本" {
+		t.Fatalf("expected wide runes to stop before the edge, got %q", got)
+	}
+	if got := truncateToWidth("ab🚀cd", 3); got != "ab" {
+		t.Fatalf("expected emoji to be dropped whole, got %q", got)
+	}
+	if got := truncateToWidth("short", 10); got != "short" {
+		t.Fatalf("expected short line untouched, got %q", got)
+	}
+}
+
+func TestClipToWidthMarksCutLines(t *testing.T) {
+	if got := clipToWidth("abcdefgh", 5, "…"); got != "abcd…" {
+		t.Fatalf("expected marker in the last cell, got %q", got)
+	}
+	if got := clipToWidth("日本語テキスト", 6, "…"); got != "日本…" {
+		t.Fatalf("expected wide runes to stop before the marker, got %q", got)
+	}
+	if got := clipToWidth("short", 5, "…"); got != "short" {
+		t.Fatalf("expected a fitting line untouched, got %q", got)
+	}
+}
+
+func TestWrapToWidthIndentsContinuationRows(t *testing.T) {
+	rows := wrapToWidth("漢字漢字漢字", 5, "  ")
+	want := []string{"漢字", "  漢", "  字", "  漢", "  字"}
+	if strings.Join(rows, "|") != strings.Join(want, "|") {
+		t.Fatalf("wrap=%q want %q", rows, want)
+	}
+	for _, row := range rows {
+		if displayWidth(row) > 5 {
+			t.Fatalf("row %q overflows width", row)
+		}
+	}
+}
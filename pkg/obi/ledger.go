@@ -0,0 +1,158 @@
+package obi
+
+import (
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/app"
+)
+
+// LedgerEntry is one results-log line. Fields added to the ledger later are
+// only exposed here once they are added to this struct.
+type LedgerEntry struct {
+	RunID          string    `json:"run_id"`
+	SessionID      string    `json:"session_id"`
+	RepoRoot       string    `json:"repo_root"`
+	EpicID         string    `json:"epic_id"`
+	EpicKey        string    `json:"epic_key"`
+	EpicName       string    `json:"epic_name"`
+	Alias          string    `json:"alias"`
+	BeadID         string    `json:"bead_id,omitempty"`
+	Status         string    `json:"status"`
+	CommitSummary  string    `json:"commit_summary"`
+	CommitDetails  string    `json:"commit_details"`
+	Escalation     string    `json:"escalation,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+	CompletedAt    time.Time `json:"completed_at"`
+	DurationMs     int64     `json:"duration_ms"`
+	ExitCode       int       `json:"exit_code"`
+	TranscriptPath string    `json:"transcript_path,omitempty"`
+	CodexModel     string    `json:"codex_model,omitempty"`
+	ConfigDigest   string    `json:"config_digest,omitempty"`
+	PromptHash     string    `json:"prompt_hash,omitempty"`
+	TokensUsed     int64     `json:"tokens_used,omitempty"`
+	CodexSessionID string    `json:"codex_session_id,omitempty"`
+	ContinuedFrom  string    `json:"continued_from,omitempty"`
+	Template       string    `json:"template,omitempty"`
+	FailureKind    string    `json:"failure_kind,omitempty"`
+	Simulated      string    `json:"simulated,omitempty"`
+	Degraded       string    `json:"degraded,omitempty"`
+	AbortCleanup   string    `json:"abort_cleanup,omitempty"`
+	ArtifactsDir   string    `json:"artifacts_dir,omitempty"`
+	Artifacts      []string  `json:"artifacts,omitempty"`
+	Experiment     string    `json:"experiment,omitempty"`
+	Variant        string    `json:"experiment_variant,omitempty"`
+	Profile        string    `json:"profile,omitempty"`
+	CommitType     string    `json:"commit_type,omitempty"`
+	CommitScope    string    `json:"commit_scope,omitempty"`
+	CommitSubject  string    `json:"commit_subject,omitempty"`
+	Exploratory    bool      `json:"exploratory,omitempty"`
+	AttemptGroup   string    `json:"attempt_group,omitempty"`
+	CostUSD        float64   `json:"cost_usd,omitempty"`
+}
+
+// ReadLedger returns the results-log entries for epicID, or all entries
+// when epicID is empty. A missing log yields no entries.
+func ReadLedger(path, epicID string) ([]LedgerEntry, error) {
+	entries, err := app.ReadLedger(path, epicID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]LedgerEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, fromAppLedgerEntry(entry))
+	}
+	return out, nil
+}
+
+// AppendLedger appends entry to the results log.
+func AppendLedger(path string, entry LedgerEntry) error {
+	return app.AppendLedger(path, toAppLedgerEntry(entry))
+}
+
+func fromAppLedgerEntry(entry app.LedgerEntry) LedgerEntry {
+	return LedgerEntry{
+		RunID:          entry.RunID,
+		SessionID:      entry.SessionID,
+		RepoRoot:       entry.RepoRoot,
+		EpicID:         entry.EpicID,
+		EpicKey:        entry.EpicKey,
+		EpicName:       entry.EpicName,
+		Alias:          entry.Alias,
+		BeadID:         entry.BeadID,
+		Status:         entry.Status,
+		CommitSummary:  entry.CommitSummary,
+		CommitDetails:  entry.CommitDetails,
+		Escalation:     entry.Escalation,
+		StartedAt:      entry.StartedAt,
+		CompletedAt:    entry.CompletedAt,
+		DurationMs:     entry.DurationMs,
+		ExitCode:       entry.ExitCode,
+		TranscriptPath: entry.TranscriptPath,
+		CodexModel:     entry.CodexModel,
+		ConfigDigest:   entry.ConfigDigest,
+		PromptHash:     entry.PromptHash,
+		TokensUsed:     entry.TokensUsed,
+		CodexSessionID: entry.CodexSessionID,
+		ContinuedFrom:  entry.ContinuedFrom,
+		Template:       entry.Template,
+		FailureKind:    entry.FailureKind,
+		Simulated:      entry.Simulated,
+		Degraded:       entry.Degraded,
+		AbortCleanup:   entry.AbortCleanup,
+		ArtifactsDir:   entry.ArtifactsDir,
+		Artifacts:      entry.Artifacts,
+		Experiment:     entry.Experiment,
+		Variant:        entry.Variant,
+		Profile:        entry.Profile,
+		CommitType:     entry.CommitType,
+		CommitScope:    entry.CommitScope,
+		CommitSubject:  entry.CommitSubject,
+		Exploratory:    entry.Exploratory,
+		AttemptGroup:   entry.AttemptGroup,
+		CostUSD:        entry.CostUSD,
+	}
+}
+
+func toAppLedgerEntry(entry LedgerEntry) app.LedgerEntry {
+	return app.LedgerEntry{
+		RunID:          entry.RunID,
+		SessionID:      entry.SessionID,
+		RepoRoot:       entry.RepoRoot,
+		EpicID:         entry.EpicID,
+		EpicKey:        entry.EpicKey,
+		EpicName:       entry.EpicName,
+		Alias:          entry.Alias,
+		BeadID:         entry.BeadID,
+		Status:         entry.Status,
+		CommitSummary:  entry.CommitSummary,
+		CommitDetails:  entry.CommitDetails,
+		Escalation:     entry.Escalation,
+		StartedAt:      entry.StartedAt,
+		CompletedAt:    entry.CompletedAt,
+		DurationMs:     entry.DurationMs,
+		ExitCode:       entry.ExitCode,
+		TranscriptPath: entry.TranscriptPath,
+		CodexModel:     entry.CodexModel,
+		ConfigDigest:   entry.ConfigDigest,
+		PromptHash:     entry.PromptHash,
+		TokensUsed:     entry.TokensUsed,
+		CodexSessionID: entry.CodexSessionID,
+		ContinuedFrom:  entry.ContinuedFrom,
+		Template:       entry.Template,
+		FailureKind:    entry.FailureKind,
+		Simulated:      entry.Simulated,
+		Degraded:       entry.Degraded,
+		AbortCleanup:   entry.AbortCleanup,
+		ArtifactsDir:   entry.ArtifactsDir,
+		Artifacts:      entry.Artifacts,
+		Experiment:     entry.Experiment,
+		Variant:        entry.Variant,
+		Profile:        entry.Profile,
+		CommitType:     entry.CommitType,
+		CommitScope:    entry.CommitScope,
+		CommitSubject:  entry.CommitSubject,
+		Exploratory:    entry.Exploratory,
+		AttemptGroup:   entry.AttemptGroup,
+		CostUSD:        entry.CostUSD,
+	}
+}
@@ -0,0 +1,349 @@
+// Package obi exposes obi's orchestration for other Go tools: loading
+// obi.toml, resolving an epic into a session plan and prompt, launching Codex
+// through the session runner, parsing the fenced report, and reading or
+// appending the results ledger. The obi CLI is built on the same code.
+//
+// The types in this package are its own and are converted from obi's
+// internal ones, so changes under internal/ do not alter this API.
+package obi
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/app"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/codexexec"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/config"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/fenced"
+	"github.com/brandonharvey/automatic-octo-barnacle/tools/obi/internal/interactive"
+)
+
+// Config is a loaded obi.toml (with fragments and profile applied). Its
+// contents are not exposed; pass it to BuildPlan.
+type Config struct {
+	cfg *config.Config
+}
+
+// CodexConfig selects the Codex binary, model, sandbox, and approvals.
+type CodexConfig struct {
+	Binary    string
+	Model     string
+	Sandbox   string
+	Approval  string
+	ExtraArgs []string
+}
+
+// PlanOptions mirrors the obi go flags that shape a plan.
+type PlanOptions struct {
+	// RepoRoot anchors relative epic dirs and context files; empty uses the
+	// git toplevel of the config file's directory.
+	RepoRoot     string
+	ConfigPath   string
+	Dir          string
+	Env          []string
+	ContextFiles []string
+	// ResumeFromLog skips beads this results log already records as done.
+	ResumeFromLog string
+	// ReadOnly plans an exploratory session as obi go --read-only does.
+	ReadOnly bool
+}
+
+// Plan is a resolved epic with its composed prompt body (without the
+// per-session fenced-report instructions Start adds).
+type Plan struct {
+	EpicKey              string
+	EpicName             string
+	Alias                string
+	EpicID               string
+	Tool                 string
+	Prompt               string
+	Codex                CodexConfig
+	RepoRoot             string
+	ConfigDigest         string
+	Dir                  string
+	Env                  []string
+	VerifyCommand        string
+	ResumeCompletedBeads []string
+	// ReportGuidance holds the [style] rules to add to the fenced-report
+	// instructions.
+	ReportGuidance []string
+
+	// codex keeps the settings Codex is built from that the public
+	// CodexConfig does not carry, such as forbidden_args.
+	codex config.CodexConfig
+	// secretEnv and unset are the epic's secrets.from_env names and the
+	// other epics' ones, kept out of the session's environment.
+	secretEnv []string
+	unset     []string
+}
+
+// Invocation is the resolved Codex command line.
+type Invocation struct {
+	Binary string
+	Args   []string
+}
+
+// Session event types and lifecycle states reported on Session.Events.
+const (
+	EventLogChunk    = string(interactive.EventLogChunk)
+	EventStateChange = string(interactive.EventStateChange)
+	EventExit        = string(interactive.EventExit)
+	EventPhaseChange = string(interactive.EventPhaseChange)
+
+	StateStarting = string(interactive.StateStarting)
+	StateRunning  = string(interactive.StateRunning)
+	StateStopping = string(interactive.StateStopping)
+	StateExited   = string(interactive.StateExited)
+)
+
+// SessionEvent is one item on Session.Events: output (Chunk), a state or
+// phase change, or the exit.
+type SessionEvent struct {
+	Time     time.Time
+	Type     string
+	State    string
+	Phase    string
+	Stream   string
+	Chunk    string
+	ExitCode int
+	Err      error
+}
+
+// SessionResult is returned by Session.Wait.
+type SessionResult struct {
+	SessionID   string
+	Prompt      string
+	Invocation  Invocation
+	Output      string
+	ExitCode    int
+	StartedAt   time.Time
+	CompletedAt time.Time
+	// Degraded explains why Codex ran on pipes instead of a PTY; empty for
+	// a normal session.
+	Degraded string
+}
+
+// Report is a parsed fenced report.
+type Report struct {
+	SessionID  string
+	Status     string
+	CommitMsg  string
+	Details    string
+	Escalation string
+}
+
+// LoadConfig resolves path like the CLI does (empty means $OBI_CONFIG, then
+// the nearest obi.toml) and applies profile (empty means $OBI_PROFILE).
+func LoadConfig(path, profile string) (*Config, string, error) {
+	resolved, err := config.ResolvePath(path)
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := config.Load(resolved)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := cfg.ApplyProfile(config.ResolveProfile(profile)); err != nil {
+		return nil, "", err
+	}
+	return &Config{cfg: cfg}, resolved, nil
+}
+
+// BuildPlan resolves an epic alias or ID (empty selects loose issues).
+func BuildPlan(cfg *Config, alias string, opts PlanOptions) (Plan, error) {
+	var internal *config.Config
+	if cfg != nil {
+		internal = cfg.cfg
+	}
+	plan, err := app.BuildPlan(internal, alias, app.PlanOptions{
+		RepoRoot:      opts.RepoRoot,
+		ConfigPath:    opts.ConfigPath,
+		Dir:           opts.Dir,
+		Env:           opts.Env,
+		ContextFiles:  opts.ContextFiles,
+		ResumeFromLog: opts.ResumeFromLog,
+		ReadOnly:      opts.ReadOnly,
+	})
+	if err != nil {
+		return Plan{}, err
+	}
+	return Plan{
+		EpicKey:              plan.EpicKey,
+		EpicName:             plan.EpicName,
+		Alias:                plan.Alias,
+		EpicID:               plan.EpicID,
+		Tool:                 plan.Tool,
+		Prompt:               plan.Prompt,
+		Codex:                publicCodex(plan.Codex),
+		RepoRoot:             plan.RepoRoot,
+		ConfigDigest:         plan.ConfigDigest,
+		Dir:                  plan.Dir,
+		Env:                  plan.Env,
+		VerifyCommand:        plan.VerifyCommand,
+		ResumeCompletedBeads: plan.ResumeCompletedBeads,
+		ReportGuidance:       plan.ReportGuidance,
+		codex:                plan.Codex,
+		secretEnv:            plan.SecretEnv,
+		unset:                plan.Unset,
+	}, nil
+}
+
+func publicCodex(codex config.CodexConfig) CodexConfig {
+	return CodexConfig{
+		Binary:    codex.Binary,
+		Model:     codex.Model,
+		Sandbox:   codex.Sandbox,
+		Approval:  codex.Approval,
+		ExtraArgs: append([]string(nil), codex.ExtraArgs...),
+	}
+}
+
+// codexConfig applies the public settings, which callers may have edited,
+// onto the plan's full Codex configuration.
+func (p Plan) codexConfig() config.CodexConfig {
+	codex := p.codex
+	codex.Binary = p.Codex.Binary
+	codex.Model = p.Codex.Model
+	codex.Sandbox = p.Codex.Sandbox
+	codex.Approval = p.Codex.Approval
+	codex.ExtraArgs = p.Codex.ExtraArgs
+	return codex
+}
+
+// SessionRunner launches Codex sessions.
+type SessionRunner struct {
+	runner *interactive.SessionRunner
+}
+
+// NewSessionRunner returns a runner that launches Codex in a PTY.
+func NewSessionRunner() *SessionRunner {
+	return &SessionRunner{runner: interactive.NewSessionRunner()}
+}
+
+// Session is a launched Codex session with the prompt it was given.
+type Session struct {
+	ID     string
+	Prompt string
+
+	handle     *interactive.SessionHandle
+	eventsOnce sync.Once
+	events     chan SessionEvent
+}
+
+// Start appends fenced-report instructions with a fresh session ID (and the
+// plan's report guidance) to the plan's prompt and launches Codex in the
+// plan's directory and environment, with the epic's secrets added and other
+// epics' secrets removed as obi go does. Output is mirrored to stdout
+// (os.Stdout when nil) and, with $OBI_REDACT and the epic's secret values
+// redacted, to tee and the session result. Like obi go it refuses an
+// invocation or environment that codex.forbidden_args or forbidden_env
+// rules out.
+func Start(ctx context.Context, runner *SessionRunner, plan Plan, stdout, tee io.Writer) (*Session, error) {
+	if runner == nil {
+		runner = NewSessionRunner()
+	}
+	env, secrets, err := app.SessionEnv(plan.Env, plan.secretEnv)
+	if err != nil {
+		return nil, err
+	}
+	codex := plan.codexConfig()
+	if err := codexexec.CheckEnv(codex, env); err != nil {
+		return nil, err
+	}
+	prepared, err := runner.runner.PreparePrompt(plan.Prompt, plan.ReportGuidance...)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := codexexec.Build(codex, prepared.Text)
+	if err != nil {
+		return nil, err
+	}
+	handle, err := runner.runner.Start(ctx, interactive.StartOptions{
+		SessionID:  prepared.SessionID,
+		Prompt:     prepared.Text,
+		Invocation: inv,
+		Stdout:     stdout,
+		Tee:        tee,
+		Secrets:    secrets,
+		Dir:        plan.Dir,
+		Env:        env,
+		Unset:      plan.unset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Session{ID: prepared.SessionID, Prompt: prepared.Text, handle: handle}, nil
+}
+
+// Events streams the session's events. The channel closes once Codex
+// exits; a session whose events are never read does not block.
+func (s *Session) Events() <-chan SessionEvent {
+	s.eventsOnce.Do(func() {
+		s.events = make(chan SessionEvent)
+		go func() {
+			defer close(s.events)
+			for ev := range s.handle.Events() {
+				s.events <- SessionEvent{
+					Time:     ev.Time,
+					Type:     string(ev.Type),
+					State:    string(ev.State),
+					Phase:    string(ev.Phase),
+					Stream:   ev.Stream,
+					Chunk:    ev.Chunk,
+					ExitCode: ev.ExitCode,
+					Err:      ev.Error,
+				}
+			}
+		}()
+	})
+	return s.events
+}
+
+// SoftStop asks Codex to wrap up and emit its report.
+func (s *Session) SoftStop(reason string) error {
+	return s.handle.SoftStop(reason)
+}
+
+// SubmitHint sends a human hint into the session.
+func (s *Session) SubmitHint(text string) error {
+	return s.handle.SubmitHint(text)
+}
+
+// Abort interrupts Codex, killing it if the interrupt is ignored.
+func (s *Session) Abort() error {
+	return s.handle.Abort()
+}
+
+// Wait blocks until Codex exits.
+func (s *Session) Wait() (SessionResult, error) {
+	res, err := s.handle.Wait()
+	return SessionResult{
+		SessionID:   res.SessionID,
+		Prompt:      res.Prompt,
+		Invocation:  Invocation{Binary: res.Invocation.Binary, Args: res.Invocation.Args},
+		Output:      res.Output,
+		ExitCode:    res.ExitCode,
+		StartedAt:   res.StartedAt,
+		CompletedAt: res.CompletedAt,
+		Degraded:    res.Degraded,
+	}, err
+}
+
+// ParseReport extracts the fenced report for sessionID from Codex output.
+// It fails when the output holds no complete, valid report.
+func ParseReport(output, sessionID string) (Report, error) {
+	parser := fenced.NewParser(sessionID)
+	res, ok, err := parser.Feed(output)
+	if err == nil && !ok {
+		res, _, err = parser.Finalize()
+	}
+	return Report{
+		SessionID:  res.SessionID,
+		Status:     res.Status,
+		CommitMsg:  res.CommitMsg,
+		Details:    res.Details,
+		Escalation: res.Escalation,
+	}, err
+}
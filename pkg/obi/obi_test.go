@@ -0,0 +1,136 @@
+package obi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildPlanComposesPrompt(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "obi.toml")
+	text := "base_prompt = \"Base text\"\n\n[epic.foo]\nname = \"Foo\"\nid = \"obi-foo\"\nprompt = \"Epic text\"\n"
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, resolved, err := LoadConfig(path, "")
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	plan, err := BuildPlan(cfg, "foo", PlanOptions{RepoRoot: root, ConfigPath: resolved})
+	if err != nil {
+		t.Fatalf("BuildPlan: %v", err)
+	}
+	if plan.EpicID != "obi-foo" || plan.ConfigDigest == "" {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	plan.Codex.Model = "gpt-test"
+	if got := plan.codexConfig(); got.Model != "gpt-test" {
+		t.Fatalf("edited Codex settings were not applied: %+v", got)
+	}
+	for _, part := range []string{"Base text", "Epic text", "Epic ID: obi-foo"} {
+		if !strings.Contains(plan.Prompt, part) {
+			t.Fatalf("expected prompt to include %q, got %q", part, plan.Prompt)
+		}
+	}
+}
+
+func TestStartRedactsEpicSecretsFromTheTee(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "obi.toml")
+	text := "base_prompt = \"Base text\"\n\n[epic.foo]\nname = \"Foo\"\nid = \"obi-foo\"\nprompt = \"Use sk-live-123 and hunter2.\"\n\n[epic.foo.secrets]\nfrom_env = [\"OBI_TEST_KEY\"]\n\n[epic.bar]\nname = \"Bar\"\nid = \"obi-bar\"\nprompt = \"x\"\n\n[epic.bar.secrets]\nfrom_env = [\"OBI_OTHER_KEY\"]\n"
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("OBI_TEST_KEY", "sk-live-123")
+	t.Setenv("OBI_REDACT", "hunter2")
+	t.Setenv("OBI_PIPE_LAUNCHER", "1")
+	cfg, resolved, err := LoadConfig(path, "")
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	plan, err := BuildPlan(cfg, "foo", PlanOptions{RepoRoot: root, ConfigPath: resolved})
+	if err != nil {
+		t.Fatalf("BuildPlan: %v", err)
+	}
+	if len(plan.unset) != 1 || plan.unset[0] != "OBI_OTHER_KEY" {
+		t.Fatalf("other epics' secrets should be unset, got %q", plan.unset)
+	}
+	plan.Codex.Binary = "echo"
+	var tee bytes.Buffer
+	session, err := Start(context.Background(), nil, plan, io.Discard, &tee)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	res, err := session.Wait()
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	for _, out := range []string{tee.String(), res.Output} {
+		if !strings.Contains(out, "Use ") || strings.Contains(out, "sk-live-123") || strings.Contains(out, "hunter2") {
+			t.Fatalf("expected secrets redacted from the output, got %q", out)
+		}
+	}
+}
+
+func TestStartEnforcesForbiddenEnv(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "obi.toml")
+	text := "[codex]\nforbidden_env = [\"CODEX_UNSAFE\"]\n\n[epic.foo]\nname = \"Foo\"\nid = \"obi-foo\"\nprompt = \"Epic text\"\n"
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, resolved, err := LoadConfig(path, "")
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	plan, err := BuildPlan(cfg, "foo", PlanOptions{RepoRoot: root, ConfigPath: resolved, Env: []string{"CODEX_UNSAFE=1"}})
+	if err != nil {
+		t.Fatalf("BuildPlan: %v", err)
+	}
+	plan.Codex.Binary = "echo"
+	if _, err := Start(context.Background(), nil, plan, io.Discard, nil); err == nil || !strings.Contains(err.Error(), "forbidden_env") {
+		t.Fatalf("expected forbidden_env to refuse the launch, got %v", err)
+	}
+}
+
+func TestParseReport(t *testing.T) {
+	output := "noise\n```obi:abc\nstatus: success\ncommit_msg: Ship it\ndetails: done\n```\n"
+	report, err := ParseReport(output, "abc")
+	if err != nil {
+		t.Fatalf("ParseReport: %v", err)
+	}
+	if report.Status != "success" || report.CommitMsg != "Ship it" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if _, err := ParseReport("no fence here", "abc"); err == nil {
+		t.Fatalf("expected error without a fenced report")
+	}
+}
+
+func TestLedgerRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.log")
+	if entries, err := ReadLedger(path, ""); err != nil || len(entries) != 0 {
+		t.Fatalf("missing ledger should be empty, got %v, %v", entries, err)
+	}
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	entry := LedgerEntry{SessionID: "s1", EpicID: "obi-foo", BeadID: "obi-foo.1", Status: "success", CommitSummary: "Add thing", StartedAt: start, CompletedAt: start.Add(time.Minute)}
+	if err := AppendLedger(path, entry); err != nil {
+		t.Fatalf("AppendLedger: %v", err)
+	}
+	if err := AppendLedger(path, LedgerEntry{SessionID: "s2", EpicID: "obi-bar", Status: "needs_help"}); err != nil {
+		t.Fatalf("AppendLedger: %v", err)
+	}
+	entries, err := ReadLedger(path, "obi-foo")
+	if err != nil {
+		t.Fatalf("ReadLedger: %v", err)
+	}
+	if len(entries) != 1 || entries[0].BeadID != "obi-foo.1" || entries[0].DurationMs != 60000 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}